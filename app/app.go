@@ -0,0 +1,393 @@
+package app
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/alimzhanovlr/sdk/buildinfo"
+	"github.com/alimzhanovlr/sdk/config"
+	"github.com/alimzhanovlr/sdk/errors"
+	"github.com/alimzhanovlr/sdk/eventbus"
+	"github.com/alimzhanovlr/sdk/health"
+	"github.com/alimzhanovlr/sdk/i18n"
+	"github.com/alimzhanovlr/sdk/logger"
+	"github.com/alimzhanovlr/sdk/metrics"
+	"github.com/alimzhanovlr/sdk/middleware"
+	"github.com/alimzhanovlr/sdk/server"
+	"github.com/alimzhanovlr/sdk/tracing"
+	"github.com/alimzhanovlr/sdk/workerpool"
+	"github.com/gofiber/fiber/v2"
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/fx"
+)
+
+// DefaultConfigPath is used when WithConfigPath is not set
+const DefaultConfigPath = "config/config.yaml"
+
+// options holds runner options
+type options struct {
+	configPath      string
+	shutdownTimeout time.Duration
+	routes          []func(*fiber.App)
+	fxOptions       []fx.Option
+	verbose         bool
+}
+
+// Option configures application
+type Option func(*options)
+
+// WithConfigPath sets config file path, APP_CONFIG env overrides default
+func WithConfigPath(path string) Option {
+	return func(o *options) {
+		o.configPath = path
+	}
+}
+
+// WithShutdownTimeout limits graceful shutdown
+func WithShutdownTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.shutdownTimeout = timeout
+	}
+}
+
+// WithRoutes registers routes after default middleware
+func WithRoutes(register func(*fiber.App)) Option {
+	return func(o *options) {
+		o.routes = append(o.routes, register)
+	}
+}
+
+// WithProviders adds fx constructors (repositories, use cases, handlers)
+func WithProviders(constructors ...interface{}) Option {
+	return func(o *options) {
+		o.fxOptions = append(o.fxOptions, fx.Provide(constructors...))
+	}
+}
+
+// WithInvokes adds fx invocations, e.g. handler route registration
+func WithInvokes(funcs ...interface{}) Option {
+	return func(o *options) {
+		o.fxOptions = append(o.fxOptions, fx.Invoke(funcs...))
+	}
+}
+
+// WithFxOptions adds raw fx options (fx.Decorate, fx.Replace, modules)
+func WithFxOptions(opts ...fx.Option) Option {
+	return func(o *options) {
+		o.fxOptions = append(o.fxOptions, opts...)
+	}
+}
+
+// WithFxLogs enables fx dependency graph logging
+func WithFxLogs() Option {
+	return func(o *options) {
+		o.verbose = true
+	}
+}
+
+// App is an assembled application
+type App struct {
+	fx              *fx.App
+	shutdownTimeout time.Duration
+}
+
+// New assembles config, logger, tracing, metrics, i18n, health and server
+func New(opts ...Option) *App {
+	o := newOptions(opts)
+
+	var log *logger.Logger
+	a := &App{
+		fx:              fx.New(append(o.appOptions(), fx.Populate(&log))...),
+		shutdownTimeout: o.shutdownTimeout,
+	}
+
+	// log.Fatal stops fx before exiting, so OnStop hooks flush and release
+	// resources instead of being skipped by os.Exit
+	if log != nil {
+		log.OnFatal("fx", a.fx.Stop)
+	}
+	return a
+}
+
+// newOptions applies opts over defaults
+func newOptions(opts []Option) *options {
+	o := &options{
+		configPath:      DefaultConfigPath,
+		shutdownTimeout: 15 * time.Second,
+	}
+	if path := os.Getenv("APP_CONFIG"); path != "" {
+		o.configPath = path
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// appOptions builds fx options of the application
+func (o *options) appOptions() []fx.Option {
+	fxOptions := []fx.Option{
+		Module(o.configPath),
+		fx.StopTimeout(o.shutdownTimeout),
+	}
+	if !o.verbose {
+		fxOptions = append(fxOptions, fx.NopLogger)
+	}
+
+	// User options come after middleware so routes see it
+	fxOptions = append(fxOptions, o.fxOptions...)
+	fxOptions = append(fxOptions, fx.Invoke(func(srv *server.Server) {
+		for _, register := range o.routes {
+			srv.RegisterRoutes(register)
+		}
+	}))
+
+	// Start listening last, once everything is registered
+	return append(fxOptions, fx.Invoke(func(lc fx.Lifecycle, srv *server.Server) {
+		srv.Start(lc)
+	}))
+}
+
+// Module provides SDK components and default middleware, use it directly
+// when composing fx.New by hand
+func Module(configPath string) fx.Option {
+	return fx.Module("microkit",
+		fx.Provide(
+			func() (*config.Config, error) {
+				return config.Load(configPath)
+			},
+			provideLogger,
+			provideTracer,
+			provideMetrics,
+			provideI18n,
+			provideErrorStats,
+			provideEventBus,
+			provideWorkerPool,
+			health.New,
+			server.New,
+		),
+		fx.Invoke(logConfig, setupMiddleware),
+	)
+}
+
+// logConfig logs fingerprint of the loaded config and of every reload, so
+// replicas running stale configuration after a rollout can be spotted
+func logConfig(lc fx.Lifecycle, log *logger.Logger) {
+	if rev := config.Snapshot(); rev != nil {
+		log.Info("Config loaded",
+			logger.String("fingerprint", rev.Fingerprint()),
+			logger.Any("generation", rev.Generation()),
+		)
+	}
+
+	unsubscribe := config.Subscribe(func(_, rev *config.Revision) {
+		log.Info("Config reloaded",
+			logger.String("fingerprint", rev.Fingerprint()),
+			logger.Any("generation", rev.Generation()),
+		)
+	})
+	lc.Append(fx.Hook{
+		OnStop: func(ctx context.Context) error {
+			unsubscribe()
+			return nil
+		},
+	})
+}
+
+// Run starts application, blocks until SIGINT/SIGTERM and stops it gracefully
+func (a *App) Run() error {
+	if err := a.fx.Err(); err != nil {
+		return err
+	}
+
+	startCtx, cancel := context.WithTimeout(context.Background(), a.fx.StartTimeout())
+	defer cancel()
+	if err := a.fx.Start(startCtx); err != nil {
+		return err
+	}
+
+	<-a.fx.Done()
+
+	stopCtx, cancel := context.WithTimeout(context.Background(), a.shutdownTimeout)
+	defer cancel()
+	return a.fx.Stop(stopCtx)
+}
+
+// Fx returns underlying fx application
+func (a *App) Fx() *fx.App {
+	return a.fx
+}
+
+func provideLogger(lc fx.Lifecycle, cfg *config.Config) (*logger.Logger, error) {
+	log, err := logger.New(logger.Config{
+		Level:          cfg.Logger.Level,
+		Format:         cfg.Logger.Format,
+		OutputPaths:    cfg.Logger.OutputPath,
+		SpanEventLevel: cfg.Logger.SpanEventLevel,
+		Levels:         cfg.Logger.Levels,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Every entry tells which build wrote it
+	build := buildinfo.Get()
+	log = log.WithFields(
+		logger.String("version", build.Version),
+		logger.String("commit", build.ShortCommit()),
+	)
+
+	// Panics recovered by errors.Recover and errors.SafeGo
+	errors.SetPanicHook(func(err *errors.AppError, panicErr *errors.PanicError) {
+		log.Error("Panic recovered",
+			logger.Error(err),
+			logger.String("stack", string(panicErr.Stack)),
+		)
+	})
+
+	// Logger is constructed first, so it is closed after everything else
+	lc.Append(fx.Hook{
+		OnStop: func(ctx context.Context) error {
+			errors.SetPanicHook(nil)
+			return log.Close()
+		},
+	})
+	return log, nil
+}
+
+// tracingConfig maps config, the resource is shared by traces and metrics
+func tracingConfig(cfg *config.Config) tracing.Config {
+	// Validated by config.Load
+	resourceAttributes, _ := cfg.Tracing.ResourceAttributeMap()
+
+	return tracing.Config{
+		Enabled:            cfg.Tracing.Enabled,
+		ServiceName:        cfg.Tracing.ServiceName,
+		Endpoint:           cfg.Tracing.Endpoint,
+		SampleRate:         cfg.Tracing.SampleRate,
+		ServiceVersion:     cfg.Tracing.ServiceVersion,
+		Environment:        cfg.Tracing.Environment,
+		ResourceAttributes: resourceAttributes,
+		RedactAttributes:   cfg.Tracing.RedactAttributes,
+		TailSampling: tracing.TailSamplingConfig{
+			Enabled:          cfg.Tracing.TailSampling.Enabled,
+			LatencyThreshold: cfg.Tracing.TailSampling.LatencyThreshold,
+			DecisionWait:     cfg.Tracing.TailSampling.DecisionWait,
+			MaxTraces:        cfg.Tracing.TailSampling.MaxTraces,
+		},
+	}
+}
+
+func provideTracer(lc fx.Lifecycle, cfg *config.Config) (*tracing.Tracer, error) {
+	tracer, err := tracing.New(tracingConfig(cfg))
+	if err != nil {
+		return nil, err
+	}
+
+	lc.Append(fx.Hook{
+		OnStop: func(ctx context.Context) error {
+			return tracer.Shutdown(ctx)
+		},
+	})
+	return tracer, nil
+}
+
+func provideMetrics(lc fx.Lifecycle, cfg *config.Config) (*metrics.Registry, error) {
+	reg, err := metrics.New(metrics.Config{
+		Enabled:     cfg.Metrics.Enabled,
+		ServiceName: cfg.Tracing.ServiceName,
+		Exporter:    cfg.Metrics.Exporter,
+		Path:        cfg.Metrics.Path,
+		Namespace:   cfg.Metrics.Namespace,
+		Endpoint:    cfg.Metrics.Endpoint,
+		Interval:    time.Duration(cfg.Metrics.Interval) * time.Second,
+		Resource:    tracing.NewResource(tracingConfig(cfg)),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	reg.Start(lc)
+	return reg, nil
+}
+
+// provideErrorStats returns nil when error stats are disabled
+func provideErrorStats(lc fx.Lifecycle, cfg *config.Config, reg *metrics.Registry) *errors.Stats {
+	if !cfg.Errors.Stats {
+		return nil
+	}
+
+	stats := errors.NewStats()
+	occurrences := reg.Counter("app_errors_total", "Total number of AppError occurrences by code")
+	stats.OnRecord(func(err *errors.AppError) {
+		occurrences.Add(context.Background(), 1,
+			attribute.String("code", err.Code),
+			attribute.Int("status", err.StatusCode),
+		)
+	})
+
+	errors.SetStats(stats)
+	lc.Append(fx.Hook{
+		OnStop: func(ctx context.Context) error {
+			errors.SetStats(nil)
+			return nil
+		},
+	})
+	return stats
+}
+
+// provideEventBus creates the event bus, async handlers are awaited on stop
+func provideEventBus(lc fx.Lifecycle, log *logger.Logger) *eventbus.Bus {
+	cfg := eventbus.DefaultConfig()
+	cfg.OnAsyncError = func(ctx context.Context, event interface{}, handler string, err error) {
+		log.Ctx(ctx).Error("Event handler failed",
+			logger.String("handler", handler),
+			logger.Error(err),
+		)
+	}
+
+	bus := eventbus.New(cfg)
+	lc.Append(fx.Hook{
+		OnStop: bus.Close,
+	})
+	return bus
+}
+
+// provideWorkerPool creates the pool for background tasks, queued tasks
+// are drained on stop
+func provideWorkerPool(lc fx.Lifecycle, log *logger.Logger, reg *metrics.Registry) *workerpool.Pool {
+	poolCfg := workerpool.DefaultConfig()
+	poolCfg.Name = "background"
+	poolCfg.OnError = func(ctx context.Context, err error) {
+		log.Ctx(ctx).Error("Background task failed", logger.Error(err))
+	}
+	poolCfg.Metrics = reg.WorkerPool()
+
+	pool := workerpool.New(poolCfg)
+	lc.Append(fx.Hook{
+		OnStop: pool.Shutdown,
+	})
+	return pool
+}
+
+func provideI18n(cfg *config.Config) (*i18n.I18n, error) {
+	return i18n.New(i18n.Config{
+		DefaultLanguage: cfg.I18n.DefaultLanguage,
+		SupportedLangs:  cfg.I18n.SupportedLangs,
+		Path:            cfg.I18n.Path,
+	})
+}
+
+// setupMiddleware installs default middleware chain
+func setupMiddleware(srv *server.Server, cfg *config.Config, log *logger.Logger, tracer *tracing.Tracer, reg *metrics.Registry, translator *i18n.I18n) {
+	app := srv.App()
+
+	app.Use(middleware.RequestIDMiddleware())
+	app.Use(middleware.TracingMiddleware(tracer))
+	if cfg.Metrics.Enabled {
+		app.Use(middleware.MetricsMiddleware(reg))
+	}
+	app.Use(middleware.LoggerMiddleware(log))
+	app.Use(middleware.I18nMiddleware(translator))
+}
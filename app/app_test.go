@@ -0,0 +1,41 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alimzhanovlr/sdk/eventbus"
+	"github.com/alimzhanovlr/sdk/health"
+	"github.com/alimzhanovlr/sdk/workerpool"
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/fx"
+)
+
+type orderService struct{}
+
+func TestDefaultOptionsValidate(t *testing.T) {
+	if err := fx.ValidateApp(newOptions(nil).appOptions()...); err != nil {
+		t.Fatalf("default application graph is invalid: %v", err)
+	}
+}
+
+func TestUserOptionsValidate(t *testing.T) {
+	o := newOptions([]Option{
+		WithProviders(func(pool *workerpool.Pool, bus *eventbus.Bus) *orderService {
+			return &orderService{}
+		}),
+		WithInvokes(func(svc *orderService, checks *health.Registry) {}),
+		WithRoutes(func(app *fiber.App) {}),
+	})
+	if err := fx.ValidateApp(o.appOptions()...); err != nil {
+		t.Fatalf("application graph is invalid: %v", err)
+	}
+}
+
+func TestMissingDependencyFailsValidation(t *testing.T) {
+	o := newOptions([]Option{WithInvokes(func(svc *orderService) {})})
+	err := fx.ValidateApp(o.appOptions()...)
+	if err == nil || !strings.Contains(err.Error(), "orderService") {
+		t.Fatalf("err = %v, want missing *orderService", err)
+	}
+}
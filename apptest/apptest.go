@@ -0,0 +1,116 @@
+package apptest
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alimzhanovlr/sdk/config"
+	"github.com/alimzhanovlr/sdk/httpclient"
+	"github.com/alimzhanovlr/sdk/logger"
+	"github.com/alimzhanovlr/sdk/server"
+	"github.com/alimzhanovlr/sdk/tracing"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+	"go.uber.org/zap/zaptest"
+)
+
+// App is a running in-process application
+type App struct {
+	tb     testing.TB
+	fx     *fxtest.App
+	server *server.Server
+	http   *httptest.Server
+	client *http.Client
+}
+
+// New boots application with test defaults: config with defaults only,
+// logger writing to tb, disabled tracer and server without listener.
+// Use fx.Provide for in-memory repositories, fx.Decorate or fx.Replace
+// to override defaults and fx.Invoke to register routes
+func New(tb testing.TB, opts ...fx.Option) *App {
+	tb.Helper()
+
+	a := &App{tb: tb}
+
+	options := []fx.Option{
+		fx.NopLogger,
+		fx.Provide(
+			DefaultConfig,
+			func() *logger.Logger {
+				return &logger.Logger{Logger: zaptest.NewLogger(tb)}
+			},
+			func() (*tracing.Tracer, error) {
+				return tracing.New(tracing.Config{Enabled: false})
+			},
+			server.New,
+		),
+	}
+	options = append(options, opts...)
+	options = append(options, fx.Populate(&a.server))
+
+	a.fx = fxtest.New(tb, options...)
+	a.fx.RequireStart()
+
+	// Serve fiber app through net/http so real transports can be used
+	a.http = httptest.NewServer(adaptor.FiberApp(a.server.App()))
+	a.client = &http.Client{
+		Transport: httpclient.NewLoggingRoundTripper(a.http.Client().Transport, httpclient.DefaultLoggingConfig(&testLogger{tb: tb})),
+	}
+
+	tb.Cleanup(func() {
+		a.http.Close()
+		a.fx.RequireStop()
+	})
+
+	return a
+}
+
+// DefaultConfig returns configuration built from defaults only
+func DefaultConfig() (*config.Config, error) {
+	return config.Load("")
+}
+
+// Server returns application server
+func (a *App) Server() *server.Server {
+	return a.server
+}
+
+// URL returns base URL of the test server
+func (a *App) URL() string {
+	return a.http.URL
+}
+
+// HTTPClient returns client with SDK logging transport
+func (a *App) HTTPClient() *http.Client {
+	return a.client
+}
+
+// testLogger adapts testing.TB to httpclient.Logger
+type testLogger struct {
+	tb testing.TB
+}
+
+func (l *testLogger) Debug(msg string, fields ...interface{}) {
+	l.log("DEBUG", msg, fields)
+}
+
+func (l *testLogger) Info(msg string, fields ...interface{}) {
+	l.log("INFO", msg, fields)
+}
+
+func (l *testLogger) Error(msg string, fields ...interface{}) {
+	l.log("ERROR", msg, fields)
+}
+
+func (l *testLogger) log(level, msg string, fields []interface{}) {
+	l.tb.Helper()
+
+	line := fmt.Sprintf("[%s] %s", level, msg)
+	for i := 0; i+1 < len(fields); i += 2 {
+		line += fmt.Sprintf(" %v=%v", fields[i], fields[i+1])
+	}
+	l.tb.Log(line)
+}
@@ -0,0 +1,75 @@
+package apptest
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/alimzhanovlr/sdk/config"
+	"github.com/alimzhanovlr/sdk/health"
+	"github.com/alimzhanovlr/sdk/server"
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/fx"
+)
+
+type order struct {
+	ID    string `json:"id"`
+	Items []item `json:"items"`
+}
+
+type item struct {
+	SKU string `json:"sku"`
+	Qty int    `json:"qty"`
+}
+
+func registerOrders(s *server.Server) {
+	s.App().Get("/orders/:id", func(c *fiber.Ctx) error {
+		return server.SendSuccess(c, order{ID: c.Params("id"), Items: []item{{SKU: "book", Qty: 2}}})
+	})
+	s.App().Post("/orders", func(c *fiber.Ctx) error {
+		var o order
+		if err := c.BodyParser(&o); err != nil {
+			return fiber.ErrBadRequest
+		}
+		o.ID = c.Get("X-Order-ID")
+		return server.SendCreated(c, o)
+	})
+}
+
+func TestAppServesRoutes(t *testing.T) {
+	app := New(t, fx.Invoke(registerOrders))
+
+	app.Get("/orders/42").Do().
+		ExpectStatus(http.StatusOK).
+		ExpectJSON(`{"success":true,"data":{"id":"42","items":[{"sku":"book","qty":2}]}}`).
+		ExpectField("data.items.0.qty", 2)
+
+	var created server.Response
+	app.Post("/orders").
+		Header("X-Order-ID", "43").
+		JSON(order{Items: []item{{SKU: "pen", Qty: 1}}}).
+		Do().
+		ExpectStatus(http.StatusCreated).
+		ExpectField("data.id", "43").
+		Decode(&created)
+	if !created.Success {
+		t.Errorf("response = %+v, want success", created)
+	}
+
+	app.Get("/missing").Do().ExpectStatus(http.StatusNotFound)
+}
+
+func TestAppWithOptionalDependencies(t *testing.T) {
+	app := New(t,
+		fx.Provide(health.New),
+		fx.Decorate(func(cfg *config.Config) *config.Config {
+			cfg.Server.AdminAddr = "127.0.0.1:0"
+			return cfg
+		}),
+	)
+
+	app.Get("/livez").Do().ExpectStatus(http.StatusOK).ExpectField("status", "up")
+	app.Get("/readyz").Do().ExpectStatus(http.StatusOK)
+	if app.Server().Admin() == nil {
+		t.Error("admin app is nil, want the decorated config to enable it")
+	}
+}
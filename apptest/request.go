@@ -0,0 +1,204 @@
+package apptest
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// Request builds a request to the application
+type Request struct {
+	app     *App
+	method  string
+	path    string
+	body    io.Reader
+	headers http.Header
+}
+
+// Get starts GET request
+func (a *App) Get(path string) *Request {
+	return a.Request(http.MethodGet, path)
+}
+
+// Post starts POST request
+func (a *App) Post(path string) *Request {
+	return a.Request(http.MethodPost, path)
+}
+
+// Put starts PUT request
+func (a *App) Put(path string) *Request {
+	return a.Request(http.MethodPut, path)
+}
+
+// Delete starts DELETE request
+func (a *App) Delete(path string) *Request {
+	return a.Request(http.MethodDelete, path)
+}
+
+// Request starts request with method
+func (a *App) Request(method, path string) *Request {
+	return &Request{
+		app:     a,
+		method:  method,
+		path:    path,
+		headers: make(http.Header),
+	}
+}
+
+// Header sets request header
+func (r *Request) Header(key, value string) *Request {
+	r.headers.Set(key, value)
+	return r
+}
+
+// JSON sets request body encoded as JSON
+func (r *Request) JSON(v interface{}) *Request {
+	r.app.tb.Helper()
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		r.app.tb.Fatalf("apptest: failed to encode body: %v", err)
+	}
+	r.body = bytes.NewReader(data)
+	r.headers.Set("Content-Type", "application/json")
+	return r
+}
+
+// Body sets raw request body
+func (r *Request) Body(body io.Reader, contentType string) *Request {
+	r.body = body
+	r.headers.Set("Content-Type", contentType)
+	return r
+}
+
+// Do sends request and reads response
+func (r *Request) Do() *Response {
+	tb := r.app.tb
+	tb.Helper()
+
+	req, err := http.NewRequest(r.method, r.app.URL()+r.path, r.body)
+	if err != nil {
+		tb.Fatalf("apptest: failed to build request: %v", err)
+	}
+	req.Header = r.headers
+
+	resp, err := r.app.client.Do(req)
+	if err != nil {
+		tb.Fatalf("apptest: %s %s failed: %v", r.method, r.path, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		tb.Fatalf("apptest: failed to read response: %v", err)
+	}
+
+	return &Response{
+		tb:         tb,
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+		Body:       body,
+	}
+}
+
+// Response is a received response with assertion helpers
+type Response struct {
+	tb         testing.TB
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+// ExpectStatus fails test when status differs
+func (r *Response) ExpectStatus(code int) *Response {
+	r.tb.Helper()
+
+	if r.StatusCode != code {
+		r.tb.Errorf("apptest: expected status %d, got %d, body: %s", code, r.StatusCode, r.Body)
+	}
+	return r
+}
+
+// Decode decodes JSON body into v
+func (r *Response) Decode(v interface{}) *Response {
+	r.tb.Helper()
+
+	if err := json.Unmarshal(r.Body, v); err != nil {
+		r.tb.Fatalf("apptest: failed to decode response: %v, body: %s", err, r.Body)
+	}
+	return r
+}
+
+// ExpectJSON fails test when body is not semantically equal to expected JSON
+func (r *Response) ExpectJSON(expected string) *Response {
+	r.tb.Helper()
+
+	var want, got interface{}
+	if err := json.Unmarshal([]byte(expected), &want); err != nil {
+		r.tb.Fatalf("apptest: invalid expected JSON: %v", err)
+	}
+	if err := json.Unmarshal(r.Body, &got); err != nil {
+		r.tb.Fatalf("apptest: response is not JSON: %v, body: %s", err, r.Body)
+	}
+
+	if !reflect.DeepEqual(want, got) {
+		r.tb.Errorf("apptest: JSON mismatch\nexpected: %s\n     got: %s", expected, r.Body)
+	}
+	return r
+}
+
+// ExpectField fails test when value at dot separated path differs,
+// array elements are addressed by index, e.g. "data.items.0.id"
+func (r *Response) ExpectField(path string, expected interface{}) *Response {
+	r.tb.Helper()
+
+	var doc interface{}
+	if err := json.Unmarshal(r.Body, &doc); err != nil {
+		r.tb.Fatalf("apptest: response is not JSON: %v, body: %s", err, r.Body)
+	}
+
+	got, ok := lookup(doc, path)
+	if !ok {
+		r.tb.Errorf("apptest: field %s not found in %s", path, r.Body)
+		return r
+	}
+
+	// Round-trip expected value so numbers compare as float64
+	data, _ := json.Marshal(expected)
+	var want interface{}
+	_ = json.Unmarshal(data, &want)
+
+	if !reflect.DeepEqual(want, got) {
+		r.tb.Errorf("apptest: field %s: expected %v, got %v", path, want, got)
+	}
+	return r
+}
+
+// lookup walks decoded JSON by dot separated path
+func lookup(doc interface{}, path string) (interface{}, bool) {
+	current := doc
+	for _, part := range strings.Split(path, ".") {
+		switch node := current.(type) {
+		case map[string]interface{}:
+			value, ok := node[part]
+			if !ok {
+				return nil, false
+			}
+			current = value
+		case []interface{}:
+			i, err := strconv.Atoi(part)
+			if err != nil || i < 0 || i >= len(node) {
+				return nil, false
+			}
+			current = node[i]
+		default:
+			return nil, false
+		}
+	}
+	return current, true
+}
@@ -0,0 +1,192 @@
+// Package audit records who did what to which resource. Events are
+// append-only evidence, unlike logs they are never sampled or dropped:
+// Record fails when the sink does
+package audit
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/alimzhanovlr/sdk/httpclient"
+	"github.com/alimzhanovlr/sdk/idgen"
+	"github.com/alimzhanovlr/sdk/redact"
+	"github.com/alimzhanovlr/sdk/tenancy"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Actor types
+const (
+	ActorUser    = "user"
+	ActorService = "service"
+	ActorSystem  = "system"
+)
+
+// Outcomes
+const (
+	OutcomeSuccess = "success"
+	OutcomeFailure = "failure"
+	OutcomeDenied  = "denied"
+)
+
+// Actor is who performed the action
+type Actor struct {
+	ID        string `json:"id"`
+	Type      string `json:"type"`
+	IP        string `json:"ip,omitempty"`
+	UserAgent string `json:"user_agent,omitempty"`
+}
+
+// Resource is what the action was performed on
+type Resource struct {
+	Type string `json:"type"`
+	ID   string `json:"id,omitempty"`
+}
+
+// Change is a changed field, nested fields are dot separated paths
+type Change struct {
+	Field  string      `json:"field"`
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// Event is an audit trail entry
+type Event struct {
+	ID        string            `json:"id"`
+	Time      time.Time         `json:"time"`
+	Tenant    string            `json:"tenant,omitempty"`
+	Actor     Actor             `json:"actor"`
+	Action    string            `json:"action"`
+	Resource  Resource          `json:"resource"`
+	Outcome   string            `json:"outcome"`
+	Reason    string            `json:"reason,omitempty"`
+	Changes   []Change          `json:"changes,omitempty"`
+	RequestID string            `json:"request_id,omitempty"`
+	TraceID   string            `json:"trace_id,omitempty"`
+	Metadata  map[string]string `json:"metadata,omitempty"`
+}
+
+// Sink stores events
+type Sink interface {
+	Write(ctx context.Context, events ...*Event) error
+}
+
+// Config holds auditor configuration
+type Config struct {
+	// Sanitizer masks sensitive values of changes and metadata, defaults to
+	// redact.Default
+	Sanitizer redact.ValueSanitizer
+}
+
+// Auditor fills events from context and writes them to a sink
+type Auditor struct {
+	sink      Sink
+	sanitizer redact.ValueSanitizer
+}
+
+// New creates an auditor
+func New(sink Sink, config Config) *Auditor {
+	if config.Sanitizer == nil {
+		config.Sanitizer = redact.Default()
+	}
+	return &Auditor{sink: sink, sanitizer: config.Sanitizer}
+}
+
+// Record writes event. ID, time, tenant, actor, request and trace IDs are
+// taken from ctx unless set, outcome defaults to success
+func (a *Auditor) Record(ctx context.Context, event Event) error {
+	prepared, err := a.Prepare(ctx, event)
+	if err != nil {
+		return err
+	}
+	if err := a.sink.Write(ctx, prepared); err != nil {
+		return fmt.Errorf("failed to write audit event: %w", err)
+	}
+	return nil
+}
+
+// Prepare fills event like Record without writing it, for saving it in the
+// usecase transaction:
+//
+//	event, err := auditor.Prepare(ctx, audit.Event{Action: "order.cancel", Resource: res})
+//	err = sink.Save(ctx, tx, event)
+func (a *Auditor) Prepare(ctx context.Context, event Event) (*Event, error) {
+	if event.ID == "" {
+		event.ID = idgen.NewString()
+	}
+	if event.Time.IsZero() {
+		event.Time = time.Now().UTC()
+	}
+	if event.Tenant == "" {
+		event.Tenant = tenancy.ID(ctx)
+	}
+	if event.Actor.ID == "" {
+		if actor, ok := ActorFromContext(ctx); ok {
+			event.Actor = actor
+		} else {
+			event.Actor = Actor{ID: "system", Type: ActorSystem}
+		}
+	}
+	if event.Outcome == "" {
+		event.Outcome = OutcomeSuccess
+	}
+	if event.RequestID == "" {
+		event.RequestID = httpclient.RequestIDFromContext(ctx)
+	}
+	if event.TraceID == "" {
+		if sc := trace.SpanContextFromContext(ctx); sc.HasTraceID() {
+			event.TraceID = sc.TraceID().String()
+		}
+	}
+	if len(event.Metadata) > 0 {
+		metadata, err := a.sanitizeMetadata(event.Metadata)
+		if err != nil {
+			return nil, err
+		}
+		event.Metadata = metadata
+	}
+	return &event, nil
+}
+
+// RecordChange records action on resource with the fields that differ
+// between before and after, sensitive values are masked. Before is nil for
+// created resources and after for deleted ones
+func (a *Auditor) RecordChange(ctx context.Context, action string, resource Resource, before, after interface{}) error {
+	changes, err := a.Diff(before, after)
+	if err != nil {
+		return err
+	}
+	return a.Record(ctx, Event{
+		Action:   action,
+		Resource: resource,
+		Changes:  changes,
+	})
+}
+
+func (a *Auditor) sanitizeMetadata(metadata map[string]string) (map[string]string, error) {
+	sanitized, err := a.sanitizer.SanitizeValue(metadata)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sanitize audit metadata: %w", err)
+	}
+
+	values, _ := sanitized.(map[string]interface{})
+	result := make(map[string]string, len(values))
+	for key, value := range values {
+		result[key] = fmt.Sprint(value)
+	}
+	return result, nil
+}
+
+type actorKey struct{}
+
+// WithActor returns context carrying actor, middleware.AuditMiddleware sets
+// it from the request
+func WithActor(ctx context.Context, actor Actor) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFromContext returns actor stored by WithActor
+func ActorFromContext(ctx context.Context) (Actor, bool) {
+	actor, ok := ctx.Value(actorKey{}).(Actor)
+	return actor, ok
+}
@@ -0,0 +1,109 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/alimzhanovlr/sdk/redact"
+	"github.com/alimzhanovlr/sdk/tenancy"
+)
+
+type memorySink struct {
+	events []*Event
+	err    error
+}
+
+func (s *memorySink) Write(_ context.Context, events ...*Event) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.events = append(s.events, events...)
+	return nil
+}
+
+func TestRecordFillsEventFromContext(t *testing.T) {
+	sink := &memorySink{}
+	auditor := New(sink, Config{})
+
+	ctx := WithActor(context.Background(), Actor{ID: "u1", Type: ActorUser})
+	ctx = tenancy.WithTenant(ctx, tenancy.Tenant{ID: "acme"})
+	err := auditor.Record(ctx, Event{
+		Action:   "user.update",
+		Resource: Resource{Type: "user", ID: "42"},
+		Metadata: map[string]string{"password": "hunter2", "plan": "pro"},
+	})
+	if err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+
+	if len(sink.events) != 1 {
+		t.Fatalf("events = %d", len(sink.events))
+	}
+	event := sink.events[0]
+	if event.ID == "" || event.Time.IsZero() {
+		t.Error("ID and time must be set")
+	}
+	if event.Actor.ID != "u1" || event.Tenant != "acme" || event.Outcome != OutcomeSuccess {
+		t.Errorf("unexpected event %+v", event)
+	}
+	if event.Metadata["password"] != redact.DefaultMask || event.Metadata["plan"] != "pro" {
+		t.Errorf("metadata = %v", event.Metadata)
+	}
+}
+
+func TestRecordDefaultsToSystemActor(t *testing.T) {
+	sink := &memorySink{}
+	if err := New(sink, Config{}).Record(context.Background(), Event{Action: "cleanup"}); err != nil {
+		t.Fatal(err)
+	}
+	if sink.events[0].Actor.ID != "system" || sink.events[0].Actor.Type != ActorSystem {
+		t.Errorf("actor = %+v", sink.events[0].Actor)
+	}
+}
+
+func TestRecordReturnsSinkError(t *testing.T) {
+	sinkErr := errors.New("disk full")
+	err := New(&memorySink{err: sinkErr}, Config{}).Record(context.Background(), Event{Action: "x"})
+	if !errors.Is(err, sinkErr) {
+		t.Errorf("err = %v, want sink error", err)
+	}
+}
+
+func TestPrepareDoesNotWrite(t *testing.T) {
+	sink := &memorySink{}
+	event, err := New(sink, Config{}).Prepare(context.Background(), Event{Action: "order.cancel"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event.ID == "" || len(sink.events) != 0 {
+		t.Errorf("event = %+v, written = %d", event, len(sink.events))
+	}
+}
+
+func TestRecordChangeMasksSensitiveFields(t *testing.T) {
+	type user struct {
+		Email    string `json:"email"`
+		Password string `json:"password"`
+		Name     string `json:"name"`
+	}
+	sink := &memorySink{}
+	auditor := New(sink, Config{})
+
+	before := user{Email: "a@x", Password: "old", Name: "Ann"}
+	after := user{Email: "b@x", Password: "new", Name: "Ann"}
+	if err := auditor.RecordChange(context.Background(), "user.update", Resource{Type: "user"}, before, after); err != nil {
+		t.Fatal(err)
+	}
+
+	changes := sink.events[0].Changes
+	if len(changes) != 2 || changes[0].Field != "email" || changes[1].Field != "password" {
+		t.Fatalf("changes = %+v", changes)
+	}
+	if changes[0].Before != "a@x" || changes[0].After != "b@x" {
+		t.Errorf("email change = %+v", changes[0])
+	}
+	if changes[1].Before != redact.DefaultMask || changes[1].After != redact.DefaultMask {
+		t.Errorf("password change = %+v", changes[1])
+	}
+}
@@ -0,0 +1,107 @@
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+)
+
+// Diff returns fields that differ between before and after by their JSON
+// form, nested objects are compared field by field and arrays as a whole.
+// Values are reported sanitized, so a changed password is recorded as
+// changed without its values
+func (a *Auditor) Diff(before, after interface{}) ([]Change, error) {
+	rawBefore, err := flattenValue(before)
+	if err != nil {
+		return nil, err
+	}
+	rawAfter, err := flattenValue(after)
+	if err != nil {
+		return nil, err
+	}
+
+	var changed []string
+	for field, value := range rawBefore {
+		if other, ok := rawAfter[field]; !ok || !reflect.DeepEqual(value, other) {
+			changed = append(changed, field)
+		}
+	}
+	for field := range rawAfter {
+		if _, ok := rawBefore[field]; !ok {
+			changed = append(changed, field)
+		}
+	}
+	if len(changed) == 0 {
+		return nil, nil
+	}
+	slices.Sort(changed)
+
+	// Sanitizer rules match JSON keys, so whole values are sanitized
+	sanitizedBefore, err := a.sanitizedFields(before)
+	if err != nil {
+		return nil, err
+	}
+	sanitizedAfter, err := a.sanitizedFields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make([]Change, 0, len(changed))
+	for _, field := range changed {
+		changes = append(changes, Change{
+			Field:  field,
+			Before: sanitizedBefore[field],
+			After:  sanitizedAfter[field],
+		})
+	}
+	return changes, nil
+}
+
+func (a *Auditor) sanitizedFields(value interface{}) (map[string]interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+	sanitized, err := a.sanitizer.SanitizeValue(value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sanitize audit value: %w", err)
+	}
+	fields := make(map[string]interface{})
+	flatten("", sanitized, fields)
+	return fields, nil
+}
+
+// flattenValue returns leaf values of the JSON form of value by path
+func flattenValue(value interface{}) (map[string]interface{}, error) {
+	fields := make(map[string]interface{})
+	if value == nil {
+		return fields, nil
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal audit value: %w", err)
+	}
+	var decoded interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal audit value: %w", err)
+	}
+
+	flatten("", decoded, fields)
+	return fields, nil
+}
+
+func flatten(prefix string, value interface{}, fields map[string]interface{}) {
+	object, ok := value.(map[string]interface{})
+	if !ok || len(object) == 0 {
+		fields[prefix] = value
+		return
+	}
+	for key, nested := range object {
+		path := key
+		if prefix != "" {
+			path = prefix + "." + key
+		}
+		flatten(path, nested, fields)
+	}
+}
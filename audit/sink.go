@@ -0,0 +1,207 @@
+package audit
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/alimzhanovlr/sdk/messaging"
+)
+
+// DefaultTable is the default audit table name
+const DefaultTable = "audit_events"
+
+// Schema returns PostgreSQL DDL for the audit table. Grant the service
+// INSERT and SELECT only, so the trail cannot be rewritten
+func Schema(table string) string {
+	if table == "" {
+		table = DefaultTable
+	}
+
+	return `CREATE TABLE IF NOT EXISTS ` + table + ` (
+	id            TEXT PRIMARY KEY,
+	occurred_at   TIMESTAMPTZ NOT NULL,
+	tenant        TEXT NOT NULL DEFAULT '',
+	actor_id      TEXT NOT NULL,
+	actor_type    TEXT NOT NULL,
+	action        TEXT NOT NULL,
+	resource_type TEXT NOT NULL,
+	resource_id   TEXT NOT NULL DEFAULT '',
+	outcome       TEXT NOT NULL,
+	event         JSONB NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS ` + table + `_resource_idx
+	ON ` + table + ` (resource_type, resource_id, occurred_at);
+
+CREATE INDEX IF NOT EXISTS ` + table + `_actor_idx
+	ON ` + table + ` (actor_id, occurred_at);
+`
+}
+
+// Execer is implemented by *sql.DB and *sql.Tx
+type Execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// SQLSink stores events in a PostgreSQL table, searchable columns are
+// duplicated from the JSON event
+type SQLSink struct {
+	db    *sql.DB
+	table string
+}
+
+var _ Sink = (*SQLSink)(nil)
+
+// NewSQLSink creates a table sink
+func NewSQLSink(db *sql.DB, table string) *SQLSink {
+	if table == "" {
+		table = DefaultTable
+	}
+	return &SQLSink{db: db, table: table}
+}
+
+// Migrate creates the audit table if it does not exist
+func (s *SQLSink) Migrate(ctx context.Context) error {
+	if _, err := s.db.ExecContext(ctx, Schema(s.table)); err != nil {
+		return fmt.Errorf("failed to create audit table: %w", err)
+	}
+	return nil
+}
+
+// Write implements Sink
+func (s *SQLSink) Write(ctx context.Context, events ...*Event) error {
+	return s.Save(ctx, s.db, events...)
+}
+
+// Save stores events using the caller's transaction, so they are committed
+// atomically with the change they describe
+func (s *SQLSink) Save(ctx context.Context, tx Execer, events ...*Event) error {
+	query := `INSERT INTO ` + s.table + ` (id, occurred_at, tenant, actor_id, actor_type, action, resource_type, resource_id, outcome, event)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`
+
+	for _, e := range events {
+		data, err := json.Marshal(e)
+		if err != nil {
+			return fmt.Errorf("failed to marshal audit event: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx, query, e.ID, e.Time, e.Tenant, e.Actor.ID, e.Actor.Type,
+			e.Action, e.Resource.Type, e.Resource.ID, e.Outcome, data); err != nil {
+			return fmt.Errorf("failed to save audit event: %w", err)
+		}
+	}
+	return nil
+}
+
+// PublisherSink publishes events as JSON messages keyed by resource, e.g.
+// to Kafka for a central audit store
+type PublisherSink struct {
+	publisher messaging.Publisher
+	topic     string
+}
+
+var _ Sink = (*PublisherSink)(nil)
+
+// NewPublisherSink creates a messaging sink
+func NewPublisherSink(publisher messaging.Publisher, topic string) *PublisherSink {
+	return &PublisherSink{publisher: publisher, topic: topic}
+}
+
+// Write implements Sink
+func (s *PublisherSink) Write(ctx context.Context, events ...*Event) error {
+	msgs := make([]*messaging.Message, 0, len(events))
+	for _, e := range events {
+		data, err := json.Marshal(e)
+		if err != nil {
+			return fmt.Errorf("failed to marshal audit event: %w", err)
+		}
+		msgs = append(msgs, &messaging.Message{
+			Key:       []byte(e.Resource.Type + ":" + e.Resource.ID),
+			Value:     data,
+			Timestamp: e.Time,
+			Headers: map[string]string{
+				"content-type":   "application/json",
+				"audit-event-id": e.ID,
+			},
+		})
+	}
+	return s.publisher.Publish(ctx, s.topic, msgs...)
+}
+
+// WriterSink writes events as JSON lines
+type WriterSink struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+var _ Sink = (*WriterSink)(nil)
+
+// NewWriterSink creates a JSON lines sink, e.g. for stdout collected by the
+// log pipeline into a separate index
+func NewWriterSink(w io.Writer) *WriterSink {
+	return &WriterSink{w: w}
+}
+
+// Write implements Sink
+func (s *WriterSink) Write(_ context.Context, events ...*Event) error {
+	var buf []byte
+	for _, e := range events {
+		data, err := json.Marshal(e)
+		if err != nil {
+			return fmt.Errorf("failed to marshal audit event: %w", err)
+		}
+		buf = append(append(buf, data...), '\n')
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err := s.w.Write(buf)
+	return err
+}
+
+// FileSink appends JSON lines to a file and syncs every write
+type FileSink struct {
+	*WriterSink
+	file *os.File
+}
+
+// NewFileSink opens path for appending, readable by the owner only
+func NewFileSink(path string) (*FileSink, error) {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit file: %w", err)
+	}
+	return &FileSink{WriterSink: NewWriterSink(file), file: file}, nil
+}
+
+// Write implements Sink
+func (s *FileSink) Write(ctx context.Context, events ...*Event) error {
+	if err := s.WriterSink.Write(ctx, events...); err != nil {
+		return err
+	}
+	return s.file.Sync()
+}
+
+// Close closes the file
+func (s *FileSink) Close() error {
+	return s.file.Close()
+}
+
+// MultiSink writes to every sink, e.g. a table and a central topic
+type MultiSink []Sink
+
+var _ Sink = MultiSink(nil)
+
+// Write implements Sink, all sinks are tried and their errors joined
+func (m MultiSink) Write(ctx context.Context, events ...*Event) error {
+	var errs []error
+	for _, sink := range m {
+		errs = append(errs, sink.Write(ctx, events...))
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,193 @@
+// Package auth issues and verifies JWTs (HS256, RS256, ES256) with key
+// rotation. The gateway signs tokens with the primary key, services verify
+// them with the key set or its public JWKS
+package auth
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/alimzhanovlr/sdk/idgen"
+)
+
+// Verification errors, details are wrapped
+var (
+	ErrInvalidToken = errors.New("invalid token")
+	ErrTokenExpired = errors.New("token expired")
+)
+
+// Config holds token configuration
+type Config struct {
+	// Issuer is set by Sign and required by Verify when not empty
+	Issuer string
+	// Audience is set by Sign unless claims have one, Verify requires one
+	// of them when not empty
+	Audience []string
+	TTL      time.Duration // Lifetime of tokens without ExpiresAt
+	Leeway   time.Duration // Allowed clock skew
+}
+
+// DefaultConfig returns default token config
+func DefaultConfig() Config {
+	return Config{
+		TTL:    15 * time.Minute,
+		Leeway: 30 * time.Second,
+	}
+}
+
+// Tokens signs and verifies JWTs with a key set
+type Tokens struct {
+	keys   *KeySet
+	config Config
+	now    func() time.Time
+}
+
+// New creates tokens for the key set
+func New(keys *KeySet, config Config) *Tokens {
+	if config.TTL <= 0 {
+		config.TTL = DefaultConfig().TTL
+	}
+	return &Tokens{keys: keys, config: config, now: time.Now}
+}
+
+// header is the JOSE header
+type header struct {
+	Alg string `json:"alg"`
+	Typ string `json:"typ,omitempty"`
+	Kid string `json:"kid,omitempty"`
+}
+
+// Sign returns a token of claims signed by the primary key. Empty issuer,
+// audience, issued at, expiry and ID are filled in
+func (t *Tokens) Sign(claims Claims) (string, error) {
+	key := t.keys.Primary()
+	if key == nil {
+		return "", errors.New("key set has no primary key")
+	}
+
+	now := t.now()
+	if claims.Issuer == "" {
+		claims.Issuer = t.config.Issuer
+	}
+	if len(claims.Audience) == 0 {
+		claims.Audience = t.config.Audience
+	}
+	if claims.IssuedAt == nil {
+		claims.IssuedAt = NewNumericDate(now)
+	}
+	if claims.ExpiresAt == nil {
+		claims.ExpiresAt = NewNumericDate(now.Add(t.config.TTL))
+	}
+	if claims.ID == "" {
+		claims.ID = idgen.NewString()
+	}
+
+	headerJSON, err := json.Marshal(header{Alg: string(key.Algorithm), Typ: "JWT", Kid: key.ID})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode token header: %w", err)
+	}
+	claimsJSON, err := json.Marshal(claims)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode token claims: %w", err)
+	}
+
+	b64 := base64.RawURLEncoding
+	signingInput := b64.EncodeToString(headerJSON) + "." + b64.EncodeToString(claimsJSON)
+	signature, err := key.sign([]byte(signingInput))
+	if err != nil {
+		return "", fmt.Errorf("failed to sign token: %w", err)
+	}
+	return signingInput + "." + b64.EncodeToString(signature), nil
+}
+
+// Verify checks signature, time and issuer/audience claims and returns the
+// claims. The algorithm must match the key found by kid, so a token cannot
+// pick a weaker one
+func (t *Tokens) Verify(token string) (*Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: malformed token", ErrInvalidToken)
+	}
+
+	b64 := base64.RawURLEncoding
+	headerJSON, err := b64.DecodeString(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed header", ErrInvalidToken)
+	}
+	var h header
+	if err := json.Unmarshal(headerJSON, &h); err != nil {
+		return nil, fmt.Errorf("%w: malformed header", ErrInvalidToken)
+	}
+
+	key, err := t.key(h.Kid)
+	if err != nil {
+		return nil, err
+	}
+	if h.Alg != string(key.Algorithm) {
+		return nil, fmt.Errorf("%w: algorithm %q does not match key %q", ErrInvalidToken, h.Alg, key.ID)
+	}
+
+	signature, err := b64.DecodeString(parts[2])
+	if err != nil || !key.verify([]byte(parts[0]+"."+parts[1]), signature) {
+		return nil, fmt.Errorf("%w: signature mismatch", ErrInvalidToken)
+	}
+
+	claimsJSON, err := b64.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed claims", ErrInvalidToken)
+	}
+	var claims Claims
+	if err := json.Unmarshal(claimsJSON, &claims); err != nil {
+		return nil, fmt.Errorf("%w: malformed claims", ErrInvalidToken)
+	}
+
+	if err := t.validate(&claims); err != nil {
+		return nil, err
+	}
+	return &claims, nil
+}
+
+// key returns the key of kid, tokens without kid use the primary key
+func (t *Tokens) key(kid string) (*Key, error) {
+	if kid == "" {
+		if key := t.keys.Primary(); key != nil {
+			return key, nil
+		}
+		return nil, fmt.Errorf("%w: key ID is missing", ErrInvalidToken)
+	}
+
+	key, ok := t.keys.Key(kid)
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown key %q", ErrInvalidToken, kid)
+	}
+	return key, nil
+}
+
+func (t *Tokens) validate(claims *Claims) error {
+	now := t.now()
+	leeway := t.config.Leeway
+
+	if claims.ExpiresAt == nil {
+		return fmt.Errorf("%w: exp claim is missing", ErrInvalidToken)
+	}
+	if now.After(claims.ExpiresAt.Add(leeway)) {
+		return fmt.Errorf("%w at %s", ErrTokenExpired, claims.ExpiresAt.UTC().Format(time.RFC3339))
+	}
+	if claims.NotBefore != nil && now.Add(leeway).Before(claims.NotBefore.Time) {
+		return fmt.Errorf("%w: token is not valid yet", ErrInvalidToken)
+	}
+	if t.config.Issuer != "" && claims.Issuer != t.config.Issuer {
+		return fmt.Errorf("%w: unexpected issuer %q", ErrInvalidToken, claims.Issuer)
+	}
+	if len(t.config.Audience) > 0 && !slices.ContainsFunc(claims.Audience, func(aud string) bool {
+		return slices.Contains(t.config.Audience, aud)
+	}) {
+		return fmt.Errorf("%w: unexpected audience", ErrInvalidToken)
+	}
+	return nil
+}
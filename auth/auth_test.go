@@ -0,0 +1,290 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testNow = time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+func hmacKey(t *testing.T, id string) *Key {
+	t.Helper()
+	key, err := NewHMACKey(id, []byte(strings.Repeat(id, 32)))
+	if err != nil {
+		t.Fatalf("NewHMACKey() error = %v", err)
+	}
+	return key
+}
+
+func ecKey(t *testing.T, id string) *Key {
+	t.Helper()
+	private, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	key, err := NewECDSAKey(id, private)
+	if err != nil {
+		t.Fatalf("NewECDSAKey() error = %v", err)
+	}
+	return key
+}
+
+func newTestTokens(t *testing.T, config Config, primary *Key, keys ...*Key) *Tokens {
+	t.Helper()
+	set, err := NewKeySet(primary, keys...)
+	if err != nil {
+		t.Fatalf("NewKeySet() error = %v", err)
+	}
+	tokens := New(set, config)
+	tokens.now = func() time.Time { return testNow }
+	return tokens
+}
+
+// forge returns token with its header replaced, the signature is kept
+func forge(t *testing.T, token string, h header) string {
+	t.Helper()
+	data, _ := json.Marshal(h)
+	parts := strings.Split(token, ".")
+	return base64.RawURLEncoding.EncodeToString(data) + "." + parts[1] + "." + parts[2]
+}
+
+func TestSignVerifyRoundTrip(t *testing.T) {
+	config := Config{Issuer: "gateway", Audience: []string{"orders"}, TTL: time.Minute}
+	for _, key := range []*Key{hmacKey(t, "h1"), ecKey(t, "e1")} {
+		t.Run(string(key.Algorithm), func(t *testing.T) {
+			tokens := newTestTokens(t, config, key)
+			token, err := tokens.Sign(Claims{Subject: "user-1", Roles: []string{"admin"}})
+			if err != nil {
+				t.Fatalf("Sign() error = %v", err)
+			}
+
+			claims, err := tokens.Verify(token)
+			if err != nil {
+				t.Fatalf("Verify() error = %v", err)
+			}
+			if claims.Subject != "user-1" || !claims.HasRole("admin") || claims.Issuer != "gateway" || claims.ID == "" {
+				t.Errorf("claims = %+v", claims)
+			}
+			if !claims.ExpiresAt.Equal(testNow.Add(time.Minute)) {
+				t.Errorf("exp = %v, want now + TTL", claims.ExpiresAt)
+			}
+		})
+	}
+}
+
+func TestVerifyRejectsAlgorithmAndKeyMismatch(t *testing.T) {
+	primary := hmacKey(t, "h1")
+	tokens := newTestTokens(t, Config{}, primary, ecKey(t, "e1"))
+	token, _ := tokens.Sign(Claims{Subject: "user-1"})
+
+	tests := []struct {
+		name  string
+		token string
+		want  string
+	}{
+		{"alg none", forge(t, token, header{Alg: "none", Kid: "h1"}), `algorithm "none"`},
+		{"alg of another key", forge(t, token, header{Alg: "ES256", Kid: "h1"}), `algorithm "ES256"`},
+		{"kid of another key", forge(t, token, header{Alg: "ES256", Kid: "e1"}), "signature mismatch"},
+		{"unknown kid", forge(t, token, header{Alg: "HS256", Kid: "h9"}), `unknown key "h9"`},
+		{"malformed", "a.b", "malformed token"},
+		{"bad header", "!." + strings.SplitN(token, ".", 2)[1], "malformed header"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tokens.Verify(tt.token)
+			if !errors.Is(err, ErrInvalidToken) || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Verify() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+
+	// Without kid the primary key is used
+	noKid := forge(t, token, header{Alg: "HS256"})
+	input := noKid[:strings.LastIndex(noKid, ".")]
+	signature, _ := primary.sign([]byte(input))
+	if _, err := tokens.Verify(input + "." + base64.RawURLEncoding.EncodeToString(signature)); err != nil {
+		t.Errorf("Verify() without kid error = %v", err)
+	}
+}
+
+func TestVerifyTimeClaims(t *testing.T) {
+	key := hmacKey(t, "h1")
+	tokens := newTestTokens(t, Config{Leeway: 30 * time.Second}, key)
+	at := func(d time.Duration) *NumericDate { return NewNumericDate(testNow.Add(d)) }
+
+	tests := []struct {
+		name    string
+		claims  Claims
+		wantErr error
+	}{
+		{"valid", Claims{ExpiresAt: at(time.Minute)}, nil},
+		{"expired within leeway", Claims{ExpiresAt: at(-20 * time.Second)}, nil},
+		{"expired", Claims{ExpiresAt: at(-time.Minute)}, ErrTokenExpired},
+		{"nbf within leeway", Claims{ExpiresAt: at(time.Minute), NotBefore: at(20 * time.Second)}, nil},
+		{"nbf in the future", Claims{ExpiresAt: at(time.Hour), NotBefore: at(time.Minute)}, ErrInvalidToken},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := tokens.Sign(tt.claims)
+			if err != nil {
+				t.Fatalf("Sign() error = %v", err)
+			}
+			if _, err := tokens.Verify(token); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Verify() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	// A token without exp is never accepted
+	token, _ := tokens.Sign(Claims{})
+	parts := strings.Split(token, ".")
+	noExp, _ := json.Marshal(Claims{Subject: "user-1"})
+	input := parts[0] + "." + base64.RawURLEncoding.EncodeToString(noExp)
+	signature, _ := key.sign([]byte(input))
+	if _, err := tokens.Verify(input + "." + base64.RawURLEncoding.EncodeToString(signature)); err == nil || !strings.Contains(err.Error(), "exp claim is missing") {
+		t.Errorf("Verify() without exp error = %v", err)
+	}
+}
+
+func TestVerifyIssuerAndAudience(t *testing.T) {
+	key := hmacKey(t, "h1")
+	signer := newTestTokens(t, Config{}, key)
+	verifier := newTestTokens(t, Config{Issuer: "gateway", Audience: []string{"orders", "billing"}}, key)
+
+	tests := []struct {
+		name    string
+		claims  Claims
+		wantErr string
+	}{
+		{"matching", Claims{Issuer: "gateway", Audience: Audience{"web", "billing"}}, ""},
+		{"other issuer", Claims{Issuer: "evil", Audience: Audience{"orders"}}, `unexpected issuer "evil"`},
+		{"no issuer", Claims{Audience: Audience{"orders"}}, "unexpected issuer"},
+		{"other audience", Claims{Issuer: "gateway", Audience: Audience{"web"}}, "unexpected audience"},
+		{"no audience", Claims{Issuer: "gateway"}, "unexpected audience"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, _ := signer.Sign(tt.claims)
+			_, err := verifier.Verify(token)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Verify() error = %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrInvalidToken) || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Verify() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestVerifyES256SignatureLength(t *testing.T) {
+	tokens := newTestTokens(t, Config{}, ecKey(t, "e1"))
+	token, _ := tokens.Sign(Claims{Subject: "user-1"})
+
+	parts := strings.Split(token, ".")
+	signature, _ := base64.RawURLEncoding.DecodeString(parts[2])
+	if len(signature) != 64 {
+		t.Fatalf("signature length = %d, want 64", len(signature))
+	}
+
+	for _, sig := range [][]byte{signature[:63], append(append([]byte{}, signature...), 0), append([]byte{0}, signature...)} {
+		forged := parts[0] + "." + parts[1] + "." + base64.RawURLEncoding.EncodeToString(sig)
+		if _, err := tokens.Verify(forged); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("Verify() with a %d byte signature error = %v", len(sig), err)
+		}
+	}
+}
+
+func TestRotateKeepsOldTokensValid(t *testing.T) {
+	old := hmacKey(t, "h1")
+	tokens := newTestTokens(t, Config{}, old)
+	token, _ := tokens.Sign(Claims{Subject: "user-1"})
+
+	if err := tokens.keys.Rotate(hmacKey(t, "h2")); err != nil {
+		t.Fatalf("Rotate() error = %v", err)
+	}
+	if _, err := tokens.Verify(token); err != nil {
+		t.Errorf("Verify() of a token of the old key error = %v", err)
+	}
+	if err := tokens.keys.Remove("h1"); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+	if _, err := tokens.Verify(token); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Verify() after Remove error = %v, want ErrInvalidToken", err)
+	}
+}
+
+func TestJWKSRoundTrip(t *testing.T) {
+	private, _ := rsa.GenerateKey(rand.Reader, 2048)
+	rsaKey, err := NewRSAKey("r1", private)
+	if err != nil {
+		t.Fatalf("NewRSAKey() error = %v", err)
+	}
+	ec := ecKey(t, "e1")
+	set, _ := NewKeySet(rsaKey, ec, hmacKey(t, "h1"))
+
+	data, err := set.JWKS()
+	if err != nil {
+		t.Fatalf("JWKS() error = %v", err)
+	}
+	if strings.Contains(string(data), `"h1"`) {
+		t.Errorf("JWKS() published an HMAC key: %s", data)
+	}
+
+	keys, err := ParseJWKS(data)
+	if err != nil {
+		t.Fatalf("ParseJWKS() error = %v", err)
+	}
+	if len(keys) != 2 {
+		t.Fatalf("ParseJWKS() returned %d keys, want 2", len(keys))
+	}
+	for _, key := range keys {
+		if key.CanSign() {
+			t.Errorf("key %s from JWKS can sign", key.ID)
+		}
+	}
+
+	verifierSet, _ := NewKeySet(nil, keys...)
+	verifier := New(verifierSet, Config{})
+	verifier.now = func() time.Time { return testNow }
+	for _, primary := range []*Key{rsaKey, ec} {
+		token, _ := newTestTokens(t, Config{}, primary).Sign(Claims{Subject: "user-1"})
+		if claims, err := verifier.Verify(token); err != nil || claims.Subject != "user-1" {
+			t.Errorf("Verify() of a %s token with JWKS keys = %v, %v", primary.Algorithm, claims, err)
+		}
+	}
+}
+
+func TestParseJWKSRejectsWeakRSAKeys(t *testing.T) {
+	private, _ := rsa.GenerateKey(rand.Reader, 1024)
+	b64 := base64.RawURLEncoding
+	jwks := func(n, e []byte) []byte {
+		data, _ := json.Marshal(map[string][]jwk{"keys": {{
+			Kty: "RSA", Kid: "weak", Alg: "RS256", Use: "sig",
+			N: b64.EncodeToString(n), E: b64.EncodeToString(e),
+		}}})
+		return data
+	}
+
+	if _, err := ParseJWKS(jwks(private.N.Bytes(), []byte{1, 0, 1})); err == nil || !strings.Contains(err.Error(), "at least 2048 bits") {
+		t.Errorf("ParseJWKS() of a 1024 bit key error = %v", err)
+	}
+
+	strong, _ := rsa.GenerateKey(rand.Reader, 2048)
+	if _, err := ParseJWKS(jwks(strong.N.Bytes(), []byte{1})); err == nil || !strings.Contains(err.Error(), "invalid exponent") {
+		t.Errorf("ParseJWKS() with e=1 error = %v", err)
+	}
+	if _, err := ParseJWKS(jwks(strong.N.Bytes(), []byte{1, 0, 0, 0, 0, 1})); err == nil {
+		t.Error("ParseJWKS() with an oversized e error = nil")
+	}
+	if _, err := NewPublicKey("weak", &private.PublicKey); err == nil {
+		t.Error("NewPublicKey() of a 1024 bit key error = nil")
+	}
+}
@@ -0,0 +1,384 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+)
+
+// Algorithm is a JWS signing algorithm
+type Algorithm string
+
+// Supported algorithms
+const (
+	HS256 Algorithm = "HS256"
+	RS256 Algorithm = "RS256"
+	ES256 Algorithm = "ES256"
+)
+
+// Key is a signing or verification key identified by kid. Keys created from
+// public keys only verify
+type Key struct {
+	ID        string
+	Algorithm Algorithm
+
+	secret     []byte
+	rsaKey     *rsa.PrivateKey
+	rsaPublic  *rsa.PublicKey
+	ecKey      *ecdsa.PrivateKey
+	ecPublic   *ecdsa.PublicKey
+	verifyOnly bool
+}
+
+// NewHMACKey creates an HS256 key, services sharing it can also mint
+// tokens, so prefer RS256 or ES256 across trust boundaries
+func NewHMACKey(id string, secret []byte) (*Key, error) {
+	if len(secret) < 32 {
+		return nil, fmt.Errorf("HMAC key %q must be at least 32 bytes, got %d", id, len(secret))
+	}
+	return &Key{ID: id, Algorithm: HS256, secret: append([]byte(nil), secret...)}, nil
+}
+
+// minRSABits is the smallest accepted RSA modulus
+const minRSABits = 2048
+
+// NewRSAKey creates an RS256 signing key
+func NewRSAKey(id string, key *rsa.PrivateKey) (*Key, error) {
+	if err := checkRSAKey(id, &key.PublicKey); err != nil {
+		return nil, err
+	}
+	return &Key{ID: id, Algorithm: RS256, rsaKey: key, rsaPublic: &key.PublicKey}, nil
+}
+
+// NewECDSAKey creates an ES256 signing key, the curve must be P-256
+func NewECDSAKey(id string, key *ecdsa.PrivateKey) (*Key, error) {
+	if key.Curve != elliptic.P256() {
+		return nil, fmt.Errorf("ECDSA key %q must use P-256 curve", id)
+	}
+	return &Key{ID: id, Algorithm: ES256, ecKey: key, ecPublic: &key.PublicKey}, nil
+}
+
+// NewPublicKey creates a verification key from an RSA or ECDSA public key
+func NewPublicKey(id string, public crypto.PublicKey) (*Key, error) {
+	switch pub := public.(type) {
+	case *rsa.PublicKey:
+		if err := checkRSAKey(id, pub); err != nil {
+			return nil, err
+		}
+		return &Key{ID: id, Algorithm: RS256, rsaPublic: pub, verifyOnly: true}, nil
+	case *ecdsa.PublicKey:
+		if pub.Curve != elliptic.P256() {
+			return nil, fmt.Errorf("ECDSA key %q must use P-256 curve", id)
+		}
+		return &Key{ID: id, Algorithm: ES256, ecPublic: pub, verifyOnly: true}, nil
+	default:
+		return nil, fmt.Errorf("unsupported public key type %T", public)
+	}
+}
+
+// checkRSAKey rejects short moduli and exponents rsa cannot verify with
+func checkRSAKey(id string, key *rsa.PublicKey) error {
+	if key.N.BitLen() < minRSABits {
+		return fmt.Errorf("RSA key %q must be at least %d bits, got %d", id, minRSABits, key.N.BitLen())
+	}
+	if key.E < 3 || key.E%2 == 0 {
+		return fmt.Errorf("RSA key %q has invalid exponent %d", id, key.E)
+	}
+	return nil
+}
+
+// ParsePEM creates a key from a PEM encoded private key (PKCS#8, PKCS#1 or
+// SEC 1) or public key (PKIX)
+func ParsePEM(id string, data []byte) (*Key, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("failed to decode PEM key %q", id)
+	}
+
+	switch block.Type {
+	case "PUBLIC KEY":
+		public, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse public key %q: %w", id, err)
+		}
+		return NewPublicKey(id, public)
+	case "RSA PRIVATE KEY":
+		key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse RSA key %q: %w", id, err)
+		}
+		return NewRSAKey(id, key)
+	case "EC PRIVATE KEY":
+		key, err := x509.ParseECPrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse ECDSA key %q: %w", id, err)
+		}
+		return NewECDSAKey(id, key)
+	case "PRIVATE KEY":
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse private key %q: %w", id, err)
+		}
+		switch k := key.(type) {
+		case *rsa.PrivateKey:
+			return NewRSAKey(id, k)
+		case *ecdsa.PrivateKey:
+			return NewECDSAKey(id, k)
+		default:
+			return nil, fmt.Errorf("unsupported private key type %T", key)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+}
+
+// CanSign reports whether the key holds a private key or secret
+func (k *Key) CanSign() bool {
+	return !k.verifyOnly
+}
+
+func (k *Key) sign(payload []byte) ([]byte, error) {
+	if k.verifyOnly {
+		return nil, fmt.Errorf("key %q is verification only", k.ID)
+	}
+
+	digest := sha256.Sum256(payload)
+	switch k.Algorithm {
+	case HS256:
+		mac := hmac.New(sha256.New, k.secret)
+		mac.Write(payload)
+		return mac.Sum(nil), nil
+	case RS256:
+		return rsa.SignPKCS1v15(rand.Reader, k.rsaKey, crypto.SHA256, digest[:])
+	case ES256:
+		r, s, err := ecdsa.Sign(rand.Reader, k.ecKey, digest[:])
+		if err != nil {
+			return nil, err
+		}
+		// JWS uses fixed size r || s instead of ASN.1
+		signature := make([]byte, 64)
+		r.FillBytes(signature[:32])
+		s.FillBytes(signature[32:])
+		return signature, nil
+	default:
+		return nil, fmt.Errorf("unsupported algorithm %q", k.Algorithm)
+	}
+}
+
+func (k *Key) verify(payload, signature []byte) bool {
+	digest := sha256.Sum256(payload)
+	switch k.Algorithm {
+	case HS256:
+		mac := hmac.New(sha256.New, k.secret)
+		mac.Write(payload)
+		return hmac.Equal(signature, mac.Sum(nil))
+	case RS256:
+		return rsa.VerifyPKCS1v15(k.rsaPublic, crypto.SHA256, digest[:], signature) == nil
+	case ES256:
+		if len(signature) != 64 {
+			return false
+		}
+		r := new(big.Int).SetBytes(signature[:32])
+		s := new(big.Int).SetBytes(signature[32:])
+		return ecdsa.Verify(k.ecPublic, digest[:], r, s)
+	default:
+		return false
+	}
+}
+
+// KeySet holds keys by kid. The primary key signs new tokens, the rest
+// verify tokens issued before rotation until they expire
+type KeySet struct {
+	mu      sync.RWMutex
+	keys    map[string]*Key
+	primary string
+}
+
+// NewKeySet creates a key set, a nil primary makes a verification only set,
+// e.g. public keys of the gateway in a service
+func NewKeySet(primary *Key, keys ...*Key) (*KeySet, error) {
+	s := &KeySet{keys: make(map[string]*Key)}
+	if primary != nil {
+		keys = append([]*Key{primary}, keys...)
+	}
+	for _, key := range keys {
+		if err := s.add(key); err != nil {
+			return nil, err
+		}
+	}
+	if primary != nil {
+		if !primary.CanSign() {
+			return nil, fmt.Errorf("primary key %q is verification only", primary.ID)
+		}
+		s.primary = primary.ID
+	}
+	return s, nil
+}
+
+// Rotate adds key as the new primary, previous keys keep verifying
+func (s *KeySet) Rotate(key *Key) error {
+	if !key.CanSign() {
+		return fmt.Errorf("primary key %q is verification only", key.ID)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.add(key); err != nil {
+		return err
+	}
+	s.primary = key.ID
+	return nil
+}
+
+// Remove drops a retired key, tokens signed by it stop verifying
+func (s *KeySet) Remove(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if id == s.primary {
+		return fmt.Errorf("key %q is primary and cannot be removed", id)
+	}
+	delete(s.keys, id)
+	return nil
+}
+
+// Primary returns the signing key, nil for verification only sets
+func (s *KeySet) Primary() *Key {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.keys[s.primary]
+}
+
+// Key returns key by kid
+func (s *KeySet) Key(id string) (*Key, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	key, ok := s.keys[id]
+	return key, ok
+}
+
+func (s *KeySet) add(key *Key) error {
+	if key.ID == "" {
+		return errors.New("key ID is required")
+	}
+	if _, ok := s.keys[key.ID]; ok {
+		return fmt.Errorf("key %q is already in the key set", key.ID)
+	}
+	s.keys[key.ID] = key
+	return nil
+}
+
+// jwk is a public JSON Web Key (RFC 7517)
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+// JWKS returns public keys as a JWK set for /.well-known/jwks.json, so
+// services verify tokens without sharing private keys. HMAC keys are
+// never published
+func (s *KeySet) JWKS() ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	set := struct {
+		Keys []jwk `json:"keys"`
+	}{Keys: []jwk{}}
+
+	b64 := base64.RawURLEncoding
+	for _, key := range s.keys {
+		switch key.Algorithm {
+		case RS256:
+			set.Keys = append(set.Keys, jwk{
+				Kty: "RSA", Kid: key.ID, Alg: string(RS256), Use: "sig",
+				N: b64.EncodeToString(key.rsaPublic.N.Bytes()),
+				E: b64.EncodeToString(big.NewInt(int64(key.rsaPublic.E)).Bytes()),
+			})
+		case ES256:
+			x := make([]byte, 32)
+			y := make([]byte, 32)
+			key.ecPublic.X.FillBytes(x)
+			key.ecPublic.Y.FillBytes(y)
+			set.Keys = append(set.Keys, jwk{
+				Kty: "EC", Kid: key.ID, Alg: string(ES256), Use: "sig",
+				Crv: "P-256", X: b64.EncodeToString(x), Y: b64.EncodeToString(y),
+			})
+		}
+	}
+	return json.Marshal(set)
+}
+
+// ParseJWKS returns verification keys of a JWK set, keys of unsupported
+// types are skipped. RSA keys shorter than 2048 bits are rejected
+func ParseJWKS(data []byte) ([]*Key, error) {
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("failed to parse JWKS: %w", err)
+	}
+
+	b64 := base64.RawURLEncoding
+	decode := func(kid, name, value string) (*big.Int, error) {
+		raw, err := b64.DecodeString(value)
+		if err != nil || len(raw) == 0 {
+			return nil, fmt.Errorf("invalid %s of JWK %q", name, kid)
+		}
+		return new(big.Int).SetBytes(raw), nil
+	}
+
+	var keys []*Key
+	for _, k := range set.Keys {
+		switch {
+		case k.Kty == "RSA":
+			n, err := decode(k.Kid, "n", k.N)
+			if err != nil {
+				return nil, err
+			}
+			e, err := decode(k.Kid, "e", k.E)
+			if err != nil {
+				return nil, err
+			}
+			if e.BitLen() > 31 {
+				return nil, fmt.Errorf("invalid e of JWK %q", k.Kid)
+			}
+			public := &rsa.PublicKey{N: n, E: int(e.Int64())}
+			if err := checkRSAKey(k.Kid, public); err != nil {
+				return nil, err
+			}
+			keys = append(keys, &Key{ID: k.Kid, Algorithm: RS256, verifyOnly: true, rsaPublic: public})
+		case k.Kty == "EC" && k.Crv == "P-256":
+			x, err := decode(k.Kid, "x", k.X)
+			if err != nil {
+				return nil, err
+			}
+			y, err := decode(k.Kid, "y", k.Y)
+			if err != nil {
+				return nil, err
+			}
+			keys = append(keys, &Key{ID: k.Kid, Algorithm: ES256, verifyOnly: true,
+				ecPublic: &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}})
+		}
+	}
+	return keys, nil
+}
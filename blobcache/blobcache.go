@@ -0,0 +1,57 @@
+// Package blobcache caches large generated artifacts such as reports and
+// exports. Disk keeps them in a size-capped local directory, Redis shares
+// small ones between instances, both implement Cache
+package blobcache
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrNotFound is returned for missing and expired keys
+var ErrNotFound = errors.New("blobcache: not found")
+
+// Cache stores values by key
+type Cache interface {
+	// Get returns the value of key, ErrNotFound if there is none
+	Get(ctx context.Context, key string) ([]byte, error)
+	// Set stores value for ttl, zero ttl means until evicted
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// Delete removes key, deleting a missing key is not an error
+	Delete(ctx context.Context, key string) error
+}
+
+// Redis is Cache shared by all instances, for values that fit in memory
+type Redis struct {
+	client redis.UniversalClient
+	prefix string
+}
+
+var _ Cache = (*Redis)(nil)
+
+// NewRedis creates Redis cache, keys are stored under prefix
+func NewRedis(client redis.UniversalClient, prefix string) *Redis {
+	return &Redis{client: client, prefix: prefix}
+}
+
+// Get implements Cache
+func (r *Redis) Get(ctx context.Context, key string) ([]byte, error) {
+	value, err := r.client.Get(ctx, r.prefix+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrNotFound
+	}
+	return value, err
+}
+
+// Set implements Cache
+func (r *Redis) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return r.client.Set(ctx, r.prefix+key, value, ttl).Err()
+}
+
+// Delete implements Cache
+func (r *Redis) Delete(ctx context.Context, key string) error {
+	return r.client.Del(ctx, r.prefix+key).Err()
+}
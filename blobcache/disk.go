@@ -0,0 +1,455 @@
+package blobcache
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+)
+
+// ErrTooLarge is returned for values larger than the cache
+var ErrTooLarge = errors.New("blobcache: value exceeds cache size")
+
+// headerSize is the expiry, unix nanoseconds or zero, before the value
+const headerSize = 8
+
+// DiskConfig holds disk cache configuration
+type DiskConfig struct {
+	// Dir holds cached files, it should not be shared with other data
+	Dir string
+	// MaxBytes caps the total size, least recently used values are evicted
+	MaxBytes int64
+}
+
+// DefaultDiskConfig returns default disk cache config
+func DefaultDiskConfig() DiskConfig {
+	return DiskConfig{
+		Dir:      filepath.Join(os.TempDir(), "blobcache"),
+		MaxBytes: 1 << 30,
+	}
+}
+
+// DiskStats are counters of a disk cache
+type DiskStats struct {
+	Entries   int
+	Bytes     int64
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64
+}
+
+type diskEntry struct {
+	name    string
+	size    int64
+	expires time.Time
+}
+
+type fetchCall struct {
+	done chan struct{}
+	err  error
+}
+
+// Disk is Cache in a local directory. Values are written to a temporary
+// file and renamed into place, so readers never see partial values and a
+// crash leaves no corrupt entries. Recency survives restarts through file
+// modification times
+type Disk struct {
+	dir      string
+	maxBytes int64
+
+	mu       sync.Mutex
+	lru      *list.List
+	entries  map[string]*list.Element
+	size     int64
+	inflight map[string]*fetchCall
+
+	hits      uint64
+	misses    uint64
+	evictions uint64
+}
+
+var _ Cache = (*Disk)(nil)
+
+// NewDisk opens the cache directory, indexing values left by a previous run
+func NewDisk(config DiskConfig) (*Disk, error) {
+	defaults := DefaultDiskConfig()
+	if config.Dir == "" {
+		config.Dir = defaults.Dir
+	}
+	if config.MaxBytes <= 0 {
+		config.MaxBytes = defaults.MaxBytes
+	}
+
+	d := &Disk{
+		dir:      config.Dir,
+		maxBytes: config.MaxBytes,
+		lru:      list.New(),
+		entries:  make(map[string]*list.Element),
+		inflight: make(map[string]*fetchCall),
+	}
+
+	// Writes interrupted by a crash are discarded
+	if err := os.RemoveAll(d.tmpDir()); err != nil {
+		return nil, fmt.Errorf("failed to clean blobcache temp dir: %w", err)
+	}
+	if err := os.MkdirAll(d.tmpDir(), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create blobcache dir: %w", err)
+	}
+	if err := d.load(); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// Get implements Cache
+func (d *Disk) Get(ctx context.Context, key string) ([]byte, error) {
+	r, _, err := d.Open(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	value, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cached value: %w", err)
+	}
+	return value, nil
+}
+
+// Set implements Cache
+func (d *Disk) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	_, err := d.Put(ctx, key, bytes.NewReader(value), ttl)
+	return err
+}
+
+// Put streams a value from r, returning its size
+func (d *Disk) Put(ctx context.Context, key string, r io.Reader, ttl time.Duration) (int64, error) {
+	return d.store(ctx, key, ttl, func(_ context.Context, w io.Writer) error {
+		_, err := io.Copy(w, r)
+		return err
+	})
+}
+
+// Open returns a reader of a value and its size, ErrNotFound if there is
+// none. The reader stays valid if the value is evicted meanwhile
+func (d *Disk) Open(_ context.Context, key string) (io.ReadCloser, int64, error) {
+	name := d.name(key)
+
+	d.mu.Lock()
+	elem, ok := d.entries[name]
+	if ok && d.expired(elem.Value.(*diskEntry)) {
+		d.remove(elem)
+		ok = false
+	}
+	if !ok {
+		d.misses++
+		d.mu.Unlock()
+		return nil, 0, ErrNotFound
+	}
+
+	e := elem.Value.(*diskEntry)
+	file, err := os.Open(d.path(name))
+	if err != nil {
+		// Removed behind our back
+		d.remove(elem)
+		d.misses++
+		d.mu.Unlock()
+		return nil, 0, ErrNotFound
+	}
+	d.lru.MoveToFront(elem)
+	d.hits++
+	d.mu.Unlock()
+
+	now := time.Now()
+	_ = os.Chtimes(file.Name(), now, now)
+
+	size := e.size - headerSize
+	return &fileReader{Reader: io.NewSectionReader(file, headerSize, size), file: file}, size, nil
+}
+
+// Fetch returns a cached value, creating it with create on a miss. Callers
+// asking for the same key meanwhile wait for one create instead of
+// generating the artifact again
+func (d *Disk) Fetch(ctx context.Context, key string, ttl time.Duration, create func(ctx context.Context, w io.Writer) error) (io.ReadCloser, int64, error) {
+	for {
+		r, size, err := d.Open(ctx, key)
+		if !errors.Is(err, ErrNotFound) {
+			return r, size, err
+		}
+
+		d.mu.Lock()
+		call, ok := d.inflight[key]
+		if !ok {
+			call = &fetchCall{done: make(chan struct{})}
+			d.inflight[key] = call
+		}
+		d.mu.Unlock()
+
+		if !ok {
+			_, call.err = d.store(ctx, key, ttl, create)
+
+			d.mu.Lock()
+			delete(d.inflight, key)
+			d.mu.Unlock()
+			close(call.done)
+
+			if call.err != nil {
+				return nil, 0, call.err
+			}
+			return d.Open(ctx, key)
+		}
+
+		select {
+		case <-call.done:
+			if call.err != nil {
+				return nil, 0, call.err
+			}
+			// Stored, unless already evicted, then generate again
+		case <-ctx.Done():
+			return nil, 0, ctx.Err()
+		}
+	}
+}
+
+// Delete implements Cache
+func (d *Disk) Delete(_ context.Context, key string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if elem, ok := d.entries[d.name(key)]; ok {
+		d.remove(elem)
+	}
+	return nil
+}
+
+// Stats returns counters
+func (d *Disk) Stats() DiskStats {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return DiskStats{
+		Entries:   len(d.entries),
+		Bytes:     d.size,
+		Hits:      d.hits,
+		Misses:    d.misses,
+		Evictions: d.evictions,
+	}
+}
+
+func (d *Disk) store(ctx context.Context, key string, ttl time.Duration, write func(ctx context.Context, w io.Writer) error) (int64, error) {
+	tmp, err := os.CreateTemp(d.tmpDir(), "put-*")
+	if err != nil {
+		return 0, fmt.Errorf("failed to create cache file: %w", err)
+	}
+	committed := false
+	defer func() {
+		if !committed {
+			tmp.Close()
+			os.Remove(tmp.Name())
+		}
+	}()
+
+	var expires time.Time
+	var header [headerSize]byte
+	if ttl > 0 {
+		expires = time.Now().Add(ttl)
+		binary.BigEndian.PutUint64(header[:], uint64(expires.UnixNano()))
+	}
+	if _, err := tmp.Write(header[:]); err != nil {
+		return 0, fmt.Errorf("failed to write cache file: %w", err)
+	}
+
+	w := &limitedWriter{w: tmp, remaining: d.maxBytes - headerSize}
+	if err := write(ctx, w); err != nil {
+		if w.exceeded {
+			return 0, ErrTooLarge
+		}
+		return 0, fmt.Errorf("failed to write cache file: %w", err)
+	}
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	if err := tmp.Sync(); err != nil {
+		return 0, fmt.Errorf("failed to sync cache file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return 0, fmt.Errorf("failed to close cache file: %w", err)
+	}
+
+	name := d.name(key)
+	e := &diskEntry{name: name, size: headerSize + w.written, expires: expires}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	// Renamed under the lock so eviction of a previous value cannot remove
+	// the new file
+	if err := os.MkdirAll(filepath.Dir(d.path(name)), 0700); err != nil {
+		return 0, fmt.Errorf("failed to create cache dir: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), d.path(name)); err != nil {
+		return 0, fmt.Errorf("failed to store cache file: %w", err)
+	}
+	committed = true
+
+	if elem, ok := d.entries[name]; ok {
+		d.size -= elem.Value.(*diskEntry).size
+		d.lru.Remove(elem)
+	}
+	d.entries[name] = d.lru.PushFront(e)
+	d.size += e.size
+	d.evict()
+
+	return w.written, nil
+}
+
+// load indexes stored values, the most recently used first
+func (d *Disk) load() error {
+	var loaded []*diskEntry
+	var mtimes = make(map[string]time.Time)
+
+	err := filepath.WalkDir(d.dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if path == d.tmpDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		// Files not named by the cache are left alone
+		if name := entry.Name(); len(name) != sha256.Size*2 || d.path(name) != path {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		e, err := readEntry(path, info.Size())
+		if err != nil || d.expired(e) {
+			// Unreadable or expired values are dropped
+			os.Remove(path)
+			return nil
+		}
+		loaded = append(loaded, e)
+		mtimes[e.name] = info.ModTime()
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to load blobcache dir: %w", err)
+	}
+
+	slices.SortFunc(loaded, func(a, b *diskEntry) int {
+		return mtimes[a.name].Compare(mtimes[b.name])
+	})
+	for _, e := range loaded {
+		d.entries[e.name] = d.lru.PushFront(e)
+		d.size += e.size
+	}
+	d.evict()
+	return nil
+}
+
+func readEntry(path string, size int64) (*diskEntry, error) {
+	if size < headerSize {
+		return nil, errors.New("short cache file")
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var header [headerSize]byte
+	if _, err := io.ReadFull(file, header[:]); err != nil {
+		return nil, err
+	}
+
+	e := &diskEntry{name: filepath.Base(path), size: size}
+	if nanos := binary.BigEndian.Uint64(header[:]); nanos != 0 {
+		e.expires = time.Unix(0, int64(nanos))
+	}
+	return e, nil
+}
+
+// evict removes least recently used values until the cache fits, the lock
+// must be held
+func (d *Disk) evict() {
+	for d.size > d.maxBytes {
+		elem := d.lru.Back()
+		if elem == nil {
+			return
+		}
+		d.remove(elem)
+		d.evictions++
+	}
+}
+
+// remove drops an entry and its file, the lock must be held
+func (d *Disk) remove(elem *list.Element) {
+	e := elem.Value.(*diskEntry)
+	d.lru.Remove(elem)
+	delete(d.entries, e.name)
+	d.size -= e.size
+	os.Remove(d.path(e.name))
+}
+
+func (d *Disk) expired(e *diskEntry) bool {
+	return !e.expires.IsZero() && time.Now().After(e.expires)
+}
+
+// name hashes key, so any key is a safe file name
+func (d *Disk) name(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// path shards files by the first byte of the name
+func (d *Disk) path(name string) string {
+	return filepath.Join(d.dir, name[:2], name)
+}
+
+func (d *Disk) tmpDir() string {
+	return filepath.Join(d.dir, "tmp")
+}
+
+type fileReader struct {
+	io.Reader
+	file *os.File
+}
+
+func (r *fileReader) Close() error {
+	return r.file.Close()
+}
+
+// limitedWriter fails writes past the cache size
+type limitedWriter struct {
+	w         io.Writer
+	remaining int64
+	written   int64
+	exceeded  bool
+}
+
+func (l *limitedWriter) Write(p []byte) (int, error) {
+	if int64(len(p)) > l.remaining {
+		l.exceeded = true
+		return 0, ErrTooLarge
+	}
+	n, err := l.w.Write(p)
+	l.remaining -= int64(n)
+	l.written += int64(n)
+	return n, err
+}
@@ -0,0 +1,212 @@
+package blobcache
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestDisk(t *testing.T, dir string, maxBytes int64) *Disk {
+	t.Helper()
+	d, err := NewDisk(DiskConfig{Dir: dir, MaxBytes: maxBytes})
+	if err != nil {
+		t.Fatalf("NewDisk() error = %v", err)
+	}
+	return d
+}
+
+func TestDiskSetGetDelete(t *testing.T) {
+	ctx := context.Background()
+	d := newTestDisk(t, t.TempDir(), 1<<20)
+
+	if _, err := d.Get(ctx, "report"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Get() of a missing key error = %v, want ErrNotFound", err)
+	}
+	if err := d.Set(ctx, "report", []byte("quarterly"), 0); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if err := d.Set(ctx, "report", []byte("yearly"), 0); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	got, err := d.Get(ctx, "report")
+	if err != nil || string(got) != "yearly" {
+		t.Errorf("Get() = %q, %v, want the last value", got, err)
+	}
+
+	stats := d.Stats()
+	if stats.Entries != 1 || stats.Bytes != headerSize+6 || stats.Hits != 1 || stats.Misses != 1 {
+		t.Errorf("Stats() = %+v", stats)
+	}
+
+	if err := d.Delete(ctx, "report"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if err := d.Delete(ctx, "report"); err != nil {
+		t.Errorf("Delete() of a missing key error = %v", err)
+	}
+	if _, err := d.Get(ctx, "report"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get() after Delete() error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestDiskExpiry(t *testing.T) {
+	ctx := context.Background()
+	d := newTestDisk(t, t.TempDir(), 1<<20)
+
+	if err := d.Set(ctx, "short", []byte("v"), 10*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := d.Get(ctx, "short"); err != nil {
+		t.Fatalf("Get() before expiry error = %v", err)
+	}
+	time.Sleep(20 * time.Millisecond)
+	if _, err := d.Get(ctx, "short"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get() after expiry error = %v, want ErrNotFound", err)
+	}
+	if stats := d.Stats(); stats.Entries != 0 || stats.Bytes != 0 {
+		t.Errorf("Stats() = %+v, want the expired entry removed", stats)
+	}
+}
+
+func TestDiskEvictsLeastRecentlyUsed(t *testing.T) {
+	ctx := context.Background()
+	// Room for three values of 8 bytes with their headers
+	d := newTestDisk(t, t.TempDir(), 3*(headerSize+8))
+
+	for _, key := range []string{"a", "b", "c"} {
+		if err := d.Set(ctx, key, []byte("12345678"), 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// a becomes the most recently used, b is evicted next
+	if _, err := d.Get(ctx, "a"); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Set(ctx, "d", []byte("12345678"), 0); err != nil {
+		t.Fatal(err)
+	}
+
+	for key, want := range map[string]bool{"a": true, "b": false, "c": true, "d": true} {
+		if _, err := d.Get(ctx, key); (err == nil) != want {
+			t.Errorf("Get(%q) error = %v, want present = %v", key, err, want)
+		}
+	}
+	if stats := d.Stats(); stats.Evictions != 1 || stats.Bytes != 3*(headerSize+8) {
+		t.Errorf("Stats() = %+v", stats)
+	}
+
+	if err := d.Set(ctx, "huge", make([]byte, 100), 0); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("Set() of a value larger than the cache error = %v, want ErrTooLarge", err)
+	}
+}
+
+func TestDiskOpenSurvivesEviction(t *testing.T) {
+	ctx := context.Background()
+	d := newTestDisk(t, t.TempDir(), 1<<20)
+
+	if _, err := d.Put(ctx, "export", strings.NewReader("csv,data"), 0); err != nil {
+		t.Fatal(err)
+	}
+	r, size, err := d.Open(ctx, "export")
+	if err != nil || size != 8 {
+		t.Fatalf("Open() = %d, %v", size, err)
+	}
+	defer r.Close()
+
+	_ = d.Delete(ctx, "export")
+	if got, err := io.ReadAll(r); err != nil || string(got) != "csv,data" {
+		t.Errorf("read after Delete() = %q, %v", got, err)
+	}
+}
+
+func TestDiskReloadsPreviousRun(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	d := newTestDisk(t, dir, 1<<20)
+	_ = d.Set(ctx, "kept", []byte("value"), time.Hour)
+	_ = d.Set(ctx, "expiring", []byte("value"), 10*time.Millisecond)
+	_ = os.WriteFile(filepath.Join(dir, "tmp", "put-123"), []byte("partial"), 0600)
+	_ = os.WriteFile(filepath.Join(dir, "README"), []byte("not cached"), 0600)
+	time.Sleep(20 * time.Millisecond)
+
+	d = newTestDisk(t, dir, 1<<20)
+	if got, err := d.Get(ctx, "kept"); err != nil || string(got) != "value" {
+		t.Errorf("Get() after reload = %q, %v", got, err)
+	}
+	if _, err := d.Get(ctx, "expiring"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get() of an expired value after reload error = %v", err)
+	}
+	if entries, _ := os.ReadDir(filepath.Join(dir, "tmp")); len(entries) != 0 {
+		t.Errorf("temp files left after reload: %v", entries)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "README")); err != nil {
+		t.Errorf("foreign file removed: %v", err)
+	}
+
+	// A smaller limit evicts on load
+	d = newTestDisk(t, dir, 4)
+	if stats := d.Stats(); stats.Entries != 0 || stats.Evictions != 1 {
+		t.Errorf("Stats() after reload with a smaller limit = %+v", stats)
+	}
+}
+
+func TestDiskFetchCreatesOnce(t *testing.T) {
+	ctx := context.Background()
+	d := newTestDisk(t, t.TempDir(), 1<<20)
+
+	var creates atomic.Int32
+	release := make(chan struct{})
+	create := func(ctx context.Context, w io.Writer) error {
+		creates.Add(1)
+		<-release
+		_, err := io.WriteString(w, "generated")
+		return err
+	}
+
+	var wg sync.WaitGroup
+	results := make([]string, 5)
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r, _, err := d.Fetch(ctx, "report", time.Minute, create)
+			if err != nil {
+				t.Errorf("Fetch() error = %v", err)
+				return
+			}
+			defer r.Close()
+			value, _ := io.ReadAll(r)
+			results[i] = string(value)
+		}()
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if creates.Load() != 1 {
+		t.Errorf("create called %d times, want once", creates.Load())
+	}
+	for i, got := range results {
+		if got != "generated" {
+			t.Errorf("results[%d] = %q", i, got)
+		}
+	}
+
+	// Errors are not cached
+	errCreate := errors.New("generator failed")
+	_, _, err := d.Fetch(ctx, "failing", 0, func(context.Context, io.Writer) error { return errCreate })
+	if !errors.Is(err, errCreate) {
+		t.Errorf("Fetch() error = %v, want the create error", err)
+	}
+	if _, err := d.Get(ctx, "failing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get() after a failed Fetch() error = %v", err)
+	}
+}
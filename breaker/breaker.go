@@ -0,0 +1,343 @@
+// Package breaker is a circuit breaker with the sony/gobreaker API, shared by
+// httpclient, messaging and repository code so every dependency reports its
+// state the same way
+package breaker
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// State is a circuit breaker state
+type State int
+
+const (
+	// StateClosed passes requests and counts failures
+	StateClosed State = iota
+	// StateHalfOpen passes MaxRequests probes after Timeout
+	StateHalfOpen
+	// StateOpen rejects requests until Timeout passes
+	StateOpen
+)
+
+// String implements fmt.Stringer
+func (s State) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateHalfOpen:
+		return "half-open"
+	case StateOpen:
+		return "open"
+	default:
+		return fmt.Sprintf("unknown state: %d", int(s))
+	}
+}
+
+var (
+	// ErrOpenState is returned when the breaker is open
+	ErrOpenState = errors.New("circuit breaker is open")
+	// ErrTooManyRequests is returned when the breaker is half-open and all
+	// probes are taken
+	ErrTooManyRequests = errors.New("too many requests")
+)
+
+// Counts are requests of the current generation, cleared on state change
+// and every Interval in the closed state
+type Counts struct {
+	Requests             uint32
+	TotalSuccesses       uint32
+	TotalFailures        uint32
+	ConsecutiveSuccesses uint32
+	ConsecutiveFailures  uint32
+}
+
+func (c *Counts) onRequest() {
+	c.Requests++
+}
+
+func (c *Counts) onSuccess() {
+	c.TotalSuccesses++
+	c.ConsecutiveSuccesses++
+	c.ConsecutiveFailures = 0
+}
+
+func (c *Counts) onFailure() {
+	c.TotalFailures++
+	c.ConsecutiveFailures++
+	c.ConsecutiveSuccesses = 0
+}
+
+// Result of a request for Metrics
+type Result string
+
+const (
+	ResultSuccess  Result = "success"
+	ResultFailure  Result = "failure"
+	ResultRejected Result = "rejected"
+)
+
+// Metrics records breaker activity (implemented by metrics.BreakerMetrics)
+type Metrics interface {
+	// ObserveState is called with the initial state and on every change
+	ObserveState(name string, from, to State)
+	ObserveRequest(name string, result Result)
+}
+
+// Settings configures a circuit breaker
+type Settings struct {
+	Name string
+	// MaxRequests is how many probes pass in the half-open state, the
+	// breaker closes when all of them succeed. Defaults to 1
+	MaxRequests uint32
+	// Interval clears counts in the closed state, 0 never clears them
+	Interval time.Duration
+	// Timeout is how long the breaker stays open, defaults to 60 seconds
+	Timeout time.Duration
+	// ReadyToTrip opens the breaker after a failure in the closed state,
+	// defaults to more than 5 consecutive failures
+	ReadyToTrip func(counts Counts) bool
+	// OnStateChange is called under the breaker lock, it must not call the
+	// breaker
+	OnStateChange func(name string, from, to State)
+	// IsSuccessful decides whether an error counts as a failure, defaults
+	// to err == nil. Return true for errors of the caller, e.g. validation
+	IsSuccessful func(err error) bool
+	Metrics      Metrics // Optional
+}
+
+// CircuitBreaker stops calling a failing dependency for a while
+type CircuitBreaker struct {
+	name          string
+	maxRequests   uint32
+	interval      time.Duration
+	timeout       time.Duration
+	readyToTrip   func(counts Counts) bool
+	isSuccessful  func(err error) bool
+	onStateChange func(name string, from, to State)
+	metrics       Metrics
+	now           func() time.Time
+
+	mu         sync.Mutex
+	state      State
+	generation uint64
+	counts     Counts
+	expiry     time.Time
+}
+
+// NewCircuitBreaker creates a circuit breaker
+func NewCircuitBreaker(st Settings) *CircuitBreaker {
+	return newCircuitBreaker(st, time.Now)
+}
+
+func newCircuitBreaker(st Settings, now func() time.Time) *CircuitBreaker {
+	cb := &CircuitBreaker{
+		name:          st.Name,
+		maxRequests:   st.MaxRequests,
+		interval:      st.Interval,
+		timeout:       st.Timeout,
+		readyToTrip:   st.ReadyToTrip,
+		isSuccessful:  st.IsSuccessful,
+		onStateChange: st.OnStateChange,
+		metrics:       st.Metrics,
+		now:           now,
+	}
+
+	if cb.maxRequests == 0 {
+		cb.maxRequests = 1
+	}
+	if cb.timeout <= 0 {
+		cb.timeout = 60 * time.Second
+	}
+	if cb.readyToTrip == nil {
+		cb.readyToTrip = func(counts Counts) bool {
+			return counts.ConsecutiveFailures > 5
+		}
+	}
+	if cb.isSuccessful == nil {
+		cb.isSuccessful = func(err error) bool {
+			return err == nil
+		}
+	}
+
+	cb.toNewGeneration(cb.now())
+	if cb.metrics != nil {
+		cb.metrics.ObserveState(cb.name, StateClosed, StateClosed)
+	}
+	return cb
+}
+
+// Name returns the breaker name
+func (cb *CircuitBreaker) Name() string {
+	return cb.name
+}
+
+// State returns the current state
+func (cb *CircuitBreaker) State() State {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	state, _ := cb.currentState(cb.now())
+	return state
+}
+
+// Counts returns counts of the current generation
+func (cb *CircuitBreaker) Counts() Counts {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	return cb.counts
+}
+
+// Execute runs req if the breaker allows it, otherwise returns ErrOpenState
+// or ErrTooManyRequests. A panic of req counts as a failure and is
+// re-raised
+func (cb *CircuitBreaker) Execute(req func() (interface{}, error)) (interface{}, error) {
+	generation, err := cb.beforeRequest()
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		if e := recover(); e != nil {
+			cb.afterRequest(generation, false)
+			panic(e)
+		}
+	}()
+
+	result, err := req()
+	cb.afterRequest(generation, cb.isSuccessful(err))
+	return result, err
+}
+
+// Allow checks the breaker for a request reported later, e.g. an HTTP
+// response judged by status. done must be called once with the outcome
+func (cb *CircuitBreaker) Allow() (done func(success bool), err error) {
+	generation, err := cb.beforeRequest()
+	if err != nil {
+		return nil, err
+	}
+
+	return func(success bool) {
+		cb.afterRequest(generation, success)
+	}, nil
+}
+
+func (cb *CircuitBreaker) beforeRequest() (uint64, error) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	state, generation := cb.currentState(cb.now())
+	if state == StateOpen {
+		cb.observe(ResultRejected)
+		return generation, ErrOpenState
+	}
+	if state == StateHalfOpen && cb.counts.Requests >= cb.maxRequests {
+		cb.observe(ResultRejected)
+		return generation, ErrTooManyRequests
+	}
+
+	cb.counts.onRequest()
+	return generation, nil
+}
+
+func (cb *CircuitBreaker) afterRequest(before uint64, success bool) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if success {
+		cb.observe(ResultSuccess)
+	} else {
+		cb.observe(ResultFailure)
+	}
+
+	now := cb.now()
+	state, generation := cb.currentState(now)
+	// The result of a request from a previous generation is not counted
+	if generation != before {
+		return
+	}
+
+	if success {
+		cb.onSuccess(state, now)
+	} else {
+		cb.onFailure(state, now)
+	}
+}
+
+func (cb *CircuitBreaker) onSuccess(state State, now time.Time) {
+	cb.counts.onSuccess()
+	if state == StateHalfOpen && cb.counts.ConsecutiveSuccesses >= cb.maxRequests {
+		cb.setState(StateClosed, now)
+	}
+}
+
+func (cb *CircuitBreaker) onFailure(state State, now time.Time) {
+	switch state {
+	case StateClosed:
+		cb.counts.onFailure()
+		if cb.readyToTrip(cb.counts) {
+			cb.setState(StateOpen, now)
+		}
+	case StateHalfOpen:
+		cb.setState(StateOpen, now)
+	}
+}
+
+// currentState moves to a new generation or state when expiry passed
+func (cb *CircuitBreaker) currentState(now time.Time) (State, uint64) {
+	switch cb.state {
+	case StateClosed:
+		if !cb.expiry.IsZero() && cb.expiry.Before(now) {
+			cb.toNewGeneration(now)
+		}
+	case StateOpen:
+		if cb.expiry.Before(now) {
+			cb.setState(StateHalfOpen, now)
+		}
+	}
+	return cb.state, cb.generation
+}
+
+func (cb *CircuitBreaker) setState(state State, now time.Time) {
+	if cb.state == state {
+		return
+	}
+
+	prev := cb.state
+	cb.state = state
+	cb.toNewGeneration(now)
+
+	if cb.onStateChange != nil {
+		cb.onStateChange(cb.name, prev, state)
+	}
+	if cb.metrics != nil {
+		cb.metrics.ObserveState(cb.name, prev, state)
+	}
+}
+
+func (cb *CircuitBreaker) toNewGeneration(now time.Time) {
+	cb.generation++
+	cb.counts = Counts{}
+
+	switch cb.state {
+	case StateClosed:
+		if cb.interval == 0 {
+			cb.expiry = time.Time{}
+		} else {
+			cb.expiry = now.Add(cb.interval)
+		}
+	case StateOpen:
+		cb.expiry = now.Add(cb.timeout)
+	default:
+		cb.expiry = time.Time{}
+	}
+}
+
+func (cb *CircuitBreaker) observe(result Result) {
+	if cb.metrics != nil {
+		cb.metrics.ObserveRequest(cb.name, result)
+	}
+}
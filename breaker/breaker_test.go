@@ -0,0 +1,309 @@
+package breaker
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+var errDown = errors.New("down")
+
+// clock is a manually advanced time source
+type clock struct {
+	now time.Time
+}
+
+func (c *clock) Now() time.Time { return c.now }
+
+func (c *clock) Advance(d time.Duration) { c.now = c.now.Add(d) }
+
+type transition struct {
+	from, to State
+}
+
+// recorder collects OnStateChange calls and Metrics observations
+type recorder struct {
+	changes []transition
+	states  []transition
+	results map[Result]int
+}
+
+func (r *recorder) onStateChange(_ string, from, to State) {
+	r.changes = append(r.changes, transition{from, to})
+}
+
+func (r *recorder) ObserveState(_ string, from, to State) {
+	r.states = append(r.states, transition{from, to})
+}
+
+func (r *recorder) ObserveRequest(_ string, result Result) {
+	if r.results == nil {
+		r.results = make(map[Result]int)
+	}
+	r.results[result]++
+}
+
+func newTestBreaker(st Settings) (*CircuitBreaker, *clock, *recorder) {
+	clk := &clock{now: time.Unix(1_700_000_000, 0)}
+	rec := &recorder{}
+	st.Name = "test"
+	st.OnStateChange = rec.onStateChange
+	st.Metrics = rec
+	if st.Timeout == 0 {
+		st.Timeout = 10 * time.Second
+	}
+	if st.ReadyToTrip == nil {
+		st.ReadyToTrip = func(counts Counts) bool {
+			return counts.ConsecutiveFailures >= 3
+		}
+	}
+	return newCircuitBreaker(st, clk.Now), clk, rec
+}
+
+func succeed() (interface{}, error) { return "ok", nil }
+
+func fail() (interface{}, error) { return nil, errDown }
+
+func TestTransitions(t *testing.T) {
+	cb, clk, rec := newTestBreaker(Settings{})
+
+	for i := 0; i < 2; i++ {
+		if _, err := cb.Execute(fail); err != errDown {
+			t.Fatalf("failure %d: err = %v", i, err)
+		}
+	}
+	if cb.State() != StateClosed {
+		t.Fatalf("state = %s after 2 failures, want closed", cb.State())
+	}
+
+	cb.Execute(fail)
+	if cb.State() != StateOpen {
+		t.Fatalf("state = %s after 3 failures, want open", cb.State())
+	}
+	if _, err := cb.Execute(succeed); err != ErrOpenState {
+		t.Fatalf("open: err = %v, want ErrOpenState", err)
+	}
+
+	clk.Advance(9 * time.Second)
+	if cb.State() != StateOpen {
+		t.Fatalf("state = %s before timeout, want open", cb.State())
+	}
+	clk.Advance(2 * time.Second)
+	if cb.State() != StateHalfOpen {
+		t.Fatalf("state = %s after timeout, want half-open", cb.State())
+	}
+
+	if res, err := cb.Execute(succeed); err != nil || res != "ok" {
+		t.Fatalf("probe: res = %v, err = %v", res, err)
+	}
+	if cb.State() != StateClosed {
+		t.Fatalf("state = %s after successful probe, want closed", cb.State())
+	}
+	if counts := cb.Counts(); counts != (Counts{}) {
+		t.Errorf("counts = %+v after closing, want cleared", counts)
+	}
+
+	want := []transition{
+		{StateClosed, StateOpen},
+		{StateOpen, StateHalfOpen},
+		{StateHalfOpen, StateClosed},
+	}
+	assertTransitions(t, "OnStateChange", rec.changes, want)
+	assertTransitions(t, "ObserveState", rec.states, append([]transition{{StateClosed, StateClosed}}, want...))
+
+	if rec.results[ResultFailure] != 3 || rec.results[ResultSuccess] != 1 || rec.results[ResultRejected] != 1 {
+		t.Errorf("results = %v", rec.results)
+	}
+}
+
+func TestHalfOpenFailureReopens(t *testing.T) {
+	cb, clk, rec := newTestBreaker(Settings{})
+	for i := 0; i < 3; i++ {
+		cb.Execute(fail)
+	}
+	clk.Advance(11 * time.Second)
+
+	if _, err := cb.Execute(fail); err != errDown {
+		t.Fatalf("probe: err = %v", err)
+	}
+	if cb.State() != StateOpen {
+		t.Fatalf("state = %s after failed probe, want open", cb.State())
+	}
+
+	// The timeout restarts from the failed probe
+	clk.Advance(9 * time.Second)
+	if cb.State() != StateOpen {
+		t.Fatalf("state = %s before the new timeout, want open", cb.State())
+	}
+	clk.Advance(2 * time.Second)
+	if cb.State() != StateHalfOpen {
+		t.Fatalf("state = %s after the new timeout, want half-open", cb.State())
+	}
+
+	assertTransitions(t, "OnStateChange", rec.changes, []transition{
+		{StateClosed, StateOpen},
+		{StateOpen, StateHalfOpen},
+		{StateHalfOpen, StateOpen},
+		{StateOpen, StateHalfOpen},
+	})
+}
+
+func TestHalfOpenRequestLimit(t *testing.T) {
+	cb, clk, _ := newTestBreaker(Settings{MaxRequests: 2})
+	for i := 0; i < 3; i++ {
+		cb.Execute(fail)
+	}
+	clk.Advance(11 * time.Second)
+
+	first, err := cb.Allow()
+	if err != nil {
+		t.Fatalf("probe 1: %v", err)
+	}
+	second, err := cb.Allow()
+	if err != nil {
+		t.Fatalf("probe 2: %v", err)
+	}
+	if _, err := cb.Allow(); err != ErrTooManyRequests {
+		t.Fatalf("probe 3: err = %v, want ErrTooManyRequests", err)
+	}
+
+	first(true)
+	if cb.State() != StateHalfOpen {
+		t.Fatalf("state = %s after 1 of 2 probes, want half-open", cb.State())
+	}
+	// Probes in flight still hold their slots
+	if _, err := cb.Execute(succeed); err != ErrTooManyRequests {
+		t.Fatalf("err = %v with all probes taken, want ErrTooManyRequests", err)
+	}
+
+	second(true)
+	if cb.State() != StateClosed {
+		t.Fatalf("state = %s after 2 of 2 probes, want closed", cb.State())
+	}
+}
+
+func TestIntervalClearsCounts(t *testing.T) {
+	cb, clk, _ := newTestBreaker(Settings{Interval: time.Minute})
+
+	cb.Execute(fail)
+	cb.Execute(fail)
+	clk.Advance(61 * time.Second)
+	if counts := cb.Counts(); counts.ConsecutiveFailures != 2 {
+		t.Fatalf("counts = %+v, cleared only on the next request", counts)
+	}
+
+	cb.Execute(fail)
+	if cb.State() != StateClosed {
+		t.Fatalf("state = %s, failures of the previous interval must not count", cb.State())
+	}
+	if counts := cb.Counts(); counts.Requests != 1 || counts.ConsecutiveFailures != 1 {
+		t.Errorf("counts = %+v, want 1 request and 1 failure", counts)
+	}
+}
+
+func TestStaleGenerationResultIgnored(t *testing.T) {
+	cb, clk, _ := newTestBreaker(Settings{})
+
+	done, err := cb.Allow()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		cb.Execute(fail)
+	}
+	clk.Advance(11 * time.Second)
+
+	// Started while closed, finished while half-open: not a probe result
+	done(true)
+	if cb.State() != StateHalfOpen {
+		t.Fatalf("state = %s, want half-open", cb.State())
+	}
+	if counts := cb.Counts(); counts != (Counts{}) {
+		t.Errorf("counts = %+v, want empty", counts)
+	}
+}
+
+func TestIsSuccessful(t *testing.T) {
+	errInvalid := errors.New("invalid")
+	cb, _, _ := newTestBreaker(Settings{
+		IsSuccessful: func(err error) bool {
+			return err == nil || err == errInvalid
+		},
+	})
+
+	for i := 0; i < 5; i++ {
+		if _, err := cb.Execute(func() (interface{}, error) { return nil, errInvalid }); err != errInvalid {
+			t.Fatalf("err = %v, want the request error", err)
+		}
+	}
+	if cb.State() != StateClosed {
+		t.Errorf("state = %s, successful errors must not trip the breaker", cb.State())
+	}
+	if counts := cb.Counts(); counts.TotalSuccesses != 5 {
+		t.Errorf("counts = %+v, want 5 successes", counts)
+	}
+}
+
+func TestPanicCountsAsFailure(t *testing.T) {
+	cb, _, _ := newTestBreaker(Settings{
+		ReadyToTrip: func(counts Counts) bool { return counts.ConsecutiveFailures >= 1 },
+	})
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Fatal("panic must be re-raised")
+			}
+		}()
+		cb.Execute(func() (interface{}, error) { panic("boom") })
+	}()
+
+	if cb.State() != StateOpen {
+		t.Errorf("state = %s after panic, want open", cb.State())
+	}
+}
+
+func TestDefaults(t *testing.T) {
+	clk := &clock{now: time.Unix(1_700_000_000, 0)}
+	cb := newCircuitBreaker(Settings{}, clk.Now)
+
+	for i := 0; i < 5; i++ {
+		cb.Execute(fail)
+	}
+	if cb.State() != StateClosed {
+		t.Fatalf("state = %s after 5 failures, default trips after more than 5", cb.State())
+	}
+	cb.Execute(fail)
+	if cb.State() != StateOpen {
+		t.Fatalf("state = %s after 6 failures, want open", cb.State())
+	}
+
+	clk.Advance(59 * time.Second)
+	if cb.State() != StateOpen {
+		t.Fatalf("state = %s before the default 60s timeout, want open", cb.State())
+	}
+	clk.Advance(2 * time.Second)
+	if cb.State() != StateHalfOpen {
+		t.Fatalf("state = %s after the default timeout, want half-open", cb.State())
+	}
+
+	// Default MaxRequests is 1
+	if _, err := cb.Allow(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cb.Allow(); err != ErrTooManyRequests {
+		t.Errorf("err = %v, want ErrTooManyRequests", err)
+	}
+}
+
+func assertTransitions(t *testing.T, name string, got, want []transition) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("%s: got %v, want %v", name, got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("%s: got %v, want %v", name, got, want)
+		}
+	}
+}
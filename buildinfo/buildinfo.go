@@ -0,0 +1,93 @@
+// Package buildinfo describes the running binary. Values set with ldflags
+// win, the rest comes from build info Go embeds for module builds:
+//
+//	go build -ldflags "-X github.com/alimzhanovlr/sdk/buildinfo.Version=v1.2.3 \
+//		-X github.com/alimzhanovlr/sdk/buildinfo.Commit=$(git rev-parse HEAD) \
+//		-X github.com/alimzhanovlr/sdk/buildinfo.BuildDate=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+package buildinfo
+
+import (
+	"runtime"
+	"runtime/debug"
+	"sync"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Set with -ldflags "-X"
+var (
+	Version   string
+	Commit    string
+	BuildDate string
+)
+
+// Info is build metadata of the binary
+type Info struct {
+	// Version is empty for local builds without ldflags
+	Version string `json:"version"`
+	Commit  string `json:"commit,omitempty"`
+	// BuildDate falls back to the commit time without ldflags
+	BuildDate string `json:"build_date,omitempty"`
+	// Modified reports uncommitted changes in the built tree
+	Modified  bool   `json:"modified,omitempty"`
+	GoVersion string `json:"go_version"`
+	// Module is the main module path
+	Module string `json:"module,omitempty"`
+}
+
+var get = sync.OnceValue(read)
+
+// Get returns build metadata, read once
+func Get() Info {
+	return get()
+}
+
+func read() Info {
+	info := Info{
+		Version:   Version,
+		Commit:    Commit,
+		BuildDate: BuildDate,
+		GoVersion: runtime.Version(),
+	}
+
+	build, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+	info.Module = build.Main.Path
+	if info.Version == "" && build.Main.Version != "(devel)" {
+		info.Version = build.Main.Version
+	}
+
+	// Set by go build inside a VCS checkout
+	for _, setting := range build.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			if info.Commit == "" {
+				info.Commit = setting.Value
+			}
+		case "vcs.time":
+			if info.BuildDate == "" {
+				info.BuildDate = setting.Value
+			}
+		case "vcs.modified":
+			info.Modified = setting.Value == "true"
+		}
+	}
+	return info
+}
+
+// ShortCommit returns the first 12 characters of the commit
+func (i Info) ShortCommit() string {
+	if len(i.Commit) > 12 {
+		return i.Commit[:12]
+	}
+	return i.Commit
+}
+
+// Handler serves build metadata, the server mounts it on /version
+func Handler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		return c.JSON(Get())
+	}
+}
@@ -0,0 +1,80 @@
+package buildinfo
+
+import (
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"runtime"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// setLdflags sets the variables as -X would and restores them after the test
+func setLdflags(t *testing.T, version, commit, date string) {
+	t.Helper()
+	old := [3]string{Version, Commit, BuildDate}
+	Version, Commit, BuildDate = version, commit, date
+	t.Cleanup(func() { Version, Commit, BuildDate = old[0], old[1], old[2] })
+}
+
+func TestReadPrefersLdflags(t *testing.T) {
+	setLdflags(t, "v1.2.3", "0123456789abcdef0123", "2025-06-01T10:00:00Z")
+
+	info := read()
+	if info.Version != "v1.2.3" || info.Commit != "0123456789abcdef0123" || info.BuildDate != "2025-06-01T10:00:00Z" {
+		t.Errorf("read() = %+v, want the ldflags values", info)
+	}
+	if info.GoVersion != runtime.Version() {
+		t.Errorf("GoVersion = %q, want %q", info.GoVersion, runtime.Version())
+	}
+}
+
+func TestReadWithoutLdflags(t *testing.T) {
+	setLdflags(t, "", "", "")
+
+	// Test binaries are built without a module version
+	if info := read(); info.Version == "(devel)" {
+		t.Errorf("Version = %q, want the devel placeholder dropped", info.Version)
+	}
+}
+
+func TestShortCommit(t *testing.T) {
+	tests := []struct {
+		commit string
+		want   string
+	}{
+		{"0123456789abcdef0123", "0123456789ab"},
+		{"0123456789ab", "0123456789ab"},
+		{"abc", "abc"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := (Info{Commit: tt.commit}).ShortCommit(); got != tt.want {
+			t.Errorf("ShortCommit() of %q = %q, want %q", tt.commit, got, tt.want)
+		}
+	}
+}
+
+func TestHandler(t *testing.T) {
+	app := fiber.New()
+	app.Get("/version", Handler())
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/version", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+
+	var got map[string]interface{}
+	if err := json.Unmarshal(body, &got); err != nil {
+		t.Fatalf("body %s is not JSON: %v", body, err)
+	}
+	if got["go_version"] != runtime.Version() {
+		t.Errorf("body = %s, want go_version", body)
+	}
+	if _, ok := got["version"]; !ok {
+		t.Errorf("body = %s, want version even when empty", body)
+	}
+}
@@ -0,0 +1,427 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/alimzhanovlr/sdk/config"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+func newDoctorCmd() *cobra.Command {
+	var configPath string
+
+	cmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Diagnose project structure, imports, routes and config",
+		Long: `Checks that imports match go.mod, generated handlers are wired,
+and config.yaml matches the SDK config. Must be run from the project root.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runDoctor(configPath)
+		},
+	}
+
+	cmd.Flags().StringVar(&configPath, "config", "config/config.yaml", "Path to config file")
+
+	return cmd
+}
+
+// diagnosis is a doctor check result
+type diagnosis struct {
+	problems []string
+	warnings []string
+}
+
+func (d *diagnosis) problem(format string, args ...interface{}) {
+	d.problems = append(d.problems, fmt.Sprintf(format, args...))
+}
+
+func (d *diagnosis) warn(format string, args ...interface{}) {
+	d.warnings = append(d.warnings, fmt.Sprintf(format, args...))
+}
+
+func runDoctor(configPath string) error {
+	mod, err := readGoMod("go.mod")
+	if err != nil {
+		return fmt.Errorf("go.mod not found, run the command from the project root: %w", err)
+	}
+
+	files, err := parseProjectFiles(".")
+	if err != nil {
+		return err
+	}
+
+	checks := []struct {
+		name string
+		run  func(d *diagnosis)
+	}{
+		{"Imports match go.mod", func(d *diagnosis) { checkImports(d, mod, files) }},
+		{"Handlers are wired", func(d *diagnosis) { checkHandlers(d, files) }},
+		{"Config " + configPath, func(d *diagnosis) { checkConfig(d, configPath) }},
+	}
+
+	failed := 0
+	for _, check := range checks {
+		var d diagnosis
+		check.run(&d)
+
+		switch {
+		case len(d.problems) > 0:
+			fmt.Printf("❌ %s\n", check.name)
+			failed++
+		case len(d.warnings) > 0:
+			fmt.Printf("⚠️  %s\n", check.name)
+		default:
+			fmt.Printf("✅ %s\n", check.name)
+		}
+		for _, p := range d.problems {
+			fmt.Printf("   - %s\n", p)
+		}
+		for _, w := range d.warnings {
+			fmt.Printf("   - %s\n", w)
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d checks failed", failed, len(checks))
+	}
+	return nil
+}
+
+// goMod is the part of go.mod used by doctor
+type goMod struct {
+	module   string
+	requires []string
+}
+
+// readGoMod reads module and required module paths, replace directives are not needed
+func readGoMod(path string) (*goMod, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	mod := &goMod{}
+	inRequire := false
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(strings.SplitN(scanner.Text(), "//", 2)[0])
+		fields := strings.Fields(line)
+		switch {
+		case len(fields) == 0:
+		case fields[0] == "module" && len(fields) > 1:
+			mod.module = strings.Trim(fields[1], `"`)
+		case line == "require (":
+			inRequire = true
+		case inRequire && line == ")":
+			inRequire = false
+		case inRequire:
+			mod.requires = append(mod.requires, fields[0])
+		case fields[0] == "require" && len(fields) > 1:
+			mod.requires = append(mod.requires, fields[1])
+		}
+	}
+	return mod, scanner.Err()
+}
+
+// parseProjectFiles parses Go files of the project except vendor and hidden directories
+func parseProjectFiles(root string) (map[string]*ast.File, error) {
+	files := map[string]*ast.File{}
+	fset := token.NewFileSet()
+
+	err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			name := entry.Name()
+			if path != root && (name == "vendor" || name == "testdata" || strings.HasPrefix(name, ".")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(path, ".go") {
+			return nil
+		}
+
+		file, err := parser.ParseFile(fset, path, nil, parser.SkipObjectResolution)
+		if err != nil {
+			return fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		files[path] = file
+		return nil
+	})
+	return files, err
+}
+
+// checkImports reports non-standard imports that are neither in the module nor required by go.mod
+func checkImports(d *diagnosis, mod *goMod, files map[string]*ast.File) {
+	for _, path := range sortedKeys(files) {
+		for _, spec := range files[path].Imports {
+			importPath, _ := strconv.Unquote(spec.Path.Value)
+			// Standard library
+			if !strings.Contains(strings.Split(importPath, "/")[0], ".") && !strings.HasPrefix(importPath, "your-module") {
+				continue
+			}
+			if importPath == mod.module || strings.HasPrefix(importPath, mod.module+"/") || requiredBy(mod, importPath) {
+				continue
+			}
+
+			if rest, ok := localImportSuffix(importPath); ok {
+				d.problem("%s imports %q, fix: replace with %q", path, importPath, mod.module+rest)
+				continue
+			}
+			d.problem("%s imports %q which is not required in go.mod, fix: go get %s", path, importPath, importPath)
+		}
+	}
+}
+
+func requiredBy(mod *goMod, importPath string) bool {
+	for _, req := range mod.requires {
+		if importPath == req || strings.HasPrefix(importPath, req+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// localImportSuffix detects project imports with a wrong module prefix, e.g.
+// "your-module/internal/domain/entity" from a project generated before go.mod existed
+func localImportSuffix(importPath string) (string, bool) {
+	if strings.HasPrefix(importPath, "your-module/") {
+		return strings.TrimPrefix(importPath, "your-module"), true
+	}
+	if i := strings.Index(importPath, "/internal/"); i >= 0 {
+		return importPath[i:], true
+	}
+	return "", false
+}
+
+// checkHandlers reports types with RegisterRoutes whose constructor is never called
+func checkHandlers(d *diagnosis, files map[string]*ast.File) {
+	// Handler type -> file defining RegisterRoutes
+	handlers := map[string]string{}
+	for path, file := range files {
+		for _, decl := range file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Recv == nil || fn.Name.Name != "RegisterRoutes" || len(fn.Recv.List) == 0 {
+				continue
+			}
+			recv := fn.Recv.List[0].Type
+			if star, ok := recv.(*ast.StarExpr); ok {
+				recv = star.X
+			}
+			if ident, ok := recv.(*ast.Ident); ok {
+				handlers[ident.Name] = path
+			}
+		}
+	}
+
+	// Names referenced outside of the defining file
+	used := map[string]bool{}
+	for path, file := range files {
+		ast.Inspect(file, func(n ast.Node) bool {
+			switch x := n.(type) {
+			case *ast.SelectorExpr:
+				used[path+"\x00"+x.Sel.Name] = true
+			case *ast.Ident:
+				used[path+"\x00"+x.Name] = true
+			}
+			return true
+		})
+	}
+
+	for _, name := range sortedKeys(handlers) {
+		constructor := "New" + name
+		wired := false
+		for path := range files {
+			if path != handlers[name] && !strings.HasSuffix(path, "_test.go") && used[path+"\x00"+constructor] {
+				wired = true
+				break
+			}
+		}
+		if !wired {
+			pkg := files[handlers[name]].Name.Name
+			d.problem("%s is not constructed, so its routes are not registered, fix: fx.Provide(%s.%s) and call RegisterRoutes(srv.App()) in an fx.Invoke (%s)",
+				name, pkg, constructor, handlers[name])
+		}
+	}
+}
+
+// configEnum is a config field restricted by an enum tag, like in config.Schema
+type configEnum struct {
+	key     string
+	allowed []string
+	value   string
+}
+
+// configEnums collects string fields with an enum tag, keys are dotted
+// mapstructure names
+func configEnums(v reflect.Value, prefix string) []configEnum {
+	var enums []configEnum
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := strings.Split(field.Tag.Get("mapstructure"), ",")[0]
+		if name == "" || name == "-" || !field.IsExported() {
+			continue
+		}
+		key := name
+		if prefix != "" {
+			key = prefix + "." + name
+		}
+
+		switch {
+		case field.Type.Kind() == reflect.Struct:
+			enums = append(enums, configEnums(v.Field(i), key)...)
+		case field.Type.Kind() == reflect.String && field.Tag.Get("enum") != "":
+			enums = append(enums, configEnum{
+				key:     key,
+				allowed: strings.Split(field.Tag.Get("enum"), ","),
+				value:   v.Field(i).String(),
+			})
+		}
+	}
+	return enums
+}
+
+// checkConfig loads config like the application does and reports unknown keys and invalid values
+func checkConfig(d *diagnosis, path string) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		d.problem("failed to read config: %v", err)
+		return
+	}
+
+	var raw map[string]interface{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		d.problem("invalid YAML: %v", err)
+		return
+	}
+
+	cfg, err := config.Load(path)
+	if err != nil {
+		d.problem("%v", err)
+		return
+	}
+
+	sections := configKeys(reflect.TypeOf(config.Config{}))
+	for _, section := range sortedKeys(raw) {
+		fields, known := sections[section]
+		if !known {
+			d.warn("section %q is not an SDK section, ignore if it is read by the application", section)
+			continue
+		}
+		values, ok := raw[section].(map[string]interface{})
+		if !ok {
+			d.problem("%s must be a mapping", section)
+			continue
+		}
+		for _, key := range sortedKeys(values) {
+			if fields[key] {
+				continue
+			}
+			fix := ""
+			if suggestion := closestKey(key, fields); suggestion != "" {
+				fix = fmt.Sprintf(", fix: rename to %s.%s", section, suggestion)
+			}
+			d.problem("unknown key %s.%s%s", section, key, fix)
+		}
+	}
+
+	for _, enum := range configEnums(reflect.ValueOf(*cfg), "") {
+		// Empty is an unset optional field, e.g. kafka.sasl.mechanism
+		if enum.value != "" && !contains(enum.allowed, enum.value) {
+			d.problem("%s is %q, fix: use one of %s", enum.key, enum.value, strings.Join(enum.allowed, ", "))
+		}
+	}
+
+	if cfg.Server.Port <= 0 || cfg.Server.Port > 65535 {
+		d.problem("server.port is %d, fix: use a port between 1 and 65535", cfg.Server.Port)
+	}
+	if cfg.Tracing.SampleRate < 0 || cfg.Tracing.SampleRate > 1 {
+		d.problem("tracing.sample_rate is %v, fix: use a value between 0 and 1", cfg.Tracing.SampleRate)
+	}
+	if cfg.Storage.Driver == "s3" && cfg.Storage.Bucket == "" {
+		d.problem("storage.bucket is empty, fix: set it for the s3 driver")
+	}
+	if cfg.I18n.DefaultLanguage != "" && len(cfg.I18n.SupportedLangs) > 0 && !contains(cfg.I18n.SupportedLangs, cfg.I18n.DefaultLanguage) {
+		d.problem("i18n.default_language %q is not in i18n.supported_languages", cfg.I18n.DefaultLanguage)
+	}
+}
+
+// configKeys returns mapstructure keys of config sections
+func configKeys(t reflect.Type) map[string]map[string]bool {
+	sections := map[string]map[string]bool{}
+	for i := 0; i < t.NumField(); i++ {
+		section := t.Field(i)
+		fields := map[string]bool{}
+		for j := 0; j < section.Type.NumField(); j++ {
+			fields[section.Type.Field(j).Tag.Get("mapstructure")] = true
+		}
+		sections[section.Tag.Get("mapstructure")] = fields
+	}
+	return sections
+}
+
+// closestKey suggests a known key within edit distance 2
+func closestKey(key string, known map[string]bool) string {
+	best, bestDistance := "", 3
+	for candidate := range known {
+		if distance := editDistance(key, candidate); distance < bestDistance || (distance == bestDistance && candidate < best) {
+			best, bestDistance = candidate, distance
+		}
+	}
+	return best
+}
+
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
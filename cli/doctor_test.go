@@ -0,0 +1,151 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeProject writes files relative to a temp project dir and changes into it
+func writeProject(t *testing.T, files map[string]string) {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Chdir(dir)
+}
+
+func TestCheckImports(t *testing.T) {
+	writeProject(t, map[string]string{
+		"go.mod": "module example.com/shop\n\ngo 1.25\n\nrequire (\n\tgithub.com/gofiber/fiber/v2 v2.52.10 // indirect\n)\n",
+		"internal/app/app.go": `package app
+
+import (
+	"fmt"
+
+	"example.com/shop/internal/domain"
+	"github.com/gofiber/fiber/v2/middleware/cors"
+	"github.com/unknown/pkg"
+	"your-module/internal/domain/entity"
+)
+`,
+	})
+
+	mod, err := readGoMod("go.mod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	files, err := parseProjectFiles(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var d diagnosis
+	checkImports(&d, mod, files)
+	if len(d.problems) != 2 {
+		t.Fatalf("problems = %q, want the unknown module and the placeholder import", d.problems)
+	}
+	if !strings.Contains(d.problems[0], `go get github.com/unknown/pkg`) {
+		t.Errorf("problem = %q, want a go get fix", d.problems[0])
+	}
+	if !strings.Contains(d.problems[1], `replace with "example.com/shop/internal/domain/entity"`) {
+		t.Errorf("problem = %q, want the module path fix", d.problems[1])
+	}
+}
+
+func TestCheckHandlers(t *testing.T) {
+	handler := `package http
+
+type %sHandler struct{}
+
+func New%sHandler() *%sHandler { return &%sHandler{} }
+
+func (h *%sHandler) RegisterRoutes(router interface{}) {}
+`
+	writeProject(t, map[string]string{
+		"go.mod":             "module example.com/shop\n",
+		"http/order.go":      strings.ReplaceAll(handler, "%s", "Order"),
+		"http/product.go":    strings.ReplaceAll(handler, "%s", "Product"),
+		"http/order_test.go": "package http\n\nvar _ = NewProductHandler()\n",
+		"cmd/main.go":        "package main\n\nimport \"example.com/shop/http\"\n\nvar _ = http.NewOrderHandler()\n",
+	})
+
+	files, err := parseProjectFiles(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var d diagnosis
+	checkHandlers(&d, files)
+	if len(d.problems) != 1 || !strings.HasPrefix(d.problems[0], "ProductHandler is not constructed") {
+		t.Errorf("problems = %q, want only ProductHandler, a test does not wire it", d.problems)
+	}
+}
+
+func TestCheckConfig(t *testing.T) {
+	tests := []struct {
+		name         string
+		yaml         string
+		wantProblems []string
+		wantWarnings int
+	}{
+		{
+			name: "valid",
+			yaml: "server:\n  port: 8080\nlogger:\n  level: warn\n  format: console\n",
+		},
+		{
+			name:         "application section",
+			yaml:         "payments:\n  provider: stripe\n",
+			wantWarnings: 1,
+		},
+		{
+			name:         "level outside the enum tag",
+			yaml:         "logger:\n  level: dpanic\n",
+			wantProblems: []string{`logger.level is "dpanic", fix: use one of debug, info, warn, error`},
+		},
+		{
+			name:         "enum of a field missing from the old list",
+			yaml:         "logger:\n  span_event_level: trace\n",
+			wantProblems: []string{`logger.span_event_level is "trace"`},
+		},
+		{
+			name:         "misspelled key",
+			yaml:         "logger:\n  levle: info\n",
+			wantProblems: []string{"unknown key logger.levle, fix: rename to logger.level"},
+		},
+		{
+			name:         "invalid port",
+			yaml:         "server:\n  port: 70000\n",
+			wantProblems: []string{"server.port is 70000"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			writeProject(t, map[string]string{"config/config.yaml": tt.yaml})
+
+			var d diagnosis
+			checkConfig(&d, "config/config.yaml")
+
+			if len(d.problems) != len(tt.wantProblems) {
+				t.Fatalf("problems = %q, want %q", d.problems, tt.wantProblems)
+			}
+			for i, want := range tt.wantProblems {
+				if !strings.HasPrefix(d.problems[i], want) {
+					t.Errorf("problem = %q, want prefix %q", d.problems[i], want)
+				}
+			}
+			if len(d.warnings) != tt.wantWarnings {
+				t.Errorf("warnings = %q, want %d", d.warnings, tt.wantWarnings)
+			}
+		})
+	}
+}
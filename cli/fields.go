@@ -0,0 +1,282 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// fieldSpec is an entity field parsed from a name:type argument
+type fieldSpec struct {
+	Name     string // Go field name
+	Column   string
+	GoType   string
+	DBType   string
+	JSONName string
+	Nullable bool
+}
+
+// relationSpec is a relation parsed from name:belongs_to[:entity] or name:has_many[:entity]
+type relationSpec struct {
+	Kind     string // belongs_to or has_many
+	Name     string // Go field holding the loaded relation
+	Entity   string
+	JSONName string
+	// ForeignKey is the Go field of belongs_to foreign key, e.g. CategoryID
+	ForeignKey string
+	Column     string
+	Table      string // Referenced table for belongs_to
+}
+
+// GoType is the type of the field holding the loaded relation
+func (r relationSpec) GoType() string {
+	if r.Kind == "has_many" {
+		return "[]*" + r.Entity
+	}
+	return "*" + r.Entity
+}
+
+// fieldType is a supported field type
+type fieldType struct {
+	goType string
+	dbType string
+	pkg    string // Import required by goType
+}
+
+var fieldTypes = map[string]fieldType{
+	"string":  {goType: "string", dbType: "TEXT"},
+	"text":    {goType: "string", dbType: "TEXT"},
+	"uuid":    {goType: "string", dbType: "UUID"},
+	"int":     {goType: "int", dbType: "INTEGER"},
+	"int64":   {goType: "int64", dbType: "BIGINT"},
+	"float64": {goType: "float64", dbType: "DOUBLE PRECISION"},
+	"bool":    {goType: "bool", dbType: "BOOLEAN"},
+	"time":    {goType: "time.Time", dbType: "TIMESTAMPTZ", pkg: "time"},
+	"json":    {goType: "json.RawMessage", dbType: "JSONB", pkg: "encoding/json"},
+}
+
+// modelData is the template data of entity, repository and migration templates
+type modelData struct {
+	Name      string
+	VarName   string
+	Table     string
+	Fields    []fieldSpec
+	Relations []relationSpec
+	Imports   []string
+}
+
+// newModelData parses field arguments:
+//
+//	name:string price:float64 note:text? category:belongs_to comments:has_many:comment
+//
+// A trailing ? makes the field nullable
+func newModelData(name string, specs []string) (modelData, error) {
+	data := modelData{
+		Name:    toPascalCase(name),
+		VarName: toLowerCamelCase(name),
+		Table:   pluralize(toSnakeCase(name)),
+	}
+
+	imports := map[string]bool{"time": true, "github.com/yourorg/microkit/pkg/idgen": true}
+	// Columns are compared, toPascalCase turns id into Id, not ID
+	seen := map[string]bool{"id": true, "created_at": true, "updated_at": true}
+
+	for _, spec := range specs {
+		nullable := strings.HasSuffix(spec, "?")
+		parts := strings.Split(strings.TrimSuffix(spec, "?"), ":")
+		if len(parts) < 2 || len(parts) > 3 || parts[0] == "" {
+			return modelData{}, fmt.Errorf("invalid field %q, expected name:type", spec)
+		}
+
+		column := toSnakeCase(parts[0])
+		fieldName := toPascalCase(column)
+		if seen[column] {
+			return modelData{}, fmt.Errorf("duplicate field %s", column)
+		}
+		seen[column] = true
+
+		switch kind := parts[1]; kind {
+		case "belongs_to", "has_many":
+			relation := relationSpec{
+				Kind:     kind,
+				Name:     fieldName,
+				JSONName: column,
+				Entity:   fieldName,
+			}
+			if len(parts) == 3 {
+				relation.Entity = toPascalCase(parts[2])
+			} else if kind == "has_many" {
+				relation.Entity = toPascalCase(singularize(column))
+			}
+
+			if kind == "belongs_to" {
+				relation.ForeignKey = fieldName + "ID"
+				relation.Column = column + "_id"
+				relation.Table = pluralize(toSnakeCase(relation.Entity))
+				if seen[relation.Column] {
+					return modelData{}, fmt.Errorf("duplicate field %s", relation.Column)
+				}
+				seen[relation.Column] = true
+
+				field := fieldSpec{
+					Name:     relation.ForeignKey,
+					Column:   relation.Column,
+					GoType:   "string",
+					DBType:   "TEXT",
+					JSONName: relation.Column,
+					Nullable: nullable,
+				}
+				if nullable {
+					field.GoType = "*string"
+				}
+				data.Fields = append(data.Fields, field)
+			}
+			data.Relations = append(data.Relations, relation)
+
+		default:
+			if len(parts) == 3 {
+				return modelData{}, fmt.Errorf("invalid field %q, only relations accept an entity", spec)
+			}
+			ft, ok := fieldTypes[kind]
+			if !ok {
+				return modelData{}, fmt.Errorf("unknown type %q of field %s, supported: %s", kind, parts[0], supportedFieldTypes())
+			}
+
+			field := fieldSpec{
+				Name:     fieldName,
+				Column:   column,
+				GoType:   ft.goType,
+				DBType:   ft.dbType,
+				JSONName: column,
+				Nullable: nullable,
+			}
+			// RawMessage is nil for NULL already
+			if nullable && kind != "json" {
+				field.GoType = "*" + ft.goType
+			}
+			if ft.pkg != "" {
+				imports[ft.pkg] = true
+			}
+			data.Fields = append(data.Fields, field)
+		}
+	}
+
+	for pkg := range imports {
+		data.Imports = append(data.Imports, pkg)
+	}
+	sort.Strings(data.Imports)
+	return data, nil
+}
+
+func supportedFieldTypes() string {
+	names := make([]string, 0, len(fieldTypes)+2)
+	for name := range fieldTypes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(append(names, "belongs_to", "has_many"), ", ")
+}
+
+// columns returns all table columns in the order used by queries
+func (d modelData) columns() []string {
+	columns := []string{"id"}
+	for _, f := range d.Fields {
+		columns = append(columns, f.Column)
+	}
+	return append(columns, "created_at", "updated_at")
+}
+
+// goFields returns Go fields matching columns()
+func (d modelData) goFields() []string {
+	fields := []string{"ID"}
+	for _, f := range d.Fields {
+		fields = append(fields, f.Name)
+	}
+	return append(fields, "CreatedAt", "UpdatedAt")
+}
+
+// Columns returns comma separated column list
+func (d modelData) Columns() string {
+	return strings.Join(d.columns(), ", ")
+}
+
+// Placeholders returns $1, $2, ... for every column
+func (d modelData) Placeholders() string {
+	placeholders := make([]string, len(d.columns()))
+	for i := range placeholders {
+		placeholders[i] = "$" + strconv.Itoa(i+1)
+	}
+	return strings.Join(placeholders, ", ")
+}
+
+// Args returns v.Field for every column
+func (d modelData) Args(v string) string {
+	fields := d.goFields()
+	for i, f := range fields {
+		fields[i] = v + "." + f
+	}
+	return strings.Join(fields, ", ")
+}
+
+// ScanArgs returns &v.Field for every column
+func (d modelData) ScanArgs(v string) string {
+	fields := d.goFields()
+	for i, f := range fields {
+		fields[i] = "&" + v + "." + f
+	}
+	return strings.Join(fields, ", ")
+}
+
+// UpdateSet returns SET clause for fields and updated_at, $1 is the id
+func (d modelData) UpdateSet() string {
+	set := make([]string, 0, len(d.Fields)+1)
+	for i, f := range d.Fields {
+		set = append(set, f.Column+" = $"+strconv.Itoa(i+2))
+	}
+	set = append(set, "updated_at = $"+strconv.Itoa(len(d.Fields)+2))
+	return strings.Join(set, ", ")
+}
+
+// UpdateArgs returns arguments matching UpdateSet
+func (d modelData) UpdateArgs(v string) string {
+	args := []string{v + ".ID"}
+	for _, f := range d.Fields {
+		args = append(args, v+"."+f.Name)
+	}
+	args = append(args, v+".UpdatedAt")
+	return strings.Join(args, ", ")
+}
+
+// BelongsTo returns belongs_to relations
+func (d modelData) BelongsTo() []relationSpec {
+	var relations []relationSpec
+	for _, r := range d.Relations {
+		if r.Kind == "belongs_to" {
+			relations = append(relations, r)
+		}
+	}
+	return relations
+}
+
+func pluralize(s string) string {
+	switch {
+	case strings.HasSuffix(s, "y") && len(s) > 1 && !strings.ContainsRune("aeiou", rune(s[len(s)-2])):
+		return s[:len(s)-1] + "ies"
+	case strings.HasSuffix(s, "s"), strings.HasSuffix(s, "x"), strings.HasSuffix(s, "ch"), strings.HasSuffix(s, "sh"):
+		return s + "es"
+	}
+	return s + "s"
+}
+
+func singularize(s string) string {
+	switch {
+	case strings.HasSuffix(s, "ies"):
+		return s[:len(s)-3] + "y"
+	case strings.HasSuffix(s, "ses"), strings.HasSuffix(s, "xes"), strings.HasSuffix(s, "ches"), strings.HasSuffix(s, "shes"):
+		return s[:len(s)-2]
+	case strings.HasSuffix(s, "s"):
+		return s[:len(s)-1]
+	}
+	return s
+}
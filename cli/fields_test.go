@@ -0,0 +1,128 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestNewModelDataFields(t *testing.T) {
+	data, err := newModelData("blog_post", []string{"title:string", "published_at:time?", "meta:json?", "views:int"})
+	if err != nil {
+		t.Fatalf("newModelData: %v", err)
+	}
+
+	if data.Name != "BlogPost" || data.VarName != "blogPost" || data.Table != "blog_posts" {
+		t.Errorf("names = %s, %s, %s", data.Name, data.VarName, data.Table)
+	}
+
+	want := []fieldSpec{
+		{Name: "Title", Column: "title", GoType: "string", DBType: "TEXT", JSONName: "title"},
+		{Name: "PublishedAt", Column: "published_at", GoType: "*time.Time", DBType: "TIMESTAMPTZ", JSONName: "published_at", Nullable: true},
+		// RawMessage holds NULL as nil, it is not wrapped in a pointer
+		{Name: "Meta", Column: "meta", GoType: "json.RawMessage", DBType: "JSONB", JSONName: "meta", Nullable: true},
+		{Name: "Views", Column: "views", GoType: "int", DBType: "INTEGER", JSONName: "views"},
+	}
+	if !slices.Equal(data.Fields, want) {
+		t.Errorf("fields = %+v\nwant %+v", data.Fields, want)
+	}
+	if !slices.Contains(data.Imports, "encoding/json") || !slices.Contains(data.Imports, "time") {
+		t.Errorf("imports = %v", data.Imports)
+	}
+	if !slices.IsSorted(data.Imports) {
+		t.Errorf("imports are not sorted: %v", data.Imports)
+	}
+}
+
+func TestNewModelDataRelations(t *testing.T) {
+	data, err := newModelData("post", []string{
+		"category:belongs_to",
+		"author:belongs_to:user?",
+		"comments:has_many",
+		"replies:has_many:comment",
+		"stories:has_many",
+	})
+	if err != nil {
+		t.Fatalf("newModelData: %v", err)
+	}
+
+	wantFields := []fieldSpec{
+		{Name: "CategoryID", Column: "category_id", GoType: "string", DBType: "TEXT", JSONName: "category_id"},
+		{Name: "AuthorID", Column: "author_id", GoType: "*string", DBType: "TEXT", JSONName: "author_id", Nullable: true},
+	}
+	if !slices.Equal(data.Fields, wantFields) {
+		t.Errorf("foreign keys = %+v\nwant %+v", data.Fields, wantFields)
+	}
+
+	wantRelations := []relationSpec{
+		{Kind: "belongs_to", Name: "Category", Entity: "Category", JSONName: "category", ForeignKey: "CategoryID", Column: "category_id", Table: "categories"},
+		{Kind: "belongs_to", Name: "Author", Entity: "User", JSONName: "author", ForeignKey: "AuthorID", Column: "author_id", Table: "users"},
+		{Kind: "has_many", Name: "Comments", Entity: "Comment", JSONName: "comments"},
+		{Kind: "has_many", Name: "Replies", Entity: "Comment", JSONName: "replies"},
+		{Kind: "has_many", Name: "Stories", Entity: "Story", JSONName: "stories"},
+	}
+	if !slices.Equal(data.Relations, wantRelations) {
+		t.Errorf("relations = %+v\nwant %+v", data.Relations, wantRelations)
+	}
+	if got := data.Relations[2].GoType(); got != "[]*Comment" {
+		t.Errorf("has_many GoType = %s", got)
+	}
+	if got := data.Relations[1].GoType(); got != "*User" {
+		t.Errorf("belongs_to GoType = %s", got)
+	}
+	if len(data.BelongsTo()) != 2 {
+		t.Errorf("BelongsTo() = %+v", data.BelongsTo())
+	}
+	if cols := data.Columns(); cols != "id, category_id, author_id, created_at, updated_at" {
+		t.Errorf("Columns() = %s", cols)
+	}
+}
+
+func TestNewModelDataErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		specs []string
+		want  string
+	}{
+		{"duplicate field", []string{"title:string", "title:text"}, "duplicate field title"},
+		{"duplicate of a case variant", []string{"user_name:string", "userName:string"}, "duplicate field user_name"},
+		{"reserved field", []string{"id:uuid"}, "duplicate field id"},
+		{"foreign key clashes with a field", []string{"category_id:string", "category:belongs_to"}, "duplicate field category_id"},
+		{"missing type", []string{"title"}, "expected name:type"},
+		{"missing name", []string{":string"}, "expected name:type"},
+		{"unknown type", []string{"price:money"}, "unknown type"},
+		{"entity on a plain field", []string{"title:string:post"}, "only relations accept an entity"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newModelData("post", tt.specs)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestPluralize(t *testing.T) {
+	tests := []struct{ singular, plural string }{
+		{"post", "posts"},
+		{"category", "categories"},
+		{"day", "days"},
+		{"status", "statuses"},
+		{"box", "boxes"},
+		{"batch", "batches"},
+		{"wish", "wishes"},
+		{"order_item", "order_items"},
+	}
+	for _, tt := range tests {
+		if got := pluralize(tt.singular); got != tt.plural {
+			t.Errorf("pluralize(%q) = %q, want %q", tt.singular, got, tt.plural)
+		}
+		if got := singularize(tt.plural); got != tt.singular {
+			t.Errorf("singularize(%q) = %q, want %q", tt.plural, got, tt.singular)
+		}
+	}
+	if got := singularize("news"); got != "new" {
+		t.Errorf("singularize(news) = %q, a plain s is dropped", got)
+	}
+}
@@ -5,22 +5,44 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 )
 
 func newGenerateCmd() *cobra.Command {
+	var layoutFlag string
+
 	cmd := &cobra.Command{
 		Use:     "generate",
 		Short:   "Generate code components",
 		Aliases: []string{"gen", "g"},
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			resolved, err := resolveLayout(layoutFlag)
+			if err != nil {
+				return err
+			}
+			layout = resolved
+			return nil
+		},
 	}
 
+	cmd.PersistentFlags().StringVar(&layoutFlag, "layout", "auto",
+		`Project layout: "auto" detects existing internal/, app/ or pkg/ structure, other values set the root directory (e.g. pkg)`)
+
 	cmd.AddCommand(
 		newGenerateEntityCmd(),
 		newGenerateUsecaseCmd(),
 		newGenerateHandlerCmd(),
 		newGenerateRepositoryCmd(),
+		newGenerateUploadCmd(),
+		newGenerateMiddlewareCmd(),
+		newGenerateDTOCmd(),
+		newGenerateGRPCClientCmd(),
+		newGenerateSagaCmd(),
+		newGenerateStreamCmd(),
+		newGeneratePolicyCmd(),
+		newGenerateAuthCmd(),
 	)
 
 	return cmd
@@ -28,11 +50,12 @@ func newGenerateCmd() *cobra.Command {
 
 func newGenerateEntityCmd() *cobra.Command {
 	return &cobra.Command{
-		Use:   "entity [name]",
+		Use:   "entity [name] [field:type...]",
 		Short: "Generate a domain entity",
-		Args:  cobra.ExactArgs(1),
+		Long:  fieldsHelp,
+		Args:  cobra.MinimumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return generateEntity(args[0])
+			return generateEntity(args[0], args[1:])
 		},
 	}
 }
@@ -61,24 +84,45 @@ func newGenerateHandlerCmd() *cobra.Command {
 
 func newGenerateRepositoryCmd() *cobra.Command {
 	return &cobra.Command{
-		Use:   "repository [name]",
-		Short: "Generate a repository interface and implementation",
+		Use:   "repository [name] [field:type...]",
+		Short: "Generate a repository interface, PostgreSQL implementation and migration",
+		Long:  fieldsHelp,
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return generateRepository(args[0], args[1:])
+		},
+	}
+}
+
+const fieldsHelp = `Fields are name:type pairs, a trailing ? makes the field nullable:
+
+  microkit generate entity product name:string price:float64 description:text? \
+    category:belongs_to tags:has_many:tag
+
+Types: string, text, uuid, int, int64, float64, bool, time, json.
+Relations: name:belongs_to[:entity] adds <name>_id foreign key, name:has_many[:entity]
+adds a slice of related entities. Pass the same fields to entity and repository.`
+
+func newGenerateUploadCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "upload [name]",
+		Short: "Generate an HTTP file upload handler backed by object storage",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return generateRepository(args[0])
+			return generateUpload(args[0])
 		},
 	}
 }
 
-func generateEntity(name string) error {
-	entityName := toPascalCase(name)
+func generateEntity(name string, fields []string) error {
 	fileName := toSnakeCase(name) + ".go"
 
-	data := struct {
-		Name string
-	}{Name: entityName}
+	data, err := newModelData(name, fields)
+	if err != nil {
+		return err
+	}
 
-	dir := "internal/domain/entity"
+	dir := layout.Entity
 	if err := os.MkdirAll(dir, 0755); err != nil {
 		return err
 	}
@@ -104,7 +148,7 @@ func generateUsecase(name string) error {
 		VarName: toLowerCamelCase(name),
 	}
 
-	dir := "internal/usecase"
+	dir := layout.Usecase
 	if err := os.MkdirAll(dir, 0755); err != nil {
 		return err
 	}
@@ -130,7 +174,7 @@ func generateHandler(name string) error {
 		VarName: toLowerCamelCase(name),
 	}
 
-	dir := "internal/delivery/http"
+	dir := layout.HTTP
 	if err := os.MkdirAll(dir, 0755); err != nil {
 		return err
 	}
@@ -144,20 +188,16 @@ func generateHandler(name string) error {
 	return nil
 }
 
-func generateRepository(name string) error {
-	repoName := toPascalCase(name)
+func generateRepository(name string, fields []string) error {
 	fileName := toSnakeCase(name) + ".go"
 
-	data := struct {
-		Name    string
-		VarName string
-	}{
-		Name:    repoName,
-		VarName: toLowerCamelCase(name),
+	data, err := newModelData(name, fields)
+	if err != nil {
+		return err
 	}
 
 	// Generate interface
-	interfaceDir := "internal/domain/repository"
+	interfaceDir := layout.Repository
 	if err := os.MkdirAll(interfaceDir, 0755); err != nil {
 		return err
 	}
@@ -168,7 +208,7 @@ func generateRepository(name string) error {
 	}
 
 	// Generate implementation
-	implDir := "internal/infrastructure/repository"
+	implDir := layout.RepositoryImpl
 	if err := os.MkdirAll(implDir, 0755); err != nil {
 		return err
 	}
@@ -178,8 +218,51 @@ func generateRepository(name string) error {
 		return err
 	}
 
+	// Generate migration
+	migrationDir := "migrations"
+	if err := os.MkdirAll(migrationDir, 0755); err != nil {
+		return err
+	}
+
+	migrationName := time.Now().UTC().Format("20060102150405") + "_create_" + data.Table
+	upPath := filepath.Join(migrationDir, migrationName+".up.sql")
+	if err := generateFile(upPath, migrationUpTemplate, data); err != nil {
+		return err
+	}
+	downPath := filepath.Join(migrationDir, migrationName+".down.sql")
+	if err := generateFile(downPath, migrationDownTemplate, data); err != nil {
+		return err
+	}
+
 	fmt.Printf("✅ Generated repository interface: %s\n", interfacePath)
 	fmt.Printf("✅ Generated repository implementation: %s\n", implPath)
+	fmt.Printf("✅ Generated migration: %s\n", upPath)
+	return nil
+}
+
+func generateUpload(name string) error {
+	handlerName := toPascalCase(name)
+	fileName := toSnakeCase(name) + "_upload.go"
+
+	data := struct {
+		Name    string
+		VarName string
+	}{
+		Name:    handlerName,
+		VarName: toLowerCamelCase(name),
+	}
+
+	dir := layout.HTTP
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	path := filepath.Join(dir, fileName)
+	if err := generateFile(path, uploadHandlerTemplate, data); err != nil {
+		return err
+	}
+
+	fmt.Printf("✅ Generated upload handler: %s\n", path)
 	return nil
 }
 
@@ -204,27 +287,54 @@ func toLowerCamelCase(s string) string {
 
 func toSnakeCase(s string) string {
 	var result strings.Builder
-	for i, r := range s {
-		if i > 0 && r >= 'A' && r <= 'Z' {
+	prev := '_'
+	for _, r := range s {
+		if r == '-' || r == ' ' {
+			r = '_'
+		}
+		if r >= 'A' && r <= 'Z' && prev != '_' {
 			result.WriteRune('_')
 		}
 		result.WriteRune(r)
+		prev = r
 	}
 	return strings.ToLower(result.String())
 }
 
 // Templates
-const entityTemplate = `package entity
+const entityTemplate = `package {{package "entity"}}
 
-import "time"
+import (
+{{- range .Imports}}
+	"{{.}}"
+{{- end}}
+)
 
 // {{.Name}} represents a {{.Name}} entity
 type {{.Name}} struct {
-	ID        string    ` + "`json:\"id\"`" + `
-	CreatedAt time.Time ` + "`json:\"created_at\"`" + `
-	UpdatedAt time.Time ` + "`json:\"updated_at\"`" + `
-	
+	ID        string    ` + "`json:\"id\" db:\"id\"`" + `
+{{- range .Fields}}
+	{{.Name}} {{.GoType}} ` + "`" + `json:"{{.JSONName}}{{if .Nullable}},omitempty{{end}}" db:"{{.Column}}"` + "`" + `
+{{- end}}
+{{- range .Relations}}
+	{{.Name}} {{.GoType}} ` + "`" + `json:"{{.JSONName}},omitempty" db:"-"` + "`" + `
+{{- end}}
+	CreatedAt time.Time ` + "`json:\"created_at\" db:\"created_at\"`" + `
+	UpdatedAt time.Time ` + "`json:\"updated_at\" db:\"updated_at\"`" + `
+{{- if not (or .Fields .Relations)}}
+
 	// TODO: Add your fields here
+{{- end}}
+}
+
+// New{{.Name}} creates a {{.Name}} with a time-ordered ID
+func New{{.Name}}() *{{.Name}} {
+	now := time.Now().UTC()
+	return &{{.Name}}{
+		ID:        idgen.NewString(),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
 }
 
 // Validate validates the {{.Name}} entity
@@ -234,7 +344,7 @@ func (e *{{.Name}}) Validate() error {
 }
 `
 
-const usecaseTemplate = `package usecase
+const usecaseTemplate = `package {{package "usecase"}}
 
 import (
 	"context"
@@ -274,14 +384,15 @@ func (u *{{.Name}}Usecase) Execute(ctx context.Context) error {
 }
 `
 
-const handlerTemplate = `package http
+const handlerTemplate = `package {{package "http"}}
 
 import (
 	"github.com/gofiber/fiber/v2"
 	
 	"github.com/yourorg/microkit/pkg/logger"
-	"github.com/yourorg/microkit/pkg/errors"
 	"github.com/yourorg/microkit/pkg/middleware"
+	"github.com/yourorg/microkit/pkg/pagination"
+	"github.com/yourorg/microkit/pkg/respond"
 )
 
 // {{.Name}}Handler handles {{.Name}} HTTP requests
@@ -310,29 +421,36 @@ func (h *{{.Name}}Handler) RegisterRoutes(router fiber.Router) {
 
 // List handles GET /{{.VarName}}
 func (h *{{.Name}}Handler) List(c *fiber.Ctx) error {
-	ctx := c.UserContext()
 	lang := middleware.GetLanguage(c)
 	
+	page, err := pagination.ParsePage(c)
+	if err != nil {
+		return respond.Error(c, err)
+	}
+	
 	h.logger.Info("Listing {{.VarName}}",
 		logger.String("lang", lang),
+		logger.Int("page", page.Page),
 	)
 	
-	// TODO: Implement list logic
+	// TODO: Implement list logic, pass c.UserContext(), page.Limit() and
+	// page.Offset() to the usecase
+	items := []interface{}{}
+	total := 0
 	
-	return c.JSON(fiber.Map{
-		"data": []interface{}{},
-	})
+	pagination.SetPageLinks(c, page, total)
+	return respond.OK(c, items, page.Meta(total))
 }
 
 // Get handles GET /{{.VarName}}/:id
 func (h *{{.Name}}Handler) Get(c *fiber.Ctx) error {
 	id := c.Params("id")
 	
-	// TODO: Implement get logic
+	// TODO: Implement get logic, return respond.Error(c, err) on failure
 	
-	return c.JSON(fiber.Map{
+	return respond.OK(c, fiber.Map{
 		"id": id,
-	})
+	}, nil)
 }
 
 // Create handles POST /{{.VarName}}
@@ -341,7 +459,7 @@ func (h *{{.Name}}Handler) Create(c *fiber.Ctx) error {
 	// TODO: Validate
 	// TODO: Call use case
 	
-	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+	return respond.Created(c, fiber.Map{
 		"message": "Created successfully",
 	})
 }
@@ -354,10 +472,10 @@ func (h *{{.Name}}Handler) Update(c *fiber.Ctx) error {
 	// TODO: Validate
 	// TODO: Call use case
 	
-	return c.JSON(fiber.Map{
+	return respond.OK(c, fiber.Map{
 		"id": id,
 		"message": "Updated successfully",
-	})
+	}, nil)
 }
 
 // Delete handles DELETE /{{.VarName}}/:id
@@ -366,67 +484,77 @@ func (h *{{.Name}}Handler) Delete(c *fiber.Ctx) error {
 	
 	// TODO: Call use case
 	
-	return c.JSON(fiber.Map{
+	return respond.OK(c, fiber.Map{
 		"id": id,
 		"message": "Deleted successfully",
-	})
+	}, nil)
 }
 `
 
-const repositoryInterfaceTemplate = `package repository
+const repositoryInterfaceTemplate = `package {{package "repository"}}
 
 import (
 	"context"
-	
-	"your-module/internal/domain/entity"
+
+	{{import "entity"}}
 )
 
 // {{.Name}}Repository defines {{.Name}} data access interface
 type {{.Name}}Repository interface {
 	// Create creates a new {{.Name}}
 	Create(ctx context.Context, {{.VarName}} *entity.{{.Name}}) error
-	
+
 	// GetByID retrieves a {{.Name}} by ID
 	GetByID(ctx context.Context, id string) (*entity.{{.Name}}, error)
-	
+
 	// Update updates an existing {{.Name}}
 	Update(ctx context.Context, {{.VarName}} *entity.{{.Name}}) error
-	
+
 	// Delete deletes a {{.Name}} by ID
 	Delete(ctx context.Context, id string) error
-	
+
 	// List retrieves all {{.Name}}s with pagination
 	List(ctx context.Context, limit, offset int) ([]*entity.{{.Name}}, error)
+{{- range .BelongsTo}}
+
+	// ListBy{{.ForeignKey}} retrieves {{$.Name}}s of a {{.Entity}} with pagination
+	ListBy{{.ForeignKey}}(ctx context.Context, {{.JSONName}}ID string, limit, offset int) ([]*entity.{{$.Name}}, error)
+{{- end}}
 }
 `
 
-const repositoryImplTemplate = `package repository
+const repositoryImplTemplate = `package {{package "repository_impl"}}
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
-	
-	"your-module/internal/domain/entity"
-	"your-module/internal/domain/repository"
-	
+
+	{{import "entity"}}
+	{{import "repository"}}
+
+	"github.com/yourorg/microkit/pkg/errors"
 	"github.com/yourorg/microkit/pkg/logger"
 	"github.com/yourorg/microkit/pkg/tracing"
-	"github.com/yourorg/microkit/pkg/errors"
 )
 
-// {{.VarName}}Repository implements {{.Name}}Repository interface
+const {{.VarName}}Columns = "{{.Columns}}"
+
+// {{.VarName}}Repository implements {{.Name}}Repository interface on PostgreSQL
 type {{.VarName}}Repository struct {
+	db     *sql.DB
 	logger *logger.Logger
 	tracer *tracing.Tracer
-	// TODO: Add database connection
 }
 
 // New{{.Name}}Repository creates a new {{.Name}}Repository
 func New{{.Name}}Repository(
+	db *sql.DB,
 	logger *logger.Logger,
 	tracer *tracing.Tracer,
 ) repository.{{.Name}}Repository {
 	return &{{.VarName}}Repository{
+		db:     db,
 		logger: logger,
 		tracer: tracer,
 	}
@@ -436,11 +564,11 @@ func New{{.Name}}Repository(
 func (r *{{.VarName}}Repository) Create(ctx context.Context, {{.VarName}} *entity.{{.Name}}) error {
 	ctx, span := r.tracer.Start(ctx, "{{.Name}}Repository.Create")
 	defer span.End()
-	
-	r.logger.Info("Creating {{.VarName}}")
-	
-	// TODO: Implement database insert
-	
+
+	query := "INSERT INTO {{.Table}} (" + {{.VarName}}Columns + ") VALUES ({{.Placeholders}})"
+	if _, err := r.db.ExecContext(ctx, query, {{.Args .VarName}}); err != nil {
+		return fmt.Errorf("failed to create {{.VarName}}: %w", err)
+	}
 	return nil
 }
 
@@ -448,50 +576,232 @@ func (r *{{.VarName}}Repository) Create(ctx context.Context, {{.VarName}} *entit
 func (r *{{.VarName}}Repository) GetByID(ctx context.Context, id string) (*entity.{{.Name}}, error) {
 	ctx, span := r.tracer.Start(ctx, "{{.Name}}Repository.GetByID")
 	defer span.End()
-	
-	r.logger.Info("Getting {{.VarName}} by ID", logger.String("id", id))
-	
-	// TODO: Implement database query
-	
-	return nil, errors.ErrNotFound
+
+	query := "SELECT " + {{.VarName}}Columns + " FROM {{.Table}} WHERE id = $1"
+	{{.VarName}}, err := scan{{.Name}}(r.db.QueryRowContext(ctx, query, id).Scan)
+	if err == sql.ErrNoRows {
+		return nil, errors.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get {{.VarName}}: %w", err)
+	}
+	return {{.VarName}}, nil
 }
 
 // Update updates an existing {{.Name}}
 func (r *{{.VarName}}Repository) Update(ctx context.Context, {{.VarName}} *entity.{{.Name}}) error {
 	ctx, span := r.tracer.Start(ctx, "{{.Name}}Repository.Update")
 	defer span.End()
-	
-	r.logger.Info("Updating {{.VarName}}")
-	
-	// TODO: Implement database update
-	
-	return nil
+
+	query := "UPDATE {{.Table}} SET {{.UpdateSet}} WHERE id = $1"
+	result, err := r.db.ExecContext(ctx, query, {{.UpdateArgs .VarName}})
+	if err != nil {
+		return fmt.Errorf("failed to update {{.VarName}}: %w", err)
+	}
+	return r.checkAffected(result)
 }
 
 // Delete deletes a {{.Name}} by ID
 func (r *{{.VarName}}Repository) Delete(ctx context.Context, id string) error {
 	ctx, span := r.tracer.Start(ctx, "{{.Name}}Repository.Delete")
 	defer span.End()
-	
+
 	r.logger.Info("Deleting {{.VarName}}", logger.String("id", id))
-	
-	// TODO: Implement database delete
-	
-	return nil
+
+	result, err := r.db.ExecContext(ctx, "DELETE FROM {{.Table}} WHERE id = $1", id)
+	if err != nil {
+		return fmt.Errorf("failed to delete {{.VarName}}: %w", err)
+	}
+	return r.checkAffected(result)
 }
 
 // List retrieves all {{.Name}}s with pagination
 func (r *{{.VarName}}Repository) List(ctx context.Context, limit, offset int) ([]*entity.{{.Name}}, error) {
 	ctx, span := r.tracer.Start(ctx, "{{.Name}}Repository.List")
 	defer span.End()
-	
-	r.logger.Info("Listing {{.VarName}}s", 
-		logger.Int("limit", limit),
-		logger.Int("offset", offset),
-	)
-	
-	// TODO: Implement database query with pagination
-	
-	return []*entity.{{.Name}}{}, nil
+
+	query := "SELECT " + {{.VarName}}Columns + " FROM {{.Table}} ORDER BY created_at, id LIMIT $1 OFFSET $2"
+	return r.list(ctx, query, limit, offset)
+}
+{{- range .BelongsTo}}
+
+// ListBy{{.ForeignKey}} retrieves {{$.Name}}s of a {{.Entity}} with pagination
+func (r *{{$.VarName}}Repository) ListBy{{.ForeignKey}}(ctx context.Context, {{.JSONName}}ID string, limit, offset int) ([]*entity.{{$.Name}}, error) {
+	ctx, span := r.tracer.Start(ctx, "{{$.Name}}Repository.ListBy{{.ForeignKey}}")
+	defer span.End()
+
+	query := "SELECT " + {{$.VarName}}Columns + " FROM {{$.Table}} WHERE {{.Column}} = $1 ORDER BY created_at, id LIMIT $2 OFFSET $3"
+	return r.list(ctx, query, {{.JSONName}}ID, limit, offset)
+}
+{{- end}}
+
+func (r *{{.VarName}}Repository) list(ctx context.Context, query string, args ...interface{}) ([]*entity.{{.Name}}, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list {{.VarName}}: %w", err)
+	}
+	defer rows.Close()
+
+	items := []*entity.{{.Name}}{}
+	for rows.Next() {
+		item, err := scan{{.Name}}(rows.Scan)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan {{.VarName}}: %w", err)
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+// scan{{.Name}} scans columns in {{.VarName}}Columns order
+func scan{{.Name}}(scan func(dest ...interface{}) error) (*entity.{{.Name}}, error) {
+	{{.VarName}} := &entity.{{.Name}}{}
+	if err := scan({{.ScanArgs .VarName}}); err != nil {
+		return nil, err
+	}
+	return {{.VarName}}, nil
+}
+
+func (r *{{.VarName}}Repository) checkAffected(result sql.Result) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return errors.ErrNotFound
+	}
+	return nil
+}
+`
+
+const migrationUpTemplate = `CREATE TABLE IF NOT EXISTS {{.Table}} (
+	id TEXT PRIMARY KEY,
+{{- range .Fields}}
+	{{.Column}} {{.DBType}}{{if not .Nullable}} NOT NULL{{end}},
+{{- end}}
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+{{- range .BelongsTo}},
+	FOREIGN KEY ({{.Column}}) REFERENCES {{.Table}} (id)
+{{- end}}
+);
+{{- range .BelongsTo}}
+
+CREATE INDEX IF NOT EXISTS {{$.Table}}_{{.Column}}_idx ON {{$.Table}} ({{.Column}});
+{{- end}}
+`
+
+const migrationDownTemplate = `DROP TABLE IF EXISTS {{.Table}};
+`
+
+const uploadHandlerTemplate = `package {{package "http"}}
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/yourorg/microkit/pkg/idgen"
+	"github.com/yourorg/microkit/pkg/logger"
+	"github.com/yourorg/microkit/pkg/storage"
+)
+
+// {{.Name}}UploadHandler handles {{.Name}} file uploads
+type {{.Name}}UploadHandler struct {
+	storage storage.Storage
+	logger  *logger.Logger
+	maxSize int64
+}
+
+// New{{.Name}}UploadHandler creates a new {{.Name}}UploadHandler
+func New{{.Name}}UploadHandler(storage storage.Storage, logger *logger.Logger) *{{.Name}}UploadHandler {
+	return &{{.Name}}UploadHandler{
+		storage: storage,
+		logger:  logger,
+		maxSize: 10 << 20, // 10MB
+	}
+}
+
+// RegisterRoutes registers {{.Name}} upload routes
+func (h *{{.Name}}UploadHandler) RegisterRoutes(router fiber.Router) {
+	group := router.Group("/{{.VarName}}/files")
+
+	group.Post("/", h.Upload)
+	group.Get("/:key", h.Download)
+	group.Delete("/:key", h.Delete)
+}
+
+// Upload handles POST /{{.VarName}}/files (multipart field "file")
+func (h *{{.Name}}UploadHandler) Upload(c *fiber.Ctx) error {
+	fh, err := c.FormFile("file")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "file is required")
+	}
+	if fh.Size > h.maxSize {
+		return fiber.NewError(fiber.StatusRequestEntityTooLarge, "file is too large")
+	}
+
+	file, err := fh.Open()
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	key := idgen.NewString() + path.Ext(fh.Filename)
+
+	// Stream file to storage without buffering it in memory
+	info, err := h.storage.Put(c.UserContext(), key, file, fh.Size, storage.PutOptions{
+		ContentType: fh.Header.Get("Content-Type"),
+		Metadata: map[string]string{
+			"filename": fh.Filename,
+		},
+	})
+	if err != nil {
+		h.logger.Error("Failed to upload file", logger.Error(err))
+		return err
+	}
+
+	url, err := h.storage.Presign(c.UserContext(), key, fiber.MethodGet, 15*time.Minute)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"key":  info.Key,
+		"size": info.Size,
+		"url":  url,
+	})
+}
+
+// Download handles GET /{{.VarName}}/files/:key
+func (h *{{.Name}}UploadHandler) Download(c *fiber.Ctx) error {
+	body, info, err := h.storage.Get(c.UserContext(), c.Params("key"))
+	if errors.Is(err, storage.ErrNotFound) {
+		return fiber.ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	if info.ContentType != "" {
+		c.Set(fiber.HeaderContentType, info.ContentType)
+	}
+	if filename := info.Metadata["filename"]; filename != "" {
+		c.Set(fiber.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", filename))
+	}
+
+	// Fiber closes body after streaming
+	return c.SendStream(body, int(info.Size))
+}
+
+// Delete handles DELETE /{{.VarName}}/files/:key
+func (h *{{.Name}}UploadHandler) Delete(c *fiber.Ctx) error {
+	if err := h.storage.Delete(c.UserContext(), c.Params("key")); err != nil {
+		return err
+	}
+	return c.SendStatus(fiber.StatusNoContent)
 }
 `
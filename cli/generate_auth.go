@@ -0,0 +1,154 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+)
+
+func newGenerateAuthCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "auth",
+		Short: "Generate an auth module issuing JWTs with rotated keys and serving JWKS",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return generateAuth()
+		},
+	}
+}
+
+func generateAuth() error {
+	dir := filepath.Join(layout.Infrastructure, "auth")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	path := filepath.Join(dir, "auth.go")
+	if err := generateFile(path, authModuleTemplate, nil); err != nil {
+		return err
+	}
+
+	fmt.Printf("✅ Generated auth module: %s\n", path)
+	fmt.Printf("\nGenerate a signing key, file names sort by age and the last one signs:\n\n")
+	fmt.Printf("\tmkdir -p keys && openssl ecparam -name prime256v1 -genkey -noout | openssl pkcs8 -topk8 -nocrypt -out keys/$(date +%%Y-%%m).pem\n\n")
+	fmt.Printf("Add the module and protect routes:\n\n")
+	fmt.Printf("\tapp.WithFxOptions(auth.Module)\n")
+	fmt.Printf("\tapi := app.Group(\"/api/v1\", middleware.JWTMiddleware(middleware.DefaultJWTConfig(tokens)))\n")
+	return nil
+}
+
+const authModuleTemplate = `package auth
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/fx"
+
+	"github.com/yourorg/microkit/pkg/auth"
+	"github.com/yourorg/microkit/pkg/server"
+)
+
+// KeysDir holds PEM signing keys, the last by name is primary. Rotation is
+// adding a newer file, an old one is removed once its tokens expired
+const KeysDir = "keys"
+
+// TokenTTL is the lifetime of access tokens
+const TokenTTL = 15 * time.Minute
+
+// Module provides *auth.Tokens and registers token routes
+var Module = fx.Module("auth",
+	fx.Provide(LoadKeys, NewTokens),
+	fx.Invoke(RegisterRoutes),
+)
+
+// LoadKeys reads signing keys from KeysDir
+func LoadKeys() (*auth.KeySet, error) {
+	paths, err := filepath.Glob(filepath.Join(KeysDir, "*.pem"))
+	if err != nil {
+		return nil, err
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no signing keys in %s", KeysDir)
+	}
+	sort.Strings(paths)
+
+	keys := make([]*auth.Key, 0, len(paths))
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		key, err := auth.ParsePEM(strings.TrimSuffix(filepath.Base(path), ".pem"), data)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	primary := keys[len(keys)-1]
+	return auth.NewKeySet(primary, keys[:len(keys)-1]...)
+}
+
+// NewTokens creates tokens signed by the primary key
+func NewTokens(keys *auth.KeySet) *auth.Tokens {
+	cfg := auth.DefaultConfig()
+	cfg.Issuer = "microkit" // TODO: set the issuer services expect
+	cfg.TTL = TokenTTL
+	return auth.New(keys, cfg)
+}
+
+// TokenRequest is the body of POST /api/v1/auth/token
+type TokenRequest struct {
+	Username string ` + "`json:\"username\"`" + `
+	Password string ` + "`json:\"password\"`" + `
+}
+
+// RegisterRoutes registers token issuing and the JWKS of public keys
+func RegisterRoutes(srv *server.Server, keys *auth.KeySet, tokens *auth.Tokens) {
+	app := srv.App()
+
+	app.Get("/.well-known/jwks.json", func(c *fiber.Ctx) error {
+		jwks, err := keys.JWKS()
+		if err != nil {
+			return err
+		}
+		c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+		c.Set(fiber.HeaderCacheControl, "public, max-age=300")
+		return c.Send(jwks)
+	})
+
+	app.Post("/api/v1/auth/token", func(c *fiber.Ctx) error {
+		var req TokenRequest
+		if err := c.BodyParser(&req); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "invalid request body")
+		}
+
+		// TODO: Check credentials and load the user
+		subject := req.Username
+		if subject == "" || req.Password == "" {
+			return fiber.NewError(fiber.StatusUnauthorized, "invalid credentials")
+		}
+
+		token, err := tokens.Sign(auth.Claims{
+			Subject: subject,
+			Roles:   []string{"user"},
+		})
+		if err != nil {
+			return err
+		}
+		return c.JSON(fiber.Map{
+			"access_token": token,
+			"token_type":   "Bearer",
+			"expires_in":   int(TokenTTL.Seconds()),
+		})
+	})
+}
+`
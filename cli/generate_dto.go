@@ -0,0 +1,263 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+func newGenerateDTOCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "dto [entity]",
+		Short: "Generate request/response models with entity mapping functions",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return generateDTO(args[0])
+		},
+	}
+}
+
+// dtoField is an entity field copied into request/response models
+type dtoField struct {
+	Name       string
+	Type       string
+	JSONName   string
+	Validate   string // Create request validation tag
+	UpdateType string // Nil means unchanged in update request
+	Nullable   bool   // Type is already a pointer or slice
+}
+
+func generateDTO(name string) error {
+	entityPath := filepath.Join(layout.Entity, toSnakeCase(name)+".go")
+
+	fields, err := entityFields(entityPath, toPascalCase(name))
+	if err != nil {
+		fmt.Printf("⚠️  %v, generating models without fields (run `microkit generate entity %s` first)\n", err, name)
+	}
+
+	data := struct {
+		Name    string
+		VarName string
+		Fields  []dtoField
+	}{
+		Name:    toPascalCase(name),
+		VarName: toLowerCamelCase(name),
+		Fields:  fields,
+	}
+
+	dir := filepath.Join(layout.HTTP, "dto")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	path := filepath.Join(dir, toSnakeCase(name)+".go")
+	if err := generateFile(path, dtoTemplate, data); err != nil {
+		return err
+	}
+
+	fmt.Printf("✅ Generated DTO: %s\n", path)
+	return nil
+}
+
+// entityFields reads exported fields of the entity struct except ID and timestamps
+func entityFields(path, structName string) ([]dtoField, error) {
+	file, err := parser.ParseFile(token.NewFileSet(), path, nil, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse entity: %w", err)
+	}
+
+	var spec *ast.StructType
+	ast.Inspect(file, func(n ast.Node) bool {
+		if ts, ok := n.(*ast.TypeSpec); ok && ts.Name.Name == structName {
+			spec, _ = ts.Type.(*ast.StructType)
+		}
+		return spec == nil
+	})
+	if spec == nil {
+		return nil, fmt.Errorf("struct %s not found in %s", structName, path)
+	}
+
+	var fields []dtoField
+	for _, field := range spec.Fields.List {
+		typeName := exprString(field.Type)
+		// Types from other packages would need extra imports in the DTO
+		if typeName == "" || (strings.Contains(typeName, ".") && typeName != "time.Time") {
+			continue
+		}
+
+		for _, ident := range field.Names {
+			if !ident.IsExported() {
+				continue
+			}
+			switch ident.Name {
+			case "ID", "CreatedAt", "UpdatedAt":
+				continue
+			}
+
+			jsonName := toSnakeCase(ident.Name)
+			if field.Tag != nil {
+				tag := reflect.StructTag(strings.Trim(field.Tag.Value, "`"))
+				// Relations are loaded separately, e.g. Category *Category
+				if tag.Get("db") == "-" {
+					continue
+				}
+				if value, ok := tag.Lookup("json"); ok {
+					value = strings.Split(value, ",")[0]
+					if value == "-" {
+						continue
+					}
+					if value != "" {
+						jsonName = value
+					}
+				}
+			}
+
+			fields = append(fields, newDTOField(ident.Name, typeName, jsonName))
+		}
+	}
+	return fields, nil
+}
+
+func newDTOField(name, typeName, jsonName string) dtoField {
+	field := dtoField{Name: name, Type: typeName, JSONName: jsonName}
+
+	field.Nullable = strings.HasPrefix(typeName, "*") || strings.HasPrefix(typeName, "[]")
+	if field.Nullable {
+		field.UpdateType = typeName
+	} else {
+		field.UpdateType = "*" + typeName
+	}
+
+	// required rejects false and pointers are optional by design
+	if typeName != "bool" && !strings.HasPrefix(typeName, "*") {
+		field.Validate = "required"
+	}
+	return field
+}
+
+// exprString formats a field type, returns empty string for unsupported types
+func exprString(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		return t.Name
+	case *ast.SelectorExpr:
+		if pkg, ok := t.X.(*ast.Ident); ok {
+			return pkg.Name + "." + t.Sel.Name
+		}
+	case *ast.StarExpr:
+		if inner := exprString(t.X); inner != "" {
+			return "*" + inner
+		}
+	case *ast.ArrayType:
+		if inner := exprString(t.Elt); inner != "" && t.Len == nil {
+			return "[]" + inner
+		}
+	}
+	return ""
+}
+
+// modulePath reads the module path from go.mod in the current directory
+func modulePath() string {
+	file, err := os.Open("go.mod")
+	if err != nil {
+		return "your-module"
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); strings.HasPrefix(line, "module ") {
+			return strings.TrimSpace(strings.TrimPrefix(line, "module "))
+		}
+	}
+	return "your-module"
+}
+
+const dtoTemplate = `package dto
+
+import (
+	"time"
+
+	{{import "entity"}}
+)
+
+// Create{{.Name}}Request is the request body for POST /{{.VarName}}
+type Create{{.Name}}Request struct {
+{{- range .Fields}}
+	{{.Name}} {{.Type}} ` + "`" + `json:"{{.JSONName}}"{{if .Validate}} validate:"{{.Validate}}"{{end}}` + "`" + `
+{{- else}}
+	// TODO: Add fields, e.g.
+	// Name string ` + "`" + `json:"name" validate:"required,max=255"` + "`" + `
+{{- end}}
+}
+
+// ToEntity maps the request to a new {{.Name}} with a generated ID
+func (r Create{{.Name}}Request) ToEntity() *entity.{{.Name}} {
+	e := entity.New{{.Name}}()
+{{- range .Fields}}
+	e.{{.Name}} = r.{{.Name}}
+{{- end}}
+	return e
+}
+
+// Update{{.Name}}Request is the request body for PUT /{{.VarName}}/:id.
+// Nil fields are left unchanged
+type Update{{.Name}}Request struct {
+{{- range .Fields}}
+	{{.Name}} {{.UpdateType}} ` + "`" + `json:"{{.JSONName}},omitempty"` + "`" + `
+{{- else}}
+	// TODO: Add fields, e.g.
+	// Name *string ` + "`" + `json:"name,omitempty" validate:"omitempty,max=255"` + "`" + `
+{{- end}}
+}
+
+// ToEntity applies the request to an existing {{.Name}}
+func (r Update{{.Name}}Request) ToEntity(e *entity.{{.Name}}) *entity.{{.Name}} {
+{{- range .Fields}}
+	if r.{{.Name}} != nil {
+		e.{{.Name}} = {{if not .Nullable}}*{{end}}r.{{.Name}}
+	}
+{{- end}}
+	e.UpdatedAt = time.Now()
+	return e
+}
+
+// {{.Name}}Response is the {{.Name}} representation returned by the API
+type {{.Name}}Response struct {
+	ID        string    ` + "`" + `json:"id"` + "`" + `
+{{- range .Fields}}
+	{{.Name}} {{.Type}} ` + "`" + `json:"{{.JSONName}}"` + "`" + `
+{{- end}}
+	CreatedAt time.Time ` + "`" + `json:"created_at"` + "`" + `
+	UpdatedAt time.Time ` + "`" + `json:"updated_at"` + "`" + `
+}
+
+// {{.Name}}FromEntity maps a {{.Name}} to its response
+func {{.Name}}FromEntity(e *entity.{{.Name}}) {{.Name}}Response {
+	return {{.Name}}Response{
+		ID:        e.ID,
+{{- range .Fields}}
+		{{.Name}}: e.{{.Name}},
+{{- end}}
+		CreatedAt: e.CreatedAt,
+		UpdatedAt: e.UpdatedAt,
+	}
+}
+
+// {{.Name}}ListFromEntities maps a list of {{.Name}} to responses
+func {{.Name}}ListFromEntities(items []*entity.{{.Name}}) []{{.Name}}Response {
+	result := make([]{{.Name}}Response, 0, len(items))
+	for _, item := range items {
+		result = append(result, {{.Name}}FromEntity(item))
+	}
+	return result
+}
+`
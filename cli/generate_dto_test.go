@@ -0,0 +1,43 @@
+package main
+
+import (
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerateDTOBuildsEntityWithID(t *testing.T) {
+	t.Chdir(t.TempDir())
+	if err := generateEntity("product", []string{"name:string", "price:int"}); err != nil {
+		t.Fatalf("generateEntity: %v", err)
+	}
+	if err := generateDTO("product"); err != nil {
+		t.Fatalf("generateDTO: %v", err)
+	}
+
+	path := filepath.Join(layout.HTTP, "dto", "product.go")
+	source, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("DTO not generated: %v", err)
+	}
+	if _, err := parser.ParseFile(token.NewFileSet(), path, source, 0); err != nil {
+		t.Fatalf("generated DTO does not parse: %v\n%s", err, source)
+	}
+
+	code := string(source)
+	// entity.NewProduct assigns the ID, a literal would leave it empty
+	if !strings.Contains(code, "e := entity.NewProduct()") {
+		t.Errorf("create ToEntity does not start from entity.NewProduct:\n%s", code)
+	}
+	if strings.Contains(code, "&entity.Product{") {
+		t.Errorf("create ToEntity builds the entity literal:\n%s", code)
+	}
+	for _, assign := range []string{"e.Name = r.Name", "e.Price = r.Price"} {
+		if !strings.Contains(code, assign) {
+			t.Errorf("ToEntity misses %q:\n%s", assign, code)
+		}
+	}
+}
@@ -0,0 +1,279 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+func newGenerateGRPCClientCmd() *cobra.Command {
+	var (
+		protoPath  string
+		includes   []string
+		skipProtoc bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "grpc-client [service]",
+		Short: "Generate a gRPC client wrapper for a downstream service",
+		Long: `Compiles the proto with protoc (protoc-gen-go and protoc-gen-go-grpc must be in PATH)
+and wraps the generated stubs with SDK logging, tracing and retry interceptors.
+Must be run from the project root.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return generateGRPCClient(args[0], protoPath, includes, skipProtoc)
+		},
+	}
+
+	cmd.Flags().StringVar(&protoPath, "proto", "", "Path to the service .proto file")
+	cmd.Flags().StringSliceVarP(&includes, "include", "I", nil, "Additional proto import paths")
+	cmd.Flags().BoolVar(&skipProtoc, "skip-protoc", false, "Do not run protoc, stubs are already generated")
+	cmd.MarkFlagRequired("proto")
+
+	return cmd
+}
+
+// protoService is a service parsed from a .proto file
+type protoService struct {
+	Name    string
+	Field   string // Client field holding the stub
+	Methods []protoMethod
+}
+
+// protoMethod is a unary rpc
+type protoMethod struct {
+	Name     string
+	Request  string
+	Response string
+}
+
+var (
+	protoPackageRe = regexp.MustCompile(`(?m)^\s*package\s+([\w.]+)\s*;`)
+	protoServiceRe = regexp.MustCompile(`(?m)^\s*service\s+(\w+)\s*\{`)
+	protoRPCRe     = regexp.MustCompile(`rpc\s+(\w+)\s*\(\s*(stream\s+)?([\w.]+)\s*\)\s*returns\s*\(\s*(stream\s+)?([\w.]+)\s*\)`)
+	protoComments  = regexp.MustCompile(`(?s)//[^\n]*|/\*.*?\*/`)
+)
+
+func generateGRPCClient(name, protoPath string, includes []string, skipProtoc bool) error {
+	module := modulePath()
+	if module == "your-module" {
+		return fmt.Errorf("go.mod not found, run the command from the project root")
+	}
+
+	source, err := os.ReadFile(protoPath)
+	if err != nil {
+		return fmt.Errorf("failed to read proto: %w", err)
+	}
+
+	services, skipped := parseProtoServices(string(source))
+	if len(services) == 0 {
+		return fmt.Errorf("no services with unary methods found in %s", protoPath)
+	}
+	for i := range services {
+		services[i].Field = "api"
+		if len(services) > 1 {
+			services[i].Field = strings.ToLower(services[i].Name[:1]) + services[i].Name[1:]
+		}
+	}
+	for _, method := range skipped {
+		fmt.Printf("⚠️  Skipped %s: streaming and imported message types are not wrapped, use Client.Conn()\n", method)
+	}
+
+	pkgName := strings.ReplaceAll(toSnakeCase(toPascalCase(name)), "_", "")
+	dir := filepath.Join(layout.Infrastructure, "grpc", pkgName)
+	pbDir := filepath.Join(dir, "pb")
+	if err := os.MkdirAll(pbDir, 0755); err != nil {
+		return err
+	}
+
+	if !skipProtoc {
+		if err := runProtoc(protoPath, includes, module, module+"/"+filepath.ToSlash(pbDir)); err != nil {
+			return err
+		}
+		fmt.Printf("✅ Compiled %s into %s\n", protoPath, pbDir)
+	}
+
+	data := struct {
+		Name      string
+		Package   string
+		PBImport  string
+		ConfigKey string
+		Services  []protoService
+	}{
+		Name:      toPascalCase(name),
+		Package:   pkgName,
+		PBImport:  module + "/" + filepath.ToSlash(pbDir),
+		ConfigKey: toSnakeCase(toPascalCase(name)),
+		Services:  services,
+	}
+
+	path := filepath.Join(dir, "client.go")
+	if err := generateFile(path, grpcClientTemplate, data); err != nil {
+		return err
+	}
+
+	fmt.Printf("✅ Generated gRPC client: %s\n", path)
+	fmt.Printf("\nAdd the config section:\n\n")
+	fmt.Printf("\t%s:\n\t  address: %s:9090\n\t  timeout: 5s\n\t  insecure: true\n\t  max_attempts: 3\n\n", data.ConfigKey, name)
+	fmt.Printf("and provide the client:\n\n")
+	fmt.Printf("\tfx.Provide(func(cfg *Config, log *logger.Logger, tracer *tracing.Tracer) (*%s.Client, error) {\n", pkgName)
+	fmt.Printf("\t\treturn %s.New(cfg.%s, log, tracer)\n\t})\n", pkgName, data.Name)
+	return nil
+}
+
+// parseProtoServices extracts unary rpcs, returns skipped methods separately
+func parseProtoServices(source string) ([]protoService, []string) {
+	source = protoComments.ReplaceAllString(source, "")
+
+	var pkg string
+	if m := protoPackageRe.FindStringSubmatch(source); m != nil {
+		pkg = m[1] + "."
+	}
+
+	var (
+		services []protoService
+		skipped  []string
+		names    = map[string]bool{}
+	)
+
+	locs := protoServiceRe.FindAllStringSubmatchIndex(source, -1)
+	for i, loc := range locs {
+		end := len(source)
+		if i+1 < len(locs) {
+			end = locs[i+1][0]
+		}
+		service := protoService{Name: source[loc[2]:loc[3]]}
+
+		for _, m := range protoRPCRe.FindAllStringSubmatch(source[loc[1]:end], -1) {
+			method := service.Name + "." + m[1]
+			request, reqOK := protoGoType(m[3], pkg)
+			response, respOK := protoGoType(m[5], pkg)
+			// Same method name in two services would clash on Client
+			if m[2] != "" || m[4] != "" || !reqOK || !respOK || names[m[1]] {
+				skipped = append(skipped, method)
+				continue
+			}
+			names[m[1]] = true
+			service.Methods = append(service.Methods, protoMethod{Name: m[1], Request: request, Response: response})
+		}
+
+		if len(service.Methods) > 0 {
+			services = append(services, service)
+		}
+	}
+	return services, skipped
+}
+
+// protoGoType converts message name from the same package to generated Go type name
+func protoGoType(name, pkg string) (string, bool) {
+	name = strings.TrimPrefix(strings.TrimPrefix(name, "."), pkg)
+	// Other package, e.g. google.protobuf.Empty
+	if first := strings.Split(name, ".")[0]; first != "" && first[0] >= 'a' && first[0] <= 'z' {
+		return "", false
+	}
+	// Nested messages: Outer.Inner -> Outer_Inner
+	return strings.ReplaceAll(name, ".", "_"), true
+}
+
+func runProtoc(protoPath string, includes []string, module, goPackage string) error {
+	if _, err := exec.LookPath("protoc"); err != nil {
+		return fmt.Errorf("protoc not found in PATH, install it or use --skip-protoc: %w", err)
+	}
+
+	base := filepath.Base(protoPath)
+	args := []string{"-I", filepath.Dir(protoPath)}
+	for _, include := range includes {
+		args = append(args, "-I", include)
+	}
+	mapping := "M" + base + "=" + goPackage
+	args = append(args,
+		"--go_out=.", "--go_opt=module="+module, "--go_opt="+mapping,
+		"--go-grpc_out=.", "--go-grpc_opt=module="+module, "--go-grpc_opt="+mapping,
+		base,
+	)
+
+	cmd := exec.Command("protoc", args...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("protoc failed: %w", err)
+	}
+	return nil
+}
+
+const grpcClientTemplate = `package {{.Package}}
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"github.com/yourorg/microkit/pkg/grpcclient"
+	"github.com/yourorg/microkit/pkg/logger"
+	"github.com/yourorg/microkit/pkg/tracing"
+
+	"{{.PBImport}}"
+)
+
+// Config is the {{.ConfigKey}} config section
+type Config struct {
+	Address     string        ` + "`" + `mapstructure:"address"` + "`" + `
+	Timeout     time.Duration ` + "`" + `mapstructure:"timeout"` + "`" + `
+	Insecure    bool          ` + "`" + `mapstructure:"insecure"` + "`" + `
+	MaxAttempts int           ` + "`" + `mapstructure:"max_attempts"` + "`" + `
+}
+
+// Client calls {{.Name}} with logging, tracing and retries
+type Client struct {
+	conn *grpc.ClientConn
+{{- range .Services}}
+	{{.Field}} pb.{{.Name}}Client
+{{- end}}
+}
+
+// New creates {{.Name}} client, connection is established on first call
+func New(cfg Config, log *logger.Logger, tracer *tracing.Tracer) (*Client, error) {
+	retry := grpcclient.DefaultRetryConfig()
+	if cfg.MaxAttempts > 0 {
+		retry.MaxAttempts = cfg.MaxAttempts
+	}
+
+	conn, err := grpcclient.Dial(grpcclient.Config{
+		Address:  cfg.Address,
+		Timeout:  cfg.Timeout,
+		Insecure: cfg.Insecure,
+		Retry:    retry,
+	}, log, tracer)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Client{
+		conn: conn,
+{{- range .Services}}
+		{{.Field}}: pb.New{{.Name}}Client(conn),
+{{- end}}
+	}, nil
+}
+
+// Conn returns underlying connection for streaming methods
+func (c *Client) Conn() *grpc.ClientConn {
+	return c.conn
+}
+
+// Close closes the connection
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+{{range $service := .Services}}{{range .Methods}}
+// {{.Name}} calls {{$service.Name}}.{{.Name}}
+func (c *Client) {{.Name}}(ctx context.Context, req *pb.{{.Request}}, opts ...grpc.CallOption) (*pb.{{.Response}}, error) {
+	return c.{{$service.Field}}.{{.Name}}(ctx, req, opts...)
+}
+{{end}}{{end}}`
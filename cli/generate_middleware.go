@@ -0,0 +1,141 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+)
+
+func newGenerateMiddlewareCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "middleware [name]",
+		Short: "Generate a Fiber middleware with config and tests",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return generateMiddleware(args[0])
+		},
+	}
+}
+
+func generateMiddleware(name string) error {
+	fileName := toSnakeCase(name)
+
+	data := struct {
+		Name    string
+		VarName string
+	}{
+		Name:    toPascalCase(name),
+		VarName: toLowerCamelCase(name),
+	}
+
+	dir := filepath.Join(layout.HTTP, "middleware")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	path := filepath.Join(dir, fileName+".go")
+	if err := generateFile(path, middlewareTemplate, data); err != nil {
+		return err
+	}
+
+	testPath := filepath.Join(dir, fileName+"_test.go")
+	if err := generateFile(testPath, middlewareTestTemplate, data); err != nil {
+		return err
+	}
+
+	fmt.Printf("✅ Generated middleware: %s\n", path)
+	fmt.Printf("✅ Generated middleware test: %s\n", testPath)
+	fmt.Printf("\nRegister it in your server setup:\n\n")
+	fmt.Printf("\tapp.Use(middleware.%sMiddleware(middleware.Default%sConfig()))\n\n", data.Name, data.Name)
+	fmt.Printf("or for a route group only:\n\n")
+	fmt.Printf("\tapi := app.Group(\"/api\", middleware.%sMiddleware(middleware.Default%sConfig()))\n", data.Name, data.Name)
+	return nil
+}
+
+const middlewareTemplate = `package middleware
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+// {{.Name}}Config holds {{.Name}} middleware configuration
+type {{.Name}}Config struct {
+	// Next skips the middleware when it returns true
+	Next func(c *fiber.Ctx) bool
+
+	// TODO: Add your options here
+}
+
+// Default{{.Name}}Config returns default {{.Name}} middleware config
+func Default{{.Name}}Config() {{.Name}}Config {
+	return {{.Name}}Config{}
+}
+
+// {{.Name}}Middleware returns {{.Name}} middleware
+func {{.Name}}Middleware(config {{.Name}}Config) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if config.Next != nil && config.Next(c) {
+			return c.Next()
+		}
+
+		// TODO: Implement logic before the handler
+
+		if err := c.Next(); err != nil {
+			return err
+		}
+
+		// TODO: Implement logic after the handler
+
+		return nil
+	}
+}
+`
+
+const middlewareTestTemplate = `package middleware
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func Test{{.Name}}Middleware(t *testing.T) {
+	app := fiber.New()
+	app.Use({{.Name}}Middleware(Default{{.Name}}Config()))
+	app.Get("/", func(c *fiber.Ctx) error {
+		return c.SendString("ok")
+	})
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != fiber.StatusOK {
+		t.Errorf("status = %d, want %d", resp.StatusCode, fiber.StatusOK)
+	}
+}
+
+func Test{{.Name}}MiddlewareNext(t *testing.T) {
+	config := Default{{.Name}}Config()
+	config.Next = func(c *fiber.Ctx) bool {
+		return c.Path() == "/health"
+	}
+
+	app := fiber.New()
+	app.Use({{.Name}}Middleware(config))
+	app.Get("/health", func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusNoContent)
+	})
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/health", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != fiber.StatusNoContent {
+		t.Errorf("status = %d, want %d", resp.StatusCode, fiber.StatusNoContent)
+	}
+}
+`
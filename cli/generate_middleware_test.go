@@ -0,0 +1,38 @@
+package main
+
+import (
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerateMiddlewareUsesLayoutRoot(t *testing.T) {
+	mkdirs(t, "app/transport/http")
+	useLayout(t, "auto")
+
+	if err := generateMiddleware("rate_limit"); err != nil {
+		t.Fatalf("generateMiddleware: %v", err)
+	}
+
+	dir := filepath.Join("app", "transport", "http", "middleware")
+	for _, name := range []string{"rate_limit.go", "rate_limit_test.go"} {
+		path := filepath.Join(dir, name)
+		source, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("%s not generated under the detected root: %v", name, err)
+		}
+		if _, err := parser.ParseFile(token.NewFileSet(), path, source, 0); err != nil {
+			t.Errorf("%s does not parse: %v", name, err)
+		}
+		if !strings.Contains(string(source), "RateLimitMiddleware") {
+			t.Errorf("%s does not reference RateLimitMiddleware", name)
+		}
+	}
+
+	if _, err := os.Stat("internal"); !os.IsNotExist(err) {
+		t.Error("generator wrote to the default internal root")
+	}
+}
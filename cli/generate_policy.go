@@ -0,0 +1,294 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+)
+
+func newGeneratePolicyCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "policy",
+		Short: "Generate policies.yaml with per-route rate and body size limits and its loader",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return generatePolicy()
+		},
+	}
+}
+
+func generatePolicy() error {
+	configPath := filepath.Join("config", "policies.yaml")
+	if _, err := os.Stat(configPath); err == nil {
+		fmt.Printf("⚠️  %s already exists, leaving it unchanged\n", configPath)
+	} else {
+		if err := os.MkdirAll("config", 0755); err != nil {
+			return err
+		}
+		if err := generateFile(configPath, policyConfigTemplate, nil); err != nil {
+			return err
+		}
+		fmt.Printf("✅ Generated policies: %s\n", configPath)
+	}
+
+	dir := filepath.Join(layout.Infrastructure, "policy")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	path := filepath.Join(dir, "policy.go")
+	if err := generateFile(path, policyLoaderTemplate, nil); err != nil {
+		return err
+	}
+
+	fmt.Printf("✅ Generated policy loader: %s\n", path)
+	fmt.Printf("\nInstall it before routes are registered:\n\n")
+	fmt.Printf("\tfx.Invoke(func(srv *server.Server) error {\n")
+	fmt.Printf("\t\tpolicies, err := policy.Load(%q)\n", configPath)
+	fmt.Printf("\t\tif err != nil {\n\t\t\treturn err\n\t\t}\n")
+	fmt.Printf("\t\tsrv.App().Use(policies.Middleware())\n\t\treturn nil\n\t})\n\n")
+	fmt.Printf("Raise server.body_limit in config.yaml to the largest body_limit of the policies,\n")
+	fmt.Printf("larger bodies are rejected before middleware runs.\n")
+	return nil
+}
+
+const policyConfigTemplate = `# Request policies, routes are matched in order by method and path prefix,
+# the first match wins and unmatched requests use default.
+# body_limit accepts bytes or KB/MB/GB, rate_limit.key is ip or header:<Name>
+default:
+  body_limit: 1MB
+  rate_limit:
+    max: 100
+    window: 1m
+    key: ip
+
+routes:
+  - name: auth
+    path: /api/v1/auth
+    methods: [POST]
+    body_limit: 16KB
+    rate_limit:
+      max: 10
+      window: 1m
+      key: ip
+
+  - name: uploads
+    path: /api/v1/files
+    methods: [POST, PUT]
+    body_limit: 50MB
+    rate_limit:
+      max: 20
+      window: 1m
+      key: header:X-API-Key
+
+  - name: health
+    path: /livez
+    # No rate limit for probes
+    rate_limit:
+      max: 0
+`
+
+const policyLoaderTemplate = `package policy
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gopkg.in/yaml.v3"
+
+	"github.com/yourorg/microkit/pkg/middleware"
+)
+
+// RateLimit limits requests per client, Max 0 disables the limit
+type RateLimit struct {
+	Max    int           ` + "`yaml:\"max\"`" + `
+	Window time.Duration ` + "`yaml:\"window\"`" + `
+	// Key identifies the client: ip or header:<Name>
+	Key string ` + "`yaml:\"key\"`" + `
+}
+
+// Policy is a set of limits applied to a request
+type Policy struct {
+	BodyLimit string     ` + "`yaml:\"body_limit\"`" + `
+	RateLimit *RateLimit ` + "`yaml:\"rate_limit\"`" + `
+}
+
+// Route is a policy for requests matching method and path prefix
+type Route struct {
+	Name    string   ` + "`yaml:\"name\"`" + `
+	Path    string   ` + "`yaml:\"path\"`" + `
+	Methods []string ` + "`yaml:\"methods\"`" + `
+	Policy  ` + "`yaml:\",inline\"`" + `
+}
+
+// Policies is the policies.yaml content
+type Policies struct {
+	Default Policy  ` + "`yaml:\"default\"`" + `
+	Routes  []Route ` + "`yaml:\"routes\"`" + `
+}
+
+// Load reads and validates policies, unknown keys are rejected
+func Load(path string) (*Policies, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read policies: %w", err)
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+
+	var policies Policies
+	if err := decoder.Decode(&policies); err != nil {
+		return nil, fmt.Errorf("failed to parse policies: %w", err)
+	}
+
+	if _, err := policies.Default.compile("default"); err != nil {
+		return nil, err
+	}
+	for _, route := range policies.Routes {
+		if route.Path == "" {
+			return nil, fmt.Errorf("policy %s: path is required", route.Name)
+		}
+		if _, err := route.compile(route.Name); err != nil {
+			return nil, err
+		}
+	}
+	return &policies, nil
+}
+
+// compiled is a policy ready to be applied
+type compiled struct {
+	route     Route
+	bodyLimit int
+	limiter   fiber.Handler
+}
+
+func (p Policy) compile(name string) (*compiled, error) {
+	c := &compiled{}
+
+	if p.BodyLimit != "" {
+		limit, err := parseSize(p.BodyLimit)
+		if err != nil {
+			return nil, fmt.Errorf("policy %s: invalid body_limit: %w", name, err)
+		}
+		c.bodyLimit = limit
+	}
+
+	if rl := p.RateLimit; rl != nil && rl.Max > 0 {
+		if rl.Window <= 0 {
+			return nil, fmt.Errorf("policy %s: rate_limit.window must be positive", name)
+		}
+		key, err := keyGenerator(rl.Key)
+		if err != nil {
+			return nil, fmt.Errorf("policy %s: %w", name, err)
+		}
+
+		config := middleware.DefaultRateLimitConfig()
+		config.Max = rl.Max
+		config.Expiration = rl.Window
+		config.KeyGenerator = key
+		c.limiter = middleware.RateLimitMiddleware(config)
+	}
+	return c, nil
+}
+
+// Middleware applies the first matching route policy or the default one.
+// Every route has its own rate limit counters
+func (p *Policies) Middleware() fiber.Handler {
+	// Load validated policies, compile cannot fail here
+	fallback, _ := p.Default.compile("default")
+	routes := make([]*compiled, 0, len(p.Routes))
+	for _, route := range p.Routes {
+		c, _ := route.compile(route.Name)
+		c.route = route
+		routes = append(routes, c)
+	}
+
+	return func(c *fiber.Ctx) error {
+		policy := fallback
+		for _, route := range routes {
+			if route.matches(c.Method(), c.Path()) {
+				policy = route
+				break
+			}
+		}
+
+		if policy.bodyLimit > 0 && len(c.Request().Body()) > policy.bodyLimit {
+			return fiber.ErrRequestEntityTooLarge
+		}
+		if policy.limiter != nil {
+			return policy.limiter(c)
+		}
+		return c.Next()
+	}
+}
+
+func (c *compiled) matches(method, path string) bool {
+	if !strings.HasPrefix(path, c.route.Path) {
+		return false
+	}
+	if len(c.route.Methods) == 0 {
+		return true
+	}
+	for _, m := range c.route.Methods {
+		if strings.EqualFold(m, method) {
+			return true
+		}
+	}
+	return false
+}
+
+func keyGenerator(key string) (func(c *fiber.Ctx) string, error) {
+	switch {
+	case key == "" || key == "ip":
+		return func(c *fiber.Ctx) string { return c.IP() }, nil
+	case strings.HasPrefix(key, "header:"):
+		header := strings.TrimPrefix(key, "header:")
+		// Requests without the header are limited by IP
+		return func(c *fiber.Ctx) string {
+			if value := c.Get(header); value != "" {
+				return header + ":" + value
+			}
+			return c.IP()
+		}, nil
+	}
+	return nil, fmt.Errorf("unknown rate_limit.key %q, expected ip or header:<Name>", key)
+}
+
+// sizeUnits are checked in order, B must stay last
+var sizeUnits = []struct {
+	suffix string
+	size   int
+}{
+	{"GB", 1 << 30},
+	{"MB", 1 << 20},
+	{"KB", 1 << 10},
+	{"B", 1},
+}
+
+// parseSize parses 1048576, 512KB, 10MB or 1GB
+func parseSize(s string) (int, error) {
+	value := strings.ToUpper(strings.TrimSpace(s))
+	multiplier := 1
+	for _, unit := range sizeUnits {
+		if strings.HasSuffix(value, unit.suffix) {
+			value = strings.TrimSpace(strings.TrimSuffix(value, unit.suffix))
+			multiplier = unit.size
+			break
+		}
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	return n * multiplier, nil
+}
+`
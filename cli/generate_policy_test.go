@@ -0,0 +1,55 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestGeneratePolicyCompiles generates the policy loader and builds it
+// against this module, the template imports are rewritten from the
+// placeholder module path
+func TestGeneratePolicyCompiles(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs go build")
+	}
+
+	root, err := filepath.Abs("..")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	project := t.TempDir()
+	t.Chdir(project)
+	if err := generatePolicy(); err != nil {
+		t.Fatalf("generatePolicy: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(project, "config", "policies.yaml")); err != nil {
+		t.Errorf("policies.yaml not generated: %v", err)
+	}
+	source, err := os.ReadFile(filepath.Join(project, layout.Infrastructure, "policy", "policy.go"))
+	if err != nil {
+		t.Fatalf("loader not generated: %v", err)
+	}
+
+	// Directories starting with _ are ignored by ./... patterns
+	dir, err := os.MkdirTemp(root, "_policytest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	rewritten := strings.ReplaceAll(string(source), "github.com/yourorg/microkit/pkg/", "github.com/alimzhanovlr/sdk/")
+	if err := os.WriteFile(filepath.Join(dir, "policy.go"), []byte(rewritten), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := exec.Command("go", "build", "./"+filepath.Base(dir))
+	cmd.Dir = root
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("generated loader does not compile: %v\n%s", err, out)
+	}
+}
@@ -0,0 +1,427 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+)
+
+func newGenerateSagaCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "saga [name]",
+		Short: "Generate a saga orchestrator with steps, compensations and persisted state",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return generateSaga(args[0])
+		},
+	}
+}
+
+func generateSaga(name string) error {
+	fileName := toSnakeCase(name) + "_saga.go"
+
+	data := struct {
+		Name    string
+		VarName string
+		Table   string
+	}{
+		Name:    toPascalCase(name),
+		VarName: toLowerCamelCase(name),
+		Table:   toSnakeCase(name) + "_sagas",
+	}
+
+	files := []struct {
+		dir  string
+		tmpl string
+		kind string
+	}{
+		{layout.Entity, sagaEntityTemplate, "saga state entity"},
+		{layout.Repository, sagaRepositoryInterfaceTemplate, "saga repository interface"},
+		{layout.RepositoryImpl, sagaRepositoryImplTemplate, "saga repository implementation"},
+		{layout.Usecase, sagaOrchestratorTemplate, "saga orchestrator"},
+	}
+
+	for _, f := range files {
+		if err := os.MkdirAll(f.dir, 0755); err != nil {
+			return err
+		}
+
+		path := filepath.Join(f.dir, fileName)
+		if err := generateFile(path, f.tmpl, data); err != nil {
+			return err
+		}
+		fmt.Printf("✅ Generated %s: %s\n", f.kind, path)
+	}
+
+	fmt.Printf("\nNext steps:\n")
+	fmt.Printf("  1. Add fields to %sSagaData and implement steps in %s\n", data.Name, filepath.Join(layout.Usecase, fileName))
+	fmt.Printf("  2. Create table %s and implement the repository\n", data.Table)
+	fmt.Printf("  3. Run %sSaga.RecoverExpired periodically (scheduler) to compensate timed out and interrupted sagas\n", data.Name)
+	return nil
+}
+
+const sagaEntityTemplate = `package {{package "entity"}}
+
+import "time"
+
+// {{.Name}}SagaStatus is the {{.Name}} saga lifecycle status
+type {{.Name}}SagaStatus string
+
+const (
+	{{.Name}}SagaRunning      {{.Name}}SagaStatus = "running"
+	{{.Name}}SagaCompleted    {{.Name}}SagaStatus = "completed"
+	{{.Name}}SagaCompensating {{.Name}}SagaStatus = "compensating"
+	{{.Name}}SagaCompensated  {{.Name}}SagaStatus = "compensated"
+	// {{.Name}}SagaFailed means compensation failed and manual action is required
+	{{.Name}}SagaFailed {{.Name}}SagaStatus = "failed"
+)
+
+// {{.Name}}SagaData is the business payload passed between steps.
+// Steps store ids of created resources here so compensations can undo them
+type {{.Name}}SagaData struct {
+	// TODO: Add your fields here
+}
+
+// {{.Name}}Saga is the persisted {{.Name}} saga state
+type {{.Name}}Saga struct {
+	ID     string
+	Status {{.Name}}SagaStatus
+	// Step is the index of the next step to execute, or of the next step
+	// to compensate while compensating
+	Step      int
+	Data      {{.Name}}SagaData
+	LastError string
+	// Deadline after which a running saga is compensated
+	Deadline  time.Time
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Done reports whether the saga reached a final status
+func (s *{{.Name}}Saga) Done() bool {
+	switch s.Status {
+	case {{.Name}}SagaCompleted, {{.Name}}SagaCompensated, {{.Name}}SagaFailed:
+		return true
+	}
+	return false
+}
+`
+
+const sagaRepositoryInterfaceTemplate = `package {{package "repository"}}
+
+import (
+	"context"
+	"time"
+
+	{{import "entity"}}
+)
+
+// {{.Name}}SagaRepository persists {{.Name}} saga state
+type {{.Name}}SagaRepository interface {
+	// Create stores a new saga
+	Create(ctx context.Context, saga *entity.{{.Name}}Saga) error
+
+	// GetByID retrieves a saga by ID
+	GetByID(ctx context.Context, id string) (*entity.{{.Name}}Saga, error)
+
+	// Update stores saga progress, called after every step
+	Update(ctx context.Context, saga *entity.{{.Name}}Saga) error
+
+	// ListExpired returns running and compensating sagas with deadline
+	// before now, compensating ones were interrupted by a crash
+	ListExpired(ctx context.Context, now time.Time, limit int) ([]*entity.{{.Name}}Saga, error)
+}
+`
+
+const sagaRepositoryImplTemplate = `package {{package "repository_impl"}}
+
+import (
+	"context"
+	"time"
+
+	{{import "entity"}}
+	{{import "repository"}}
+
+	"github.com/yourorg/microkit/pkg/errors"
+	"github.com/yourorg/microkit/pkg/logger"
+)
+
+// {{.VarName}}SagaRepository implements {{.Name}}SagaRepository interface.
+// Data is stored as JSON, e.g. table {{.Table}}(id, status, step, data jsonb,
+// last_error, deadline, created_at, updated_at) with index on (status, deadline)
+type {{.VarName}}SagaRepository struct {
+	logger *logger.Logger
+	// TODO: Add database connection
+}
+
+// New{{.Name}}SagaRepository creates a new {{.Name}}SagaRepository
+func New{{.Name}}SagaRepository(logger *logger.Logger) repository.{{.Name}}SagaRepository {
+	return &{{.VarName}}SagaRepository{
+		logger: logger,
+	}
+}
+
+// Create stores a new saga
+func (r *{{.VarName}}SagaRepository) Create(ctx context.Context, saga *entity.{{.Name}}Saga) error {
+	// TODO: INSERT INTO {{.Table}}
+	return nil
+}
+
+// GetByID retrieves a saga by ID
+func (r *{{.VarName}}SagaRepository) GetByID(ctx context.Context, id string) (*entity.{{.Name}}Saga, error) {
+	// TODO: SELECT ... FROM {{.Table}} WHERE id = $1
+	return nil, errors.ErrNotFound
+}
+
+// Update stores saga progress
+func (r *{{.VarName}}SagaRepository) Update(ctx context.Context, saga *entity.{{.Name}}Saga) error {
+	// TODO: UPDATE {{.Table}} SET status, step, data, last_error, updated_at WHERE id = $1
+	return nil
+}
+
+// ListExpired returns running and compensating sagas with deadline before now
+func (r *{{.VarName}}SagaRepository) ListExpired(ctx context.Context, now time.Time, limit int) ([]*entity.{{.Name}}Saga, error) {
+	// TODO: SELECT ... FROM {{.Table}} WHERE status IN ('running', 'compensating') AND deadline < $1 LIMIT $2 FOR UPDATE SKIP LOCKED
+	return nil, nil
+}
+`
+
+const sagaOrchestratorTemplate = `package {{package "usecase"}}
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	{{import "entity"}}
+	{{import "repository"}}
+
+	"github.com/yourorg/microkit/pkg/idgen"
+	"github.com/yourorg/microkit/pkg/logger"
+	"github.com/yourorg/microkit/pkg/tracing"
+)
+
+// {{.Name}}SagaStep is one step of the {{.Name}} saga.
+// Execute and Compensate must be idempotent: after a crash the step
+// is executed again from the persisted state
+type {{.Name}}SagaStep interface {
+	// Name identifies the step in logs and traces
+	Name() string
+	// Execute performs the step, it may update saga data
+	Execute(ctx context.Context, data *entity.{{.Name}}SagaData) error
+	// Compensate undoes a successfully executed step
+	Compensate(ctx context.Context, data *entity.{{.Name}}SagaData) error
+}
+
+// {{.Name}}SagaConfig holds {{.Name}} saga timeouts
+type {{.Name}}SagaConfig struct {
+	// Timeout is the whole saga deadline, expired sagas are compensated by RecoverExpired
+	Timeout time.Duration
+	// StepTimeout limits a single Execute or Compensate call
+	StepTimeout time.Duration
+}
+
+// Default{{.Name}}SagaConfig returns default {{.Name}} saga config
+func Default{{.Name}}SagaConfig() {{.Name}}SagaConfig {
+	return {{.Name}}SagaConfig{
+		Timeout:     5 * time.Minute,
+		StepTimeout: 30 * time.Second,
+	}
+}
+
+// {{.Name}}Saga orchestrates {{.Name}} steps and compensations
+type {{.Name}}Saga struct {
+	config {{.Name}}SagaConfig
+	steps  []{{.Name}}SagaStep
+	repo   repository.{{.Name}}SagaRepository
+	logger *logger.Logger
+	tracer *tracing.Tracer
+}
+
+// New{{.Name}}Saga creates a new {{.Name}}Saga
+func New{{.Name}}Saga(
+	config {{.Name}}SagaConfig,
+	repo repository.{{.Name}}SagaRepository,
+	logger *logger.Logger,
+	tracer *tracing.Tracer,
+) *{{.Name}}Saga {
+	return &{{.Name}}Saga{
+		config: config,
+		repo:   repo,
+		logger: logger,
+		tracer: tracer,
+		steps:  []{{.Name}}SagaStep{
+			// TODO: Add steps in execution order
+		},
+	}
+}
+
+// Start creates and runs a new saga
+func (s *{{.Name}}Saga) Start(ctx context.Context, data entity.{{.Name}}SagaData) (*entity.{{.Name}}Saga, error) {
+	now := time.Now()
+	saga := &entity.{{.Name}}Saga{
+		ID:        idgen.NewString(),
+		Status:    entity.{{.Name}}SagaRunning,
+		Data:      data,
+		Deadline:  now.Add(s.config.Timeout),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if err := s.repo.Create(ctx, saga); err != nil {
+		return nil, fmt.Errorf("failed to create saga: %w", err)
+	}
+
+	return saga, s.run(ctx, saga)
+}
+
+// Resume continues a saga from its persisted state, e.g. after restart
+func (s *{{.Name}}Saga) Resume(ctx context.Context, id string) (*entity.{{.Name}}Saga, error) {
+	saga, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load saga: %w", err)
+	}
+	return saga, s.run(ctx, saga)
+}
+
+// RecoverExpired compensates running sagas past their deadline and finishes
+// compensations interrupted by a crash, run it periodically
+func (s *{{.Name}}Saga) RecoverExpired(ctx context.Context, limit int) error {
+	sagas, err := s.repo.ListExpired(ctx, time.Now(), limit)
+	if err != nil {
+		return fmt.Errorf("failed to list expired sagas: %w", err)
+	}
+
+	var errs []error
+	for _, saga := range sagas {
+		if saga.Status == entity.{{.Name}}SagaCompensating {
+			// Keep the error that started the compensation
+			s.logger.Warn("{{.Name}} saga compensation was interrupted, resuming", logger.String("saga_id", saga.ID))
+		} else {
+			s.logger.Warn("{{.Name}} saga timed out, compensating", logger.String("saga_id", saga.ID))
+			saga.LastError = "saga timed out"
+		}
+		if err := s.compensate(ctx, saga); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// run executes remaining steps and compensates on failure
+func (s *{{.Name}}Saga) run(ctx context.Context, saga *entity.{{.Name}}Saga) error {
+	ctx, span := s.tracer.Start(ctx, "{{.Name}}Saga.run")
+	defer span.End()
+
+	if saga.Status == entity.{{.Name}}SagaCompensating {
+		return s.abort(ctx, saga)
+	}
+	if saga.Done() {
+		return nil
+	}
+
+	for saga.Step < len(s.steps) {
+		if time.Now().After(saga.Deadline) {
+			saga.LastError = "saga timed out"
+			return s.abort(ctx, saga)
+		}
+
+		step := s.steps[saga.Step]
+		if err := s.call(ctx, step.Name()+".execute", func(ctx context.Context) error {
+			return step.Execute(ctx, &saga.Data)
+		}); err != nil {
+			s.logger.Error("{{.Name}} saga step failed",
+				logger.String("saga_id", saga.ID),
+				logger.String("step", step.Name()),
+				logger.Error(err),
+			)
+			saga.LastError = fmt.Sprintf("%s: %v", step.Name(), err)
+			return s.abort(ctx, saga)
+		}
+
+		saga.Step++
+		if err := s.save(ctx, saga); err != nil {
+			return err
+		}
+	}
+
+	saga.Status = entity.{{.Name}}SagaCompleted
+	return s.save(ctx, saga)
+}
+
+// abort compensates the saga and returns the error that caused it
+func (s *{{.Name}}Saga) abort(ctx context.Context, saga *entity.{{.Name}}Saga) error {
+	if err := s.compensate(ctx, saga); err != nil {
+		return err
+	}
+	return fmt.Errorf("saga %s compensated: %s", saga.ID, saga.LastError)
+}
+
+// compensate undoes executed steps in reverse order
+func (s *{{.Name}}Saga) compensate(ctx context.Context, saga *entity.{{.Name}}Saga) error {
+	if saga.Status != entity.{{.Name}}SagaCompensating {
+		saga.Status = entity.{{.Name}}SagaCompensating
+		// Step points to the failed step which has nothing to undo
+		if err := s.save(ctx, saga); err != nil {
+			return err
+		}
+	}
+
+	for saga.Step > 0 {
+		step := s.steps[saga.Step-1]
+		if err := s.call(ctx, step.Name()+".compensate", func(ctx context.Context) error {
+			return step.Compensate(ctx, &saga.Data)
+		}); err != nil {
+			saga.Status = entity.{{.Name}}SagaFailed
+			saga.LastError = fmt.Sprintf("compensate %s: %v", step.Name(), err)
+			s.logger.Error("{{.Name}} saga compensation failed, manual action required",
+				logger.String("saga_id", saga.ID),
+				logger.String("step", step.Name()),
+				logger.Error(err),
+			)
+			if saveErr := s.save(ctx, saga); saveErr != nil {
+				return saveErr
+			}
+			return fmt.Errorf("saga %s: %s", saga.ID, saga.LastError)
+		}
+
+		saga.Step--
+		if err := s.save(ctx, saga); err != nil {
+			return err
+		}
+	}
+
+	saga.Status = entity.{{.Name}}SagaCompensated
+	return s.save(ctx, saga)
+}
+
+// call runs a step operation with step timeout and span
+func (s *{{.Name}}Saga) call(ctx context.Context, name string, fn func(ctx context.Context) error) error {
+	ctx, span := s.tracer.Start(ctx, "{{.Name}}Saga."+name)
+	defer span.End()
+
+	if s.config.StepTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.config.StepTimeout)
+		defer cancel()
+	}
+
+	err := fn(ctx)
+	if err != nil {
+		span.RecordError(err)
+	}
+	return err
+}
+
+// save persists saga progress
+func (s *{{.Name}}Saga) save(ctx context.Context, saga *entity.{{.Name}}Saga) error {
+	saga.UpdatedAt = time.Now()
+	if err := s.repo.Update(ctx, saga); err != nil {
+		return fmt.Errorf("failed to save saga %s: %w", saga.ID, err)
+	}
+	return nil
+}
+`
@@ -0,0 +1,243 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+)
+
+func newGenerateStreamCmd() *cobra.Command {
+	var kind string
+
+	cmd := &cobra.Command{
+		Use:   "stream [name]",
+		Short: "Generate a WebSocket or SSE handler broadcasting through a hub",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return generateStream(args[0], kind)
+		},
+	}
+
+	cmd.Flags().StringVar(&kind, "kind", "ws", "Stream kind: ws or sse")
+
+	return cmd
+}
+
+func generateStream(name, kind string) error {
+	templates := map[string]string{
+		"ws":  streamWebSocketTemplate,
+		"sse": streamSSETemplate,
+	}
+	tmpl, ok := templates[kind]
+	if !ok {
+		return fmt.Errorf("unknown stream kind %q, expected ws or sse", kind)
+	}
+
+	data := struct {
+		Name    string
+		VarName string
+	}{
+		Name:    toPascalCase(name),
+		VarName: toLowerCamelCase(name),
+	}
+
+	dir := layout.HTTP
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	path := filepath.Join(dir, toSnakeCase(name)+"_stream.go")
+	if err := generateFile(path, tmpl, data); err != nil {
+		return err
+	}
+
+	fmt.Printf("✅ Generated %s stream handler: %s\n", kind, path)
+	fmt.Printf("\nProvide it and register routes:\n\n")
+	fmt.Printf("\tfx.Provide(http.New%sStreamHandler)\n", data.Name)
+	fmt.Printf("\tfx.Invoke(func(srv *server.Server, h *http.%sStreamHandler) {\n", data.Name)
+	fmt.Printf("\t\th.RegisterRoutes(srv.App())\n\t})\n\n")
+	fmt.Printf("Publish events with h.Broadcast(msg). The hub is created by server.NewHub\n")
+	fmt.Printf("and closed on shutdown, so open streams do not block it.\n")
+	return nil
+}
+
+const streamWebSocketTemplate = `package {{package "http"}}
+
+import (
+	"time"
+
+	"github.com/gofiber/contrib/websocket"
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/yourorg/microkit/pkg/logger"
+	"github.com/yourorg/microkit/pkg/server"
+)
+
+const (
+	{{.VarName}}WriteWait  = 10 * time.Second
+	{{.VarName}}PongWait   = 60 * time.Second
+	{{.VarName}}PingPeriod = {{.VarName}}PongWait * 9 / 10
+	{{.VarName}}MaxMessage = 64 << 10
+)
+
+// {{.Name}}StreamHandler streams {{.Name}} events to WebSocket clients
+type {{.Name}}StreamHandler struct {
+	hub    *server.Hub
+	logger *logger.Logger
+}
+
+// New{{.Name}}StreamHandler creates a new {{.Name}}StreamHandler, its hub is closed on server shutdown
+func New{{.Name}}StreamHandler(srv *server.Server, logger *logger.Logger) *{{.Name}}StreamHandler {
+	return &{{.Name}}StreamHandler{
+		hub:    srv.NewHub(64),
+		logger: logger,
+	}
+}
+
+// RegisterRoutes registers {{.Name}} stream routes
+func (h *{{.Name}}StreamHandler) RegisterRoutes(router fiber.Router) {
+	router.Get("/{{.VarName}}/ws", server.WebSocket(h.serve))
+}
+
+// Broadcast sends the message to all connected clients
+func (h *{{.Name}}StreamHandler) Broadcast(msg []byte) int {
+	return h.hub.Broadcast(msg)
+}
+
+// serve writes hub messages and pings until the client disconnects or the hub is closed
+func (h *{{.Name}}StreamHandler) serve(conn *websocket.Conn) {
+	client, err := h.hub.Subscribe()
+	if err != nil {
+		conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseGoingAway, "shutting down"))
+		return
+	}
+	defer h.hub.Unsubscribe(client)
+
+	done := make(chan struct{})
+	go h.read(conn, done)
+
+	ticker := time.NewTicker({{.VarName}}PingPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case msg, ok := <-client.Messages():
+			conn.SetWriteDeadline(time.Now().Add({{.VarName}}WriteWait))
+			if !ok {
+				// Hub closed on shutdown or the client is too slow
+				conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseGoingAway, ""))
+				return
+			}
+			if err := conn.WriteMessage(websocket.TextMessage, msg); err != nil {
+				return
+			}
+		case <-ticker.C:
+			conn.SetWriteDeadline(time.Now().Add({{.VarName}}WriteWait))
+			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		case <-done:
+			return
+		}
+	}
+}
+
+// read handles client messages until the connection is closed
+func (h *{{.Name}}StreamHandler) read(conn *websocket.Conn, done chan struct{}) {
+	defer close(done)
+
+	conn.SetReadLimit({{.VarName}}MaxMessage)
+	conn.SetReadDeadline(time.Now().Add({{.VarName}}PongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add({{.VarName}}PongWait))
+	})
+
+	for {
+		_, msg, err := conn.ReadMessage()
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseNormalClosure) {
+				h.logger.Warn("{{.Name}} stream closed unexpectedly", logger.Error(err))
+			}
+			return
+		}
+
+		// TODO: Handle client message
+		_ = msg
+	}
+}
+`
+
+const streamSSETemplate = `package {{package "http"}}
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/yourorg/microkit/pkg/logger"
+	"github.com/yourorg/microkit/pkg/server"
+)
+
+// {{.VarName}}PingPeriod keeps proxies from closing idle streams
+const {{.VarName}}PingPeriod = 15 * time.Second
+
+// {{.Name}}StreamHandler streams {{.Name}} events with Server-Sent Events
+type {{.Name}}StreamHandler struct {
+	hub    *server.Hub
+	logger *logger.Logger
+}
+
+// New{{.Name}}StreamHandler creates a new {{.Name}}StreamHandler, its hub is closed on server shutdown
+func New{{.Name}}StreamHandler(srv *server.Server, logger *logger.Logger) *{{.Name}}StreamHandler {
+	return &{{.Name}}StreamHandler{
+		hub:    srv.NewHub(64),
+		logger: logger,
+	}
+}
+
+// RegisterRoutes registers {{.Name}} stream routes
+func (h *{{.Name}}StreamHandler) RegisterRoutes(router fiber.Router) {
+	router.Get("/{{.VarName}}/events", h.Stream)
+}
+
+// Broadcast sends the message to all connected clients
+func (h *{{.Name}}StreamHandler) Broadcast(msg []byte) int {
+	return h.hub.Broadcast(msg)
+}
+
+// Stream handles GET /{{.VarName}}/events
+func (h *{{.Name}}StreamHandler) Stream(c *fiber.Ctx) error {
+	client, err := h.hub.Subscribe()
+	if err != nil {
+		return fiber.ErrServiceUnavailable
+	}
+
+	// TODO: Replay missed events after server.LastEventID(c)
+
+	return server.SSE(c, func(w *server.SSEWriter) {
+		defer h.hub.Unsubscribe(client)
+
+		ticker := time.NewTicker({{.VarName}}PingPeriod)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case msg, ok := <-client.Messages():
+				// Hub closed on shutdown or the client is too slow
+				if !ok {
+					return
+				}
+				if err := w.Send(server.SSEEvent{Event: "{{.VarName}}", Data: string(msg)}); err != nil {
+					return
+				}
+			case <-ticker.C:
+				if err := w.Ping(); err != nil {
+					return
+				}
+			}
+		}
+	})
+}
+`
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestGenerateHandlerCompiles generates a handler and builds it against this
+// module, like TestGeneratePolicyCompiles
+func TestGenerateHandlerCompiles(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs go build")
+	}
+
+	root, err := filepath.Abs("..")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	project := t.TempDir()
+	t.Chdir(project)
+	if err := generateHandler("order"); err != nil {
+		t.Fatalf("generateHandler: %v", err)
+	}
+	source, err := os.ReadFile(filepath.Join(project, layout.HTTP, "order.go"))
+	if err != nil {
+		t.Fatalf("handler not generated: %v", err)
+	}
+
+	// Directories starting with _ are ignored by ./... patterns
+	dir, err := os.MkdirTemp(root, "_handlertest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	rewritten := strings.ReplaceAll(string(source), "github.com/yourorg/microkit/pkg/", "github.com/alimzhanovlr/sdk/")
+	if err := os.WriteFile(filepath.Join(dir, "order.go"), []byte(rewritten), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := exec.Command("go", "build", "./"+filepath.Base(dir))
+	cmd.Dir = root
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("generated handler does not compile: %v\n%s", err, out)
+	}
+}
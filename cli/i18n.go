@@ -0,0 +1,331 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/alimzhanovlr/sdk/config"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"github.com/spf13/cobra"
+	"golang.org/x/text/feature/plural"
+	"golang.org/x/text/language"
+	"gopkg.in/yaml.v3"
+)
+
+func newI18nCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "i18n",
+		Short: "Inspect translations",
+	}
+
+	cmd.AddCommand(newI18nLintCmd())
+
+	return cmd
+}
+
+func newI18nLintCmd() *cobra.Command {
+	var (
+		configPath   string
+		dir          string
+		defaultLang  string
+		ignoreUnused bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "lint",
+		Short: "Compare locale files against the default language",
+		Long: `Reports keys missing in or extra to each locale compared with the default
+language, missing plural forms, and default language keys not referenced by
+any string literal in Go code. Exits with non-zero code on problems, so it can
+run in CI. Must be run from the project root.
+
+Variant keys (welcome@gender=female) are optional in other locales, they
+are extra only when the base key is not in the default language.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// Locale settings come from config unless set by flags
+			if cfg, err := config.Load(configPath); err == nil {
+				if dir == "" {
+					dir = cfg.I18n.Path
+				}
+				if defaultLang == "" {
+					defaultLang = cfg.I18n.DefaultLanguage
+				}
+			}
+			if dir == "" {
+				dir = "./locales"
+			}
+			if defaultLang == "" {
+				defaultLang = "en"
+			}
+			return runI18nLint(dir, defaultLang, ignoreUnused)
+		},
+	}
+
+	cmd.Flags().StringVar(&configPath, "config", "config/config.yaml", "Path to config file with i18n section")
+	cmd.Flags().StringVar(&dir, "dir", "", "Locales directory (defaults to i18n.path)")
+	cmd.Flags().StringVar(&defaultLang, "default", "", "Default language (defaults to i18n.default_language)")
+	cmd.Flags().BoolVar(&ignoreUnused, "ignore-unused", false, "Skip unused keys check, e.g. for keys built at runtime")
+
+	return cmd
+}
+
+// localeFile holds messages of one locale by ID
+type localeFile struct {
+	lang     string
+	path     string
+	messages map[string]*i18n.Message
+}
+
+func runI18nLint(dir, defaultLang string, ignoreUnused bool) error {
+	locales, err := loadLocales(dir)
+	if err != nil {
+		return err
+	}
+
+	base, ok := locales[defaultLang]
+	if !ok {
+		return fmt.Errorf("default language file %s.yaml not found in %s", defaultLang, dir)
+	}
+
+	type check struct {
+		name string
+		run  func(d *diagnosis)
+	}
+	checks := []check{{
+		name: fmt.Sprintf("Default locale %s (%s)", defaultLang, base.path),
+		run:  func(d *diagnosis) { checkPluralForms(d, base) },
+	}}
+	for _, lang := range sortedKeys(locales) {
+		if lang == defaultLang {
+			continue
+		}
+		locale := locales[lang]
+		checks = append(checks, check{
+			name: fmt.Sprintf("Locale %s (%s)", lang, locale.path),
+			run: func(d *diagnosis) {
+				checkKeys(d, base, locale)
+				checkPluralForms(d, locale)
+			},
+		})
+	}
+	if !ignoreUnused {
+		checks = append(checks, check{
+			name: "Keys of " + base.path + " are used in code",
+			run:  func(d *diagnosis) { checkUnusedKeys(d, base) },
+		})
+	}
+
+	failed := 0
+	for _, check := range checks {
+		var d diagnosis
+		check.run(&d)
+
+		if len(d.problems) > 0 {
+			fmt.Printf("❌ %s\n", check.name)
+			failed++
+		} else {
+			fmt.Printf("✅ %s\n", check.name)
+		}
+		for _, p := range d.problems {
+			fmt.Printf("   - %s\n", p)
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d checks failed", failed, len(checks))
+	}
+	return nil
+}
+
+// loadLocales parses *.yaml files of dir the same way the i18n package does
+func loadLocales(dir string) (map[string]*localeFile, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.yaml"))
+	if err != nil {
+		return nil, err
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no locale files found in %s", dir)
+	}
+
+	unmarshal := map[string]i18n.UnmarshalFunc{"yaml": yaml.Unmarshal}
+	locales := make(map[string]*localeFile, len(paths))
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		file, err := i18n.ParseMessageFileBytes(data, path, unmarshal)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+
+		locale := &localeFile{
+			lang:     strings.TrimSuffix(filepath.Base(path), ".yaml"),
+			path:     path,
+			messages: make(map[string]*i18n.Message, len(file.Messages)),
+		}
+		for _, msg := range file.Messages {
+			locale.messages[msg.ID] = msg
+		}
+		locales[locale.lang] = locale
+	}
+	return locales, nil
+}
+
+// checkKeys reports keys missing in or extra to locale
+func checkKeys(d *diagnosis, base, locale *localeFile) {
+	for _, id := range sortedKeys(base.messages) {
+		if _, ok := locale.messages[id]; !ok && !isVariantID(id) {
+			d.problem("missing key %s", id)
+		}
+	}
+
+	for _, id := range sortedKeys(locale.messages) {
+		if _, ok := base.messages[id]; ok {
+			continue
+		}
+		if baseID, _, variant := strings.Cut(id, "@"); variant {
+			if _, ok := base.messages[baseID]; ok {
+				continue
+			}
+		}
+		d.problem("extra key %s, not in %s", id, base.path)
+	}
+}
+
+// checkPluralForms reports plural messages without forms the language needs,
+// e.g. few and many in Russian
+func checkPluralForms(d *diagnosis, locale *localeFile) {
+	tag, err := language.Parse(locale.lang)
+	if err != nil {
+		return
+	}
+	forms := pluralForms(tag)
+	for _, id := range sortedKeys(locale.messages) {
+		msg := locale.messages[id]
+		if !isPlural(msg) {
+			continue
+		}
+		var missing []string
+		for _, form := range forms {
+			if pluralForm(msg, form) == "" {
+				missing = append(missing, pluralNames[form])
+			}
+		}
+		if len(missing) > 0 {
+			d.problem("key %s misses plural forms %s", id, strings.Join(missing, ", "))
+		}
+	}
+}
+
+// checkUnusedKeys reports default language keys that appear in no Go string
+// literal. A literal ending with "." marks keys with that prefix as used, for
+// IDs built at runtime like "error." + code
+func checkUnusedKeys(d *diagnosis, base *localeFile) {
+	files, err := parseProjectFiles(".")
+	if err != nil {
+		d.problem("%v", err)
+		return
+	}
+
+	literals := map[string]bool{}
+	var prefixes []string
+	for _, file := range files {
+		ast.Inspect(file, func(n ast.Node) bool {
+			lit, ok := n.(*ast.BasicLit)
+			if !ok || lit.Kind != token.STRING {
+				return true
+			}
+			value, err := strconv.Unquote(lit.Value)
+			if err != nil {
+				return true
+			}
+			literals[value] = true
+			if strings.HasSuffix(value, ".") {
+				prefixes = append(prefixes, value)
+			}
+			return true
+		})
+	}
+
+	for _, id := range sortedKeys(base.messages) {
+		// Variants are selected at runtime by their base key
+		baseID, _, _ := strings.Cut(id, "@")
+		if literals[baseID] || hasAnyPrefix(baseID, prefixes) {
+			continue
+		}
+		d.problem("unused key %s, not referenced in Go code", id)
+	}
+}
+
+func isVariantID(id string) bool {
+	return strings.Contains(id, "@")
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(s, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// isPlural reports whether the message is written with plural forms
+func isPlural(msg *i18n.Message) bool {
+	return msg.Zero != "" || msg.One != "" || msg.Two != "" || msg.Few != "" || msg.Many != ""
+}
+
+func pluralForm(msg *i18n.Message, form plural.Form) string {
+	switch form {
+	case plural.Zero:
+		return msg.Zero
+	case plural.One:
+		return msg.One
+	case plural.Two:
+		return msg.Two
+	case plural.Few:
+		return msg.Few
+	case plural.Many:
+		return msg.Many
+	default:
+		return msg.Other
+	}
+}
+
+// pluralNames maps plural forms to message YAML keys
+var pluralNames = map[plural.Form]string{
+	plural.Zero:  "zero",
+	plural.One:   "one",
+	plural.Two:   "two",
+	plural.Few:   "few",
+	plural.Many:  "many",
+	plural.Other: "other",
+}
+
+// pluralForms returns plural forms used by the language in CLDR order. CLDR
+// rules depend on the last digits, so integers up to 1000, a fraction and
+// a million cover every form
+func pluralForms(tag language.Tag) []plural.Form {
+	used := map[plural.Form]bool{}
+	for n := 0; n <= 1000; n++ {
+		used[plural.Cardinal.MatchPlural(tag, n, 0, 0, 0, 0)] = true
+	}
+	used[plural.Cardinal.MatchPlural(tag, 1000000, 0, 0, 0, 0)] = true
+	// 1.5: integer 1, one visible fraction digit 5
+	used[plural.Cardinal.MatchPlural(tag, 1, 1, 1, 5, 5)] = true
+
+	var forms []plural.Form
+	for _, form := range []plural.Form{plural.Zero, plural.One, plural.Two, plural.Few, plural.Many, plural.Other} {
+		if used[form] {
+			forms = append(forms, form)
+		}
+	}
+	return forms
+}
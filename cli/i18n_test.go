@@ -0,0 +1,123 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeLintProject creates a project with locales and Go code in a temp
+// directory and changes into it
+func writeLintProject(t *testing.T, files map[string]string) {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Chdir(dir)
+}
+
+func TestI18nLintReportsProblems(t *testing.T) {
+	writeLintProject(t, map[string]string{
+		"locales/en.yaml": `
+welcome: "Welcome"
+welcome@gender=female: "Welcome, madam"
+error.not_found: "Not found"
+orphan: "Never used"
+items:
+  one: "{{.Count}} item"
+  other: "{{.Count}} items"
+`,
+		"locales/ru.yaml": `
+welcome: "Добро пожаловать"
+welcome@formality=formal: "Добро пожаловать, уважаемый"
+legacy: "Старый ключ"
+orphan: "Не используется"
+items:
+  one: "{{.Count}} товар"
+  other: "{{.Count}} товаров"
+`,
+		"internal/handler.go": `package internal
+
+func messages(code string) []string {
+	return []string{"welcome", "items", "error." + code}
+}
+`,
+	})
+
+	locales, err := loadLocales("locales")
+	if err != nil {
+		t.Fatalf("loadLocales() error = %v", err)
+	}
+	en, ru := locales["en"], locales["ru"]
+
+	var keys diagnosis
+	checkKeys(&keys, en, ru)
+	want := []string{
+		"missing key error.not_found",
+		"extra key legacy, not in locales/en.yaml",
+	}
+	if !reflect.DeepEqual(keys.problems, want) {
+		t.Errorf("checkKeys() = %q, want %q", keys.problems, want)
+	}
+
+	var plurals diagnosis
+	checkPluralForms(&plurals, ru)
+	if len(plurals.problems) != 1 || plurals.problems[0] != "key items misses plural forms few, many" {
+		t.Errorf("checkPluralForms(ru) = %q", plurals.problems)
+	}
+	var enPlurals diagnosis
+	checkPluralForms(&enPlurals, en)
+	if len(enPlurals.problems) != 0 {
+		t.Errorf("checkPluralForms(en) = %q", enPlurals.problems)
+	}
+
+	var unused diagnosis
+	checkUnusedKeys(&unused, en)
+	if len(unused.problems) != 1 || unused.problems[0] != "unused key orphan, not referenced in Go code" {
+		t.Errorf("checkUnusedKeys() = %q", unused.problems)
+	}
+
+	err = runI18nLint("locales", "en", false)
+	if err == nil || !strings.Contains(err.Error(), "2 of 3 checks failed") {
+		t.Errorf("runI18nLint() error = %v", err)
+	}
+}
+
+func TestI18nLintPasses(t *testing.T) {
+	writeLintProject(t, map[string]string{
+		"locales/en.yaml": `
+greeting: "Hello"
+dynamic.key: "Built at runtime"
+`,
+		"locales/kk.yaml": `
+greeting: "Сәлем"
+dynamic.key: "Орындалу кезінде"
+`,
+		"main.go": `package main
+
+func main() { println("greeting") }
+`,
+	})
+
+	if err := runI18nLint("locales", "en", true); err != nil {
+		t.Errorf("runI18nLint() with ignore unused error = %v", err)
+	}
+	if err := runI18nLint("locales", "en", false); err == nil {
+		t.Error("runI18nLint() error = nil with an unused key")
+	}
+	if err := runI18nLint("locales", "de", true); err == nil || !strings.Contains(err.Error(), "de.yaml not found") {
+		t.Errorf("runI18nLint() without the default locale error = %v", err)
+	}
+	if err := runI18nLint("missing", "en", true); err == nil || !strings.Contains(err.Error(), "no locale files") {
+		t.Errorf("runI18nLint() without locales error = %v", err)
+	}
+}
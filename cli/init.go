@@ -1,9 +1,12 @@
 package main
 
 import (
+	"bytes"
 	"fmt"
+	"go/format"
 	"os"
 	"path/filepath"
+	"strings"
 	"text/template"
 
 	"github.com/spf13/cobra"
@@ -52,6 +55,7 @@ func initProject(projectName, modulePath string) error {
 		filepath.Join(projectName, "locales"),
 		filepath.Join(projectName, "migrations"),
 		filepath.Join(projectName, "scripts"),
+		filepath.Join(projectName, "deploy", "k8s"),
 	}
 
 	for _, dir := range dirs {
@@ -62,15 +66,17 @@ func initProject(projectName, modulePath string) error {
 
 	// Generate files
 	files := map[string]string{
-		filepath.Join(projectName, "go.mod"):                goModTemplate,
-		filepath.Join(projectName, "cmd", "api", "main.go"): mainTemplate,
-		filepath.Join(projectName, "config", "config.yaml"): configTemplate,
-		filepath.Join(projectName, "locales", "en.yaml"):    enLocaleTemplate,
-		filepath.Join(projectName, "locales", "ru.yaml"):    ruLocaleTemplate,
-		filepath.Join(projectName, "README.md"):             readmeTemplate,
-		filepath.Join(projectName, "Makefile"):              makefileTemplate,
-		filepath.Join(projectName, ".gitignore"):            gitignoreTemplate,
-		filepath.Join(projectName, "Dockerfile"):            dockerfileTemplate,
+		filepath.Join(projectName, "go.mod"):                           goModTemplate,
+		filepath.Join(projectName, "cmd", "api", "main.go"):            mainTemplate,
+		filepath.Join(projectName, "cmd", "api", "main_test.go"):       mainTestTemplate,
+		filepath.Join(projectName, "config", "config.yaml"):            configTemplate,
+		filepath.Join(projectName, "locales", "en.yaml"):               enLocaleTemplate,
+		filepath.Join(projectName, "locales", "ru.yaml"):               ruLocaleTemplate,
+		filepath.Join(projectName, "README.md"):                        readmeTemplate,
+		filepath.Join(projectName, "Makefile"):                         makefileTemplate,
+		filepath.Join(projectName, ".gitignore"):                       gitignoreTemplate,
+		filepath.Join(projectName, "Dockerfile"):                       dockerfileTemplate,
+		filepath.Join(projectName, "deploy", "k8s", "deployment.yaml"): k8sDeploymentTemplate,
 	}
 
 	data := struct {
@@ -98,18 +104,28 @@ func initProject(projectName, modulePath string) error {
 }
 
 func generateFile(path, tmplStr string, data interface{}) error {
-	tmpl, err := template.New(filepath.Base(path)).Parse(tmplStr)
+	tmpl, err := template.New(filepath.Base(path)).Funcs(layout.funcs()).Parse(tmplStr)
 	if err != nil {
 		return fmt.Errorf("failed to parse template: %w", err)
 	}
 
-	file, err := os.Create(path)
-	if err != nil {
-		return fmt.Errorf("failed to create file: %w", err)
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return fmt.Errorf("failed to execute template: %w", err)
+	}
+
+	content := buf.Bytes()
+	if strings.HasSuffix(path, ".go") {
+		// Templates with conditional fields are not aligned, leave as is if not valid Go
+		if formatted, err := format.Source(content); err == nil {
+			content = formatted
+		}
 	}
-	defer file.Close()
 
-	return tmpl.Execute(file, data)
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	return nil
 }
 
 const goModTemplate = `module {{.ModulePath}}
@@ -124,63 +140,26 @@ require (
 const mainTemplate = `package main
 
 import (
-	"context"
 	"log"
 
-	"go.uber.org/fx"
+	"github.com/gofiber/fiber/v2"
 
-	"github.com/yourorg/microkit/pkg/config"
+	"github.com/yourorg/microkit/pkg/app"
 	"github.com/yourorg/microkit/pkg/logger"
 	"github.com/yourorg/microkit/pkg/server"
-	"github.com/yourorg/microkit/pkg/tracing"
-	"github.com/yourorg/microkit/pkg/i18n"
-	"github.com/yourorg/microkit/pkg/middleware"
 )
 
 func main() {
-	app := fx.New(
-		// Modules
-		fx.Provide(
-			provideConfig,
-			logger.New,
-			tracing.New,
-			i18n.New,
-			server.New,
-		),
-		
-		// Lifecycle
-		fx.Invoke(
-			setupServer,
-			registerRoutes,
-		),
+	// Config is read from config/config.yaml, APP_CONFIG overrides the path
+	application := app.New(
+		// TODO: Register repositories, use cases and handlers
+		app.WithProviders(),
+		app.WithInvokes(registerRoutes),
 	)
 
-	if err := app.Start(context.Background()); err != nil {
+	if err := application.Run(); err != nil {
 		log.Fatal(err)
 	}
-
-	<-app.Done()
-}
-
-func provideConfig() (*config.Config, error) {
-	return config.Load("config/config.yaml")
-}
-
-func setupServer(lc fx.Lifecycle, srv *server.Server, log *logger.Logger, tracer *tracing.Tracer, i18n *i18n.I18n) {
-	// Add middleware
-	srv.App().Use(middleware.TracingMiddleware(tracer))
-	srv.App().Use(middleware.LoggerMiddleware(log))
-	srv.App().Use(middleware.I18nMiddleware(i18n))
-	
-	// Start server
-	srv.Start(lc)
-	
-	// Shutdown tracer
-	lc.Append(fx.Hook{
-		OnStop: func(ctx context.Context) error {
-			return tracer.Shutdown(ctx)
-		},
-	})
 }
 
 func registerRoutes(srv *server.Server, log *logger.Logger) {
@@ -205,11 +184,32 @@ func registerRoutes(srv *server.Server, log *logger.Logger) {
 }
 `
 
+const mainTestTemplate = `package main
+
+import (
+	"net/http"
+	"testing"
+
+	"go.uber.org/fx"
+
+	"github.com/yourorg/microkit/pkg/apptest"
+)
+
+func TestHealth(t *testing.T) {
+	app := apptest.New(t, fx.Invoke(registerRoutes))
+
+	app.Get("/health").Do().
+		ExpectStatus(http.StatusOK).
+		ExpectJSON(` + "`" + `{"status": "ok"}` + "`" + `)
+}
+`
+
 const configTemplate = `server:
   host: 0.0.0.0
   port: 8080
   read_timeout: 30
   write_timeout: 30
+  body_limit: 4194304 # bytes
 
 logger:
   level: info
@@ -329,13 +329,19 @@ Environment variables override config file values:
 MIT
 `
 
-const makefileTemplate = `.PHONY: run build test clean dev
+const makefileTemplate = `.PHONY: run build test clean dev docker
+
+VERSION ?= $(shell git describe --tags --always --dirty 2>/dev/null || echo dev)
+COMMIT ?= $(shell git rev-parse HEAD 2>/dev/null)
+BUILD_DATE ?= $(shell date -u +%Y-%m-%dT%H:%M:%SZ)
+BUILDINFO = github.com/yourorg/microkit/pkg/buildinfo
+LDFLAGS = -X $(BUILDINFO).Version=$(VERSION) -X $(BUILDINFO).Commit=$(COMMIT) -X $(BUILDINFO).BuildDate=$(BUILD_DATE)
 
 run:
 	go run cmd/api/main.go
 
 build:
-	go build -o bin/api cmd/api/main.go
+	go build -ldflags "$(LDFLAGS)" -o bin/api cmd/api/main.go
 
 test:
 	go test -v ./...
@@ -345,6 +351,13 @@ clean:
 
 dev:
 	air -c .air.toml
+
+docker:
+	docker build \
+		--build-arg VERSION=$(VERSION) \
+		--build-arg COMMIT=$(COMMIT) \
+		--build-arg BUILD_DATE=$(BUILD_DATE) \
+		-t {{.ProjectName}}:$(VERSION) .
 `
 
 const gitignoreTemplate = `# Binaries
@@ -396,7 +409,14 @@ COPY go.mod go.sum ./
 RUN go mod download
 
 COPY . .
-RUN CGO_ENABLED=0 GOOS=linux go build -o /app/api cmd/api/main.go
+
+# Build metadata served on /version, set by make docker
+ARG VERSION=dev
+ARG COMMIT=""
+ARG BUILD_DATE=""
+RUN CGO_ENABLED=0 GOOS=linux go build \
+	-ldflags "-X github.com/yourorg/microkit/pkg/buildinfo.Version=${VERSION} -X github.com/yourorg/microkit/pkg/buildinfo.Commit=${COMMIT} -X github.com/yourorg/microkit/pkg/buildinfo.BuildDate=${BUILD_DATE}" \
+	-o /app/api cmd/api/main.go
 
 FROM alpine:latest
 
@@ -412,3 +432,59 @@ EXPOSE 8080
 
 CMD ["./api"]
 `
+
+const k8sDeploymentTemplate = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: {{.ProjectName}}
+  labels:
+    app: {{.ProjectName}}
+spec:
+  replicas: 2
+  selector:
+    matchLabels:
+      app: {{.ProjectName}}
+  template:
+    metadata:
+      labels:
+        app: {{.ProjectName}}
+    spec:
+      containers:
+        - name: {{.ProjectName}}
+          image: {{.ProjectName}}:latest
+          ports:
+            - name: http
+              containerPort: 8080
+          startupProbe:
+            httpGet:
+              path: /livez
+              port: http
+            periodSeconds: 2
+            failureThreshold: 30
+          livenessProbe:
+            httpGet:
+              path: /livez
+              port: http
+            periodSeconds: 10
+            timeoutSeconds: 2
+            failureThreshold: 3
+          readinessProbe:
+            httpGet:
+              path: /readyz
+              port: http
+            periodSeconds: 5
+            timeoutSeconds: 3
+            failureThreshold: 2
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: {{.ProjectName}}
+spec:
+  selector:
+    app: {{.ProjectName}}
+  ports:
+    - name: http
+      port: 80
+      targetPort: http
+`
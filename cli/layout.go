@@ -0,0 +1,146 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// projectLayout maps generated components to project directories
+type projectLayout struct {
+	Entity         string
+	Repository     string
+	RepositoryImpl string
+	Usecase        string
+	HTTP           string
+	Infrastructure string
+}
+
+// layout is resolved from --layout before generate subcommands run
+var layout = newProjectLayout("internal")
+
+// componentDirs lists known directory conventions per component, the first one is the default
+var componentDirs = []struct {
+	dir  func(*projectLayout) *string
+	dirs []string
+}{
+	{func(l *projectLayout) *string { return &l.Entity }, []string{"domain/entity", "domain/model", "entity", "model"}},
+	{func(l *projectLayout) *string { return &l.Repository }, []string{"domain/repository", "repository"}},
+	{func(l *projectLayout) *string { return &l.RepositoryImpl }, []string{"infrastructure/repository", "infrastructure/persistence", "storage"}},
+	{func(l *projectLayout) *string { return &l.Usecase }, []string{"usecase", "service"}},
+	{func(l *projectLayout) *string { return &l.HTTP }, []string{"delivery/http", "transport/http", "handler"}},
+	{func(l *projectLayout) *string { return &l.Infrastructure }, []string{"infrastructure", "adapter"}},
+}
+
+// layoutRoots are roots checked by auto-detection in order
+var layoutRoots = []string{"internal", "app", "pkg"}
+
+// newProjectLayout returns the microkit directory structure under root
+func newProjectLayout(root string) projectLayout {
+	var l projectLayout
+	for _, c := range componentDirs {
+		*c.dir(&l) = filepath.Join(root, c.dirs[0])
+	}
+	return l
+}
+
+// resolveLayout returns the layout for --layout value: "auto" detects an existing
+// structure, any other value is used as the root of the microkit structure
+func resolveLayout(value string) (projectLayout, error) {
+	if value != "" && value != "auto" {
+		if filepath.IsAbs(value) || strings.HasPrefix(filepath.Clean(value), "..") {
+			return projectLayout{}, fmt.Errorf("layout must be a directory inside the project: %s", value)
+		}
+		return newProjectLayout(filepath.Clean(value)), nil
+	}
+	return detectLayout(), nil
+}
+
+// detectLayout picks the first existing root with known component directories and
+// uses existing directories for every component, missing ones follow the microkit default
+func detectLayout() projectLayout {
+	root := layoutRoots[0]
+	for _, candidate := range layoutRoots {
+		if countComponentDirs(candidate) > 0 {
+			root = candidate
+			break
+		}
+	}
+
+	l := newProjectLayout(root)
+	for _, c := range componentDirs {
+		for _, dir := range c.dirs {
+			if isDir(filepath.Join(root, dir)) {
+				*c.dir(&l) = filepath.Join(root, dir)
+				break
+			}
+		}
+	}
+	return l
+}
+
+func countComponentDirs(root string) int {
+	count := 0
+	for _, c := range componentDirs {
+		for _, dir := range c.dirs {
+			if isDir(filepath.Join(root, dir)) {
+				count++
+				break
+			}
+		}
+	}
+	return count
+}
+
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+// dir returns the directory of a component by its template name
+func (l projectLayout) dir(component string) (string, error) {
+	switch component {
+	case "entity":
+		return l.Entity, nil
+	case "repository":
+		return l.Repository, nil
+	case "repository_impl":
+		return l.RepositoryImpl, nil
+	case "usecase":
+		return l.Usecase, nil
+	case "http":
+		return l.HTTP, nil
+	case "infrastructure":
+		return l.Infrastructure, nil
+	}
+	return "", fmt.Errorf("unknown layout component %q", component)
+}
+
+// funcs returns template functions resolving component packages:
+// {{package "entity"}} is the package name, {{import "entity"}} is an import spec
+// aliased to the component name when the directory is named differently
+func (l projectLayout) funcs() template.FuncMap {
+	return template.FuncMap{
+		"package": func(component string) (string, error) {
+			dir, err := l.dir(component)
+			if err != nil {
+				return "", err
+			}
+			return filepath.Base(dir), nil
+		},
+		"import": func(component string) (string, error) {
+			dir, err := l.dir(component)
+			if err != nil {
+				return "", err
+			}
+			spec := strconv.Quote(modulePath() + "/" + filepath.ToSlash(dir))
+			if filepath.Base(dir) != component {
+				spec = component + " " + spec
+			}
+			return spec, nil
+		},
+	}
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// mkdirs creates directories in a temp project and changes into it
+func mkdirs(t *testing.T, dirs ...string) {
+	t.Helper()
+	root := t.TempDir()
+	for _, dir := range dirs {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Chdir(root)
+}
+
+// useLayout sets the global layout for a test and restores it after
+func useLayout(t *testing.T, value string) {
+	t.Helper()
+	previous := layout
+	t.Cleanup(func() { layout = previous })
+
+	resolved, err := resolveLayout(value)
+	if err != nil {
+		t.Fatalf("resolveLayout(%q): %v", value, err)
+	}
+	layout = resolved
+}
+
+func TestDetectLayout(t *testing.T) {
+	tests := []struct {
+		name string
+		dirs []string
+		want projectLayout
+	}{
+		{
+			name: "empty project uses the microkit default",
+			want: newProjectLayout("internal"),
+		},
+		{
+			name: "app root",
+			dirs: []string{"app/handler", "app/service", "app/model"},
+			want: projectLayout{
+				Entity:         "app/model",
+				Repository:     "app/domain/repository",
+				RepositoryImpl: "app/infrastructure/repository",
+				Usecase:        "app/service",
+				HTTP:           "app/handler",
+				Infrastructure: "app/infrastructure",
+			},
+		},
+		{
+			name: "pkg root",
+			dirs: []string{"pkg/transport/http", "pkg/storage", "pkg/adapter"},
+			want: projectLayout{
+				Entity:         "pkg/domain/entity",
+				Repository:     "pkg/domain/repository",
+				RepositoryImpl: "pkg/storage",
+				Usecase:        "pkg/usecase",
+				HTTP:           "pkg/transport/http",
+				Infrastructure: "pkg/adapter",
+			},
+		},
+		{
+			name: "internal wins over other roots",
+			dirs: []string{"internal/usecase", "app/handler"},
+			want: newProjectLayout("internal"),
+		},
+		{
+			name: "root without component directories is ignored",
+			dirs: []string{"app/static", "pkg/service"},
+			want: projectLayout{
+				Entity:         "pkg/domain/entity",
+				Repository:     "pkg/domain/repository",
+				RepositoryImpl: "pkg/infrastructure/repository",
+				Usecase:        "pkg/service",
+				HTTP:           "pkg/delivery/http",
+				Infrastructure: "pkg/infrastructure",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mkdirs(t, tt.dirs...)
+			got, err := resolveLayout("auto")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("layout = %+v\nwant %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveLayoutRoot(t *testing.T) {
+	got, err := resolveLayout("src/./core")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != newProjectLayout("src/core") {
+		t.Errorf("layout = %+v, want the microkit structure under src/core", got)
+	}
+
+	for _, value := range []string{"../shared", "/srv/app"} {
+		if _, err := resolveLayout(value); err == nil {
+			t.Errorf("resolveLayout(%q) = nil error, want a directory outside the project rejected", value)
+		}
+	}
+}
+
+func TestLayoutImportAlias(t *testing.T) {
+	mkdirs(t, "app/model")
+	if err := os.WriteFile("go.mod", []byte("module example.com/shop\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	l, err := resolveLayout("auto")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	importSpec := l.funcs()["import"].(func(string) (string, error))
+	if spec, _ := importSpec("entity"); spec != `entity "example.com/shop/app/model"` {
+		t.Errorf("import entity = %s, want an alias for the model directory", spec)
+	}
+	if spec, _ := importSpec("usecase"); spec != `"example.com/shop/app/usecase"` {
+		t.Errorf("import usecase = %s", spec)
+	}
+}
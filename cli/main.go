@@ -19,6 +19,10 @@ func main() {
 	rootCmd.AddCommand(
 		newGenerateCmd(),
 		newInitCmd(),
+		newOutboxCmd(),
+		newSelfUpdateCmd(),
+		newDoctorCmd(),
+		newI18nCmd(),
 	)
 
 	if err := rootCmd.Execute(); err != nil {
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/alimzhanovlr/sdk/outbox"
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/spf13/cobra"
+)
+
+func newOutboxCmd() *cobra.Command {
+	var (
+		dsn   string
+		table string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "outbox",
+		Short: "Inspect and manage transactional outbox",
+	}
+
+	cmd.PersistentFlags().StringVar(&dsn, "dsn", os.Getenv("DATABASE_URL"), "PostgreSQL DSN (defaults to $DATABASE_URL)")
+	cmd.PersistentFlags().StringVar(&table, "table", outbox.DefaultTable, "Outbox table name")
+
+	cmd.AddCommand(
+		newOutboxStuckCmd(&dsn, &table),
+		newOutboxRetryCmd(&dsn, &table),
+	)
+
+	return cmd
+}
+
+func newOutboxStuckCmd(dsn, table *string) *cobra.Command {
+	var (
+		olderThan time.Duration
+		limit     int
+	)
+
+	cmd := &cobra.Command{
+		Use:   "stuck",
+		Short: "List unpublished outbox events",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			repo, closeDB, err := openOutbox(*dsn, *table)
+			if err != nil {
+				return err
+			}
+			defer closeDB()
+
+			events, err := repo.ListStuck(cmd.Context(), olderThan, limit)
+			if err != nil {
+				return err
+			}
+
+			if len(events) == 0 {
+				fmt.Println("✅ No stuck outbox events")
+				return nil
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "ID\tTOPIC\tCREATED\tATTEMPTS\tLAST ERROR")
+			for _, e := range events {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\n",
+					e.ID, e.Topic, e.CreatedAt.Format(time.RFC3339), e.Attempts, e.LastError)
+			}
+			return w.Flush()
+		},
+	}
+
+	cmd.Flags().DurationVar(&olderThan, "older-than", 5*time.Minute, "Only show events older than this")
+	cmd.Flags().IntVar(&limit, "limit", 50, "Maximum number of events")
+
+	return cmd
+}
+
+func newOutboxRetryCmd(dsn, table *string) *cobra.Command {
+	return &cobra.Command{
+		Use:   "retry [event-id...]",
+		Short: "Reset attempts so the relay publishes events again",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			repo, closeDB, err := openOutbox(*dsn, *table)
+			if err != nil {
+				return err
+			}
+			defer closeDB()
+
+			for _, id := range args {
+				if err := repo.Reset(cmd.Context(), id); err != nil {
+					return err
+				}
+				fmt.Printf("✅ Event %s scheduled for retry\n", id)
+			}
+			return nil
+		},
+	}
+}
+
+func openOutbox(dsn, table string) (*outbox.Repository, func(), error) {
+	if dsn == "" {
+		return nil, nil, fmt.Errorf("database DSN is required (--dsn or $DATABASE_URL)")
+	}
+
+	db, err := sql.Open("pgx", dsn)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+
+	return outbox.NewRepository(db, table), func() { db.Close() }, nil
+}
@@ -0,0 +1,410 @@
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// Set at release build time:
+//
+//	-ldflags "-X main.updateRepo=owner/repo -X main.updatePublicKey=<base64 ed25519 key>"
+var (
+	updateRepo      = "alimzhanovlr/sdk"
+	updatePublicKey = ""
+)
+
+const (
+	checksumsAsset = "checksums.txt"
+	signatureAsset = "checksums.txt.sig"
+
+	// maxDownloadSize bounds release assets and API responses
+	maxDownloadSize = 256 << 20
+)
+
+// githubRelease is a subset of GitHub releases API response
+type githubRelease struct {
+	TagName string `json:"tag_name"`
+	Assets  []struct {
+		Name string `json:"name"`
+		URL  string `json:"browser_download_url"`
+	} `json:"assets"`
+}
+
+func (r *githubRelease) assetURL(name string) string {
+	for _, asset := range r.Assets {
+		if asset.Name == name {
+			return asset.URL
+		}
+	}
+	return ""
+}
+
+func newSelfUpdateCmd() *cobra.Command {
+	var (
+		repo     string
+		check    bool
+		force    bool
+		insecure bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "self-update",
+		Short: "Update microkit to the latest GitHub release",
+		Long: `Downloads the latest release binary for this platform, verifies the ed25519
+signature of checksums.txt and the SHA-256 of the binary against it, then
+replaces the running binary. Builds without a release public key refuse to
+update unless --insecure is given.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, cancel := context.WithTimeout(cmd.Context(), 5*time.Minute)
+			defer cancel()
+			return selfUpdate(ctx, repo, check, force, insecure)
+		},
+	}
+
+	cmd.Flags().StringVar(&repo, "repo", updateRepo, "GitHub repository with releases")
+	cmd.Flags().BoolVar(&check, "check", false, "Only check for a newer version")
+	cmd.Flags().BoolVar(&force, "force", false, "Reinstall even if already up to date")
+	cmd.Flags().BoolVar(&insecure, "insecure", false, "Update without a release signature when built without a public key")
+
+	return cmd
+}
+
+func selfUpdate(ctx context.Context, repo string, check, force, insecure bool) error {
+	release, err := latestRelease(ctx, repo)
+	if err != nil {
+		return err
+	}
+
+	latest := strings.TrimPrefix(release.TagName, "v")
+	if !force && !newerVersion(latest, version) {
+		fmt.Printf("✅ microkit %s is up to date\n", version)
+		return nil
+	}
+	if check {
+		fmt.Printf("⬆️  microkit %s is available (current %s), run `microkit self-update`\n", latest, version)
+		return nil
+	}
+
+	name, url := release.platformAsset()
+	if url == "" {
+		return fmt.Errorf("release %s has no binary for %s/%s", release.TagName, runtime.GOOS, runtime.GOARCH)
+	}
+
+	checksums, err := download(ctx, release.assetURL(checksumsAsset))
+	if err != nil {
+		return fmt.Errorf("failed to download %s: %w", checksumsAsset, err)
+	}
+	if err := verifySignature(ctx, release, checksums, insecure); err != nil {
+		return err
+	}
+
+	expected, err := findChecksum(checksums, name)
+	if err != nil {
+		return err
+	}
+
+	asset, err := download(ctx, url)
+	if err != nil {
+		return fmt.Errorf("failed to download %s: %w", name, err)
+	}
+	sum := sha256.Sum256(asset)
+	if hex.EncodeToString(sum[:]) != expected {
+		return fmt.Errorf("checksum mismatch for %s", name)
+	}
+
+	binary, err := extractBinary(name, asset)
+	if err != nil {
+		return err
+	}
+
+	path, err := replaceExecutable(binary)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("✅ Updated microkit %s -> %s (%s)\n", version, latest, path)
+	return nil
+}
+
+func latestRelease(ctx context.Context, repo string) (*githubRelease, error) {
+	url := "https://api.github.com/repos/" + repo + "/releases/latest"
+	body, err := download(ctx, url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check releases: %w", err)
+	}
+
+	var release githubRelease
+	if err := json.Unmarshal(body, &release); err != nil {
+		return nil, fmt.Errorf("failed to decode release: %w", err)
+	}
+	return &release, nil
+}
+
+// platformAsset finds microkit_<os>_<arch>[.exe|.tar.gz|.zip], versioned
+// names like microkit_1.2.0_linux_amd64.tar.gz are accepted too
+func (r *githubRelease) platformAsset() (string, string) {
+	platform := "_" + runtime.GOOS + "_" + runtime.GOARCH
+	for _, asset := range r.Assets {
+		name := asset.Name
+		for _, ext := range []string{".tar.gz", ".zip", ".exe"} {
+			name = strings.TrimSuffix(name, ext)
+		}
+		if strings.HasPrefix(asset.Name, "microkit_") && strings.HasSuffix(name, platform) {
+			return asset.Name, asset.URL
+		}
+	}
+	return "", ""
+}
+
+func download(ctx context.Context, url string) ([]byte, error) {
+	if url == "" {
+		return nil, fmt.Errorf("asset not found in release")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if token := os.Getenv("GITHUB_TOKEN"); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+	return readLimited(resp.Body)
+}
+
+// readLimited reads at most maxDownloadSize bytes and fails on larger content
+func readLimited(r io.Reader) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, maxDownloadSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxDownloadSize {
+		return nil, fmt.Errorf("content exceeds %d MB", maxDownloadSize>>20)
+	}
+	return data, nil
+}
+
+// verifySignature checks checksums.txt.sig (base64 ed25519 signature). A
+// binary built without a public key refuses to update unless insecure is set
+func verifySignature(ctx context.Context, release *githubRelease, checksums []byte, insecure bool) error {
+	if updatePublicKey == "" {
+		if !insecure {
+			return fmt.Errorf("binary is built without a release public key, the update cannot be verified (use --insecure to update anyway)")
+		}
+		fmt.Println("⚠️  Release signature is not verified: binary is built without a public key")
+		return nil
+	}
+
+	key, err := base64.StdEncoding.DecodeString(updatePublicKey)
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return fmt.Errorf("invalid release public key")
+	}
+
+	encoded, err := download(ctx, release.assetURL(signatureAsset))
+	if err != nil {
+		return fmt.Errorf("failed to download %s: %w", signatureAsset, err)
+	}
+	signature, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(encoded)))
+	if err != nil {
+		return fmt.Errorf("failed to decode signature: %w", err)
+	}
+
+	if !ed25519.Verify(key, checksums, signature) {
+		return fmt.Errorf("invalid signature of %s", checksumsAsset)
+	}
+	return nil
+}
+
+// findChecksum reads "<sha256>  <file>" lines of sha256sum output
+func findChecksum(checksums []byte, name string) (string, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(checksums))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && strings.TrimPrefix(fields[1], "*") == name {
+			return strings.ToLower(fields[0]), nil
+		}
+	}
+	return "", fmt.Errorf("checksum for %s not found in %s", name, checksumsAsset)
+}
+
+// extractBinary returns the microkit binary from a .tar.gz or .zip asset, other
+// assets are the binary itself. Entries with absolute paths or ".." are rejected
+func extractBinary(name string, asset []byte) ([]byte, error) {
+	switch {
+	case strings.HasSuffix(name, ".tar.gz"):
+		return extractTarGz(asset)
+	case strings.HasSuffix(name, ".zip"):
+		return extractZip(asset)
+	default:
+		return asset, nil
+	}
+}
+
+func extractTarGz(archive []byte) ([]byte, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(archive))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read archive: %w", err)
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil, fmt.Errorf("microkit binary not found in archive")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read archive: %w", err)
+		}
+
+		if header.Typeflag == tar.TypeReg && isBinaryEntry(header.Name) {
+			if !safeArchivePath(header.Name) {
+				return nil, fmt.Errorf("unsafe path %q in archive", header.Name)
+			}
+			return readLimited(tr)
+		}
+	}
+}
+
+func extractZip(archive []byte) ([]byte, error) {
+	zr, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read archive: %w", err)
+	}
+
+	for _, file := range zr.File {
+		if !file.Mode().IsRegular() || !isBinaryEntry(file.Name) {
+			continue
+		}
+		if !safeArchivePath(file.Name) {
+			return nil, fmt.Errorf("unsafe path %q in archive", file.Name)
+		}
+
+		rc, err := file.Open()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read archive: %w", err)
+		}
+		defer rc.Close()
+		return readLimited(rc)
+	}
+	return nil, fmt.Errorf("microkit binary not found in archive")
+}
+
+func isBinaryEntry(name string) bool {
+	base := path.Base(strings.ReplaceAll(name, "\\", "/"))
+	return base == "microkit" || base == "microkit.exe"
+}
+
+// safeArchivePath rejects absolute entry names and names leaving the archive root
+func safeArchivePath(name string) bool {
+	name = strings.ReplaceAll(name, "\\", "/")
+	if strings.HasPrefix(name, "/") || filepath.VolumeName(name) != "" {
+		return false
+	}
+	for _, part := range strings.Split(name, "/") {
+		if part == ".." {
+			return false
+		}
+	}
+	return true
+}
+
+// replaceExecutable writes the binary next to the running one and renames it over
+func replaceExecutable(binary []byte) (string, error) {
+	path, err := os.Executable()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate executable: %w", err)
+	}
+	if path, err = filepath.EvalSymlinks(path); err != nil {
+		return "", fmt.Errorf("failed to locate executable: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".microkit-update-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to write update (try sudo): %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(binary); err != nil {
+		tmp.Close()
+		return "", fmt.Errorf("failed to write update: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return "", fmt.Errorf("failed to write update: %w", err)
+	}
+	if err := os.Chmod(tmp.Name(), 0755); err != nil {
+		return "", fmt.Errorf("failed to write update: %w", err)
+	}
+
+	// Windows does not allow replacing a running executable, but allows renaming it
+	if runtime.GOOS == "windows" {
+		old := path + ".old"
+		os.Remove(old)
+		if err := os.Rename(path, old); err != nil {
+			return "", fmt.Errorf("failed to replace executable: %w", err)
+		}
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return "", fmt.Errorf("failed to replace executable: %w", err)
+	}
+	return path, nil
+}
+
+// newerVersion compares dotted numeric versions, pre-release suffixes are ignored
+func newerVersion(latest, current string) bool {
+	parse := func(v string) []int {
+		v = strings.SplitN(v, "-", 2)[0]
+		var parts []int
+		for _, p := range strings.Split(v, ".") {
+			n, _ := strconv.Atoi(p)
+			parts = append(parts, n)
+		}
+		return parts
+	}
+
+	l, c := parse(latest), parse(current)
+	for i := 0; i < len(l) || i < len(c); i++ {
+		var a, b int
+		if i < len(l) {
+			a = l[i]
+		}
+		if i < len(c) {
+			b = c[i]
+		}
+		if a != b {
+			return a > b
+		}
+	}
+	return false
+}
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNewerVersion(t *testing.T) {
+	tests := []struct {
+		latest, current string
+		want            bool
+	}{
+		{"1.2.0", "1.1.9", true},
+		{"1.10.0", "1.9.0", true},
+		{"2.0", "1.99.99", true},
+		{"1.2.1", "1.2", true},
+		{"1.2.0", "1.2.0", false},
+		{"1.2", "1.2.0", false},
+		{"1.1.0", "1.2.0", false},
+		{"1.3.0-rc1", "1.2.0", true},
+		{"1.2.0-rc1", "1.2.0", false},
+	}
+
+	for _, tt := range tests {
+		if got := newerVersion(tt.latest, tt.current); got != tt.want {
+			t.Errorf("newerVersion(%s, %s) = %v, want %v", tt.latest, tt.current, got, tt.want)
+		}
+	}
+}
+
+func TestFindChecksum(t *testing.T) {
+	checksums := []byte("ABCDEF  microkit_linux_amd64.tar.gz\n" +
+		"012345 *microkit_windows_amd64.zip\n" +
+		"broken line\n")
+
+	tests := map[string]string{
+		"microkit_linux_amd64.tar.gz": "abcdef",
+		"microkit_windows_amd64.zip":  "012345",
+	}
+	for name, want := range tests {
+		got, err := findChecksum(checksums, name)
+		if err != nil || got != want {
+			t.Errorf("findChecksum(%s) = %q, %v, want %q", name, got, err, want)
+		}
+	}
+
+	if _, err := findChecksum(checksums, "microkit_darwin_arm64.tar.gz"); err == nil {
+		t.Error("expected error for missing asset")
+	}
+}
+
+func tarGz(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		tw.WriteHeader(&tar.Header{Name: name, Mode: 0755, Size: int64(len(content)), Typeflag: tar.TypeReg})
+		tw.Write([]byte(content))
+	}
+	tw.Close()
+	gz.Close()
+	return buf.Bytes()
+}
+
+func zipArchive(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(content))
+	}
+	zw.Close()
+	return buf.Bytes()
+}
+
+func TestExtractBinary(t *testing.T) {
+	tests := []struct {
+		name    string
+		asset   []byte
+		want    string
+		wantErr string
+	}{
+		{
+			name:  "microkit_linux_amd64.tar.gz",
+			asset: tarGz(t, map[string]string{"README.md": "docs", "microkit_1.2.0/microkit": "tar-binary"}),
+			want:  "tar-binary",
+		},
+		{
+			name:  "microkit_windows_amd64.zip",
+			asset: zipArchive(t, map[string]string{"LICENSE": "mit", "microkit.exe": "zip-binary"}),
+			want:  "zip-binary",
+		},
+		{
+			name:  "microkit_linux_amd64",
+			asset: []byte("raw-binary"),
+			want:  "raw-binary",
+		},
+		{
+			name:    "microkit_linux_amd64.tar.gz",
+			asset:   tarGz(t, map[string]string{"../../usr/local/bin/microkit": "evil"}),
+			wantErr: "unsafe path",
+		},
+		{
+			name:    "microkit_linux_amd64.tar.gz",
+			asset:   tarGz(t, map[string]string{"/usr/local/bin/microkit": "evil"}),
+			wantErr: "unsafe path",
+		},
+		{
+			name:    "microkit_windows_amd64.zip",
+			asset:   zipArchive(t, map[string]string{`..\..\microkit.exe`: "evil"}),
+			wantErr: "unsafe path",
+		},
+		{
+			name:    "microkit_linux_amd64.tar.gz",
+			asset:   tarGz(t, map[string]string{"README.md": "docs"}),
+			wantErr: "not found",
+		},
+		{
+			name:    "microkit_windows_amd64.zip",
+			asset:   []byte("not a zip"),
+			wantErr: "failed to read archive",
+		},
+	}
+
+	for _, tt := range tests {
+		got, err := extractBinary(tt.name, tt.asset)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("%s: error = %v, want %q", tt.name, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || string(got) != tt.want {
+			t.Errorf("%s: got %q, %v, want %q", tt.name, got, err, tt.want)
+		}
+	}
+}
+
+func TestReadLimited(t *testing.T) {
+	if _, err := readLimited(strings.NewReader(strings.Repeat("x", maxDownloadSize+1))); err == nil {
+		t.Error("expected error for oversized content")
+	}
+	if data, err := readLimited(strings.NewReader("ok")); err != nil || string(data) != "ok" {
+		t.Errorf("readLimited() = %q, %v", data, err)
+	}
+}
+
+func TestVerifySignature(t *testing.T) {
+	public, private, _ := ed25519.GenerateKey(nil)
+	checksums := []byte("abcdef  microkit_linux_amd64\n")
+	signature := base64.StdEncoding.EncodeToString(ed25519.Sign(private, checksums))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(signature))
+	}))
+	defer server.Close()
+
+	release := &githubRelease{}
+	release.Assets = append(release.Assets, struct {
+		Name string `json:"name"`
+		URL  string `json:"browser_download_url"`
+	}{Name: signatureAsset, URL: server.URL})
+
+	defer func(key string) { updatePublicKey = key }(updatePublicKey)
+	ctx := context.Background()
+
+	updatePublicKey = ""
+	if err := verifySignature(ctx, release, checksums, false); err == nil {
+		t.Error("update without a public key must fail unless insecure")
+	}
+	if err := verifySignature(ctx, release, checksums, true); err != nil {
+		t.Errorf("insecure update: %v", err)
+	}
+
+	updatePublicKey = base64.StdEncoding.EncodeToString(public)
+	if err := verifySignature(ctx, release, checksums, false); err != nil {
+		t.Errorf("valid signature: %v", err)
+	}
+	if err := verifySignature(ctx, release, []byte("tampered"), true); err == nil {
+		t.Error("tampered checksums must fail even with insecure")
+	}
+}
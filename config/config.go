@@ -3,16 +3,25 @@ package config
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 )
 
 // Config represents application configuration
 type Config struct {
-	Server  ServerConfig  `mapstructure:"server"`
-	Logger  LoggerConfig  `mapstructure:"logger"`
-	Tracing TracingConfig `mapstructure:"tracing"`
-	I18n    I18nConfig    `mapstructure:"i18n"`
+	Server    ServerConfig    `mapstructure:"server"`
+	Logger    LoggerConfig    `mapstructure:"logger"`
+	Tracing   TracingConfig   `mapstructure:"tracing"`
+	I18n      I18nConfig      `mapstructure:"i18n"`
+	Scheduler SchedulerConfig `mapstructure:"scheduler"`
+	Metrics   MetricsConfig   `mapstructure:"metrics"`
+	Storage   StorageConfig   `mapstructure:"storage"`
+	Database  DatabaseConfig  `mapstructure:"database"`
+	Redis     RedisConfig     `mapstructure:"redis"`
+	Kafka     KafkaConfig     `mapstructure:"kafka"`
+	Search    SearchConfig    `mapstructure:"search"`
+	Errors    ErrorsConfig    `mapstructure:"errors"`
 }
 
 // ServerConfig holds server configuration
@@ -21,13 +30,33 @@ type ServerConfig struct {
 	Port         int    `mapstructure:"port"`
 	ReadTimeout  int    `mapstructure:"read_timeout"`
 	WriteTimeout int    `mapstructure:"write_timeout"`
+	BodyLimit    int    `mapstructure:"body_limit"` // Max request body in bytes
+	// ReusePort sets SO_REUSEPORT, so a new process binds the port while the
+	// old one drains connections, for restarts without a load balancer
+	ReusePort bool `mapstructure:"reuse_port"`
+	// SocketActivation serves the socket passed by systemd (LISTEN_FDS) and
+	// falls back to Host and Port when started without it
+	SocketActivation bool `mapstructure:"socket_activation"`
+	// SocketName selects the socket by FileDescriptorName of the .socket
+	// unit, defaults to the first one
+	SocketName string `mapstructure:"socket_name"`
+	// AdminAddr is a separate listener for /debug/* endpoints, e.g.
+	// 127.0.0.1:9091. Empty disables them, they are never served on the
+	// public port
+	AdminAddr string `mapstructure:"admin_addr"`
 }
 
 // LoggerConfig holds logger configuration
 type LoggerConfig struct {
-	Level      string `mapstructure:"level"`
-	Format     string `mapstructure:"format"` // json or console
-	OutputPath string `mapstructure:"output_path"`
+	Level  string `mapstructure:"level" enum:"debug,info,warn,error"`
+	Format string `mapstructure:"format" enum:"json,console"`
+	// OutputPath lists outputs: stdout, stderr, file paths and network sinks
+	// kafka://broker:9092/topic, fluentd://host:24224, syslog://host:514
+	OutputPath []string `mapstructure:"output_path"`
+	// SpanEventLevel is the minimum level added to the active span as events
+	SpanEventLevel string `mapstructure:"span_event_level" enum:"debug,info,warn,error"`
+	// Levels override named loggers: ["repository.orders=debug"]
+	Levels []string `mapstructure:"levels"`
 }
 
 // TracingConfig holds tracing configuration
@@ -36,6 +65,40 @@ type TracingConfig struct {
 	ServiceName string  `mapstructure:"service_name"`
 	Endpoint    string  `mapstructure:"endpoint"`
 	SampleRate  float64 `mapstructure:"sample_rate"`
+	// ServiceVersion defaults to the buildinfo version, ldflags or build info
+	ServiceVersion string `mapstructure:"service_version"`
+	// Environment is deployment.environment of traces and metrics
+	Environment string `mapstructure:"environment"`
+	// ResourceAttributes are extra resource attributes as "key=value", e.g.
+	// "team=payments" or "cloud.region=kz-ala". A list, because viper splits
+	// dotted map keys into nested sections. Kubernetes pod, namespace and node
+	// come from K8S_POD_NAME, K8S_NAMESPACE_NAME and K8S_NODE_NAME
+	ResourceAttributes []string `mapstructure:"resource_attributes"`
+	// RedactAttributes masks sensitive span attributes before export
+	RedactAttributes bool               `mapstructure:"redact_attributes"`
+	TailSampling     TailSamplingConfig `mapstructure:"tail_sampling"`
+}
+
+// ResourceAttributeMap parses ResourceAttributes
+func (c TracingConfig) ResourceAttributeMap() (map[string]string, error) {
+	attrs := make(map[string]string, len(c.ResourceAttributes))
+	for _, attr := range c.ResourceAttributes {
+		key, value, ok := strings.Cut(attr, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("tracing.resource_attributes: %q is not key=value", attr)
+		}
+		attrs[key] = strings.TrimSpace(value)
+	}
+	return attrs, nil
+}
+
+// TailSamplingConfig keeps error and slow traces, sample_rate applies to the rest
+type TailSamplingConfig struct {
+	Enabled          bool          `mapstructure:"enabled"`
+	LatencyThreshold time.Duration `mapstructure:"latency_threshold"`
+	DecisionWait     time.Duration `mapstructure:"decision_wait"`
+	MaxTraces        int           `mapstructure:"max_traces"`
 }
 
 // I18nConfig holds i18n configuration
@@ -45,12 +108,49 @@ type I18nConfig struct {
 	Path            string   `mapstructure:"path"`
 }
 
-// Load loads configuration from file and environment variables
+// ErrorsConfig holds error reporting configuration
+type ErrorsConfig struct {
+	// Stats counts AppError codes in process, served on /debug/errors
+	Stats bool `mapstructure:"stats"`
+}
+
+// SchedulerConfig holds scheduler configuration
+type SchedulerConfig struct {
+	Enabled  bool   `mapstructure:"enabled"`
+	Timezone string `mapstructure:"timezone"`
+}
+
+// MetricsConfig holds metrics configuration
+type MetricsConfig struct {
+	Enabled   bool   `mapstructure:"enabled"`
+	Exporter  string `mapstructure:"exporter" enum:"prometheus,otlp"`
+	Path      string `mapstructure:"path"`
+	Namespace string `mapstructure:"namespace"`
+	Endpoint  string `mapstructure:"endpoint"`
+	Interval  int    `mapstructure:"interval"`
+}
+
+// StorageConfig holds object storage configuration
+type StorageConfig struct {
+	Driver    string `mapstructure:"driver" enum:"s3,local"`
+	Endpoint  string `mapstructure:"endpoint"`
+	Region    string `mapstructure:"region"`
+	Bucket    string `mapstructure:"bucket"`
+	AccessKey string `mapstructure:"access_key"`
+	SecretKey string `mapstructure:"secret_key"`
+	UseSSL    bool   `mapstructure:"use_ssl"`
+	LocalPath string `mapstructure:"local_path"`
+	PublicURL string `mapstructure:"public_url"`
+}
+
+// Load loads configuration from file and environment variables and publishes
+// it as the next Snapshot revision, call it again to reload
 func Load(configPath string) (*Config, error) {
 	v := viper.New()
 
 	// Set defaults
 	setDefaults(v)
+	setSectionDefaults(v)
 
 	// Read config file
 	if configPath != "" {
@@ -69,6 +169,19 @@ func Load(configPath string) (*Config, error) {
 	if err := v.Unmarshal(&cfg); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
 	}
+	settings := v.AllSettings()
+	sections, err := decodeSections(settings)
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.validateDatastores(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+	if _, err := cfg.Tracing.ResourceAttributeMap(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+
+	swap(&cfg, settings, sections)
 
 	return &cfg, nil
 }
@@ -79,20 +192,55 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("server.port", 8080)
 	v.SetDefault("server.read_timeout", 30)
 	v.SetDefault("server.write_timeout", 30)
+	v.SetDefault("server.body_limit", 4*1024*1024)
+	v.SetDefault("server.reuse_port", false)
+	v.SetDefault("server.socket_activation", false)
+	v.SetDefault("server.socket_name", "")
+	v.SetDefault("server.admin_addr", "")
 
 	// Logger
 	v.SetDefault("logger.level", "info")
 	v.SetDefault("logger.format", "json")
-	v.SetDefault("logger.output_path", "stdout")
+	v.SetDefault("logger.output_path", []string{"stdout"})
+	v.SetDefault("logger.span_event_level", "warn")
 
 	// Tracing
 	v.SetDefault("tracing.enabled", false)
 	v.SetDefault("tracing.service_name", "microservice")
 	v.SetDefault("tracing.endpoint", "http://localhost:14268/api/traces")
 	v.SetDefault("tracing.sample_rate", 1.0)
+	v.SetDefault("tracing.redact_attributes", false)
+	v.SetDefault("tracing.tail_sampling.enabled", false)
+	v.SetDefault("tracing.tail_sampling.latency_threshold", time.Second)
+	v.SetDefault("tracing.tail_sampling.decision_wait", 10*time.Second)
+	v.SetDefault("tracing.tail_sampling.max_traces", 10000)
 
 	// I18n
 	v.SetDefault("i18n.default_language", "en")
 	v.SetDefault("i18n.supported_languages", []string{"en", "ru"})
 	v.SetDefault("i18n.path", "./locales")
+
+	// Errors
+	v.SetDefault("errors.stats", false)
+
+	// Scheduler
+	v.SetDefault("scheduler.enabled", true)
+	v.SetDefault("scheduler.timezone", "UTC")
+
+	// Metrics
+	v.SetDefault("metrics.enabled", false)
+	v.SetDefault("metrics.exporter", "prometheus")
+	v.SetDefault("metrics.path", "/metrics")
+	v.SetDefault("metrics.namespace", "")
+	v.SetDefault("metrics.endpoint", "localhost:4317")
+	v.SetDefault("metrics.interval", 15)
+
+	// Storage
+	v.SetDefault("storage.driver", "local")
+	v.SetDefault("storage.region", "us-east-1")
+	v.SetDefault("storage.use_ssl", true)
+	v.SetDefault("storage.local_path", "./data")
+	v.SetDefault("storage.public_url", "http://localhost:8080/files")
+
+	setDatastoreDefaults(v)
 }
@@ -0,0 +1,73 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadResourceAttributes(t *testing.T) {
+	cfg, err := Load(writeConfig(t, `
+tracing:
+  resource_attributes:
+    - team=payments
+    - cloud.region = kz-ala
+    - k8s.cluster.name=prod=1
+`))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	attrs, err := cfg.Tracing.ResourceAttributeMap()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"team": "payments", "cloud.region": "kz-ala", "k8s.cluster.name": "prod=1"}
+	if len(attrs) != len(want) {
+		t.Fatalf("attrs = %v", attrs)
+	}
+	for key, value := range want {
+		if attrs[key] != value {
+			t.Errorf("%s = %q, want %q", key, attrs[key], value)
+		}
+	}
+}
+
+func TestLoadRejectsInvalidResourceAttribute(t *testing.T) {
+	if _, err := Load(writeConfig(t, "tracing:\n  resource_attributes: [\"team\"]\n")); err == nil {
+		t.Error("expected error for attribute without value")
+	}
+}
+
+func TestLoadOutputPathStringOrList(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{"string", "logger:\n  output_path: stdout\n", []string{"stdout"}},
+		{"list", "logger:\n  output_path: [stdout, /var/log/app.log]\n", []string{"stdout", "/var/log/app.log"}},
+		{"default", "", []string{"stdout"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := Load(writeConfig(t, tt.content))
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			if !slices.Equal(cfg.Logger.OutputPath, tt.want) {
+				t.Errorf("OutputPath = %v, want %v", cfg.Logger.OutputPath, tt.want)
+			}
+		})
+	}
+}
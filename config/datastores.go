@@ -0,0 +1,379 @@
+package config
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/redis/go-redis/v9"
+	"github.com/spf13/viper"
+)
+
+// TLSConfig holds client TLS configuration
+type TLSConfig struct {
+	Enabled            bool   `mapstructure:"enabled"`
+	CAFile             string `mapstructure:"ca_file"`
+	CertFile           string `mapstructure:"cert_file"`
+	KeyFile            string `mapstructure:"key_file"`
+	InsecureSkipVerify bool   `mapstructure:"insecure_skip_verify"`
+}
+
+// DatabaseConfig holds SQL database configuration, DSN overrides connection parts
+type DatabaseConfig struct {
+	Driver          string        `mapstructure:"driver" enum:"postgres,mysql"`
+	DSN             string        `mapstructure:"dsn"`
+	Host            string        `mapstructure:"host"`
+	Port            int           `mapstructure:"port"`
+	User            string        `mapstructure:"user"`
+	Password        string        `mapstructure:"password"`
+	Name            string        `mapstructure:"name"`
+	SSLMode         string        `mapstructure:"ssl_mode" enum:"disable,require,verify-ca,verify-full"`
+	MaxOpenConns    int           `mapstructure:"max_open_conns"`
+	MaxIdleConns    int           `mapstructure:"max_idle_conns"`
+	ConnMaxLifetime time.Duration `mapstructure:"conn_max_lifetime"`
+	ConnMaxIdleTime time.Duration `mapstructure:"conn_max_idle_time"`
+}
+
+// RedisConfig holds Redis configuration, several addrs mean cluster and
+// master_name means sentinel
+type RedisConfig struct {
+	Addrs        []string      `mapstructure:"addrs"`
+	MasterName   string        `mapstructure:"master_name"`
+	Username     string        `mapstructure:"username"`
+	Password     string        `mapstructure:"password"`
+	DB           int           `mapstructure:"db"`
+	PoolSize     int           `mapstructure:"pool_size"`
+	MinIdleConns int           `mapstructure:"min_idle_conns"`
+	DialTimeout  time.Duration `mapstructure:"dial_timeout"`
+	ReadTimeout  time.Duration `mapstructure:"read_timeout"`
+	WriteTimeout time.Duration `mapstructure:"write_timeout"`
+	TLS          TLSConfig     `mapstructure:"tls"`
+}
+
+// KafkaConfig holds Kafka configuration
+type KafkaConfig struct {
+	Brokers      []string        `mapstructure:"brokers"`
+	ClientID     string          `mapstructure:"client_id"`
+	GroupID      string          `mapstructure:"group_id"`
+	TLS          TLSConfig       `mapstructure:"tls"`
+	SASL         KafkaSASLConfig `mapstructure:"sasl"`
+	RequiredAcks int             `mapstructure:"required_acks"` // -1 all, 0 none, 1 leader
+	BatchTimeout time.Duration   `mapstructure:"batch_timeout"`
+	MinBytes     int             `mapstructure:"min_bytes"`
+	MaxBytes     int             `mapstructure:"max_bytes"`
+	// StartFromOldest reads new consumer groups from the beginning
+	StartFromOldest bool          `mapstructure:"start_from_oldest"`
+	ShutdownTimeout time.Duration `mapstructure:"shutdown_timeout"`
+}
+
+// SearchConfig holds Elasticsearch or OpenSearch configuration, api_key
+// takes precedence over username and password
+type SearchConfig struct {
+	Addresses  []string      `mapstructure:"addresses"`
+	Username   string        `mapstructure:"username"`
+	Password   string        `mapstructure:"password"`
+	APIKey     string        `mapstructure:"api_key"`
+	TLS        TLSConfig     `mapstructure:"tls"`
+	Timeout    time.Duration `mapstructure:"timeout"`
+	MaxRetries int           `mapstructure:"max_retries"`
+	// IndexPrefix is prepended to index and template names, e.g. per environment
+	IndexPrefix string `mapstructure:"index_prefix"`
+}
+
+// KafkaSASLConfig holds Kafka SASL configuration
+type KafkaSASLConfig struct {
+	Mechanism string `mapstructure:"mechanism" enum:"plain,scram-sha-256,scram-sha-512"`
+	Username  string `mapstructure:"username"`
+	Password  string `mapstructure:"password"`
+}
+
+func setDatastoreDefaults(v *viper.Viper) {
+	// Database
+	v.SetDefault("database.driver", "postgres")
+	v.SetDefault("database.port", 5432)
+	v.SetDefault("database.ssl_mode", "disable")
+	v.SetDefault("database.max_open_conns", 25)
+	v.SetDefault("database.max_idle_conns", 5)
+	v.SetDefault("database.conn_max_lifetime", 30*time.Minute)
+	v.SetDefault("database.conn_max_idle_time", 5*time.Minute)
+
+	// Redis
+	v.SetDefault("redis.pool_size", 10)
+	v.SetDefault("redis.dial_timeout", 5*time.Second)
+	v.SetDefault("redis.read_timeout", 3*time.Second)
+	v.SetDefault("redis.write_timeout", 3*time.Second)
+
+	// Kafka
+	v.SetDefault("kafka.client_id", "microservice")
+	v.SetDefault("kafka.required_acks", -1)
+	v.SetDefault("kafka.batch_timeout", 10*time.Millisecond)
+	v.SetDefault("kafka.min_bytes", 1)
+	v.SetDefault("kafka.max_bytes", 10_000_000)
+	v.SetDefault("kafka.start_from_oldest", true)
+	v.SetDefault("kafka.shutdown_timeout", 30*time.Second)
+
+	// Search
+	v.SetDefault("search.timeout", 10*time.Second)
+	v.SetDefault("search.max_retries", 3)
+}
+
+// Configured reports whether the database section is filled in
+func (c DatabaseConfig) Configured() bool {
+	return c.DSN != "" || c.Host != ""
+}
+
+// Validate checks database configuration
+func (c DatabaseConfig) Validate() error {
+	var errs []error
+	if c.Driver != "postgres" && c.Driver != "mysql" {
+		errs = append(errs, fmt.Errorf("database.driver must be postgres or mysql, got %q", c.Driver))
+	}
+	if c.DSN == "" {
+		if c.Host == "" {
+			errs = append(errs, errors.New("database.host or database.dsn is required"))
+		}
+		if c.Name == "" {
+			errs = append(errs, errors.New("database.name is required"))
+		}
+		if c.Port <= 0 || c.Port > 65535 {
+			errs = append(errs, fmt.Errorf("database.port must be 1-65535, got %d", c.Port))
+		}
+	}
+	if c.MaxOpenConns < 0 || c.MaxIdleConns < 0 {
+		errs = append(errs, errors.New("database pool sizes must not be negative"))
+	}
+	if c.MaxOpenConns > 0 && c.MaxIdleConns > c.MaxOpenConns {
+		errs = append(errs, errors.New("database.max_idle_conns must not exceed max_open_conns"))
+	}
+	return errors.Join(errs...)
+}
+
+// ConnectionString returns DSN or builds it from connection parts
+func (c DatabaseConfig) ConnectionString() string {
+	if c.DSN != "" {
+		return c.DSN
+	}
+
+	addr := net.JoinHostPort(c.Host, strconv.Itoa(c.Port))
+	if c.Driver == "mysql" {
+		return fmt.Sprintf("%s:%s@tcp(%s)/%s?parseTime=true", c.User, c.Password, addr, c.Name)
+	}
+
+	u := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(c.User, c.Password),
+		Host:     addr,
+		Path:     "/" + c.Name,
+		RawQuery: url.Values{"sslmode": {c.SSLMode}}.Encode(),
+	}
+	return u.String()
+}
+
+// PgxPoolConfig returns pgx pool config from ConnectionString and pool
+// settings, set ConnConfig.Tracer to tracing.NewPgxTracer() for query spans
+func (c DatabaseConfig) PgxPoolConfig() (*pgxpool.Config, error) {
+	if c.Driver != "postgres" {
+		return nil, fmt.Errorf("pgx pool requires the postgres driver, got %q", c.Driver)
+	}
+	cfg, err := pgxpool.ParseConfig(c.ConnectionString())
+	if err != nil {
+		return nil, fmt.Errorf("invalid database connection string: %w", err)
+	}
+	if c.MaxOpenConns > 0 {
+		cfg.MaxConns = int32(c.MaxOpenConns)
+	}
+	if c.ConnMaxLifetime > 0 {
+		cfg.MaxConnLifetime = c.ConnMaxLifetime
+	}
+	if c.ConnMaxIdleTime > 0 {
+		cfg.MaxConnIdleTime = c.ConnMaxIdleTime
+	}
+	return cfg, nil
+}
+
+// NewPgxPool creates a pgx pool, connections are opened on first use
+func (c DatabaseConfig) NewPgxPool(ctx context.Context) (*pgxpool.Pool, error) {
+	cfg, err := c.PgxPoolConfig()
+	if err != nil {
+		return nil, err
+	}
+	pool, err := pgxpool.NewWithConfig(ctx, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create database pool: %w", err)
+	}
+	return pool, nil
+}
+
+// Configured reports whether the redis section is filled in
+func (c RedisConfig) Configured() bool {
+	return len(c.Addrs) > 0
+}
+
+// Validate checks redis configuration
+func (c RedisConfig) Validate() error {
+	var errs []error
+	if len(c.Addrs) == 0 {
+		errs = append(errs, errors.New("redis.addrs is required"))
+	}
+	for _, addr := range c.Addrs {
+		if _, _, err := net.SplitHostPort(addr); err != nil {
+			errs = append(errs, fmt.Errorf("redis.addrs: invalid address %q", addr))
+		}
+	}
+	if c.DB < 0 || c.DB > 15 {
+		errs = append(errs, fmt.Errorf("redis.db must be 0-15, got %d", c.DB))
+	}
+	if c.PoolSize < 0 || c.MinIdleConns < 0 {
+		errs = append(errs, errors.New("redis pool sizes must not be negative"))
+	}
+	return errors.Join(errs...)
+}
+
+// UniversalOptions returns go-redis options, see RedisConfig for how the
+// client kind is chosen
+func (c RedisConfig) UniversalOptions() (*redis.UniversalOptions, error) {
+	tlsConfig, err := c.TLS.ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("redis tls: %w", err)
+	}
+
+	return &redis.UniversalOptions{
+		Addrs:        c.Addrs,
+		MasterName:   c.MasterName,
+		Username:     c.Username,
+		Password:     c.Password,
+		DB:           c.DB,
+		PoolSize:     c.PoolSize,
+		MinIdleConns: c.MinIdleConns,
+		DialTimeout:  c.DialTimeout,
+		ReadTimeout:  c.ReadTimeout,
+		WriteTimeout: c.WriteTimeout,
+		TLSConfig:    tlsConfig,
+	}, nil
+}
+
+// NewRedisClient creates a single node, cluster or sentinel client, no
+// connection is made until the first command
+func (c RedisConfig) NewRedisClient() (redis.UniversalClient, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	opts, err := c.UniversalOptions()
+	if err != nil {
+		return nil, err
+	}
+	return redis.NewUniversalClient(opts), nil
+}
+
+// ClientConfig builds client tls.Config, nil when TLS is disabled
+func (c TLSConfig) ClientConfig() (*tls.Config, error) {
+	if !c.Enabled {
+		return nil, nil
+	}
+
+	cfg := &tls.Config{
+		InsecureSkipVerify: c.InsecureSkipVerify,
+	}
+
+	if c.CAFile != "" {
+		ca, err := os.ReadFile(c.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("failed to parse CA file %s", c.CAFile)
+		}
+		cfg.RootCAs = pool
+	}
+
+	if c.CertFile != "" && c.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	return cfg, nil
+}
+
+// Configured reports whether the kafka section is filled in
+func (c KafkaConfig) Configured() bool {
+	return len(c.Brokers) > 0
+}
+
+// Validate checks kafka configuration
+func (c KafkaConfig) Validate() error {
+	var errs []error
+	if len(c.Brokers) == 0 {
+		errs = append(errs, errors.New("kafka.brokers is required"))
+	}
+	if c.RequiredAcks < -1 || c.RequiredAcks > 1 {
+		errs = append(errs, fmt.Errorf("kafka.required_acks must be -1, 0 or 1, got %d", c.RequiredAcks))
+	}
+	switch c.SASL.Mechanism {
+	case "":
+	case "plain", "scram-sha-256", "scram-sha-512":
+		if c.SASL.Username == "" {
+			errs = append(errs, errors.New("kafka.sasl.username is required"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("kafka.sasl.mechanism must be plain, scram-sha-256 or scram-sha-512, got %q", c.SASL.Mechanism))
+	}
+	if c.MinBytes > c.MaxBytes {
+		errs = append(errs, errors.New("kafka.min_bytes must not exceed max_bytes"))
+	}
+	return errors.Join(errs...)
+}
+
+// Configured reports whether the search section is filled in
+func (c SearchConfig) Configured() bool {
+	return len(c.Addresses) > 0
+}
+
+// Validate checks search configuration
+func (c SearchConfig) Validate() error {
+	var errs []error
+	if len(c.Addresses) == 0 {
+		errs = append(errs, errors.New("search.addresses is required"))
+	}
+	for _, addr := range c.Addresses {
+		if u, err := url.Parse(addr); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs = append(errs, fmt.Errorf("search.addresses: invalid URL %q", addr))
+		}
+	}
+	if c.Password != "" && c.Username == "" {
+		errs = append(errs, errors.New("search.username is required with password"))
+	}
+	if c.MaxRetries < 0 {
+		errs = append(errs, fmt.Errorf("search.max_retries must not be negative, got %d", c.MaxRetries))
+	}
+	return errors.Join(errs...)
+}
+
+// validateDatastores validates the sections the service has filled in
+func (c *Config) validateDatastores() error {
+	var errs []error
+	if c.Database.Configured() {
+		errs = append(errs, c.Database.Validate())
+	}
+	if c.Redis.Configured() {
+		errs = append(errs, c.Redis.Validate())
+	}
+	if c.Kafka.Configured() {
+		errs = append(errs, c.Kafka.Validate())
+	}
+	if c.Search.Configured() {
+		errs = append(errs, c.Search.Validate())
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,125 @@
+package config
+
+import (
+	"context"
+	"encoding/pem"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+func TestPgxPoolConfig(t *testing.T) {
+	db := DatabaseConfig{
+		Driver:          "postgres",
+		Host:            "db.internal",
+		Port:            5433,
+		User:            "app",
+		Password:        "p@ss word",
+		Name:            "orders",
+		SSLMode:         "disable",
+		MaxOpenConns:    40,
+		ConnMaxLifetime: time.Hour,
+		ConnMaxIdleTime: time.Minute,
+	}
+
+	cfg, err := db.PgxPoolConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn := cfg.ConnConfig
+	if conn.Host != "db.internal" || conn.Port != 5433 || conn.User != "app" || conn.Password != "p@ss word" || conn.Database != "orders" {
+		t.Errorf("unexpected connection %s@%s:%d/%s", conn.User, conn.Host, conn.Port, conn.Database)
+	}
+	if cfg.MaxConns != 40 || cfg.MaxConnLifetime != time.Hour || cfg.MaxConnIdleTime != time.Minute {
+		t.Errorf("unexpected pool settings %d %s %s", cfg.MaxConns, cfg.MaxConnLifetime, cfg.MaxConnIdleTime)
+	}
+
+	pool, err := db.NewPgxPool(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool.Close()
+}
+
+func TestPgxPoolRequiresPostgres(t *testing.T) {
+	if _, err := (DatabaseConfig{Driver: "mysql", Host: "db", Name: "x", Port: 3306}).PgxPoolConfig(); err == nil {
+		t.Error("expected error for mysql")
+	}
+}
+
+func TestNewRedisClientKinds(t *testing.T) {
+	tests := []struct {
+		name   string
+		config RedisConfig
+		check  func(redis.UniversalClient) bool
+	}{
+		{"single", RedisConfig{Addrs: []string{"localhost:6379"}}, func(c redis.UniversalClient) bool {
+			_, ok := c.(*redis.Client)
+			return ok
+		}},
+		{"cluster", RedisConfig{Addrs: []string{"a:6379", "b:6379"}}, func(c redis.UniversalClient) bool {
+			_, ok := c.(*redis.ClusterClient)
+			return ok
+		}},
+		{"sentinel", RedisConfig{Addrs: []string{"s:26379"}, MasterName: "main"}, func(c redis.UniversalClient) bool {
+			_, ok := c.(*redis.Client)
+			return ok
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := tt.config.NewRedisClient()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer client.Close()
+			if !tt.check(client) {
+				t.Errorf("unexpected client %T", client)
+			}
+		})
+	}
+}
+
+func TestNewRedisClientRejectsInvalidConfig(t *testing.T) {
+	if _, err := (RedisConfig{Addrs: []string{"no-port"}}).NewRedisClient(); err == nil {
+		t.Error("expected error for address without port")
+	}
+}
+
+func TestRedisUniversalOptionsTLS(t *testing.T) {
+	srv := httptest.NewTLSServer(nil)
+	defer srv.Close()
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	if err := os.WriteFile(caFile, ca, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	opts, err := RedisConfig{
+		Addrs:       []string{"redis:6380"},
+		Password:    "secret",
+		DB:          2,
+		DialTimeout: time.Second,
+		TLS:         TLSConfig{Enabled: true, CAFile: caFile},
+	}.UniversalOptions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opts.TLSConfig == nil || opts.TLSConfig.RootCAs == nil {
+		t.Fatal("TLS config with CA pool expected")
+	}
+	if opts.Password != "secret" || opts.DB != 2 || opts.DialTimeout != time.Second {
+		t.Errorf("unexpected options %+v", opts)
+	}
+
+	if _, err := (RedisConfig{Addrs: []string{"redis:6380"}, TLS: TLSConfig{Enabled: true, CAFile: "missing.pem"}}).UniversalOptions(); err == nil {
+		t.Error("expected error for missing CA file")
+	}
+	if opts, _ := (RedisConfig{Addrs: []string{"redis:6379"}}).UniversalOptions(); opts.TLSConfig != nil {
+		t.Error("TLS must stay disabled by default")
+	}
+}
@@ -0,0 +1,76 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+)
+
+// redactedValue replaces secrets in Redacted output
+const redactedValue = "[REDACTED]"
+
+// secretKeys are key fragments whose values are redacted
+var secretKeys = []string{"password", "secret", "token", "dsn", "access_key", "private_key", "api_key"}
+
+// Fingerprint returns a short hash of the effective settings, equal on all
+// replicas running the same configuration. It is computed from Redacted
+// settings: a plain hash of a low-entropy password could be brute-forced
+// offline, so rotating a secret alone does not change the fingerprint
+func (r *Revision) Fingerprint() string {
+	return r.fingerprint
+}
+
+// Redacted returns settings with secret values replaced, safe to log
+func (r *Revision) Redacted() map[string]interface{} {
+	if r.settings == nil {
+		return map[string]interface{}{}
+	}
+	return redact(r.settings).(map[string]interface{})
+}
+
+func fingerprint(settings map[string]interface{}) string {
+	// encoding/json sorts map keys, so equal settings give equal hashes
+	data, err := json.Marshal(redact(settings))
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8])
+}
+
+func redact(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			if isSecretKey(key) {
+				if item != nil && item != "" {
+					out[key] = redactedValue
+				} else {
+					out[key] = item
+				}
+				continue
+			}
+			out[key] = redact(item)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			out[i] = redact(item)
+		}
+		return out
+	}
+	return value
+}
+
+func isSecretKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, secret := range secretKeys {
+		if strings.Contains(key, secret) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,42 @@
+package config
+
+import "testing"
+
+func TestFingerprintIgnoresSecretValues(t *testing.T) {
+	settings := func(password, host string) map[string]interface{} {
+		return map[string]interface{}{
+			"database": map[string]interface{}{"host": host, "password": password},
+		}
+	}
+
+	base := fingerprint(settings("hunter2", "db-1"))
+	if base == "" {
+		t.Fatal("empty fingerprint")
+	}
+	if got := fingerprint(settings("correct-horse", "db-1")); got != base {
+		t.Errorf("fingerprint depends on the secret value: %s != %s", got, base)
+	}
+	if got := fingerprint(settings("hunter2", "db-2")); got == base {
+		t.Error("fingerprint must change with non-secret settings")
+	}
+}
+
+func TestRevisionRedacted(t *testing.T) {
+	rev := &Revision{settings: map[string]interface{}{
+		"redis": map[string]interface{}{"addr": "localhost:6379", "password": "x", "token": ""},
+		"hooks": []interface{}{map[string]interface{}{"api_key": "k"}},
+	}}
+
+	redacted := rev.Redacted()
+	redis := redacted["redis"].(map[string]interface{})
+	if redis["password"] != redactedValue || redis["addr"] != "localhost:6379" || redis["token"] != "" {
+		t.Errorf("unexpected redis section: %v", redis)
+	}
+	hook := redacted["hooks"].([]interface{})[0].(map[string]interface{})
+	if hook["api_key"] != redactedValue {
+		t.Errorf("secret in list not redacted: %v", hook)
+	}
+	if rev.settings["redis"].(map[string]interface{})["password"] != "x" {
+		t.Error("Redacted must not modify settings")
+	}
+}
@@ -0,0 +1,227 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// SchemaID is $schema of the generated schema
+const SchemaID = "https://json-schema.org/draft/2020-12/schema"
+
+var (
+	sectionsMu sync.RWMutex
+	sections   = make(map[string]interface{})
+)
+
+// Register adds a custom top-level section. section is a pointer to a struct
+// with mapstructure tags, its values at registration are the defaults and
+// can be overridden by config.yaml and APP_<SECTION>_<KEY> env vars. Load
+// decodes the section into a fresh copy for every revision and leaves
+// section unchanged, read it with Section. Fields may declare allowed values
+// with an enum:"a,b" tag. Registered sections are included in Schema
+func Register(name string, section interface{}) {
+	if t := reflect.TypeOf(section); t == nil || t.Kind() != reflect.Ptr || t.Elem().Kind() != reflect.Struct {
+		panic(fmt.Sprintf("config: section %s must be a pointer to struct", name))
+	}
+
+	sectionsMu.Lock()
+	defer sectionsMu.Unlock()
+
+	if _, ok := sections[name]; ok {
+		panic(fmt.Sprintf("config: section %s is already registered", name))
+	}
+	sections[name] = section
+}
+
+// Section returns the registered section decoded for rev, false when the
+// section is not registered or has another type
+//
+//	payments, _ := config.Section[PaymentsConfig](config.Snapshot(), "payments")
+func Section[T any](rev *Revision, name string) (T, bool) {
+	value, ok := rev.sections[name].(T)
+	return value, ok
+}
+
+// setSectionDefaults registers leaf keys of sections as viper defaults:
+// AutomaticEnv and Unmarshal only see env vars of keys viper knows about
+func setSectionDefaults(v *viper.Viper) {
+	sectionsMu.RLock()
+	defer sectionsMu.RUnlock()
+
+	for name, section := range sections {
+		setStructDefaults(v, name, reflect.ValueOf(section).Elem())
+	}
+}
+
+func setStructDefaults(v *viper.Viper, prefix string, value reflect.Value) {
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		name := strings.Split(field.Tag.Get("mapstructure"), ",")[0]
+		if name == "" || name == "-" || !field.IsExported() {
+			continue
+		}
+
+		key := prefix + "." + name
+		if field.Type.Kind() == reflect.Struct && field.Type != reflect.TypeOf(time.Time{}) {
+			setStructDefaults(v, key, value.Field(i))
+			continue
+		}
+		v.SetDefault(key, value.Field(i).Interface())
+	}
+}
+
+// decodeSections decodes registered sections from resolved settings into
+// new values, settings come from AllSettings so env overrides of nested
+// keys are included, defaults are set by setSectionDefaults
+func decodeSections(settings map[string]interface{}) (map[string]interface{}, error) {
+	sectionsMu.RLock()
+	defer sectionsMu.RUnlock()
+
+	v := viper.New()
+	if err := v.MergeConfigMap(settings); err != nil {
+		return nil, err
+	}
+	decoded := make(map[string]interface{}, len(sections))
+	for name, section := range sections {
+		value := reflect.New(reflect.TypeOf(section).Elem())
+		if err := v.UnmarshalKey(name, value.Interface()); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal config section %s: %w", name, err)
+		}
+		decoded[name] = value.Elem().Interface()
+	}
+	return decoded, nil
+}
+
+// Schema returns JSON Schema of config.yaml including registered sections,
+// for validating config in pipelines and generating operator docs
+func Schema() ([]byte, error) {
+	v := viper.New()
+	setDefaults(v)
+
+	schema := structSchema(reflect.TypeOf(Config{}), func(key string) interface{} {
+		return v.Get(key)
+	}, "")
+	schema["$schema"] = SchemaID
+	schema["title"] = "Service configuration"
+
+	sectionsMu.RLock()
+	defer sectionsMu.RUnlock()
+
+	properties := schema["properties"].(map[string]interface{})
+	for name, section := range sections {
+		value := reflect.ValueOf(section).Elem()
+		properties[name] = structSchema(value.Type(), func(key string) interface{} {
+			return fieldValue(value, strings.Split(key, ".")[1:])
+		}, name)
+	}
+
+	return json.MarshalIndent(schema, "", "  ")
+}
+
+// structSchema describes struct t, defaults are looked up by dotted key
+func structSchema(t reflect.Type, defaults func(key string) interface{}, prefix string) map[string]interface{} {
+	properties := make(map[string]interface{})
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := strings.Split(field.Tag.Get("mapstructure"), ",")[0]
+		if name == "" || name == "-" || !field.IsExported() {
+			continue
+		}
+
+		key := name
+		if prefix != "" {
+			key = prefix + "." + name
+		}
+
+		var property map[string]interface{}
+		if field.Type.Kind() == reflect.Struct {
+			property = structSchema(field.Type, defaults, key)
+		} else {
+			property = typeSchema(field.Type)
+			if value := defaults(key); value != nil && !reflect.ValueOf(value).IsZero() {
+				if d, ok := value.(time.Duration); ok {
+					value = d.String()
+				}
+				property["default"] = value
+			}
+		}
+		if enum := field.Tag.Get("enum"); enum != "" {
+			property["enum"] = strings.Split(enum, ",")
+		}
+		properties[name] = property
+	}
+
+	return map[string]interface{}{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+}
+
+func typeSchema(t reflect.Type) map[string]interface{} {
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		// time.Duration is decoded from strings like 30s
+		if t.PkgPath() == "time" && t.Name() == "Duration" {
+			return map[string]interface{}{"type": []string{"string", "integer"}}
+		}
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		// a string is split on commas into a list, output_path: stdout
+		if t.Kind() == reflect.Slice && scalarKind(t.Elem().Kind()) {
+			return map[string]interface{}{"type": []string{"string", "array"}, "items": typeSchema(t.Elem())}
+		}
+		return map[string]interface{}{"type": "array", "items": typeSchema(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": typeSchema(t.Elem())}
+	case reflect.Ptr:
+		return typeSchema(t.Elem())
+	case reflect.Struct:
+		return structSchema(t, func(string) interface{} { return nil }, "")
+	}
+	return map[string]interface{}{}
+}
+
+func scalarKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Struct, reflect.Map, reflect.Slice, reflect.Array, reflect.Ptr, reflect.Interface:
+		return false
+	}
+	return true
+}
+
+// fieldValue returns value of the field at mapstructure path
+func fieldValue(v reflect.Value, path []string) interface{} {
+	for _, name := range path {
+		if v.Kind() != reflect.Struct {
+			return nil
+		}
+		found := false
+		for i := 0; i < v.NumField(); i++ {
+			if strings.Split(v.Type().Field(i).Tag.Get("mapstructure"), ",")[0] == name {
+				v, found = v.Field(i), true
+				break
+			}
+		}
+		if !found {
+			return nil
+		}
+	}
+	if !v.CanInterface() {
+		return nil
+	}
+	return v.Interface()
+}
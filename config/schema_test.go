@@ -0,0 +1,119 @@
+package config
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+type testPaymentsConfig struct {
+	Provider string        `mapstructure:"provider" enum:"stripe,adyen"`
+	Timeout  time.Duration `mapstructure:"timeout"`
+	Retry    struct {
+		Attempts int `mapstructure:"attempts"`
+	} `mapstructure:"retry"`
+}
+
+func registerTestSection(t *testing.T, name string, section interface{}) {
+	t.Helper()
+	Register(name, section)
+	t.Cleanup(func() {
+		sectionsMu.Lock()
+		delete(sections, name)
+		sectionsMu.Unlock()
+	})
+}
+
+func TestRegisteredSectionEnvOverride(t *testing.T) {
+	defaults := &testPaymentsConfig{Provider: "stripe", Timeout: 10 * time.Second}
+	defaults.Retry.Attempts = 3
+	registerTestSection(t, "payments", defaults)
+
+	t.Setenv("APP_PAYMENTS_PROVIDER", "adyen")
+	t.Setenv("APP_PAYMENTS_RETRY_ATTEMPTS", "5")
+	if _, err := Load(""); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	payments, ok := Section[testPaymentsConfig](Snapshot(), "payments")
+	if !ok {
+		t.Fatal("section not decoded")
+	}
+	if payments.Provider != "adyen" || payments.Retry.Attempts != 5 || payments.Timeout != 10*time.Second {
+		t.Errorf("unexpected section %+v", payments)
+	}
+	if defaults.Provider != "stripe" {
+		t.Error("Load modified the registered defaults")
+	}
+	if Snapshot().MustString("payments.provider") != "adyen" {
+		t.Error("section settings missing from the revision")
+	}
+}
+
+func TestRegisteredSectionImmutableAcrossReloads(t *testing.T) {
+	registerTestSection(t, "payments", &testPaymentsConfig{Provider: "stripe"})
+
+	first, err := Load(writeConfig(t, "payments:\n  provider: stripe\n"))
+	if err != nil || first == nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	old := Snapshot()
+
+	if _, err := Load(writeConfig(t, "payments:\n  provider: adyen\n")); err != nil {
+		t.Fatalf("reload error = %v", err)
+	}
+
+	before, _ := Section[testPaymentsConfig](old, "payments")
+	after, _ := Section[testPaymentsConfig](Snapshot(), "payments")
+	if before.Provider != "stripe" || after.Provider != "adyen" {
+		t.Errorf("before = %s, after = %s", before.Provider, after.Provider)
+	}
+	if _, ok := Section[string](Snapshot(), "payments"); ok {
+		t.Error("Section must report a type mismatch")
+	}
+}
+
+func TestSchemaIncludesSections(t *testing.T) {
+	registerTestSection(t, "payments", &testPaymentsConfig{Provider: "stripe"})
+
+	data, err := Schema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var schema struct {
+		Properties map[string]struct {
+			Properties map[string]map[string]interface{} `json:"properties"`
+		} `json:"properties"`
+	}
+	if err := json.Unmarshal(data, &schema); err != nil {
+		t.Fatal(err)
+	}
+
+	provider := schema.Properties["payments"].Properties["provider"]
+	if provider["default"] != "stripe" || provider["enum"] == nil {
+		t.Errorf("unexpected provider schema %v", provider)
+	}
+	if schema.Properties["server"].Properties["port"]["type"] != "integer" {
+		t.Errorf("built-in sections missing: %v", schema.Properties["server"])
+	}
+}
+
+func TestSchemaAcceptsStringForLists(t *testing.T) {
+	data, err := Schema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var schema struct {
+		Properties map[string]struct {
+			Properties map[string]map[string]interface{} `json:"properties"`
+		} `json:"properties"`
+	}
+	if err := json.Unmarshal(data, &schema); err != nil {
+		t.Fatal(err)
+	}
+
+	types, _ := schema.Properties["logger"].Properties["output_path"]["type"].([]interface{})
+	if len(types) != 2 || types[0] != "string" || types[1] != "array" {
+		t.Errorf("output_path type = %v, want [string array]", types)
+	}
+}
@@ -0,0 +1,200 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Revision is an immutable configuration snapshot. Readers hold a revision for
+// the duration of an operation, so a reload never exposes a half-updated config
+type Revision struct {
+	generation uint64
+	config     Config
+	settings   map[string]interface{}
+	// sections are registered sections decoded for this revision
+	sections    map[string]interface{}
+	fingerprint string
+	loadedAt    time.Time
+}
+
+var (
+	current atomic.Pointer[Revision]
+
+	// swapMu orders swaps and their notifications, subMu guards subscribers
+	// so that callbacks may subscribe and unsubscribe
+	swapMu      sync.Mutex
+	subMu       sync.Mutex
+	subscribers = make(map[uint64]func(old, new *Revision))
+	nextSub     uint64
+)
+
+// Snapshot returns the latest revision published by Load or Swap, nil before
+// the first Load
+func Snapshot() *Revision {
+	return current.Load()
+}
+
+// Swap publishes cfg as the next generation and notifies subscribers in
+// the order of subscription. settings are raw values for Must accessors,
+// may be nil
+func Swap(cfg *Config, settings map[string]interface{}) *Revision {
+	return swap(cfg, settings, nil)
+}
+
+func swap(cfg *Config, settings, sections map[string]interface{}) *Revision {
+	swapMu.Lock()
+	defer swapMu.Unlock()
+
+	old := current.Load()
+	next := &Revision{
+		config:      *cfg,
+		settings:    settings,
+		sections:    sections,
+		fingerprint: fingerprint(settings),
+		loadedAt:    time.Now(),
+	}
+	if old != nil {
+		next.generation = old.generation + 1
+	} else {
+		next.generation = 1
+	}
+	current.Store(next)
+
+	subMu.Lock()
+	ids := make([]uint64, 0, len(subscribers))
+	for id := range subscribers {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	fns := make([]func(old, new *Revision), len(ids))
+	for i, id := range ids {
+		fns[i] = subscribers[id]
+	}
+	subMu.Unlock()
+
+	for _, fn := range fns {
+		fn(old, next)
+	}
+	return next
+}
+
+// Subscribe calls fn after every Swap with the previous and new revisions,
+// fn must not call Swap or Load. fn may subscribe or unsubscribe, the change
+// applies from the next Swap. Returns a function removing the subscription
+func Subscribe(fn func(old, new *Revision)) func() {
+	subMu.Lock()
+	defer subMu.Unlock()
+
+	nextSub++
+	id := nextSub
+	subscribers[id] = fn
+
+	return func() {
+		subMu.Lock()
+		delete(subscribers, id)
+		subMu.Unlock()
+	}
+}
+
+// Generation returns the version number, incremented on every Swap
+func (r *Revision) Generation() uint64 {
+	return r.generation
+}
+
+// LoadedAt returns time the revision was published
+func (r *Revision) LoadedAt() time.Time {
+	return r.loadedAt
+}
+
+// Config returns a copy of the configuration, slices are shared and must
+// not be modified
+func (r *Revision) Config() Config {
+	return r.config
+}
+
+// Value returns raw value at dotted key, e.g. "payments.timeout"
+func (r *Revision) Value(key string) (interface{}, bool) {
+	var value interface{} = r.settings
+	for _, part := range strings.Split(strings.ToLower(key), ".") {
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if value, ok = m[part]; !ok {
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+// MustString returns string at key, panics if it is missing
+func (r *Revision) MustString(key string) string {
+	value := r.mustValue(key)
+	if str, ok := value.(string); ok {
+		return str
+	}
+	return fmt.Sprint(value)
+}
+
+// MustInt returns integer at key, panics if it is missing or not a number
+func (r *Revision) MustInt(key string) int {
+	switch value := r.mustValue(key).(type) {
+	case int:
+		return value
+	case int64:
+		return int(value)
+	case float64:
+		return int(value)
+	case string:
+		if n, err := strconv.Atoi(value); err == nil {
+			return n
+		}
+	}
+	panic(fmt.Sprintf("config: %s is not an integer", key))
+}
+
+// MustBool returns boolean at key, panics if it is missing or not a boolean
+func (r *Revision) MustBool(key string) bool {
+	switch value := r.mustValue(key).(type) {
+	case bool:
+		return value
+	case string:
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	}
+	panic(fmt.Sprintf("config: %s is not a boolean", key))
+}
+
+// MustDuration returns duration at key: a string like 30s or a number of
+// seconds, as in the built-in sections. Panics if it is missing or invalid
+func (r *Revision) MustDuration(key string) time.Duration {
+	switch value := r.mustValue(key).(type) {
+	case time.Duration:
+		return value
+	case int:
+		return time.Duration(value) * time.Second
+	case int64:
+		return time.Duration(value) * time.Second
+	case float64:
+		return time.Duration(value * float64(time.Second))
+	case string:
+		if d, err := time.ParseDuration(value); err == nil {
+			return d
+		}
+	}
+	panic(fmt.Sprintf("config: %s is not a duration", key))
+}
+
+func (r *Revision) mustValue(key string) interface{} {
+	value, ok := r.Value(key)
+	if !ok {
+		panic(fmt.Sprintf("config: %s is not set", key))
+	}
+	return value
+}
@@ -0,0 +1,127 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSwapIncrementsGeneration(t *testing.T) {
+	first := Swap(&Config{}, nil)
+	second := Swap(&Config{}, nil)
+
+	if second.Generation() != first.Generation()+1 {
+		t.Errorf("generation = %d, want %d", second.Generation(), first.Generation()+1)
+	}
+	if Snapshot() != second {
+		t.Error("Snapshot() is not the latest revision")
+	}
+	if second.LoadedAt().Before(first.LoadedAt()) {
+		t.Error("LoadedAt goes back in time")
+	}
+}
+
+func TestSubscribeOrder(t *testing.T) {
+	var calls []string
+	var prev, next *Revision
+	unsubscribeA := Subscribe(func(old, new *Revision) {
+		calls = append(calls, "a")
+		prev, next = old, new
+	})
+	defer unsubscribeA()
+	unsubscribeB := Subscribe(func(old, new *Revision) { calls = append(calls, "b") })
+	unsubscribeC := Subscribe(func(old, new *Revision) { calls = append(calls, "c") })
+	defer unsubscribeC()
+
+	old := Snapshot()
+	rev := Swap(&Config{}, nil)
+	if strings.Join(calls, "") != "abc" {
+		t.Errorf("calls = %v, want subscription order a b c", calls)
+	}
+	if prev != old || next != rev {
+		t.Error("subscriber got wrong revisions")
+	}
+
+	calls = nil
+	unsubscribeB()
+	Swap(&Config{}, nil)
+	if strings.Join(calls, "") != "ac" {
+		t.Errorf("calls = %v, want a c after unsubscribe", calls)
+	}
+}
+
+func TestSubscriberMaySubscribe(t *testing.T) {
+	nested := 0
+	var unsubscribe func()
+	unsubscribe = Subscribe(func(old, new *Revision) {
+		unsubscribe()
+		Subscribe(func(old, new *Revision) { nested++ })()
+		stop := Subscribe(func(old, new *Revision) { nested++ })
+		t.Cleanup(stop)
+	})
+
+	done := make(chan struct{})
+	go func() {
+		Swap(&Config{}, nil)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Swap deadlocked on a subscriber calling Subscribe")
+	}
+
+	if nested != 0 {
+		t.Errorf("nested = %d, a subscription made during Swap must wait for the next one", nested)
+	}
+	Swap(&Config{}, nil)
+	if nested != 1 {
+		t.Errorf("nested = %d, want 1 after the next Swap", nested)
+	}
+}
+
+func TestRevisionMustAccessors(t *testing.T) {
+	rev := Swap(&Config{}, map[string]interface{}{
+		"payments": map[string]interface{}{
+			"provider": "stripe",
+			"attempts": "3",
+			"limit":    float64(10),
+			"enabled":  "true",
+			"timeout":  "30s",
+			"interval": 5,
+		},
+	})
+
+	if got := rev.MustString("Payments.Provider"); got != "stripe" {
+		t.Errorf("MustString = %q", got)
+	}
+	if rev.MustInt("payments.attempts") != 3 || rev.MustInt("payments.limit") != 10 {
+		t.Errorf("MustInt = %d, %d", rev.MustInt("payments.attempts"), rev.MustInt("payments.limit"))
+	}
+	if !rev.MustBool("payments.enabled") {
+		t.Error("MustBool = false")
+	}
+	if rev.MustDuration("payments.timeout") != 30*time.Second || rev.MustDuration("payments.interval") != 5*time.Second {
+		t.Errorf("MustDuration = %s, %s", rev.MustDuration("payments.timeout"), rev.MustDuration("payments.interval"))
+	}
+	if _, ok := rev.Value("payments.provider.name"); ok {
+		t.Error("Value descends into a string")
+	}
+
+	panics := map[string]func(){
+		"missing":      func() { rev.MustString("payments.missing") },
+		"not int":      func() { rev.MustInt("payments.provider") },
+		"not bool":     func() { rev.MustBool("payments.provider") },
+		"not duration": func() { rev.MustDuration("payments.provider") },
+	}
+	for name, fn := range panics {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("no panic")
+				}
+			}()
+			fn()
+		})
+	}
+}
@@ -0,0 +1,178 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/spf13/viper"
+)
+
+type tenantKey struct{}
+
+// WithTenant returns context carrying tenant ID for ForTenant
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenant)
+}
+
+// TenantFromContext returns tenant ID stored by WithTenant
+func TenantFromContext(ctx context.Context) string {
+	tenant, _ := ctx.Value(tenantKey{}).(string)
+	return tenant
+}
+
+// TenantProvider returns settings overlaid over the base config for a tenant,
+// nil settings mean the tenant uses the base config
+type TenantProvider interface {
+	TenantSettings(ctx context.Context, tenant string) (map[string]interface{}, error)
+}
+
+// TenantProviderFunc adapts a function, e.g. a database query, to TenantProvider
+type TenantProviderFunc func(ctx context.Context, tenant string) (map[string]interface{}, error)
+
+// TenantSettings implements TenantProvider
+func (f TenantProviderFunc) TenantSettings(ctx context.Context, tenant string) (map[string]interface{}, error) {
+	return f(ctx, tenant)
+}
+
+// DirTenantProvider reads overlays from <dir>/<tenant>.yaml
+type DirTenantProvider struct {
+	dir string
+}
+
+// NewDirTenantProvider creates provider for a tenants directory
+func NewDirTenantProvider(dir string) *DirTenantProvider {
+	return &DirTenantProvider{dir: dir}
+}
+
+// TenantSettings implements TenantProvider
+func (p *DirTenantProvider) TenantSettings(_ context.Context, tenant string) (map[string]interface{}, error) {
+	if tenant == "" || strings.ContainsAny(tenant, `/\`) || strings.HasPrefix(tenant, ".") {
+		return nil, fmt.Errorf("invalid tenant id %q", tenant)
+	}
+
+	path := filepath.Join(p.dir, tenant+".yaml")
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+
+	v := viper.New()
+	v.SetConfigFile(path)
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("failed to read tenant config: %w", err)
+	}
+	return v.AllSettings(), nil
+}
+
+// maxTenantCache bounds cached overlays, tenant ids may come from request
+// headers
+const maxTenantCache = 1024
+
+var (
+	tenantMu       sync.Mutex
+	tenantProvider TenantProvider
+	// tenantCache holds merged revisions of the current base generation
+	tenantCache      = make(map[string]*Revision)
+	tenantGeneration uint64
+)
+
+// SetTenantProvider enables ForTenant overlays
+func SetTenantProvider(provider TenantProvider) {
+	tenantMu.Lock()
+	defer tenantMu.Unlock()
+
+	tenantProvider = provider
+	tenantCache = make(map[string]*Revision)
+}
+
+// InvalidateTenant drops the cached overlay, e.g. after a tenant's settings
+// change in the database. Overlays are dropped on every Swap as well
+func InvalidateTenant(tenant string) {
+	tenantMu.Lock()
+	delete(tenantCache, tenant)
+	tenantMu.Unlock()
+}
+
+// ForTenant returns the current revision with settings of the context tenant
+// overlaid. The base revision is returned without a tenant or provider
+func ForTenant(ctx context.Context) (*Revision, error) {
+	base := Snapshot()
+	if base == nil {
+		return nil, fmt.Errorf("config is not loaded")
+	}
+
+	tenant := TenantFromContext(ctx)
+	tenantMu.Lock()
+	provider := tenantProvider
+	if tenantGeneration != base.generation {
+		tenantCache = make(map[string]*Revision)
+		tenantGeneration = base.generation
+	}
+	cached, ok := tenantCache[tenant]
+	tenantMu.Unlock()
+
+	if tenant == "" || provider == nil {
+		return base, nil
+	}
+	if ok {
+		return cached, nil
+	}
+
+	overlay, err := provider.TenantSettings(ctx, tenant)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load tenant %s config: %w", tenant, err)
+	}
+
+	// Unknown tenants are not cached, so arbitrary ids do not grow the cache
+	if overlay == nil {
+		return base, nil
+	}
+	revision, err := base.overlay(overlay)
+	if err != nil {
+		return nil, fmt.Errorf("failed to apply tenant %s config: %w", tenant, err)
+	}
+
+	tenantMu.Lock()
+	if tenantGeneration == base.generation {
+		// Cleared when full, the working set refills it
+		if len(tenantCache) >= maxTenantCache {
+			tenantCache = make(map[string]*Revision)
+		}
+		tenantCache[tenant] = revision
+	}
+	tenantMu.Unlock()
+	return revision, nil
+}
+
+// overlay returns revision of the same generation with settings merged over
+func (r *Revision) overlay(settings map[string]interface{}) (*Revision, error) {
+	v := viper.New()
+	if err := v.MergeConfigMap(r.settings); err != nil {
+		return nil, err
+	}
+	if err := v.MergeConfigMap(settings); err != nil {
+		return nil, err
+	}
+
+	var cfg Config
+	if err := v.Unmarshal(&cfg); err != nil {
+		return nil, err
+	}
+	sections, err := decodeSections(v.AllSettings())
+	if err != nil {
+		return nil, err
+	}
+	settings = v.AllSettings()
+	return &Revision{
+		generation:  r.generation,
+		config:      cfg,
+		settings:    settings,
+		sections:    sections,
+		fingerprint: fingerprint(settings),
+		loadedAt:    r.loadedAt,
+	}, nil
+}
@@ -0,0 +1,152 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// useTenantDir swaps in a base revision and serves overlays from a temp dir,
+// counting provider calls
+func useTenantDir(t *testing.T, files map[string]string) *int {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	Swap(&Config{Server: ServerConfig{Host: "0.0.0.0", Port: 8080}}, map[string]interface{}{
+		"server": map[string]interface{}{"host": "0.0.0.0", "port": 8080},
+	})
+
+	calls := 0
+	dirProvider := NewDirTenantProvider(dir)
+	SetTenantProvider(TenantProviderFunc(func(ctx context.Context, tenant string) (map[string]interface{}, error) {
+		calls++
+		return dirProvider.TenantSettings(ctx, tenant)
+	}))
+	t.Cleanup(func() { SetTenantProvider(nil) })
+	return &calls
+}
+
+func TestForTenantMergesOverlay(t *testing.T) {
+	useTenantDir(t, map[string]string{"acme.yaml": "server:\n  port: 9090\n"})
+
+	rev, err := ForTenant(WithTenant(context.Background(), "acme"))
+	if err != nil {
+		t.Fatalf("ForTenant() error = %v", err)
+	}
+	cfg := rev.Config()
+	if cfg.Server.Port != 9090 || cfg.Server.Host != "0.0.0.0" {
+		t.Errorf("server = %+v, want port from the overlay and host from the base", cfg.Server)
+	}
+	if rev.MustInt("server.port") != 9090 {
+		t.Errorf("server.port = %d, want 9090", rev.MustInt("server.port"))
+	}
+	if rev.Generation() != Snapshot().Generation() {
+		t.Errorf("generation = %d, want the base generation %d", rev.Generation(), Snapshot().Generation())
+	}
+	if Snapshot().Config().Server.Port != 8080 {
+		t.Error("overlay modified the base revision")
+	}
+}
+
+func TestForTenantWithoutTenantOrFile(t *testing.T) {
+	useTenantDir(t, nil)
+	base := Snapshot()
+
+	rev, err := ForTenant(context.Background())
+	if err != nil || rev != base {
+		t.Errorf("ForTenant() without tenant = %v, %v, want the base revision", rev, err)
+	}
+
+	rev, err = ForTenant(WithTenant(context.Background(), "globex"))
+	if err != nil {
+		t.Fatalf("ForTenant() error = %v", err)
+	}
+	if rev != base {
+		t.Error("tenant without a file does not fall back to the base revision")
+	}
+}
+
+func TestForTenantCacheIsBounded(t *testing.T) {
+	calls := useTenantDir(t, nil)
+
+	// Unknown tenants, e.g. from a forged header, are not cached
+	for range 2 {
+		if _, err := ForTenant(WithTenant(context.Background(), "forged")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tenantMu.Lock()
+	cached := len(tenantCache)
+	tenantMu.Unlock()
+	if cached != 0 || *calls != 2 {
+		t.Errorf("cached = %d, calls = %d, want tenants without overlay left out of the cache", cached, *calls)
+	}
+
+	SetTenantProvider(TenantProviderFunc(func(ctx context.Context, tenant string) (map[string]interface{}, error) {
+		return map[string]interface{}{"server": map[string]interface{}{"port": 9090}}, nil
+	}))
+	for i := range maxTenantCache + 10 {
+		if _, err := ForTenant(WithTenant(context.Background(), fmt.Sprintf("tenant-%d", i))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tenantMu.Lock()
+	cached = len(tenantCache)
+	tenantMu.Unlock()
+	if cached > maxTenantCache {
+		t.Errorf("cached = %d, want at most %d", cached, maxTenantCache)
+	}
+}
+
+func TestForTenantCache(t *testing.T) {
+	calls := useTenantDir(t, map[string]string{"acme.yaml": "server:\n  port: 9090\n"})
+	ctx := WithTenant(context.Background(), "acme")
+
+	first, err := ForTenant(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, _ := ForTenant(ctx)
+	if second != first || *calls != 1 {
+		t.Errorf("calls = %d, want the cached overlay on the second call", *calls)
+	}
+
+	InvalidateTenant("acme")
+	if _, err := ForTenant(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if *calls != 2 {
+		t.Errorf("calls = %d, want a reload after InvalidateTenant", *calls)
+	}
+
+	Swap(&Config{Server: ServerConfig{Host: "127.0.0.1", Port: 8080}}, map[string]interface{}{
+		"server": map[string]interface{}{"host": "127.0.0.1", "port": 8080},
+	})
+	rev, err := ForTenant(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *calls != 3 {
+		t.Errorf("calls = %d, want a reload after Swap", *calls)
+	}
+	if rev.Config().Server.Host != "127.0.0.1" || rev.Generation() != Snapshot().Generation() {
+		t.Errorf("overlay is built on a stale base: %+v, generation %d", rev.Config().Server, rev.Generation())
+	}
+}
+
+func TestDirTenantProviderRejectsPaths(t *testing.T) {
+	useTenantDir(t, nil)
+
+	for _, tenant := range []string{"../secrets", "a/b", `a\b`, ".hidden"} {
+		if _, err := ForTenant(WithTenant(context.Background(), tenant)); err == nil {
+			t.Errorf("ForTenant(%q) = nil error, want an invalid tenant id", tenant)
+		}
+	}
+}
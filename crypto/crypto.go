@@ -0,0 +1,342 @@
+// Package crypto encrypts application data with AES-256-GCM envelope
+// encryption: every value is encrypted with a data key, which is stored
+// next to it wrapped by a key-encryption key of a KeyProvider
+package crypto
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	keySize = 32
+	// version of the envelope format
+	version byte = 1
+	// stringPrefix marks encrypted strings, so they are not encrypted twice
+	stringPrefix = "enc:v1:"
+)
+
+// ErrDecrypt is returned for tampered, truncated or foreign ciphertexts
+var ErrDecrypt = errors.New("crypto: message authentication failed")
+
+// Config holds encryptor configuration
+type Config struct {
+	// DataKeyTTL is how long one data key encrypts new values, 0 creates a
+	// key per value. Reuse saves a KMS call per value
+	DataKeyTTL time.Duration
+	// CacheSize bounds unwrapped data keys kept for decryption, 0 disables
+	// the cache
+	CacheSize int
+}
+
+// DefaultConfig returns default encryptor config
+func DefaultConfig() Config {
+	return Config{
+		DataKeyTTL: 5 * time.Minute,
+		CacheSize:  1000,
+	}
+}
+
+// Encryptor encrypts and decrypts values
+type Encryptor struct {
+	provider KeyProvider
+	config   Config
+
+	mu      sync.Mutex
+	current *dataKey
+	cache   map[string][]byte // keyID + wrapped key -> data key
+}
+
+// dataKey is a data key used for encryption
+type dataKey struct {
+	plain   []byte
+	keyID   string
+	wrapped []byte
+	expires time.Time
+}
+
+// New creates an encryptor
+func New(provider KeyProvider, config Config) *Encryptor {
+	return &Encryptor{
+		provider: provider,
+		config:   config,
+		cache:    make(map[string][]byte),
+	}
+}
+
+// Encrypt encrypts plaintext, aad is authenticated but not encrypted, the
+// same aad is required to decrypt. Envelope format:
+//
+//	version | len(keyID) | keyID | len(wrapped) uint16 | wrapped | nonce | ciphertext
+func (e *Encryptor) Encrypt(ctx context.Context, plaintext, aad []byte) ([]byte, error) {
+	key, err := e.dataKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	sealed, err := seal(key.plain, plaintext, aad)
+	if err != nil {
+		return nil, err
+	}
+	return envelope(key.keyID, key.wrapped, sealed), nil
+}
+
+// Decrypt decrypts a value encrypted by Encrypt with any key of the
+// provider. A substituted wrapped key unwraps to another data key and
+// fails authentication like tampered data
+func (e *Encryptor) Decrypt(ctx context.Context, ciphertext, aad []byte) ([]byte, error) {
+	keyID, wrapped, sealed, err := parseEnvelope(ciphertext)
+	if err != nil {
+		return nil, err
+	}
+
+	plain, err := e.unwrap(ctx, keyID, wrapped)
+	if err != nil {
+		return nil, err
+	}
+	return open(plain, sealed, aad)
+}
+
+// EncryptString encrypts s into a printable "enc:v1:" string for text columns
+func (e *Encryptor) EncryptString(ctx context.Context, s string, aad []byte) (string, error) {
+	ciphertext, err := e.Encrypt(ctx, []byte(s), aad)
+	if err != nil {
+		return "", err
+	}
+	return stringPrefix + base64.RawURLEncoding.EncodeToString(ciphertext), nil
+}
+
+// DecryptString decrypts a string encrypted by EncryptString
+func (e *Encryptor) DecryptString(ctx context.Context, s string, aad []byte) (string, error) {
+	encoded, ok := strings.CutPrefix(s, stringPrefix)
+	if !ok {
+		return "", fmt.Errorf("crypto: value is not encrypted")
+	}
+	ciphertext, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", ErrDecrypt
+	}
+
+	plaintext, err := e.Decrypt(ctx, ciphertext, aad)
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+// IsEncrypted reports whether s was produced by EncryptString
+func IsEncrypted(s string) bool {
+	return strings.HasPrefix(s, stringPrefix)
+}
+
+// KeyID returns ID of the KEK that wrapped the data key of ciphertext,
+// e.g. to find values to rewrap after rotation
+func KeyID(ciphertext []byte) (string, error) {
+	keyID, _, _, err := parseEnvelope(ciphertext)
+	return keyID, err
+}
+
+// Rewrap wraps the data key of ciphertext with the current primary KEK.
+// The data is not decrypted, so rotation needs neither aad nor a schema
+// aware migration. Ciphertexts already under the primary KEK are returned
+// as is
+func (e *Encryptor) Rewrap(ctx context.Context, ciphertext []byte) ([]byte, error) {
+	keyID, wrapped, sealed, err := parseEnvelope(ciphertext)
+	if err != nil {
+		return nil, err
+	}
+
+	plain, err := e.unwrap(ctx, keyID, wrapped)
+	if err != nil {
+		return nil, err
+	}
+	newKeyID, newWrapped, err := e.provider.WrapKey(ctx, plain)
+	if err != nil {
+		return nil, err
+	}
+	if newKeyID == keyID {
+		return ciphertext, nil
+	}
+	if err := checkWrapped(newKeyID, newWrapped); err != nil {
+		return nil, err
+	}
+	return envelope(newKeyID, newWrapped, sealed), nil
+}
+
+// RewrapString is Rewrap for strings encrypted by EncryptString
+func (e *Encryptor) RewrapString(ctx context.Context, s string) (string, error) {
+	encoded, ok := strings.CutPrefix(s, stringPrefix)
+	if !ok {
+		return "", fmt.Errorf("crypto: value is not encrypted")
+	}
+	ciphertext, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", ErrDecrypt
+	}
+
+	rewrapped, err := e.Rewrap(ctx, ciphertext)
+	if err != nil {
+		return "", err
+	}
+	return stringPrefix + base64.RawURLEncoding.EncodeToString(rewrapped), nil
+}
+
+// dataKey returns the data key for new values, creating it when expired
+func (e *Encryptor) dataKey(ctx context.Context) (*dataKey, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.current != nil && time.Now().Before(e.current.expires) && !e.rotated(e.current) {
+		return e.current, nil
+	}
+
+	plain := make([]byte, keySize)
+	if _, err := rand.Read(plain); err != nil {
+		return nil, fmt.Errorf("failed to generate data key: %w", err)
+	}
+	keyID, wrapped, err := e.provider.WrapKey(ctx, plain)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkWrapped(keyID, wrapped); err != nil {
+		return nil, err
+	}
+
+	key := &dataKey{plain: plain, keyID: keyID, wrapped: wrapped}
+	if e.config.DataKeyTTL > 0 {
+		key.expires = time.Now().Add(e.config.DataKeyTTL)
+		e.current = key
+	}
+	return key, nil
+}
+
+// rotated reports whether the provider primary key changed since key was
+// wrapped, so values are not encrypted under a retired KEK until DataKeyTTL
+func (e *Encryptor) rotated(key *dataKey) bool {
+	p, ok := e.provider.(interface{ Primary() string })
+	return ok && p.Primary() != key.keyID
+}
+
+// unwrap returns the data key of an envelope, cached by its wrapped form
+func (e *Encryptor) unwrap(ctx context.Context, keyID string, wrapped []byte) ([]byte, error) {
+	cacheKey := keyID + ":" + string(wrapped)
+	if e.config.CacheSize > 0 {
+		e.mu.Lock()
+		plain, ok := e.cache[cacheKey]
+		e.mu.Unlock()
+		if ok {
+			return plain, nil
+		}
+	}
+
+	plain, err := e.provider.UnwrapKey(ctx, keyID, wrapped)
+	if err != nil {
+		return nil, err
+	}
+
+	if e.config.CacheSize > 0 {
+		e.mu.Lock()
+		// Cleared when full, the working set refills it
+		if len(e.cache) >= e.config.CacheSize {
+			e.cache = make(map[string][]byte)
+		}
+		e.cache[cacheKey] = plain
+		e.mu.Unlock()
+	}
+	return plain, nil
+}
+
+// checkWrapped checks that a wrapped key fits the envelope header
+func checkWrapped(keyID string, wrapped []byte) error {
+	if len(keyID) > 255 || len(wrapped) > 65535 {
+		return fmt.Errorf("crypto: key id or wrapped data key is too long")
+	}
+	return nil
+}
+
+func envelope(keyID string, wrapped, sealed []byte) []byte {
+	var buf bytes.Buffer
+	buf.Grow(4 + len(keyID) + len(wrapped) + len(sealed))
+	buf.WriteByte(version)
+	buf.WriteByte(byte(len(keyID)))
+	buf.WriteString(keyID)
+	var size [2]byte
+	binary.BigEndian.PutUint16(size[:], uint16(len(wrapped)))
+	buf.Write(size[:])
+	buf.Write(wrapped)
+	buf.Write(sealed)
+	return buf.Bytes()
+}
+
+// parseEnvelope splits an envelope into key ID, wrapped data key and sealed data
+func parseEnvelope(ciphertext []byte) (keyID string, wrapped, sealed []byte, err error) {
+	if len(ciphertext) < 2 || ciphertext[0] != version {
+		return "", nil, nil, ErrDecrypt
+	}
+	rest := ciphertext[1:]
+
+	idLen := int(rest[0])
+	rest = rest[1:]
+	if len(rest) < idLen+2 {
+		return "", nil, nil, ErrDecrypt
+	}
+	keyID = string(rest[:idLen])
+	rest = rest[idLen:]
+
+	wrappedLen := int(binary.BigEndian.Uint16(rest))
+	rest = rest[2:]
+	if len(rest) < wrappedLen {
+		return "", nil, nil, ErrDecrypt
+	}
+	return keyID, rest[:wrappedLen], rest[wrappedLen:], nil
+}
+
+// seal encrypts with AES-256-GCM, the random nonce is prepended
+func seal(key, plaintext, aad []byte) ([]byte, error) {
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	return aead.Seal(nonce, nonce, plaintext, aad), nil
+}
+
+// open decrypts a value sealed by seal
+func open(key, sealed, aad []byte) ([]byte, error) {
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < aead.NonceSize() {
+		return nil, ErrDecrypt
+	}
+
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, aad)
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+	return plaintext, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,297 @@
+package crypto
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func testKey(b byte) []byte {
+	return bytes.Repeat([]byte{b}, keySize)
+}
+
+func newTestEncryptor(t *testing.T, config Config) (*Encryptor, *Keyring) {
+	t.Helper()
+	keyring, err := NewKeyring("k1", map[string][]byte{"k1": testKey(1)})
+	if err != nil {
+		t.Fatalf("NewKeyring() error = %v", err)
+	}
+	return New(keyring, config), keyring
+}
+
+// countingProvider counts UnwrapKey calls of the wrapped provider
+type countingProvider struct {
+	KeyProvider
+	unwraps int
+}
+
+func (p *countingProvider) UnwrapKey(ctx context.Context, keyID string, wrapped []byte) ([]byte, error) {
+	p.unwraps++
+	return p.KeyProvider.UnwrapKey(ctx, keyID, wrapped)
+}
+
+func TestEncryptDecryptRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	enc, _ := newTestEncryptor(t, DefaultConfig())
+
+	for _, plaintext := range [][]byte{[]byte("4111 1111 1111 1111"), {}, bytes.Repeat([]byte("x"), 1<<16)} {
+		ciphertext, err := enc.Encrypt(ctx, plaintext, []byte("cards.number"))
+		if err != nil {
+			t.Fatalf("Encrypt() error = %v", err)
+		}
+		if len(plaintext) > 0 && bytes.Contains(ciphertext, plaintext) {
+			t.Error("ciphertext contains the plaintext")
+		}
+
+		got, err := enc.Decrypt(ctx, ciphertext, []byte("cards.number"))
+		if err != nil {
+			t.Fatalf("Decrypt() error = %v", err)
+		}
+		if !bytes.Equal(got, plaintext) {
+			t.Errorf("Decrypt() = %q, want %q", got, plaintext)
+		}
+	}
+
+	s, err := enc.EncryptString(ctx, "alice@example.com", nil)
+	if err != nil || !IsEncrypted(s) {
+		t.Fatalf("EncryptString() = %q, %v", s, err)
+	}
+	if got, err := enc.DecryptString(ctx, s, nil); err != nil || got != "alice@example.com" {
+		t.Errorf("DecryptString() = %q, %v", got, err)
+	}
+	if _, err := enc.DecryptString(ctx, "alice@example.com", nil); err == nil {
+		t.Error("DecryptString() of a plaintext error = nil")
+	}
+}
+
+func TestDecryptAADMismatch(t *testing.T) {
+	ctx := context.Background()
+	enc, _ := newTestEncryptor(t, DefaultConfig())
+
+	ciphertext, _ := enc.Encrypt(ctx, []byte("secret"), []byte("users.email"))
+	if _, err := enc.Decrypt(ctx, ciphertext, []byte("users.phone")); !errors.Is(err, ErrDecrypt) {
+		t.Errorf("Decrypt() with another aad error = %v, want ErrDecrypt", err)
+	}
+	if _, err := enc.Decrypt(ctx, ciphertext, nil); !errors.Is(err, ErrDecrypt) {
+		t.Errorf("Decrypt() without aad error = %v, want ErrDecrypt", err)
+	}
+}
+
+func TestDecryptTamperedAndTruncated(t *testing.T) {
+	ctx := context.Background()
+	enc, _ := newTestEncryptor(t, Config{})
+
+	ciphertext, _ := enc.Encrypt(ctx, []byte("secret value"), nil)
+	keyID, wrapped, _, _ := parseEnvelope(ciphertext)
+	// version, keyID length, keyID, wrapped length, wrapped, then sealed data
+	sealedAt := 2 + len(keyID) + 2 + len(wrapped)
+
+	flip := func(i int) []byte {
+		c := append([]byte(nil), ciphertext...)
+		c[i] ^= 0x01
+		return c
+	}
+	other, _ := enc.Encrypt(ctx, []byte("secret value"), nil)
+	_, otherWrapped, _, _ := parseEnvelope(other)
+	swapped := envelope(keyID, otherWrapped, ciphertext[sealedAt:])
+
+	tests := []struct {
+		name       string
+		ciphertext []byte
+	}{
+		{"empty", nil},
+		{"version", flip(0)},
+		{"wrapped key", flip(2 + len(keyID) + 2)},
+		{"nonce", flip(sealedAt)},
+		{"data", flip(len(ciphertext) - 20)},
+		{"tag", flip(len(ciphertext) - 1)},
+		{"truncated header", ciphertext[:3]},
+		{"truncated wrapped key", ciphertext[:sealedAt-1]},
+		{"truncated nonce", ciphertext[:sealedAt+4]},
+		{"truncated tag", ciphertext[:len(ciphertext)-1]},
+		{"swapped data key", swapped},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := enc.Decrypt(ctx, tt.ciphertext, nil); !errors.Is(err, ErrDecrypt) {
+				t.Errorf("Decrypt() error = %v, want ErrDecrypt", err)
+			}
+		})
+	}
+
+	s, _ := enc.EncryptString(ctx, "value", nil)
+	if _, err := enc.DecryptString(ctx, s+"!", nil); !errors.Is(err, ErrDecrypt) {
+		t.Errorf("DecryptString() of invalid base64 error = %v, want ErrDecrypt", err)
+	}
+}
+
+func TestDecryptUnknownKey(t *testing.T) {
+	ctx := context.Background()
+	enc, _ := newTestEncryptor(t, Config{})
+	foreignKeyring, _ := NewKeyring("k9", map[string][]byte{"k9": testKey(9)})
+	foreign := New(foreignKeyring, Config{})
+
+	ciphertext, _ := foreign.Encrypt(ctx, []byte("secret"), nil)
+	if _, err := enc.Decrypt(ctx, ciphertext, nil); err == nil || !strings.Contains(err.Error(), `"k9" is not in the keyring`) {
+		t.Errorf("Decrypt() error = %v, want unknown key", err)
+	}
+}
+
+func TestRotateThenRewrap(t *testing.T) {
+	ctx := context.Background()
+	enc, keyring := newTestEncryptor(t, DefaultConfig())
+
+	old, _ := enc.EncryptString(ctx, "alice@example.com", []byte("users.email"))
+
+	if err := keyring.Rotate("k2", testKey(2)); err != nil {
+		t.Fatalf("Rotate() error = %v", err)
+	}
+	if err := keyring.Rotate("k1", testKey(3)); err == nil {
+		t.Error("Rotate() to an existing id error = nil")
+	}
+
+	// New values use the new primary before DataKeyTTL passes
+	fresh, _ := enc.Encrypt(ctx, []byte("bob"), nil)
+	if id, _ := KeyID(fresh); id != "k2" {
+		t.Errorf("new value key id = %s, want k2", id)
+	}
+
+	rewrapped, err := enc.RewrapString(ctx, old)
+	if err != nil {
+		t.Fatalf("RewrapString() error = %v", err)
+	}
+	raw, _ := base64.RawURLEncoding.DecodeString(strings.TrimPrefix(rewrapped, stringPrefix))
+	if id, _ := KeyID(raw); id != "k2" {
+		t.Errorf("rewrapped key id = %s, want k2", id)
+	}
+	if got, err := enc.DecryptString(ctx, rewrapped, []byte("users.email")); err != nil || got != "alice@example.com" {
+		t.Errorf("DecryptString() after rewrap = %q, %v", got, err)
+	}
+
+	// Already under the primary key
+	again, _ := enc.RewrapString(ctx, rewrapped)
+	if again != rewrapped {
+		t.Error("Rewrap() changed a value under the primary key")
+	}
+
+	// Retiring k1 keeps rewrapped values readable
+	retired, _ := NewKeyring("k2", map[string][]byte{"k2": testKey(2)})
+	if got, err := New(retired, Config{}).DecryptString(ctx, rewrapped, []byte("users.email")); err != nil || got != "alice@example.com" {
+		t.Errorf("DecryptString() without k1 = %q, %v", got, err)
+	}
+}
+
+func TestUnwrapCache(t *testing.T) {
+	ctx := context.Background()
+	keyring, _ := NewKeyring("k1", map[string][]byte{"k1": testKey(1)})
+	provider := &countingProvider{KeyProvider: keyring}
+	enc := New(provider, Config{CacheSize: 2})
+
+	// Without DataKeyTTL every value has its own data key
+	values := make([][]byte, 3)
+	for i := range values {
+		values[i], _ = enc.Encrypt(ctx, []byte("v"), nil)
+	}
+
+	for range 2 {
+		if _, err := enc.Decrypt(ctx, values[0], nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if provider.unwraps != 1 {
+		t.Errorf("unwraps = %d, want 1 with a cached data key", provider.unwraps)
+	}
+
+	// The third distinct key clears the full cache
+	_, _ = enc.Decrypt(ctx, values[1], nil)
+	_, _ = enc.Decrypt(ctx, values[2], nil)
+	if len(enc.cache) != 1 {
+		t.Errorf("cache size = %d, want 1 after clearing", len(enc.cache))
+	}
+	_, _ = enc.Decrypt(ctx, values[0], nil)
+	if provider.unwraps != 4 {
+		t.Errorf("unwraps = %d, want 4 after the cache was cleared", provider.unwraps)
+	}
+
+	uncached := &countingProvider{KeyProvider: keyring}
+	enc = New(uncached, Config{})
+	_, _ = enc.Decrypt(ctx, values[0], nil)
+	_, _ = enc.Decrypt(ctx, values[0], nil)
+	if uncached.unwraps != 2 {
+		t.Errorf("unwraps = %d, want 2 with the cache disabled", uncached.unwraps)
+	}
+}
+
+func TestKeyringFromEnv(t *testing.T) {
+	k1 := base64.StdEncoding.EncodeToString(testKey(1))
+	k2 := base64.StdEncoding.EncodeToString(testKey(2))
+
+	t.Setenv("TEST_KEYS", "2025-06:"+k2+", 2024-11:"+k1)
+	keyring, err := KeyringFromEnv("TEST_KEYS")
+	if err != nil {
+		t.Fatalf("KeyringFromEnv() error = %v", err)
+	}
+	if keyring.Primary() != "2025-06" {
+		t.Errorf("Primary() = %s, want the first key", keyring.Primary())
+	}
+	if _, err := keyring.UnwrapKey(context.Background(), "2024-11", nil); !errors.Is(err, ErrDecrypt) {
+		t.Errorf("second key is not loaded: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		value string
+		want  string
+	}{
+		{"unset", "", "not set"},
+		{"no id", ":" + k1, "expected id:base64"},
+		{"no separator", k1, "expected id:base64"},
+		{"bad base64", "k1:not base64!", "failed to decode"},
+		{"short key", "k1:" + base64.StdEncoding.EncodeToString([]byte("short")), "must be 32 bytes"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("TEST_KEYS", tt.value)
+			if _, err := KeyringFromEnv("TEST_KEYS"); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("KeyringFromEnv() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestEncryptFieldsRoundTrip(t *testing.T) {
+	type Contact struct {
+		Email string  `encrypt:"users.email"`
+		Phone *string `encrypt:"users.phone"`
+		Note  string
+	}
+	ctx := context.Background()
+	enc, _ := newTestEncryptor(t, DefaultConfig())
+
+	phone := "+7 700 000 00 00"
+	c := Contact{Email: "alice@example.com", Phone: &phone, Note: "visible"}
+	if err := enc.EncryptFields(ctx, &c); err != nil {
+		t.Fatalf("EncryptFields() error = %v", err)
+	}
+	if !IsEncrypted(c.Email) || !IsEncrypted(*c.Phone) || c.Note != "visible" {
+		t.Fatalf("EncryptFields() = %+v", c)
+	}
+
+	// A value copied to another column does not decrypt
+	swapped := c
+	swappedPhone := c.Email
+	swapped.Phone = &swappedPhone
+	if err := enc.DecryptFields(ctx, &swapped); !errors.Is(err, ErrDecrypt) {
+		t.Errorf("DecryptFields() of a moved value error = %v, want ErrDecrypt", err)
+	}
+
+	if err := enc.DecryptFields(ctx, &c); err != nil {
+		t.Fatalf("DecryptFields() error = %v", err)
+	}
+	if c.Email != "alice@example.com" || *c.Phone != phone {
+		t.Errorf("DecryptFields() = %+v", c)
+	}
+}
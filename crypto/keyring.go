@@ -0,0 +1,174 @@
+package crypto
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// KeyProvider encrypts data keys with key-encryption keys (KEK), which
+// never leave the provider. Keyring keeps them in memory, KMSProvider in a KMS
+type KeyProvider interface {
+	// WrapKey encrypts a data key with the primary KEK and returns its ID
+	WrapKey(ctx context.Context, dataKey []byte) (keyID string, wrapped []byte, err error)
+	// UnwrapKey decrypts a data key wrapped by the KEK with keyID
+	UnwrapKey(ctx context.Context, keyID string, wrapped []byte) ([]byte, error)
+}
+
+// Keyring holds KEKs by ID. The primary one wraps new data keys, the rest
+// only unwrap data keys of existing values until they are rewrapped
+type Keyring struct {
+	mu      sync.RWMutex
+	keys    map[string][]byte
+	primary string
+}
+
+var _ KeyProvider = (*Keyring)(nil)
+
+// NewKeyring creates a keyring, keys are 32 bytes for AES-256
+func NewKeyring(primary string, keys map[string][]byte) (*Keyring, error) {
+	k := &Keyring{keys: make(map[string][]byte, len(keys))}
+	for id, key := range keys {
+		if err := k.add(id, key); err != nil {
+			return nil, err
+		}
+	}
+	if _, ok := k.keys[primary]; !ok {
+		return nil, fmt.Errorf("primary key %q is not in the keyring", primary)
+	}
+	k.primary = primary
+	return k, nil
+}
+
+// KeyringFromEnv reads keys from the env variable as comma separated
+// id:base64 pairs, the first one is primary:
+//
+//	APP_ENCRYPTION_KEYS=2025-06:q3v...=,2024-11:Zm9...=
+func KeyringFromEnv(name string) (*Keyring, error) {
+	value := os.Getenv(name)
+	if value == "" {
+		return nil, fmt.Errorf("encryption keys are not set in %s", name)
+	}
+
+	var primary string
+	keys := make(map[string][]byte)
+	for _, pair := range strings.Split(value, ",") {
+		id, encoded, ok := strings.Cut(strings.TrimSpace(pair), ":")
+		if !ok || id == "" {
+			return nil, fmt.Errorf("invalid encryption key in %s, expected id:base64", name)
+		}
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode encryption key %q: %w", id, err)
+		}
+		if primary == "" {
+			primary = id
+		}
+		keys[id] = key
+	}
+	return NewKeyring(primary, keys)
+}
+
+// Rotate adds key as the new primary, previous keys keep unwrapping
+func (k *Keyring) Rotate(id string, key []byte) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if _, ok := k.keys[id]; ok {
+		return fmt.Errorf("encryption key %q is already in the keyring", id)
+	}
+	if err := k.add(id, key); err != nil {
+		return err
+	}
+	k.primary = id
+	return nil
+}
+
+// Primary returns ID of the primary key
+func (k *Keyring) Primary() string {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return k.primary
+}
+
+// WrapKey implements KeyProvider
+func (k *Keyring) WrapKey(_ context.Context, dataKey []byte) (string, []byte, error) {
+	k.mu.RLock()
+	id, kek := k.primary, k.keys[k.primary]
+	k.mu.RUnlock()
+
+	wrapped, err := seal(kek, dataKey, []byte(id))
+	if err != nil {
+		return "", nil, err
+	}
+	return id, wrapped, nil
+}
+
+// UnwrapKey implements KeyProvider
+func (k *Keyring) UnwrapKey(_ context.Context, keyID string, wrapped []byte) ([]byte, error) {
+	k.mu.RLock()
+	kek, ok := k.keys[keyID]
+	k.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("encryption key %q is not in the keyring", keyID)
+	}
+	return open(kek, wrapped, []byte(keyID))
+}
+
+func (k *Keyring) add(id string, key []byte) error {
+	if id == "" || strings.ContainsAny(id, ":,") {
+		return fmt.Errorf("invalid encryption key id %q", id)
+	}
+	if len(key) != keySize {
+		return fmt.Errorf("encryption key %q must be %d bytes, got %d", id, keySize, len(key))
+	}
+	k.keys[id] = append([]byte(nil), key...)
+	return nil
+}
+
+// KMS is a key management service client, e.g. an adapter of AWS KMS,
+// GCP Cloud KMS or Vault transit
+type KMS interface {
+	Encrypt(ctx context.Context, keyID string, plaintext []byte) ([]byte, error)
+	Decrypt(ctx context.Context, keyID string, ciphertext []byte) ([]byte, error)
+}
+
+// KMSProvider wraps data keys with a KMS key. Rotation is changing keyID,
+// the KMS keeps old keys for unwrapping
+type KMSProvider struct {
+	kms   KMS
+	keyID string
+}
+
+var _ KeyProvider = (*KMSProvider)(nil)
+
+// NewKMSProvider creates KeyProvider backed by a KMS key
+func NewKMSProvider(kms KMS, keyID string) *KMSProvider {
+	return &KMSProvider{kms: kms, keyID: keyID}
+}
+
+// Primary returns ID of the KMS key wrapping new data keys
+func (p *KMSProvider) Primary() string {
+	return p.keyID
+}
+
+// WrapKey implements KeyProvider
+func (p *KMSProvider) WrapKey(ctx context.Context, dataKey []byte) (string, []byte, error) {
+	wrapped, err := p.kms.Encrypt(ctx, p.keyID, dataKey)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to wrap data key: %w", err)
+	}
+	return p.keyID, wrapped, nil
+}
+
+// UnwrapKey implements KeyProvider
+func (p *KMSProvider) UnwrapKey(ctx context.Context, keyID string, wrapped []byte) ([]byte, error) {
+	dataKey, err := p.kms.Decrypt(ctx, keyID, wrapped)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap data key: %w", err)
+	}
+	return dataKey, nil
+}
@@ -0,0 +1,84 @@
+package errors
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"runtime/debug"
+	"sync/atomic"
+)
+
+// PanicError is a recovered panic, the cause of AppError built by Recover
+type PanicError struct {
+	Value interface{}
+	Stack []byte
+}
+
+// Error implements error interface
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// Unwrap returns the panic value when it is an error
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// PanicHook is called for every panic recovered by Recover and SafeGo
+type PanicHook func(err *AppError, panicErr *PanicError)
+
+var panicHook atomic.Pointer[PanicHook]
+
+// SetPanicHook sets hook reporting recovered panics, the application sets
+// it to the logger. Panics are written to stderr until then
+func SetPanicHook(hook PanicHook) {
+	if hook == nil {
+		panicHook.Store(nil)
+		return
+	}
+	panicHook.Store(&hook)
+}
+
+// Recover converts a panic into internal AppError stored in *errp, the panic
+// value and stack are kept in its PanicError cause, so they are logged but
+// never sent to clients. Must be deferred directly:
+//
+//	func (uc *Usecase) Execute(ctx context.Context) (err error) {
+//		defer errors.Recover(&err)
+//		...
+//	}
+func Recover(errp *error) {
+	r := recover()
+	if r == nil {
+		return
+	}
+
+	appErr := fromPanic(r)
+	if errp != nil {
+		*errp = appErr
+	}
+}
+
+// SafeGo runs fn in a goroutine reporting its panic instead of crashing
+// the process, the panic is tracked in error stats
+func SafeGo(fn func()) {
+	go func() {
+		var err error
+		defer func() { Track(err) }()
+		defer Recover(&err)
+		fn()
+	}()
+}
+
+func fromPanic(r interface{}) *AppError {
+	panicErr := &PanicError{Value: r, Stack: debug.Stack()}
+	appErr := Wrap(panicErr, "internal_error", "Internal server error", http.StatusInternalServerError)
+
+	if hook := panicHook.Load(); hook != nil {
+		(*hook)(appErr, panicErr)
+	} else {
+		fmt.Fprintf(os.Stderr, "%v\n%s", panicErr, panicErr.Stack)
+	}
+	return appErr
+}
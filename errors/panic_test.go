@@ -0,0 +1,103 @@
+package errors
+
+import (
+	stderrors "errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// setTestPanicHook records panics for the test
+func setTestPanicHook(t *testing.T) chan *PanicError {
+	t.Helper()
+	recovered := make(chan *PanicError, 1)
+	SetPanicHook(func(err *AppError, panicErr *PanicError) { recovered <- panicErr })
+	t.Cleanup(func() { SetPanicHook(nil) })
+	return recovered
+}
+
+func TestRecoverConvertsPanic(t *testing.T) {
+	recovered := setTestPanicHook(t)
+
+	run := func() (err error) {
+		defer Recover(&err)
+		var m map[string]int
+		m["boom"]++
+		return nil
+	}
+	err := run()
+
+	appErr := GetAppError(err)
+	if appErr.Code != "internal_error" || appErr.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("error = %+v, want internal_error", appErr)
+	}
+	// The message sent to clients does not leak the panic
+	if appErr.Message != "Internal server error" {
+		t.Errorf("message = %q", appErr.Message)
+	}
+
+	var panicErr *PanicError
+	if !stderrors.As(err, &panicErr) {
+		t.Fatalf("error %v does not wrap *PanicError", err)
+	}
+	if !strings.Contains(panicErr.Error(), "assignment to entry in nil map") {
+		t.Errorf("panic error = %q", panicErr.Error())
+	}
+	if !strings.Contains(string(panicErr.Stack), "TestRecoverConvertsPanic") {
+		t.Errorf("stack does not contain the panicking function:\n%s", panicErr.Stack)
+	}
+	if got := <-recovered; got != panicErr {
+		t.Error("hook received another panic error")
+	}
+}
+
+func TestRecoverKeepsErrorValues(t *testing.T) {
+	setTestPanicHook(t)
+
+	run := func() (err error) {
+		defer Recover(&err)
+		panic(io.ErrUnexpectedEOF)
+	}
+	if err := run(); !stderrors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("error = %v, want the panic value in the chain", err)
+	}
+
+	noPanic := func() (err error) {
+		defer Recover(&err)
+		return io.EOF
+	}
+	if err := noPanic(); err != io.EOF {
+		t.Errorf("Recover() changed the error without a panic: %v", err)
+	}
+}
+
+func TestSafeGoTracksPanic(t *testing.T) {
+	recovered := setTestPanicHook(t)
+	stats := NewStats()
+	SetStats(stats)
+	t.Cleanup(func() { SetStats(nil) })
+
+	tracked := make(chan string, 1)
+	stats.OnRecord(func(err *AppError) { tracked <- err.Code })
+
+	SafeGo(func() { panic("worker failed") })
+
+	select {
+	case panicErr := <-recovered:
+		if panicErr.Value != "worker failed" {
+			t.Errorf("panic value = %v", panicErr.Value)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("panic of SafeGo not recovered")
+	}
+	select {
+	case code := <-tracked:
+		if code != "internal_error" {
+			t.Errorf("tracked code = %s", code)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("panic of SafeGo not tracked")
+	}
+}
@@ -0,0 +1,143 @@
+package errors
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// maxStatsCodes bounds tracked codes, the rest is counted as otherCode
+	maxStatsCodes = 500
+	otherCode     = "_other"
+	// maxSampleLength bounds stored sample messages
+	maxSampleLength = 256
+)
+
+// CodeStats summarizes occurrences of an error code
+type CodeStats struct {
+	Code       string    `json:"code"`
+	StatusCode int       `json:"status_code"`
+	Count      uint64    `json:"count"`
+	FirstSeen  time.Time `json:"first_seen"`
+	LastSeen   time.Time `json:"last_seen"`
+	// Sample is the message of the last occurrence. The cause chain is
+	// left out, it may carry SQL, hostnames or user input
+	Sample string `json:"sample"`
+}
+
+// Stats collects AppError occurrences by code in process
+type Stats struct {
+	mu        sync.Mutex
+	codes     map[string]*CodeStats
+	startedAt time.Time
+	observers []func(*AppError)
+}
+
+// NewStats creates an empty collector
+func NewStats() *Stats {
+	return &Stats{
+		codes:     make(map[string]*CodeStats),
+		startedAt: time.Now(),
+	}
+}
+
+// OnRecord registers fn called for every recorded error, e.g. to count
+// errors in metrics
+func (s *Stats) OnRecord(fn func(*AppError)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.observers = append(s.observers, fn)
+}
+
+// Record counts err by its AppError code, plain errors are internal_error
+func (s *Stats) Record(err error) {
+	if err == nil {
+		return
+	}
+	appErr := GetAppError(err)
+	now := time.Now()
+
+	s.mu.Lock()
+	code := appErr.Code
+	stats, ok := s.codes[code]
+	if !ok && len(s.codes) >= maxStatsCodes {
+		code = otherCode
+		stats, ok = s.codes[code]
+	}
+	if !ok {
+		stats = &CodeStats{Code: code, FirstSeen: now}
+		s.codes[code] = stats
+	}
+	stats.Count++
+	stats.StatusCode = appErr.StatusCode
+	stats.LastSeen = now
+	stats.Sample = truncate(appErr.Message, maxSampleLength)
+	observers := s.observers
+	s.mu.Unlock()
+
+	for _, fn := range observers {
+		fn(appErr)
+	}
+}
+
+// Snapshot returns stats of all codes, the most frequent first
+func (s *Stats) Snapshot() []CodeStats {
+	s.mu.Lock()
+	result := make([]CodeStats, 0, len(s.codes))
+	for _, stats := range s.codes {
+		result = append(result, *stats)
+	}
+	s.mu.Unlock()
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+		return result[i].Code < result[j].Code
+	})
+	return result
+}
+
+// StartedAt returns when counting started, the collector creation or the
+// last Reset
+func (s *Stats) StartedAt() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.startedAt
+}
+
+// Reset clears collected stats
+func (s *Stats) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.codes = make(map[string]*CodeStats)
+	s.startedAt = time.Now()
+}
+
+var defaultStats atomic.Pointer[Stats]
+
+// SetStats sets collector used by Track, nil disables tracking
+func SetStats(s *Stats) {
+	defaultStats.Store(s)
+}
+
+// Track records err in the collector set by SetStats. Error responses and
+// recovered panics are tracked by the SDK
+func Track(err error) {
+	if s := defaultStats.Load(); s != nil {
+		s.Record(err)
+	}
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	// Cut on a rune boundary
+	for n > 0 && s[n]&0xC0 == 0x80 {
+		n--
+	}
+	return s[:n] + "…"
+}
@@ -0,0 +1,87 @@
+package errors
+
+import (
+	stderrors "errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestStatsRecord(t *testing.T) {
+	stats := NewStats()
+	var observed []string
+	stats.OnRecord(func(err *AppError) { observed = append(observed, err.Code) })
+
+	notFound := New("not_found", "Order not found", http.StatusNotFound)
+	stats.Record(notFound)
+	stats.Record(notFound)
+	stats.Record(stderrors.New("boom"))
+	stats.Record(nil)
+
+	snapshot := stats.Snapshot()
+	if len(snapshot) != 2 {
+		t.Fatalf("expected 2 codes, got %v", snapshot)
+	}
+	if snapshot[0].Code != "not_found" || snapshot[0].Count != 2 || snapshot[0].StatusCode != http.StatusNotFound {
+		t.Errorf("unexpected first entry %+v", snapshot[0])
+	}
+	if snapshot[0].FirstSeen.After(snapshot[0].LastSeen) {
+		t.Error("first seen after last seen")
+	}
+	if len(observed) != 3 {
+		t.Errorf("observers called %d times, want 3", len(observed))
+	}
+}
+
+func TestStatsSampleOmitsCause(t *testing.T) {
+	stats := NewStats()
+	cause := fmt.Errorf("pq: duplicate key value violates unique constraint \"users_email_key\" (email=a@b.kz)")
+	stats.Record(Wrap(cause, "conflict", "User already exists", http.StatusConflict))
+
+	sample := stats.Snapshot()[0].Sample
+	if sample != "User already exists" {
+		t.Errorf("sample = %q", sample)
+	}
+}
+
+func TestStatsBoundsCodes(t *testing.T) {
+	stats := NewStats()
+	for i := 0; i < maxStatsCodes+10; i++ {
+		stats.Record(New(fmt.Sprintf("code_%d", i), "x", http.StatusBadRequest))
+	}
+
+	snapshot := stats.Snapshot()
+	if len(snapshot) != maxStatsCodes+1 {
+		t.Fatalf("tracked %d codes, want %d", len(snapshot), maxStatsCodes+1)
+	}
+	for _, entry := range snapshot {
+		if entry.Code == otherCode && entry.Count != 10 {
+			t.Errorf("%s count = %d, want 10", otherCode, entry.Count)
+		}
+	}
+}
+
+func TestStatsResetAndTrack(t *testing.T) {
+	stats := NewStats()
+	SetStats(stats)
+	defer SetStats(nil)
+
+	Track(New("bad_request", "Bad", http.StatusBadRequest))
+	if len(stats.Snapshot()) != 1 {
+		t.Fatal("Track did not record into the default collector")
+	}
+
+	started := stats.StartedAt()
+	stats.Reset()
+	if len(stats.Snapshot()) != 0 || stats.StartedAt().Before(started) {
+		t.Error("Reset did not clear stats")
+	}
+}
+
+func TestTruncateRuneBoundary(t *testing.T) {
+	got := truncate(strings.Repeat("ж", 10), 5)
+	if got != "жж…" {
+		t.Errorf("truncate = %q", got)
+	}
+}
@@ -0,0 +1,269 @@
+// Package eventbus dispatches domain events in process. Usecases publish
+// events, other modules subscribe to them by type, without depending on
+// each other. Events are not persisted, use outbox and messaging when they
+// must survive a restart or reach other services
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+
+	apperrors "github.com/alimzhanovlr/sdk/errors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/alimzhanovlr/sdk/eventbus"
+
+// ErrClosed is returned by Publish after Close
+var ErrClosed = errors.New("eventbus: bus is closed")
+
+// ErrorPolicy decides how Publish handles errors of sync handlers
+type ErrorPolicy int
+
+const (
+	// StopOnError returns the first error, later handlers are not called
+	StopOnError ErrorPolicy = iota
+	// ContinueOnError calls all handlers and returns their errors joined
+	ContinueOnError
+)
+
+// Config holds event bus configuration
+type Config struct {
+	ErrorPolicy ErrorPolicy
+	// AsyncWorkers limits concurrently running async handlers, Publish
+	// waits for a free worker when all are busy
+	AsyncWorkers int
+	// OnAsyncError is called with errors of async handlers, which have no
+	// caller to return them to
+	OnAsyncError func(ctx context.Context, event interface{}, handler string, err error)
+}
+
+// DefaultConfig returns default event bus config
+func DefaultConfig() Config {
+	return Config{
+		ErrorPolicy:  StopOnError,
+		AsyncWorkers: 16,
+	}
+}
+
+// Bus dispatches events to handlers subscribed to their type
+type Bus struct {
+	config Config
+	tracer trace.Tracer
+
+	mu       sync.RWMutex
+	handlers map[reflect.Type][]*subscription
+	nextID   uint64
+	closed   bool
+
+	workers chan struct{}
+	wg      sync.WaitGroup
+}
+
+// subscription is a handler of one event type
+type subscription struct {
+	id     uint64
+	name   string
+	async  bool
+	handle func(ctx context.Context, event interface{}) error
+}
+
+// New creates an event bus
+func New(config Config) *Bus {
+	if config.AsyncWorkers <= 0 {
+		config.AsyncWorkers = DefaultConfig().AsyncWorkers
+	}
+
+	return &Bus{
+		config:   config,
+		tracer:   otel.Tracer(instrumentationName),
+		handlers: make(map[reflect.Type][]*subscription),
+		workers:  make(chan struct{}, config.AsyncWorkers),
+	}
+}
+
+// SubscribeOption configures a subscription
+type SubscribeOption func(*subscription)
+
+// Async runs the handler in background after Publish returns. Its errors go
+// to Config.OnAsyncError, the context keeps values and the trace of the
+// publisher but not its cancellation
+func Async() SubscribeOption {
+	return func(s *subscription) {
+		s.async = true
+	}
+}
+
+// Named sets handler name used in spans and errors
+func Named(name string) SubscribeOption {
+	return func(s *subscription) {
+		s.name = name
+	}
+}
+
+// Subscribe registers handler of events of type T and returns a function
+// removing it. Sync handlers run in the publisher goroutine in order of
+// subscription, so they may take part in its transaction. T is matched
+// exactly, a handler of an interface type gets only events published as
+// that interface:
+//
+//	eventbus.Subscribe(bus, func(ctx context.Context, e OrderCreated) error {
+//		return uc.notifier.OrderCreated(ctx, e.OrderID)
+//	}, eventbus.Async(), eventbus.Named("notify.order_created"))
+func Subscribe[T any](b *Bus, handler func(ctx context.Context, event T) error, opts ...SubscribeOption) func() {
+	eventType := reflect.TypeFor[T]()
+
+	sub := &subscription{
+		handle: func(ctx context.Context, event interface{}) error {
+			return handler(ctx, event.(T))
+		},
+	}
+	for _, opt := range opts {
+		opt(sub)
+	}
+
+	b.mu.Lock()
+	b.nextID++
+	sub.id = b.nextID
+	if sub.name == "" {
+		sub.name = fmt.Sprintf("%s#%d", eventType, sub.id)
+	}
+	b.handlers[eventType] = append(b.handlers[eventType], sub)
+	b.mu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			b.unsubscribe(eventType, sub.id)
+		})
+	}
+}
+
+// Publish dispatches event to handlers subscribed to type T. It returns
+// errors of sync handlers according to Config.ErrorPolicy, panics are
+// recovered as internal errors
+func Publish[T any](ctx context.Context, b *Bus, event T) error {
+	eventType := reflect.TypeFor[T]()
+
+	b.mu.RLock()
+	if b.closed {
+		b.mu.RUnlock()
+		return ErrClosed
+	}
+	subs := b.handlers[eventType]
+	b.mu.RUnlock()
+
+	var errs []error
+	for _, sub := range subs {
+		if sub.async {
+			if err := b.dispatchAsync(ctx, eventType, sub, event); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := b.handle(ctx, eventType, sub, event); err != nil {
+			err = fmt.Errorf("event handler %s failed: %w", sub.name, err)
+			if b.config.ErrorPolicy == StopOnError {
+				return err
+			}
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Close waits for running async handlers, Publish fails with ErrClosed
+// afterwards
+func (b *Bus) Close(ctx context.Context) error {
+	b.mu.Lock()
+	b.closed = true
+	b.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		b.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("failed to wait for async event handlers: %w", ctx.Err())
+	}
+}
+
+func (b *Bus) dispatchAsync(ctx context.Context, eventType reflect.Type, sub *subscription, event interface{}) error {
+	select {
+	case b.workers <- struct{}{}:
+	case <-ctx.Done():
+		return fmt.Errorf("failed to dispatch event to %s: %w", sub.name, ctx.Err())
+	}
+
+	// Added under the lock, so Close never waits while handlers are added
+	b.mu.RLock()
+	if b.closed {
+		b.mu.RUnlock()
+		<-b.workers
+		return ErrClosed
+	}
+	b.wg.Add(1)
+	b.mu.RUnlock()
+
+	ctx = context.WithoutCancel(ctx)
+	go func() {
+		defer b.wg.Done()
+		defer func() { <-b.workers }()
+
+		if err := b.handle(ctx, eventType, sub, event); err != nil && b.config.OnAsyncError != nil {
+			b.config.OnAsyncError(ctx, event, sub.name, err)
+		}
+	}()
+	return nil
+}
+
+// handle calls the handler in its own span
+func (b *Bus) handle(ctx context.Context, eventType reflect.Type, sub *subscription, event interface{}) (err error) {
+	ctx, span := b.tracer.Start(ctx, "event "+eventType.Name(),
+		trace.WithAttributes(
+			attribute.String("event.type", eventType.String()),
+			attribute.String("event.handler", sub.name),
+			attribute.Bool("event.async", sub.async),
+		),
+	)
+	defer span.End()
+
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+	}()
+	defer apperrors.Recover(&err)
+
+	return sub.handle(ctx, event)
+}
+
+func (b *Bus) unsubscribe(eventType reflect.Type, id uint64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	subs := b.handlers[eventType]
+	for i, sub := range subs {
+		if sub.id == id {
+			// Copy, so Publish calls iterating the old slice are not affected
+			b.handlers[eventType] = append(subs[:i:i], subs[i+1:]...)
+			break
+		}
+	}
+	if len(b.handlers[eventType]) == 0 {
+		delete(b.handlers, eventType)
+	}
+}
@@ -0,0 +1,188 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type orderCreated struct {
+	OrderID string
+}
+
+type orderPaid struct {
+	OrderID string
+}
+
+var errNotify = errors.New("notifier down")
+
+func TestPublishCallsSyncHandlersInOrder(t *testing.T) {
+	bus := New(DefaultConfig())
+
+	var calls []string
+	Subscribe(bus, func(ctx context.Context, e orderCreated) error {
+		calls = append(calls, "first "+e.OrderID)
+		return nil
+	})
+	Subscribe(bus, func(ctx context.Context, e orderCreated) error {
+		calls = append(calls, "second "+e.OrderID)
+		return nil
+	})
+	Subscribe(bus, func(ctx context.Context, e orderPaid) error {
+		calls = append(calls, "paid "+e.OrderID)
+		return nil
+	})
+
+	if err := Publish(context.Background(), bus, orderCreated{OrderID: "42"}); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+	if strings.Join(calls, ", ") != "first 42, second 42" {
+		t.Errorf("calls = %q, want handlers of the event type in order", calls)
+	}
+
+	// A pointer is another type
+	if err := Publish(context.Background(), bus, &orderCreated{OrderID: "43"}); err != nil || len(calls) != 2 {
+		t.Errorf("Publish() of a pointer = %v, calls = %q", err, calls)
+	}
+}
+
+func TestPublishErrorPolicy(t *testing.T) {
+	tests := []struct {
+		name      string
+		policy    ErrorPolicy
+		wantCalls int
+	}{
+		{"stop on error", StopOnError, 1},
+		{"continue on error", ContinueOnError, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bus := New(Config{ErrorPolicy: tt.policy})
+			calls := 0
+			failing := func(ctx context.Context, e orderCreated) error {
+				calls++
+				return errNotify
+			}
+			Subscribe(bus, failing, Named("notify"))
+			Subscribe(bus, func(ctx context.Context, e orderCreated) error {
+				calls++
+				panic("handler bug")
+			})
+			Subscribe(bus, failing)
+
+			err := Publish(context.Background(), bus, orderCreated{})
+			if !errors.Is(err, errNotify) || !strings.Contains(err.Error(), "event handler notify failed") {
+				t.Errorf("Publish() error = %v, want the named handler error", err)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", calls, tt.wantCalls)
+			}
+			if tt.policy == ContinueOnError && !strings.Contains(err.Error(), "eventbus.orderCreated#2 failed") {
+				t.Errorf("Publish() error = %v, want the recovered panic", err)
+			}
+		})
+	}
+}
+
+func TestUnsubscribe(t *testing.T) {
+	bus := New(DefaultConfig())
+
+	calls := 0
+	unsubscribe := Subscribe(bus, func(ctx context.Context, e orderCreated) error {
+		calls++
+		return nil
+	})
+	_ = Publish(context.Background(), bus, orderCreated{})
+	unsubscribe()
+	unsubscribe()
+	_ = Publish(context.Background(), bus, orderCreated{})
+
+	if calls != 1 {
+		t.Errorf("calls = %d, want 1", calls)
+	}
+	if len(bus.handlers) != 0 {
+		t.Errorf("handlers = %v, want none", bus.handlers)
+	}
+}
+
+func TestAsyncHandlers(t *testing.T) {
+	type ctxKey struct{}
+	var (
+		mu     sync.Mutex
+		failed []string
+	)
+	bus := New(Config{
+		AsyncWorkers: 2,
+		OnAsyncError: func(ctx context.Context, event interface{}, handler string, err error) {
+			mu.Lock()
+			failed = append(failed, handler)
+			mu.Unlock()
+		},
+	})
+
+	release := make(chan struct{})
+	values := make(chan interface{}, 1)
+	Subscribe(bus, func(ctx context.Context, e orderCreated) error {
+		<-release
+		// The publisher context is cancelled by now
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		values <- ctx.Value(ctxKey{})
+		return errNotify
+	}, Async(), Named("slow"))
+
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), ctxKey{}, "request-1"))
+	if err := Publish(ctx, bus, orderCreated{}); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+	cancel()
+	close(release)
+
+	if err := bus.Close(context.Background()); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if v := <-values; v != "request-1" {
+		t.Errorf("context value = %v, want the publisher value", v)
+	}
+	if len(failed) != 1 || failed[0] != "slow" {
+		t.Errorf("async errors = %q, want the slow handler", failed)
+	}
+
+	if err := Publish(context.Background(), bus, orderCreated{}); !errors.Is(err, ErrClosed) {
+		t.Errorf("Publish() after Close() error = %v, want ErrClosed", err)
+	}
+}
+
+func TestAsyncWorkersLimit(t *testing.T) {
+	bus := New(Config{AsyncWorkers: 1})
+	release := make(chan struct{})
+	Subscribe(bus, func(ctx context.Context, e orderCreated) error {
+		<-release
+		return nil
+	}, Async())
+
+	if err := Publish(context.Background(), bus, orderCreated{}); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+
+	// The only worker is busy, Publish waits until its context is done
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := Publish(ctx, bus, orderCreated{}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Publish() with busy workers error = %v, want DeadlineExceeded", err)
+	}
+
+	closeCtx, cancelClose := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancelClose()
+	if err := bus.Close(closeCtx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Close() with running handlers error = %v", err)
+	}
+	close(release)
+	if err := bus.Close(context.Background()); err != nil {
+		t.Errorf("Close() error = %v", err)
+	}
+}
@@ -0,0 +1,312 @@
+// Package featureflag evaluates feature flags locally with targeting rules.
+// Flags are read from a file or set by the application, there is no remote
+// service on the request path
+package featureflag
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/spf13/viper"
+)
+
+// Well known attributes, set by middleware.FeatureFlagsMiddleware
+const (
+	AttributeUser   = "user"
+	AttributeTenant = "tenant"
+)
+
+// Operator compares an attribute with condition values
+type Operator string
+
+// Supported operators
+const (
+	OperatorIn     Operator = "in"
+	OperatorNotIn  Operator = "not_in"
+	OperatorPrefix Operator = "prefix"
+	OperatorSuffix Operator = "suffix"
+)
+
+// Evaluation reasons
+const (
+	ReasonNotFound = "not_found"
+	ReasonDisabled = "disabled"
+	ReasonRule     = "rule"
+	ReasonDefault  = "default"
+)
+
+// Attributes describe who the flag is evaluated for
+type Attributes map[string]string
+
+// Flag is a boolean feature flag
+type Flag struct {
+	Key         string `mapstructure:"key" json:"key"`
+	Description string `mapstructure:"description" json:"description,omitempty"`
+	// Enabled false turns the flag off for everyone, rules included
+	Enabled bool `mapstructure:"enabled" json:"enabled"`
+	// Default is the value when no rule matches
+	Default bool   `mapstructure:"default" json:"default"`
+	Rules   []Rule `mapstructure:"rules" json:"rules,omitempty"`
+}
+
+// Rule returns Value for attributes matching all conditions, the first
+// matching rule wins
+type Rule struct {
+	Name       string      `mapstructure:"name" json:"name"`
+	Conditions []Condition `mapstructure:"conditions" json:"conditions,omitempty"`
+	// Percentage limits the rule to a share of matching attributes, 0-100.
+	// Buckets are a stable hash of the flag key and BucketBy attribute, so
+	// a user keeps the value while the percentage grows. Nil means all
+	Percentage *float64 `mapstructure:"percentage" json:"percentage,omitempty"`
+	// BucketBy defaults to user, then tenant
+	BucketBy string `mapstructure:"bucket_by" json:"bucket_by,omitempty"`
+	Value    bool   `mapstructure:"value" json:"value"`
+}
+
+// Condition matches an attribute against values
+type Condition struct {
+	Attribute string   `mapstructure:"attribute" json:"attribute"`
+	Operator  Operator `mapstructure:"operator" json:"operator"`
+	Values    []string `mapstructure:"values" json:"values"`
+}
+
+// Evaluation is a flag value with the reason it was chosen
+type Evaluation struct {
+	Key    string `json:"key"`
+	Value  bool   `json:"value"`
+	Reason string `json:"reason"`
+	Rule   string `json:"rule,omitempty"`
+}
+
+// Engine holds flags and evaluates them
+type Engine struct {
+	flags atomic.Pointer[map[string]*Flag]
+}
+
+// New creates an engine with flags
+func New(flags ...Flag) (*Engine, error) {
+	e := &Engine{}
+	if err := e.Set(flags); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// LoadFile reads flags from a YAML or JSON file with a top-level flags list
+func LoadFile(path string) ([]Flag, error) {
+	v := viper.New()
+	v.SetConfigFile(path)
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("failed to read feature flags: %w", err)
+	}
+
+	var file struct {
+		Flags []Flag `mapstructure:"flags"`
+	}
+	if err := v.Unmarshal(&file); err != nil {
+		return nil, fmt.Errorf("failed to parse feature flags: %w", err)
+	}
+	return file.Flags, nil
+}
+
+// Set validates and replaces all flags, snapshots taken before keep the
+// previous flags
+func (e *Engine) Set(flags []Flag) error {
+	set := make(map[string]*Flag, len(flags))
+	for i := range flags {
+		flag := flags[i]
+		if err := flag.validate(); err != nil {
+			return err
+		}
+		if _, ok := set[flag.Key]; ok {
+			return fmt.Errorf("duplicate feature flag %q", flag.Key)
+		}
+		set[flag.Key] = &flag
+	}
+
+	e.flags.Store(&set)
+	return nil
+}
+
+// Flags returns flag definitions sorted by key
+func (e *Engine) Flags() []Flag {
+	set := *e.flags.Load()
+	flags := make([]Flag, 0, len(set))
+	for _, flag := range set {
+		flags = append(flags, *flag)
+	}
+	slices.SortFunc(flags, func(a, b Flag) int {
+		return strings.Compare(a.Key, b.Key)
+	})
+	return flags
+}
+
+// Evaluate evaluates a flag with current flags
+func (e *Engine) Evaluate(key string, attrs Attributes) Evaluation {
+	return evaluate(*e.flags.Load(), key, attrs)
+}
+
+// Snapshot fixes current flags and attrs, so a request sees the same value
+// of a flag however often it asks, even if flags change meanwhile
+func (e *Engine) Snapshot(attrs Attributes) *Snapshot {
+	return &Snapshot{
+		flags:   *e.flags.Load(),
+		attrs:   attrs,
+		results: make(map[string]Evaluation),
+	}
+}
+
+// Snapshot evaluates flags of one moment for one set of attributes
+type Snapshot struct {
+	flags map[string]*Flag
+	attrs Attributes
+
+	mu      sync.Mutex
+	results map[string]Evaluation
+}
+
+// Enabled returns the flag value, false for unknown flags
+func (s *Snapshot) Enabled(key string) bool {
+	return s.Evaluate(key).Value
+}
+
+// Evaluate returns the flag evaluation
+func (s *Snapshot) Evaluate(key string) Evaluation {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if result, ok := s.results[key]; ok {
+		return result
+	}
+	result := evaluate(s.flags, key, s.attrs)
+	s.results[key] = result
+	return result
+}
+
+// Evaluated returns evaluations made so far, e.g. for logs or span events
+func (s *Snapshot) Evaluated() []Evaluation {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	results := make([]Evaluation, 0, len(s.results))
+	for _, result := range s.results {
+		results = append(results, result)
+	}
+	slices.SortFunc(results, func(a, b Evaluation) int {
+		return strings.Compare(a.Key, b.Key)
+	})
+	return results
+}
+
+type snapshotKey struct{}
+
+// WithSnapshot returns context carrying snapshot
+func WithSnapshot(ctx context.Context, snapshot *Snapshot) context.Context {
+	return context.WithValue(ctx, snapshotKey{}, snapshot)
+}
+
+// FromContext returns snapshot stored by WithSnapshot
+func FromContext(ctx context.Context) (*Snapshot, bool) {
+	snapshot, ok := ctx.Value(snapshotKey{}).(*Snapshot)
+	return snapshot, ok
+}
+
+// Enabled returns the flag value of the context snapshot, false without one
+func Enabled(ctx context.Context, key string) bool {
+	snapshot, ok := FromContext(ctx)
+	return ok && snapshot.Enabled(key)
+}
+
+func evaluate(flags map[string]*Flag, key string, attrs Attributes) Evaluation {
+	flag, ok := flags[key]
+	if !ok {
+		return Evaluation{Key: key, Reason: ReasonNotFound}
+	}
+	if !flag.Enabled {
+		return Evaluation{Key: key, Reason: ReasonDisabled}
+	}
+
+	for _, rule := range flag.Rules {
+		if rule.matches(flag.Key, attrs) {
+			return Evaluation{Key: key, Value: rule.Value, Reason: ReasonRule, Rule: rule.Name}
+		}
+	}
+	return Evaluation{Key: key, Value: flag.Default, Reason: ReasonDefault}
+}
+
+func (r *Rule) matches(flagKey string, attrs Attributes) bool {
+	for _, cond := range r.Conditions {
+		if !cond.matches(attrs) {
+			return false
+		}
+	}
+	if r.Percentage == nil {
+		return true
+	}
+
+	bucketBy := r.BucketBy
+	if bucketBy == "" {
+		bucketBy = AttributeUser
+		if attrs[bucketBy] == "" {
+			bucketBy = AttributeTenant
+		}
+	}
+	value := attrs[bucketBy]
+	if value == "" {
+		// Nothing stable to bucket by, only a full rollout applies
+		return *r.Percentage >= 100
+	}
+
+	// Basis points keep fractional percents like 0.5
+	h := fnv.New64a()
+	h.Write([]byte(flagKey + ":" + value))
+	return float64(h.Sum64()%10000) < *r.Percentage*100
+}
+
+func (c *Condition) matches(attrs Attributes) bool {
+	value, ok := attrs[c.Attribute]
+
+	switch c.Operator {
+	case OperatorIn:
+		return ok && slices.Contains(c.Values, value)
+	case OperatorNotIn:
+		return !slices.Contains(c.Values, value)
+	case OperatorPrefix:
+		return ok && slices.ContainsFunc(c.Values, func(prefix string) bool {
+			return strings.HasPrefix(value, prefix)
+		})
+	case OperatorSuffix:
+		return ok && slices.ContainsFunc(c.Values, func(suffix string) bool {
+			return strings.HasSuffix(value, suffix)
+		})
+	default:
+		return false
+	}
+}
+
+func (f *Flag) validate() error {
+	if f.Key == "" {
+		return fmt.Errorf("feature flag key is required")
+	}
+	for _, rule := range f.Rules {
+		if rule.Percentage != nil && (*rule.Percentage < 0 || *rule.Percentage > 100) {
+			return fmt.Errorf("feature flag %q rule %q: percentage must be between 0 and 100", f.Key, rule.Name)
+		}
+		for _, cond := range rule.Conditions {
+			switch cond.Operator {
+			case OperatorIn, OperatorNotIn, OperatorPrefix, OperatorSuffix:
+			default:
+				return fmt.Errorf("feature flag %q rule %q: unsupported operator %q", f.Key, rule.Name, cond.Operator)
+			}
+			if cond.Attribute == "" {
+				return fmt.Errorf("feature flag %q rule %q: condition attribute is required", f.Key, rule.Name)
+			}
+		}
+	}
+	return nil
+}
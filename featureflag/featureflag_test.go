@@ -0,0 +1,212 @@
+package featureflag
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func percentage(p float64) *float64 {
+	return &p
+}
+
+func mustEngine(t *testing.T, flags ...Flag) *Engine {
+	t.Helper()
+	engine, err := New(flags...)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	return engine
+}
+
+func TestEvaluateReasons(t *testing.T) {
+	engine := mustEngine(t,
+		Flag{Key: "off", Enabled: false, Default: true},
+		Flag{Key: "default_on", Enabled: true, Default: true},
+		Flag{Key: "beta", Enabled: true, Rules: []Rule{{
+			Name:       "staff",
+			Conditions: []Condition{{Attribute: AttributeTenant, Operator: OperatorIn, Values: []string{"acme"}}},
+			Value:      true,
+		}}},
+	)
+
+	tests := []struct {
+		key   string
+		attrs Attributes
+		want  Evaluation
+	}{
+		{"missing", nil, Evaluation{Key: "missing", Reason: ReasonNotFound}},
+		{"off", nil, Evaluation{Key: "off", Reason: ReasonDisabled}},
+		{"default_on", nil, Evaluation{Key: "default_on", Value: true, Reason: ReasonDefault}},
+		{"beta", Attributes{AttributeTenant: "acme"}, Evaluation{Key: "beta", Value: true, Reason: ReasonRule, Rule: "staff"}},
+		{"beta", Attributes{AttributeTenant: "other"}, Evaluation{Key: "beta", Reason: ReasonDefault}},
+	}
+	for _, tt := range tests {
+		if got := engine.Evaluate(tt.key, tt.attrs); got != tt.want {
+			t.Errorf("Evaluate(%s, %v) = %+v, want %+v", tt.key, tt.attrs, got, tt.want)
+		}
+	}
+}
+
+func TestConditionOperators(t *testing.T) {
+	tests := []struct {
+		cond  Condition
+		attrs Attributes
+		want  bool
+	}{
+		{Condition{"user", OperatorIn, []string{"1", "2"}}, Attributes{"user": "2"}, true},
+		{Condition{"user", OperatorIn, []string{"1"}}, Attributes{}, false},
+		{Condition{"user", OperatorNotIn, []string{"1"}}, Attributes{"user": "2"}, true},
+		{Condition{"user", OperatorNotIn, []string{"1"}}, Attributes{}, true},
+		{Condition{"user", OperatorNotIn, []string{"1"}}, Attributes{"user": "1"}, false},
+		{Condition{"email", OperatorSuffix, []string{"@acme.kz"}}, Attributes{"email": "a@acme.kz"}, true},
+		{Condition{"email", OperatorSuffix, []string{"@acme.kz"}}, Attributes{}, false},
+		{Condition{"region", OperatorPrefix, []string{"kz-"}}, Attributes{"region": "kz-ala"}, true},
+		{Condition{"region", OperatorPrefix, []string{"kz-"}}, Attributes{"region": "ru-msk"}, false},
+		{Condition{"region", "regex", []string{".*"}}, Attributes{"region": "x"}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.cond.matches(tt.attrs); got != tt.want {
+			t.Errorf("%+v.matches(%v) = %v, want %v", tt.cond, tt.attrs, got, tt.want)
+		}
+	}
+}
+
+func TestPercentageBucketing(t *testing.T) {
+	rollout := func(p float64) *Engine {
+		return mustEngine(t, Flag{Key: "new_checkout", Enabled: true, Rules: []Rule{{
+			Name: "rollout", Percentage: percentage(p), Value: true,
+		}}})
+	}
+
+	// Stable: the same user gets the same value on every evaluation
+	engine := rollout(30)
+	first := engine.Evaluate("new_checkout", Attributes{AttributeUser: "42"}).Value
+	for i := 0; i < 10; i++ {
+		if engine.Evaluate("new_checkout", Attributes{AttributeUser: "42"}).Value != first {
+			t.Fatal("bucketing is not stable")
+		}
+	}
+
+	// Distribution and monotonic growth: users enabled at 30% stay enabled at 60%
+	enabled30, enabled60 := 0, 0
+	const users = 10000
+	grown := rollout(60)
+	for i := 0; i < users; i++ {
+		attrs := Attributes{AttributeUser: fmt.Sprint(i)}
+		at30 := engine.Evaluate("new_checkout", attrs).Value
+		at60 := grown.Evaluate("new_checkout", attrs).Value
+		if at30 {
+			enabled30++
+			if !at60 {
+				t.Fatalf("user %d lost the flag when the rollout grew", i)
+			}
+		}
+		if at60 {
+			enabled60++
+		}
+	}
+	if share := float64(enabled30) / users; math.Abs(share-0.3) > 0.03 {
+		t.Errorf("30%% rollout enabled %.3f of users", share)
+	}
+	if share := float64(enabled60) / users; math.Abs(share-0.6) > 0.03 {
+		t.Errorf("60%% rollout enabled %.3f of users", share)
+	}
+
+	// Without user the tenant is the bucket, without both only 100% applies
+	if rollout(100).Evaluate("new_checkout", nil).Value != true {
+		t.Error("full rollout must apply without attributes")
+	}
+	if rollout(99.9).Evaluate("new_checkout", nil).Value {
+		t.Error("partial rollout must not apply without a bucket attribute")
+	}
+	tenantOnly := Attributes{AttributeTenant: "acme"}
+	if rollout(50).Evaluate("new_checkout", tenantOnly) != rollout(50).Evaluate("new_checkout", tenantOnly) {
+		t.Error("tenant bucketing is not stable")
+	}
+}
+
+func TestSnapshotConsistency(t *testing.T) {
+	engine := mustEngine(t, Flag{Key: "beta", Enabled: true, Default: true})
+	snapshot := engine.Snapshot(Attributes{AttributeUser: "1"})
+	if !snapshot.Enabled("beta") {
+		t.Fatal("beta should be enabled")
+	}
+
+	if err := engine.Set([]Flag{{Key: "beta", Enabled: false}, {Key: "late", Enabled: true, Default: true}}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The snapshot keeps the flags of the moment it was taken
+	if !snapshot.Enabled("beta") {
+		t.Error("snapshot changed after Set")
+	}
+	if snapshot.Enabled("late") {
+		t.Error("snapshot sees a flag added after it was taken")
+	}
+	if engine.Evaluate("beta", nil).Value {
+		t.Error("engine must use the new flags")
+	}
+
+	evaluated := snapshot.Evaluated()
+	if len(evaluated) != 2 || evaluated[0].Key != "beta" || evaluated[1].Key != "late" {
+		t.Errorf("unexpected evaluations %+v", evaluated)
+	}
+
+	ctx := WithSnapshot(context.Background(), snapshot)
+	if !Enabled(ctx, "beta") || Enabled(context.Background(), "beta") {
+		t.Error("context helpers mismatch")
+	}
+}
+
+func TestSetValidates(t *testing.T) {
+	invalid := map[string][]Flag{
+		"empty key":    {{}},
+		"duplicate":    {{Key: "a"}, {Key: "a"}},
+		"percentage":   {{Key: "a", Rules: []Rule{{Percentage: percentage(101)}}}},
+		"operator":     {{Key: "a", Rules: []Rule{{Conditions: []Condition{{Attribute: "user", Operator: "eq"}}}}}},
+		"no attribute": {{Key: "a", Rules: []Rule{{Conditions: []Condition{{Operator: OperatorIn}}}}}},
+	}
+	for name, flags := range invalid {
+		engine := mustEngine(t, Flag{Key: "kept", Enabled: true, Default: true})
+		if err := engine.Set(flags); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+		if !engine.Evaluate("kept", nil).Value {
+			t.Errorf("%s: failed Set replaced flags", name)
+		}
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "flags.yaml")
+	content := `flags:
+  - key: new_checkout
+    enabled: true
+    rules:
+      - name: acme
+        conditions:
+          - attribute: tenant
+            operator: in
+            values: [acme]
+        percentage: 25
+        value: true
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	flags, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile() error = %v", err)
+	}
+	if len(flags) != 1 || len(flags[0].Rules) != 1 || *flags[0].Rules[0].Percentage != 25 {
+		t.Fatalf("unexpected flags %+v", flags)
+	}
+	if flags[0].Rules[0].Conditions[0].Operator != OperatorIn {
+		t.Errorf("operator = %q", flags[0].Rules[0].Conditions[0].Operator)
+	}
+}
@@ -0,0 +1,34 @@
+package featureflag
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+// Handler serves flag definitions. Query parameters are attributes the
+// flags are evaluated for, e.g. /debug/flags?user=42&tenant=acme shows what
+// that user gets and by which rule. Flag rules list user and tenant ids,
+// serve it only on the admin listener (server.Admin) or behind auth
+func (e *Engine) Handler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		flags := e.Flags()
+
+		attrs := make(Attributes)
+		for key, value := range c.Queries() {
+			attrs[key] = value
+		}
+		if len(attrs) == 0 {
+			return c.JSON(fiber.Map{"flags": flags})
+		}
+
+		snapshot := e.Snapshot(attrs)
+		evaluations := make([]Evaluation, 0, len(flags))
+		for _, flag := range flags {
+			evaluations = append(evaluations, snapshot.Evaluate(flag.Key))
+		}
+		return c.JSON(fiber.Map{
+			"flags":       flags,
+			"attributes":  attrs,
+			"evaluations": evaluations,
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package grpcclient
+
+import (
+	"context"
+	"time"
+
+	"github.com/alimzhanovlr/sdk/logger"
+	"github.com/alimzhanovlr/sdk/retry"
+	"github.com/alimzhanovlr/sdk/tracing"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// TimeoutInterceptor sets deadline for calls without one
+func TimeoutInterceptor(timeout time.Duration) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if _, ok := ctx.Deadline(); !ok && timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// TracingInterceptor starts client span and propagates trace context in metadata
+func TracingInterceptor(tracer *tracing.Tracer) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		ctx, span := tracer.Start(ctx, "grpc.client "+method, trace.WithSpanKind(trace.SpanKindClient))
+		defer span.End()
+
+		span.SetAttributes(
+			attribute.String("rpc.system", "grpc"),
+			attribute.String("rpc.method", method),
+			attribute.String("server.address", cc.Target()),
+		)
+
+		md, _ := metadata.FromOutgoingContext(ctx)
+		md = md.Copy()
+		otel.GetTextMapPropagator().Inject(ctx, metadataCarrier(md))
+		ctx = metadata.NewOutgoingContext(ctx, md)
+
+		err := invoker(ctx, method, req, reply, cc, opts...)
+
+		code := status.Code(err)
+		span.SetAttributes(attribute.Int("rpc.grpc.status_code", int(code)))
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(otelcodes.Error, code.String())
+		}
+		return err
+	}
+}
+
+// LoggingInterceptor logs every call attempt, failures with error level
+func LoggingInterceptor(log *logger.Logger) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		start := time.Now()
+		err := invoker(ctx, method, req, reply, cc, opts...)
+
+		fields := []zap.Field{
+			logger.String("method", method),
+			logger.String("target", cc.Target()),
+			logger.String("code", status.Code(err).String()),
+			zap.Int64("duration_ms", time.Since(start).Milliseconds()),
+		}
+		if traceID := tracing.GetTraceID(ctx); traceID != "" {
+			fields = append(fields, logger.String("trace_id", traceID))
+		}
+
+		if err != nil {
+			log.Error("gRPC call failed", append(fields, logger.Error(err))...)
+		} else {
+			log.Debug("gRPC call", fields...)
+		}
+		return err
+	}
+}
+
+// RetryInterceptor retries calls failed with configured codes using
+// exponential backoff with full jitter
+func RetryInterceptor(cfg RetryConfig) grpc.UnaryClientInterceptor {
+	retryable := make(map[codes.Code]bool, len(cfg.Codes))
+	for _, code := range cfg.Codes {
+		retryable[code] = true
+	}
+
+	policy := retry.Policy{
+		MaxAttempts: cfg.MaxAttempts,
+		Backoff:     retry.Jitter(retry.Exponential(cfg.InitialBackoff, cfg.MaxBackoff)),
+		Retryable: func(err error) bool {
+			return retryable[status.Code(err)]
+		},
+	}
+
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return retry.Do(ctx, policy, func(ctx context.Context) error {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		})
+	}
+}
+
+// metadataCarrier adapts outgoing metadata to propagation.TextMapCarrier
+type metadataCarrier metadata.MD
+
+func (c metadataCarrier) Get(key string) string {
+	if values := metadata.MD(c).Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+func (c metadataCarrier) Set(key, value string) {
+	metadata.MD(c).Set(key, value)
+}
+
+func (c metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for key := range c {
+		keys = append(keys, key)
+	}
+	return keys
+}
@@ -0,0 +1,237 @@
+package grpcclient
+
+import (
+	"context"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alimzhanovlr/sdk/logger"
+	"github.com/alimzhanovlr/sdk/tracing"
+	"go.opentelemetry.io/otel"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// healthServer fails calls with the queued codes, then succeeds
+type healthServer struct {
+	grpc_health_v1.UnimplementedHealthServer
+
+	mu        sync.Mutex
+	failures  []codes.Code
+	calls     int
+	metadata  metadata.MD
+	deadline  time.Time
+	hasExpiry bool
+}
+
+func (s *healthServer) Check(ctx context.Context, _ *grpc_health_v1.HealthCheckRequest) (*grpc_health_v1.HealthCheckResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls++
+	s.metadata, _ = metadata.FromIncomingContext(ctx)
+	s.deadline, s.hasExpiry = ctx.Deadline()
+	if len(s.failures) > 0 {
+		code := s.failures[0]
+		s.failures = s.failures[1:]
+		return nil, status.Error(code, "injected")
+	}
+	return &grpc_health_v1.HealthCheckResponse{Status: grpc_health_v1.HealthCheckResponse_SERVING}, nil
+}
+
+// startServer serves srv on an in-memory listener and dials it with cfg
+func startServer(t *testing.T, srv *healthServer, cfg Config, log *logger.Logger, tracer *tracing.Tracer) grpc_health_v1.HealthClient {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	grpc_health_v1.RegisterHealthServer(server, srv)
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+
+	cfg.Address = "passthrough:///bufnet"
+	cfg.Insecure = true
+	conn, err := Dial(cfg, log, tracer, grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+		return lis.DialContext(ctx)
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return grpc_health_v1.NewHealthClient(conn)
+}
+
+func fastRetry(maxAttempts int) RetryConfig {
+	cfg := DefaultRetryConfig()
+	cfg.MaxAttempts = maxAttempts
+	cfg.InitialBackoff = time.Millisecond
+	cfg.MaxBackoff = time.Millisecond
+	return cfg
+}
+
+func TestTimeoutInterceptorSetsDeadline(t *testing.T) {
+	srv := &healthServer{}
+	client := startServer(t, srv, Config{Timeout: 5 * time.Second}, nil, nil)
+
+	start := time.Now()
+	if _, err := client.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{}); err != nil {
+		t.Fatal(err)
+	}
+	if !srv.hasExpiry {
+		t.Fatal("server saw no deadline, want the configured timeout")
+	}
+	// The server rebuilds the deadline from grpc-timeout on arrival
+	if left := srv.deadline.Sub(start); left < 4*time.Second || left > 6*time.Second {
+		t.Errorf("deadline in %s, want about 5s", left)
+	}
+
+	// A deadline set by the caller is kept
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	if _, err := client.Check(ctx, &grpc_health_v1.HealthCheckRequest{}); err != nil {
+		t.Fatal(err)
+	}
+	if left := time.Until(srv.deadline); left < 50*time.Second {
+		t.Errorf("deadline in %s, want the caller's 1m", left)
+	}
+}
+
+func TestTimeoutInterceptorDisabled(t *testing.T) {
+	srv := &healthServer{}
+	client := startServer(t, srv, Config{}, nil, nil)
+
+	if _, err := client.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{}); err != nil {
+		t.Fatal(err)
+	}
+	if srv.hasExpiry {
+		t.Errorf("server saw deadline %s with zero Timeout", srv.deadline)
+	}
+}
+
+func TestRetryInterceptor(t *testing.T) {
+	tests := []struct {
+		name      string
+		failures  []codes.Code
+		wantCalls int
+		wantCode  codes.Code
+	}{
+		{"succeeds after unavailable", []codes.Code{codes.Unavailable, codes.Unavailable}, 3, codes.OK},
+		{"gives up after max attempts", []codes.Code{codes.Unavailable, codes.Unavailable, codes.Unavailable, codes.Unavailable}, 3, codes.Unavailable},
+		{"does not retry other codes", []codes.Code{codes.InvalidArgument}, 1, codes.InvalidArgument},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := &healthServer{failures: tt.failures}
+			client := startServer(t, srv, Config{Retry: fastRetry(3)}, nil, nil)
+
+			_, err := client.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{})
+			if code := status.Code(err); code != tt.wantCode {
+				t.Errorf("code = %s, want %s", code, tt.wantCode)
+			}
+			if srv.calls != tt.wantCalls {
+				t.Errorf("server calls = %d, want %d", srv.calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestRetryDisabled(t *testing.T) {
+	srv := &healthServer{failures: []codes.Code{codes.Unavailable}}
+	client := startServer(t, srv, Config{Retry: fastRetry(1)}, nil, nil)
+
+	if _, err := client.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{}); status.Code(err) != codes.Unavailable {
+		t.Errorf("err = %v, want Unavailable", err)
+	}
+	if srv.calls != 1 {
+		t.Errorf("server calls = %d, want 1", srv.calls)
+	}
+}
+
+func TestLoggingInterceptorLogsEachAttempt(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	log := &logger.Logger{Logger: zap.New(core)}
+	srv := &healthServer{failures: []codes.Code{codes.Unavailable}}
+	client := startServer(t, srv, Config{Retry: fastRetry(2)}, log, nil)
+
+	if _, err := client.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{}); err != nil {
+		t.Fatal(err)
+	}
+
+	entries := logs.All()
+	if len(entries) != 2 {
+		t.Fatalf("got %d log entries, want one per attempt", len(entries))
+	}
+	for i, want := range []struct {
+		level   zapcore.Level
+		message string
+		code    string
+	}{
+		{zapcore.ErrorLevel, "gRPC call failed", "Unavailable"},
+		{zapcore.DebugLevel, "gRPC call", "OK"},
+	} {
+		entry := entries[i]
+		if entry.Level != want.level || entry.Message != want.message {
+			t.Errorf("entry %d = %s %q, want %s %q", i, entry.Level, entry.Message, want.level, want.message)
+		}
+		fields := entry.ContextMap()
+		if fields["code"] != want.code {
+			t.Errorf("entry %d: code = %v, want %s", i, fields["code"], want.code)
+		}
+		if fields["method"] != grpc_health_v1.Health_Check_FullMethodName {
+			t.Errorf("entry %d: method = %v", i, fields["method"])
+		}
+	}
+}
+
+func TestTracingInterceptor(t *testing.T) {
+	previous := otel.GetTextMapPropagator()
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() { otel.SetTextMapPropagator(previous) })
+
+	recorder := tracetest.NewSpanRecorder()
+	tracer := tracing.NewWithProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)), "test")
+	srv := &healthServer{failures: []codes.Code{codes.Unavailable, codes.Unavailable}}
+	client := startServer(t, srv, Config{Retry: fastRetry(2)}, nil, tracer)
+
+	if _, err := client.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{}); status.Code(err) != codes.Unavailable {
+		t.Fatalf("err = %v, want Unavailable", err)
+	}
+
+	// One span covers both attempts
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("got %d spans, want 1", len(spans))
+	}
+	span := spans[0]
+	if want := "grpc.client " + grpc_health_v1.Health_Check_FullMethodName; span.Name() != want {
+		t.Errorf("span name = %q, want %q", span.Name(), want)
+	}
+	if span.Status().Code != otelcodes.Error {
+		t.Errorf("span status = %v, want error", span.Status())
+	}
+	attrs := map[string]string{}
+	for _, attr := range span.Attributes() {
+		attrs[string(attr.Key)] = attr.Value.Emit()
+	}
+	if attrs["rpc.system"] != "grpc" || attrs["rpc.grpc.status_code"] != "14" {
+		t.Errorf("attributes = %v", attrs)
+	}
+
+	traceparent := srv.metadata.Get("traceparent")
+	if len(traceparent) != 1 || !strings.Contains(traceparent[0], span.SpanContext().TraceID().String()) {
+		t.Errorf("traceparent = %v, want trace %s", traceparent, span.SpanContext().TraceID())
+	}
+}
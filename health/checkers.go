@@ -0,0 +1,85 @@
+package health
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/segmentio/kafka-go"
+)
+
+// DB checks database connectivity with ping
+func DB(name string, db *sql.DB) Checker {
+	return CheckFunc(name, func(ctx context.Context) error {
+		return db.PingContext(ctx)
+	})
+}
+
+// Redis checks redis connectivity with PING
+func Redis(name string, client redis.UniversalClient) Checker {
+	return CheckFunc(name, func(ctx context.Context) error {
+		return client.Ping(ctx).Err()
+	})
+}
+
+// Kafka checks that at least one broker is reachable
+func Kafka(name string, brokers ...string) Checker {
+	return CheckFunc(name, func(ctx context.Context) error {
+		var errs []error
+		for _, broker := range brokers {
+			conn, err := kafka.DialContext(ctx, "tcp", broker)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", broker, err))
+				continue
+			}
+			conn.Close()
+			return nil
+		}
+		if len(errs) == 0 {
+			return errors.New("no kafka brokers configured")
+		}
+		return errors.Join(errs...)
+	})
+}
+
+// URL checks that downstream responds with non-5xx status
+func URL(name, url string, client *http.Client) Checker {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	return CheckFunc(name, func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return err
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode >= http.StatusInternalServerError {
+			return fmt.Errorf("unexpected status: %d", resp.StatusCode)
+		}
+		return nil
+	})
+}
+
+// DiskSpace checks that filesystem containing path has at least minFree bytes
+func DiskSpace(name, path string, minFree uint64) Checker {
+	return CheckFunc(name, func(ctx context.Context) error {
+		free, err := freeSpace(path)
+		if err != nil {
+			return err
+		}
+		if free < minFree {
+			return fmt.Errorf("low disk space on %s: %d bytes free, %d required", path, free, minFree)
+		}
+		return nil
+	})
+}
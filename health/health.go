@@ -0,0 +1,252 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	apperrors "github.com/alimzhanovlr/sdk/errors"
+	"github.com/alimzhanovlr/sdk/logger"
+	"github.com/gofiber/fiber/v2"
+)
+
+// Severity defines how a failing check affects overall status
+type Severity string
+
+const (
+	// SeverityCritical marks service as down when check fails
+	SeverityCritical Severity = "critical"
+	// SeverityDegraded marks service as degraded but still ready
+	SeverityDegraded Severity = "degraded"
+)
+
+// Status is a check or overall status
+type Status string
+
+const (
+	StatusUp       Status = "up"
+	StatusDegraded Status = "degraded"
+	StatusDown     Status = "down"
+)
+
+// Default check settings
+const (
+	DefaultTimeout  = 2 * time.Second
+	DefaultCacheTTL = 5 * time.Second
+)
+
+// Checker checks a single dependency
+type Checker interface {
+	Name() string
+	Check(ctx context.Context) error
+}
+
+// CheckFunc adapts a function to Checker
+func CheckFunc(name string, fn func(ctx context.Context) error) Checker {
+	return &funcChecker{name: name, fn: fn}
+}
+
+type funcChecker struct {
+	name string
+	fn   func(ctx context.Context) error
+}
+
+func (c *funcChecker) Name() string {
+	return c.name
+}
+
+func (c *funcChecker) Check(ctx context.Context) error {
+	return c.fn(ctx)
+}
+
+// Option configures a registered check
+type Option func(*entry)
+
+// WithSeverity sets check severity, default is critical
+func WithSeverity(severity Severity) Option {
+	return func(e *entry) {
+		e.severity = severity
+	}
+}
+
+// WithTimeout sets check timeout
+func WithTimeout(timeout time.Duration) Option {
+	return func(e *entry) {
+		e.timeout = timeout
+	}
+}
+
+// WithCacheTTL sets how long a result is reused, zero disables caching
+func WithCacheTTL(ttl time.Duration) Option {
+	return func(e *entry) {
+		e.cacheTTL = ttl
+	}
+}
+
+// Result is a single check result
+type Result struct {
+	Name      string    `json:"name"`
+	Status    Status    `json:"status"`
+	Severity  Severity  `json:"severity"`
+	Error     string    `json:"error,omitempty"`
+	Duration  string    `json:"duration"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// Report is aggregated health report
+type Report struct {
+	Status Status   `json:"status"`
+	Checks []Result `json:"checks"`
+}
+
+// entry is a registered check with cached result
+type entry struct {
+	checker  Checker
+	severity Severity
+	timeout  time.Duration
+	cacheTTL time.Duration
+
+	mu     sync.Mutex
+	result *Result
+}
+
+// Registry holds health checks
+type Registry struct {
+	logger *logger.Logger
+
+	mu      sync.RWMutex
+	entries map[string]*entry
+}
+
+// New creates a new health registry
+func New(log *logger.Logger) *Registry {
+	return &Registry{
+		logger:  log,
+		entries: make(map[string]*entry),
+	}
+}
+
+// Register adds a check to the registry
+func (r *Registry) Register(checker Checker, opts ...Option) error {
+	e := &entry{
+		checker:  checker,
+		severity: SeverityCritical,
+		timeout:  DefaultTimeout,
+		cacheTTL: DefaultCacheTTL,
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.entries[checker.Name()]; exists {
+		return fmt.Errorf("health check %s already registered", checker.Name())
+	}
+	r.entries[checker.Name()] = e
+	return nil
+}
+
+// Check runs all checks concurrently and aggregates result
+func (r *Registry) Check(ctx context.Context) Report {
+	r.mu.RLock()
+	entries := make([]*entry, 0, len(r.entries))
+	for _, e := range r.entries {
+		entries = append(entries, e)
+	}
+	r.mu.RUnlock()
+
+	results := make([]Result, len(entries))
+
+	var wg sync.WaitGroup
+	for i, e := range entries {
+		wg.Add(1)
+		go func(i int, e *entry) {
+			defer wg.Done()
+			results[i] = r.run(ctx, e)
+		}(i, e)
+	}
+	wg.Wait()
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Name < results[j].Name
+	})
+
+	status := StatusUp
+	for _, res := range results {
+		if res.Status == StatusUp {
+			continue
+		}
+		if res.Severity == SeverityCritical {
+			status = StatusDown
+			break
+		}
+		status = StatusDegraded
+	}
+
+	return Report{Status: status, Checks: results}
+}
+
+// run executes a check or returns cached result
+func (r *Registry) run(ctx context.Context, e *entry) Result {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.result != nil && time.Since(e.result.CheckedAt) < e.cacheTTL {
+		return *e.result
+	}
+
+	checkCtx, cancel := context.WithTimeout(ctx, e.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := safeCheck(checkCtx, e.checker)
+
+	result := Result{
+		Name:      e.checker.Name(),
+		Status:    StatusUp,
+		Severity:  e.severity,
+		Duration:  time.Since(start).String(),
+		CheckedAt: time.Now(),
+	}
+
+	if err != nil {
+		result.Status = StatusDown
+		if e.severity == SeverityDegraded {
+			result.Status = StatusDegraded
+		}
+		result.Error = err.Error()
+
+		r.logger.Warn("Health check failed",
+			logger.String("check", result.Name),
+			logger.String("severity", string(e.severity)),
+			logger.Error(err),
+		)
+	}
+
+	e.result = &result
+	return result
+}
+
+// safeCheck runs checker converting panics to errors
+func safeCheck(ctx context.Context, checker Checker) (err error) {
+	defer apperrors.Recover(&err)
+	return checker.Check(ctx)
+}
+
+// Handler returns readiness handler, responds 503 when a critical check fails
+func (r *Registry) Handler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		report := r.Check(c.UserContext())
+
+		status := fiber.StatusOK
+		if report.Status == StatusDown {
+			status = fiber.StatusServiceUnavailable
+		}
+
+		return c.Status(status).JSON(report)
+	}
+}
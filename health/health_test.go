@@ -0,0 +1,184 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	apperrors "github.com/alimzhanovlr/sdk/errors"
+	"github.com/alimzhanovlr/sdk/logger"
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+func newTestRegistry() *Registry {
+	return New(&logger.Logger{Logger: zap.NewNop()})
+}
+
+func up(context.Context) error { return nil }
+
+func down(context.Context) error { return errors.New("connection refused") }
+
+func TestCheckAggregatesSeverity(t *testing.T) {
+	tests := []struct {
+		name     string
+		critical func(context.Context) error
+		degraded func(context.Context) error
+		want     Status
+	}{
+		{"all up", up, up, StatusUp},
+		{"degraded check fails", up, down, StatusDegraded},
+		{"critical check fails", down, up, StatusDown},
+		{"both fail", down, down, StatusDown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newTestRegistry()
+			if err := r.Register(CheckFunc("postgres", tt.critical)); err != nil {
+				t.Fatal(err)
+			}
+			if err := r.Register(CheckFunc("cache", tt.degraded), WithSeverity(SeverityDegraded)); err != nil {
+				t.Fatal(err)
+			}
+
+			report := r.Check(context.Background())
+			if report.Status != tt.want {
+				t.Errorf("status = %s, want %s", report.Status, tt.want)
+			}
+			if len(report.Checks) != 2 || report.Checks[0].Name != "cache" || report.Checks[1].Name != "postgres" {
+				t.Fatalf("checks = %+v, want sorted by name", report.Checks)
+			}
+			if tt.degraded(context.Background()) != nil && report.Checks[0].Status != StatusDegraded {
+				t.Errorf("failed degraded check status = %s", report.Checks[0].Status)
+			}
+			if tt.critical(context.Background()) != nil && report.Checks[1].Error != "connection refused" {
+				t.Errorf("failed critical check error = %q", report.Checks[1].Error)
+			}
+		})
+	}
+}
+
+func TestCheckReusesCachedResult(t *testing.T) {
+	r := newTestRegistry()
+	var cached, uncached atomic.Int32
+	r.Register(CheckFunc("cached", func(context.Context) error {
+		cached.Add(1)
+		return nil
+	}), WithCacheTTL(time.Hour))
+	r.Register(CheckFunc("uncached", func(context.Context) error {
+		uncached.Add(1)
+		return nil
+	}), WithCacheTTL(0))
+
+	for i := 0; i < 3; i++ {
+		r.Check(context.Background())
+	}
+	if got := cached.Load(); got != 1 {
+		t.Errorf("cached check ran %d times, want 1", got)
+	}
+	if got := uncached.Load(); got != 3 {
+		t.Errorf("uncached check ran %d times, want 3", got)
+	}
+}
+
+func TestCheckTimeout(t *testing.T) {
+	r := newTestRegistry()
+	r.Register(CheckFunc("slow", func(ctx context.Context) error {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Minute):
+			return nil
+		}
+	}), WithTimeout(10*time.Millisecond))
+
+	start := time.Now()
+	report := r.Check(context.Background())
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("check took %s, want the 10ms timeout", elapsed)
+	}
+	if report.Status != StatusDown || report.Checks[0].Error != context.DeadlineExceeded.Error() {
+		t.Errorf("report = %+v, want a deadline failure", report)
+	}
+}
+
+func TestCheckRecoversPanics(t *testing.T) {
+	r := newTestRegistry()
+	r.Register(CheckFunc("broken", func(context.Context) error {
+		panic("nil client")
+	}))
+
+	report := r.Check(context.Background())
+	if report.Status != StatusDown || !strings.Contains(report.Checks[0].Error, "nil client") {
+		t.Errorf("report = %+v, want the panic as a failure", report)
+	}
+
+	err := safeCheck(context.Background(), CheckFunc("broken", func(context.Context) error {
+		panic("nil client")
+	}))
+	var panicErr *apperrors.PanicError
+	if !errors.As(err, &panicErr) || len(panicErr.Stack) == 0 {
+		t.Errorf("safeCheck() error = %v, want a PanicError with the stack", err)
+	}
+}
+
+func TestRegisterRejectsDuplicates(t *testing.T) {
+	r := newTestRegistry()
+	if err := r.Register(CheckFunc("postgres", up)); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Register(CheckFunc("postgres", down)); err == nil {
+		t.Error("duplicate check is registered")
+	}
+	if report := r.Check(context.Background()); report.Status != StatusUp {
+		t.Errorf("status = %s, the first check must stay registered", report.Status)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	tests := []struct {
+		name     string
+		severity Severity
+		check    func(context.Context) error
+		want     int
+		status   Status
+	}{
+		{"up", SeverityCritical, up, fiber.StatusOK, StatusUp},
+		{"degraded is still ready", SeverityDegraded, down, fiber.StatusOK, StatusDegraded},
+		{"down", SeverityCritical, down, fiber.StatusServiceUnavailable, StatusDown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newTestRegistry()
+			r.Register(CheckFunc("dependency", tt.check), WithSeverity(tt.severity))
+
+			app := fiber.New()
+			app.Get("/health/ready", r.Handler())
+			resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/health/ready", nil))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tt.want {
+				t.Errorf("status code = %d, want %d", resp.StatusCode, tt.want)
+			}
+			body, _ := io.ReadAll(resp.Body)
+			var report Report
+			if err := json.Unmarshal(body, &report); err != nil {
+				t.Fatalf("body is not a report: %v\n%s", err, body)
+			}
+			if report.Status != tt.status || len(report.Checks) != 1 {
+				t.Errorf("report = %+v", report)
+			}
+		})
+	}
+}
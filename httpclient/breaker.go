@@ -0,0 +1,56 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/alimzhanovlr/sdk/breaker"
+)
+
+// BreakerRoundTripper не вызывает upstream, пока circuit breaker открыт
+type BreakerRoundTripper struct {
+	next      http.RoundTripper
+	breaker   *breaker.CircuitBreaker
+	isFailure func(resp *http.Response, err error) bool
+}
+
+// NewBreakerRoundTripper создает RoundTripper с circuit breaker. isFailure
+// решает, считать ли ответ сбоем, по умолчанию DefaultIsFailure
+func NewBreakerRoundTripper(next http.RoundTripper, cb *breaker.CircuitBreaker, isFailure func(resp *http.Response, err error) bool) *BreakerRoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	if isFailure == nil {
+		isFailure = DefaultIsFailure
+	}
+
+	return &BreakerRoundTripper{
+		next:      next,
+		breaker:   cb,
+		isFailure: isFailure,
+	}
+}
+
+// DefaultIsFailure считает сбоем сетевые ошибки, 429 и 5xx. Отмена
+// вызывающим не говорит о состоянии upstream и сбоем не считается
+func DefaultIsFailure(resp *http.Response, err error) bool {
+	if err != nil {
+		return !errors.Is(err, context.Canceled)
+	}
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError
+}
+
+// RoundTrip выполняет запрос, если breaker пропускает его. Иначе
+// возвращается ошибка с breaker.ErrOpenState или breaker.ErrTooManyRequests
+func (b *BreakerRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	done, err := b.breaker.Allow()
+	if err != nil {
+		return nil, fmt.Errorf("httpclient: %s: %w", b.breaker.Name(), err)
+	}
+
+	resp, err := b.next.RoundTrip(req)
+	done(!b.isFailure(resp, err))
+	return resp, err
+}
@@ -0,0 +1,115 @@
+package httpclient
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alimzhanovlr/sdk/breaker"
+)
+
+func TestBreakerRoundTripper(t *testing.T) {
+	var failing atomic.Bool
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if failing.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+
+	var changes []string
+	cb := breaker.NewCircuitBreaker(breaker.Settings{
+		Name:    "upstream",
+		Timeout: 50 * time.Millisecond,
+		ReadyToTrip: func(counts breaker.Counts) bool {
+			return counts.ConsecutiveFailures >= 2
+		},
+		OnStateChange: func(name string, from, to breaker.State) {
+			changes = append(changes, from.String()+"->"+to.String())
+		},
+	})
+	client := &http.Client{Transport: NewBreakerRoundTripper(nil, cb, nil)}
+
+	get := func() (*http.Response, error) {
+		resp, err := client.Get(srv.URL)
+		if err == nil {
+			resp.Body.Close()
+		}
+		return resp, err
+	}
+
+	failing.Store(true)
+	for i := 0; i < 2; i++ {
+		resp, err := get()
+		if err != nil {
+			t.Fatalf("request %d failed: %v", i, err)
+		}
+		if resp.StatusCode != http.StatusServiceUnavailable {
+			t.Fatalf("expected 503, got %d", resp.StatusCode)
+		}
+	}
+	if cb.State() != breaker.StateOpen {
+		t.Fatalf("expected open breaker, got %s", cb.State())
+	}
+
+	// Открытый breaker не пропускает запросы к upstream
+	if _, err := get(); !errors.Is(err, breaker.ErrOpenState) {
+		t.Fatalf("expected ErrOpenState, got %v", err)
+	}
+	if got := requests.Load(); got != 2 {
+		t.Errorf("expected 2 requests to reach server, got %d", got)
+	}
+
+	// После Timeout пробный запрос закрывает breaker
+	failing.Store(false)
+	time.Sleep(60 * time.Millisecond)
+	if resp, err := get(); err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected probe to succeed, got %v", err)
+	}
+	if cb.State() != breaker.StateClosed {
+		t.Errorf("expected closed breaker, got %s", cb.State())
+	}
+
+	want := []string{"closed->open", "open->half-open", "half-open->closed"}
+	if len(changes) != len(want) {
+		t.Fatalf("expected state changes %v, got %v", want, changes)
+	}
+	for i := range want {
+		if changes[i] != want[i] {
+			t.Errorf("expected state changes %v, got %v", want, changes)
+			break
+		}
+	}
+}
+
+func TestDefaultIsFailure(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		err    error
+		want   bool
+	}{
+		{"ok", http.StatusOK, nil, false},
+		{"client error", http.StatusNotFound, nil, false},
+		{"rate limited", http.StatusTooManyRequests, nil, true},
+		{"server error", http.StatusBadGateway, nil, true},
+		{"network error", 0, errors.New("connection refused"), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var resp *http.Response
+			if tt.err == nil {
+				resp = &http.Response{StatusCode: tt.status}
+			}
+			if got := DefaultIsFailure(resp, tt.err); got != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,446 @@
+package httpclient
+
+import (
+	"bytes"
+	"cmp"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// CaptureTimings фазы неудачного запроса
+type CaptureTimings struct {
+	DNSMillis       int64 `json:"dns_ms,omitempty"`
+	ConnectMillis   int64 `json:"connect_ms,omitempty"`
+	TLSMillis       int64 `json:"tls_ms,omitempty"`
+	FirstByteMillis int64 `json:"first_byte_ms,omitempty"`
+	TotalMillis     int64 `json:"total_ms"`
+	// ReusedConn соединение взято из пула
+	ReusedConn bool `json:"reused_conn"`
+}
+
+// CapturedExchange сохраненный неудачный обмен, заголовки, тела и query
+// санитизированы
+type CapturedExchange struct {
+	Seq       uint64    `json:"seq"`
+	Time      time.Time `json:"time"`
+	RequestID string    `json:"request_id,omitempty"`
+
+	Method         string            `json:"method"`
+	URL            string            `json:"url"`
+	RequestHeaders map[string]string `json:"request_headers,omitempty"`
+	RequestBody    string            `json:"request_body,omitempty"`
+
+	Status          int               `json:"status,omitempty"`
+	ResponseHeaders map[string]string `json:"response_headers,omitempty"`
+	ResponseBody    string            `json:"response_body,omitempty"`
+	Error           string            `json:"error,omitempty"`
+
+	Timings CaptureTimings `json:"timings"`
+}
+
+// CaptureStore хранит последние N обменов, старые вытесняются
+type CaptureStore interface {
+	Add(exchange CapturedExchange) error
+	// List возвращает обмены от новых к старым
+	List() ([]CapturedExchange, error)
+}
+
+// CaptureConfig конфигурация сохранения неудачных обменов
+type CaptureConfig struct {
+	// Store по умолчанию NewMemoryCaptureStore(100)
+	Store CaptureStore
+	// Sanitizer по умолчанию с правилами по умолчанию
+	Sanitizer *Sanitizer
+	// ShouldCapture по умолчанию ошибки транспорта и ответы 5xx
+	ShouldCapture func(req *http.Request, resp *http.Response, err error) bool
+	// MaxBodySize сколько байт санитизированного тела сохранять
+	MaxBodySize int
+	// OnError вызывается при ошибке записи в Store
+	OnError func(err error)
+}
+
+// DefaultCaptureConfig дефолтная конфигурация
+func DefaultCaptureConfig() CaptureConfig {
+	return CaptureConfig{
+		Store:     NewMemoryCaptureStore(100),
+		Sanitizer: NewSanitizer(nil),
+		ShouldCapture: func(req *http.Request, resp *http.Response, err error) bool {
+			return err != nil || resp.StatusCode >= 500
+		},
+		MaxBodySize: 64 * 1024,
+	}
+}
+
+// captureReadLimit сколько байт тела читается для санитизации
+const captureReadLimit = 1 << 20
+
+// CaptureRoundTripper сохраняет неудачные обмены для разбора после
+// инцидента, например периодических 5xx партнера. Тело запроса берется
+// через GetBody и не вычитывается. Тело ответа читается целиком до
+// captureReadLimit и возвращается вызывающему без изменений
+type CaptureRoundTripper struct {
+	next   http.RoundTripper
+	config CaptureConfig
+}
+
+// NewCaptureRoundTripper создает RoundTripper с сохранением неудачных обменов
+func NewCaptureRoundTripper(next http.RoundTripper, config CaptureConfig) *CaptureRoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	defaults := DefaultCaptureConfig()
+	if config.Store == nil {
+		config.Store = defaults.Store
+	}
+	if config.Sanitizer == nil {
+		config.Sanitizer = defaults.Sanitizer
+	}
+	if config.ShouldCapture == nil {
+		config.ShouldCapture = defaults.ShouldCapture
+	}
+	if config.MaxBodySize <= 0 {
+		config.MaxBodySize = defaults.MaxBodySize
+	}
+
+	return &CaptureRoundTripper{next: next, config: config}
+}
+
+// Store возвращает хранилище обменов
+func (c *CaptureRoundTripper) Store() CaptureStore {
+	return c.config.Store
+}
+
+// RoundTrip выполняет запрос и сохраняет его, если он неудачный
+func (c *CaptureRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	timings := &captureTrace{}
+	start := time.Now()
+	traced := req.WithContext(httptrace.WithClientTrace(req.Context(), timings.clientTrace()))
+
+	resp, err := c.next.RoundTrip(traced)
+	if !c.config.ShouldCapture(req, resp, err) {
+		return resp, err
+	}
+
+	exchange := CapturedExchange{
+		Time:           start.UTC(),
+		RequestID:      requestID(req),
+		Method:         req.Method,
+		URL:            c.config.Sanitizer.sanitizeURL(req.URL),
+		RequestHeaders: c.config.Sanitizer.SanitizeHeaders(req.Header),
+		RequestBody:    c.requestBody(req),
+	}
+	if err != nil {
+		exchange.Error = err.Error()
+	} else {
+		exchange.Status = resp.StatusCode
+		exchange.ResponseHeaders = c.config.Sanitizer.SanitizeHeaders(resp.Header)
+		exchange.ResponseBody = c.responseBody(resp)
+	}
+	exchange.Timings = timings.result(time.Since(start))
+
+	if storeErr := c.config.Store.Add(exchange); storeErr != nil && c.config.OnError != nil {
+		c.config.OnError(fmt.Errorf("failed to capture exchange: %w", storeErr))
+	}
+	return resp, err
+}
+
+// requestBody читает копию тела через GetBody, не трогая отправленное
+func (c *CaptureRoundTripper) requestBody(req *http.Request) string {
+	if req.GetBody == nil || req.ContentLength == 0 {
+		return ""
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return ""
+	}
+	defer body.Close()
+
+	data, _ := io.ReadAll(io.LimitReader(body, captureReadLimit+1))
+	return c.sanitizeBody(data, req.Header.Get("Content-Type"), req.ContentLength)
+}
+
+// responseBody читает начало тела и возвращает его обратно в ответ
+func (c *CaptureRoundTripper) responseBody(resp *http.Response) string {
+	if resp.Body == nil || resp.Body == http.NoBody {
+		return ""
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, captureReadLimit+1))
+	resp.Body = &prefixedBody{
+		Reader: io.MultiReader(bytes.NewReader(data), errReader(err, resp.Body)),
+		closer: resp.Body,
+	}
+	return c.sanitizeBody(data, resp.Header.Get("Content-Type"), resp.ContentLength)
+}
+
+// sanitizeBody санитизирует тело целиком и только потом обрезает: обрезанный
+// JSON не разбирается, и значения чувствительных полей попали бы как есть
+func (c *CaptureRoundTripper) sanitizeBody(data []byte, contentType string, size int64) string {
+	size = max(size, int64(len(data)))
+	if isBinaryContent(contentType) {
+		return fmt.Sprintf("[binary body - size: %s]", formatSize(int(size)))
+	}
+	if len(data) > captureReadLimit {
+		return fmt.Sprintf("[body too large to sanitize - size: %s]", formatSize(int(size)))
+	}
+
+	body := c.config.Sanitizer.SanitizeBody(data, contentType)
+	if len(body) > c.config.MaxBodySize {
+		body = cutUTF8(body, c.config.MaxBodySize) + "...[truncated]"
+	}
+	return body
+}
+
+// Handler отдает сохраненные обмены в JSON от новых к старым, ?limit=N
+// ограничивает количество. Подключается к админскому серверу:
+//
+//	app.Get("/debug/http-failures", adaptor.HTTPHandler(capture.Handler()))
+func (c *CaptureRoundTripper) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		exchanges, err := c.config.Store.List()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if limit, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && limit >= 0 && limit < len(exchanges) {
+			exchanges = exchanges[:limit]
+		}
+		if exchanges == nil {
+			exchanges = []CapturedExchange{}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(exchanges)
+	})
+}
+
+// prefixedBody тело ответа, начало которого уже прочитано
+type prefixedBody struct {
+	io.Reader
+	closer io.Closer
+}
+
+func (b *prefixedBody) Close() error {
+	return b.closer.Close()
+}
+
+// errReader возвращает ошибку чтения начала тела, иначе остаток тела
+func errReader(err error, rest io.Reader) io.Reader {
+	if err != nil {
+		return &failingReader{err: err}
+	}
+	return rest
+}
+
+type failingReader struct {
+	err error
+}
+
+func (r *failingReader) Read([]byte) (int, error) {
+	return 0, r.err
+}
+
+// captureTrace собирает фазы запроса через httptrace
+type captureTrace struct {
+	mu                     sync.Mutex
+	dnsStart, dnsDone      time.Time
+	connectStart, connDone time.Time
+	tlsStart, tlsDone      time.Time
+	wroteRequest           time.Time
+	firstByte              time.Time
+	reused                 bool
+}
+
+func (t *captureTrace) clientTrace() *httptrace.ClientTrace {
+	set := func(field *time.Time) {
+		t.mu.Lock()
+		*field = time.Now()
+		t.mu.Unlock()
+	}
+	return &httptrace.ClientTrace{
+		DNSStart:          func(httptrace.DNSStartInfo) { set(&t.dnsStart) },
+		DNSDone:           func(httptrace.DNSDoneInfo) { set(&t.dnsDone) },
+		ConnectStart:      func(string, string) { set(&t.connectStart) },
+		ConnectDone:       func(string, string, error) { set(&t.connDone) },
+		TLSHandshakeStart: func() { set(&t.tlsStart) },
+		TLSHandshakeDone:  func(tls.ConnectionState, error) { set(&t.tlsDone) },
+		WroteRequest:      func(httptrace.WroteRequestInfo) { set(&t.wroteRequest) },
+		GotFirstResponseByte: func() {
+			set(&t.firstByte)
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			t.mu.Lock()
+			t.reused = info.Reused
+			t.mu.Unlock()
+		},
+	}
+}
+
+func (t *captureTrace) result(total time.Duration) CaptureTimings {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return CaptureTimings{
+		DNSMillis:       millisBetween(t.dnsStart, t.dnsDone),
+		ConnectMillis:   millisBetween(t.connectStart, t.connDone),
+		TLSMillis:       millisBetween(t.tlsStart, t.tlsDone),
+		FirstByteMillis: millisBetween(t.wroteRequest, t.firstByte),
+		TotalMillis:     total.Milliseconds(),
+		ReusedConn:      t.reused,
+	}
+}
+
+func millisBetween(start, end time.Time) int64 {
+	if start.IsZero() || end.IsZero() {
+		return 0
+	}
+	return end.Sub(start).Milliseconds()
+}
+
+// MemoryCaptureStore кольцевой буфер в памяти, теряется при рестарте
+type MemoryCaptureStore struct {
+	mu        sync.Mutex
+	exchanges []CapturedExchange
+	next      int
+	seq       uint64
+}
+
+// NewMemoryCaptureStore создает буфер на size обменов
+func NewMemoryCaptureStore(size int) *MemoryCaptureStore {
+	return &MemoryCaptureStore{exchanges: make([]CapturedExchange, 0, max(size, 1))}
+}
+
+// Add добавляет обмен, вытесняя самый старый
+func (s *MemoryCaptureStore) Add(exchange CapturedExchange) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.seq++
+	exchange.Seq = s.seq
+	if len(s.exchanges) < cap(s.exchanges) {
+		s.exchanges = append(s.exchanges, exchange)
+		return nil
+	}
+	s.exchanges[s.next] = exchange
+	s.next = (s.next + 1) % len(s.exchanges)
+	return nil
+}
+
+// List возвращает обмены от новых к старым
+func (s *MemoryCaptureStore) List() ([]CapturedExchange, error) {
+	s.mu.Lock()
+	exchanges := slices.Clone(s.exchanges)
+	s.mu.Unlock()
+
+	sortNewestFirst(exchanges)
+	return exchanges, nil
+}
+
+// DiskCaptureStore кольцевой буфер из size файлов в каталоге, переживает
+// рестарт. Каждый обмен пишется во временный файл и переименовывается
+type DiskCaptureStore struct {
+	dir  string
+	size int
+
+	mu  sync.Mutex
+	seq uint64
+}
+
+// NewDiskCaptureStore создает буфер на size обменов в dir, продолжая
+// нумерацию сохраненных ранее
+func NewDiskCaptureStore(dir string, size int) (*DiskCaptureStore, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create capture dir: %w", err)
+	}
+
+	s := &DiskCaptureStore{dir: dir, size: max(size, 1)}
+	exchanges, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+	if len(exchanges) > 0 {
+		s.seq = exchanges[0].Seq
+	}
+	return s, nil
+}
+
+// Add записывает обмен в слот самого старого
+func (s *DiskCaptureStore) Add(exchange CapturedExchange) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	exchange.Seq = s.seq + 1
+	data, err := json.Marshal(exchange)
+	if err != nil {
+		return fmt.Errorf("failed to marshal exchange: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(s.dir, ".exchange-*")
+	if err != nil {
+		return fmt.Errorf("failed to create capture file: %w", err)
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		slot := (exchange.Seq - 1) % uint64(s.size)
+		err = os.Rename(tmp.Name(), filepath.Join(s.dir, fmt.Sprintf("exchange-%d.json", slot)))
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write capture file: %w", err)
+	}
+
+	s.seq = exchange.Seq
+	return nil
+}
+
+// List возвращает обмены от новых к старым, поврежденные файлы пропускаются
+func (s *DiskCaptureStore) List() ([]CapturedExchange, error) {
+	paths, err := filepath.Glob(filepath.Join(s.dir, "exchange-*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list capture files: %w", err)
+	}
+
+	exchanges := make([]CapturedExchange, 0, len(paths))
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read capture file: %w", err)
+		}
+		var exchange CapturedExchange
+		if json.Unmarshal(data, &exchange) != nil {
+			continue
+		}
+		exchanges = append(exchanges, exchange)
+	}
+
+	sortNewestFirst(exchanges)
+	// Слоты сверх size остаются после уменьшения размера буфера
+	if len(exchanges) > s.size {
+		exchanges = exchanges[:s.size]
+	}
+	return exchanges, nil
+}
+
+func sortNewestFirst(exchanges []CapturedExchange) {
+	slices.SortFunc(exchanges, func(a, b CapturedExchange) int {
+		return cmp.Compare(b.Seq, a.Seq)
+	})
+}
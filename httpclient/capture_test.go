@@ -0,0 +1,169 @@
+package httpclient
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestCaptureRoundTripper(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/ok" {
+			io.WriteString(w, "ok")
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadGateway)
+		io.WriteString(w, `{"error":"upstream","token":"secret-token","data":"`+strings.Repeat("x", 100)+`"}`)
+	}))
+	defer srv.Close()
+
+	store := NewMemoryCaptureStore(10)
+	capture := NewCaptureRoundTripper(nil, CaptureConfig{Store: store, MaxBodySize: 60})
+	client := &http.Client{Transport: capture}
+
+	resp, err := client.Get(srv.URL + "/ok")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
+
+	req, _ := http.NewRequest(http.MethodPost, srv.URL+"/pay?api_key=k&id=1", strings.NewReader(`{"password":"p","amount":10}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer sk-1234567890abcdef")
+	resp, err = client.Do(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	// Вызывающий получает тело целиком
+	if !strings.HasSuffix(string(body), strings.Repeat("x", 100)+`"}`) {
+		t.Errorf("response body changed: %q", body)
+	}
+
+	exchanges, _ := store.List()
+	if len(exchanges) != 1 {
+		t.Fatalf("expected 1 captured exchange, got %d", len(exchanges))
+	}
+	e := exchanges[0]
+	if e.Status != http.StatusBadGateway || e.Method != http.MethodPost {
+		t.Errorf("unexpected exchange: %+v", e)
+	}
+	for _, leaked := range []string{"sk-1234567890abcdef", "=k", `"p"`, "secret-token"} {
+		if strings.Contains(e.URL+e.RequestHeaders["Authorization"]+e.RequestBody+e.ResponseBody, leaked) {
+			t.Errorf("%q not sanitized: %+v", leaked, e)
+		}
+	}
+	if !strings.Contains(e.RequestBody, "amount") {
+		t.Errorf("request body not captured: %q", e.RequestBody)
+	}
+	if !strings.HasSuffix(e.ResponseBody, "...[truncated]") {
+		t.Errorf("response body not truncated: %q", e.ResponseBody)
+	}
+	if e.Timings.TotalMillis < 0 || e.Timings.FirstByteMillis < 0 {
+		t.Errorf("unexpected timings: %+v", e.Timings)
+	}
+}
+
+func TestCaptureTruncatesOnRuneBoundary(t *testing.T) {
+	capture := NewCaptureRoundTripper(nil, CaptureConfig{MaxBodySize: 5})
+
+	// Кириллица занимает 2 байта, 5 байт разрезали бы третью букву
+	body := capture.sanitizeBody([]byte("платеж отклонен"), "text/plain", -1)
+	if !utf8.ValidString(body) {
+		t.Fatalf("body is not valid UTF-8: %q", body)
+	}
+	if body != "пл...[truncated]" {
+		t.Errorf("body = %q", body)
+	}
+}
+
+func TestCaptureRoundTripperTransportError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	url := srv.URL
+	srv.Close()
+
+	capture := NewCaptureRoundTripper(nil, CaptureConfig{})
+	client := &http.Client{Transport: capture}
+	if _, err := client.Get(url); err == nil {
+		t.Fatal("expected error")
+	}
+
+	exchanges, _ := capture.Store().List()
+	if len(exchanges) != 1 || exchanges[0].Error == "" {
+		t.Fatalf("expected captured error, got %+v", exchanges)
+	}
+}
+
+func TestMemoryCaptureStore(t *testing.T) {
+	store := NewMemoryCaptureStore(3)
+	for i := 0; i < 5; i++ {
+		store.Add(CapturedExchange{Method: http.MethodGet})
+	}
+
+	exchanges, _ := store.List()
+	if len(exchanges) != 3 {
+		t.Fatalf("expected 3 exchanges, got %d", len(exchanges))
+	}
+	for i, want := range []uint64{5, 4, 3} {
+		if exchanges[i].Seq != want {
+			t.Errorf("exchange %d: expected seq %d, got %d", i, want, exchanges[i].Seq)
+		}
+	}
+}
+
+func TestDiskCaptureStore(t *testing.T) {
+	dir := t.TempDir()
+
+	store, err := NewDiskCaptureStore(dir, 3)
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	for i := 0; i < 4; i++ {
+		if err := store.Add(CapturedExchange{Method: http.MethodGet}); err != nil {
+			t.Fatalf("failed to add: %v", err)
+		}
+	}
+
+	// После рестарта нумерация продолжается, старейший слот перезаписывается
+	store, err = NewDiskCaptureStore(dir, 3)
+	if err != nil {
+		t.Fatalf("failed to reopen store: %v", err)
+	}
+	store.Add(CapturedExchange{Method: http.MethodPost})
+
+	exchanges, err := store.List()
+	if err != nil {
+		t.Fatalf("failed to list: %v", err)
+	}
+	if len(exchanges) != 3 {
+		t.Fatalf("expected 3 exchanges, got %d", len(exchanges))
+	}
+	if exchanges[0].Seq != 5 || exchanges[0].Method != http.MethodPost || exchanges[2].Seq != 3 {
+		t.Errorf("unexpected exchanges: %+v", exchanges)
+	}
+}
+
+func TestCaptureHandler(t *testing.T) {
+	capture := NewCaptureRoundTripper(nil, CaptureConfig{})
+	for i := 0; i < 3; i++ {
+		capture.Store().Add(CapturedExchange{Method: http.MethodGet})
+	}
+
+	rec := httptest.NewRecorder()
+	capture.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/http-failures?limit=2", nil))
+
+	var exchanges []CapturedExchange
+	if err := json.Unmarshal(rec.Body.Bytes(), &exchanges); err != nil {
+		t.Fatalf("invalid response: %v", err)
+	}
+	if len(exchanges) != 2 || exchanges[0].Seq != 3 {
+		t.Errorf("unexpected exchanges: %+v", exchanges)
+	}
+}
@@ -42,14 +42,10 @@ func NewAPIClient(baseURL, apiKey string, logger httpclient.Logger) *APIClient {
 		MaxBodySize: 50 * 1024, // 50KB
 	}
 
-	rt := httpclient.NewLoggingRoundTripper(
-		http.DefaultTransport,
-		&httpclient.LoggingConfig{
-			Logger:          logger,
-			SanitizerConfig: sanitizerConfig,
-			LogBody:         true,
-		},
-	)
+	loggingConfig := httpclient.DefaultLoggingConfig(logger)
+	loggingConfig.SanitizerConfig = sanitizerConfig
+
+	rt := httpclient.NewLoggingRoundTripper(http.DefaultTransport, loggingConfig)
 
 	return &APIClient{
 		client: &http.Client{
@@ -292,13 +288,12 @@ func CreateProductionClient(logger httpclient.Logger) *http.Client {
 	tracingTransport := NewTracingRoundTripper(baseTransport)
 
 	// Добавляем логирование с санитизацией
-	loggingTransport := httpclient.NewLoggingRoundTripper(
-		tracingTransport,
-		&httpclient.LoggingConfig{
-			Logger:  logger,
-			LogBody: true, // В проде можно поставить false для performance
-		},
-	)
+	loggingConfig := httpclient.DefaultLoggingConfig(logger)
+	// В проде можно не логировать тела для performance
+	loggingConfig.ShouldLogBody = func(req *http.Request, contentType string, size int) bool {
+		return size <= 64*1024
+	}
+	loggingTransport := httpclient.NewLoggingRoundTripper(tracingTransport, loggingConfig)
 
 	return &http.Client{
 		Transport: loggingTransport,
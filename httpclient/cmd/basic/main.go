@@ -30,13 +30,7 @@ func basicExample() {
 	logger := httpclient.NewSimpleLogger(httpclient.DEBUG)
 
 	// Создаем RoundTripper с дефолтными настройками
-	rt := httpclient.NewLoggingRoundTripper(
-		http.DefaultTransport,
-		&httpclient.LoggingConfig{
-			Logger:  logger,
-			LogBody: true,
-		},
-	)
+	rt := httpclient.NewLoggingRoundTripper(http.DefaultTransport, httpclient.DefaultLoggingConfig(logger))
 
 	// Создаем HTTP клиент
 	client := &http.Client{
@@ -87,14 +81,10 @@ func customConfigExample() {
 		MaxBodySize: 5 * 1024, // 5KB лимит
 	}
 
-	rt := httpclient.NewLoggingRoundTripper(
-		http.DefaultTransport,
-		&httpclient.LoggingConfig{
-			Logger:          logger,
-			SanitizerConfig: sanitizerConfig,
-			LogBody:         true,
-		},
-	)
+	loggingConfig := httpclient.DefaultLoggingConfig(logger)
+	loggingConfig.SanitizerConfig = sanitizerConfig
+
+	rt := httpclient.NewLoggingRoundTripper(http.DefaultTransport, loggingConfig)
 
 	client := &http.Client{Transport: rt}
 
@@ -117,13 +107,7 @@ func differentContentTypesExample() {
 	fmt.Println("\n=== Different Content Types Example ===")
 
 	logger := httpclient.NewSimpleLogger(httpclient.DEBUG)
-	rt := httpclient.NewLoggingRoundTripper(
-		http.DefaultTransport,
-		&httpclient.LoggingConfig{
-			Logger:  logger,
-			LogBody: true,
-		},
-	)
+	rt := httpclient.NewLoggingRoundTripper(http.DefaultTransport, httpclient.DefaultLoggingConfig(logger))
 	client := &http.Client{Transport: rt}
 
 	// 1. JSON объект
@@ -181,9 +181,23 @@ func NewFileUploadClient() *FileUploadClient {
 
 	rt := httpclient.NewLoggingRoundTripper(http.DefaultTransport, config)
 
+	// Прогресс загрузки и ограничение скорости до 5MB/s
+	progress := httpclient.NewProgressRoundTripper(rt, httpclient.ProgressConfig{
+		OnUpload: func(p httpclient.Progress) {
+			logger.Info("Upload progress",
+				"transferred", p.Transferred,
+				"percent", fmt.Sprintf("%.1f", p.Percent()),
+				"rate_kbps", int(p.BytesPerSecond/1024),
+				"done", p.Done,
+			)
+		},
+		Interval:      time.Second,
+		MaxUploadRate: 5 * 1024 * 1024,
+	})
+
 	return &FileUploadClient{
 		client: &http.Client{
-			Transport: rt,
+			Transport: progress,
 			Timeout:   5 * time.Minute, // Большой таймаут для файлов
 		},
 	}
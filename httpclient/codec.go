@@ -0,0 +1,53 @@
+package httpclient
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// BodyCodec декодирует бинарный формат тела в JSON-совместимое значение
+// (map[string]interface{}, []interface{}, string, числа), которое затем
+// санитизируется по полям и логируется как JSON
+type BodyCodec interface {
+	// Match проверяет что кодек умеет декодировать этот Content-Type
+	Match(contentType string) bool
+	// Decode декодирует тело
+	Decode(contentType string, body []byte) (interface{}, error)
+}
+
+// DefaultBodyCodecs кодеки, не требующие настройки
+func DefaultBodyCodecs() []BodyCodec {
+	return []BodyCodec{MsgpackCodec{}}
+}
+
+// decodeWithCodecs декодирует тело первым подходящим кодеком
+func decodeWithCodecs(codecs []BodyCodec, contentType string, body []byte) (interface{}, bool) {
+	for _, codec := range codecs {
+		if !codec.Match(contentType) {
+			continue
+		}
+		decoded, err := codec.Decode(contentType, body)
+		if err != nil {
+			continue
+		}
+		return decoded, true
+	}
+	return nil, false
+}
+
+// marshalDecoded сериализует санитизированное значение в JSON для лога
+func marshalDecoded(value interface{}) (string, bool) {
+	result, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return "", false
+	}
+	return string(result), true
+}
+
+// mediaType возвращает Content-Type без параметров в нижнем регистре
+func mediaType(contentType string) string {
+	if i := strings.IndexByte(contentType, ';'); i >= 0 {
+		contentType = contentType[:i]
+	}
+	return strings.ToLower(strings.TrimSpace(contentType))
+}
@@ -0,0 +1,65 @@
+package httpclient
+
+import "context"
+
+type contextKey int
+
+const (
+	bodyLoggingKey contextKey = iota
+	logLevelKey
+	requestIDKey
+	pathTemplateKey
+)
+
+// RequestIDHeader заголовок для корреляции входящих и исходящих запросов
+const RequestIDHeader = "X-Request-ID"
+
+// WithBodyLogging включает или отключает логирование body для запросов с этим контекстом,
+// переопределяя LogRequestBody/LogResponseBody из LoggingConfig
+func WithBodyLogging(ctx context.Context, enabled bool) context.Context {
+	return context.WithValue(ctx, bodyLoggingKey, enabled)
+}
+
+// WithLogLevel задает уровень, с которым логируются запрос и ответ.
+// Например WithLogLevel(ctx, DEBUG) скрывает логи массовых операций при уровне INFO.
+// Ошибки транспорта всегда логируются как ERROR
+func WithLogLevel(ctx context.Context, level LogLevel) context.Context {
+	return context.WithValue(ctx, logLevelKey, level)
+}
+
+// WithRequestID сохраняет ID входящего запроса, он передается в исходящие
+// запросы заголовком X-Request-ID и попадает в логи клиента.
+// Обычно вызывается middleware.RequestIDMiddleware
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey, requestID)
+}
+
+// RequestIDFromContext возвращает ID запроса из контекста
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey).(string)
+	return requestID
+}
+
+// WithPathTemplate задает шаблон пути запроса, например "/users/{id}",
+// под которым запрос учитывается в SLOTracker
+func WithPathTemplate(ctx context.Context, template string) context.Context {
+	return context.WithValue(ctx, pathTemplateKey, template)
+}
+
+// pathTemplateFromContext возвращает шаблон пути из контекста
+func pathTemplateFromContext(ctx context.Context) string {
+	template, _ := ctx.Value(pathTemplateKey).(string)
+	return template
+}
+
+// bodyLoggingFromContext возвращает переопределение логирования body
+func bodyLoggingFromContext(ctx context.Context) (bool, bool) {
+	enabled, ok := ctx.Value(bodyLoggingKey).(bool)
+	return enabled, ok
+}
+
+// logLevelFromContext возвращает переопределение уровня логов
+func logLevelFromContext(ctx context.Context) (LogLevel, bool) {
+	level, ok := ctx.Value(logLevelKey).(LogLevel)
+	return level, ok
+}
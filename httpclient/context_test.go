@@ -0,0 +1,97 @@
+package httpclient
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// recordingLogger запоминает записи для проверок в тестах
+type recordingLogger struct {
+	mu      sync.Mutex
+	entries []string
+}
+
+func (r *recordingLogger) Debug(msg string, fields ...interface{}) { r.add("DEBUG", msg, fields) }
+func (r *recordingLogger) Info(msg string, fields ...interface{})  { r.add("INFO", msg, fields) }
+func (r *recordingLogger) Error(msg string, fields ...interface{}) { r.add("ERROR", msg, fields) }
+
+func (r *recordingLogger) add(level, msg string, fields []interface{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries = append(r.entries, fmt.Sprintf("%s %s %v", level, msg, fields))
+}
+
+func (r *recordingLogger) all() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return strings.Join(r.entries, "\n")
+}
+
+func TestContextLoggingOverrides(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"result":"response-body"}`)
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name       string
+		ctx        func(context.Context) context.Context
+		wantBody   bool
+		wantLevels []string
+	}{
+		{
+			name:       "defaults",
+			ctx:        func(ctx context.Context) context.Context { return ctx },
+			wantBody:   true,
+			wantLevels: []string{"INFO → HTTP Request", "DEBUG ← HTTP Response"},
+		},
+		{
+			name:       "body logging disabled",
+			ctx:        func(ctx context.Context) context.Context { return WithBodyLogging(ctx, false) },
+			wantBody:   false,
+			wantLevels: []string{"INFO → HTTP Request", "DEBUG ← HTTP Response"},
+		},
+		{
+			name:       "level override",
+			ctx:        func(ctx context.Context) context.Context { return WithLogLevel(ctx, DEBUG) },
+			wantBody:   true,
+			wantLevels: []string{"DEBUG → HTTP Request", "DEBUG ← HTTP Response"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			log := &recordingLogger{}
+			client := &http.Client{
+				Transport: NewLoggingRoundTripper(nil, DefaultLoggingConfig(log)),
+			}
+
+			req, _ := http.NewRequestWithContext(tt.ctx(context.Background()), http.MethodPost, srv.URL,
+				strings.NewReader(`{"query":"request-body"}`))
+			req.Header.Set("Content-Type", "application/json")
+
+			resp, err := client.Do(req)
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			resp.Body.Close()
+
+			out := log.all()
+			for _, want := range tt.wantLevels {
+				if !strings.Contains(out, want) {
+					t.Errorf("expected %q in logs:\n%s", want, out)
+				}
+			}
+			if got := strings.Contains(out, "request-body") && strings.Contains(out, "response-body"); got != tt.wantBody {
+				t.Errorf("body logged = %v, want %v:\n%s", got, tt.wantBody, out)
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package httpclient
+
+import "strings"
+
+// isCookieHeader проверяет заголовки, значения которых разбираются по отдельным cookie
+func isCookieHeader(key string) bool {
+	return strings.EqualFold(key, "Cookie") || strings.EqualFold(key, "Set-Cookie")
+}
+
+// sanitizeCookies скрывает значения cookie кроме VisibleCookies, оставляя
+// имена, флаги (Secure, HttpOnly, SameSite) и срок действия
+func (s *Sanitizer) sanitizeCookies(key string, values []string) string {
+	setCookie := strings.EqualFold(key, "Set-Cookie")
+
+	result := make([]string, len(values))
+	for i, value := range values {
+		parts := strings.Split(value, ";")
+		for j, part := range parts {
+			// В Set-Cookie только первая часть - name=value, остальные атрибуты
+			if setCookie && j > 0 {
+				break
+			}
+
+			eq := strings.IndexByte(part, '=')
+			if eq < 0 {
+				continue
+			}
+			name := strings.TrimSpace(part[:eq])
+			if part[eq+1:] == "" || !s.isHiddenCookie(name) {
+				continue
+			}
+			parts[j] = part[:eq+1] + s.maskHeaderValue([]string{strings.TrimSpace(part[eq+1:])})
+		}
+		result[i] = strings.Join(parts, ";")
+	}
+	return strings.Join(result, ", ")
+}
+
+// isHiddenCookie проверяет имя cookie: скрывается все, кроме VisibleCookies.
+// Имена, содержащие SensitiveFields, скрываются даже из VisibleCookies
+func (s *Sanitizer) isHiddenCookie(name string) bool {
+	if sensitive, ok := s.fields.match(name); ok {
+		s.observeRedaction(RedactionCookie, sensitive, name, 1)
+		return true
+	}
+	for _, visible := range s.config.VisibleCookies {
+		if strings.EqualFold(visible, name) {
+			return false
+		}
+	}
+	s.observeRedaction(RedactionCookie, cookieRuleDefault, name, 1)
+	return true
+}
+
+// cookieRuleDefault - правило в метриках для cookie вне VisibleCookies
+const cookieRuleDefault = "*"
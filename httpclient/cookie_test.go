@@ -0,0 +1,83 @@
+package httpclient
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSanitizeCookieHeaderMasksAllByDefault(t *testing.T) {
+	config := DefaultSanitizerConfig()
+	config.HeaderMaskMode = HeaderMaskFull
+
+	got := NewSanitizer(config).SanitizeHeaders(map[string][]string{
+		"Cookie": {"theme=dark; custom_sess=abc123"},
+	})["Cookie"]
+
+	want := "theme=***REDACTED***; custom_sess=***REDACTED***"
+	if got != want {
+		t.Errorf("got  %q\nwant %q", got, want)
+	}
+}
+
+func TestSanitizeCookieHeader(t *testing.T) {
+	config := DefaultSanitizerConfig()
+	config.HeaderMaskMode = HeaderMaskFull
+	config.VisibleCookies = []string{"theme", "Lang", "session_token"}
+
+	got := NewSanitizer(config).SanitizeHeaders(map[string][]string{
+		"Cookie": {"theme=dark; sid=abc123; lang=ru; session_token=xyz"},
+	})["Cookie"]
+
+	// session_token contains a sensitive field and stays hidden
+	want := "theme=dark; sid=***REDACTED***; lang=ru; session_token=***REDACTED***"
+	if got != want {
+		t.Errorf("got  %q\nwant %q", got, want)
+	}
+}
+
+func TestSanitizeSetCookieHeader(t *testing.T) {
+	config := DefaultSanitizerConfig()
+	config.HeaderMaskMode = HeaderMaskFull
+	config.VisibleCookies = []string{"theme"}
+
+	got := NewSanitizer(config).SanitizeHeaders(map[string][]string{
+		"Set-Cookie": {
+			"PHPSESSID=0123456789abcdef; Path=/; Expires=Wed, 21 Oct 2026 07:28:00 GMT; Secure; HttpOnly; SameSite=Lax",
+			"theme=dark; Path=/; Max-Age=3600",
+		},
+	})["Set-Cookie"]
+
+	want := "PHPSESSID=***REDACTED***; Path=/; Expires=Wed, 21 Oct 2026 07:28:00 GMT; Secure; HttpOnly; SameSite=Lax, " +
+		"theme=dark; Path=/; Max-Age=3600"
+	if got != want {
+		t.Errorf("got  %q\nwant %q", got, want)
+	}
+}
+
+func TestSanitizeCookiePartialMask(t *testing.T) {
+	got := NewSanitizer(nil).SanitizeHeaders(map[string][]string{
+		"Cookie": {"connect.sid=s%3Aabcdefghijklmnop"},
+	})["Cookie"]
+
+	if strings.Contains(got, "abcdefghijklmnop") || !strings.HasPrefix(got, "connect.sid=s%3A") {
+		t.Errorf("expected partially masked value, got %q", got)
+	}
+}
+
+func TestSanitizeCookieMetrics(t *testing.T) {
+	metrics := newRecordingSanitizerMetrics()
+	sanitizer := NewSanitizer(nil)
+	sanitizer.SetMetrics(metrics)
+
+	sanitizer.SanitizeHeaders(map[string][]string{"Cookie": {"sid=1; theme=dark; auth_token=2"}})
+
+	if metrics.redacted["cookie/*"] != 2 || metrics.redacted["cookie/token"] != 1 {
+		t.Errorf("unexpected cookie metrics %v", metrics.redacted)
+	}
+	if _, ok := metrics.redacted["header/cookie"]; !ok {
+		t.Error("cookie header must stay registered")
+	}
+	if metrics.redacted["header/cookie"] != 0 {
+		t.Error("cookie header must not be counted as wholesale redaction")
+	}
+}
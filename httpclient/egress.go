@@ -0,0 +1,99 @@
+package httpclient
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+	"strings"
+)
+
+// ErrEgressDenied возвращается когда соединение запрещено политикой
+var ErrEgressDenied = errors.New("httpclient: egress denied by policy")
+
+// EgressDeniedError подробности отказа в соединении
+type EgressDeniedError struct {
+	Host   string
+	IP     string
+	Reason string
+}
+
+func (e *EgressDeniedError) Error() string {
+	return fmt.Sprintf("httpclient: egress to %s (%s) denied: %s", e.Host, e.IP, e.Reason)
+}
+
+func (e *EgressDeniedError) Is(target error) bool {
+	return target == ErrEgressDenied
+}
+
+// EgressPolicy ограничивает адреса, к которым разрешены исходящие соединения.
+// Проверка выполняется при установке соединения по фактическому IP, поэтому
+// защищает и от DNS rebinding, когда разрешенное имя резолвится во внутренний адрес
+type EgressPolicy struct {
+	// Разрешенные хосты, "*.example.com" разрешает все поддомены.
+	// Пустые AllowHosts и AllowCIDRs - разрешены все адреса
+	AllowHosts []string
+	// Разрешенные подсети, имеют приоритет над DenyPrivate
+	AllowCIDRs []netip.Prefix
+	// Запретить loopback, приватные, link-local и прочие внутренние адреса
+	DenyPrivate bool
+}
+
+// Check проверяет разрешено ли соединение с хостом по адресу ip
+func (p *EgressPolicy) Check(host string, ip netip.Addr) error {
+	ip = ip.Unmap()
+
+	inAllowedNet := false
+	for _, prefix := range p.AllowCIDRs {
+		if prefix.Contains(ip) {
+			inAllowedNet = true
+			break
+		}
+	}
+
+	if (len(p.AllowHosts) > 0 || len(p.AllowCIDRs) > 0) && !inAllowedNet && !p.hostAllowed(host) {
+		return &EgressDeniedError{Host: host, IP: ip.String(), Reason: "not in allowlist"}
+	}
+
+	if p.DenyPrivate && !inAllowedNet && isInternalAddr(ip) {
+		return &EgressDeniedError{Host: host, IP: ip.String(), Reason: "internal address"}
+	}
+
+	return nil
+}
+
+// hostAllowed проверяет хост по AllowHosts
+func (p *EgressPolicy) hostAllowed(host string) bool {
+	for _, pattern := range p.AllowHosts {
+		if matchHost(host, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchHost сравнивает хост с шаблоном, "*.example.com" совпадает со всеми
+// поддоменами
+func matchHost(host, pattern string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	pattern = strings.ToLower(pattern)
+
+	if suffix, ok := strings.CutPrefix(pattern, "*."); ok {
+		return strings.HasSuffix(host, "."+suffix)
+	}
+	return host == pattern
+}
+
+// cgnat диапазон Carrier-Grade NAT (RFC 6598)
+var cgnat = netip.MustParsePrefix("100.64.0.0/10")
+
+// isInternalAddr проверяет что адрес не маршрутизируется в интернет
+func isInternalAddr(ip netip.Addr) bool {
+	return ip.IsLoopback() ||
+		ip.IsPrivate() ||
+		ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() ||
+		ip.IsMulticast() ||
+		ip.IsUnspecified() ||
+		cgnat.Contains(ip)
+}
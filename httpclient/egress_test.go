@@ -0,0 +1,68 @@
+package httpclient
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"net/url"
+	"testing"
+)
+
+func TestEgressPolicyCheck(t *testing.T) {
+	policy := &EgressPolicy{
+		AllowHosts:  []string{"api.example.com", "*.partner.io"},
+		AllowCIDRs:  []netip.Prefix{netip.MustParsePrefix("10.20.0.0/16")},
+		DenyPrivate: true,
+	}
+
+	tests := []struct {
+		name    string
+		host    string
+		ip      string
+		allowed bool
+	}{
+		{"allowed host", "api.example.com", "93.184.216.34", true},
+		{"wildcard subdomain", "eu.partner.io", "93.184.216.35", true},
+		{"wildcard apex", "partner.io", "93.184.216.35", false},
+		{"unknown host", "evil.com", "93.184.216.36", false},
+		{"allowed host rebound to loopback", "api.example.com", "127.0.0.1", false},
+		{"metadata endpoint", "api.example.com", "169.254.169.254", false},
+		{"allowed cidr", "db.internal", "10.20.1.5", true},
+		{"mapped ipv6 loopback", "api.example.com", "::ffff:127.0.0.1", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := policy.Check(tt.host, netip.MustParseAddr(tt.ip))
+			if tt.allowed && err != nil {
+				t.Errorf("expected allowed, got %v", err)
+			}
+			if !tt.allowed && !errors.Is(err, ErrEgressDenied) {
+				t.Errorf("expected ErrEgressDenied, got %v", err)
+			}
+		})
+	}
+}
+
+func TestTransportEgressPolicy(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	u, _ := url.Parse(srv.URL)
+
+	config := DefaultTransportConfig()
+	config.Resolver = NewStaticResolver(map[string][]string{
+		"public.example.com": {u.Hostname()},
+	}, nil)
+	config.Egress = &EgressPolicy{DenyPrivate: true}
+
+	client := &http.Client{Transport: NewTransport(config)}
+
+	for _, target := range []string{srv.URL, "http://public.example.com:" + u.Port()} {
+		_, err := client.Get(target)
+		if !errors.Is(err, ErrEgressDenied) {
+			t.Errorf("GET %s: expected ErrEgressDenied, got %v", target, err)
+		}
+	}
+}
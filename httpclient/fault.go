@@ -0,0 +1,354 @@
+package httpclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"os"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// FaultType вид внедряемого сбоя
+type FaultType string
+
+const (
+	// FaultLatency задержка перед запросом, совмещается с другими сбоями
+	FaultLatency FaultType = "latency"
+	// FaultReset ошибка соединения ECONNRESET без вызова upstream
+	FaultReset FaultType = "reset"
+	// FaultStatus ответ со статусом Status без вызова upstream
+	FaultStatus FaultType = "status"
+	// FaultMalformedBody ответ upstream с обрезанным телом
+	FaultMalformedBody FaultType = "malformed_body"
+)
+
+// FaultInjectedHeader заголовок синтетических и испорченных ответов с именем правила
+const FaultInjectedHeader = "X-Fault-Injected"
+
+// FaultRule правило внедрения сбоя. Пустые Host, Path и Methods совпадают
+// со всеми запросами
+type FaultRule struct {
+	Name string    `json:"name"`
+	Type FaultType `json:"type"`
+	// Probability вероятность сбоя от 0 до 1
+	Probability float64 `json:"probability"`
+	// Host "api.x.com" или "*.x.com"
+	Host string `json:"host,omitempty"`
+	// Path шаблон path.Match, например "/v1/orders/*"
+	Path    string   `json:"path,omitempty"`
+	Methods []string `json:"methods,omitempty"`
+	// Latency и случайная добавка до LatencyJitter для FaultLatency
+	Latency       time.Duration `json:"-"`
+	LatencyJitter time.Duration `json:"-"`
+	// Status для FaultStatus, по умолчанию 503
+	Status int `json:"status,omitempty"`
+}
+
+// faultRuleJSON задержки в JSON строками вида "200ms"
+type faultRuleJSON struct {
+	faultRuleAlias
+	Latency       string `json:"latency,omitempty"`
+	LatencyJitter string `json:"latency_jitter,omitempty"`
+}
+
+// faultRuleAlias FaultRule без методов JSON
+type faultRuleAlias FaultRule
+
+// MarshalJSON пишет задержки строками
+func (r FaultRule) MarshalJSON() ([]byte, error) {
+	wire := faultRuleJSON{faultRuleAlias: faultRuleAlias(r)}
+	if r.Latency > 0 {
+		wire.Latency = r.Latency.String()
+	}
+	if r.LatencyJitter > 0 {
+		wire.LatencyJitter = r.LatencyJitter.String()
+	}
+	return json.Marshal(wire)
+}
+
+// UnmarshalJSON читает задержки строками
+func (r *FaultRule) UnmarshalJSON(data []byte) error {
+	var wire faultRuleJSON
+	if err := json.Unmarshal(data, &wire); err != nil {
+		return err
+	}
+	*r = FaultRule(wire.faultRuleAlias)
+
+	var err error
+	if wire.Latency != "" {
+		if r.Latency, err = time.ParseDuration(wire.Latency); err != nil {
+			return fmt.Errorf("invalid latency: %w", err)
+		}
+	}
+	if wire.LatencyJitter != "" {
+		if r.LatencyJitter, err = time.ParseDuration(wire.LatencyJitter); err != nil {
+			return fmt.Errorf("invalid latency_jitter: %w", err)
+		}
+	}
+	return nil
+}
+
+// Validate проверяет правило
+func (r FaultRule) Validate() error {
+	switch r.Type {
+	case FaultLatency:
+		if r.Latency <= 0 && r.LatencyJitter <= 0 {
+			return fmt.Errorf("fault rule %q: latency is required", r.Name)
+		}
+	case FaultReset, FaultMalformedBody:
+	case FaultStatus:
+		if r.Status != 0 && (r.Status < 100 || r.Status > 599) {
+			return fmt.Errorf("fault rule %q: invalid status %d", r.Name, r.Status)
+		}
+	default:
+		return fmt.Errorf("fault rule %q: unknown type %q", r.Name, r.Type)
+	}
+	if r.Probability < 0 || r.Probability > 1 {
+		return fmt.Errorf("fault rule %q: probability must be between 0 and 1", r.Name)
+	}
+	if _, err := path.Match(r.Path, "/"); err != nil {
+		return fmt.Errorf("fault rule %q: invalid path pattern: %w", r.Name, err)
+	}
+	return nil
+}
+
+// matches проверяет, относится ли правило к запросу
+func (r FaultRule) matches(req *http.Request) bool {
+	if r.Host != "" && !matchHost(req.URL.Hostname(), r.Host) {
+		return false
+	}
+	if r.Path != "" {
+		if ok, _ := path.Match(r.Path, req.URL.Path); !ok {
+			return false
+		}
+	}
+	if len(r.Methods) > 0 && !slices.ContainsFunc(r.Methods, func(m string) bool {
+		return strings.EqualFold(m, req.Method)
+	}) {
+		return false
+	}
+	return true
+}
+
+// FaultConfig конфигурация FaultInjectionRoundTripper
+type FaultConfig struct {
+	Rules []FaultRule
+	// Enabled включает сбои сразу, иначе после Enable
+	Enabled bool
+	// OnFault вызывается при каждом внедренном сбое, например для логов
+	OnFault func(rule FaultRule, req *http.Request)
+}
+
+// FaultInjectionRoundTripper внедряет задержки, обрывы соединения, 5xx и
+// испорченные тела ответов, чтобы проверить повторы и circuit breaker
+// сервиса. Правила и включение меняются на лету, например через Handler.
+// Ставится ближе к транспорту, под RetryRoundTripper и BreakerRoundTripper
+type FaultInjectionRoundTripper struct {
+	next    http.RoundTripper
+	onFault func(rule FaultRule, req *http.Request)
+	random  func() float64
+
+	enabled atomic.Bool
+	rules   atomic.Pointer[[]FaultRule]
+}
+
+// NewFaultInjectionRoundTripper создает RoundTripper со сбоями, ошибка
+// возвращается для неверных правил
+func NewFaultInjectionRoundTripper(next http.RoundTripper, config FaultConfig) (*FaultInjectionRoundTripper, error) {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	f := &FaultInjectionRoundTripper{
+		next:    next,
+		onFault: config.OnFault,
+		random:  rand.Float64,
+	}
+	if err := f.SetRules(config.Rules); err != nil {
+		return nil, err
+	}
+	f.enabled.Store(config.Enabled)
+	return f, nil
+}
+
+// Enable включает внедрение сбоев
+func (f *FaultInjectionRoundTripper) Enable() {
+	f.enabled.Store(true)
+}
+
+// Disable выключает внедрение сбоев, запросы проходят без изменений
+func (f *FaultInjectionRoundTripper) Disable() {
+	f.enabled.Store(false)
+}
+
+// Enabled сообщает, включены ли сбои
+func (f *FaultInjectionRoundTripper) Enabled() bool {
+	return f.enabled.Load()
+}
+
+// Rules возвращает текущие правила
+func (f *FaultInjectionRoundTripper) Rules() []FaultRule {
+	return slices.Clone(*f.rules.Load())
+}
+
+// SetRules заменяет правила, при ошибке текущие правила не меняются
+func (f *FaultInjectionRoundTripper) SetRules(rules []FaultRule) error {
+	for _, rule := range rules {
+		if err := rule.Validate(); err != nil {
+			return err
+		}
+	}
+	rules = slices.Clone(rules)
+	f.rules.Store(&rules)
+	return nil
+}
+
+// RoundTrip выполняет запрос, внедряя сбои совпавших правил. Все задержки
+// применяются, из остальных сбоев срабатывает первый
+func (f *FaultInjectionRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !f.enabled.Load() {
+		return f.next.RoundTrip(req)
+	}
+
+	var malformed *FaultRule
+rules:
+	for _, rule := range *f.rules.Load() {
+		if !rule.matches(req) || f.random() >= rule.Probability {
+			continue
+		}
+		f.observe(rule, req)
+
+		switch rule.Type {
+		case FaultLatency:
+			if err := sleepContext(req.Context(), rule.latency(f.random)); err != nil {
+				closeRequestBody(req)
+				return nil, err
+			}
+		case FaultReset:
+			closeRequestBody(req)
+			return nil, &net.OpError{Op: "read", Net: "tcp", Err: os.NewSyscallError("read", syscall.ECONNRESET)}
+		case FaultStatus:
+			closeRequestBody(req)
+			return faultResponse(req, rule), nil
+		case FaultMalformedBody:
+			malformed = &rule
+			break rules
+		}
+	}
+
+	resp, err := f.next.RoundTrip(req)
+	if err != nil || malformed == nil {
+		return resp, err
+	}
+	return malformBody(resp, *malformed)
+}
+
+func (f *FaultInjectionRoundTripper) observe(rule FaultRule, req *http.Request) {
+	if f.onFault != nil {
+		f.onFault(rule, req)
+	}
+}
+
+// Handler отдает состояние на GET и заменяет его на PUT с телом
+// {"enabled": true, "rules": [...]}. Подключается к админскому серверу:
+//
+//	app.All("/debug/faults", adaptor.HTTPHandler(faults.Handler()))
+func (f *FaultInjectionRoundTripper) Handler() http.Handler {
+	type state struct {
+		Enabled bool        `json:"enabled"`
+		Rules   []FaultRule `json:"rules"`
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut:
+			var s state
+			if err := json.NewDecoder(io.LimitReader(r.Body, 1<<20)).Decode(&s); err != nil {
+				http.Error(w, "invalid body: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+			if err := f.SetRules(s.Rules); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			f.enabled.Store(s.Enabled)
+		default:
+			w.Header().Set("Allow", "GET, PUT")
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(state{Enabled: f.Enabled(), Rules: f.Rules()})
+	})
+}
+
+// latency возвращает задержку со случайной добавкой
+func (r FaultRule) latency(random func() float64) time.Duration {
+	return r.Latency + time.Duration(random()*float64(r.LatencyJitter))
+}
+
+// faultResponse синтетический ответ для FaultStatus
+func faultResponse(req *http.Request, rule FaultRule) *http.Response {
+	status := rule.Status
+	if status == 0 {
+		status = http.StatusServiceUnavailable
+	}
+	body := `{"error":"fault injected"}`
+
+	header := make(http.Header)
+	header.Set("Content-Type", "application/json")
+	header.Set("Content-Length", strconv.Itoa(len(body)))
+	header.Set(FaultInjectedHeader, rule.Name)
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
+
+// malformBody обрезает тело ответа наполовину, JSON и XML перестают разбираться
+func malformBody(resp *http.Response, rule FaultRule) (*http.Response, error) {
+	data, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	data = data[:len(data)/2]
+	if len(data) == 0 {
+		data = []byte("{")
+	}
+
+	resp.Body = io.NopCloser(bytes.NewReader(data))
+	resp.ContentLength = int64(len(data))
+	resp.Header.Set("Content-Length", strconv.Itoa(len(data)))
+	resp.Header.Set(FaultInjectedHeader, rule.Name)
+	return resp, nil
+}
+
+// closeRequestBody закрывает тело неотправленного запроса, как это
+// сделал бы транспорт
+func closeRequestBody(req *http.Request) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+}
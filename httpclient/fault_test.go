@@ -0,0 +1,127 @@
+package httpclient
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestFaultInjectionRoundTripper(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"status":"ok"}`)
+	}))
+	defer srv.Close()
+
+	faults, err := NewFaultInjectionRoundTripper(nil, FaultConfig{
+		Rules: []FaultRule{
+			{Name: "slow", Type: FaultLatency, Probability: 1, Latency: 20 * time.Millisecond},
+			{Name: "reset", Type: FaultReset, Probability: 1, Path: "/reset"},
+			{Name: "down", Type: FaultStatus, Probability: 1, Path: "/orders/*", Methods: []string{"POST"}},
+			{Name: "broken", Type: FaultMalformedBody, Probability: 1, Path: "/broken"},
+			{Name: "never", Type: FaultStatus, Probability: 0},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create: %v", err)
+	}
+	client := &http.Client{Transport: faults}
+
+	// Выключен по умолчанию
+	resp, err := client.Post(srv.URL+"/orders/1", "application/json", nil)
+	if err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected pass-through when disabled, got %v %v", resp, err)
+	}
+	resp.Body.Close()
+
+	faults.Enable()
+
+	start := time.Now()
+	resp, err = client.Post(srv.URL+"/orders/1", "application/json", nil)
+	if err != nil || resp.StatusCode != http.StatusServiceUnavailable || resp.Header.Get(FaultInjectedHeader) != "down" {
+		t.Fatalf("expected injected 503, got %v %v", resp, err)
+	}
+	resp.Body.Close()
+	if time.Since(start) < 20*time.Millisecond {
+		t.Error("latency not injected")
+	}
+
+	resp, err = client.Get(srv.URL + "/orders/1")
+	if err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("method filter ignored: %v %v", resp, err)
+	}
+	resp.Body.Close()
+
+	if _, err := client.Get(srv.URL + "/reset"); !errors.Is(err, syscall.ECONNRESET) {
+		t.Errorf("expected ECONNRESET, got %v", err)
+	}
+
+	resp, err = client.Get(srv.URL + "/broken")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	var body map[string]string
+	if err := json.NewDecoder(resp.Body).Decode(&body); err == nil {
+		t.Errorf("expected malformed body, got %v", body)
+	}
+	resp.Body.Close()
+}
+
+func TestFaultInjectionProbability(t *testing.T) {
+	faults, _ := NewFaultInjectionRoundTripper(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody, Header: make(http.Header)}, nil
+	}), FaultConfig{
+		Enabled: true,
+		Rules:   []FaultRule{{Name: "flaky", Type: FaultStatus, Probability: 0.3, Host: "*.x.com", Status: 502}},
+	})
+	values := []float64{0.1, 0.5, 0.29, 0.9}
+	faults.random = func() float64 {
+		v := values[0]
+		values = values[1:]
+		return v
+	}
+
+	var statuses []int
+	for range 4 {
+		req, _ := http.NewRequest(http.MethodGet, "https://api.x.com/", nil)
+		resp, _ := faults.RoundTrip(req)
+		statuses = append(statuses, resp.StatusCode)
+	}
+	if want := []int{502, 200, 502, 200}; !slices.Equal(statuses, want) {
+		t.Errorf("expected %v, got %v", want, statuses)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "https://api.y.com/", nil)
+	if resp, _ := faults.RoundTrip(req); resp.StatusCode != http.StatusOK {
+		t.Error("host filter ignored")
+	}
+}
+
+func TestFaultInjectionHandler(t *testing.T) {
+	faults, _ := NewFaultInjectionRoundTripper(nil, FaultConfig{})
+
+	rec := httptest.NewRecorder()
+	body := `{"enabled":true,"rules":[{"name":"slow","type":"latency","probability":0.5,"latency":"150ms"}]}`
+	faults.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/debug/faults", strings.NewReader(body)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("unexpected status %d: %s", rec.Code, rec.Body)
+	}
+	if !faults.Enabled() || faults.Rules()[0].Latency != 150*time.Millisecond {
+		t.Errorf("state not applied: %v %+v", faults.Enabled(), faults.Rules())
+	}
+	if !strings.Contains(rec.Body.String(), `"latency":"150ms"`) {
+		t.Errorf("unexpected response: %s", rec.Body)
+	}
+
+	rec = httptest.NewRecorder()
+	faults.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/debug/faults", strings.NewReader(`{"rules":[{"type":"explode"}]}`)))
+	if rec.Code != http.StatusBadRequest || !faults.Enabled() {
+		t.Errorf("invalid rules accepted: %d", rec.Code)
+	}
+}
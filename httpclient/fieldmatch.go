@@ -0,0 +1,101 @@
+package httpclient
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// homoglyphs буквы кириллицы и греческого, неотличимые от латинских, после
+// приведения к нижнему регистру. Заглавные В, Н, М, Т, К похожи на латинские,
+// поэтому их строчные формы тоже здесь
+var homoglyphs = map[rune]rune{
+	// Кириллица
+	'а': 'a', 'в': 'b', 'е': 'e', 'ё': 'e', 'к': 'k', 'м': 'm', 'н': 'h',
+	'о': 'o', 'р': 'p', 'с': 'c', 'т': 't', 'у': 'y', 'х': 'x', 'ѕ': 's',
+	'і': 'i', 'ї': 'i', 'ј': 'j', 'ԁ': 'd', 'ԛ': 'q', 'ԝ': 'w', 'һ': 'h',
+	'ӏ': 'l', 'ү': 'y',
+	// Греческий
+	'α': 'a', 'β': 'b', 'ε': 'e', 'η': 'n', 'ι': 'i', 'κ': 'k', 'ν': 'v',
+	'ο': 'o', 'ρ': 'p', 'τ': 't', 'υ': 'u', 'χ': 'x', 'ζ': 'z',
+	// Латиница без точки
+	'ı': 'i', 'ȷ': 'j',
+}
+
+// foldFieldName приводит имя поля к виду для сравнения: NFKC (полноширинные
+// символы и лигатуры становятся ASCII), нижний регистр, только буквы и
+// цифры. "Pass Word", "pass_word" и "ｐａｓｓｗｏｒｄ" дают "password".
+// С withHomoglyphs кириллические и греческие двойники заменяются латиницей
+func foldFieldName(name string, withHomoglyphs bool) string {
+	if isASCII(name) {
+		var b strings.Builder
+		b.Grow(len(name))
+		for i := 0; i < len(name); i++ {
+			c := name[i]
+			switch {
+			case c >= 'a' && c <= 'z', c >= '0' && c <= '9':
+				b.WriteByte(c)
+			case c >= 'A' && c <= 'Z':
+				b.WriteByte(c + 'a' - 'A')
+			}
+		}
+		return b.String()
+	}
+
+	var b strings.Builder
+	for _, r := range strings.ToLower(norm.NFKC.String(name)) {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			// Пробелы, пунктуация, символы и невидимые (zero-width) символы
+			continue
+		}
+		if withHomoglyphs {
+			if latin, ok := homoglyphs[r]; ok {
+				r = latin
+			}
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= 0x80 {
+			return false
+		}
+	}
+	return true
+}
+
+// fieldMatcher ищет в имени поля вхождение одного из чувствительных имен
+// после foldFieldName, нормализованные имена считаются один раз
+type fieldMatcher struct {
+	fields         []string
+	folded         []string
+	withHomoglyphs bool
+}
+
+func newFieldMatcher(fields []string, withHomoglyphs bool) *fieldMatcher {
+	m := &fieldMatcher{withHomoglyphs: withHomoglyphs}
+	for _, field := range fields {
+		folded := foldFieldName(field, withHomoglyphs)
+		if folded == "" {
+			continue
+		}
+		m.fields = append(m.fields, field)
+		m.folded = append(m.folded, folded)
+	}
+	return m
+}
+
+// match возвращает совпавшее чувствительное имя в исходном виде
+func (m *fieldMatcher) match(name string) (string, bool) {
+	folded := foldFieldName(name, m.withHomoglyphs)
+	for i, sensitive := range m.folded {
+		if strings.Contains(folded, sensitive) {
+			return m.fields[i], true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,67 @@
+package httpclient
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFoldFieldName(t *testing.T) {
+	tests := []struct {
+		name       string
+		homoglyphs bool
+		want       string
+	}{
+		{"Password", false, "password"},
+		{"pass word", false, "password"},
+		{"pass-word_hash", false, "passwordhash"},
+		{"ｐａｓｓｗｏｒｄ", false, "password"},
+		{"pass\u200bword", false, "password"},
+		{"ＡＰＩ＿ＫＥＹ", false, "apikey"},
+		{"p\u0430ssword", false, "p\u0430ssword"},
+		{"p\u0430ssword", true, "password"},
+		{"ТОКЕН", true, "tokeh"},
+		{"s\u0435cr\u0435t", true, "secret"},
+		{"пароль", true, "пapoль"},
+	}
+
+	for _, tt := range tests {
+		if got := foldFieldName(tt.name, tt.homoglyphs); got != tt.want {
+			t.Errorf("foldFieldName(%q, %v) = %q, want %q", tt.name, tt.homoglyphs, got, tt.want)
+		}
+	}
+}
+
+func TestSanitizerUnicodeFieldMatching(t *testing.T) {
+	body := `{"pass word":"s1","ｐａｓｓｗｏｒｄ":"s2","api\u200b_key":"s3","p\u0430ssword":"s4","name":"visible"}`
+
+	plain := NewSanitizer(&SanitizerConfig{SensitiveFields: []string{"password", "api_key"}, Mask: "***"})
+	result := plain.SanitizeBody([]byte(body), "application/json")
+	for _, secret := range []string{`"s1"`, `"s2"`, `"s3"`} {
+		if strings.Contains(result, secret) {
+			t.Errorf("%s not redacted: %s", secret, result)
+		}
+	}
+	if !strings.Contains(result, `"s4"`) || !strings.Contains(result, "visible") {
+		t.Errorf("unexpected redaction without homoglyph folding: %s", result)
+	}
+
+	homoglyph := NewSanitizer(&SanitizerConfig{SensitiveFields: []string{"password"}, Mask: "***", HomoglyphFolding: true})
+	if result := homoglyph.SanitizeBody([]byte(body), "application/json"); strings.Contains(result, `"s4"`) {
+		t.Errorf("homoglyph field not redacted: %s", result)
+	}
+
+	noRegex := NewSanitizerNoRegex(&SanitizerConfigNoRegex{SensitiveFields: []string{"password"}, Mask: "***", HomoglyphFolding: true})
+	if result := noRegex.SanitizeBody([]byte(body), "application/json"); strings.Contains(result, `"s1"`) || strings.Contains(result, `"s4"`) {
+		t.Errorf("no-regex sanitizer missed fields: %s", result)
+	}
+}
+
+func TestParseSanitizerConfigHomoglyphs(t *testing.T) {
+	config, err := ParseSanitizerConfig([]byte("sensitive_fields: [password]\nhomoglyphs: true\n"))
+	if err != nil {
+		t.Fatalf("failed to parse: %v", err)
+	}
+	if !config.HomoglyphFolding {
+		t.Error("homoglyphs not enabled")
+	}
+}
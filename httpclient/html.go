@@ -0,0 +1,263 @@
+package httpclient
+
+import (
+	"fmt"
+	"strings"
+)
+
+// isHTML проверяет Content-Type HTML страницы
+func isHTML(contentType string) bool {
+	switch mediaType(contentType) {
+	case "text/html", "application/xhtml+xml":
+		return true
+	}
+	return false
+}
+
+// looksLikeHTML проверяет начало документа
+func looksLikeHTML(body string) bool {
+	head := strings.TrimSpace(body)
+	if len(head) > 64 {
+		head = head[:64]
+	}
+	head = strings.ToLower(head)
+	return strings.HasPrefix(head, "<!doctype html") || strings.HasPrefix(head, "<html")
+}
+
+// htmlAttr атрибут тега. start/end - границы значения внутри тега, -1 если значения нет
+type htmlAttr struct {
+	name       string
+	start, end int
+}
+
+// htmlTagValue значение атрибута без кавычек
+func htmlTagValue(tag string, attrs []htmlAttr, name string) string {
+	for _, attr := range attrs {
+		if attr.name == name && attr.start >= 0 {
+			return tag[attr.start:attr.end]
+		}
+	}
+	return ""
+}
+
+// inputKeepsValue типы input, значение которых - подпись, а не данные пользователя
+var inputKeepsValue = map[string]bool{
+	"submit": true, "button": true, "reset": true, "checkbox": true, "radio": true, "image": true,
+}
+
+// sanitizeHTML санитизирует HTML, сохраняя разметку:
+//   - value у input скрывается целиком (кроме кнопок, checkbox и radio), hidden токены тоже
+//   - content у meta и содержимое textarea скрываются, если name чувствительный
+//   - содержимое script заменяется описанием размера
+//   - к тексту и остальным атрибутам применяется sanitizeText по отдельности,
+//     чтобы паттерны не захватывали соседние теги
+func sanitizeHTML(body, mask string, isSensitive func(string) bool, sanitizeText func(string) string) string {
+	var sb strings.Builder
+	sb.Grow(len(body))
+
+	i := 0
+	for i < len(body) {
+		lt := strings.IndexByte(body[i:], '<')
+		if lt < 0 {
+			sb.WriteString(sanitizeText(body[i:]))
+			break
+		}
+		if lt > 0 {
+			sb.WriteString(sanitizeText(body[i : i+lt]))
+		}
+		i += lt
+
+		// Комментарии могут содержать что угодно, оставляем только текст
+		if strings.HasPrefix(body[i:], "<!--") {
+			end := strings.Index(body[i+4:], "-->")
+			if end < 0 {
+				sb.WriteString(sanitizeText(body[i:]))
+				break
+			}
+			sb.WriteString(sanitizeText(body[i : i+4+end+3]))
+			i += 4 + end + 3
+			continue
+		}
+
+		// Незакрытый тег в конце тела (например обрезанного) разбираем как тег
+		end := htmlTagEnd(body, i)
+		if end < 0 {
+			end = len(body)
+		}
+		tag := body[i:end]
+		i = end
+
+		name, attrs := parseHTMLTag(tag)
+		if name == "" {
+			// Не тег: "<" в тексте, </закрывающий> или <!doctype>
+			if strings.HasPrefix(tag, "</") || strings.HasPrefix(tag, "<!") {
+				sb.WriteString(tag)
+			} else {
+				sb.WriteString(sanitizeText(tag))
+			}
+			continue
+		}
+
+		sb.WriteString(sanitizeHTMLTag(tag, name, attrs, mask, isSensitive, sanitizeText))
+
+		switch name {
+		case "script", "style":
+			closeAt := htmlCloseTag(body, i, name)
+			content := body[i:closeAt]
+			if name == "script" && strings.TrimSpace(content) != "" {
+				fmt.Fprintf(&sb, "[script %s]", formatSize(len(content)))
+			} else {
+				sb.WriteString(content)
+			}
+			i = closeAt
+		case "textarea":
+			if field := htmlTagValue(tag, attrs, "name"); field != "" && isSensitive(field) {
+				closeAt := htmlCloseTag(body, i, name)
+				if closeAt > i {
+					sb.WriteString(mask)
+				}
+				i = closeAt
+			}
+		}
+	}
+
+	return sb.String()
+}
+
+// sanitizeHTMLTag скрывает значения атрибутов открывающего тега
+func sanitizeHTMLTag(tag, name string, attrs []htmlAttr, mask string, isSensitive func(string) bool, sanitizeText func(string) string) string {
+	var maskValue, maskContent bool
+	switch name {
+	case "input":
+		maskValue = !inputKeepsValue[strings.ToLower(htmlTagValue(tag, attrs, "type"))]
+	case "meta":
+		field := htmlTagValue(tag, attrs, "name")
+		if field == "" {
+			field = htmlTagValue(tag, attrs, "property")
+		}
+		maskContent = field != "" && isSensitive(field)
+	}
+
+	var sb strings.Builder
+	last := 0
+	for _, attr := range attrs {
+		if attr.start < 0 || attr.start == attr.end {
+			continue
+		}
+
+		value := tag[attr.start:attr.end]
+		switch {
+		case attr.name == "value" && maskValue,
+			attr.name == "content" && maskContent,
+			isSensitive(attr.name):
+			value = mask
+		default:
+			value = sanitizeText(value)
+		}
+
+		sb.WriteString(tag[last:attr.start])
+		sb.WriteString(value)
+		last = attr.end
+	}
+	sb.WriteString(tag[last:])
+	return sb.String()
+}
+
+// htmlTagEnd находит конец тега с учетом кавычек в атрибутах
+func htmlTagEnd(body string, start int) int {
+	var quote byte
+	for j := start + 1; j < len(body); j++ {
+		c := body[j]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '>':
+			return j + 1
+		case c == '<' && j == start+1:
+			return -1
+		}
+	}
+	return -1
+}
+
+// htmlCloseTag возвращает позицию закрывающего тега </name> или конец тела
+func htmlCloseTag(body string, from int, name string) int {
+	idx := strings.Index(strings.ToLower(body[from:]), "</"+name)
+	if idx < 0 {
+		return len(body)
+	}
+	return from + idx
+}
+
+// parseHTMLTag разбирает открывающий тег. Имена приводятся к нижнему регистру.
+// Для закрывающих тегов, doctype и "<" в тексте имя пустое
+func parseHTMLTag(tag string) (string, []htmlAttr) {
+	j := 1
+	for j < len(tag) && isHTMLNameChar(tag[j]) {
+		j++
+	}
+	if j == 1 {
+		return "", nil
+	}
+	return strings.ToLower(tag[1:j]), parseTagAttrs(tag, j)
+}
+
+// parseTagAttrs разбирает атрибуты тега начиная с позиции j после имени.
+// Имена атрибутов приводятся к нижнему регистру
+func parseTagAttrs(tag string, j int) []htmlAttr {
+	var attrs []htmlAttr
+	for j < len(tag) {
+		for j < len(tag) && (isSpace(tag[j]) || tag[j] == '/' || tag[j] == '>') {
+			j++
+		}
+		nameStart := j
+		for j < len(tag) && !isSpace(tag[j]) && tag[j] != '=' && tag[j] != '>' && tag[j] != '/' {
+			j++
+		}
+		if j == nameStart {
+			break
+		}
+		attr := htmlAttr{name: strings.ToLower(tag[nameStart:j]), start: -1, end: -1}
+
+		k := j
+		for k < len(tag) && isSpace(tag[k]) {
+			k++
+		}
+		if k < len(tag) && tag[k] == '=' {
+			k++
+			for k < len(tag) && isSpace(tag[k]) {
+				k++
+			}
+			if k < len(tag) && (tag[k] == '"' || tag[k] == '\'') {
+				attr.start = k + 1
+				if closeAt := strings.IndexByte(tag[k+1:], tag[k]); closeAt >= 0 {
+					attr.end = attr.start + closeAt
+				} else {
+					attr.end = len(strings.TrimSuffix(tag, ">"))
+				}
+				k = attr.end + 1
+			} else {
+				attr.start = k
+				for k < len(tag) && !isSpace(tag[k]) && tag[k] != '>' {
+					k++
+				}
+				attr.end = k
+			}
+			j = k
+		}
+		attrs = append(attrs, attr)
+	}
+	return attrs
+}
+
+func isHTMLNameChar(c byte) bool {
+	return isAlnum(c) || c == '-' || c == ':'
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
@@ -0,0 +1,86 @@
+package httpclient
+
+import (
+	"strings"
+	"testing"
+)
+
+const testHTMLPage = `<!DOCTYPE html>
+<html>
+<head>
+  <meta name="csrf-token" content="c5rf-abc">
+  <meta charset="utf-8">
+  <script>var apiKey = "sk_live_abcdefghijklmnop";</script>
+  <script src="/app.js"></script>
+</head>
+<body class="login">
+  <form action="/login?next=/home" method="post">
+    <input type="hidden" name="_state" value="opaque-state">
+    <input type=text name=login value=alice>
+    <input type="password" name="password" value='hunter2'>
+    <input type="submit" value="Sign in">
+    <textarea name="secret">multi
+line</textarea>
+  </form>
+  <p>Header: Bearer abc.def.ghi</p>
+  <!-- token: Bearer xyz -->
+</body>
+</html>`
+
+func TestSanitizeHTML(t *testing.T) {
+	for _, sanitizer := range []interface {
+		SanitizeBody([]byte, string) string
+	}{NewSanitizer(nil), NewSanitizerNoRegex(nil)} {
+		got := sanitizer.SanitizeBody([]byte(testHTMLPage), "text/html; charset=utf-8")
+
+		for _, want := range []string{
+			`<meta name="csrf-token" content="***REDACTED***">`,
+			`<meta charset="utf-8">`,
+			`<script>[script 40 bytes]</script>`,
+			`<script src="/app.js"></script>`,
+			`<input type="hidden" name="_state" value="***REDACTED***">`,
+			`<input type=text name=login value=***REDACTED***>`,
+			`<input type="password" name="password" value='***REDACTED***'>`,
+			`<input type="submit" value="Sign in">`,
+			`<textarea name="secret">***REDACTED***</textarea>`,
+			`<form action="/login?next=/home" method="post">`,
+			`<p>Header: Bearer ***REDACTED***</p>`,
+			`<!-- token: Bearer ***REDACTED*** -->`,
+			`<!DOCTYPE html>`,
+		} {
+			if !strings.Contains(got, want) {
+				t.Errorf("%T: expected %s in\n%s", sanitizer, want, got)
+			}
+		}
+		for _, leaked := range []string{"c5rf-abc", "sk_live", "opaque-state", "alice", "hunter2", "multi"} {
+			if strings.Contains(got, leaked) {
+				t.Errorf("%T: %s leaked", sanitizer, leaked)
+			}
+		}
+	}
+}
+
+func TestSanitizeHTMLDetected(t *testing.T) {
+	body := `<html><body><input name="q" value="x"></body></html>`
+	got := NewSanitizer(nil).SanitizeBody([]byte(body), "")
+	if !strings.Contains(got, `value="***REDACTED***"`) {
+		t.Errorf("expected HTML detected without Content-Type, got %s", got)
+	}
+}
+
+func TestSanitizeHTMLMalformed(t *testing.T) {
+	for _, body := range []string{
+		`<html>a < b and c > d`,
+		`<html><input value="unterminated`,
+		`<html><script>never closed`,
+		`<html><<>>`,
+	} {
+		got := NewSanitizer(nil).SanitizeBody([]byte(body), "text/html")
+		if got == "" {
+			t.Errorf("%q: empty result", body)
+		}
+		if strings.Contains(got, "unterminated") || strings.Contains(got, "never closed") {
+			t.Errorf("%q: value leaked: %s", body, got)
+		}
+	}
+}
@@ -0,0 +1,266 @@
+package httpclient
+
+import "strings"
+
+// NationalIDDetector описывает национальный идентификатор фиксированной длины
+// из цифр, например ИИН Казахстана или SSN. Validate проверяет контрольную сумму
+type NationalIDDetector struct {
+	Name     string
+	Length   int
+	Validate func(digits string) bool
+}
+
+// IINDetector детектор казахстанского ИИН/БИН (12 цифр с контрольным разрядом)
+func IINDetector() NationalIDDetector {
+	return NationalIDDetector{
+		Name:     "kz_iin",
+		Length:   12,
+		Validate: ValidIIN,
+	}
+}
+
+// ValidIIN проверяет контрольный разряд ИИН/БИН и месяц в дате рождения/регистрации
+func ValidIIN(digits string) bool {
+	if len(digits) != 12 || strings.Trim(digits, "0123456789") != "" {
+		return false
+	}
+
+	month := (digits[2]-'0')*10 + (digits[3] - '0')
+	if month < 1 || month > 12 {
+		return false
+	}
+
+	check := iinChecksum(digits, []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11})
+	if check == 10 {
+		check = iinChecksum(digits, []int{3, 4, 5, 6, 7, 8, 9, 10, 11, 1, 2})
+	}
+	return check < 10 && check == int(digits[11]-'0')
+}
+
+func iinChecksum(digits string, weights []int) int {
+	sum := 0
+	for i, w := range weights {
+		sum += int(digits[i]-'0') * w
+	}
+	return sum % 11
+}
+
+// ValidIBAN проверяет IBAN по ISO 13616 (mod-97), пробелы допускаются
+func ValidIBAN(iban string) bool {
+	iban = strings.ReplaceAll(iban, " ", "")
+	if len(iban) < 15 || len(iban) > 34 {
+		return false
+	}
+	if !isUpper(iban[0]) || !isUpper(iban[1]) || !isDigit(iban[2]) || !isDigit(iban[3]) {
+		return false
+	}
+
+	// Переносим первые 4 символа в конец, буквы заменяем числами A=10..Z=35
+	rearranged := iban[4:] + iban[:4]
+	remainder := 0
+	for i := 0; i < len(rearranged); i++ {
+		ch := rearranged[i]
+		switch {
+		case isDigit(ch):
+			remainder = (remainder*10 + int(ch-'0')) % 97
+		case isUpper(ch):
+			remainder = (remainder*100 + int(ch-'A'+10)) % 97
+		default:
+			return false
+		}
+	}
+	return remainder == 1
+}
+
+// ValidBIC проверяет структуру SWIFT/BIC: 4 буквы банка, код страны,
+// 2 символа локации и опционально 3 символа филиала
+func ValidBIC(bic string) bool {
+	if len(bic) != 8 && len(bic) != 11 {
+		return false
+	}
+	for i := 0; i < 4; i++ {
+		if !isUpper(bic[i]) {
+			return false
+		}
+	}
+	if !isCountryCode(bic[4:6]) {
+		return false
+	}
+	for i := 6; i < len(bic); i++ {
+		if !isUpper(bic[i]) && !isDigit(bic[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// hideIBANs скрывает IBAN, оставляя код страны и 4 последних символа
+func hideIBANs(text, mask string) string {
+	var sb strings.Builder
+	last := 0
+
+	for i := 0; i+4 <= len(text); i++ {
+		if (i > 0 && isAlnum(text[i-1])) || !isUpper(text[i]) || !isUpper(text[i+1]) ||
+			!isDigit(text[i+2]) || !isDigit(text[i+3]) {
+			continue
+		}
+
+		end := scanIBAN(text, i)
+		if end < 0 {
+			continue
+		}
+
+		compact := strings.ReplaceAll(text[i:end], " ", "")
+		sb.WriteString(text[last:i])
+		sb.WriteString(compact[:2] + mask + compact[len(compact)-4:])
+		last = end
+		i = end - 1
+	}
+
+	if last == 0 {
+		return text
+	}
+	sb.WriteString(text[last:])
+	return sb.String()
+}
+
+// scanIBAN возвращает конец валидного IBAN начиная с start или -1.
+// IBAN может быть записан группами по 4 символа через пробел
+func scanIBAN(text string, start int) int {
+	var ends []int
+	count := 0
+
+scan:
+	for j := start; j < len(text) && count < 34; j++ {
+		ch := text[j]
+		switch {
+		case isUpper(ch) || isDigit(ch):
+			count++
+			if j+1 == len(text) || !isAlnum(text[j+1]) {
+				ends = append(ends, j+1)
+			}
+		case ch == ' ' && j > start && isAlnum(text[j-1]) && j+1 < len(text) && isAlnum(text[j+1]):
+		default:
+			break scan
+		}
+	}
+
+	// Предпочитаем самый длинный валидный вариант
+	for k := len(ends) - 1; k >= 0; k-- {
+		if ValidIBAN(text[start:ends[k]]) {
+			return ends[k]
+		}
+	}
+	return -1
+}
+
+// hideBICs скрывает SWIFT/BIC коды. Чтобы не принимать обычные слова
+// в верхнем регистре за BIC, код должен содержать цифру или рядом
+// должно стоять слово swift/bic
+func hideBICs(text, mask string) string {
+	var sb strings.Builder
+	last := 0
+
+	for i := 0; i < len(text); i++ {
+		if !isUpper(text[i]) || (i > 0 && isAlnum(text[i-1])) {
+			continue
+		}
+
+		end := i
+		for end < len(text) && isAlnum(text[end]) {
+			end++
+		}
+
+		word := text[i:end]
+		if ValidBIC(word) && (strings.ContainsAny(word[6:], "0123456789") || hasBICKeyword(text, i)) {
+			sb.WriteString(text[last:i])
+			sb.WriteString(mask)
+			last = end
+		}
+		i = end - 1
+	}
+
+	if last == 0 {
+		return text
+	}
+	sb.WriteString(text[last:])
+	return sb.String()
+}
+
+// hasBICKeyword проверяет наличие слова swift/bic перед позицией pos
+func hasBICKeyword(text string, pos int) bool {
+	from := pos - 32
+	if from < 0 {
+		from = 0
+	}
+	before := strings.ToLower(text[from:pos])
+	return strings.Contains(before, "swift") || strings.Contains(before, "bic")
+}
+
+// hideNationalIDs скрывает идентификаторы, прошедшие проверку детектора
+func hideNationalIDs(text, mask string, detectors []NationalIDDetector) string {
+	if len(detectors) == 0 {
+		return text
+	}
+
+	var sb strings.Builder
+	last := 0
+
+	for i := 0; i < len(text); i++ {
+		if !isDigit(text[i]) || (i > 0 && isAlnum(text[i-1])) {
+			continue
+		}
+
+		end := i
+		for end < len(text) && isDigit(text[end]) {
+			end++
+		}
+		if end < len(text) && isAlnum(text[end]) {
+			i = end - 1
+			continue
+		}
+
+		digits := text[i:end]
+		for _, detector := range detectors {
+			if len(digits) == detector.Length && (detector.Validate == nil || detector.Validate(digits)) {
+				sb.WriteString(text[last:i])
+				sb.WriteString(mask)
+				last = end
+				break
+			}
+		}
+		i = end - 1
+	}
+
+	if last == 0 {
+		return text
+	}
+	sb.WriteString(text[last:])
+	return sb.String()
+}
+
+// countryCodes коды стран ISO 3166-1 alpha-2
+var countryCodes = func() map[string]struct{} {
+	const list = "AD AE AF AG AI AL AM AO AQ AR AS AT AU AW AX AZ BA BB BD BE BF BG BH BI BJ BL BM BN BO BQ BR BS BT BV BW BY BZ " +
+		"CA CC CD CF CG CH CI CK CL CM CN CO CR CU CV CW CX CY CZ DE DJ DK DM DO DZ EC EE EG EH ER ES ET FI FJ FK FM FO FR " +
+		"GA GB GD GE GF GG GH GI GL GM GN GP GQ GR GS GT GU GW GY HK HM HN HR HT HU ID IE IL IM IN IO IQ IR IS IT JE JM JO JP " +
+		"KE KG KH KI KM KN KP KR KW KY KZ LA LB LC LI LK LR LS LT LU LV LY MA MC MD ME MF MG MH MK ML MM MN MO MP MQ MR MS MT " +
+		"MU MV MW MX MY MZ NA NC NE NF NG NI NL NO NP NR NU NZ OM PA PE PF PG PH PK PL PM PN PR PS PT PW PY QA RE RO RS RU RW " +
+		"SA SB SC SD SE SG SH SI SJ SK SL SM SN SO SR SS ST SV SX SY SZ TC TD TF TG TH TJ TK TL TM TN TO TR TT TV TW TZ UA UG " +
+		"UM US UY UZ VA VC VE VG VI VN VU WF WS XK YE YT ZA ZM ZW"
+
+	codes := make(map[string]struct{})
+	for _, code := range strings.Fields(list) {
+		codes[code] = struct{}{}
+	}
+	return codes
+}()
+
+func isCountryCode(code string) bool {
+	_, ok := countryCodes[code]
+	return ok
+}
+
+func isUpper(ch byte) bool {
+	return ch >= 'A' && ch <= 'Z'
+}
@@ -0,0 +1,47 @@
+package httpclient
+
+import (
+	"testing"
+)
+
+func TestFinancialAndNationalIDDetection(t *testing.T) {
+	regexConfig := DefaultSanitizerConfig()
+	regexConfig.EnableIBANDetection = true
+	regexConfig.EnableSWIFTDetection = true
+	regexConfig.NationalIDs = []NationalIDDetector{IINDetector()}
+
+	noRegexConfig := DefaultSanitizerConfigNoRegex()
+	noRegexConfig.EnableIBANDetection = true
+	noRegexConfig.EnableSWIFTDetection = true
+	noRegexConfig.NationalIDs = []NationalIDDetector{IINDetector()}
+
+	engines := map[string]func(string) string{
+		"regex":    NewSanitizer(regexConfig).sanitizeText,
+		"no_regex": NewSanitizerNoRegex(noRegexConfig).sanitizeText,
+	}
+
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"iban compact", "оплата на KZ86125KZT5004100100 по договору", "оплата на KZ***REDACTED***0100 по договору"},
+		{"iban grouped", "IBAN: GB82 WEST 1234 5698 7654 32.", "IBAN: GB***REDACTED***5432."},
+		{"iban bad checksum", "KZ86125KZT5004100101", "KZ86125KZT5004100101"},
+		{"bic with keyword", "SWIFT: DEUTDEFF", "SWIFT: ***REDACTED***"},
+		{"bic with digits", "bank CASPKZKA001 branch", "bank ***REDACTED*** branch"},
+		{"uppercase word", "CALLBACK failed", "CALLBACK failed"},
+		{"iin", "ИИН клиента 900101300126", "ИИН клиента ***REDACTED***"},
+		{"iin bad checksum", "номер 900101300127", "номер 900101300127"},
+	}
+
+	for engine, sanitize := range engines {
+		for _, tt := range tests {
+			t.Run(engine+"/"+tt.name, func(t *testing.T) {
+				if got := sanitize(tt.in); got != tt.want {
+					t.Errorf("got %q, want %q", got, tt.want)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	apperrors "github.com/alimzhanovlr/sdk/errors"
+)
+
+// maxErrorBodySize ограничивает чтение тела ошибки
+const maxErrorBodySize = 64 * 1024
+
+// DoJSON выполняет запрос с JSON телом in и декодирует ответ в out.
+// in и out могут быть nil. Ответы не-2xx возвращаются как *errors.AppError
+// с кодом upstream_error и статусом 502 Bad Gateway: 401 upstream означает,
+// что не прошли наши учетные данные, а не учетные данные клиента сервиса.
+// Статус, код, сообщение и детали upstream (формат SDK или {"code","message"})
+// лежат в Details["upstream"], вызывающий сопоставляет их сам, например 404
+// upstream с собственным not found, см. UpstreamStatus
+func DoJSON(ctx context.Context, client *http.Client, method, url string, in, out interface{}) error {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	return doJSON(client, req, in, out)
+}
+
+// doer выполняет запрос, *http.Client или *RequestTemplate
+type doer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// doJSON кодирует in в тело req, выполняет его и декодирует ответ в out
+func doJSON(client doer, req *http.Request, in, out interface{}) error {
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return fmt.Errorf("failed to encode request body: %w", err)
+		}
+		req.Body = io.NopCloser(bytes.NewReader(data))
+		req.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(data)), nil
+		}
+		req.ContentLength = int64(len(data))
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return apperrors.Wrap(err, "upstream_timeout", "Upstream service timed out", http.StatusGatewayTimeout)
+		}
+		return apperrors.Wrap(err, "upstream_unavailable", "Upstream service unavailable", http.StatusServiceUnavailable)
+	}
+	defer func() {
+		// Дочитываем тело чтобы соединение вернулось в пул
+		io.Copy(io.Discard, io.LimitReader(resp.Body, maxErrorBodySize))
+		resp.Body.Close()
+	}()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return decodeErrorResponse(resp)
+	}
+
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil && !errors.Is(err, io.EOF) {
+		return apperrors.Wrap(err, "upstream_invalid_response", "Upstream service returned invalid response", http.StatusBadGateway)
+	}
+
+	return nil
+}
+
+// errorBody поддерживаемые форматы тела ошибки
+type errorBody struct {
+	// Формат SDK: {"success": false, "error": {...}}
+	Error *struct {
+		Code    string                 `json:"code"`
+		Message string                 `json:"message"`
+		Details map[string]interface{} `json:"details"`
+	} `json:"error"`
+
+	// Плоский формат: {"code": "...", "message": "..."}
+	Code    json.RawMessage        `json:"code"`
+	Message string                 `json:"message"`
+	Details map[string]interface{} `json:"details"`
+}
+
+// decodeErrorResponse преобразует не-2xx ответ в AppError. Код, сообщение и
+// статус upstream не подменяют собственные, они попадают в Details["upstream"]
+func decodeErrorResponse(resp *http.Response) *apperrors.AppError {
+	upstream := map[string]interface{}{"status": resp.StatusCode}
+
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+
+	var parsed errorBody
+	if len(data) > 0 && json.Unmarshal(data, &parsed) == nil {
+		switch {
+		case parsed.Error != nil:
+			upstream["code"] = parsed.Error.Code
+			upstream["message"] = parsed.Error.Message
+			if parsed.Error.Details != nil {
+				upstream["details"] = parsed.Error.Details
+			}
+		case parsed.Message != "":
+			// code может быть строкой или числом
+			var code string
+			if json.Unmarshal(parsed.Code, &code) != nil {
+				code = string(parsed.Code)
+			}
+			upstream["code"] = code
+			upstream["message"] = parsed.Message
+			if parsed.Details != nil {
+				upstream["details"] = parsed.Details
+			}
+		}
+	}
+
+	appErr := apperrors.New("upstream_error", "Upstream service error", http.StatusBadGateway)
+	appErr.Details = map[string]interface{}{"upstream": upstream}
+	appErr.Err = fmt.Errorf("%s %s: status %d", resp.Request.Method, resp.Request.URL.Redacted(), resp.StatusCode)
+
+	return appErr
+}
+
+// UpstreamStatus возвращает статус ответа upstream из ошибки DoJSON
+func UpstreamStatus(err error) (int, bool) {
+	var appErr *apperrors.AppError
+	if !errors.As(err, &appErr) {
+		return 0, false
+	}
+	upstream, ok := appErr.Details["upstream"].(map[string]interface{})
+	if !ok {
+		return 0, false
+	}
+	status, ok := upstream["status"].(int)
+	return status, ok
+}
@@ -0,0 +1,107 @@
+package httpclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	apperrors "github.com/alimzhanovlr/sdk/errors"
+)
+
+func TestDoJSON(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/echo":
+			var in map[string]string
+			json.NewDecoder(r.Body).Decode(&in)
+			json.NewEncoder(w).Encode(map[string]string{"name": in["name"], "ct": r.Header.Get("Content-Type")})
+		case "/sdk-error":
+			w.WriteHeader(http.StatusNotFound)
+			io.WriteString(w, `{"success":false,"error":{"code":"user_not_found","message":"User not found"}}`)
+		case "/flat-error":
+			w.WriteHeader(http.StatusConflict)
+			io.WriteString(w, `{"code":409,"message":"Already exists"}`)
+		case "/unauthorized":
+			w.WriteHeader(http.StatusUnauthorized)
+			io.WriteString(w, `{"success":false,"error":{"code":"invalid_api_key","message":"API key abc is revoked","details":{"key_id":"abc"}}}`)
+		case "/crash":
+			w.WriteHeader(http.StatusInternalServerError)
+			io.WriteString(w, `panic`)
+		case "/empty":
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+
+	t.Run("success", func(t *testing.T) {
+		var out map[string]string
+		if err := DoJSON(ctx, nil, http.MethodPost, srv.URL+"/echo", map[string]string{"name": "john"}, &out); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if out["name"] != "john" || out["ct"] != "application/json" {
+			t.Errorf("unexpected response: %v", out)
+		}
+	})
+
+	t.Run("no content", func(t *testing.T) {
+		var out map[string]string
+		if err := DoJSON(ctx, nil, http.MethodDelete, srv.URL+"/empty", nil, &out); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	errorCases := []struct {
+		path         string
+		wantUpstream map[string]interface{}
+	}{
+		{"/sdk-error", map[string]interface{}{"status": http.StatusNotFound, "code": "user_not_found", "message": "User not found"}},
+		{"/flat-error", map[string]interface{}{"status": http.StatusConflict, "code": "409", "message": "Already exists"}},
+		{"/crash", map[string]interface{}{"status": http.StatusInternalServerError}},
+	}
+
+	for _, tc := range errorCases {
+		t.Run(tc.path, func(t *testing.T) {
+			err := DoJSON(ctx, nil, http.MethodGet, srv.URL+tc.path, nil, nil)
+
+			var appErr *apperrors.AppError
+			if !errors.As(err, &appErr) {
+				t.Fatalf("expected AppError, got %v", err)
+			}
+			if appErr.Code != "upstream_error" || appErr.StatusCode != http.StatusBadGateway {
+				t.Errorf("got code=%s status=%d, want upstream_error and 502", appErr.Code, appErr.StatusCode)
+			}
+			upstream, _ := appErr.Details["upstream"].(map[string]interface{})
+			if !reflect.DeepEqual(upstream, tc.wantUpstream) {
+				t.Errorf("upstream = %v, want %v", upstream, tc.wantUpstream)
+			}
+		})
+	}
+
+	t.Run("/unauthorized", func(t *testing.T) {
+		err := DoJSON(ctx, nil, http.MethodGet, srv.URL+"/unauthorized", nil, nil)
+
+		var appErr *apperrors.AppError
+		if !errors.As(err, &appErr) {
+			t.Fatalf("expected AppError, got %v", err)
+		}
+		// Our credentials failed, the caller must not get a 401
+		if appErr.StatusCode != http.StatusBadGateway || appErr.Code != "upstream_error" || appErr.Message != "Upstream service error" {
+			t.Errorf("got code=%s status=%d message=%q", appErr.Code, appErr.StatusCode, appErr.Message)
+		}
+		if status, ok := UpstreamStatus(err); !ok || status != http.StatusUnauthorized {
+			t.Errorf("UpstreamStatus() = %d, %v, want 401", status, ok)
+		}
+		upstream := appErr.Details["upstream"].(map[string]interface{})
+		if upstream["code"] != "invalid_api_key" || upstream["details"].(map[string]interface{})["key_id"] != "abc" {
+			t.Errorf("upstream = %v", upstream)
+		}
+	})
+}
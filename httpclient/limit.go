@@ -0,0 +1,83 @@
+package httpclient
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// ResponseTooLargeError возвращается когда ответ превышает лимит
+type ResponseTooLargeError struct {
+	Limit int64
+	URL   string
+}
+
+func (e *ResponseTooLargeError) Error() string {
+	return fmt.Sprintf("httpclient: response from %s exceeds limit of %s", e.URL, formatSize(int(e.Limit)))
+}
+
+// SizeLimitRoundTripper ограничивает размер тела ответа
+type SizeLimitRoundTripper struct {
+	next  http.RoundTripper
+	limit int64
+}
+
+// NewSizeLimitRoundTripper создает RoundTripper с лимитом тела ответа в байтах
+func NewSizeLimitRoundTripper(next http.RoundTripper, limit int64) *SizeLimitRoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	return &SizeLimitRoundTripper{
+		next:  next,
+		limit: limit,
+	}
+}
+
+// RoundTrip выполняет запрос. Если Content-Length больше лимита, ошибка
+// возвращается сразу, иначе при чтении сверх лимита
+func (s *SizeLimitRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := s.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	tooLarge := &ResponseTooLargeError{Limit: s.limit, URL: req.URL.Redacted()}
+
+	if resp.ContentLength > s.limit {
+		resp.Body.Close()
+		return nil, tooLarge
+	}
+
+	resp.Body = &limitedBody{
+		ReadCloser: resp.Body,
+		remaining:  s.limit,
+		err:        tooLarge,
+	}
+	return resp, nil
+}
+
+// limitedBody возвращает ошибку при чтении больше remaining байт
+type limitedBody struct {
+	io.ReadCloser
+	remaining int64
+	err       error
+}
+
+func (l *limitedBody) Read(p []byte) (int, error) {
+	if l.remaining < 0 {
+		return 0, l.err
+	}
+
+	// Читаем на байт больше лимита, чтобы отличить ровно лимит от превышения
+	if int64(len(p)) > l.remaining+1 {
+		p = p[:l.remaining+1]
+	}
+
+	n, err := l.ReadCloser.Read(p)
+	l.remaining -= int64(n)
+	if l.remaining < 0 {
+		return n + int(l.remaining), l.err
+	}
+	return n, err
+}
@@ -0,0 +1,63 @@
+package httpclient
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSizeLimitRoundTripper(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := `{"data":"` + strings.Repeat("x", 100) + `"}`
+		if r.URL.Query().Get("chunked") != "" {
+			// Без Content-Length проверка срабатывает при чтении
+			w.(http.Flusher).Flush()
+		}
+		io.WriteString(w, body)
+	}))
+	defer srv.Close()
+
+	client := &http.Client{Transport: NewSizeLimitRoundTripper(nil, 50)}
+
+	t.Run("content length", func(t *testing.T) {
+		_, err := client.Get(srv.URL)
+
+		var tooLarge *ResponseTooLargeError
+		if !errors.As(err, &tooLarge) {
+			t.Fatalf("expected ResponseTooLargeError, got %v", err)
+		}
+	})
+
+	t.Run("streamed", func(t *testing.T) {
+		resp, err := client.Get(srv.URL + "?chunked=1")
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		defer resp.Body.Close()
+
+		var out map[string]string
+		err = json.NewDecoder(resp.Body).Decode(&out)
+
+		var tooLarge *ResponseTooLargeError
+		if !errors.As(err, &tooLarge) {
+			t.Fatalf("expected ResponseTooLargeError, got %v", err)
+		}
+	})
+
+	t.Run("within limit", func(t *testing.T) {
+		client := &http.Client{Transport: NewSizeLimitRoundTripper(nil, 200)}
+		resp, err := client.Get(srv.URL + "?chunked=1")
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		defer resp.Body.Close()
+
+		if _, err := io.ReadAll(resp.Body); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+}
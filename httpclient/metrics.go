@@ -0,0 +1,49 @@
+package httpclient
+
+import (
+	"net/http"
+	"time"
+)
+
+// Metrics интерфейс для сбора метрик исходящих запросов
+// (реализуется metrics.HTTPClientMetrics)
+type Metrics interface {
+	ObserveRequest(method, host string, status int, duration time.Duration, err error)
+}
+
+// MetricsRoundTripper RoundTripper со сбором метрик
+type MetricsRoundTripper struct {
+	next    http.RoundTripper
+	metrics Metrics
+}
+
+// NewMetricsRoundTripper создает RoundTripper с метриками
+func NewMetricsRoundTripper(next http.RoundTripper, metrics Metrics) *MetricsRoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	return &MetricsRoundTripper{
+		next:    next,
+		metrics: metrics,
+	}
+}
+
+// RoundTrip выполняет HTTP запрос и записывает метрики
+func (m *MetricsRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+
+	resp, err := m.next.RoundTrip(req)
+
+	// Статус 0 означает что ответ не получен
+	status := 0
+	if resp != nil {
+		status = resp.StatusCode
+	}
+
+	if m.metrics != nil {
+		m.metrics.ObserveRequest(req.Method, req.URL.Host, status, time.Since(start), err)
+	}
+
+	return resp, err
+}
@@ -0,0 +1,244 @@
+package httpclient
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// errMsgpackTruncated тело закончилось посреди значения
+var errMsgpackTruncated = errors.New("httpclient: msgpack body truncated")
+
+// msgpackMaxDepth ограничивает вложенность, чтобы не переполнить стек на враждебных данных
+const msgpackMaxDepth = 64
+
+// MsgpackCodec декодирует MessagePack. Бинарные поля и расширения
+// заменяются описанием, их содержимое не логируется
+type MsgpackCodec struct{}
+
+// Match проверяет Content-Type
+func (MsgpackCodec) Match(contentType string) bool {
+	switch mediaType(contentType) {
+	case "application/msgpack", "application/x-msgpack", "application/vnd.msgpack":
+		return true
+	}
+	return false
+}
+
+// Decode декодирует тело
+func (MsgpackCodec) Decode(contentType string, body []byte) (interface{}, error) {
+	d := &msgpackDecoder{data: body}
+	value, err := d.decode(0)
+	if err != nil {
+		return nil, err
+	}
+	if d.pos != len(body) {
+		return nil, fmt.Errorf("httpclient: msgpack has %d trailing bytes", len(body)-d.pos)
+	}
+	return value, nil
+}
+
+type msgpackDecoder struct {
+	data []byte
+	pos  int
+}
+
+func (d *msgpackDecoder) decode(depth int) (interface{}, error) {
+	if depth > msgpackMaxDepth {
+		return nil, errors.New("httpclient: msgpack nesting too deep")
+	}
+
+	b, err := d.readByte()
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case b <= 0x7f:
+		return int64(b), nil
+	case b >= 0xe0:
+		return int64(int8(b)), nil
+	case b >= 0x80 && b <= 0x8f:
+		return d.decodeMap(int(b&0x0f), depth)
+	case b >= 0x90 && b <= 0x9f:
+		return d.decodeArray(int(b&0x0f), depth)
+	case b >= 0xa0 && b <= 0xbf:
+		return d.str(int(b & 0x1f))
+	}
+
+	switch b {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+
+	case 0xc4, 0xc5, 0xc6:
+		n, err := d.length(b - 0xc4)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := d.readBytes(n); err != nil {
+			return nil, err
+		}
+		return fmt.Sprintf("[binary %s]", formatSize(n)), nil
+
+	case 0xc7, 0xc8, 0xc9:
+		n, err := d.length(b - 0xc7)
+		if err != nil {
+			return nil, err
+		}
+		return d.ext(n)
+	case 0xd4, 0xd5, 0xd6, 0xd7, 0xd8:
+		return d.ext(1 << (b - 0xd4))
+
+	case 0xca:
+		v, err := d.readBytes(4)
+		if err != nil {
+			return nil, err
+		}
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(v))), nil
+	case 0xcb:
+		v, err := d.readBytes(8)
+		if err != nil {
+			return nil, err
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(v)), nil
+
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		v, err := d.readBytes(1 << (b - 0xcc))
+		if err != nil {
+			return nil, err
+		}
+		return readUint(v), nil
+	case 0xd0, 0xd1, 0xd2, 0xd3:
+		v, err := d.readBytes(1 << (b - 0xd0))
+		if err != nil {
+			return nil, err
+		}
+		// Расширяем знак до 64 бит
+		shift := 64 - 8*len(v)
+		return int64(readUint(v)<<shift) >> shift, nil
+
+	case 0xd9, 0xda, 0xdb:
+		n, err := d.length(b - 0xd9)
+		if err != nil {
+			return nil, err
+		}
+		return d.str(n)
+	case 0xdc, 0xdd:
+		n, err := d.length(b - 0xdc + 1)
+		if err != nil {
+			return nil, err
+		}
+		return d.decodeArray(n, depth)
+	case 0xde, 0xdf:
+		n, err := d.length(b - 0xde + 1)
+		if err != nil {
+			return nil, err
+		}
+		return d.decodeMap(n, depth)
+	}
+
+	return nil, fmt.Errorf("httpclient: invalid msgpack type 0x%02x", b)
+}
+
+func (d *msgpackDecoder) decodeMap(n, depth int) (interface{}, error) {
+	// Каждая пара занимает минимум 2 байта
+	if n > (len(d.data)-d.pos)/2 {
+		return nil, errMsgpackTruncated
+	}
+
+	result := make(map[string]interface{}, n)
+	for i := 0; i < n; i++ {
+		key, err := d.decode(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		value, err := d.decode(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+
+		if s, ok := key.(string); ok {
+			result[s] = value
+		} else {
+			result[fmt.Sprint(key)] = value
+		}
+	}
+	return result, nil
+}
+
+func (d *msgpackDecoder) decodeArray(n, depth int) (interface{}, error) {
+	if n > len(d.data)-d.pos {
+		return nil, errMsgpackTruncated
+	}
+
+	result := make([]interface{}, n)
+	for i := range result {
+		value, err := d.decode(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		result[i] = value
+	}
+	return result, nil
+}
+
+// ext пропускает расширение, оставляя его тип
+func (d *msgpackDecoder) ext(n int) (interface{}, error) {
+	t, err := d.readByte()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := d.readBytes(n); err != nil {
+		return nil, err
+	}
+	return fmt.Sprintf("[ext %d, %s]", int8(t), formatSize(n)), nil
+}
+
+// length читает длину размером 1, 2 или 4 байта (size 0, 1, 2)
+func (d *msgpackDecoder) length(size byte) (int, error) {
+	v, err := d.readBytes(1 << size)
+	if err != nil {
+		return 0, err
+	}
+	return int(readUint(v)), nil
+}
+
+func (d *msgpackDecoder) str(n int) (interface{}, error) {
+	v, err := d.readBytes(n)
+	if err != nil {
+		return nil, err
+	}
+	return string(v), nil
+}
+
+func (d *msgpackDecoder) readByte() (byte, error) {
+	if d.pos >= len(d.data) {
+		return 0, errMsgpackTruncated
+	}
+	b := d.data[d.pos]
+	d.pos++
+	return b, nil
+}
+
+func (d *msgpackDecoder) readBytes(n int) ([]byte, error) {
+	if n < 0 || n > len(d.data)-d.pos {
+		return nil, errMsgpackTruncated
+	}
+	v := d.data[d.pos : d.pos+n]
+	d.pos += n
+	return v, nil
+}
+
+// readUint читает беззнаковое big-endian число длиной 1-8 байт
+func readUint(v []byte) uint64 {
+	var n uint64
+	for _, b := range v {
+		n = n<<8 | uint64(b)
+	}
+	return n
+}
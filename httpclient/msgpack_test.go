@@ -0,0 +1,92 @@
+package httpclient
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMsgpackBodySanitized(t *testing.T) {
+	// {"user": "bob", "password": "x", "n": 300, "neg": -2, "blob": <bin 3>}
+	body := []byte{0x85,
+		0xa4, 'u', 's', 'e', 'r', 0xa3, 'b', 'o', 'b',
+		0xa8, 'p', 'a', 's', 's', 'w', 'o', 'r', 'd', 0xa1, 'x',
+		0xa1, 'n', 0xcd, 0x01, 0x2c,
+		0xa3, 'n', 'e', 'g', 0xfe,
+		0xa4, 'b', 'l', 'o', 'b', 0xc4, 0x03, 0x00, 0x01, 0x02,
+	}
+
+	got := NewSanitizer(nil).SanitizeBody(body, "application/msgpack")
+
+	for _, want := range []string{`"user": "bob"`, `"password": "***REDACTED***"`, `"n": 300`, `"neg": -2`, `"blob": "[binary 3 bytes]"`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected %s in:\n%s", want, got)
+		}
+	}
+}
+
+func TestMsgpackTruncated(t *testing.T) {
+	// Массив объявляет 1000 элементов, но данных нет
+	if _, err := (MsgpackCodec{}).Decode("application/msgpack", []byte{0xdc, 0x03, 0xe8}); err == nil {
+		t.Error("expected error for truncated body")
+	}
+}
+
+// countingCodec считает вызовы Decode
+type countingCodec struct {
+	MsgpackCodec
+	calls *int
+}
+
+func (c countingCodec) Decode(contentType string, body []byte) (interface{}, error) {
+	*c.calls++
+	return c.MsgpackCodec.Decode(contentType, body)
+}
+
+func TestCodecsRunAfterBodyRules(t *testing.T) {
+	calls := 0
+	rules := []BodyProcessingRule{
+		{
+			Name: "skip_export",
+			Condition: func(contentType string, body []byte, size int) bool {
+				return strings.Contains(contentType, "export")
+			},
+			Action:  BodyActionSkip,
+			Message: "[export]",
+		},
+		{
+			Name: "large",
+			Condition: func(contentType string, body []byte, size int) bool {
+				return size > 16
+			},
+			Action: BodyActionSummarize,
+		},
+	}
+	codecs := []BodyCodec{countingCodec{calls: &calls}}
+	small := []byte{0x81, 0xa1, 'a', 0x01}
+	large := append([]byte{0xdc, 0x00, 0x20}, make([]byte, 32)...)
+
+	sanitizers := map[string]interface {
+		SanitizeBody([]byte, string) string
+	}{
+		"regex":    NewSanitizer(&SanitizerConfig{Mask: "***", BodyRules: rules, BodyCodecs: codecs}),
+		"no_regex": NewSanitizerNoRegex(&SanitizerConfigNoRegex{Mask: "***", BodyRules: rules, BodyCodecs: codecs}),
+	}
+	for name, sanitizer := range sanitizers {
+		calls = 0
+
+		if got := sanitizer.SanitizeBody(small, "application/msgpack; profile=export"); got != "[export]" {
+			t.Errorf("%s: skipped body = %q", name, got)
+		}
+		sanitizer.SanitizeBody(large, "application/msgpack")
+		if calls != 0 {
+			t.Errorf("%s: codec decoded %d bodies intercepted by rules", name, calls)
+		}
+
+		if got := sanitizer.SanitizeBody(small, "application/msgpack"); !strings.Contains(got, `"a": 1`) {
+			t.Errorf("%s: small body not decoded: %s", name, got)
+		}
+		if calls != 1 {
+			t.Errorf("%s: codec calls = %d, want 1", name, calls)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package httpclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// isNDJSON проверяет Content-Type потока JSON объектов, по одному на строку
+func isNDJSON(contentType string) bool {
+	switch mediaType(contentType) {
+	case "application/x-ndjson", "application/ndjson", "application/jsonl",
+		"application/x-jsonlines", "application/jsonlines":
+		return true
+	}
+	return false
+}
+
+// looksLikeNDJSON проверяет что первые две непустые строки - отдельные JSON значения
+func looksLikeNDJSON(body []byte) bool {
+	checked := 0
+	for _, line := range bytes.SplitN(body, []byte("\n"), 3) {
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+		if !looksLikeJSON(string(line)) || !json.Valid(line) {
+			return false
+		}
+		checked++
+		if checked == 2 {
+			return true
+		}
+	}
+	return false
+}
+
+// sanitizeNDJSON санитизирует каждую строку отдельно и собирает NDJSON обратно.
+// При maxSize > 0 выводятся только целые строки, пока не исчерпан лимит
+func sanitizeNDJSON(body []byte, maxSize int, sanitizeValue func(interface{}) interface{}, sanitizeText func(string) string) string {
+	lines := bytes.Split(body, []byte("\n"))
+
+	var sb strings.Builder
+	for i, line := range lines {
+		line = bytes.TrimRight(line, "\r")
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+
+		sanitized := sanitizeNDJSONLine(line, sanitizeValue, sanitizeText)
+
+		if maxSize > 0 && sb.Len()+len(sanitized)+1 > maxSize {
+			remaining := 0
+			for _, rest := range lines[i:] {
+				if len(bytes.TrimSpace(rest)) > 0 {
+					remaining++
+				}
+			}
+			fmt.Fprintf(&sb, "... [truncated, %d more lines, total: %s]", remaining, formatSize(len(body)))
+			break
+		}
+
+		sb.WriteString(sanitized)
+		sb.WriteByte('\n')
+	}
+
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+// sanitizeNDJSONLine санитизирует одну строку, сохраняя ее в одну строку
+func sanitizeNDJSONLine(line []byte, sanitizeValue func(interface{}) interface{}, sanitizeText func(string) string) string {
+	var data interface{}
+	if err := json.Unmarshal(line, &data); err != nil {
+		return sanitizeText(string(line))
+	}
+
+	result, err := json.Marshal(sanitizeValue(data))
+	if err != nil {
+		return sanitizeText(string(line))
+	}
+	return string(result)
+}
@@ -0,0 +1,46 @@
+package httpclient
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNDJSONSanitized(t *testing.T) {
+	body := "{\"user\":\"a\",\"token\":\"t1\"}\n{\"user\":\"b\",\"token\":\"t2\"}\n\nnot json Bearer abc.def\n"
+
+	for _, ct := range []string{"application/x-ndjson", "application/jsonl", ""} {
+		got := NewSanitizer(nil).SanitizeBody([]byte(body), ct)
+		want := "{\"token\":\"***REDACTED***\",\"user\":\"a\"}\n" +
+			"{\"token\":\"***REDACTED***\",\"user\":\"b\"}\n" +
+			"not json Bearer ***REDACTED***"
+		if got != want {
+			t.Errorf("content type %q:\ngot  %q\nwant %q", ct, got, want)
+		}
+	}
+}
+
+func TestNDJSONTruncatedByLines(t *testing.T) {
+	config := DefaultSanitizerConfig()
+	config.MaxBodySize = 100
+
+	var sb strings.Builder
+	for i := 0; i < 200; i++ {
+		sb.WriteString(`{"id":1,"password":"secret"}` + "\n")
+	}
+
+	got := NewSanitizer(config).truncateBody([]byte(sb.String()), "application/x-ndjson")
+
+	lines := strings.Split(got, "\n")
+	last := lines[len(lines)-1]
+	if !strings.HasPrefix(last, "... [truncated, ") {
+		t.Fatalf("expected truncation marker, got %q", last)
+	}
+	for _, line := range lines[:len(lines)-1] {
+		if line != `{"id":1,"password":"***REDACTED***"}` {
+			t.Errorf("unexpected line %q", line)
+		}
+	}
+	if strings.Contains(got, "secret") {
+		t.Error("truncated output leaked unsanitized data")
+	}
+}
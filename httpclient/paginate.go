@@ -0,0 +1,399 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alimzhanovlr/sdk/retry"
+)
+
+// ErrPaginationLoop возвращается когда следующая страница уже была запрошена
+var ErrPaginationLoop = errors.New("httpclient: pagination loop detected")
+
+// Page страница ответа, тело прочитано целиком и закрыто
+type Page struct {
+	// Number номер страницы с 1
+	Number   int
+	Request  *http.Request
+	Response *http.Response
+	Body     []byte
+}
+
+// Decode декодирует JSON тело страницы
+func (p *Page) Decode(v interface{}) error {
+	if err := json.Unmarshal(p.Body, v); err != nil {
+		return fmt.Errorf("failed to decode page %d: %w", p.Number, err)
+	}
+	return nil
+}
+
+// NextPageFunc возвращает запрос следующей страницы или nil, если страниц
+// больше нет
+type NextPageFunc func(page *Page) (*http.Request, error)
+
+type paginateConfig struct {
+	client      *http.Client
+	maxPages    int
+	maxPageSize int64
+	interval    time.Duration
+	retries     int
+	maxRateWait time.Duration
+}
+
+// PaginateOption настраивает Paginate
+type PaginateOption func(*paginateConfig)
+
+// PaginateClient задает клиента, по умолчанию http.DefaultClient
+func PaginateClient(client *http.Client) PaginateOption {
+	return func(c *paginateConfig) {
+		c.client = client
+	}
+}
+
+// MaxPages останавливает обход после n страниц без ошибки, по умолчанию
+// 1000, 0 снимает ограничение
+func MaxPages(n int) PaginateOption {
+	return func(c *paginateConfig) {
+		c.maxPages = n
+	}
+}
+
+// MaxPageSize ограничивает тело страницы, по умолчанию 32MB
+func MaxPageSize(n int64) PaginateOption {
+	return func(c *paginateConfig) {
+		c.maxPageSize = n
+	}
+}
+
+// PageInterval пауза между страницами для API с лимитом без заголовков
+func PageInterval(d time.Duration) PaginateOption {
+	return func(c *paginateConfig) {
+		c.interval = d
+	}
+}
+
+// RateLimitRetries сколько раз повторять страницу после 429 и 503,
+// по умолчанию 3
+func RateLimitRetries(n int) PaginateOption {
+	return func(c *paginateConfig) {
+		c.retries = n
+	}
+}
+
+// MaxRateLimitWait максимальное ожидание по Retry-After и X-RateLimit-Reset,
+// если сервер просит ждать дольше, обход завершается ошибкой. По умолчанию 1 минута
+func MaxRateLimitWait(d time.Duration) PaginateOption {
+	return func(c *paginateConfig) {
+		c.maxRateWait = d
+	}
+}
+
+// Paginate обходит страницы, начиная с first. next строит запрос следующей
+// страницы, по умолчанию NextLink. Ответы 429 и 503 повторяются после
+// Retry-After, при X-RateLimit-Remaining: 0 следующая страница ждет
+// X-RateLimit-Reset. Ответы не-2xx возвращаются как *errors.AppError,
+// как в DoJSON, после ошибки обход заканчивается
+//
+//	for page, err := range httpclient.Paginate(ctx, req, httpclient.NextCursor("meta.next", "cursor")) {
+//		if err != nil {
+//			return err
+//		}
+//		var items []Item
+//		if err := page.Decode(&items); err != nil {
+//			return err
+//		}
+//	}
+func Paginate(ctx context.Context, first *http.Request, next NextPageFunc, opts ...PaginateOption) iter.Seq2[*Page, error] {
+	config := paginateConfig{
+		client:      http.DefaultClient,
+		maxPages:    1000,
+		maxPageSize: 32 << 20,
+		retries:     3,
+		maxRateWait: time.Minute,
+	}
+	for _, opt := range opts {
+		opt(&config)
+	}
+	if config.client == nil {
+		config.client = http.DefaultClient
+	}
+	if next == nil {
+		next = NextLink
+	}
+
+	return func(yield func(*Page, error) bool) {
+		req := first.WithContext(ctx)
+		seen := make(map[string]bool)
+
+		var wait time.Duration
+		for number := 1; req != nil; number++ {
+			if config.maxPages > 0 && number > config.maxPages {
+				return
+			}
+
+			// Курсор в теле POST запроса не меняет URL
+			if !hasBody(req) {
+				key := req.Method + " " + req.URL.String()
+				if seen[key] {
+					yield(nil, fmt.Errorf("%w: %s", ErrPaginationLoop, req.URL.Redacted()))
+					return
+				}
+				seen[key] = true
+			}
+
+			if err := sleepContext(ctx, wait); err != nil {
+				yield(nil, err)
+				return
+			}
+
+			page, err := config.fetch(ctx, req, number)
+			if err != nil {
+				yield(page, err)
+				return
+			}
+			if !yield(page, nil) {
+				return
+			}
+
+			req, err = next(page)
+			if err != nil {
+				yield(nil, fmt.Errorf("failed to build request for page %d: %w", number+1, err))
+				return
+			}
+			if req != nil {
+				req = req.WithContext(ctx)
+			}
+
+			wait = config.interval
+			if reset, ok := rateLimitReset(page.Response); ok {
+				if reset > config.maxRateWait {
+					yield(nil, fmt.Errorf("httpclient: rate limit resets in %s, longer than %s", reset, config.maxRateWait))
+					return
+				}
+				wait = max(wait, reset)
+			}
+		}
+	}
+}
+
+// fetch запрашивает страницу, повторяя ее после 429 и 503
+func (c *paginateConfig) fetch(ctx context.Context, req *http.Request, number int) (*Page, error) {
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 {
+			rewound, err := rewindRequest(req)
+			if err != nil {
+				return nil, err
+			}
+			req = rewound
+		}
+
+		resp, err := c.client.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch page %d: %w", number, err)
+		}
+
+		limited := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable
+		if limited && attempt < c.retries && (!hasBody(req) || req.GetBody != nil) {
+			wait, ok := retry.ParseRetryAfter(resp.Header.Get("Retry-After"))
+			if !ok {
+				wait = time.Second << attempt
+			}
+			if wait <= c.maxRateWait {
+				drainAndClose(resp.Body)
+				if err := sleepContext(ctx, wait); err != nil {
+					return nil, err
+				}
+				continue
+			}
+		}
+
+		body, err := io.ReadAll(io.LimitReader(resp.Body, c.maxPageSize+1))
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read page %d: %w", number, err)
+		}
+		if int64(len(body)) > c.maxPageSize {
+			return nil, &ResponseTooLargeError{Limit: c.maxPageSize, URL: req.URL.Redacted()}
+		}
+
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+		page := &Page{Number: number, Request: req, Response: resp, Body: body}
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			return page, decodeErrorResponse(resp)
+		}
+		return page, nil
+	}
+}
+
+func hasBody(req *http.Request) bool {
+	return req.Body != nil && req.Body != http.NoBody
+}
+
+// rewindRequest копирует запрос для повтора с новым телом из GetBody
+func rewindRequest(req *http.Request) (*http.Request, error) {
+	clone := req.Clone(req.Context())
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, fmt.Errorf("failed to rewind request body: %w", err)
+		}
+		clone.Body = body
+	}
+	return clone, nil
+}
+
+// NextLink переходит по заголовку Link с rel="next" (RFC 8288), как в
+// GitHub и GitLab API. Относительные ссылки разрешаются от URL страницы
+func NextLink(page *Page) (*http.Request, error) {
+	for _, header := range page.Response.Header.Values("Link") {
+		for _, link := range strings.Split(header, ",") {
+			target, ok := nextLinkTarget(link)
+			if !ok {
+				continue
+			}
+			u, err := page.Request.URL.Parse(target)
+			if err != nil {
+				return nil, fmt.Errorf("invalid next link %q: %w", target, err)
+			}
+			return nextPageRequest(page.Request, u), nil
+		}
+	}
+	return nil, nil
+}
+
+// nextLinkTarget возвращает URL ссылки с rel="next"
+func nextLinkTarget(link string) (string, bool) {
+	parts := strings.Split(link, ";")
+	target := strings.TrimSpace(parts[0])
+	if !strings.HasPrefix(target, "<") || !strings.HasSuffix(target, ">") {
+		return "", false
+	}
+
+	for _, param := range parts[1:] {
+		key, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+		if !strings.EqualFold(key, "rel") {
+			continue
+		}
+		// rel может содержать несколько значений: rel="next last"
+		for _, rel := range strings.Fields(strings.Trim(value, `"`)) {
+			if strings.EqualFold(rel, "next") {
+				return target[1 : len(target)-1], true
+			}
+		}
+	}
+	return "", false
+}
+
+// NextCursor берет курсор из JSON поля field страницы и передает его
+// query параметром param. field - путь через точку, например "meta.next_cursor".
+// Пустой или отсутствующий курсор и false в has_more завершают обход.
+// Для курсора в теле POST запроса нужна своя NextPageFunc
+func NextCursor(field, param string) NextPageFunc {
+	return func(page *Page) (*http.Request, error) {
+		var body interface{}
+		if err := json.Unmarshal(page.Body, &body); err != nil {
+			return nil, fmt.Errorf("failed to decode page %d: %w", page.Number, err)
+		}
+
+		cursor := cursorString(lookupField(body, field))
+		if cursor == "" {
+			return nil, nil
+		}
+		if hasMore, ok := lookupField(body, "has_more").(bool); ok && !hasMore {
+			return nil, nil
+		}
+
+		u := *page.Request.URL
+		query := u.Query()
+		query.Set(param, cursor)
+		u.RawQuery = query.Encode()
+		return nextPageRequest(page.Request, &u), nil
+	}
+}
+
+// lookupField ищет значение по пути через точку
+func lookupField(value interface{}, path string) interface{} {
+	for _, key := range strings.Split(path, ".") {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		value = object[key]
+	}
+	return value
+}
+
+// cursorString приводит курсор к строке, числа без экспоненты
+func cursorString(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return ""
+}
+
+// nextPageRequest копирует GET запрос страницы с новым URL и заголовками
+// исходного запроса. Заголовки авторизации не передаются на другой хост
+func nextPageRequest(prev *http.Request, u *url.URL) *http.Request {
+	req := prev.Clone(prev.Context())
+	req.Method = http.MethodGet
+	req.URL = u
+	req.Host = ""
+	req.Body = nil
+	req.GetBody = nil
+	req.ContentLength = 0
+	req.Header.Del("Content-Type")
+
+	if u.Host != prev.URL.Host {
+		req.Header.Del("Authorization")
+		req.Header.Del("Cookie")
+	}
+	return req
+}
+
+// rateLimitReset возвращает ожидание до сброса лимита, если он исчерпан.
+// X-RateLimit-Reset бывает в секундах до сброса или unix временем сброса
+func rateLimitReset(resp *http.Response) (time.Duration, bool) {
+	if strings.TrimSpace(resp.Header.Get("X-RateLimit-Remaining")) != "0" {
+		return 0, false
+	}
+
+	reset, err := strconv.ParseInt(strings.TrimSpace(resp.Header.Get("X-RateLimit-Reset")), 10, 64)
+	if err != nil || reset < 0 {
+		return 0, false
+	}
+	// Меньшие значения не бывают unix временем
+	if reset > 1_000_000_000 {
+		return max(time.Until(time.Unix(reset, 0)), 0), true
+	}
+	return time.Duration(reset) * time.Second, true
+}
+
+// sleepContext ждет d или отмены контекста
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,187 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	apperrors "github.com/alimzhanovlr/sdk/errors"
+)
+
+func TestPaginateLink(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer t" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		if page == 0 {
+			page = 1
+		}
+		if page < 3 {
+			w.Header().Set("Link", fmt.Sprintf(`</items?page=%d>; rel="next", </items?page=3>; rel="last"`, page+1))
+		}
+		fmt.Fprintf(w, `[%d]`, page)
+	}))
+	defer srv.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL+"/items", nil)
+	req.Header.Set("Authorization", "Bearer t")
+
+	var got []int
+	for page, err := range Paginate(context.Background(), req, nil) {
+		if err != nil {
+			t.Fatalf("page failed: %v", err)
+		}
+		var items []int
+		if err := page.Decode(&items); err != nil {
+			t.Fatalf("decode failed: %v", err)
+		}
+		got = append(got, items...)
+	}
+	if fmt.Sprint(got) != "[1 2 3]" {
+		t.Errorf("unexpected pages: %v", got)
+	}
+}
+
+func TestPaginateCursor(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("cursor") {
+		case "":
+			io.WriteString(w, `{"items":[1],"meta":{"next":"abc"}}`)
+		case "abc":
+			io.WriteString(w, `{"items":[2],"meta":{"next":"def"},"has_more":false}`)
+		default:
+			t.Errorf("unexpected cursor %q", r.URL.Query().Get("cursor"))
+		}
+	}))
+	defer srv.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL+"/items?limit=1", nil)
+	pages := 0
+	for page, err := range Paginate(context.Background(), req, NextCursor("meta.next", "cursor")) {
+		if err != nil {
+			t.Fatalf("page failed: %v", err)
+		}
+		if page.Request.URL.Query().Get("limit") != "1" {
+			t.Errorf("query not preserved: %s", page.Request.URL)
+		}
+		pages++
+	}
+	if pages != 2 {
+		t.Errorf("expected 2 pages, got %d", pages)
+	}
+}
+
+func TestPaginateRateLimit(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		switch {
+		case calls == 1:
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+		case r.URL.Query().Get("page") == "":
+			w.Header().Set("X-RateLimit-Remaining", "0")
+			w.Header().Set("X-RateLimit-Reset", "120")
+			w.Header().Set("Link", `</?page=2>; rel="next"`)
+		}
+	}))
+	defer srv.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+	var pages int
+	var lastErr error
+	for _, err := range Paginate(context.Background(), req, nil, MaxRateLimitWait(time.Minute)) {
+		if err != nil {
+			lastErr = err
+			break
+		}
+		pages++
+	}
+
+	// Первая страница повторена после 429, вторая ждала бы сброса дольше минуты
+	if pages != 1 || calls != 2 {
+		t.Errorf("expected 1 page in 2 calls, got %d pages in %d calls", pages, calls)
+	}
+	if lastErr == nil {
+		t.Error("expected error for long rate limit reset")
+	}
+}
+
+func TestPaginateLimits(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		w.Header().Set("Link", fmt.Sprintf(`<?page=%d>; rel="next"`, (page+1)%3))
+	}))
+	defer srv.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL+"?page=0", nil)
+
+	pages := 0
+	for _, err := range Paginate(context.Background(), req, nil, MaxPages(2)) {
+		if err != nil {
+			t.Fatalf("page failed: %v", err)
+		}
+		pages++
+	}
+	if pages != 2 {
+		t.Errorf("expected 2 pages, got %d", pages)
+	}
+
+	var lastErr error
+	for _, err := range Paginate(context.Background(), req, nil) {
+		lastErr = err
+	}
+	if !errors.Is(lastErr, ErrPaginationLoop) {
+		t.Errorf("expected ErrPaginationLoop, got %v", lastErr)
+	}
+}
+
+func TestPaginateErrorStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		io.WriteString(w, `{"code":"not_found","message":"no such list"}`)
+	}))
+	defer srv.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+	for page, err := range Paginate(context.Background(), req, nil) {
+		var appErr *apperrors.AppError
+		if !errors.As(err, &appErr) || appErr.Code != "upstream_error" {
+			t.Fatalf("expected AppError, got %v", err)
+		}
+		if upstream := appErr.Details["upstream"].(map[string]interface{}); upstream["code"] != "not_found" {
+			t.Errorf("upstream = %v, want the upstream code", upstream)
+		}
+		if page == nil || page.Response.StatusCode != http.StatusNotFound {
+			t.Errorf("expected page with response, got %+v", page)
+		}
+	}
+}
+
+func TestNextLinkTarget(t *testing.T) {
+	tests := []struct {
+		link string
+		want string
+		ok   bool
+	}{
+		{`<https://api.x.com/items?page=2>; rel="next"`, "https://api.x.com/items?page=2", true},
+		{` </items?page=2>;rel=next`, "/items?page=2", true},
+		{`<https://api.x.com/items?page=2>; rel="next last"`, "https://api.x.com/items?page=2", true},
+		{`<https://api.x.com/items?page=9>; rel="last"`, "", false},
+		{`https://api.x.com/items; rel="next"`, "", false},
+	}
+	for _, tt := range tests {
+		got, ok := nextLinkTarget(tt.link)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("nextLinkTarget(%q) = %q, %v", tt.link, got, ok)
+		}
+	}
+}
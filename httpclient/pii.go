@@ -0,0 +1,242 @@
+package httpclient
+
+import "strings"
+
+// PhoneLocale определяет какие локальные форматы номеров (без +)
+// распознаются как телефоны. Номера в формате +<код страны> распознаются всегда
+type PhoneLocale string
+
+const (
+	PhoneLocaleKZ PhoneLocale = "kz" // 8 701 123 45 67, 7 (701) 123-45-67
+	PhoneLocaleRU PhoneLocale = "ru" // 8 916 123-45-67
+	PhoneLocaleUS PhoneLocale = "us" // (415) 555-2671, 1-415-555-2671
+)
+
+// DefaultPhoneLocales локали по умолчанию
+func DefaultPhoneLocales() []PhoneLocale {
+	return []PhoneLocale{PhoneLocaleKZ, PhoneLocaleRU, PhoneLocaleUS}
+}
+
+// maskEmail скрывает локальную часть адреса, оставляя домен
+func maskEmail(email, mask string) string {
+	at := strings.LastIndexByte(email, '@')
+	if at < 0 {
+		return mask
+	}
+	return mask + email[at:]
+}
+
+// maskPhone скрывает номер, оставляя две последние цифры
+func maskPhone(phone, mask string) string {
+	digits := extractDigits(phone)
+	if len(digits) < 2 {
+		return mask
+	}
+	return mask + digits[len(digits)-2:]
+}
+
+// hideEmails находит email адреса без regex и скрывает локальную часть
+func hideEmails(text, mask string) string {
+	if strings.IndexByte(text, '@') < 0 {
+		return text
+	}
+
+	var sb strings.Builder
+	last := 0
+
+	for i := 0; i < len(text); i++ {
+		if text[i] != '@' {
+			continue
+		}
+
+		start := i
+		for start > last && isEmailLocalChar(text[start-1]) {
+			start--
+		}
+
+		end := i + 1
+		for end < len(text) && isEmailDomainChar(text[end]) {
+			end++
+		}
+		// Точка в конце предложения не часть домена
+		for end > i+1 && text[end-1] == '.' {
+			end--
+		}
+
+		if start == i || !isEmailDomain(text[i+1:end]) {
+			continue
+		}
+
+		sb.WriteString(text[last:start])
+		sb.WriteString(maskEmail(text[start:end], mask))
+		last = end
+		i = end - 1
+	}
+
+	if last == 0 {
+		return text
+	}
+	sb.WriteString(text[last:])
+	return sb.String()
+}
+
+// hidePhones находит телефонные номера и скрывает все цифры кроме двух последних
+func hidePhones(text, mask string, locales []PhoneLocale) string {
+	if len(locales) == 0 {
+		locales = DefaultPhoneLocales()
+	}
+
+	var sb strings.Builder
+	last := 0
+
+	for i := 0; i < len(text); i++ {
+		ch := text[i]
+		if !isDigit(ch) && ch != '+' && ch != '(' {
+			continue
+		}
+		// Номер не может продолжать слово или число
+		if i > 0 && (isAlnum(text[i-1]) || text[i-1] == '+') {
+			continue
+		}
+
+		end := i
+		if ch == '+' {
+			end++
+		}
+		for end < len(text) && (isDigit(text[end]) || isPhoneSeparator(text[end])) {
+			end++
+		}
+		// Разделители в конце не часть номера
+		for end > i && !isDigit(text[end-1]) {
+			end--
+		}
+
+		if end <= i || (end < len(text) && isAlnum(text[end])) {
+			continue
+		}
+
+		candidate := text[i:end]
+		if !isPhoneNumber(candidate, locales) {
+			// Пропускаем всю группу цифр, чтобы не искать номер внутри нее
+			i = end - 1
+			continue
+		}
+
+		sb.WriteString(text[last:i])
+		sb.WriteString(maskPhone(candidate, mask))
+		last = end
+		i = end - 1
+	}
+
+	if last == 0 {
+		return text
+	}
+	sb.WriteString(text[last:])
+	return sb.String()
+}
+
+// isPhoneNumber проверяет похож ли candidate на телефон в одной из локалей
+func isPhoneNumber(candidate string, locales []PhoneLocale) bool {
+	digits := extractDigits(candidate)
+	groups := digitGroups(candidate)
+
+	// Международный формат E.164
+	if strings.HasPrefix(candidate, "+") {
+		return len(digits) >= 8 && len(digits) <= 15
+	}
+
+	for _, locale := range locales {
+		switch locale {
+		case PhoneLocaleKZ, PhoneLocaleRU:
+			// 11 цифр, код страны 7 или префикс 8, первая цифра кода зоны 3/4/7/8/9
+			if len(digits) == 11 &&
+				(digits[0] == '7' || digits[0] == '8') &&
+				strings.IndexByte("34789", digits[1]) >= 0 &&
+				(len(groups) == 1 || groups[0] == 1) {
+				return true
+			}
+
+		case PhoneLocaleUS:
+			// NANP: коды зоны и станции не начинаются с 0 или 1
+			national := digits
+			natGroups := groups
+			// Код страны 1 только отдельной группой: 1-415-555-2671
+			if len(digits) == 11 && len(groups) > 1 && groups[0] == 1 && digits[0] == '1' {
+				national = digits[1:]
+				natGroups = groups[1:]
+			}
+			if len(national) == 10 &&
+				national[0] >= '2' && national[3] >= '2' &&
+				(equalInts(natGroups, []int{10}) || equalInts(natGroups, []int{3, 3, 4})) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// digitGroups возвращает длины групп цифр, разделенных разделителями
+func digitGroups(text string) []int {
+	var groups []int
+	run := 0
+	for i := 0; i < len(text); i++ {
+		if isDigit(text[i]) {
+			run++
+			continue
+		}
+		if run > 0 {
+			groups = append(groups, run)
+			run = 0
+		}
+	}
+	if run > 0 {
+		groups = append(groups, run)
+	}
+	return groups
+}
+
+func equalInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func isEmailDomain(domain string) bool {
+	dot := strings.LastIndexByte(domain, '.')
+	if dot <= 0 || len(domain)-dot-1 < 2 {
+		return false
+	}
+	for _, ch := range domain[dot+1:] {
+		if !((ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z')) {
+			return false
+		}
+	}
+	return true
+}
+
+func isEmailLocalChar(ch byte) bool {
+	return isAlnum(ch) || ch == '.' || ch == '_' || ch == '%' || ch == '+' || ch == '-'
+}
+
+func isEmailDomainChar(ch byte) bool {
+	return isAlnum(ch) || ch == '.' || ch == '-'
+}
+
+func isPhoneSeparator(ch byte) bool {
+	return ch == ' ' || ch == '-' || ch == '.' || ch == '(' || ch == ')'
+}
+
+func isDigit(ch byte) bool {
+	return ch >= '0' && ch <= '9'
+}
+
+func isAlnum(ch byte) bool {
+	return isDigit(ch) || (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z')
+}
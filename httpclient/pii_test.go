@@ -0,0 +1,52 @@
+package httpclient
+
+import (
+	"testing"
+)
+
+func TestEmailAndPhoneDetection(t *testing.T) {
+	regexConfig := DefaultSanitizerConfig()
+	regexConfig.EnableEmailDetection = true
+	regexConfig.EnablePhoneDetection = true
+
+	noRegexConfig := DefaultSanitizerConfigNoRegex()
+	noRegexConfig.EnableEmailDetection = true
+	noRegexConfig.EnablePhoneDetection = true
+
+	engines := map[string]func(string) string{
+		"regex":    NewSanitizer(regexConfig).sanitizeText,
+		"no_regex": NewSanitizerNoRegex(noRegexConfig).sanitizeText,
+	}
+
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"email", "contact john.doe+x@example.com.", "contact ***REDACTED***@example.com."},
+		{"international", "call +44 20 7946 0958", "call ***REDACTED***58"},
+		{"kz mobile", "тел: 8 (701) 123-45-67", "тел: ***REDACTED***67"},
+		{"kz compact", "phone=77011234567", "phone=***REDACTED***67"},
+		{"us", "(415) 555-2671 office", "***REDACTED***71 office"},
+		{"us with country code", "1-415-555-2671", "***REDACTED***71"},
+		{"date is not phone", "at 2024-01-15 10:30", "at 2024-01-15 10:30"},
+		{"order id is not phone", "order 12345678901", "order 12345678901"},
+	}
+
+	for engine, sanitize := range engines {
+		for _, tt := range tests {
+			t.Run(engine+"/"+tt.name, func(t *testing.T) {
+				if got := sanitize(tt.in); got != tt.want {
+					t.Errorf("got %q, want %q", got, tt.want)
+				}
+			})
+		}
+	}
+}
+
+func TestEmailAndPhoneDetectionDisabled(t *testing.T) {
+	in := "john@example.com +77011234567"
+	if got := NewSanitizer(nil).sanitizeText(in); got != in {
+		t.Errorf("detection must be opt-in, got %q", got)
+	}
+}
@@ -0,0 +1,210 @@
+package httpclient
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Direction направление передачи
+type Direction string
+
+const (
+	Upload   Direction = "upload"
+	Download Direction = "download"
+)
+
+// Progress состояние передачи
+type Progress struct {
+	Direction   Direction
+	Transferred int64
+	// Total размер тела, -1 если неизвестен
+	Total          int64
+	Elapsed        time.Duration
+	BytesPerSecond float64
+	// Done true для последнего вызова (EOF, ошибка или Close)
+	Done bool
+}
+
+// Percent возвращает процент выполнения или -1 если размер неизвестен
+func (p Progress) Percent() float64 {
+	if p.Total <= 0 {
+		return -1
+	}
+	return float64(p.Transferred) * 100 / float64(p.Total)
+}
+
+// ProgressFunc вызывается по мере передачи данных
+type ProgressFunc func(Progress)
+
+// ProgressConfig конфигурация отслеживания передачи
+type ProgressConfig struct {
+	OnUpload   ProgressFunc
+	OnDownload ProgressFunc
+
+	// Минимальный интервал между вызовами (по умолчанию 500ms)
+	Interval time.Duration
+
+	// Ограничение скорости в байтах в секунду, 0 - без ограничений
+	MaxUploadRate   int64
+	MaxDownloadRate int64
+}
+
+// ProgressRoundTripper RoundTripper с отслеживанием прогресса и ограничением скорости
+type ProgressRoundTripper struct {
+	next   http.RoundTripper
+	config ProgressConfig
+}
+
+// NewProgressRoundTripper создает RoundTripper с отслеживанием прогресса
+func NewProgressRoundTripper(next http.RoundTripper, config ProgressConfig) *ProgressRoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	if config.Interval <= 0 {
+		config.Interval = 500 * time.Millisecond
+	}
+
+	return &ProgressRoundTripper{
+		next:   next,
+		config: config,
+	}
+}
+
+// RoundTrip выполняет запрос, оборачивая тела запроса и ответа
+func (p *ProgressRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil && req.Body != http.NoBody && (p.config.OnUpload != nil || p.config.MaxUploadRate > 0) {
+		// RoundTripper не должен менять исходный запрос
+		req = req.Clone(req.Context())
+		req.Body = NewProgressReader(req.Context(), req.Body, req.ContentLength, Upload,
+			p.config.OnUpload, p.config.Interval, p.config.MaxUploadRate)
+	}
+
+	resp, err := p.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.config.OnDownload != nil || p.config.MaxDownloadRate > 0 {
+		resp.Body = NewProgressReader(req.Context(), resp.Body, resp.ContentLength, Download,
+			p.config.OnDownload, p.config.Interval, p.config.MaxDownloadRate)
+	}
+
+	return resp, nil
+}
+
+// ProgressReader io.ReadCloser с отчетом о прогрессе и ограничением скорости
+type ProgressReader struct {
+	ctx       context.Context
+	r         io.ReadCloser
+	total     int64
+	direction Direction
+	fn        ProgressFunc
+	interval  time.Duration
+	maxRate   int64
+
+	start       time.Time
+	lastReport  time.Time
+	transferred int64
+	doneOnce    sync.Once
+}
+
+// NewProgressReader оборачивает r, total -1 если размер неизвестен
+func NewProgressReader(ctx context.Context, r io.ReadCloser, total int64, direction Direction, fn ProgressFunc, interval time.Duration, maxRate int64) *ProgressReader {
+	now := time.Now()
+	return &ProgressReader{
+		ctx:        ctx,
+		r:          r,
+		total:      total,
+		direction:  direction,
+		fn:         fn,
+		interval:   interval,
+		maxRate:    maxRate,
+		start:      now,
+		lastReport: now,
+	}
+}
+
+// Read читает данные, соблюдая ограничение скорости
+func (p *ProgressReader) Read(b []byte) (int, error) {
+	// Не читаем больше чем разрешено за секунду, чтобы паузы были равномерными
+	if p.maxRate > 0 && int64(len(b)) > p.maxRate {
+		b = b[:p.maxRate]
+	}
+
+	n, err := p.r.Read(b)
+	p.transferred += int64(n)
+
+	if p.maxRate > 0 && n > 0 {
+		if waitErr := p.throttle(); waitErr != nil {
+			return n, waitErr
+		}
+	}
+
+	if err != nil {
+		p.finish()
+		return n, err
+	}
+
+	if p.fn != nil && time.Since(p.lastReport) >= p.interval {
+		p.lastReport = time.Now()
+		p.fn(p.progress(false))
+	}
+
+	return n, nil
+}
+
+// Close закрывает исходный reader
+func (p *ProgressReader) Close() error {
+	p.finish()
+	return p.r.Close()
+}
+
+// throttle ждет пока средняя скорость не опустится до maxRate
+func (p *ProgressReader) throttle() error {
+	expected := time.Duration(float64(p.transferred) / float64(p.maxRate) * float64(time.Second))
+	wait := expected - time.Since(p.start)
+	if wait <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-p.ctx.Done():
+		return p.ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// finish отправляет финальный отчет один раз
+func (p *ProgressReader) finish() {
+	p.doneOnce.Do(func() {
+		if p.fn != nil {
+			p.fn(p.progress(true))
+		}
+	})
+}
+
+// progress формирует текущее состояние
+func (p *ProgressReader) progress(done bool) Progress {
+	elapsed := time.Since(p.start)
+
+	var rate float64
+	if elapsed > 0 {
+		rate = float64(p.transferred) / elapsed.Seconds()
+	}
+
+	return Progress{
+		Direction:      p.direction,
+		Transferred:    p.transferred,
+		Total:          p.total,
+		Elapsed:        elapsed,
+		BytesPerSecond: rate,
+		Done:           done,
+	}
+}
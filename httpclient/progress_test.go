@@ -0,0 +1,74 @@
+package httpclient
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestProgressRoundTripper(t *testing.T) {
+	payload := bytes.Repeat([]byte("x"), 64*1024)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		w.Header().Set("Content-Length", strconv.Itoa(len(payload)))
+		w.Write(payload)
+	}))
+	defer srv.Close()
+
+	var (
+		mu      sync.Mutex
+		reports = map[Direction][]Progress{}
+	)
+	record := func(p Progress) {
+		mu.Lock()
+		defer mu.Unlock()
+		reports[p.Direction] = append(reports[p.Direction], p)
+	}
+
+	client := &http.Client{Transport: NewProgressRoundTripper(nil, ProgressConfig{
+		OnUpload:        record,
+		OnDownload:      record,
+		Interval:        time.Millisecond,
+		MaxDownloadRate: 256 * 1024,
+	})}
+
+	start := time.Now()
+	resp, err := client.Post(srv.URL, "application/octet-stream", bytes.NewReader(payload))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	if len(body) != len(payload) {
+		t.Fatalf("got %d bytes, want %d", len(body), len(payload))
+	}
+
+	// 64KB при 256KB/s должны занять около 250ms
+	if elapsed := time.Since(start); elapsed < 200*time.Millisecond {
+		t.Errorf("download was not throttled, took %s", elapsed)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	for _, dir := range []Direction{Upload, Download} {
+		got := reports[dir]
+		if len(got) == 0 {
+			t.Fatalf("no %s progress reported", dir)
+		}
+		last := got[len(got)-1]
+		if !last.Done || last.Transferred != int64(len(payload)) {
+			t.Errorf("%s final report = %+v", dir, last)
+		}
+		if last.Percent() != 100 {
+			t.Errorf("%s percent = %v, want 100", dir, last.Percent())
+		}
+	}
+}
@@ -0,0 +1,124 @@
+// Package protobuf декодирует protobuf тела для логирования httpclient
+// по дескрипторам сообщений, без сгенерированного кода
+package protobuf
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"os"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// Codec реализует httpclient.BodyCodec для application/x-protobuf.
+// Тип сообщения берется из параметра Content-Type (messageType, proto или type),
+// иначе используется DefaultMessage
+type Codec struct {
+	files          *protoregistry.Files
+	defaultMessage protoreflect.FullName
+}
+
+// NewCodec создает кодек по набору дескрипторов.
+// defaultMessage - полное имя сообщения, например "payments.v1.Payment", может быть пустым
+func NewCodec(set *descriptorpb.FileDescriptorSet, defaultMessage string) (*Codec, error) {
+	files, err := protodesc.NewFiles(set)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load descriptor set: %w", err)
+	}
+
+	return &Codec{
+		files:          files,
+		defaultMessage: protoreflect.FullName(defaultMessage),
+	}, nil
+}
+
+// LoadDescriptorSet читает файл, созданный
+// protoc --include_imports --descriptor_set_out=api.pb
+func LoadDescriptorSet(path string) (*descriptorpb.FileDescriptorSet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read descriptor set: %w", err)
+	}
+
+	set := &descriptorpb.FileDescriptorSet{}
+	if err := proto.Unmarshal(data, set); err != nil {
+		return nil, fmt.Errorf("failed to parse descriptor set: %w", err)
+	}
+	return set, nil
+}
+
+// Match проверяет Content-Type
+func (c *Codec) Match(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	switch mediaType {
+	case "application/x-protobuf", "application/protobuf", "application/vnd.google.protobuf":
+		return true
+	}
+	return false
+}
+
+// Decode декодирует сообщение и возвращает его JSON представление
+// с оригинальными именами полей, чтобы санитайзер находил api_key, card_number и т.п.
+func (c *Codec) Decode(contentType string, body []byte) (interface{}, error) {
+	descriptor, err := c.messageDescriptor(contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	msg := dynamicpb.NewMessage(descriptor)
+	if err := proto.Unmarshal(body, msg); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal %s: %w", descriptor.FullName(), err)
+	}
+
+	data, err := protojson.MarshalOptions{UseProtoNames: true}.Marshal(msg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal %s: %w", descriptor.FullName(), err)
+	}
+
+	var decoded interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return nil, err
+	}
+	return decoded, nil
+}
+
+// messageDescriptor находит дескриптор сообщения для Content-Type
+func (c *Codec) messageDescriptor(contentType string) (protoreflect.MessageDescriptor, error) {
+	name := c.defaultMessage
+
+	if _, params, err := mime.ParseMediaType(contentType); err == nil {
+		for _, key := range []string{"messagetype", "proto", "type"} {
+			if value := params[key]; value != "" {
+				name = protoreflect.FullName(strings.TrimPrefix(value, "."))
+				break
+			}
+		}
+	}
+
+	if name == "" {
+		return nil, fmt.Errorf("protobuf message type is not specified")
+	}
+
+	descriptor, err := c.files.FindDescriptorByName(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find message %s: %w", name, err)
+	}
+
+	message, ok := descriptor.(protoreflect.MessageDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a message", name)
+	}
+	return message, nil
+}
@@ -0,0 +1,45 @@
+package httpclient
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/alimzhanovlr/sdk/ratelimit"
+)
+
+// RateLimitRoundTripper ограничивает частоту исходящих запросов через
+// ratelimit.Limiter, тот же, что использует серверный middleware
+type RateLimitRoundTripper struct {
+	next    http.RoundTripper
+	limiter *ratelimit.Limiter
+	key     func(req *http.Request) string
+}
+
+// NewRateLimitRoundTripper создает RoundTripper с лимитом частоты запросов.
+// key выбирает ключ лимита, по умолчанию хост запроса, то есть лимит
+// считается отдельно для каждого upstream
+func NewRateLimitRoundTripper(next http.RoundTripper, limiter *ratelimit.Limiter, key func(req *http.Request) string) *RateLimitRoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	if key == nil {
+		key = func(req *http.Request) string {
+			return req.URL.Host
+		}
+	}
+
+	return &RateLimitRoundTripper{
+		next:    next,
+		limiter: limiter,
+		key:     key,
+	}
+}
+
+// RoundTrip ждет, пока лимит разрешит запрос. Если этого не случится до
+// дедлайна контекста, сразу возвращается ratelimit.ErrLimitExceeded
+func (r *RateLimitRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := r.limiter.Wait(req.Context(), r.key(req)); err != nil {
+		return nil, fmt.Errorf("httpclient: rate limit for %s: %w", req.URL.Host, err)
+	}
+	return r.next.RoundTrip(req)
+}
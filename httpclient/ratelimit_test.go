@@ -0,0 +1,97 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alimzhanovlr/sdk/ratelimit"
+)
+
+func TestRateLimitRoundTripper(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+	}))
+	defer srv.Close()
+
+	newClient := func(algorithm ratelimit.Algorithm) *http.Client {
+		limiter := ratelimit.New(nil, ratelimit.Limit{Rate: 2, Period: 200 * time.Millisecond, Algorithm: algorithm})
+		return &http.Client{Transport: NewRateLimitRoundTripper(nil, limiter, nil)}
+	}
+
+	get := func(client *http.Client, timeout time.Duration) error {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		return nil
+	}
+
+	for _, algorithm := range []ratelimit.Algorithm{ratelimit.GCRA, ratelimit.TokenBucket, ratelimit.SlidingWindow} {
+		t.Run(string(algorithm), func(t *testing.T) {
+			requests.Store(0)
+			client := newClient(algorithm)
+
+			for i := 0; i < 2; i++ {
+				if err := get(client, time.Second); err != nil {
+					t.Fatalf("request %d failed: %v", i, err)
+				}
+			}
+
+			// Лимит исчерпан, а дедлайн раньше, чем он освободится
+			err := get(client, 10*time.Millisecond)
+			if !errors.Is(err, ratelimit.ErrLimitExceeded) {
+				t.Fatalf("expected ErrLimitExceeded, got %v", err)
+			}
+
+			// С запасом по времени запрос дожидается лимита
+			start := time.Now()
+			if err := get(client, time.Second); err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			if time.Since(start) < 10*time.Millisecond {
+				t.Error("expected request to wait for the limit")
+			}
+			if got := requests.Load(); got != 3 {
+				t.Errorf("expected 3 requests to reach server, got %d", got)
+			}
+		})
+	}
+}
+
+func TestRateLimitRoundTripper_KeyPerHost(t *testing.T) {
+	limiter := ratelimit.New(nil, ratelimit.Limit{Rate: 1, Period: time.Minute})
+	rt := NewRateLimitRoundTripper(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody, Request: req}, nil
+	}), limiter, nil)
+
+	for _, host := range []string{"a.example.com", "b.example.com"} {
+		req, _ := http.NewRequest(http.MethodGet, "http://"+host+"/", nil)
+		if _, err := rt.RoundTrip(req); err != nil {
+			t.Fatalf("request to %s failed: %v", host, err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "http://a.example.com/", nil)
+	if _, err := rt.RoundTrip(req); !errors.Is(err, ratelimit.ErrLimitExceeded) {
+		t.Errorf("expected ErrLimitExceeded, got %v", err)
+	}
+}
+
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
@@ -0,0 +1,44 @@
+package httpclient
+
+import "net/http"
+
+// RequestIDRoundTripper передает ID запроса из контекста в заголовок X-Request-ID
+type RequestIDRoundTripper struct {
+	next http.RoundTripper
+}
+
+// NewRequestIDRoundTripper создает RoundTripper с передачей ID запроса.
+// LoggingRoundTripper делает это сам, отдельный RoundTripper нужен клиентам без логирования
+func NewRequestIDRoundTripper(next http.RoundTripper) *RequestIDRoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	return &RequestIDRoundTripper{next: next}
+}
+
+// RoundTrip выполняет запрос с заголовком X-Request-ID
+func (r *RequestIDRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	return r.next.RoundTrip(withRequestIDHeader(req))
+}
+
+// withRequestIDHeader возвращает копию запроса с X-Request-ID из контекста.
+// Заданный вызывающим заголовок не перезаписывается
+func withRequestIDHeader(req *http.Request) *http.Request {
+	requestID := RequestIDFromContext(req.Context())
+	if requestID == "" || req.Header.Get(RequestIDHeader) != "" {
+		return req
+	}
+
+	clone := req.Clone(req.Context())
+	clone.Header.Set(RequestIDHeader, requestID)
+	return clone
+}
+
+// requestID возвращает ID запроса из заголовка или контекста
+func requestID(req *http.Request) string {
+	if id := req.Header.Get(RequestIDHeader); id != "" {
+		return id
+	}
+	return RequestIDFromContext(req.Context())
+}
@@ -0,0 +1,40 @@
+package httpclient
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRequestIDPropagation(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Header.Get(RequestIDHeader)))
+	}))
+	defer srv.Close()
+
+	log := &recordingLogger{}
+	client := &http.Client{Transport: NewLoggingRoundTripper(nil, &LoggingConfig{Logger: log})}
+
+	ctx := WithRequestID(context.Background(), "req-123")
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	got, _ := io.ReadAll(resp.Body)
+	if string(got) != "req-123" {
+		t.Errorf("expected X-Request-ID req-123, got %q", got)
+	}
+	if req.Header.Get(RequestIDHeader) != "" {
+		t.Error("original request must not be modified")
+	}
+	if !strings.Contains(log.all(), "request_id req-123") {
+		t.Errorf("expected request_id in logs, got:\n%s", log.all())
+	}
+}
@@ -0,0 +1,160 @@
+package httpclient
+
+import (
+	"context"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Resolver разрешает имя хоста в адреса, *net.Resolver реализует этот интерфейс
+type Resolver interface {
+	LookupHost(ctx context.Context, host string) ([]string, error)
+}
+
+// NewDNSResolver создает резолвер, отправляющий запросы на конкретный DNS сервер,
+// например Consul DNS "127.0.0.1:8600"
+func NewDNSResolver(server string) *net.Resolver {
+	dialer := &net.Dialer{Timeout: 2 * time.Second}
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
+			return dialer.DialContext(ctx, network, server)
+		},
+	}
+}
+
+// StaticResolver разрешает хосты по статической таблице, остальные передает fallback
+type StaticResolver struct {
+	hosts    map[string][]string
+	fallback Resolver
+}
+
+// NewStaticResolver создает резолвер по таблице hosts. Если fallback nil,
+// используется net.DefaultResolver
+func NewStaticResolver(hosts map[string][]string, fallback Resolver) *StaticResolver {
+	if fallback == nil {
+		fallback = net.DefaultResolver
+	}
+
+	normalized := make(map[string][]string, len(hosts))
+	for host, addrs := range hosts {
+		normalized[strings.ToLower(host)] = addrs
+	}
+
+	return &StaticResolver{hosts: normalized, fallback: fallback}
+}
+
+// LookupHost реализует Resolver
+func (r *StaticResolver) LookupHost(ctx context.Context, host string) ([]string, error) {
+	if addrs, ok := r.hosts[strings.ToLower(host)]; ok {
+		return addrs, nil
+	}
+	return r.fallback.LookupHost(ctx, host)
+}
+
+// CachingResolver кэширует результаты резолвинга на TTL.
+// Ошибки кэшируются на NegativeTTL, чтобы не нагружать DNS при сбоях
+type CachingResolver struct {
+	next        Resolver
+	ttl         time.Duration
+	negativeTTL time.Duration
+
+	mu      sync.Mutex
+	entries map[string]*dnsEntry
+	// nextSweep время следующей очистки просроченных записей
+	nextSweep time.Time
+}
+
+// dnsEntry запись кэша, ready закрывается когда резолвинг завершен
+type dnsEntry struct {
+	ready   chan struct{}
+	addrs   []string
+	err     error
+	expires time.Time
+}
+
+// NewCachingResolver создает кэширующий резолвер
+func NewCachingResolver(next Resolver, ttl time.Duration) *CachingResolver {
+	if next == nil {
+		next = net.DefaultResolver
+	}
+
+	return &CachingResolver{
+		next:        next,
+		ttl:         ttl,
+		negativeTTL: time.Second,
+		entries:     make(map[string]*dnsEntry),
+	}
+}
+
+// LookupHost реализует Resolver. Параллельные запросы одного хоста
+// объединяются в один DNS запрос
+func (r *CachingResolver) LookupHost(ctx context.Context, host string) ([]string, error) {
+	r.mu.Lock()
+	r.sweep(time.Now())
+	entry, ok := r.entries[host]
+	if ok {
+		select {
+		case <-entry.ready:
+			if time.Now().After(entry.expires) {
+				ok = false
+			}
+		default:
+		}
+	}
+	if !ok {
+		entry = &dnsEntry{ready: make(chan struct{})}
+		r.entries[host] = entry
+		r.mu.Unlock()
+
+		// Резолвинг не должен прерываться отменой контекста первого вызывающего
+		lookupCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+		entry.addrs, entry.err = r.next.LookupHost(lookupCtx, host)
+		cancel()
+
+		ttl := r.ttl
+		if entry.err != nil {
+			ttl = r.negativeTTL
+		}
+		entry.expires = time.Now().Add(ttl)
+		close(entry.ready)
+
+		return entry.addrs, entry.err
+	}
+	r.mu.Unlock()
+
+	select {
+	case <-entry.ready:
+		return entry.addrs, entry.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// sweep удаляет просроченные записи не чаще раза в TTL, чтобы кэш клиента,
+// обращающегося ко многим хостам, не рос без ограничений. Вызывается под mu
+func (r *CachingResolver) sweep(now time.Time) {
+	if now.Before(r.nextSweep) {
+		return
+	}
+	r.nextSweep = now.Add(max(r.ttl, r.negativeTTL))
+
+	for host, entry := range r.entries {
+		select {
+		case <-entry.ready:
+			if now.After(entry.expires) {
+				delete(r.entries, host)
+			}
+		default:
+		}
+	}
+}
+
+// Flush очищает кэш
+func (r *CachingResolver) Flush() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries = make(map[string]*dnsEntry)
+}
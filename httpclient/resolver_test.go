@@ -0,0 +1,94 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// countingResolver считает обращения к резолверу
+type countingResolver struct {
+	calls atomic.Int32
+	addrs []string
+}
+
+func (r *countingResolver) LookupHost(ctx context.Context, host string) ([]string, error) {
+	r.calls.Add(1)
+	return r.addrs, nil
+}
+
+func TestCachingResolver(t *testing.T) {
+	next := &countingResolver{addrs: []string{"10.0.0.1"}}
+	resolver := NewCachingResolver(next, 50*time.Millisecond)
+
+	for i := 0; i < 3; i++ {
+		addrs, err := resolver.LookupHost(context.Background(), "svc.local")
+		if err != nil || len(addrs) != 1 || addrs[0] != "10.0.0.1" {
+			t.Fatalf("LookupHost() = %v, %v", addrs, err)
+		}
+	}
+	if calls := next.calls.Load(); calls != 1 {
+		t.Errorf("expected 1 lookup, got %d", calls)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	resolver.LookupHost(context.Background(), "svc.local")
+	if calls := next.calls.Load(); calls != 2 {
+		t.Errorf("expected lookup after TTL, got %d calls", calls)
+	}
+}
+
+func TestCachingResolverEvictsExpiredHosts(t *testing.T) {
+	next := &countingResolver{addrs: []string{"10.0.0.1"}}
+	resolver := NewCachingResolver(next, 20*time.Millisecond)
+	resolver.negativeTTL = 20 * time.Millisecond
+
+	for _, host := range []string{"a.local", "b.local", "c.local"} {
+		resolver.LookupHost(context.Background(), host)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	resolver.LookupHost(context.Background(), "d.local")
+
+	resolver.mu.Lock()
+	defer resolver.mu.Unlock()
+	if len(resolver.entries) != 1 {
+		t.Errorf("entries = %d, want only d.local after expired hosts are swept", len(resolver.entries))
+	}
+}
+
+func TestDefaultTransportConfigDisablesDNSCache(t *testing.T) {
+	if ttl := DefaultTransportConfig().DNSCacheTTL; ttl != 0 {
+		t.Errorf("DNSCacheTTL = %s, want caching off by default", ttl)
+	}
+}
+
+func TestTransportStaticResolver(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Host))
+	}))
+	defer srv.Close()
+
+	u, _ := url.Parse(srv.URL)
+
+	config := DefaultTransportConfig()
+	config.Resolver = NewStaticResolver(map[string][]string{
+		"payments.internal": {u.Hostname()},
+	}, nil)
+
+	client := &http.Client{Transport: NewTransport(config)}
+
+	resp, err := client.Get("http://payments.internal:" + u.Port() + "/")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("unexpected status %d", resp.StatusCode)
+	}
+}
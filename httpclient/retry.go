@@ -0,0 +1,252 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/alimzhanovlr/sdk/retry"
+)
+
+// ErrBudgetExhausted возвращается когда на попытку не осталось времени
+var ErrBudgetExhausted = errors.New("httpclient: request time budget exhausted")
+
+// RetryConfig конфигурация повторов и таймаутов
+type RetryConfig struct {
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+
+	// Таймаут одной попытки, 0 - без ограничения
+	PerAttemptTimeout time.Duration
+	// Общий таймаут всех попыток включая паузы, 0 - без ограничения
+	TotalTimeout time.Duration
+	// Запас, вычитаемый из дедлайна входящего контекста, чтобы успеть
+	// обработать ответ и вернуть его своему клиенту
+	SafetyMargin time.Duration
+
+	// Повторять ли POST/PATCH, по умолчанию только идемпотентные методы
+	RetryNonIdempotent bool
+
+	// Функция решающая нужен ли повтор, по умолчанию DefaultShouldRetry
+	ShouldRetry func(resp *http.Response, err error) bool
+}
+
+// DefaultRetryConfig дефолтная конфигурация
+func DefaultRetryConfig() RetryConfig {
+	return RetryConfig{
+		MaxAttempts:       3,
+		InitialBackoff:    100 * time.Millisecond,
+		MaxBackoff:        2 * time.Second,
+		PerAttemptTimeout: 5 * time.Second,
+		TotalTimeout:      15 * time.Second,
+		SafetyMargin:      50 * time.Millisecond,
+	}
+}
+
+// DefaultShouldRetry повторяет сетевые ошибки, 429 и 502-504
+func DefaultShouldRetry(resp *http.Response, err error) bool {
+	if err != nil {
+		// Отмена вызывающим не лечится повтором
+		return !errors.Is(err, context.Canceled)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// RetryRoundTripper RoundTripper с повторами и бюджетом времени
+type RetryRoundTripper struct {
+	next   http.RoundTripper
+	config RetryConfig
+}
+
+// NewRetryRoundTripper создает RoundTripper с повторами
+func NewRetryRoundTripper(next http.RoundTripper, config RetryConfig) *RetryRoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	if config.MaxAttempts < 1 {
+		config.MaxAttempts = 1
+	}
+	if config.ShouldRetry == nil {
+		config.ShouldRetry = DefaultShouldRetry
+	}
+
+	return &RetryRoundTripper{
+		next:   next,
+		config: config,
+	}
+}
+
+// BudgetContext возвращает контекст с дедлайном, уменьшенным на margin.
+// Используйте для исходящих вызовов внутри обработчика входящего запроса
+func BudgetContext(ctx context.Context, margin time.Duration) (context.Context, context.CancelFunc) {
+	deadline, ok := ctx.Deadline()
+	if !ok || margin <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithDeadline(ctx, deadline.Add(-margin))
+}
+
+// RoundTrip выполняет запрос с повторами, общее время не превышает
+// ни TotalTimeout, ни дедлайн входящего контекста минус SafetyMargin
+func (r *RetryRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx, cancel := BudgetContext(req.Context(), r.config.SafetyMargin)
+	if r.config.TotalTimeout > 0 {
+		ctx, cancel = withTimeout(ctx, cancel, r.config.TotalTimeout)
+	}
+
+	attempts := r.config.MaxAttempts
+	if !r.canRetry(req) {
+		attempts = 1
+	}
+
+	backoff := retry.Exponential(r.config.InitialBackoff, r.config.MaxBackoff)
+
+	var (
+		resp *http.Response
+		err  error
+	)
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if ctx.Err() != nil {
+			cancel()
+			return nil, budgetError(ctx)
+		}
+
+		attemptReq, attemptCancel, buildErr := r.attemptRequest(ctx, req, attempt)
+		if buildErr != nil {
+			cancel()
+			return nil, buildErr
+		}
+
+		resp, err = r.next.RoundTrip(attemptReq)
+
+		if attempt == attempts || !r.config.ShouldRetry(resp, err) {
+			if err != nil {
+				attemptCancel()
+				cancel()
+				return nil, err
+			}
+			// Контексты отменяются при закрытии тела ответа
+			resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: func() {
+				attemptCancel()
+				cancel()
+			}}
+			return resp, nil
+		}
+
+		wait := backoff.Delay(attempt)
+		if resp != nil {
+			if retryAfter := parseRetryAfter(resp); retryAfter > 0 {
+				wait = retryAfter
+			}
+			drainAndClose(resp.Body)
+		}
+		attemptCancel()
+
+		// Не ждем, если после паузы не останется времени на попытку
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) <= wait {
+			cancel()
+			if err != nil {
+				return nil, err
+			}
+			return nil, ErrBudgetExhausted
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			cancel()
+			return nil, budgetError(ctx)
+		case <-timer.C:
+		}
+	}
+
+	// Недостижимо: последняя попытка всегда возвращает результат
+	cancel()
+	return resp, err
+}
+
+// attemptRequest готовит запрос для попытки с собственным таймаутом и свежим телом
+func (r *RetryRoundTripper) attemptRequest(ctx context.Context, req *http.Request, attempt int) (*http.Request, context.CancelFunc, error) {
+	attemptCtx, cancel := context.WithCancel(ctx)
+	if r.config.PerAttemptTimeout > 0 {
+		attemptCtx, cancel = withTimeout(attemptCtx, cancel, r.config.PerAttemptTimeout)
+	}
+
+	attemptReq := req.Clone(attemptCtx)
+	if attempt > 1 && req.Body != nil && req.Body != http.NoBody {
+		body, err := req.GetBody()
+		if err != nil {
+			cancel()
+			return nil, nil, err
+		}
+		attemptReq.Body = body
+	}
+
+	return attemptReq, cancel, nil
+}
+
+// canRetry проверяет что запрос можно безопасно повторить
+func (r *RetryRoundTripper) canRetry(req *http.Request) bool {
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+	if r.config.RetryNonIdempotent {
+		return true
+	}
+
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete, http.MethodTrace:
+		return true
+	}
+	return req.Header.Get("Idempotency-Key") != ""
+}
+
+// withTimeout добавляет таймаут, объединяя функции отмены
+func withTimeout(ctx context.Context, parent context.CancelFunc, timeout time.Duration) (context.Context, context.CancelFunc) {
+	child, cancel := context.WithTimeout(ctx, timeout)
+	return child, func() {
+		cancel()
+		parent()
+	}
+}
+
+// budgetError возвращает ошибку исчерпанного бюджета или отмены
+func budgetError(ctx context.Context) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return ErrBudgetExhausted
+	}
+	return ctx.Err()
+}
+
+// parseRetryAfter читает Retry-After в секундах или как HTTP-дату
+func parseRetryAfter(resp *http.Response) time.Duration {
+	wait, _ := retry.ParseRetryAfter(resp.Header.Get("Retry-After"))
+	return wait
+}
+
+// drainAndClose дочитывает тело, чтобы соединение вернулось в пул
+func drainAndClose(body io.ReadCloser) {
+	io.Copy(io.Discard, io.LimitReader(body, 64*1024))
+	body.Close()
+}
+
+// cancelOnClose отменяет контекст запроса после закрытия тела
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c *cancelOnClose) Close() error {
+	err := c.ReadCloser.Close()
+	c.cancel()
+	return err
+}
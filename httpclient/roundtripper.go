@@ -30,6 +30,14 @@ type LoggingConfig struct {
 	Logger          Logger
 	SanitizerConfig *SanitizerConfig
 
+	// Метрики срабатывания санитайзера (metrics.Registry.Sanitizer())
+	SanitizerMetrics SanitizerMetrics
+
+	// Санитайзер-кандидат в режиме DryRun: получает те же заголовки и тела,
+	// его результат не логируется. Позволяет проверить новый набор правил
+	// на реальном трафике через ShadowSanitizer.Report(), не отключая текущие
+	ShadowSanitizer *Sanitizer
+
 	// Логировать ли тело запроса/ответа
 	LogRequestBody  bool
 	LogResponseBody bool
@@ -92,6 +100,8 @@ func NewLoggingRoundTripper(next http.RoundTripper, config *LoggingConfig) *Logg
 	}
 
 	sanitizer := NewSanitizer(config.SanitizerConfig)
+	sanitizer.Rules().SetLogger(config.Logger)
+	sanitizer.SetMetrics(config.SanitizerMetrics)
 
 	return &LoggingRoundTripper{
 		next:      next,
@@ -103,6 +113,9 @@ func NewLoggingRoundTripper(next http.RoundTripper, config *LoggingConfig) *Logg
 
 // RoundTrip выполняет HTTP запрос с логированием
 func (l *LoggingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	// Передаем ID входящего запроса
+	req = withRequestIDHeader(req)
+
 	// Проверяем нужно ли логировать этот запрос
 	if l.config.ShouldLog != nil && !l.config.ShouldLog(req) {
 		return l.next.RoundTrip(req)
@@ -110,8 +123,14 @@ func (l *LoggingRoundTripper) RoundTrip(req *http.Request) (*http.Response, erro
 
 	start := time.Now()
 
+	// Копия с санитайзером, учитывающим категории скрытых данных обмена
+	var categories RedactedCategories
+	exchange := *l
+	exchange.sanitizer = l.sanitizer.Track(&categories)
+	defer l.observeCategories(req, &categories)
+
 	// Логируем запрос
-	l.logRequest(req)
+	exchange.logRequest(req)
 
 	// Выполняем запрос
 	resp, err := l.next.RoundTrip(req)
@@ -120,15 +139,36 @@ func (l *LoggingRoundTripper) RoundTrip(req *http.Request) (*http.Response, erro
 
 	// Логируем ответ или ошибку
 	if err != nil {
-		l.logError(req, err, duration)
+		exchange.logError(req, err, duration)
 		return nil, err
 	}
 
-	l.logResponse(req, resp, duration)
+	exchange.logResponse(req, resp, duration)
 
 	return resp, nil
 }
 
+// categoryFields добавляет к полям лога категории данных, скрытые в обмене
+func (l *LoggingRoundTripper) categoryFields(fields []interface{}) []interface{} {
+	if l.sanitizer.categories == nil {
+		return fields
+	}
+	if counts := l.sanitizer.categories.Counts(); len(counts) > 0 {
+		fields = append(fields, "redacted_categories", counts)
+	}
+	return fields
+}
+
+// observeCategories передает категории скрытых данных обмена в метрики
+func (l *LoggingRoundTripper) observeCategories(req *http.Request, categories *RedactedCategories) {
+	if l.config.SanitizerMetrics == nil {
+		return
+	}
+	if counts := categories.Counts(); len(counts) > 0 {
+		l.config.SanitizerMetrics.ObserveDataCategories(req.URL.Host, counts)
+	}
+}
+
 // logRequest логирует исходящий запрос
 func (l *LoggingRoundTripper) logRequest(req *http.Request) {
 	if l.logger == nil {
@@ -151,12 +191,16 @@ func (l *LoggingRoundTripper) logRequest(req *http.Request) {
 
 	// Логируем заголовки
 	if l.config.LogHeaders && len(req.Header) > 0 {
-		headers := l.sanitizer.SanitizeHeaders(map[string][]string(req.Header))
+		headers := l.sanitizeHeaders(req.Header)
 		fields = append(fields, "headers", headers)
 	}
 
 	// Логируем тело
-	if l.config.LogRequestBody && req.Body != nil {
+	logBody := l.config.LogRequestBody
+	if enabled, ok := bodyLoggingFromContext(req.Context()); ok {
+		logBody = enabled
+	}
+	if logBody && req.Body != nil {
 		body := l.readAndRestoreBody(&req.Body)
 		if len(body) > 0 {
 			contentType := req.Header.Get("Content-Type")
@@ -168,7 +212,7 @@ func (l *LoggingRoundTripper) logRequest(req *http.Request) {
 			}
 
 			if shouldLog {
-				sanitized := l.sanitizer.SanitizeBody(body, contentType)
+				sanitized := l.sanitizeBody(body, contentType)
 				fields = append(fields, "body", sanitized)
 			} else {
 				fields = append(fields, "body", fmt.Sprintf("[Body not logged - size: %s]", formatSize(len(body))))
@@ -176,7 +220,23 @@ func (l *LoggingRoundTripper) logRequest(req *http.Request) {
 		}
 	}
 
-	l.logger.Info("→ HTTP Request", fields...)
+	l.log(req, INFO, "→ HTTP Request", fields...)
+}
+
+// sanitizeHeaders санитизирует заголовки, передавая их также ShadowSanitizer
+func (l *LoggingRoundTripper) sanitizeHeaders(header http.Header) map[string]string {
+	if l.config.ShadowSanitizer != nil {
+		l.config.ShadowSanitizer.SanitizeHeaders(header)
+	}
+	return l.sanitizer.SanitizeHeaders(header)
+}
+
+// sanitizeBody санитизирует тело, передавая его также ShadowSanitizer
+func (l *LoggingRoundTripper) sanitizeBody(body []byte, contentType string) string {
+	if l.config.ShadowSanitizer != nil {
+		l.config.ShadowSanitizer.SanitizeBody(body, contentType)
+	}
+	return l.sanitizer.SanitizeBody(body, contentType)
 }
 
 // logResponse логирует ответ
@@ -187,7 +247,7 @@ func (l *LoggingRoundTripper) logResponse(req *http.Request, resp *http.Response
 
 	fields := []interface{}{
 		"method", req.Method,
-		"url", l.sanitizeURL(req.URL),
+		"url", l.sanitizeRepeatedURL(req.URL),
 		"status", resp.StatusCode,
 		"status_text", resp.Status,
 		"duration_ms", duration.Milliseconds(),
@@ -200,12 +260,16 @@ func (l *LoggingRoundTripper) logResponse(req *http.Request, resp *http.Response
 
 	// Логируем заголовки
 	if l.config.LogHeaders && len(resp.Header) > 0 {
-		headers := l.sanitizer.SanitizeHeaders(map[string][]string(resp.Header))
+		headers := l.sanitizeHeaders(resp.Header)
 		fields = append(fields, "headers", headers)
 	}
 
 	// Логируем тело
-	if l.config.LogResponseBody && resp.Body != nil {
+	logBody := l.config.LogResponseBody
+	if enabled, ok := bodyLoggingFromContext(req.Context()); ok {
+		logBody = enabled
+	}
+	if logBody && resp.Body != nil {
 		body := l.readAndRestoreBody(&resp.Body)
 		if len(body) > 0 {
 			contentType := resp.Header.Get("Content-Type")
@@ -217,7 +281,7 @@ func (l *LoggingRoundTripper) logResponse(req *http.Request, resp *http.Response
 			}
 
 			if shouldLog {
-				sanitized := l.sanitizer.SanitizeBody(body, contentType)
+				sanitized := l.sanitizeBody(body, contentType)
 				fields = append(fields, "body", sanitized)
 			} else {
 				fields = append(fields, "body", fmt.Sprintf("[Body not logged - size: %s]", formatSize(len(body))))
@@ -225,13 +289,36 @@ func (l *LoggingRoundTripper) logResponse(req *http.Request, resp *http.Response
 		}
 	}
 
+	fields = l.categoryFields(fields)
+
 	// Выбираем уровень лога
 	if resp.StatusCode >= 500 {
-		l.logger.Error("← HTTP Response", fields...)
+		l.log(req, ERROR, "← HTTP Response", fields...)
 	} else if resp.StatusCode >= 400 {
-		l.logger.Info("← HTTP Response", fields...)
+		l.log(req, INFO, "← HTTP Response", fields...)
 	} else {
-		l.logger.Debug("← HTTP Response", fields...)
+		l.log(req, DEBUG, "← HTTP Response", fields...)
+	}
+}
+
+// log пишет запись с уровнем level, если в контексте запроса
+// не задан другой уровень через WithLogLevel
+func (l *LoggingRoundTripper) log(req *http.Request, level LogLevel, msg string, fields ...interface{}) {
+	if override, ok := logLevelFromContext(req.Context()); ok {
+		level = override
+	}
+
+	if id := requestID(req); id != "" {
+		fields = append(fields, "request_id", id)
+	}
+
+	switch level {
+	case DEBUG:
+		l.logger.Debug(msg, fields...)
+	case INFO:
+		l.logger.Info(msg, fields...)
+	default:
+		l.logger.Error(msg, fields...)
 	}
 }
 
@@ -241,50 +328,34 @@ func (l *LoggingRoundTripper) logError(req *http.Request, err error, duration ti
 		return
 	}
 
-	l.logger.Error("✗ HTTP Request Failed",
+	fields := []interface{}{
 		"method", req.Method,
-		"url", l.sanitizeURL(req.URL),
+		"url", l.sanitizeRepeatedURL(req.URL),
 		"error", err.Error(),
 		"duration_ms", duration.Milliseconds(),
-	)
+	}
+	fields = l.categoryFields(fields)
+	if id := requestID(req); id != "" {
+		fields = append(fields, "request_id", id)
+	}
+
+	l.logger.Error("✗ HTTP Request Failed", fields...)
 }
 
 // sanitizeURL санитизирует URL (скрывает чувствительные query параметры)
 func (l *LoggingRoundTripper) sanitizeURL(u *url.URL) string {
-	if u.RawQuery == "" {
-		return u.String()
-	}
-
-	sanitizedQuery := l.sanitizeQuery(u.RawQuery)
-
-	result := u.Scheme + "://" + u.Host + u.Path
-	if sanitizedQuery != "" {
-		result += "?" + sanitizedQuery
-	}
-	if u.Fragment != "" {
-		result += "#" + u.Fragment
-	}
+	return l.sanitizer.sanitizeURL(u)
+}
 
-	return result
+// sanitizeRepeatedURL санитизирует URL, уже записанный в лог запроса, не
+// учитывая его значения в категориях обмена второй раз
+func (l *LoggingRoundTripper) sanitizeRepeatedURL(u *url.URL) string {
+	return l.sanitizer.Track(nil).sanitizeURL(u)
 }
 
 // sanitizeQuery санитизирует query параметры
 func (l *LoggingRoundTripper) sanitizeQuery(rawQuery string) string {
-	values, err := url.ParseQuery(rawQuery)
-	if err != nil {
-		return rawQuery
-	}
-
-	sanitized := url.Values{}
-	for key, vals := range values {
-		if l.sanitizer.isSensitiveField(key) {
-			sanitized[key] = []string{l.sanitizer.config.Mask}
-		} else {
-			sanitized[key] = vals
-		}
-	}
-
-	return sanitized.Encode()
+	return l.sanitizer.sanitizeQuery(rawQuery)
 }
 
 // readAndRestoreBody читает тело и восстанавливает его
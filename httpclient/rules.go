@@ -0,0 +1,94 @@
+package httpclient
+
+import (
+	"fmt"
+	"sort"
+)
+
+// RuleEngine вычисляет правила обработки body в порядке приоритета.
+// Паника в Condition не роняет запрос: правило считается не сработавшим
+type RuleEngine struct {
+	rules  []BodyProcessingRule
+	logger Logger
+}
+
+// NewRuleEngine создает движок правил. Правила сортируются по убыванию
+// Priority, при равном приоритете сохраняется исходный порядок
+func NewRuleEngine(rules []BodyProcessingRule, logger Logger) *RuleEngine {
+	sorted := make([]BodyProcessingRule, len(rules))
+	copy(sorted, rules)
+
+	for i := range sorted {
+		if sorted[i].Name == "" {
+			sorted[i].Name = fmt.Sprintf("rule#%d", i+1)
+		}
+	}
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Priority > sorted[j].Priority
+	})
+
+	return &RuleEngine{
+		rules:  sorted,
+		logger: logger,
+	}
+}
+
+// SetLogger задает логгер для отладки срабатывания правил
+func (e *RuleEngine) SetLogger(logger Logger) {
+	e.logger = logger
+}
+
+// Rules возвращает правила в порядке вычисления
+func (e *RuleEngine) Rules() []BodyProcessingRule {
+	return e.rules
+}
+
+// Match возвращает первое сработавшее правило с действием skip, truncate
+// или summarize. Сработавшие правила sanitize не прерывают вычисление
+func (e *RuleEngine) Match(contentType string, body []byte) (BodyProcessingRule, bool) {
+	size := len(body)
+
+	for _, rule := range e.rules {
+		if !e.evaluate(rule, contentType, body, size) {
+			continue
+		}
+
+		if e.logger != nil {
+			e.logger.Debug("Body rule matched",
+				"rule", rule.Name,
+				"priority", rule.Priority,
+				"action", string(rule.Action),
+				"content_type", contentType,
+				"size", size,
+			)
+		}
+
+		if rule.Action != BodyActionSanitize {
+			return rule, true
+		}
+	}
+
+	return BodyProcessingRule{}, false
+}
+
+// evaluate вызывает Condition с защитой от паники
+func (e *RuleEngine) evaluate(rule BodyProcessingRule, contentType string, body []byte, size int) (matched bool) {
+	if rule.Condition == nil {
+		return true
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			matched = false
+			if e.logger != nil {
+				e.logger.Error("Body rule panicked",
+					"rule", rule.Name,
+					"panic", fmt.Sprint(r),
+				)
+			}
+		}
+	}()
+
+	return rule.Condition(contentType, body, size)
+}
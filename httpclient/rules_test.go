@@ -0,0 +1,100 @@
+package httpclient
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRuleEngine(t *testing.T) {
+	logger := &recordingLogger{}
+
+	engine := NewRuleEngine([]BodyProcessingRule{
+		{
+			Name:   "broken",
+			Action: BodyActionSkip,
+			Condition: func(contentType string, body []byte, size int) bool {
+				var m map[string]int
+				m["boom"]++
+				return true
+			},
+		},
+		{
+			Name:   "truncate_large",
+			Action: BodyActionTruncate,
+			Condition: func(contentType string, body []byte, size int) bool {
+				return size > 3
+			},
+		},
+		{
+			Name:     "skip_secret",
+			Priority: 10,
+			Action:   BodyActionSkip,
+			Condition: func(contentType string, body []byte, size int) bool {
+				return contentType == "application/secret"
+			},
+		},
+	}, logger)
+
+	if first := engine.Rules()[0].Name; first != "skip_secret" {
+		t.Errorf("expected highest priority rule first, got %s", first)
+	}
+
+	rule, ok := engine.Match("application/secret", []byte("payload"))
+	if !ok || rule.Name != "skip_secret" {
+		t.Errorf("expected skip_secret, got %q (matched=%v)", rule.Name, ok)
+	}
+
+	rule, ok = engine.Match("text/plain", []byte("payload"))
+	if !ok || rule.Name != "truncate_large" {
+		t.Errorf("expected truncate_large after panicking rule, got %q (matched=%v)", rule.Name, ok)
+	}
+
+	if _, ok := engine.Match("text/plain", []byte("ab")); ok {
+		t.Error("expected no match")
+	}
+
+	if !strings.Contains(logger.all(), "ERROR Body rule panicked [rule broken") {
+		t.Error("expected panic to be logged")
+	}
+	if !strings.Contains(logger.all(), "DEBUG Body rule matched [rule skip_secret") {
+		t.Error("expected matched rule to be logged")
+	}
+}
+
+func TestTruncateRuleBelowMaxBodySize(t *testing.T) {
+	fromFile, err := ParseSanitizerConfig([]byte("extend_defaults: true\nmax_body_size: 204800\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	custom := DefaultSanitizerConfig()
+	custom.BodyRules = []BodyProcessingRule{{
+		Name:   "over_1kb",
+		Action: BodyActionTruncate,
+		Condition: func(contentType string, body []byte, size int) bool {
+			return size > 1024
+		},
+	}}
+
+	tests := map[string]struct {
+		config *SanitizerConfig
+		size   int
+	}{
+		"file config": {config: fromFile, size: 150 * 1024},
+		"custom rule": {config: custom, size: 2048},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			body := "Authorization: Bearer hunter2\n" + strings.Repeat("lorem ipsum ", tt.size/12)
+			got := NewSanitizer(tt.config).SanitizeBody([]byte(body), "text/plain")
+
+			if strings.Contains(got, "hunter2") {
+				t.Error("token is not redacted")
+			}
+			if strings.Contains(got, "truncated") {
+				t.Error("body below MaxBodySize is truncated")
+			}
+		})
+	}
+}
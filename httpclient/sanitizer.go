@@ -1,16 +1,23 @@
 package httpclient
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"net/url"
 	"regexp"
 	"strings"
+
+	"github.com/alimzhanovlr/sdk/redact"
 )
 
 // BodyProcessingRule правило обработки body
 type BodyProcessingRule struct {
-	// Условие для применения правила
+	// Имя правила для логов, по умолчанию rule#N
+	Name string
+	// Приоритет, правила с большим значением вычисляются раньше
+	Priority int
+	// Условие для применения правила, nil - применяется всегда
 	Condition func(contentType string, body []byte, size int) bool
 	// Действие: skip, truncate, summarize, sanitize
 	Action BodyAction
@@ -29,8 +36,14 @@ const (
 
 // SanitizerConfig расширенная конфигурация санитайзера
 type SanitizerConfig struct {
-	// Поля для скрытия в JSON/XML/Form (case-insensitive)
+	// Поля для скрытия в JSON/XML/Form. Сравниваются без учета регистра,
+	// пробелов, пунктуации и после NFKC: "pass word" и "ｐａｓｓｗｏｒｄ"
+	// совпадают с password
 	SensitiveFields []string
+	// Заменять кириллические и греческие буквы-двойники латинскими перед
+	// сравнением полей: "pаssword" с кириллической "а" совпадет с password.
+	// Для API с кириллическими именами полей возможны ложные совпадения
+	HomoglyphFolding bool
 
 	// Regex паттерны для поиска в любом тексте
 	SensitivePatterns []*regexp.Regexp
@@ -41,7 +54,10 @@ type SanitizerConfig struct {
 	// Максимальный размер body для логирования (байты)
 	MaxBodySize int
 
-	// Правила обработки body (применяются по порядку)
+	// Способ обрезки тела больше MaxBodySize, по умолчанию TruncationBytes
+	TruncationMode TruncationMode
+
+	// Правила обработки body (применяются по приоритету, затем по порядку)
 	BodyRules []BodyProcessingRule
 
 	// Скрывать ли значения заголовков целиком или только часть
@@ -49,8 +65,47 @@ type SanitizerConfig struct {
 
 	// Кастомные заголовки для санитизации (дополнительно к дефолтным)
 	SensitiveHeaders []string
+
+	// Имена cookie (точное совпадение), значения которых видны в Cookie и
+	// Set-Cookie. Значения остальных cookie и имен, содержащих
+	// SensitiveFields, скрываются. Имена, флаги и срок действия видны всегда
+	VisibleCookies []string
+
+	// Скрывать email, оставляя домен: ***REDACTED***@example.com
+	EnableEmailDetection bool
+	// Скрывать телефоны, оставляя две последние цифры: ***REDACTED***67
+	EnablePhoneDetection bool
+	// Локальные форматы телефонов, по умолчанию DefaultPhoneLocales
+	PhoneLocales []PhoneLocale
+
+	// Скрывать IBAN с проверкой mod-97, оставляя код страны и 4 последних символа
+	EnableIBANDetection bool
+	// Скрывать SWIFT/BIC коды
+	EnableSWIFTDetection bool
+	// Национальные идентификаторы в свободном тексте, например IINDetector()
+	NationalIDs []NationalIDDetector
+	// Кодеки бинарных форматов (msgpack, protobuf), декодированное тело
+	// санитизируется по полям и логируется как JSON
+	BodyCodecs []BodyCodec
+	// Стратегии для конкретных полей, ключи в нижнем регистре (точное совпадение),
+	// имеют приоритет над SensitiveFields
+	FieldStrategies map[string]FieldStrategy
+	// Режим проверки: вывод не изменяется, а что было бы скрыто
+	// записывается в Sanitizer.Report(). Не включайте на основном санитайзере
+	// в продакшене, используйте LoggingConfig.ShadowSanitizer
+	DryRun bool
+	// Категории данных правил для Track, ключи в нижнем регистре: поле,
+	// заголовок, cookie, regex или имя детектора (email, phone, iban, swift,
+	// kz_iin). Правила без категории учитываются как DataUncategorized
+	DataCategories map[string]DataCategory
 }
 
+// emailPattern email адрес, группа 1 - домен
+var emailPattern = regexp.MustCompile(`[a-zA-Z0-9._%+-]+@([a-zA-Z0-9.-]+\.[a-zA-Z]{2,})`)
+
+// creditCardPattern номера карт Visa, Mastercard, Amex, Diners и Discover
+var creditCardPattern = regexp.MustCompile(`\b(?:4[0-9]{12}(?:[0-9]{3})?|5[1-5][0-9]{14}|3[47][0-9]{13}|3(?:0[0-5]|[68][0-9])[0-9]{11}|6(?:011|5[0-9]{2})[0-9]{12})\b`)
+
 type HeaderMaskMode string
 
 const (
@@ -60,115 +115,94 @@ const (
 
 // DefaultSanitizerConfig дефолтная конфигурация с расширенными правилами
 func DefaultSanitizerConfig() *SanitizerConfig {
-	return &SanitizerConfig{
-		SensitiveFields: []string{
-			// Аутентификация
-			"password", "passwd", "pwd", "secret", "token",
-			"api_key", "apikey", "api_secret", "access_token", "refresh_token",
-			"client_secret", "client_id", "authorization", "auth",
-			"bearer", "session", "session_id", "cookie",
-
-			// Персональные данные
-			"ssn", "social_security", "passport", "driver_license",
-			"tax_id", "ein", "vat",
-
-			// Финансовые данные
-			"credit_card", "card_number", "card_num", "cvv", "cvc",
-			"pin", "account_number", "routing_number", "iban", "swift",
-
-			// Криптография
-			"private_key", "public_key", "encryption_key", "signing_key",
-			"certificate", "cert", "key", "pem",
-
-			// Специфичные сервисы
-			"stripe_key", "aws_secret", "gcp_key", "azure_key",
-			"webhook_secret", "signing_secret",
-		},
-
-		SensitivePatterns: []*regexp.Regexp{
-			// Bearer tokens
-			regexp.MustCompile(`(?i)(bearer\s+)[a-zA-Z0-9\-._~+/]+=*`),
-
-			// API keys (различные форматы)
-			regexp.MustCompile(`(?i)(api[_-]?key["']?\s*[:=]\s*["']?)[a-zA-Z0-9\-_]{20,}`),
-			regexp.MustCompile(`(?i)(x-api-key:\s*)[a-zA-Z0-9\-_]{20,}`),
-
-			// AWS ключи
-			regexp.MustCompile(`(AKIA[0-9A-Z]{16})`),
-			regexp.MustCompile(`(?i)(aws[_-]?secret[_-]?access[_-]?key["']?\s*[:=]\s*["']?)([a-zA-Z0-9/+=]{40})`),
+	config := &SanitizerConfig{
+		// Поля и выражения общие с логгером, см. пакет redact.
+		// Email и телефоны включаются через EnableEmailDetection/EnablePhoneDetection
+		SensitiveFields:   redact.DefaultFields(),
+		SensitivePatterns: append(redact.DefaultPatterns(), creditCardPattern),
 
-			// Google API keys
-			regexp.MustCompile(`(AIza[0-9A-Za-z\-_]{35})`),
+		Mask:           "***REDACTED***",
+		MaxBodySize:    100 * 1024, // 100KB
+		TruncationMode: TruncationJSON,
 
-			// GitHub tokens
-			regexp.MustCompile(`(gh[ps]_[a-zA-Z0-9]{36})`),
+		BodyCodecs: DefaultBodyCodecs(),
 
-			// JWT токены
-			regexp.MustCompile(`(eyJ[a-zA-Z0-9_-]*\.eyJ[a-zA-Z0-9_-]*\.[a-zA-Z0-9_-]*)`),
+		HeaderMaskMode: HeaderMaskPartial,
+		SensitiveHeaders: []string{
+			"authorization", "proxy-authorization",
+			"cookie", "set-cookie",
+			"x-api-key", "x-auth-token", "x-access-token",
+			"api-key", "apikey",
+		},
 
-			// Private keys (начало)
-			regexp.MustCompile(`-----BEGIN (RSA |EC |OPENSSH )?PRIVATE KEY-----`),
+		DataCategories: DefaultDataCategories(),
+	}
 
-			// Email (опционально - может быть не сенситивным)
-			// regexp.MustCompile(`([a-zA-Z0-9._%+-]+@)[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}`),
+	config.BodyRules = defaultBodyRules(config)
 
-			// Credit card numbers
-			regexp.MustCompile(`\b(?:4[0-9]{12}(?:[0-9]{3})?|5[1-5][0-9]{14}|3[47][0-9]{13}|3(?:0[0-5]|[68][0-9])[0-9]{11}|6(?:011|5[0-9]{2})[0-9]{12})\b`),
-		},
+	// Все дефолтные выражения, кроме номеров карт, ищут секреты
+	for _, pattern := range config.SensitivePatterns {
+		config.DataCategories[strings.ToLower(pattern.String())] = DataCredentials
+	}
+	config.DataCategories[strings.ToLower(creditCardPattern.String())] = DataFinancial
 
-		Mask:        "***REDACTED***",
-		MaxBodySize: 100 * 1024, // 100KB
-
-		BodyRules: []BodyProcessingRule{
-			// Правило 1: Пропускаем бинарные файлы
-			{
-				Condition: func(contentType string, body []byte, size int) bool {
-					return isBinaryContent(contentType)
-				},
-				Action:  BodyActionSkip,
-				Message: "[Binary content - not logged]",
-			},
+	return config
+}
 
-			// Правило 2: Пропускаем base64 данные больше 1KB
-			{
-				Condition: func(contentType string, body []byte, size int) bool {
-					return size > 1024 && looksLikeBase64(body)
-				},
-				Action:  BodyActionSkip,
-				Message: "[Base64 encoded data - not logged]",
+// defaultBodyRules дефолтные правила обработки body. Порог truncate читается
+// из config.MaxBodySize при вычислении и следует за его изменением
+func defaultBodyRules(config *SanitizerConfig) []BodyProcessingRule {
+	return []BodyProcessingRule{
+		// Правило 1: Пропускаем бинарные файлы
+		{
+			Name: "binary",
+			Condition: func(contentType string, body []byte, size int) bool {
+				return isBinaryContent(contentType)
 			},
+			Action:  BodyActionSkip,
+			Message: "[Binary content - not logged]",
+		},
 
-			// Правило 3: Суммаризуем очень большие JSON/XML
-			{
-				Condition: func(contentType string, body []byte, size int) bool {
-					return size > 500*1024 && (isJSON(contentType) || isXML(contentType))
-				},
-				Action:  BodyActionSummarize,
-				Message: "", // Будет сгенерировано автоматически
+		// Правило 2: Пропускаем base64 данные больше 1KB
+		{
+			Name: "base64",
+			Condition: func(contentType string, body []byte, size int) bool {
+				return size > 1024 && looksLikeBase64(body)
 			},
+			Action:  BodyActionSkip,
+			Message: "[Base64 encoded data - not logged]",
+		},
 
-			// Правило 4: Truncate для больших тел
-			{
-				Condition: func(contentType string, body []byte, size int) bool {
-					return size > 100*1024
-				},
-				Action: BodyActionTruncate,
+		// Правило 3: Суммаризуем очень большие JSON/XML
+		{
+			Name: "large_structured",
+			Condition: func(contentType string, body []byte, size int) bool {
+				return size > 500*1024 && (isJSON(contentType) || isXML(contentType))
 			},
+			Action:  BodyActionSummarize,
+			Message: "", // Будет сгенерировано автоматически
 		},
 
-		HeaderMaskMode: HeaderMaskPartial,
-		SensitiveHeaders: []string{
-			"authorization", "proxy-authorization",
-			"cookie", "set-cookie",
-			"x-api-key", "x-auth-token", "x-access-token",
-			"api-key", "apikey",
+		// Правило 4: Truncate для тел больше MaxBodySize
+		{
+			Name: "large",
+			Condition: func(contentType string, body []byte, size int) bool {
+				return size > config.MaxBodySize
+			},
+			Action: BodyActionTruncate,
 		},
 	}
 }
 
 // Sanitizer расширенный санитайзер
 type Sanitizer struct {
-	config *SanitizerConfig
+	config  *SanitizerConfig
+	rules   *RuleEngine
+	fields  *fieldMatcher
+	metrics SanitizerMetrics
+	report  *redactionRecorder
+	// categories заданы только у копии из Track
+	categories *RedactedCategories
 }
 
 // NewSanitizer создает санитайзер
@@ -182,11 +216,53 @@ func NewSanitizer(config *SanitizerConfig) *Sanitizer {
 		config.SensitiveHeaders = DefaultSanitizerConfig().SensitiveHeaders
 	}
 
-	return &Sanitizer{config: config}
+	sanitizer := &Sanitizer{
+		config: config,
+		rules:  NewRuleEngine(config.BodyRules, nil),
+		fields: newFieldMatcher(config.SensitiveFields, config.HomoglyphFolding),
+	}
+	if config.DryRun {
+		sanitizer.report = newRedactionRecorder()
+	}
+	return sanitizer
+}
+
+// Rules возвращает движок правил обработки body
+func (s *Sanitizer) Rules() *RuleEngine {
+	return s.rules
 }
 
 // SanitizeBody очищает тело запроса/ответа
 func (s *Sanitizer) SanitizeBody(body []byte, contentType string) string {
+	result := s.sanitizeBody(body, contentType)
+	if s.config.DryRun {
+		return string(body)
+	}
+	return result
+}
+
+// SanitizeValue сериализует значение в JSON и скрывает поля и паттерны так же,
+// как в JSON теле. Правила body и MaxBodySize не применяются
+func (s *Sanitizer) SanitizeValue(value interface{}) (interface{}, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal value: %w", err)
+	}
+
+	var decoded interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal value: %w", err)
+	}
+
+	sanitized := s.sanitizeValue(decoded)
+	if s.config.DryRun {
+		return decoded, nil
+	}
+	return sanitized, nil
+}
+
+// sanitizeBody выбирает обработку по правилам и формату тела
+func (s *Sanitizer) sanitizeBody(body []byte, contentType string) string {
 	if len(body) == 0 {
 		return ""
 	}
@@ -194,32 +270,57 @@ func (s *Sanitizer) SanitizeBody(body []byte, contentType string) string {
 	size := len(body)
 
 	// Применяем правила обработки
-	for _, rule := range s.config.BodyRules {
-		if rule.Condition(contentType, body, size) {
-			switch rule.Action {
-			case BodyActionSkip:
-				if rule.Message != "" {
-					return rule.Message
-				}
-				return "[Body not logged]"
+	if rule, ok := s.rules.Match(contentType, body); ok {
+		s.observeBodyRule(rule, size)
 
-			case BodyActionSummarize:
-				return s.summarizeBody(body, contentType, size)
+		switch rule.Action {
+		case BodyActionSkip:
+			if rule.Message != "" {
+				return rule.Message
+			}
+			return "[Body not logged]"
 
-			case BodyActionTruncate:
-				return s.truncateBody(body, contentType)
+		case BodyActionSummarize:
+			return s.summarizeBody(body, contentType, size)
 
-			case BodyActionSanitize:
-				// Продолжаем обработку
-			}
+		case BodyActionTruncate:
+			return s.truncateBody(body, contentType)
 		}
 	}
 
-	// Определяем формат и санитизируем
+	// MaxBodySize соблюдается и без правила truncate
+	if s.config.MaxBodySize > 0 && size > s.config.MaxBodySize {
+		return s.truncateBody(body, contentType)
+	}
+
+	return s.sanitizeFormat(body, contentType)
+}
+
+// sanitizeFormat санитизирует тело по формату без вычисления правил
+func (s *Sanitizer) sanitizeFormat(body []byte, contentType string) string {
+	// Бинарные форматы с известной схемой декодируем после правил: тела,
+	// которые пропускаются или обрезаются по размеру, не разбираются
+	if decoded, ok := decodeWithCodecs(s.config.BodyCodecs, contentType, body); ok {
+		if result, ok := marshalDecoded(s.sanitizeValue(decoded)); ok {
+			return result
+		}
+	}
+
+	// Определяем формат и санитизируем.
+	// NDJSON раньше JSON: application/jsonl содержит application/json
+	if isNDJSON(contentType) || looksLikeNDJSON(body) {
+		return sanitizeNDJSON(body, 0, s.sanitizeValue, s.sanitizeText)
+	}
+
 	if isJSON(contentType) || looksLikeJSON(string(body)) {
 		return s.sanitizeJSON(string(body))
 	}
 
+	// HTML раньше XML: application/xhtml+xml тоже заканчивается на +xml
+	if isHTML(contentType) || looksLikeHTML(string(body)) {
+		return sanitizeHTML(string(body), s.config.Mask, s.isSensitiveField, s.sanitizeText)
+	}
+
 	if isXML(contentType) || looksLikeXML(string(body)) {
 		return s.sanitizeXML(string(body))
 	}
@@ -241,9 +342,17 @@ func (s *Sanitizer) SanitizeHeaders(headers map[string][]string) map[string]stri
 	result := make(map[string]string)
 
 	for key, values := range headers {
-		if s.isSensitiveHeader(key) {
+		switch {
+		case !s.isSensitiveHeader(key):
+			result[key] = strings.Join(values, ", ")
+		case isCookieHeader(key):
+			result[key] = s.sanitizeCookies(key, values)
+		default:
+			s.observeRedaction(RedactionHeader, strings.ToLower(key), key, 1)
 			result[key] = s.maskHeaderValue(values)
-		} else {
+		}
+
+		if s.config.DryRun {
 			result[key] = strings.Join(values, ", ")
 		}
 	}
@@ -267,29 +376,10 @@ func (s *Sanitizer) sanitizeJSON(body string) string {
 	return string(result)
 }
 
-// sanitizeXML обрабатывает XML
+// sanitizeXML обрабатывает XML, имена элементов и атрибутов сравниваются
+// так же, как поля JSON
 func (s *Sanitizer) sanitizeXML(body string) string {
-	// Простая санитизация XML через regex
-	// Для более сложных случаев можно распарсить через xml.Unmarshal
-	result := body
-
-	// Ищем теги с чувствительными данными
-	for _, field := range s.config.SensitiveFields {
-		// <password>value</password> -> <password>***</password>
-		pattern := regexp.MustCompile(`(?i)(<` + regexp.QuoteMeta(field) + `[^>]*>)([^<]+)(</` + regexp.QuoteMeta(field) + `>)`)
-		result = pattern.ReplaceAllString(result, "${1}"+s.config.Mask+"${3}")
-
-		// <tag password="value"> -> <tag password="***">
-		attrPattern := regexp.MustCompile(`(?i)(` + regexp.QuoteMeta(field) + `\s*=\s*["'])([^"']+)(["'])`)
-		result = attrPattern.ReplaceAllString(result, "${1}"+s.config.Mask+"${3}")
-	}
-
-	// Применяем паттерны
-	for _, pattern := range s.config.SensitivePatterns {
-		result = pattern.ReplaceAllString(result, "$1"+s.config.Mask)
-	}
-
-	return result
+	return sanitizeXML(body, s.config.Mask, s.isSensitiveField, s.sanitizeText)
 }
 
 // sanitizeFormURLEncoded обрабатывает application/x-www-form-urlencoded
@@ -301,6 +391,15 @@ func (s *Sanitizer) sanitizeFormURLEncoded(body string) string {
 
 	sanitized := url.Values{}
 	for key, vals := range values {
+		if strategy, ok := s.fieldStrategy(key); ok {
+			if strategy != FieldStrategyRemove {
+				for _, val := range vals {
+					sanitized.Add(key, fmt.Sprint(s.applyStrategy(strategy, val)))
+				}
+			}
+			continue
+		}
+
 		if s.isSensitiveField(key) {
 			sanitized[key] = []string{s.config.Mask}
 		} else {
@@ -363,6 +462,13 @@ func (s *Sanitizer) sanitizeValue(value interface{}) interface{} {
 	case map[string]interface{}:
 		result := make(map[string]interface{})
 		for key, val := range v {
+			if strategy, ok := s.fieldStrategy(key); ok {
+				if strategy != FieldStrategyRemove {
+					result[key] = s.applyStrategy(strategy, val)
+				}
+				continue
+			}
+
 			if s.isSensitiveField(key) {
 				result[key] = s.config.Mask
 			} else {
@@ -395,22 +501,57 @@ func (s *Sanitizer) sanitizeValue(value interface{}) interface{} {
 func (s *Sanitizer) sanitizeText(text string) string {
 	result := text
 
+	mask := s.config.Mask
+
 	for _, pattern := range s.config.SensitivePatterns {
-		result = pattern.ReplaceAllString(result, "$1"+s.config.Mask)
+		result = s.redact(RedactionPattern, pattern.String(), result, func(text string) string {
+			return pattern.ReplaceAllString(text, "$1"+mask)
+		})
+	}
+
+	// IBAN раньше телефонов и ИИН, так как содержит длинные группы цифр
+	if s.config.EnableIBANDetection {
+		result = s.redact(RedactionDetector, "iban", result, func(text string) string {
+			return hideIBANs(text, mask)
+		})
+	}
+
+	if s.config.EnableSWIFTDetection {
+		result = s.redact(RedactionDetector, "swift", result, func(text string) string {
+			return hideBICs(text, mask)
+		})
+	}
+
+	for _, detector := range s.config.NationalIDs {
+		detectors := []NationalIDDetector{detector}
+		result = s.redact(RedactionDetector, detector.Name, result, func(text string) string {
+			return hideNationalIDs(text, mask, detectors)
+		})
+	}
+
+	if s.config.EnableEmailDetection {
+		result = s.redact(RedactionDetector, "email", result, func(text string) string {
+			return emailPattern.ReplaceAllString(text, mask+"@$1")
+		})
+	}
+
+	if s.config.EnablePhoneDetection {
+		result = s.redact(RedactionDetector, "phone", result, func(text string) string {
+			return hidePhones(text, mask, s.config.PhoneLocales)
+		})
 	}
 
 	return result
 }
 
-// isSensitiveField проверяет чувствительность поля
+// isSensitiveField проверяет чувствительность поля без учета регистра,
+// пробелов, пунктуации и Unicode форм записи, см. foldFieldName
 func (s *Sanitizer) isSensitiveField(fieldName string) bool {
-	lower := strings.ToLower(fieldName)
-	for _, sensitive := range s.config.SensitiveFields {
-		if strings.Contains(lower, strings.ToLower(sensitive)) {
-			return true
-		}
+	sensitive, ok := s.fields.match(fieldName)
+	if ok {
+		s.observeRedaction(RedactionField, sensitive, fieldName, 1)
 	}
-	return false
+	return ok
 }
 
 // isSensitiveHeader проверяет чувствительность заголовка
@@ -436,26 +577,52 @@ func (s *Sanitizer) maskHeaderValue(values []string) string {
 		return s.config.Mask
 	}
 
-	// Partial - показываем первые и последние символы
+	return partialMask(value, s.config.Mask)
+}
+
+// partialMask показывает первые и последние символы значения
+func partialMask(value, mask string) string {
 	if len(value) <= 8 {
-		return s.config.Mask
+		return mask
 	}
 
-	return value[:4] + s.config.Mask + value[len(value)-4:]
+	return value[:4] + mask + value[len(value)-4:]
 }
 
 // truncateBody обрезает тело
 func (s *Sanitizer) truncateBody(body []byte, contentType string) string {
 	maxSize := s.config.MaxBodySize
 	if len(body) <= maxSize {
-		return s.SanitizeBody(body, contentType)
+		// Не через sanitizeBody: правило truncate сработало бы снова
+		return s.sanitizeFormat(body, contentType)
 	}
 
-	// Пытаемся обрезать умно
-	truncated := body[:maxSize]
-	result := string(truncated)
+	// NDJSON обрезаем по границам строк, каждая строка санитизирована
+	if isNDJSON(contentType) || looksLikeNDJSON(body) {
+		return sanitizeNDJSON(body, maxSize, s.sanitizeValue, s.sanitizeText)
+	}
 
-	return result + "\n... [truncated, total: " + formatSize(len(body)) + "]"
+	if s.config.TruncationMode == TruncationJSON && (isJSON(contentType) || looksLikeJSON(string(body))) {
+		if result, ok := truncateJSON(body, maxSize, s.sanitizeValue); ok {
+			return result
+		}
+	}
+
+	// Санитизируем тело целиком и только потом обрезаем: секрет, разрезанный
+	// границей, уже не совпадет с полем или выражением
+	result := s.sanitizeFormat(body, contentType)
+	if isJSON(contentType) || looksLikeJSON(string(body)) {
+		// Обрезаем JSON без отступов, как в исходном теле
+		var compact bytes.Buffer
+		if json.Compact(&compact, []byte(result)) == nil {
+			result = compact.String()
+		}
+	}
+	if len(result) <= maxSize {
+		return result
+	}
+
+	return cutUTF8(result, maxSize) + "\n... [truncated, total: " + formatSize(len(body)) + "]"
 }
 
 // summarizeBody создает сводку для большого тела
@@ -474,7 +641,9 @@ func (s *Sanitizer) summarizeBody(body []byte, contentType string, size int) str
 		}
 	}
 
-	if isXML(contentType) {
+	if isHTML(contentType) {
+		summary += " HTML document"
+	} else if isXML(contentType) {
 		summary += " XML document"
 	}
 
@@ -576,3 +745,41 @@ func formatSize(size int) string {
 func formatInt(n int) string {
 	return strings.ReplaceAll(strings.ReplaceAll(fmt.Sprintf("%d", n), ",", ""), ".", ",")
 }
+
+// sanitizeURL санитизирует URL (скрывает чувствительные query параметры)
+func (s *Sanitizer) sanitizeURL(u *url.URL) string {
+	if u.RawQuery == "" {
+		return u.String()
+	}
+
+	sanitizedQuery := s.sanitizeQuery(u.RawQuery)
+
+	result := u.Scheme + "://" + u.Host + u.Path
+	if sanitizedQuery != "" {
+		result += "?" + sanitizedQuery
+	}
+	if u.Fragment != "" {
+		result += "#" + u.Fragment
+	}
+
+	return result
+}
+
+// sanitizeQuery санитизирует query параметры
+func (s *Sanitizer) sanitizeQuery(rawQuery string) string {
+	values, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return rawQuery
+	}
+
+	sanitized := url.Values{}
+	for key, vals := range values {
+		if s.isSensitiveField(key) {
+			sanitized[key] = []string{s.config.Mask}
+		} else {
+			sanitized[key] = vals
+		}
+	}
+
+	return sanitized.Encode()
+}
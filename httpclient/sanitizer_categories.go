@@ -0,0 +1,98 @@
+package httpclient
+
+import (
+	"maps"
+	"slices"
+	"strings"
+)
+
+// DataCategory категория скрываемых данных для отчетов DPO: какие типы
+// данных проходят через интеграцию
+type DataCategory string
+
+const (
+	DataCredentials   DataCategory = "credentials" // Пароли, токены, ключи
+	DataFinancial     DataCategory = "financial"   // Карты, счета, IBAN
+	DataPersonal      DataCategory = "personal"    // Документы, ИИН, email, телефоны
+	DataHealth        DataCategory = "health"      // Медицинские данные
+	DataUncategorized DataCategory = "uncategorized"
+)
+
+// DefaultDataCategories категории для правил DefaultSanitizerConfig и
+// встроенных детекторов. Ключи - правило в нижнем регистре: поле, заголовок,
+// cookie, regex или имя детектора
+func DefaultDataCategories() map[string]DataCategory {
+	categories := make(map[string]DataCategory)
+	add := func(category DataCategory, rules ...string) {
+		for _, rule := range rules {
+			categories[rule] = category
+		}
+	}
+
+	add(DataCredentials,
+		"password", "passwd", "pwd", "secret", "token",
+		"api_key", "apikey", "api_secret", "access_token", "refresh_token",
+		"client_secret", "client_id", "authorization", "auth",
+		"bearer", "session", "session_id", "cookie",
+		"private_key", "public_key", "encryption_key", "signing_key",
+		"certificate", "cert", "key", "pem",
+		"stripe_key", "aws_secret", "gcp_key", "azure_key",
+		"webhook_secret", "signing_secret",
+		"proxy-authorization", "set-cookie",
+		"x-api-key", "x-auth-token", "x-access-token", "api-key",
+		"sid", "phpsessid", "connect.sid", "_csrf", "remember_me",
+	)
+	add(DataPersonal,
+		"ssn", "social_security", "passport", "driver_license", "tax_id",
+		"email", "phone", "kz_iin",
+	)
+	add(DataFinancial,
+		"ein", "vat",
+		"credit_card", "card_number", "card_num", "cvv", "cvc",
+		"pin", "account_number", "routing_number", "iban", "swift",
+	)
+	return categories
+}
+
+// RedactedCategories количество скрытых значений по категориям в одном
+// обмене. Не потокобезопасен, заводится на каждый запрос
+type RedactedCategories struct {
+	counts map[DataCategory]int
+}
+
+func (c *RedactedCategories) add(category DataCategory, count int) {
+	if c.counts == nil {
+		c.counts = make(map[DataCategory]int)
+	}
+	c.counts[category] += count
+}
+
+// Counts возвращает количество скрытых значений по категориям
+func (c *RedactedCategories) Counts() map[DataCategory]int {
+	return maps.Clone(c.counts)
+}
+
+// Categories возвращает скрытые категории по алфавиту
+func (c *RedactedCategories) Categories() []DataCategory {
+	return slices.Sorted(maps.Keys(c.counts))
+}
+
+// Track возвращает санитайзер с теми же правилами, который дополнительно
+// учитывает категории скрытых значений в categories
+//
+//	var categories httpclient.RedactedCategories
+//	body := sanitizer.Track(&categories).SanitizeBody(data, contentType)
+//	log.Info("exchange", "redacted_categories", categories.Counts())
+func (s *Sanitizer) Track(categories *RedactedCategories) *Sanitizer {
+	tracked := *s
+	tracked.categories = categories
+	return &tracked
+}
+
+// dataCategory возвращает категорию правила из SanitizerConfig.DataCategories
+func (s *Sanitizer) dataCategory(rule string) DataCategory {
+	if category, ok := s.config.DataCategories[strings.ToLower(rule)]; ok {
+		return category
+	}
+	return DataUncategorized
+}
@@ -0,0 +1,96 @@
+package httpclient
+
+import (
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestSanitizerTrackCategories(t *testing.T) {
+	config := DefaultSanitizerConfig()
+	config.EnableEmailDetection = true
+	config.EnableIBANDetection = true
+	config.FieldStrategies = map[string]FieldStrategy{"diagnosis": FieldStrategyMask}
+	config.DataCategories["diagnosis"] = DataHealth
+	sanitizer := NewSanitizer(config)
+
+	var categories RedactedCategories
+	tracked := sanitizer.Track(&categories)
+	tracked.SanitizeHeaders(map[string][]string{"Authorization": {"Bearer abc"}})
+	tracked.SanitizeBody([]byte(`{
+		"card_number": "4111111111111111",
+		"note": "mail a@b.kz, card 5500000000000004, iban KZ86125KZT5004100100",
+		"diagnosis": "J45",
+		"custom": "x"
+	}`), "application/json")
+
+	want := map[DataCategory]int{
+		DataCredentials: 1,
+		DataFinancial:   3,
+		DataPersonal:    1,
+		DataHealth:      1,
+	}
+	got := categories.Counts()
+	for category, count := range want {
+		if got[category] != count {
+			t.Errorf("%s = %d, want %d (all: %v)", category, got[category], count, got)
+		}
+	}
+	if !slices.Equal(categories.Categories(), []DataCategory{DataCredentials, DataFinancial, DataHealth, DataPersonal}) {
+		t.Errorf("unexpected categories: %v", categories.Categories())
+	}
+
+	// Исходный санитайзер категории не учитывает
+	if sanitizer.categories != nil {
+		t.Error("Track must not modify the original sanitizer")
+	}
+}
+
+func TestSanitizerTrackUncategorized(t *testing.T) {
+	sanitizer := NewSanitizer(&SanitizerConfig{
+		SensitiveFields: []string{"member_id"},
+		Mask:            "***",
+	})
+
+	var categories RedactedCategories
+	sanitizer.Track(&categories).SanitizeBody([]byte(`{"member_id":"42","name":"x"}`), "application/json")
+
+	if got := categories.Counts(); len(got) != 1 || got[DataUncategorized] != 1 {
+		t.Errorf("expected one uncategorized value, got %v", got)
+	}
+}
+
+func TestLoggingRoundTripperRedactedCategories(t *testing.T) {
+	logger := &recordingLogger{}
+	metrics := newRecordingSanitizerMetrics()
+	config := DefaultLoggingConfig(logger)
+	config.SanitizerMetrics = metrics
+
+	transport := NewLoggingRoundTripper(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": {"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(`{"iban":"KZ86125KZT5004100100","ssn":"123"}`)),
+			Request:    req,
+		}, nil
+	}), config)
+
+	req, _ := http.NewRequest(http.MethodPost, "https://bank.example.com/transfer?token=abc", strings.NewReader(`{"password":"x"}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
+
+	// fmt печатает ключи map по порядку
+	if !strings.Contains(logger.all(), "redacted_categories map[credentials:2 financial:1 personal:1]") {
+		t.Errorf("redacted_categories not logged:\n%s", logger.all())
+	}
+
+	if metrics.exchanges["bank.example.com/credentials"] != 2 || metrics.exchanges["bank.example.com/financial"] != 1 {
+		t.Errorf("unexpected category metrics: %v", metrics.exchanges)
+	}
+}
@@ -0,0 +1,215 @@
+package httpclient
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"os"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FieldStrategy способ обработки конкретного поля
+type FieldStrategy string
+
+const (
+	FieldStrategyMask    FieldStrategy = "mask"    // Заменить маской
+	FieldStrategyPartial FieldStrategy = "partial" // Показать первые/последние символы
+	FieldStrategyHash    FieldStrategy = "hash"    // Заменить SHA-256 префиксом, значения можно сопоставлять
+	FieldStrategyRemove  FieldStrategy = "remove"  // Удалить поле из лога
+	FieldStrategyKeep    FieldStrategy = "keep"    // Не санитизировать, например author при правиле auth
+)
+
+// sanitizerFile формат файла конфигурации санитайзера
+type sanitizerFile struct {
+	// Дополнять DefaultSanitizerConfig вместо замены
+	ExtendDefaults bool `yaml:"extend_defaults"`
+
+	Mask             string                   `yaml:"mask"`
+	MaxBodySize      int                      `yaml:"max_body_size"`
+	Truncation       TruncationMode           `yaml:"truncation"`
+	HeaderMaskMode   HeaderMaskMode           `yaml:"header_mask_mode"`
+	SensitiveFields  []string                 `yaml:"sensitive_fields"`
+	Homoglyphs       bool                     `yaml:"homoglyphs"`
+	SensitiveHeaders []string                 `yaml:"sensitive_headers"`
+	VisibleCookies   []string                 `yaml:"visible_cookies"`
+	Patterns         []string                 `yaml:"patterns"`
+	FieldStrategies  map[string]FieldStrategy `yaml:"field_strategies"`
+	DataCategories   map[string]DataCategory  `yaml:"data_categories"`
+
+	Detect struct {
+		Email bool `yaml:"email"`
+		Phone bool `yaml:"phone"`
+		IBAN  bool `yaml:"iban"`
+		SWIFT bool `yaml:"swift"`
+	} `yaml:"detect"`
+	PhoneLocales []PhoneLocale `yaml:"phone_locales"`
+	NationalIDs  []string      `yaml:"national_ids"`
+}
+
+// nationalIDDetectors детекторы, доступные по имени в файле конфигурации
+var nationalIDDetectors = map[string]func() NationalIDDetector{
+	"kz_iin": IINDetector,
+}
+
+// LoadSanitizerConfig загружает конфигурацию санитайзера из YAML или JSON файла,
+// чтобы правила скрытия хранились вместе с конфигом сервиса и проходили ревью безопасности
+func LoadSanitizerConfig(path string) (*SanitizerConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read sanitizer config: %w", err)
+	}
+	return ParseSanitizerConfig(data)
+}
+
+// LoadSanitizerConfigFS загружает конфигурацию из fs.FS, например embed.FS
+func LoadSanitizerConfigFS(fsys fs.FS, path string) (*SanitizerConfig, error) {
+	data, err := fs.ReadFile(fsys, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read sanitizer config: %w", err)
+	}
+	return ParseSanitizerConfig(data)
+}
+
+// ParseSanitizerConfig разбирает конфигурацию в формате YAML (JSON тоже подходит)
+func ParseSanitizerConfig(data []byte) (*SanitizerConfig, error) {
+	var file sanitizerFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse sanitizer config: %w", err)
+	}
+
+	config := &SanitizerConfig{}
+	if file.ExtendDefaults {
+		config = DefaultSanitizerConfig()
+	}
+
+	if file.Mask != "" {
+		config.Mask = file.Mask
+	}
+	if config.Mask == "" {
+		config.Mask = "***REDACTED***"
+	}
+	if file.MaxBodySize > 0 {
+		config.MaxBodySize = file.MaxBodySize
+	}
+	if config.MaxBodySize == 0 {
+		config.MaxBodySize = DefaultSanitizerConfig().MaxBodySize
+	}
+
+	switch file.Truncation {
+	case "":
+	case TruncationBytes, TruncationJSON:
+		config.TruncationMode = file.Truncation
+	default:
+		return nil, fmt.Errorf("invalid truncation %q", file.Truncation)
+	}
+
+	switch file.HeaderMaskMode {
+	case "":
+		if config.HeaderMaskMode == "" {
+			config.HeaderMaskMode = HeaderMaskPartial
+		}
+	case HeaderMaskFull, HeaderMaskPartial:
+		config.HeaderMaskMode = file.HeaderMaskMode
+	default:
+		return nil, fmt.Errorf("invalid header_mask_mode %q", file.HeaderMaskMode)
+	}
+
+	config.SensitiveFields = append(config.SensitiveFields, file.SensitiveFields...)
+	config.HomoglyphFolding = config.HomoglyphFolding || file.Homoglyphs
+	config.SensitiveHeaders = append(config.SensitiveHeaders, file.SensitiveHeaders...)
+	config.VisibleCookies = append(config.VisibleCookies, file.VisibleCookies...)
+
+	for _, pattern := range file.Patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+		config.SensitivePatterns = append(config.SensitivePatterns, re)
+	}
+
+	if len(file.FieldStrategies) > 0 && config.FieldStrategies == nil {
+		config.FieldStrategies = make(map[string]FieldStrategy, len(file.FieldStrategies))
+	}
+	for field, strategy := range file.FieldStrategies {
+		switch strategy {
+		case FieldStrategyMask, FieldStrategyPartial, FieldStrategyHash, FieldStrategyRemove, FieldStrategyKeep:
+			config.FieldStrategies[strings.ToLower(field)] = strategy
+		default:
+			return nil, fmt.Errorf("invalid strategy %q for field %s", strategy, field)
+		}
+	}
+
+	if len(file.DataCategories) > 0 && config.DataCategories == nil {
+		config.DataCategories = make(map[string]DataCategory, len(file.DataCategories))
+	}
+	for rule, category := range file.DataCategories {
+		switch category {
+		case DataCredentials, DataFinancial, DataPersonal, DataHealth:
+			config.DataCategories[strings.ToLower(rule)] = category
+		default:
+			return nil, fmt.Errorf("invalid data category %q for rule %s", category, rule)
+		}
+	}
+
+	config.EnableEmailDetection = config.EnableEmailDetection || file.Detect.Email
+	config.EnablePhoneDetection = config.EnablePhoneDetection || file.Detect.Phone
+	config.EnableIBANDetection = config.EnableIBANDetection || file.Detect.IBAN
+	config.EnableSWIFTDetection = config.EnableSWIFTDetection || file.Detect.SWIFT
+
+	for _, locale := range file.PhoneLocales {
+		switch locale {
+		case PhoneLocaleKZ, PhoneLocaleRU, PhoneLocaleUS:
+			config.PhoneLocales = append(config.PhoneLocales, locale)
+		default:
+			return nil, fmt.Errorf("unknown phone locale %q", locale)
+		}
+	}
+
+	for _, name := range file.NationalIDs {
+		detector, ok := nationalIDDetectors[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown national id %q", name)
+		}
+		config.NationalIDs = append(config.NationalIDs, detector())
+	}
+
+	if config.BodyRules == nil {
+		config.BodyRules = defaultBodyRules(config)
+	}
+	if config.BodyCodecs == nil {
+		config.BodyCodecs = DefaultBodyCodecs()
+	}
+
+	return config, nil
+}
+
+// fieldStrategy возвращает стратегию для поля, если она задана
+func (s *Sanitizer) fieldStrategy(field string) (FieldStrategy, bool) {
+	if len(s.config.FieldStrategies) == 0 {
+		return "", false
+	}
+	strategy, ok := s.config.FieldStrategies[strings.ToLower(field)]
+	if ok && strategy != FieldStrategyKeep {
+		s.observeRedaction(RedactionFieldStrategy, strings.ToLower(field), field, 1)
+	}
+	return strategy, ok
+}
+
+// applyStrategy применяет стратегию к значению поля
+func (s *Sanitizer) applyStrategy(strategy FieldStrategy, value interface{}) interface{} {
+	switch strategy {
+	case FieldStrategyKeep:
+		return value
+	case FieldStrategyPartial:
+		return partialMask(fmt.Sprint(value), s.config.Mask)
+	case FieldStrategyHash:
+		sum := sha256.Sum256([]byte(fmt.Sprint(value)))
+		return "sha256:" + hex.EncodeToString(sum[:6])
+	default:
+		return s.config.Mask
+	}
+}
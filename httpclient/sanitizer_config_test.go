@@ -0,0 +1,100 @@
+package httpclient
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoadSanitizerConfigFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"sanitizer.yaml": {Data: []byte(`
+extend_defaults: true
+mask: "[hidden]"
+sensitive_fields: [internal_note]
+patterns:
+  - '(ref=)[A-Z0-9]+'
+field_strategies:
+  card_number: partial
+  customer_email: hash
+  debug: remove
+  author: keep
+detect:
+  iban: true
+national_ids: [kz_iin]
+`)},
+	}
+
+	config, err := LoadSanitizerConfigFS(fsys, "sanitizer.yaml")
+	if err != nil {
+		t.Fatalf("LoadSanitizerConfigFS() error = %v", err)
+	}
+
+	body := `{"author":"ann","card_number":"4111111111111111","customer_email":"a@b.kz","debug":"x",` +
+		`"internal_note":"n","password":"p","memo":"ref=ABC123 iin 900101300126"}`
+	got := NewSanitizer(config).SanitizeBody([]byte(body), "application/json")
+
+	for _, want := range []string{
+		`"author": "ann"`,
+		`"card_number": "4111[hidden]1111"`,
+		`"customer_email": "sha256:`,
+		`"internal_note": "[hidden]"`,
+		`"password": "[hidden]"`,
+		`"memo": "ref=[hidden] iin [hidden]"`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected %s in:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "debug") {
+		t.Errorf("removed field is present:\n%s", got)
+	}
+}
+
+func TestParseSanitizerConfigInvalid(t *testing.T) {
+	tests := map[string]string{
+		"pattern":  `patterns: ["(unclosed"]`,
+		"strategy": `field_strategies: {card: shred}`,
+		"id":       `national_ids: [unknown]`,
+	}
+
+	for name, data := range tests {
+		if _, err := ParseSanitizerConfig([]byte(data)); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestParseSanitizerConfigMaxBodySize(t *testing.T) {
+	body := []byte(`{"password":"hunter2","note":"` + strings.Repeat("lorem ipsum ", 15*1024) + `"}`)
+
+	tests := []struct {
+		config    string
+		truncated bool
+	}{
+		{config: "sensitive_fields: [password]\nmax_body_size: 204800", truncated: false},
+		{config: "extend_defaults: true\nmax_body_size: 204800", truncated: false},
+		{config: "sensitive_fields: [password]\nmax_body_size: 1024", truncated: true},
+		{config: "extend_defaults: true\nmax_body_size: 1024", truncated: true},
+	}
+
+	for _, tt := range tests {
+		config, err := ParseSanitizerConfig([]byte(tt.config))
+		if err != nil {
+			t.Fatalf("%q: ParseSanitizerConfig() error = %v", tt.config, err)
+		}
+
+		sanitizer := NewSanitizer(config)
+		if _, matched := sanitizer.Rules().Match("application/json", body); matched != tt.truncated {
+			t.Errorf("%q: truncate rule matched = %v, want %v", tt.config, matched, tt.truncated)
+		}
+
+		got := sanitizer.SanitizeBody(body, "application/json")
+		if strings.Contains(got, "hunter2") {
+			t.Errorf("%q: password is not redacted", tt.config)
+		}
+		if truncated := strings.Contains(got, "truncated"); truncated != tt.truncated {
+			t.Errorf("%q: truncated = %v, want %v", tt.config, truncated, tt.truncated)
+		}
+	}
+}
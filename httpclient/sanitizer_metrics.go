@@ -0,0 +1,121 @@
+package httpclient
+
+import "strings"
+
+// Категории скрытия для SanitizerMetrics
+const (
+	RedactionField         = "field"          // Поле из SensitiveFields, rule - совпавшее имя
+	RedactionFieldStrategy = "field_strategy" // Поле из FieldStrategies
+	RedactionHeader        = "header"         // Заголовок из SensitiveHeaders
+	RedactionCookie        = "cookie"         // Значение cookie в Cookie/Set-Cookie
+	RedactionPattern       = "pattern"        // Regex из SensitivePatterns, rule - выражение
+	RedactionDetector      = "detector"       // email, phone, iban, swift, национальные ID
+	RedactionBodyRule      = "body_rule"      // Правило body, только в отчете dry-run
+)
+
+// SanitizerMetrics интерфейс метрик санитайзера, позволяет убедиться что
+// скрытие срабатывает в продакшене и найти правила, которые никогда не совпадают
+// (реализуется metrics.SanitizerMetrics)
+type SanitizerMetrics interface {
+	// ObserveRedaction учитывает count скрытых значений, count=0 регистрирует правило
+	ObserveRedaction(category, rule string, count int)
+	// ObserveBodyRule учитывает срабатывания правила body и не попавшие в лог байты
+	ObserveBodyRule(rule string, action BodyAction, matches, droppedBytes int)
+	// ObserveDataCategories учитывает обмен с хостом host, в котором были
+	// скрыты значения категорий counts
+	ObserveDataCategories(host string, counts map[DataCategory]int)
+}
+
+// SetMetrics включает метрики. Все настроенные правила регистрируются
+// с нулевым значением, чтобы не сработавшие правила были видны
+func (s *Sanitizer) SetMetrics(metrics SanitizerMetrics) {
+	s.metrics = metrics
+	if metrics == nil {
+		return
+	}
+
+	for _, field := range s.config.SensitiveFields {
+		metrics.ObserveRedaction(RedactionField, field, 0)
+	}
+	for field, strategy := range s.config.FieldStrategies {
+		if strategy != FieldStrategyKeep {
+			metrics.ObserveRedaction(RedactionFieldStrategy, field, 0)
+		}
+	}
+	for _, header := range s.config.SensitiveHeaders {
+		metrics.ObserveRedaction(RedactionHeader, strings.ToLower(header), 0)
+	}
+	metrics.ObserveRedaction(RedactionCookie, cookieRuleDefault, 0)
+	for _, pattern := range s.config.SensitivePatterns {
+		metrics.ObserveRedaction(RedactionPattern, pattern.String(), 0)
+	}
+
+	detectors := map[string]bool{
+		"iban":  s.config.EnableIBANDetection,
+		"swift": s.config.EnableSWIFTDetection,
+		"email": s.config.EnableEmailDetection,
+		"phone": s.config.EnablePhoneDetection,
+	}
+	for name, enabled := range detectors {
+		if enabled {
+			metrics.ObserveRedaction(RedactionDetector, name, 0)
+		}
+	}
+	for _, detector := range s.config.NationalIDs {
+		metrics.ObserveRedaction(RedactionDetector, detector.Name, 0)
+	}
+
+	for _, rule := range s.rules.Rules() {
+		metrics.ObserveBodyRule(rule.Name, rule.Action, 0, 0)
+	}
+}
+
+// redact применяет замену и учитывает количество появившихся масок
+func (s *Sanitizer) redact(category, rule, text string, replace func(string) string) string {
+	result := replace(text)
+
+	if (s.metrics != nil || s.report != nil || s.categories != nil) && s.config.Mask != "" && result != text {
+		count := strings.Count(result, s.config.Mask) - strings.Count(text, s.config.Mask)
+		if count > 0 {
+			s.observeRedaction(category, rule, "", count)
+		}
+	}
+
+	return result
+}
+
+// observeRedaction учитывает скрытые значения в метриках и отчете dry-run.
+// target - имя поля или заголовка, пусто для значений найденных в тексте
+func (s *Sanitizer) observeRedaction(category, rule, target string, count int) {
+	if s.metrics != nil {
+		s.metrics.ObserveRedaction(category, rule, count)
+	}
+	if s.report != nil {
+		s.report.record(category, rule, target, count)
+	}
+	if s.categories != nil && count > 0 {
+		s.categories.add(s.dataCategory(rule), count)
+	}
+}
+
+// observeBodyRule учитывает сработавшее правило body
+func (s *Sanitizer) observeBodyRule(rule BodyProcessingRule, size int) {
+	if s.report != nil {
+		s.report.record(RedactionBodyRule, rule.Name, string(rule.Action), 1)
+	}
+	if s.metrics == nil {
+		return
+	}
+
+	dropped := 0
+	switch rule.Action {
+	case BodyActionSkip, BodyActionSummarize:
+		dropped = size
+	case BodyActionTruncate:
+		if size > s.config.MaxBodySize {
+			dropped = size - s.config.MaxBodySize
+		}
+	}
+
+	s.metrics.ObserveBodyRule(rule.Name, rule.Action, 1, dropped)
+}
@@ -0,0 +1,78 @@
+package httpclient
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// recordingSanitizerMetrics запоминает наблюдения метрик санитайзера
+type recordingSanitizerMetrics struct {
+	mu        sync.Mutex
+	redacted  map[string]int
+	bodyRules map[string]int
+	dropped   int
+	exchanges map[string]int
+}
+
+func newRecordingSanitizerMetrics() *recordingSanitizerMetrics {
+	return &recordingSanitizerMetrics{redacted: map[string]int{}, bodyRules: map[string]int{}, exchanges: map[string]int{}}
+}
+
+func (m *recordingSanitizerMetrics) ObserveRedaction(category, rule string, count int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.redacted[category+"/"+rule] += count
+}
+
+func (m *recordingSanitizerMetrics) ObserveBodyRule(rule string, action BodyAction, matches, droppedBytes int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.bodyRules[fmt.Sprintf("%s/%s", rule, action)] += matches
+	m.dropped += droppedBytes
+}
+
+func (m *recordingSanitizerMetrics) ObserveDataCategories(host string, counts map[DataCategory]int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for category, count := range counts {
+		m.exchanges[fmt.Sprintf("%s/%s", host, category)] += count
+	}
+}
+
+func TestSanitizerMetrics(t *testing.T) {
+	config := DefaultSanitizerConfig()
+	config.EnableEmailDetection = true
+
+	metrics := newRecordingSanitizerMetrics()
+	sanitizer := NewSanitizer(config)
+	sanitizer.SetMetrics(metrics)
+
+	if _, ok := metrics.redacted["field/ssn"]; !ok {
+		t.Error("configured rules must be registered with zero value")
+	}
+
+	sanitizer.SanitizeBody([]byte(`{"password":"x","note":"mail a@b.kz and c@d.kz"}`), "application/json")
+	sanitizer.SanitizeHeaders(map[string][]string{"Authorization": {"Bearer abc"}})
+	sanitizer.SanitizeBody([]byte(strings.Repeat("a", 10)), "image/png")
+
+	checks := map[string]int{
+		"field/password":       1,
+		"detector/email":       2,
+		"header/authorization": 1,
+		"field/ssn":            0,
+	}
+	for key, want := range checks {
+		if got := metrics.redacted[key]; got != want {
+			t.Errorf("%s = %d, want %d", key, got, want)
+		}
+	}
+
+	if got := metrics.bodyRules["binary/skip"]; got != 1 {
+		t.Errorf("binary/skip = %d, want 1", got)
+	}
+	if metrics.dropped != 10 {
+		t.Errorf("dropped bytes = %d, want 10", metrics.dropped)
+	}
+}
@@ -1,6 +1,7 @@
 package httpclient
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"net/url"
@@ -10,8 +11,10 @@ import (
 // SanitizerConfigNoRegex конфигурация без regex
 type SanitizerConfigNoRegex struct {
 	SensitiveFields  []string
+	HomoglyphFolding bool
 	Mask             string
 	MaxBodySize      int
+	TruncationMode   TruncationMode
 	BodyRules        []BodyProcessingRule
 	HeaderMaskMode   HeaderMaskMode
 	SensitiveHeaders []string
@@ -23,11 +26,23 @@ type SanitizerConfigNoRegex struct {
 	EnableCreditCardDetection  bool
 	EnableEmailDetection       bool
 	EnableAWSKeyDetection      bool
+
+	// Телефоны и их локальные форматы, по умолчанию DefaultPhoneLocales
+	EnablePhoneDetection bool
+	PhoneLocales         []PhoneLocale
+
+	// IBAN (mod-97), SWIFT/BIC и национальные идентификаторы
+	EnableIBANDetection  bool
+	EnableSWIFTDetection bool
+	NationalIDs          []NationalIDDetector
+
+	// Кодеки бинарных форматов, см. SanitizerConfig.BodyCodecs
+	BodyCodecs []BodyCodec
 }
 
 // DefaultSanitizerConfigNoRegex дефолтная конфигурация без regex
 func DefaultSanitizerConfigNoRegex() *SanitizerConfigNoRegex {
-	return &SanitizerConfigNoRegex{
+	config := &SanitizerConfigNoRegex{
 		SensitiveFields: []string{
 			"password", "passwd", "pwd", "secret", "token",
 			"api_key", "apikey", "api_secret", "access_token", "refresh_token",
@@ -36,30 +51,10 @@ func DefaultSanitizerConfigNoRegex() *SanitizerConfigNoRegex {
 			"ssn", "credit_card", "card_number", "cvv", "cvc",
 			"private_key", "encryption_key",
 		},
-		Mask:        "***REDACTED***",
-		MaxBodySize: 100 * 1024,
-		BodyRules: []BodyProcessingRule{
-			{
-				Condition: func(contentType string, body []byte, size int) bool {
-					return isBinaryContent(contentType)
-				},
-				Action:  BodyActionSkip,
-				Message: "[Binary content - not logged]",
-			},
-			{
-				Condition: func(contentType string, body []byte, size int) bool {
-					return size > 1024 && looksLikeBase64(body)
-				},
-				Action:  BodyActionSkip,
-				Message: "[Base64 encoded data - not logged]",
-			},
-			{
-				Condition: func(contentType string, body []byte, size int) bool {
-					return size > 100*1024
-				},
-				Action: BodyActionTruncate,
-			},
-		},
+		Mask:                       "***REDACTED***",
+		MaxBodySize:                100 * 1024,
+		TruncationMode:             TruncationJSON,
+		BodyCodecs:                 DefaultBodyCodecs(),
 		HeaderMaskMode:             HeaderMaskPartial,
 		EnableBearerTokenDetection: true,
 		EnableAPIKeyDetection:      true,
@@ -67,11 +62,45 @@ func DefaultSanitizerConfigNoRegex() *SanitizerConfigNoRegex {
 		EnableCreditCardDetection:  true,
 		EnableAWSKeyDetection:      true,
 	}
+	config.BodyRules = defaultBodyRulesNoRegex(config)
+	return config
+}
+
+// defaultBodyRulesNoRegex дефолтные правила без regex, порог truncate
+// читается из config.MaxBodySize, как в defaultBodyRules
+func defaultBodyRulesNoRegex(config *SanitizerConfigNoRegex) []BodyProcessingRule {
+	return []BodyProcessingRule{
+		{
+			Name: "binary",
+			Condition: func(contentType string, body []byte, size int) bool {
+				return isBinaryContent(contentType)
+			},
+			Action:  BodyActionSkip,
+			Message: "[Binary content - not logged]",
+		},
+		{
+			Name: "base64",
+			Condition: func(contentType string, body []byte, size int) bool {
+				return size > 1024 && looksLikeBase64(body)
+			},
+			Action:  BodyActionSkip,
+			Message: "[Base64 encoded data - not logged]",
+		},
+		{
+			Name: "large",
+			Condition: func(contentType string, body []byte, size int) bool {
+				return size > config.MaxBodySize
+			},
+			Action: BodyActionTruncate,
+		},
+	}
 }
 
 // SanitizerNoRegex санитайзер без regex
 type SanitizerNoRegex struct {
 	config *SanitizerConfigNoRegex
+	rules  *RuleEngine
+	fields *fieldMatcher
 }
 
 // NewSanitizerNoRegex создает санитайзер без regex
@@ -79,7 +108,16 @@ func NewSanitizerNoRegex(config *SanitizerConfigNoRegex) *SanitizerNoRegex {
 	if config == nil {
 		config = DefaultSanitizerConfigNoRegex()
 	}
-	return &SanitizerNoRegex{config: config}
+	return &SanitizerNoRegex{
+		config: config,
+		rules:  NewRuleEngine(config.BodyRules, nil),
+		fields: newFieldMatcher(config.SensitiveFields, config.HomoglyphFolding),
+	}
+}
+
+// Rules возвращает движок правил обработки body
+func (s *SanitizerNoRegex) Rules() *RuleEngine {
+	return s.rules
 }
 
 // SanitizeBody очищает body без использования regex
@@ -91,27 +129,52 @@ func (s *SanitizerNoRegex) SanitizeBody(body []byte, contentType string) string
 	size := len(body)
 
 	// Применяем правила обработки
-	for _, rule := range s.config.BodyRules {
-		if rule.Condition(contentType, body, size) {
-			switch rule.Action {
-			case BodyActionSkip:
-				if rule.Message != "" {
-					return rule.Message
-				}
-				return "[Body not logged]"
-			case BodyActionSummarize:
-				return s.summarizeBody(body, contentType, size)
-			case BodyActionTruncate:
-				return s.truncateBody(body, contentType)
+	if rule, ok := s.rules.Match(contentType, body); ok {
+		switch rule.Action {
+		case BodyActionSkip:
+			if rule.Message != "" {
+				return rule.Message
 			}
+			return "[Body not logged]"
+		case BodyActionSummarize:
+			return s.summarizeBody(body, contentType, size)
+		case BodyActionTruncate:
+			return s.truncateBody(body, contentType)
 		}
 	}
 
-	// Определяем формат
+	// MaxBodySize соблюдается и без правила truncate
+	if s.config.MaxBodySize > 0 && size > s.config.MaxBodySize {
+		return s.truncateBody(body, contentType)
+	}
+
+	return s.sanitizeFormat(body, contentType)
+}
+
+// sanitizeFormat санитизирует тело по формату без вычисления правил
+func (s *SanitizerNoRegex) sanitizeFormat(body []byte, contentType string) string {
+	// Бинарные форматы с известной схемой декодируем после правил: тела,
+	// которые пропускаются или обрезаются по размеру, не разбираются
+	if decoded, ok := decodeWithCodecs(s.config.BodyCodecs, contentType, body); ok {
+		if result, ok := marshalDecoded(s.sanitizeValue(decoded)); ok {
+			return result
+		}
+	}
+
+	// Определяем формат, NDJSON раньше JSON: application/jsonl содержит application/json
+	if isNDJSON(contentType) || looksLikeNDJSON(body) {
+		return sanitizeNDJSON(body, 0, s.sanitizeValue, s.sanitizeText)
+	}
+
 	if isJSON(contentType) || looksLikeJSON(string(body)) {
 		return s.sanitizeJSON(string(body))
 	}
 
+	// HTML раньше XML: application/xhtml+xml тоже заканчивается на +xml
+	if isHTML(contentType) || looksLikeHTML(string(body)) {
+		return sanitizeHTML(string(body), s.config.Mask, s.isSensitiveField, s.sanitizeText)
+	}
+
 	if isXML(contentType) || looksLikeXML(string(body)) {
 		return s.sanitizeXML(string(body))
 	}
@@ -174,80 +237,7 @@ func (s *SanitizerNoRegex) sanitizeValue(value interface{}) interface{} {
 
 // sanitizeXML обрабатывает XML простым поиском
 func (s *SanitizerNoRegex) sanitizeXML(body string) string {
-	result := body
-
-	// Для каждого чувствительного поля
-	for _, field := range s.config.SensitiveFields {
-		// Ищем <field>value</field>
-		result = s.replaceXMLTag(result, field)
-		// Ищем атрибуты field="value"
-		result = s.replaceXMLAttribute(result, field)
-	}
-
-	// Применяем детекторы
-	result = s.sanitizeText(result)
-
-	return result
-}
-
-// replaceXMLTag заменяет содержимое XML тега
-func (s *SanitizerNoRegex) replaceXMLTag(text, fieldName string) string {
-	openTag := "<" + fieldName + ">"
-	closeTag := "</" + fieldName + ">"
-
-	result := text
-	for {
-		start := strings.Index(result, openTag)
-		if start == -1 {
-			// Пробуем case-insensitive
-			start = indexCaseInsensitive(result, openTag)
-			if start == -1 {
-				break
-			}
-		}
-
-		end := strings.Index(result[start:], closeTag)
-		if end == -1 {
-			break
-		}
-
-		// Заменяем содержимое между тегами
-		beforeValue := result[:start+len(openTag)]
-		afterValue := result[start+end:]
-		result = beforeValue + s.config.Mask + afterValue
-	}
-
-	return result
-}
-
-// replaceXMLAttribute заменяет значения XML атрибутов
-func (s *SanitizerNoRegex) replaceXMLAttribute(text, fieldName string) string {
-	result := text
-
-	// Ищем field="value" или field='value'
-	for _, quote := range []string{`"`, `'`} {
-		pattern := fieldName + "=" + quote
-
-		for {
-			start := indexCaseInsensitive(result, pattern)
-			if start == -1 {
-				break
-			}
-
-			valueStart := start + len(pattern)
-			valueEnd := strings.Index(result[valueStart:], quote)
-			if valueEnd == -1 {
-				break
-			}
-
-			// Заменяем значение
-			before := result[:valueStart]
-			after := result[valueStart+valueEnd:]
-			result = before + s.config.Mask + after
-		}
-	}
-
-	return result
+	return sanitizeXML(body, s.config.Mask, s.isSensitiveField, s.sanitizeText)
 }
 
 // sanitizeFormURLEncoded обрабатывает form data
@@ -297,6 +287,24 @@ func (s *SanitizerNoRegex) sanitizeText(text string) string {
 		result = s.hideAWSKeys(result)
 	}
 
+	if s.config.EnableIBANDetection {
+		result = hideIBANs(result, s.config.Mask)
+	}
+
+	if s.config.EnableSWIFTDetection {
+		result = hideBICs(result, s.config.Mask)
+	}
+
+	result = hideNationalIDs(result, s.config.Mask, s.config.NationalIDs)
+
+	if s.config.EnableEmailDetection {
+		result = hideEmails(result, s.config.Mask)
+	}
+
+	if s.config.EnablePhoneDetection {
+		result = hidePhones(result, s.config.Mask, s.config.PhoneLocales)
+	}
+
 	return result
 }
 
@@ -490,23 +498,41 @@ func (s *SanitizerNoRegex) hideAWSKeys(text string) string {
 // Вспомогательные функции
 
 func (s *SanitizerNoRegex) isSensitiveField(fieldName string) bool {
-	lower := strings.ToLower(fieldName)
-	for _, sensitive := range s.config.SensitiveFields {
-		if strings.Contains(lower, strings.ToLower(sensitive)) {
-			return true
-		}
-	}
-	return false
+	_, ok := s.fields.match(fieldName)
+	return ok
 }
 
 func (s *SanitizerNoRegex) truncateBody(body []byte, contentType string) string {
 	maxSize := s.config.MaxBodySize
 	if len(body) <= maxSize {
-		return s.SanitizeBody(body, contentType)
+		// Не через SanitizeBody: правило truncate сработало бы снова
+		return s.sanitizeFormat(body, contentType)
 	}
 
-	truncated := body[:maxSize]
-	return string(truncated) + "\n... [truncated, total: " + formatSize(len(body)) + "]"
+	// NDJSON обрезаем по границам строк, каждая строка санитизирована
+	if isNDJSON(contentType) || looksLikeNDJSON(body) {
+		return sanitizeNDJSON(body, maxSize, s.sanitizeValue, s.sanitizeText)
+	}
+
+	if s.config.TruncationMode == TruncationJSON && (isJSON(contentType) || looksLikeJSON(string(body))) {
+		if result, ok := truncateJSON(body, maxSize, s.sanitizeValue); ok {
+			return result
+		}
+	}
+
+	// Санитизируем тело целиком и только потом обрезаем
+	result := s.sanitizeFormat(body, contentType)
+	if isJSON(contentType) || looksLikeJSON(string(body)) {
+		var compact bytes.Buffer
+		if json.Compact(&compact, []byte(result)) == nil {
+			result = compact.String()
+		}
+	}
+	if len(result) <= maxSize {
+		return result
+	}
+
+	return cutUTF8(result, maxSize) + "\n... [truncated, total: " + formatSize(len(body)) + "]"
 }
 
 func (s *SanitizerNoRegex) summarizeBody(body []byte, contentType string, size int) string {
@@ -564,12 +590,6 @@ func (s *SanitizerNoRegex) replaceCreditCardInText(text, cardDigits string) stri
 
 // Утилиты
 
-func indexCaseInsensitive(text, substr string) int {
-	lower := strings.ToLower(text)
-	lowerSubstr := strings.ToLower(substr)
-	return strings.Index(lower, lowerSubstr)
-}
-
 func isWhitespace(ch byte) bool {
 	return ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r'
 }
@@ -0,0 +1,94 @@
+package httpclient
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// RedactionFinding значение, которое санитайзер скрыл бы в режиме DryRun
+type RedactionFinding struct {
+	Category string // RedactionField, RedactionHeader, RedactionDetector, RedactionBodyRule и т.д.
+	Rule     string // Сработавшее правило: чувствительное поле, имя детектора, паттерн
+	Target   string // Имя поля или заголовка как в трафике, пусто для найденного в тексте
+	Count    int
+}
+
+// RedactionReport отчет dry-run с момента создания санитайзера или ResetReport
+type RedactionReport struct {
+	Since    time.Time
+	Findings []RedactionFinding // Отсортированы по убыванию Count
+}
+
+type findingKey struct {
+	category, rule, target string
+}
+
+// redactionRecorder накапливает находки dry-run
+type redactionRecorder struct {
+	mu       sync.Mutex
+	since    time.Time
+	findings map[findingKey]int
+}
+
+func newRedactionRecorder() *redactionRecorder {
+	return &redactionRecorder{
+		since:    time.Now(),
+		findings: make(map[findingKey]int),
+	}
+}
+
+func (r *redactionRecorder) record(category, rule, target string, count int) {
+	r.mu.Lock()
+	r.findings[findingKey{category, rule, target}] += count
+	r.mu.Unlock()
+}
+
+// Report возвращает находки режима DryRun. Для санитайзера без DryRun отчет пустой
+func (s *Sanitizer) Report() RedactionReport {
+	if s.report == nil {
+		return RedactionReport{}
+	}
+
+	s.report.mu.Lock()
+	report := RedactionReport{
+		Since:    s.report.since,
+		Findings: make([]RedactionFinding, 0, len(s.report.findings)),
+	}
+	for key, count := range s.report.findings {
+		report.Findings = append(report.Findings, RedactionFinding{
+			Category: key.category,
+			Rule:     key.rule,
+			Target:   key.target,
+			Count:    count,
+		})
+	}
+	s.report.mu.Unlock()
+
+	sort.Slice(report.Findings, func(i, j int) bool {
+		a, b := report.Findings[i], report.Findings[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		if a.Category != b.Category {
+			return a.Category < b.Category
+		}
+		if a.Rule != b.Rule {
+			return a.Rule < b.Rule
+		}
+		return a.Target < b.Target
+	})
+	return report
+}
+
+// ResetReport очищает накопленные находки, например после выгрузки отчета
+func (s *Sanitizer) ResetReport() {
+	if s.report == nil {
+		return
+	}
+
+	s.report.mu.Lock()
+	s.report.since = time.Now()
+	s.report.findings = make(map[findingKey]int)
+	s.report.mu.Unlock()
+}
@@ -0,0 +1,116 @@
+package httpclient
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSanitizerDryRun(t *testing.T) {
+	config := DefaultSanitizerConfig()
+	config.EnableEmailDetection = true
+	config.DryRun = true
+	sanitizer := NewSanitizer(config)
+
+	body := `{"password":"secret","note":"mail a@b.kz"}`
+	if got := sanitizer.SanitizeBody([]byte(body), "application/json"); got != body {
+		t.Errorf("dry run must not alter body, got %s", got)
+	}
+
+	headers := sanitizer.SanitizeHeaders(map[string][]string{"Authorization": {"Bearer abc"}})
+	if headers["Authorization"] != "Bearer abc" {
+		t.Errorf("dry run must not alter headers, got %q", headers["Authorization"])
+	}
+
+	sanitizer.SanitizeBody([]byte(`{"Password":"other"}`), "application/json")
+
+	report := sanitizer.Report()
+	if report.Since.IsZero() {
+		t.Error("report must have start time")
+	}
+
+	found := map[string]int{}
+	for _, f := range report.Findings {
+		found[f.Category+"/"+f.Rule+"/"+f.Target] = f.Count
+	}
+	checks := map[string]int{
+		"field/password/password":            1,
+		"field/password/Password":            1,
+		"detector/email/":                    1,
+		"header/authorization/Authorization": 1,
+	}
+	for key, want := range checks {
+		if got := found[key]; got != want {
+			t.Errorf("%s = %d, want %d (report %v)", key, got, want, report.Findings)
+		}
+	}
+
+	sanitizer.ResetReport()
+	if n := len(sanitizer.Report().Findings); n != 0 {
+		t.Errorf("expected empty report after reset, got %d findings", n)
+	}
+}
+
+func TestSanitizerDryRunBodyRule(t *testing.T) {
+	config := DefaultSanitizerConfig()
+	config.DryRun = true
+	sanitizer := NewSanitizer(config)
+
+	body := strings.Repeat("a", 10)
+	if got := sanitizer.SanitizeBody([]byte(body), "image/png"); got != body {
+		t.Errorf("dry run must not skip body, got %s", got)
+	}
+
+	report := sanitizer.Report()
+	if len(report.Findings) != 1 || report.Findings[0].Category != RedactionBodyRule || report.Findings[0].Rule != "binary" {
+		t.Errorf("unexpected findings %v", report.Findings)
+	}
+}
+
+func TestSanitizerReportDisabled(t *testing.T) {
+	sanitizer := NewSanitizer(nil)
+	sanitizer.SanitizeBody([]byte(`{"password":"x"}`), "application/json")
+
+	if report := sanitizer.Report(); len(report.Findings) != 0 || !report.Since.IsZero() {
+		t.Errorf("report must be empty without DryRun, got %+v", report)
+	}
+}
+
+func TestLoggingShadowSanitizer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"nickname":"abc"}`))
+	}))
+	defer server.Close()
+
+	shadowConfig := DefaultSanitizerConfig()
+	shadowConfig.SensitiveFields = append(shadowConfig.SensitiveFields, "nickname")
+	shadowConfig.DryRun = true
+	shadow := NewSanitizer(shadowConfig)
+
+	logger := &recordingLogger{}
+	config := DefaultLoggingConfig(logger)
+	config.ShadowSanitizer = shadow
+
+	client := &http.Client{Transport: NewLoggingRoundTripper(nil, config)}
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if !strings.Contains(logger.all(), `"nickname": "abc"`) {
+		t.Error("active sanitizer must keep nickname, shadow must not affect logs")
+	}
+
+	var hits int
+	for _, f := range shadow.Report().Findings {
+		if f.Category == RedactionField && f.Rule == "nickname" {
+			hits += f.Count
+		}
+	}
+	if hits != 1 {
+		t.Errorf("shadow sanitizer must record nickname once, got %d", hits)
+	}
+}
@@ -0,0 +1,42 @@
+package httpclient
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestSanitizer_SanitizeValue(t *testing.T) {
+	sanitizer := NewSanitizer(&SanitizerConfig{
+		SensitiveFields: []string{"password"},
+		Mask:            "[HIDDEN]",
+		MaxBodySize:     10,
+	})
+
+	type credentials struct {
+		Login    string `json:"login"`
+		Password string `json:"password"`
+	}
+	value := struct {
+		Credentials credentials `json:"credentials"`
+		Note        string      `json:"note"`
+	}{
+		Credentials: credentials{Login: "john", Password: "secret123"},
+		Note:        "longer than MaxBodySize",
+	}
+
+	result, err := sanitizer.SanitizeValue(value)
+	if err != nil {
+		t.Fatalf("SanitizeValue() error = %v", err)
+	}
+
+	data, _ := json.Marshal(result)
+	got := string(data)
+	want := `{"credentials":{"login":"john","password":"[HIDDEN]"},"note":"longer than MaxBodySize"}`
+	if got != want {
+		t.Errorf("SanitizeValue() = %s, want %s", got, want)
+	}
+
+	if _, err := sanitizer.SanitizeValue(make(chan int)); err == nil {
+		t.Error("SanitizeValue() should fail on unserializable value")
+	}
+}
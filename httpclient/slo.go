@@ -0,0 +1,433 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Objective цель уровня обслуживания эндпоинта. Нулевые SuccessRate и
+// Latency не проверяются
+type Objective struct {
+	// SuccessRate минимальная доля успешных запросов, например 0.999
+	SuccessRate float64
+	// Latency максимальная задержка на перцентиле LatencyPercentile
+	Latency time.Duration
+	// LatencyPercentile по умолчанию 0.99
+	LatencyPercentile float64
+	// MinRequests меньше запросов в окне цель не оценивается, по умолчанию 20
+	MinRequests int64
+}
+
+// SLOConfig конфигурация SLOTracker
+type SLOConfig struct {
+	// Window скользящее окно, по умолчанию 5 минут
+	Window time.Duration
+	// Buckets на сколько частей делится окно, по умолчанию 10.
+	// Цели проверяются не чаще раза в часть окна
+	Buckets int
+	// Objective цель по умолчанию
+	Objective Objective
+	// Objectives цели по ключу "host/path/template" или "host"
+	Objectives map[string]Objective
+	// IsFailure по умолчанию DefaultIsFailure
+	IsFailure func(resp *http.Response, err error) bool
+	// PathTemplate по умолчанию шаблон из WithPathTemplate, иначе путь,
+	// в котором числа, UUID и длинные hex сегменты заменены на {id}
+	PathTemplate func(req *http.Request) string
+	// MaxEndpoints ограничивает число эндпоинтов, остальные учитываются
+	// как host/{other}. По умолчанию 500
+	MaxEndpoints int
+	// OnBreach вызывается, когда эндпоинт перестает укладываться в цель.
+	// Вызывается в горутине запроса, не блокируйте
+	OnBreach func(stats EndpointStats)
+	// OnRecover вызывается, когда эндпоинт снова укладывается в цель
+	OnRecover func(stats EndpointStats)
+}
+
+// DefaultSLOConfig дефолтная конфигурация
+func DefaultSLOConfig() SLOConfig {
+	return SLOConfig{
+		Window:       5 * time.Minute,
+		Buckets:      10,
+		IsFailure:    DefaultIsFailure,
+		PathTemplate: defaultPathTemplate,
+		MaxEndpoints: 500,
+	}
+}
+
+// EndpointStats показатели эндпоинта за окно
+type EndpointStats struct {
+	Host        string
+	Path        string
+	Requests    int64
+	Failures    int64
+	SuccessRate float64
+	P50         time.Duration
+	P90         time.Duration
+	P99         time.Duration
+	// ObjectiveLatency задержка на перцентиле цели
+	ObjectiveLatency time.Duration
+	Objective        Objective
+	// Violations нарушенные цели, пусто если эндпоинт укладывается в цель
+	Violations []string
+}
+
+// Breached сообщает о нарушении цели
+func (s EndpointStats) Breached() bool {
+	return len(s.Violations) > 0
+}
+
+// sloOtherPath путь эндпоинтов сверх MaxEndpoints
+const sloOtherPath = "/{other}"
+
+// latencyBounds границы гистограммы задержек от 1ms до ~60s с шагом 1.5x,
+// перцентили интерполируются внутри корзины
+var latencyBounds = func() []time.Duration {
+	var bounds []time.Duration
+	for b := float64(time.Millisecond); b < float64(time.Minute); b *= 1.5 {
+		bounds = append(bounds, time.Duration(b))
+	}
+	return append(bounds, time.Minute)
+}()
+
+// SLOTracker считает долю успешных запросов и перцентили задержки по
+// host и шаблону пути в скользящем окне. Один трекер можно разделять между
+// клиентами, метрики экспортирует metrics.Registry.RegisterSLO
+type SLOTracker struct {
+	config     SLOConfig
+	bucketSize time.Duration
+	now        func() time.Time
+
+	mu        sync.RWMutex
+	endpoints map[string]*sloEndpoint
+}
+
+// NewSLOTracker создает трекер
+func NewSLOTracker(config SLOConfig) *SLOTracker {
+	defaults := DefaultSLOConfig()
+	if config.Window <= 0 {
+		config.Window = defaults.Window
+	}
+	if config.Buckets <= 0 {
+		config.Buckets = defaults.Buckets
+	}
+	if config.IsFailure == nil {
+		config.IsFailure = defaults.IsFailure
+	}
+	if config.PathTemplate == nil {
+		config.PathTemplate = defaults.PathTemplate
+	}
+	if config.MaxEndpoints <= 0 {
+		config.MaxEndpoints = defaults.MaxEndpoints
+	}
+
+	return &SLOTracker{
+		config:     config,
+		bucketSize: config.Window / time.Duration(config.Buckets),
+		now:        time.Now,
+		endpoints:  make(map[string]*sloEndpoint),
+	}
+}
+
+// Observe учитывает запрос к эндпоинту
+func (t *SLOTracker) Observe(host, path string, duration time.Duration, failed bool) {
+	e := t.endpoint(host, path)
+	epoch := t.epoch()
+
+	e.mu.Lock()
+	b := e.bucket(epoch)
+	b.requests++
+	if failed {
+		b.failures++
+	}
+	b.latency[latencyBucket(duration)]++
+
+	// Цель проверяется при первом запросе в новой части окна
+	var (
+		stats   EndpointStats
+		changed bool
+	)
+	if e.evaluated != epoch {
+		e.evaluated = epoch
+		stats = e.stats(epoch)
+		if breached := stats.Breached(); breached != e.breached {
+			e.breached = breached
+			changed = true
+		}
+	}
+	e.mu.Unlock()
+
+	if !changed {
+		return
+	}
+	if stats.Breached() && t.config.OnBreach != nil {
+		t.config.OnBreach(stats)
+	} else if !stats.Breached() && t.config.OnRecover != nil {
+		t.config.OnRecover(stats)
+	}
+}
+
+// Stats возвращает показатели всех эндпоинтов, отсортированные по host и пути
+func (t *SLOTracker) Stats() []EndpointStats {
+	t.mu.RLock()
+	endpoints := make([]*sloEndpoint, 0, len(t.endpoints))
+	for _, e := range t.endpoints {
+		endpoints = append(endpoints, e)
+	}
+	t.mu.RUnlock()
+
+	epoch := t.epoch()
+	stats := make([]EndpointStats, 0, len(endpoints))
+	for _, e := range endpoints {
+		e.mu.Lock()
+		s := e.stats(epoch)
+		e.mu.Unlock()
+		if s.Requests > 0 {
+			stats = append(stats, s)
+		}
+	}
+
+	slices.SortFunc(stats, func(a, b EndpointStats) int {
+		return strings.Compare(a.Host+a.Path, b.Host+b.Path)
+	})
+	return stats
+}
+
+func (t *SLOTracker) epoch() int64 {
+	return t.now().UnixNano() / int64(t.bucketSize)
+}
+
+// endpoint возвращает или создает эндпоинт, сверх MaxEndpoints путь
+// заменяется на sloOtherPath
+func (t *SLOTracker) endpoint(host, path string) *sloEndpoint {
+	key := host + path
+
+	t.mu.RLock()
+	e, ok := t.endpoints[key]
+	full := len(t.endpoints) >= t.config.MaxEndpoints
+	t.mu.RUnlock()
+	if ok {
+		return e
+	}
+	if full {
+		path = sloOtherPath
+		key = host + path
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if e, ok := t.endpoints[key]; ok {
+		return e
+	}
+	e = &sloEndpoint{
+		host:      host,
+		path:      path,
+		objective: t.objective(host, path),
+		buckets:   make([]sloBucket, t.config.Buckets),
+	}
+	for i := range e.buckets {
+		e.buckets[i].latency = make([]int64, len(latencyBounds)+1)
+	}
+	t.endpoints[key] = e
+	return e
+}
+
+// objective ищет цель по host и пути, затем по host
+func (t *SLOTracker) objective(host, path string) Objective {
+	objective, ok := t.config.Objectives[host+path]
+	if !ok {
+		objective, ok = t.config.Objectives[host]
+	}
+	if !ok {
+		objective = t.config.Objective
+	}
+
+	if objective.LatencyPercentile <= 0 || objective.LatencyPercentile >= 1 {
+		objective.LatencyPercentile = 0.99
+	}
+	if objective.MinRequests <= 0 {
+		objective.MinRequests = 20
+	}
+	return objective
+}
+
+type sloBucket struct {
+	epoch    int64
+	requests int64
+	failures int64
+	latency  []int64
+}
+
+type sloEndpoint struct {
+	host      string
+	path      string
+	objective Objective
+
+	mu        sync.Mutex
+	buckets   []sloBucket
+	evaluated int64
+	breached  bool
+}
+
+// bucket возвращает часть окна для epoch, сбрасывая устаревшую
+func (e *sloEndpoint) bucket(epoch int64) *sloBucket {
+	b := &e.buckets[epoch%int64(len(e.buckets))]
+	if b.epoch != epoch {
+		b.epoch = epoch
+		b.requests = 0
+		b.failures = 0
+		clear(b.latency)
+	}
+	return b
+}
+
+// stats суммирует части окна, блокировка должна быть взята
+func (e *sloEndpoint) stats(epoch int64) EndpointStats {
+	stats := EndpointStats{Host: e.host, Path: e.path, Objective: e.objective}
+	latency := make([]int64, len(latencyBounds)+1)
+
+	oldest := epoch - int64(len(e.buckets)) + 1
+	for i := range e.buckets {
+		b := &e.buckets[i]
+		if b.epoch < oldest || b.epoch > epoch {
+			continue
+		}
+		stats.Requests += b.requests
+		stats.Failures += b.failures
+		for j, n := range b.latency {
+			latency[j] += n
+		}
+	}
+	if stats.Requests == 0 {
+		return stats
+	}
+
+	stats.SuccessRate = float64(stats.Requests-stats.Failures) / float64(stats.Requests)
+	stats.P50 = percentile(latency, stats.Requests, 0.5)
+	stats.P90 = percentile(latency, stats.Requests, 0.9)
+	stats.P99 = percentile(latency, stats.Requests, 0.99)
+	stats.ObjectiveLatency = percentile(latency, stats.Requests, e.objective.LatencyPercentile)
+
+	if stats.Requests < e.objective.MinRequests {
+		return stats
+	}
+	if e.objective.SuccessRate > 0 && stats.SuccessRate < e.objective.SuccessRate {
+		stats.Violations = append(stats.Violations, fmt.Sprintf("success rate %.2f%% < %.2f%%",
+			stats.SuccessRate*100, e.objective.SuccessRate*100))
+	}
+	if e.objective.Latency > 0 && stats.ObjectiveLatency > e.objective.Latency {
+		stats.Violations = append(stats.Violations, fmt.Sprintf("p%g latency %s > %s",
+			e.objective.LatencyPercentile*100, stats.ObjectiveLatency.Round(time.Millisecond), e.objective.Latency))
+	}
+	return stats
+}
+
+func latencyBucket(d time.Duration) int {
+	i, _ := slices.BinarySearch(latencyBounds, d)
+	return i
+}
+
+// percentile интерполирует перцентиль q внутри корзины гистограммы,
+// задержки больше последней границы оцениваются как последняя граница
+func percentile(hist []int64, total int64, q float64) time.Duration {
+	rank := math.Ceil(q * float64(total))
+	var cumulative int64
+	for i, n := range hist {
+		if n == 0 || float64(cumulative+n) < rank {
+			cumulative += n
+			continue
+		}
+		if i == len(latencyBounds) {
+			return latencyBounds[i-1]
+		}
+		var lower time.Duration
+		if i > 0 {
+			lower = latencyBounds[i-1]
+		}
+		fraction := (rank - float64(cumulative)) / float64(n)
+		return lower + time.Duration(fraction*float64(latencyBounds[i]-lower))
+	}
+	return 0
+}
+
+// SLORoundTripper учитывает запросы в SLOTracker
+type SLORoundTripper struct {
+	next    http.RoundTripper
+	tracker *SLOTracker
+}
+
+// NewSLORoundTripper создает RoundTripper с учетом SLO
+func NewSLORoundTripper(next http.RoundTripper, tracker *SLOTracker) *SLORoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	return &SLORoundTripper{next: next, tracker: tracker}
+}
+
+// RoundTrip выполняет запрос и учитывает результат. Запросы, отмененные
+// вызывающим, не учитываются
+func (s *SLORoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+
+	resp, err := s.next.RoundTrip(req)
+	if err != nil && errors.Is(req.Context().Err(), context.Canceled) {
+		return resp, err
+	}
+
+	s.tracker.Observe(req.URL.Host, s.tracker.config.PathTemplate(req), time.Since(start), s.tracker.config.IsFailure(resp, err))
+	return resp, err
+}
+
+// defaultPathTemplate берет шаблон из контекста или обобщает путь
+func defaultPathTemplate(req *http.Request) string {
+	if template := pathTemplateFromContext(req.Context()); template != "" {
+		return template
+	}
+	return templatePath(req.URL.Path)
+}
+
+// templatePath заменяет сегменты-идентификаторы на {id}, чтобы
+// /users/42 и /users/43 были одним эндпоинтом
+func templatePath(path string) string {
+	if path == "" {
+		return "/"
+	}
+
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if isIDSegment(segment) {
+			segments[i] = "{id}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// isIDSegment распознает числа, UUID и hex строки от 16 символов
+func isIDSegment(segment string) bool {
+	if segment == "" {
+		return false
+	}
+
+	digits := true
+	for _, r := range segment {
+		switch {
+		case r >= '0' && r <= '9':
+		case r >= 'a' && r <= 'f', r >= 'A' && r <= 'F':
+			digits = false
+		case r == '-' && len(segment) == 36:
+			digits = false
+		default:
+			return false
+		}
+	}
+	return digits || len(segment) >= 16
+}
@@ -0,0 +1,187 @@
+package httpclient
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func newTestSLOTracker(config SLOConfig) (*SLOTracker, *time.Time) {
+	tracker := NewSLOTracker(config)
+	now := time.Unix(1_700_000_000, 0)
+	tracker.now = func() time.Time { return now }
+	return tracker, &now
+}
+
+func TestSLOTrackerStats(t *testing.T) {
+	tracker, _ := newTestSLOTracker(SLOConfig{})
+	for i := 1; i <= 100; i++ {
+		tracker.Observe("api.x.com", "/users/{id}", time.Duration(i)*time.Millisecond, i <= 5)
+	}
+
+	stats := tracker.Stats()
+	if len(stats) != 1 {
+		t.Fatalf("expected 1 endpoint, got %d", len(stats))
+	}
+	s := stats[0]
+	if s.Requests != 100 || s.Failures != 5 || s.SuccessRate != 0.95 {
+		t.Errorf("unexpected counters: %+v", s)
+	}
+	if s.P50 < 35*time.Millisecond || s.P50 > 65*time.Millisecond {
+		t.Errorf("unexpected p50: %s", s.P50)
+	}
+	if s.P99 < 80*time.Millisecond || s.P99 > 150*time.Millisecond {
+		t.Errorf("unexpected p99: %s", s.P99)
+	}
+	if s.P50 > s.P90 || s.P90 > s.P99 {
+		t.Errorf("percentiles not ordered: %s %s %s", s.P50, s.P90, s.P99)
+	}
+}
+
+func TestSLOTrackerWindow(t *testing.T) {
+	tracker, now := newTestSLOTracker(SLOConfig{Window: time.Minute, Buckets: 6})
+	tracker.Observe("api.x.com", "/a", time.Millisecond, false)
+
+	*now = now.Add(30 * time.Second)
+	tracker.Observe("api.x.com", "/a", time.Millisecond, false)
+	if s := tracker.Stats(); s[0].Requests != 2 {
+		t.Errorf("expected 2 requests in window, got %d", s[0].Requests)
+	}
+
+	*now = now.Add(40 * time.Second)
+	if s := tracker.Stats(); s[0].Requests != 1 {
+		t.Errorf("expected 1 request in window, got %d", s[0].Requests)
+	}
+
+	*now = now.Add(time.Minute)
+	if s := tracker.Stats(); len(s) != 0 {
+		t.Errorf("expected no endpoints, got %+v", s)
+	}
+}
+
+func TestSLOTrackerBreach(t *testing.T) {
+	var breached, recovered []EndpointStats
+	tracker, now := newTestSLOTracker(SLOConfig{
+		Window:  time.Minute,
+		Buckets: 6,
+		Objective: Objective{
+			SuccessRate: 0.99,
+			MinRequests: 10,
+		},
+		Objectives: map[string]Objective{
+			"api.x.com/slow": {Latency: 100 * time.Millisecond, MinRequests: 10},
+		},
+		OnBreach:  func(s EndpointStats) { breached = append(breached, s) },
+		OnRecover: func(s EndpointStats) { recovered = append(recovered, s) },
+	})
+
+	for i := 0; i < 20; i++ {
+		tracker.Observe("api.x.com", "/pay", time.Millisecond, i%2 == 0)
+		tracker.Observe("api.x.com", "/slow", time.Second, false)
+	}
+	if len(breached) != 0 {
+		t.Fatalf("objective checked before the next part of the window: %+v", breached)
+	}
+
+	// Цель проверяется при первом запросе в следующей части окна
+	*now = now.Add(10 * time.Second)
+	tracker.Observe("api.x.com", "/pay", time.Millisecond, false)
+	tracker.Observe("api.x.com", "/slow", time.Second, false)
+	if len(breached) != 2 {
+		t.Fatalf("expected 2 breaches, got %+v", breached)
+	}
+	if breached[0].Path != "/pay" || len(breached[0].Violations) != 1 {
+		t.Errorf("unexpected breach: %+v", breached[0])
+	}
+	if breached[1].Path != "/slow" || breached[1].ObjectiveLatency <= 100*time.Millisecond {
+		t.Errorf("unexpected breach: %+v", breached[1])
+	}
+
+	// Повторно о том же нарушении не сообщается
+	*now = now.Add(10 * time.Second)
+	tracker.Observe("api.x.com", "/pay", time.Millisecond, false)
+	if len(breached) != 2 {
+		t.Errorf("breach reported again: %+v", breached)
+	}
+
+	*now = now.Add(2 * time.Minute)
+	for i := 0; i < 20; i++ {
+		tracker.Observe("api.x.com", "/pay", time.Millisecond, false)
+	}
+	*now = now.Add(10 * time.Second)
+	tracker.Observe("api.x.com", "/pay", time.Millisecond, false)
+	if len(recovered) != 1 || recovered[0].Path != "/pay" {
+		t.Errorf("expected recovery of /pay, got %+v", recovered)
+	}
+}
+
+func TestSLOTrackerMaxEndpoints(t *testing.T) {
+	tracker, _ := newTestSLOTracker(SLOConfig{MaxEndpoints: 2})
+	for _, path := range []string{"/a", "/b", "/c", "/d"} {
+		tracker.Observe("api.x.com", path, time.Millisecond, false)
+	}
+
+	stats := tracker.Stats()
+	if len(stats) != 3 || stats[2].Path != "/{other}" || stats[2].Requests != 2 {
+		t.Errorf("unexpected endpoints: %+v", stats)
+	}
+}
+
+func TestSLORoundTripper(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("fail") != "" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+
+	tracker := NewSLOTracker(SLOConfig{})
+	client := &http.Client{Transport: NewSLORoundTripper(nil, tracker)}
+
+	for _, path := range []string{"/users/1", "/users/2?fail=1", "/orders/550e8400-e29b-41d4-a716-446655440000"} {
+		resp, err := client.Get(srv.URL + path)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		resp.Body.Close()
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL+"/search/golang", nil)
+	resp, err := client.Do(req.WithContext(WithPathTemplate(req.Context(), "/search/{query}")))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
+
+	stats := tracker.Stats()
+	if len(stats) != 3 {
+		t.Fatalf("expected 3 endpoints, got %+v", stats)
+	}
+	want := []string{"/orders/{id}", "/search/{query}", "/users/{id}"}
+	for i, s := range stats {
+		if s.Path != want[i] {
+			t.Errorf("endpoint %d: expected %s, got %s", i, want[i], s.Path)
+		}
+	}
+	if stats[2].Requests != 2 || stats[2].Failures != 1 {
+		t.Errorf("unexpected counters: %+v", stats[2])
+	}
+}
+
+func TestTemplatePath(t *testing.T) {
+	tests := map[string]string{
+		"":                   "/",
+		"/users/42":          "/users/{id}",
+		"/users/42/orders/7": "/users/{id}/orders/{id}",
+		"/v1/files/5d41402abc4b2a76b9719d911017c592":   "/v1/files/{id}",
+		"/orders/550e8400-e29b-41d4-a716-446655440000": "/orders/{id}",
+		"/v2/users/me": "/v2/users/me",
+		"/feed/cafe":   "/feed/cafe",
+	}
+	for path, want := range tests {
+		if got := templatePath(path); got != want {
+			t.Errorf("templatePath(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
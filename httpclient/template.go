@@ -0,0 +1,161 @@
+package httpclient
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"time"
+)
+
+// RequestTemplate общие для клиента API настройки запросов: базовый URL,
+// заголовки, query параметры, авторизация и таймаут. With* методы возвращают
+// копию, поэтому от одного шаблона можно получить варианты, не меняя его
+//
+//	api := httpclient.NewRequestTemplate("https://api.partner.com/v1").
+//		WithClient(client).
+//		WithAPIKey("X-API-Key", key).
+//		WithTimeout(5 * time.Second)
+//
+//	var user User
+//	err := api.DoJSON(ctx, http.MethodGet, "/users/{id}", nil, &user, id)
+type RequestTemplate struct {
+	base    string
+	client  *http.Client
+	header  http.Header
+	query   url.Values
+	auth    func(req *http.Request) error
+	timeout time.Duration
+}
+
+// NewRequestTemplate создает шаблон с базовым URL, ошибка в адресе
+// возвращается из NewRequest
+func NewRequestTemplate(baseURL string) *RequestTemplate {
+	return &RequestTemplate{
+		base:   baseURL,
+		client: http.DefaultClient,
+		header: make(http.Header),
+		query:  make(url.Values),
+	}
+}
+
+// clone копирует шаблон для With* методов
+func (t *RequestTemplate) clone() *RequestTemplate {
+	c := *t
+	c.header = t.header.Clone()
+	// Срезы значений копируются, иначе Add в копии может дописать в
+	// общий массив и изменить соседний шаблон
+	c.query = make(url.Values, len(t.query))
+	for key, values := range t.query {
+		c.query[key] = slices.Clone(values)
+	}
+	return &c
+}
+
+// WithClient задает клиента, по умолчанию http.DefaultClient
+func (t *RequestTemplate) WithClient(client *http.Client) *RequestTemplate {
+	c := t.clone()
+	c.client = client
+	return c
+}
+
+// WithHeader задает заголовок всех запросов
+func (t *RequestTemplate) WithHeader(key, value string) *RequestTemplate {
+	c := t.clone()
+	c.header.Set(key, value)
+	return c
+}
+
+// WithQuery добавляет query параметр всех запросов, например api_version
+func (t *RequestTemplate) WithQuery(key, value string) *RequestTemplate {
+	c := t.clone()
+	c.query.Add(key, value)
+	return c
+}
+
+// WithAPIKey задает ключ API в заголовке header
+func (t *RequestTemplate) WithAPIKey(header, key string) *RequestTemplate {
+	return t.WithHeader(header, key)
+}
+
+// WithBearerToken задает Authorization: Bearer token
+func (t *RequestTemplate) WithBearerToken(token string) *RequestTemplate {
+	return t.WithHeader("Authorization", "Bearer "+token)
+}
+
+// WithBasicAuth задает Basic авторизацию
+func (t *RequestTemplate) WithBasicAuth(username, password string) *RequestTemplate {
+	return t.WithAuth(func(req *http.Request) error {
+		req.SetBasicAuth(username, password)
+		return nil
+	})
+}
+
+// WithAuth задает авторизацию, вызываемую для каждого запроса, например
+// с токеном, который обновляется
+func (t *RequestTemplate) WithAuth(auth func(req *http.Request) error) *RequestTemplate {
+	c := t.clone()
+	c.auth = auth
+	return c
+}
+
+// WithTimeout ограничивает запрос в Do вместе с чтением тела ответа,
+// 0 - без ограничения
+func (t *RequestTemplate) WithTimeout(timeout time.Duration) *RequestTemplate {
+	c := t.clone()
+	c.timeout = timeout
+	return c
+}
+
+// NewRequest создает запрос к path от базового URL. path - шаблон как в
+// URLBuilder.Path, значения values экранируются. Шаблон пути попадает в
+// контекст через WithPathTemplate, так SLOTracker учитывает эндпоинт под ним.
+// Заголовки запроса можно менять, шаблон они не затрагивают
+func (t *RequestTemplate) NewRequest(ctx context.Context, method, path string, body io.Reader, values ...interface{}) (*http.Request, error) {
+	target, err := URL(t.base).Path(path, values...).Queries(t.query).Build()
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(WithPathTemplate(ctx, path), method, target, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	for key, vals := range t.header {
+		req.Header[key] = append([]string(nil), vals...)
+	}
+	if t.auth != nil {
+		if err := t.auth(req); err != nil {
+			return nil, fmt.Errorf("failed to authorize request: %w", err)
+		}
+	}
+	return req, nil
+}
+
+// Do выполняет запрос клиентом шаблона с его таймаутом. Таймаут действует
+// до закрытия тела ответа
+func (t *RequestTemplate) Do(req *http.Request) (*http.Response, error) {
+	if t.timeout <= 0 {
+		return t.client.Do(req)
+	}
+
+	ctx, cancel := context.WithTimeout(req.Context(), t.timeout)
+	resp, err := t.client.Do(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// DoJSON выполняет запрос как httpclient.DoJSON, но с настройками шаблона
+func (t *RequestTemplate) DoJSON(ctx context.Context, method, path string, in, out interface{}, values ...interface{}) error {
+	req, err := t.NewRequest(ctx, method, path, nil, values...)
+	if err != nil {
+		return err
+	}
+	return doJSON(t, req, in, out)
+}
@@ -0,0 +1,138 @@
+package httpclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRequestTemplate(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-API-Key") != "key" || r.URL.Query().Get("v") != "2" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var in map[string]string
+		json.NewDecoder(r.Body).Decode(&in)
+		json.NewEncoder(w).Encode(map[string]string{
+			"path":  r.URL.EscapedPath(),
+			"name":  in["name"],
+			"extra": r.Header.Get("X-Extra"),
+		})
+	}))
+	defer srv.Close()
+
+	base := NewRequestTemplate(srv.URL+"/api").
+		WithAPIKey("X-API-Key", "key").
+		WithQuery("v", "2")
+	extra := base.WithHeader("X-Extra", "1")
+
+	var out map[string]string
+	if err := base.DoJSON(context.Background(), http.MethodPost, "/users/{id}", map[string]string{"name": "a"}, &out, "a/b"); err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	if out["path"] != "/api/users/a%2Fb" || out["name"] != "a" || out["extra"] != "" {
+		t.Errorf("unexpected response: %v", out)
+	}
+
+	// Вариант шаблона не меняет исходный
+	if err := extra.DoJSON(context.Background(), http.MethodGet, "/users", nil, &out); err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	if out["extra"] != "1" {
+		t.Errorf("expected header from derived template, got %v", out)
+	}
+	if _, ok := base.header["X-Extra"]; ok {
+		t.Error("derived template changed the base")
+	}
+}
+
+func TestRequestTemplateNewRequest(t *testing.T) {
+	calls := 0
+	tmpl := NewRequestTemplate("https://api.x.com").WithAuth(func(req *http.Request) error {
+		calls++
+		req.Header.Set("Authorization", "Bearer t")
+		return nil
+	})
+
+	req, err := tmpl.NewRequest(context.Background(), http.MethodGet, "/orders/{id}", nil, 7)
+	if err != nil {
+		t.Fatalf("failed to create request: %v", err)
+	}
+	if req.URL.String() != "https://api.x.com/orders/7" || req.Header.Get("Authorization") != "Bearer t" || calls != 1 {
+		t.Errorf("unexpected request: %s %v", req.URL, req.Header)
+	}
+	if got := pathTemplateFromContext(req.Context()); got != "/orders/{id}" {
+		t.Errorf("expected path template in context, got %q", got)
+	}
+
+	if _, err := NewRequestTemplate("api.x.com").NewRequest(context.Background(), http.MethodGet, "/", nil); err == nil {
+		t.Error("expected error for base url without scheme")
+	}
+
+	failing := tmpl.WithAuth(func(*http.Request) error { return errors.New("no token") })
+	if _, err := failing.NewRequest(context.Background(), http.MethodGet, "/", nil); err == nil {
+		t.Error("expected auth error")
+	}
+}
+
+func TestRequestTemplateTimeout(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.(http.Flusher).Flush()
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer srv.Close()
+
+	tmpl := NewRequestTemplate(srv.URL).WithTimeout(50 * time.Millisecond)
+	req, _ := tmpl.NewRequest(context.Background(), http.MethodGet, "/", nil)
+	resp, err := tmpl.Do(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	// Таймаут действует и на чтение тела
+	start := time.Now()
+	if _, err := io.ReadAll(resp.Body); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline exceeded, got %v", err)
+	}
+	if time.Since(start) > 500*time.Millisecond {
+		t.Error("timeout not applied to body")
+	}
+}
+
+func TestRequestTemplateSiblingQueries(t *testing.T) {
+	// Три значения: у среза остается запас емкости для append
+	base := NewRequestTemplate("https://api.example.com").
+		WithQuery("tag", "a").
+		WithQuery("tag", "b").
+		WithQuery("tag", "c")
+	first := base.WithQuery("tag", "x")
+	second := base.WithQuery("tag", "y")
+
+	query := func(tmpl *RequestTemplate) string {
+		req, err := tmpl.NewRequest(context.Background(), http.MethodGet, "/items", nil)
+		if err != nil {
+			t.Fatalf("NewRequest failed: %v", err)
+		}
+		return req.URL.RawQuery
+	}
+
+	if got := query(first); got != "tag=a&tag=b&tag=c&tag=x" {
+		t.Errorf("first = %s", got)
+	}
+	if got := query(second); got != "tag=a&tag=b&tag=c&tag=y" {
+		t.Errorf("second = %s", got)
+	}
+	if got := query(base); got != "tag=a&tag=b&tag=c" {
+		t.Errorf("base = %s", got)
+	}
+}
@@ -0,0 +1,145 @@
+package httpclient
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"time"
+)
+
+// TransportConfig конфигурация http.Transport
+type TransportConfig struct {
+	// Резолвер DNS, по умолчанию net.DefaultResolver
+	Resolver Resolver
+	// TTL кэша DNS, 0 - без кэширования
+	DNSCacheTTL time.Duration
+
+	DialTimeout           time.Duration
+	KeepAlive             time.Duration
+	TLSHandshakeTimeout   time.Duration
+	ResponseHeaderTimeout time.Duration
+	IdleConnTimeout       time.Duration
+
+	MaxIdleConns        int
+	MaxIdleConnsPerHost int
+	MaxConnsPerHost     int
+
+	TLSConfig *tls.Config
+
+	// Политика исходящих соединений, nil - без ограничений.
+	// При заданной политике прокси из окружения не используется
+	Egress *EgressPolicy
+}
+
+// DefaultTransportConfig дефолтная конфигурация
+func DefaultTransportConfig() TransportConfig {
+	return TransportConfig{
+		DialTimeout:         5 * time.Second,
+		KeepAlive:           30 * time.Second,
+		TLSHandshakeTimeout: 5 * time.Second,
+		IdleConnTimeout:     90 * time.Second,
+		MaxIdleConns:        100,
+		MaxIdleConnsPerHost: 10,
+	}
+}
+
+// NewTransport создает http.Transport по конфигурации
+func NewTransport(config TransportConfig) *http.Transport {
+	resolver := config.Resolver
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+	if config.DNSCacheTTL > 0 {
+		resolver = NewCachingResolver(resolver, config.DNSCacheTTL)
+	}
+
+	dialer := &net.Dialer{
+		Timeout:   config.DialTimeout,
+		KeepAlive: config.KeepAlive,
+	}
+
+	proxy := http.ProxyFromEnvironment
+	if config.Egress != nil {
+		// Через прокси соединение идет к адресу прокси, что обходит политику
+		proxy = nil
+	}
+
+	return &http.Transport{
+		Proxy:                 proxy,
+		DialContext:           resolvingDialer(dialer, resolver, config.Egress),
+		ForceAttemptHTTP2:     true,
+		TLSClientConfig:       config.TLSConfig,
+		TLSHandshakeTimeout:   config.TLSHandshakeTimeout,
+		ResponseHeaderTimeout: config.ResponseHeaderTimeout,
+		IdleConnTimeout:       config.IdleConnTimeout,
+		MaxIdleConns:          config.MaxIdleConns,
+		MaxIdleConnsPerHost:   config.MaxIdleConnsPerHost,
+		MaxConnsPerHost:       config.MaxConnsPerHost,
+	}
+}
+
+// DialFunc функция установки соединения
+type DialFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
+// resolvingDialer резолвит хост через resolver и пробует адреса по очереди,
+// пропуская адреса запрещенные политикой
+func resolvingDialer(dialer *net.Dialer, resolver Resolver, policy *EgressPolicy) DialFunc {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, err
+		}
+
+		if ip, err := netip.ParseAddr(host); err == nil {
+			if err := checkEgress(policy, host, ip); err != nil {
+				return nil, err
+			}
+			return dialer.DialContext(ctx, network, addr)
+		}
+
+		addrs, err := resolver.LookupHost(ctx, host)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve %s: %w", host, err)
+		}
+		if len(addrs) == 0 {
+			return nil, fmt.Errorf("failed to resolve %s: no addresses", host)
+		}
+
+		var errs []error
+		for _, ip := range addrs {
+			parsed, err := netip.ParseAddr(ip)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("failed to resolve %s: invalid address %q", host, ip))
+				continue
+			}
+			if err := checkEgress(policy, host, parsed); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+
+			conn, err := dialer.DialContext(ctx, network, net.JoinHostPort(ip, port))
+			if err == nil {
+				return conn, nil
+			}
+			errs = append(errs, err)
+
+			if ctx.Err() != nil {
+				break
+			}
+		}
+
+		return nil, errors.Join(errs...)
+	}
+}
+
+// checkEgress проверяет адрес политикой, если она задана
+func checkEgress(policy *EgressPolicy, host string, ip netip.Addr) error {
+	if policy == nil {
+		return nil
+	}
+	return policy.Check(host, ip)
+}
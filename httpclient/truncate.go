@@ -0,0 +1,188 @@
+package httpclient
+
+import (
+	"encoding/json"
+	"sort"
+	"unicode/utf8"
+)
+
+// TruncationMode способ обрезки тела больше MaxBodySize
+type TruncationMode string
+
+const (
+	TruncationBytes TruncationMode = "bytes" // Обрезать по байтам, JSON может стать невалидным
+	TruncationJSON  TruncationMode = "json"  // Удалять ключи и элементы массивов, сохраняя валидный JSON
+)
+
+// TruncatedMarkerKey поле с описанием обрезки в JSON, обрезанном в режиме TruncationJSON.
+// У массива маркер добавляется последним элементом {"_truncated": {...}}
+const TruncatedMarkerKey = "_truncated"
+
+// truncationMarker описание обрезки
+type truncationMarker struct {
+	OriginalSize string `json:"original_size"`
+	Dropped      int    `json:"dropped"` // Удаленные ключи и элементы массивов
+}
+
+// truncationMarkerReserve место под маркер в бюджете
+const truncationMarkerReserve = 80
+
+// minTruncatedString строки короче не обрезаются, а удаляются
+const minTruncatedString = 16
+
+// truncateJSON санитизирует JSON и уменьшает его до maxSize байт (компактно),
+// удаляя ключи с конца и элементы массивов. Вложенные значения, не помещающиеся
+// целиком, уменьшаются рекурсивно. Возвращает false, если тело не JSON
+func truncateJSON(body []byte, maxSize int, sanitizeValue func(interface{}) interface{}) (string, bool) {
+	var data interface{}
+	if err := json.Unmarshal(body, &data); err != nil {
+		return "", false
+	}
+	data = sanitizeValue(data)
+
+	if result, err := json.Marshal(data); err == nil && len(result) <= maxSize {
+		return string(result), true
+	}
+
+	budget := maxSize - truncationMarkerReserve
+	if budget < 2 {
+		budget = 2
+	}
+	shrunk, dropped := shrinkJSON(data, budget)
+	marker := truncationMarker{OriginalSize: formatSize(len(body)), Dropped: dropped}
+
+	switch v := shrunk.(type) {
+	case map[string]interface{}:
+		v[TruncatedMarkerKey] = marker
+	case []interface{}:
+		shrunk = append(v, map[string]interface{}{TruncatedMarkerKey: marker})
+	default:
+		shrunk = map[string]interface{}{TruncatedMarkerKey: marker}
+	}
+
+	result, err := json.Marshal(shrunk)
+	if err != nil {
+		return "", false
+	}
+	return string(result), true
+}
+
+// shrinkJSON уменьшает значение до budget байт, возвращает число удаленных ключей и элементов
+func shrinkJSON(value interface{}, budget int) (interface{}, int) {
+	if jsonSize(value) <= budget {
+		return value, 0
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		result := make(map[string]interface{}, len(v))
+		used, dropped := 2, 0
+		for _, key := range keys {
+			// "key": + запятая
+			overhead := jsonSize(key) + 2
+			remaining := budget - used - overhead
+			if remaining <= 0 {
+				dropped++
+				continue
+			}
+
+			item, d := shrinkJSON(v[key], remaining)
+			size := jsonSize(item)
+			if size > remaining || emptiedJSON(v[key], item) {
+				dropped++
+				continue
+			}
+			result[key] = item
+			used += overhead + size
+			dropped += d
+		}
+		return result, dropped
+
+	case []interface{}:
+		result := make([]interface{}, 0, len(v))
+		used, dropped := 2, 0
+		for i, elem := range v {
+			remaining := budget - used - 1
+			if remaining <= 0 {
+				dropped += len(v) - i
+				break
+			}
+			item, d := shrinkJSON(elem, remaining)
+			size := jsonSize(item)
+			if size > remaining || emptiedJSON(elem, item) {
+				dropped += len(v) - i
+				break
+			}
+			result = append(result, item)
+			used += size + 1
+			dropped += d
+
+			// Элемент уменьшен - места на следующие уже нет
+			if d > 0 {
+				dropped += len(v) - i - 1
+				break
+			}
+		}
+		return result, dropped
+
+	case string:
+		// Кавычки и "..."
+		limit := budget - 5
+		if limit < minTruncatedString {
+			return value, 0
+		}
+		cut := v
+		for jsonSize(cut)+3 > budget && len(cut) > 0 {
+			// Укорачиваем пропорционально, не разрывая UTF-8 символы
+			n := len(cut) * budget / (jsonSize(cut) + 3)
+			if n >= len(cut) {
+				n = len(cut) - 1
+			}
+			for n > 0 && !utf8.RuneStart(cut[n]) {
+				n--
+			}
+			cut = cut[:n]
+		}
+		return cut + "...", 0
+	}
+
+	return value, 0
+}
+
+// emptiedJSON проверяет что при уменьшении от объекта или массива ничего не осталось
+func emptiedJSON(original, shrunk interface{}) bool {
+	switch v := shrunk.(type) {
+	case map[string]interface{}:
+		return len(v) == 0 && len(original.(map[string]interface{})) > 0
+	case []interface{}:
+		return len(v) == 0 && len(original.([]interface{})) > 0
+	}
+	return false
+}
+
+// jsonSize размер компактного JSON представления значения
+func jsonSize(value interface{}) int {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return 0
+	}
+	return len(data)
+}
+
+// cutUTF8 обрезает строку до maxSize байт, не разрывая UTF-8 символы
+func cutUTF8(s string, maxSize int) string {
+	if len(s) <= maxSize {
+		return s
+	}
+	n := maxSize
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
@@ -0,0 +1,137 @@
+package httpclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTruncateJSONArray(t *testing.T) {
+	config := DefaultSanitizerConfig()
+	config.MaxBodySize = 500
+
+	var items []string
+	for i := 0; i < 100; i++ {
+		items = append(items, fmt.Sprintf(`{"id":%d,"password":"secret-%d"}`, i, i))
+	}
+	body := "[" + strings.Join(items, ",") + "]"
+
+	got := NewSanitizer(config).truncateBody([]byte(body), "application/json")
+	if len(got) > config.MaxBodySize {
+		t.Errorf("result %d bytes exceeds limit %d", len(got), config.MaxBodySize)
+	}
+
+	var data []map[string]interface{}
+	if err := json.Unmarshal([]byte(got), &data); err != nil {
+		t.Fatalf("result is not valid JSON: %v\n%s", err, got)
+	}
+	if strings.Contains(got, "secret-") {
+		t.Error("truncated JSON must be sanitized")
+	}
+
+	marker, ok := data[len(data)-1][TruncatedMarkerKey].(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected marker as last element, got %s", got)
+	}
+	if kept := len(data) - 1; int(marker["dropped"].(float64)) != 100-kept {
+		t.Errorf("dropped = %v, want %d", marker["dropped"], 100-kept)
+	}
+}
+
+func TestTruncateJSONNested(t *testing.T) {
+	body := fmt.Sprintf(`{"id":1,"items":[%s],"note":%q}`,
+		strings.TrimSuffix(strings.Repeat(`{"sku":"abc","qty":1},`, 200), ","),
+		strings.Repeat("x", 5000))
+
+	got, ok := truncateJSON([]byte(body), 400, func(v interface{}) interface{} { return v })
+	if !ok {
+		t.Fatal("expected JSON body to be truncated")
+	}
+	if len(got) > 400 {
+		t.Errorf("result %d bytes exceeds limit", len(got))
+	}
+
+	var data map[string]interface{}
+	if err := json.Unmarshal([]byte(got), &data); err != nil {
+		t.Fatalf("result is not valid JSON: %v\n%s", err, got)
+	}
+	if data["id"] != float64(1) {
+		t.Errorf("small fields must be kept, got %s", got)
+	}
+	if _, ok := data["items"].([]interface{}); !ok {
+		t.Errorf("large array must be shrunk, not dropped: %s", got)
+	}
+	if _, ok := data[TruncatedMarkerKey]; !ok {
+		t.Errorf("expected %s field, got %s", TruncatedMarkerKey, got)
+	}
+}
+
+func TestTruncateJSONLongString(t *testing.T) {
+	body := fmt.Sprintf(`{"text":%q}`, strings.Repeat("ж", 1000))
+
+	got, ok := truncateJSON([]byte(body), 200, func(v interface{}) interface{} { return v })
+	if !ok || len(got) > 200 {
+		t.Fatalf("unexpected result %d bytes: %s", len(got), got)
+	}
+
+	var data map[string]interface{}
+	if err := json.Unmarshal([]byte(got), &data); err != nil {
+		t.Fatalf("result is not valid JSON: %v", err)
+	}
+	text, _ := data["text"].(string)
+	if !strings.HasSuffix(text, "...") || strings.ContainsRune(text, '�') {
+		t.Errorf("expected string cut on rune boundary, got %q", text)
+	}
+}
+
+func TestTruncationBytesMode(t *testing.T) {
+	config := DefaultSanitizerConfig()
+	config.MaxBodySize = 10
+	config.TruncationMode = TruncationBytes
+
+	got := NewSanitizer(config).truncateBody([]byte(`{"id":1234567890}`), "application/json")
+	if !strings.HasPrefix(got, `{"id":1234`) || !strings.Contains(got, "[truncated") {
+		t.Errorf("unexpected byte truncation %q", got)
+	}
+}
+
+func TestTruncateBytesSanitizesBeforeCut(t *testing.T) {
+	filler := strings.Repeat("привет мир ", 12*1024)
+
+	tests := map[string]struct {
+		contentType string
+		body        string
+	}{
+		"text": {
+			contentType: "text/plain",
+			body:        "Authorization: Bearer hunter2\n" + filler,
+		},
+		"xml": {
+			contentType: "application/xml",
+			body:        "<login><password>hunter2</password><note>" + filler + "</note></login>",
+		},
+		"form": {
+			contentType: "application/x-www-form-urlencoded",
+			body:        "password=hunter2&note=" + strings.Repeat("lorem%20ipsum%20", 12*1024),
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			sanitizer := NewSanitizer(DefaultSanitizerConfig())
+			got := sanitizer.SanitizeBody([]byte(tt.body), tt.contentType)
+
+			if strings.Contains(got, "hunter2") {
+				t.Errorf("secret is logged: %.200s", got)
+			}
+			if !strings.Contains(got, "truncated") {
+				t.Error("body is not truncated")
+			}
+			if !utf8.ValidString(got) {
+				t.Error("body is cut inside a UTF-8 character")
+			}
+		})
+	}
+}
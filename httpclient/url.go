@@ -0,0 +1,150 @@
+package httpclient
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// URLBuilder собирает URL с экранированием сегментов пути и query параметров.
+// Используйте вместо fmt.Sprintf, чтобы данные пользователя не меняли путь
+//
+//	u, err := httpclient.URL("https://api.x.com").Path("/users/{id}", id).Query("limit", 10).Build()
+type URLBuilder struct {
+	base  *url.URL
+	path  strings.Builder
+	query url.Values
+	err   error
+}
+
+// URL создает builder от базового адреса
+func URL(base string) *URLBuilder {
+	b := &URLBuilder{}
+
+	u, err := url.Parse(base)
+	if err != nil {
+		b.err = fmt.Errorf("invalid base url: %w", err)
+		return b
+	}
+	if u.Scheme == "" || u.Host == "" {
+		b.err = fmt.Errorf("invalid base url %q: scheme and host are required", base)
+		return b
+	}
+
+	b.base = u
+	b.query = u.Query()
+	return b
+}
+
+// Path добавляет шаблон пути, плейсхолдеры {name} заменяются значениями по порядку.
+// Каждое значение экранируется как отдельный сегмент, "/" внутри значения не создает новый сегмент
+func (b *URLBuilder) Path(template string, values ...interface{}) *URLBuilder {
+	if b.err != nil {
+		return b
+	}
+
+	var (
+		result strings.Builder
+		next   int
+	)
+
+	rest := template
+	for {
+		start := strings.IndexByte(rest, '{')
+		if start < 0 {
+			result.WriteString(rest)
+			break
+		}
+		end := strings.IndexByte(rest[start:], '}')
+		if end < 0 {
+			b.err = fmt.Errorf("unclosed placeholder in path %q", template)
+			return b
+		}
+
+		if next >= len(values) {
+			b.err = fmt.Errorf("missing value for %s in path %q", rest[start:start+end+1], template)
+			return b
+		}
+
+		segment := fmt.Sprint(values[next])
+		next++
+
+		// Пустые и точечные сегменты меняют смысл пути
+		if segment == "" || segment == "." || segment == ".." {
+			b.err = fmt.Errorf("invalid path value %q for %s", segment, rest[start:start+end+1])
+			return b
+		}
+
+		result.WriteString(rest[:start])
+		result.WriteString(url.PathEscape(segment))
+		rest = rest[start+end+1:]
+	}
+
+	if next != len(values) {
+		b.err = fmt.Errorf("path %q has %d placeholders, got %d values", template, next, len(values))
+		return b
+	}
+
+	path := result.String()
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	b.path.WriteString(path)
+	return b
+}
+
+// Query добавляет query параметр, nil значения пропускаются
+func (b *URLBuilder) Query(key string, value interface{}) *URLBuilder {
+	if b.err != nil || value == nil {
+		return b
+	}
+
+	switch v := value.(type) {
+	case []string:
+		for _, item := range v {
+			b.query.Add(key, item)
+		}
+	default:
+		b.query.Add(key, fmt.Sprint(v))
+	}
+	return b
+}
+
+// Queries добавляет набор query параметров
+func (b *URLBuilder) Queries(values url.Values) *URLBuilder {
+	if b.err != nil {
+		return b
+	}
+	for key, vals := range values {
+		for _, v := range vals {
+			b.query.Add(key, v)
+		}
+	}
+	return b
+}
+
+// Build возвращает URL или первую ошибку построения
+func (b *URLBuilder) Build() (string, error) {
+	if b.err != nil {
+		return "", b.err
+	}
+
+	u := *b.base
+	rawPath := strings.TrimSuffix(u.EscapedPath(), "/") + b.path.String()
+
+	unescaped, err := url.PathUnescape(rawPath)
+	if err != nil {
+		return "", fmt.Errorf("invalid path: %w", err)
+	}
+	u.Path = unescaped
+	u.RawPath = rawPath
+	u.RawQuery = b.query.Encode()
+
+	return u.String(), nil
+}
+
+// String возвращает URL, при ошибке построения пустую строку
+func (b *URLBuilder) String() string {
+	s, _ := b.Build()
+	return s
+}
@@ -0,0 +1,74 @@
+package httpclient
+
+import "testing"
+
+func TestURLBuilder(t *testing.T) {
+	tests := []struct {
+		name    string
+		build   func() *URLBuilder
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "path and query",
+			build: func() *URLBuilder {
+				return URL("https://api.x.com").Path("/users/{id}", 42).Query("limit", 10)
+			},
+			want: "https://api.x.com/users/42?limit=10",
+		},
+		{
+			name: "base path is kept",
+			build: func() *URLBuilder {
+				return URL("https://api.x.com/v1/").Path("/users/{id}/orders/{orderID}", "a", "b")
+			},
+			want: "https://api.x.com/v1/users/a/orders/b",
+		},
+		{
+			name: "values are escaped",
+			build: func() *URLBuilder {
+				return URL("https://api.x.com").Path("/files/{name}", "../admin?x=1").Query("q", "a&b=c")
+			},
+			want: "https://api.x.com/files/..%2Fadmin%3Fx=1?q=a%26b%3Dc",
+		},
+		{
+			name: "dot segment rejected",
+			build: func() *URLBuilder {
+				return URL("https://api.x.com").Path("/users/{id}", "..")
+			},
+			wantErr: true,
+		},
+		{
+			name: "missing value",
+			build: func() *URLBuilder {
+				return URL("https://api.x.com").Path("/users/{id}")
+			},
+			wantErr: true,
+		},
+		{
+			name: "extra value",
+			build: func() *URLBuilder {
+				return URL("https://api.x.com").Path("/users", 1)
+			},
+			wantErr: true,
+		},
+		{
+			name: "relative base rejected",
+			build: func() *URLBuilder {
+				return URL("/users")
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.build().Build()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Build() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Build() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
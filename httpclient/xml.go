@@ -0,0 +1,143 @@
+package httpclient
+
+import "strings"
+
+// sanitizeXML санитизирует XML, сохраняя разметку:
+//   - содержимое элемента с чувствительным именем (вместе с вложенными
+//     элементами) заменяется маской
+//   - значения атрибутов с чувствительным именем заменяются маской
+//   - к тексту, комментариям и остальным атрибутам применяется sanitizeText
+//
+// Имена элементов и атрибутов проверяются через isSensitive целиком, вместе с
+// префиксом пространства имен, поэтому работает та же нормализация, что для
+// полей JSON
+func sanitizeXML(body, mask string, isSensitive func(string) bool, sanitizeText func(string) string) string {
+	var sb strings.Builder
+	sb.Grow(len(body))
+
+	i := 0
+	for i < len(body) {
+		lt := strings.IndexByte(body[i:], '<')
+		if lt < 0 {
+			sb.WriteString(sanitizeText(body[i:]))
+			break
+		}
+		if lt > 0 {
+			sb.WriteString(sanitizeText(body[i : i+lt]))
+		}
+		i += lt
+
+		// Комментарии и CDATA без разметки внутри
+		if end, ok := xmlSectionEnd(body, i); ok {
+			sb.WriteString(sanitizeText(body[i:end]))
+			i = end
+			continue
+		}
+
+		end := htmlTagEnd(body, i)
+		if end < 0 {
+			end = len(body)
+		}
+		tag := body[i:end]
+		i = end
+
+		name, nameEnd := xmlTagName(tag)
+		if name == "" {
+			// </закрывающий>, <?xml ...?>, <!DOCTYPE> или "<" в тексте
+			if strings.HasPrefix(tag, "</") || strings.HasPrefix(tag, "<?") || strings.HasPrefix(tag, "<!") {
+				sb.WriteString(tag)
+			} else {
+				sb.WriteString(sanitizeText(tag))
+			}
+			continue
+		}
+
+		sb.WriteString(sanitizeHTMLTag(tag, "", parseTagAttrs(tag, nameEnd), mask, isSensitive, sanitizeText))
+
+		if strings.HasSuffix(tag, "/>") || !isSensitive(name) {
+			continue
+		}
+
+		closeAt := xmlCloseTag(body, i, name)
+		if strings.TrimSpace(body[i:closeAt]) != "" {
+			sb.WriteString(mask)
+		}
+		i = closeAt
+	}
+
+	return sb.String()
+}
+
+// xmlSectionEnd возвращает конец комментария или CDATA, начинающегося в start
+func xmlSectionEnd(body string, start int) (int, bool) {
+	for _, section := range [][2]string{{"<!--", "-->"}, {"<![CDATA[", "]]>"}} {
+		if !strings.HasPrefix(body[start:], section[0]) {
+			continue
+		}
+		from := start + len(section[0])
+		end := strings.Index(body[from:], section[1])
+		if end < 0 {
+			return len(body), true
+		}
+		return from + end + len(section[1]), true
+	}
+	return 0, false
+}
+
+// xmlTagName возвращает имя открывающего тега как есть, включая префикс
+// пространства имен, и позицию после имени. Для остальных тегов имя пустое
+func xmlTagName(tag string) (string, int) {
+	if len(tag) < 2 || !isXMLNameStart(tag[1]) {
+		return "", 0
+	}
+	j := 1
+	for j < len(tag) && !isSpace(tag[j]) && tag[j] != '/' && tag[j] != '>' {
+		j++
+	}
+	return tag[1:j], j
+}
+
+// isXMLNameStart первый байт имени: буква, "_" или начало не-ASCII символа
+func isXMLNameStart(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c == '_' || c >= 0x80
+}
+
+// xmlCloseTag возвращает позицию закрывающего тега </name>, парного открытому
+// до from, с учетом вложенных элементов с тем же именем, или конец тела
+func xmlCloseTag(body string, from int, name string) int {
+	depth := 0
+	for i := from; i < len(body); {
+		lt := strings.IndexByte(body[i:], '<')
+		if lt < 0 {
+			break
+		}
+		i += lt
+
+		if end, ok := xmlSectionEnd(body, i); ok {
+			i = end
+			continue
+		}
+
+		end := htmlTagEnd(body, i)
+		if end < 0 {
+			break
+		}
+		tag := body[i:end]
+
+		switch {
+		case strings.HasPrefix(tag, "</"):
+			if closing, _ := xmlTagName("<" + tag[2:]); closing == name {
+				if depth == 0 {
+					return i
+				}
+				depth--
+			}
+		case !strings.HasSuffix(tag, "/>"):
+			if opening, _ := xmlTagName(tag); opening == name {
+				depth++
+			}
+		}
+		i = end
+	}
+	return len(body)
+}
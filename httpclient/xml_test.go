@@ -0,0 +1,71 @@
+package httpclient
+
+import (
+	"strings"
+	"testing"
+)
+
+const testXMLDocument = `<?xml version="1.0" encoding="UTF-8"?>
+<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/">
+  <soap:Body>
+    <login user="alice" api_key='k-123'>
+      <pаssword>hunter2</pаssword>
+      <pass_word>hunter3</pass_word>
+      <PASSWORD>hunter4</PASSWORD>
+      <ns:token>t-456</ns:token>
+      <secret><inner>nested-1</inner><secret>nested-2</secret></secret>
+      <credentials><![CDATA[cdata-secret]]></credentials>
+      <session/>
+      <note>Bearer abc.def.ghi</note>
+      <!-- Bearer xyz -->
+    </login>
+  </soap:Body>
+</soap:Envelope>`
+
+func TestSanitizeXML(t *testing.T) {
+	config := DefaultSanitizerConfig()
+	config.SensitiveFields = append(config.SensitiveFields, "credentials")
+	config.HomoglyphFolding = true
+
+	noRegex := DefaultSanitizerConfigNoRegex()
+	noRegex.SensitiveFields = append(noRegex.SensitiveFields, "credentials")
+	noRegex.HomoglyphFolding = true
+
+	for _, sanitizer := range []interface {
+		SanitizeBody([]byte, string) string
+	}{NewSanitizer(config), NewSanitizerNoRegex(noRegex)} {
+		got := sanitizer.SanitizeBody([]byte(testXMLDocument), "application/soap+xml")
+
+		for _, want := range []string{
+			`<?xml version="1.0" encoding="UTF-8"?>`,
+			`<login user="alice" api_key='***REDACTED***'>`,
+			`<pаssword>***REDACTED***</pаssword>`,
+			`<pass_word>***REDACTED***</pass_word>`,
+			`<PASSWORD>***REDACTED***</PASSWORD>`,
+			`<ns:token>***REDACTED***</ns:token>`,
+			`<secret>***REDACTED***</secret>`,
+			`<credentials>***REDACTED***</credentials>`,
+			`<session/>`,
+			`<note>Bearer ***REDACTED***</note>`,
+			`<!-- Bearer ***REDACTED*** -->`,
+			`</soap:Envelope>`,
+		} {
+			if !strings.Contains(got, want) {
+				t.Errorf("%T: expected %s in:\n%s", sanitizer, want, got)
+			}
+		}
+
+		for _, secret := range []string{"k-123", "hunter", "t-456", "nested-", "cdata-secret", "xyz"} {
+			if strings.Contains(got, secret) {
+				t.Errorf("%T: %s is not redacted:\n%s", sanitizer, secret, got)
+			}
+		}
+	}
+}
+
+func TestSanitizeXMLUnclosed(t *testing.T) {
+	got := NewSanitizer(nil).SanitizeBody([]byte(`<login><password>hunter2`), "application/xml")
+	if got != `<login><password>***REDACTED***` {
+		t.Errorf("unexpected result %q", got)
+	}
+}
@@ -0,0 +1,51 @@
+package i18n
+
+import "strings"
+
+// Unicode bidi isolates, see UAX #9
+const (
+	firstStrongIsolate = '\u2068'
+	popDirectIsolate   = '\u2069'
+)
+
+// bidiControls are stripped from user input so it cannot reorder the
+// surrounding text, e.g. an RLO in a name reversing the rest of a message
+var bidiControls = strings.NewReplacer(
+	"\u200e", "", "\u200f", "", "\u061c", "", // LRM, RLM, ALM
+	"\u202a", "", "\u202b", "", "\u202c", "", "\u202d", "", "\u202e", "", // LRE, RLE, PDF, LRO, RLO
+	"\u2066", "", "\u2067", "", "\u2068", "", "\u2069", "", // LRI, RLI, FSI, PDI
+)
+
+// Isolate wraps user-provided text in a first strong isolate, so its
+// direction is detected from its own letters and does not affect the
+// message around it, e.g. an Arabic name in a Kazakh notification. Bidi
+// control characters of the text are removed
+func Isolate(text string) string {
+	if text == "" {
+		return ""
+	}
+	return string(firstStrongIsolate) + bidiControls.Replace(text) + string(popDirectIsolate)
+}
+
+// IsolateData returns a copy of template data with string values isolated,
+// or only values of the given keys when keys are set
+//
+//	i18n.Translate(lang, "invited", i18n.WithData(i18n.IsolateData(data, "Name")))
+func IsolateData(data map[string]interface{}, keys ...string) map[string]interface{} {
+	isolated := make(map[string]interface{}, len(data))
+	for key, value := range data {
+		isolated[key] = value
+	}
+
+	if len(keys) == 0 {
+		for key := range data {
+			keys = append(keys, key)
+		}
+	}
+	for _, key := range keys {
+		if s, ok := isolated[key].(string); ok {
+			isolated[key] = Isolate(s)
+		}
+	}
+	return isolated
+}
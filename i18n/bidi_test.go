@@ -0,0 +1,42 @@
+package i18n
+
+import "testing"
+
+func TestIsolate(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"", ""},
+		{"Алия", "\u2068Алия\u2069"},
+		{"محمد", "\u2068محمد\u2069"},
+		// Overrides and isolates of the input cannot escape the isolate
+		{"evil\u202etxt.exe", "\u2068eviltxt.exe\u2069"},
+		{"\u2069\u200fname\u2066", "\u2068name\u2069"},
+	}
+	for _, tt := range tests {
+		if got := Isolate(tt.in); got != tt.want {
+			t.Errorf("Isolate(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestIsolateData(t *testing.T) {
+	data := map[string]interface{}{"Name": "Алия", "City": "Астана", "Count": 3}
+
+	all := IsolateData(data)
+	if all["Name"] != "\u2068Алия\u2069" || all["City"] != "\u2068Астана\u2069" || all["Count"] != 3 {
+		t.Errorf("IsolateData() = %q", all)
+	}
+
+	some := IsolateData(data, "Name", "Missing")
+	if some["Name"] != "\u2068Алия\u2069" || some["City"] != "Астана" {
+		t.Errorf("IsolateData(Name) = %q", some)
+	}
+	if _, ok := some["Missing"]; ok {
+		t.Error("IsolateData() added a missing key")
+	}
+	if data["Name"] != "Алия" {
+		t.Error("IsolateData() changed the source map")
+	}
+}
@@ -0,0 +1,101 @@
+package i18n
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func newTestI18n(t *testing.T, files map[string]string) *I18n {
+	t.Helper()
+	dir := t.TempDir()
+	var langs []string
+	for lang, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, lang+".yaml"), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		langs = append(langs, lang)
+	}
+	i, err := New(Config{DefaultLanguage: "en", SupportedLangs: langs, Path: dir})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	return i
+}
+
+func TestTranslateVariants(t *testing.T) {
+	i := newTestI18n(t, map[string]string{
+		"en": `
+welcome: "Welcome, {{.Name}}"
+welcome@gender=female: "Dear Ms {{.Name}}, welcome"
+farewell: "Goodbye"
+farewell@formality=formal: "Farewell"
+`,
+		"ru": `
+welcome: "Добро пожаловать, {{.Name}}"
+welcome@gender=female: "Уважаемая {{.Name}}, добро пожаловать"
+welcome@formality=informal,gender=female: "Привет, {{.Name}}, рады тебе"
+farewell: "Пока"
+items:
+  one: "{{.Count}} товар"
+  few: "{{.Count}} товара"
+  many: "{{.Count}} товаров"
+  other: "{{.Count}} товара"
+items@formality=formal:
+  one: "{{.Count}} позиция"
+  few: "{{.Count}} позиции"
+  many: "{{.Count}} позиций"
+  other: "{{.Count}} позиции"
+`,
+	})
+	data := WithData(map[string]interface{}{"Name": "Алия"})
+
+	tests := []struct {
+		name string
+		lang string
+		id   string
+		opts []Option
+		want string
+	}{
+		{"base", "ru", "welcome", []Option{data}, "Добро пожаловать, Алия"},
+		{"single variant", "ru", "welcome", []Option{data, WithVariant(VariantGender, "female")}, "Уважаемая Алия, добро пожаловать"},
+		{"combined variants", "ru", "welcome", []Option{data, WithVariant(VariantFormality, "informal"), WithVariant(VariantGender, "female")}, "Привет, Алия, рады тебе"},
+		{"untranslated variant", "ru", "welcome", []Option{data, WithVariant(VariantGender, "male")}, "Добро пожаловать, Алия"},
+		{"empty variant value", "ru", "welcome", []Option{data, WithVariant(VariantGender, "")}, "Добро пожаловать, Алия"},
+		// A translated base wins over a variant of the default language
+		{"variant in default language only", "ru", "farewell", []Option{WithVariant(VariantFormality, "formal")}, "Пока"},
+		{"plural in variant", "ru", "items", []Option{WithCount(5), WithData(map[string]interface{}{"Count": 5}), WithVariant(VariantFormality, "formal")}, "5 позиций"},
+		{"plural", "ru", "items", []Option{WithCount(3), WithData(map[string]interface{}{"Count": 3})}, "3 товара"},
+		{"unsupported language", "de", "welcome", []Option{data, WithVariant(VariantGender, "female")}, "Dear Ms Алия, welcome"},
+		{"unknown message", "ru", "missing", nil, "missing"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := i.Translate(tt.lang, tt.id, tt.opts...); got != tt.want {
+				t.Errorf("Translate() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVariantIDs(t *testing.T) {
+	got := variantIDs("welcome", []variant{{"gender", "female"}, {"formality", "informal"}})
+	want := []string{
+		"welcome@formality=informal,gender=female",
+		"welcome@gender=female",
+		"welcome@formality=informal",
+		"welcome",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("variantIDs() = %v, want %v", got, want)
+	}
+
+	o := &translateOptions{}
+	for _, value := range []string{"a", "b", "c", "d", "e"} {
+		WithVariant(value, value)(o)
+	}
+	if len(o.variants) != maxVariants {
+		t.Errorf("got %d variants, want at most %d", len(o.variants), maxVariants)
+	}
+}
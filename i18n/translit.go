@@ -0,0 +1,189 @@
+package i18n
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Scheme transliterates between Cyrillic and Latin scripts
+type Scheme struct {
+	name       string
+	toLatin    map[rune]string
+	toCyrillic map[string]rune
+	maxLatin   int
+	caser      unicode.SpecialCase
+}
+
+// NewScheme creates a transliteration scheme from lowercase letter mappings.
+// When toCyrillic is nil it is derived from toLatin: letters mapped to an
+// empty string are dropped and an ambiguous Latin sequence takes the letter
+// with the lowest code point, e.g. "e" becomes "е" rather than "э"
+func NewScheme(name string, toLatin map[rune]string, toCyrillic map[string]rune) *Scheme {
+	if toCyrillic == nil {
+		toCyrillic = deriveCyrillic(toLatin)
+	}
+
+	s := &Scheme{
+		name:       name,
+		toLatin:    toLatin,
+		toCyrillic: toCyrillic,
+	}
+	for latin := range toCyrillic {
+		s.maxLatin = max(s.maxLatin, utf8.RuneCountInString(latin))
+	}
+	return s
+}
+
+// Name returns scheme name
+func (s *Scheme) Name() string {
+	return s.name
+}
+
+// ToLatin transliterates Cyrillic letters, other characters are kept.
+// Capitalization follows the source: "Щука" becomes "Shchuka", "ЩУКА"
+// becomes "SHCHUKA"
+func (s *Scheme) ToLatin(text string) string {
+	runes := []rune(text)
+	var b strings.Builder
+	b.Grow(len(text))
+
+	for i, r := range runes {
+		latin, ok := s.toLatin[s.caser.ToLower(r)]
+		if !ok {
+			b.WriteRune(r)
+			continue
+		}
+		if latin == "" || !unicode.IsUpper(r) {
+			b.WriteString(latin)
+			continue
+		}
+
+		// A capital inside an upper case word is upper cased entirely
+		if upperWord(runes, i) {
+			b.WriteString(s.upper(latin))
+		} else {
+			first, size := utf8.DecodeRuneInString(latin)
+			b.WriteRune(s.caser.ToUpper(first))
+			b.WriteString(latin[size:])
+		}
+	}
+	return b.String()
+}
+
+// ToCyrillic transliterates Latin text back, the longest known sequence
+// wins, so "shch" becomes "щ" rather than "шч". Schemes are lossy, the
+// result is the most common spelling
+func (s *Scheme) ToCyrillic(text string) string {
+	runes := []rune(text)
+	var b strings.Builder
+	b.Grow(len(text) * 2)
+
+	for i := 0; i < len(runes); {
+		matched := false
+		for size := min(s.maxLatin, len(runes)-i); size > 0; size-- {
+			latin := make([]rune, size)
+			for j := range latin {
+				latin[j] = s.caser.ToLower(runes[i+j])
+			}
+			cyrillic, ok := s.toCyrillic[string(latin)]
+			if !ok {
+				continue
+			}
+			if unicode.IsUpper(runes[i]) {
+				cyrillic = unicode.ToUpper(cyrillic)
+			}
+			b.WriteRune(cyrillic)
+			i += size
+			matched = true
+			break
+		}
+		if !matched {
+			b.WriteRune(runes[i])
+			i++
+		}
+	}
+	return b.String()
+}
+
+func (s *Scheme) upper(text string) string {
+	return strings.Map(s.caser.ToUpper, text)
+}
+
+// upperWord reports whether the capital at i is part of an upper case word,
+// i.e. a neighbouring letter is upper case too
+func upperWord(runes []rune, i int) bool {
+	if i+1 < len(runes) && unicode.IsLetter(runes[i+1]) {
+		return unicode.IsUpper(runes[i+1])
+	}
+	if i > 0 && unicode.IsLetter(runes[i-1]) {
+		return unicode.IsUpper(runes[i-1])
+	}
+	return false
+}
+
+func deriveCyrillic(toLatin map[rune]string) map[string]rune {
+	toCyrillic := make(map[string]rune, len(toLatin))
+	for cyrillic, latin := range toLatin {
+		if latin == "" {
+			continue
+		}
+		if existing, ok := toCyrillic[latin]; !ok || cyrillic < existing {
+			toCyrillic[latin] = cyrillic
+		}
+	}
+	return toCyrillic
+}
+
+// russianICAO is ICAO Doc 9303 transliteration used in Russian passports
+var russianICAO = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "e",
+	'ж': "zh", 'з': "z", 'и': "i", 'й': "i", 'к': "k", 'л': "l", 'м': "m",
+	'н': "n", 'о': "o", 'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u",
+	'ф': "f", 'х': "kh", 'ц': "ts", 'ч': "ch", 'ш': "sh", 'щ': "shch",
+	'ъ': "ie", 'ы': "y", 'ь': "", 'э': "e", 'ю': "iu", 'я': "ia",
+}
+
+// kazakhLatin is the Kazakh Latin alphabet of 2021, letters used only in
+// Russian loanwords follow common practice
+var kazakhLatin = map[rune]string{
+	'а': "a", 'ә': "ä", 'б': "b", 'в': "v", 'г': "g", 'ғ': "ğ", 'д': "d",
+	'е': "e", 'ё': "io", 'ж': "j", 'з': "z", 'и': "i", 'й': "i", 'к': "k",
+	'қ': "q", 'л': "l", 'м': "m", 'н': "n", 'ң': "ñ", 'о': "o", 'ө': "ö",
+	'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u", 'ұ': "ū", 'ү': "ü",
+	'ф': "f", 'х': "h", 'һ': "h", 'ц': "ts", 'ч': "ç", 'ш': "ş", 'щ': "şş",
+	'ъ': "", 'ы': "y", 'і': "ı", 'ь': "", 'э': "e", 'ю': "iu", 'я': "ia",
+}
+
+// Built-in schemes
+var (
+	// SchemeICAO transliterates Russian like passports do, "Щукина Мария"
+	// becomes "Shchukina Mariia"
+	SchemeICAO = NewScheme("icao", russianICAO, icaoCyrillic())
+	// SchemeKazakh uses the Kazakh Latin alphabet, "Қазақстан" becomes
+	// "Qazaqstan"
+	SchemeKazakh = kazakhScheme()
+)
+
+// icaoCyrillic keeps "ie" as "ие", "ъ" is rare and the pair is frequent
+func icaoCyrillic() map[string]rune {
+	toCyrillic := deriveCyrillic(russianICAO)
+	delete(toCyrillic, "ie")
+	return toCyrillic
+}
+
+func kazakhScheme() *Scheme {
+	s := NewScheme("kazakh", kazakhLatin, nil)
+	// Kazakh pairs dotted i with İ and dotless ı with I like Turkish
+	s.caser = unicode.TurkishCase
+	return s
+}
+
+// Transliterate converts Cyrillic text to Latin using the scheme, SchemeICAO
+// when scheme is nil
+func Transliterate(text string, scheme *Scheme) string {
+	if scheme == nil {
+		scheme = SchemeICAO
+	}
+	return scheme.ToLatin(text)
+}
@@ -0,0 +1,60 @@
+package i18n
+
+import "testing"
+
+func TestSchemeToLatin(t *testing.T) {
+	tests := []struct {
+		scheme *Scheme
+		in     string
+		want   string
+	}{
+		{SchemeICAO, "Щукина Мария", "Shchukina Mariia"},
+		{SchemeICAO, "ЩУКА", "SHCHUKA"},
+		{SchemeICAO, "Щ.", "Shch."},
+		{SchemeICAO, "Объявление, Ёлка", "Obieiavlenie, Elka"},
+		{SchemeICAO, "Мальчик", "Malchik"},
+		{SchemeICAO, "Tom и Jerry 42", "Tom i Jerry 42"},
+		{SchemeKazakh, "Қазақстан", "Qazaqstan"},
+		{SchemeKazakh, "ӨСКЕМЕН", "ÖSKEMEN"},
+		{SchemeKazakh, "Іле", "Ile"},
+		{SchemeKazakh, "Шымкент", "Şymkent"},
+		{nil, "Жанна", "Zhanna"},
+	}
+	for _, tt := range tests {
+		if got := Transliterate(tt.in, tt.scheme); got != tt.want {
+			t.Errorf("Transliterate(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestSchemeToCyrillic(t *testing.T) {
+	tests := []struct {
+		scheme *Scheme
+		in     string
+		want   string
+	}{
+		{SchemeICAO, "Shchukina", "Щукина"},
+		{SchemeICAO, "Zhanna", "Жанна"},
+		{SchemeICAO, "Mariia", "Мария"},
+		// "ie" stays two letters, not "ъ"
+		{SchemeICAO, "Sergiev", "Сергиев"},
+		{SchemeICAO, "Elena 7", "Елена 7"},
+		{SchemeKazakh, "Qazaqstan", "Қазақстан"},
+		{SchemeKazakh, "Ile", "Іле"},
+	}
+	for _, tt := range tests {
+		if got := tt.scheme.ToCyrillic(tt.in); got != tt.want {
+			t.Errorf("%s.ToCyrillic(%q) = %q, want %q", tt.scheme.Name(), tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestNewSchemeDerivesCyrillic(t *testing.T) {
+	s := NewScheme("custom", map[rune]string{'е': "e", 'э': "e", 'ь': "", 'ш': "sh"}, nil)
+	if got := s.ToCyrillic("she"); got != "ше" {
+		t.Errorf("ToCyrillic() = %q, want the lowest code point for ambiguous e", got)
+	}
+	if got := s.ToLatin("шь"); got != "sh" {
+		t.Errorf("ToLatin() = %q, want dropped soft sign", got)
+	}
+}
@@ -0,0 +1,135 @@
+package i18n
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+// maxVariants bounds variant keys of one call, candidates grow as 2^n
+const maxVariants = 4
+
+// Variant keys used by the SDK templates
+const (
+	VariantGender    = "gender"    // male, female
+	VariantFormality = "formality" // formal, informal
+)
+
+// Option configures Translate
+type Option func(*translateOptions)
+
+type translateOptions struct {
+	data     map[string]interface{}
+	count    interface{}
+	variants []variant
+}
+
+type variant struct {
+	key   string
+	value string
+}
+
+// WithData sets template data
+func WithData(data map[string]interface{}) Option {
+	return func(o *translateOptions) {
+		o.data = data
+	}
+}
+
+// WithCount selects the plural form, e.g. one/few/many in Russian
+func WithCount(count interface{}) Option {
+	return func(o *translateOptions) {
+		o.count = count
+	}
+}
+
+// WithVariant selects message variant by a context key, e.g.
+// WithVariant(VariantGender, "female") prefers "welcome@gender=female"
+// over "welcome". Earlier variants win when not all of them are translated
+func WithVariant(key, value string) Option {
+	return func(o *translateOptions) {
+		if value != "" && len(o.variants) < maxVariants {
+			o.variants = append(o.variants, variant{key: key, value: value})
+		}
+	}
+}
+
+// Translate translates message choosing the most specific variant present.
+// Variants are separate messages with ID suffix "@key=value", keys of
+// combined variants are sorted:
+//
+//	welcome: "Добро пожаловать, {{.Name}}"
+//	welcome@gender=female: "Уважаемая {{.Name}}, добро пожаловать"
+//	welcome@formality=informal,gender=female: "Привет, {{.Name}}, рады тебе"
+//
+// Plural forms work inside every variant. A variant in the requested
+// language wins over any message of the default language
+func (i *I18n) Translate(lang, messageID string, opts ...Option) string {
+	o := &translateOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	localizer := i.Localizer(lang)
+	var fallback string
+	for _, id := range variantIDs(messageID, o.variants) {
+		msg, err := localizer.Localize(&i18n.LocalizeConfig{
+			MessageID:    id,
+			TemplateData: o.data,
+			PluralCount:  o.count,
+		})
+		if err == nil {
+			return msg
+		}
+		// Found in the default language only, a later ID may be translated
+		if fallback == "" && msg != "" {
+			fallback = msg
+		}
+	}
+
+	if fallback != "" {
+		return fallback
+	}
+	return messageID
+}
+
+// variantIDs lists message IDs from the most specific to the base ID: larger
+// variant subsets first, subsets of earlier variants first within a size
+func variantIDs(messageID string, variants []variant) []string {
+	n := len(variants)
+	ids := make([]string, 0, 1<<n)
+
+	for size := n; size > 0; size-- {
+		for _, subset := range combinations(n, size) {
+			parts := make([]string, len(subset))
+			for j, idx := range subset {
+				parts[j] = variants[idx].key + "=" + variants[idx].value
+			}
+			sort.Strings(parts)
+			ids = append(ids, messageID+"@"+strings.Join(parts, ","))
+		}
+	}
+	return append(ids, messageID)
+}
+
+// combinations returns index subsets of the given size in lexicographic order
+func combinations(n, size int) [][]int {
+	var result [][]int
+	subset := make([]int, 0, size)
+
+	var walk func(start int)
+	walk = func(start int) {
+		if len(subset) == size {
+			result = append(result, append([]int(nil), subset...))
+			return
+		}
+		for idx := start; idx < n; idx++ {
+			subset = append(subset, idx)
+			walk(idx + 1)
+			subset = subset[:len(subset)-1]
+		}
+	}
+	walk(0)
+	return result
+}
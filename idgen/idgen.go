@@ -0,0 +1,50 @@
+// Package idgen generates time-ordered IDs: UUIDv7, ULID and snowflake.
+// Ordered IDs keep B-tree indexes append-only, unlike random UUIDv4
+package idgen
+
+import (
+	"sync/atomic"
+
+	"github.com/google/uuid"
+)
+
+// Generator generates unique IDs
+type Generator interface {
+	NewString() string
+}
+
+var defaultGenerator atomic.Pointer[Generator]
+
+func init() {
+	SetDefault(UUIDv7{})
+}
+
+// SetDefault replaces the generator used by NewString, e.g. with a
+// snowflake generator for services storing IDs as bigint
+func SetDefault(g Generator) {
+	defaultGenerator.Store(&g)
+}
+
+// NewString returns an ID of the default generator, UUIDv7 unless
+// replaced. The request ID middleware, outbox events and generated
+// entities use it
+func NewString() string {
+	return (*defaultGenerator.Load()).NewString()
+}
+
+// UUIDv7 generates time-ordered UUIDs (RFC 9562), monotonic within the
+// process even for IDs of the same millisecond
+type UUIDv7 struct{}
+
+var _ Generator = UUIDv7{}
+
+// New returns a new UUIDv7
+func (UUIDv7) New() uuid.UUID {
+	// NewV7 fails only if the random source fails, like uuid.New panics
+	return uuid.Must(uuid.NewV7())
+}
+
+// NewString implements Generator
+func (g UUIDv7) NewString() string {
+	return g.New().String()
+}
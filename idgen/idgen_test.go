@@ -0,0 +1,173 @@
+package idgen
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// assertOrderedUnique checks that ids are strictly increasing
+func assertOrderedUnique(t *testing.T, ids []string) {
+	t.Helper()
+	for i := 1; i < len(ids); i++ {
+		if ids[i] <= ids[i-1] {
+			t.Fatalf("ids[%d] = %s is not after %s", i, ids[i], ids[i-1])
+		}
+	}
+}
+
+func TestUUIDv7Ordered(t *testing.T) {
+	ids := make([]string, 1000)
+	for i := range ids {
+		ids[i] = UUIDv7{}.NewString()
+	}
+	assertOrderedUnique(t, ids)
+
+	id := uuid.MustParse(ids[0])
+	if id.Version() != 7 {
+		t.Errorf("version = %d, want 7", id.Version())
+	}
+}
+
+func TestSetDefault(t *testing.T) {
+	t.Cleanup(func() { SetDefault(UUIDv7{}) })
+
+	if _, err := uuid.Parse(NewString()); err != nil {
+		t.Errorf("default NewString() is not a UUID: %v", err)
+	}
+	SetDefault(NewULID())
+	if id := NewString(); len(id) != 26 {
+		t.Errorf("NewString() = %s, want a ULID", id)
+	}
+}
+
+func TestULIDMonotonic(t *testing.T) {
+	g := NewULID()
+	before := time.Now().Truncate(time.Millisecond)
+
+	ids := make([]string, 10000)
+	for i := range ids {
+		ids[i] = g.NewString()
+	}
+	assertOrderedUnique(t, ids)
+
+	ts, err := ULIDTime(ids[0])
+	if err != nil {
+		t.Fatalf("ULIDTime() error = %v", err)
+	}
+	if ts.Before(before) || ts.After(time.Now()) {
+		t.Errorf("ULIDTime() = %s, want around now", ts)
+	}
+	if strings.Trim(ids[0], crockford) != "" {
+		t.Errorf("ULID %s has characters outside Crockford base32", ids[0])
+	}
+}
+
+func TestULIDOverflowBorrowsNextMillisecond(t *testing.T) {
+	g := NewULID()
+	// The clock went back and the random part is exhausted
+	last := time.Now().Add(time.Hour).UnixMilli()
+	g.lastMs = last
+	for i := range g.random {
+		g.random[i] = 0xff
+	}
+
+	id := g.New()
+	var timestamp [8]byte
+	copy(timestamp[2:], id[:6])
+	if ms := int64(binary.BigEndian.Uint64(timestamp[:])); ms != last+1 {
+		t.Errorf("timestamp = %d, want the borrowed millisecond %d", ms, last+1)
+	}
+	if next := g.New(); bytes.Compare(next[:], id[:]) <= 0 {
+		t.Error("ULID after the overflow is not ordered")
+	}
+}
+
+func TestULIDTimeErrors(t *testing.T) {
+	if _, err := ULIDTime("short"); err == nil {
+		t.Error("ULIDTime() of a short id error = nil")
+	}
+	if _, err := ULIDTime("01HZZZZZZU" + strings.Repeat("0", 16)); err == nil {
+		t.Error("ULIDTime() with U error = nil")
+	}
+	if _, err := ULIDTime(strings.ToLower(NewULID().NewString())); err != nil {
+		t.Errorf("ULIDTime() of a lower case id error = %v", err)
+	}
+}
+
+func TestSnowflake(t *testing.T) {
+	cfg := DefaultSnowflakeConfig(7)
+	g, err := NewSnowflake(cfg)
+	if err != nil {
+		t.Fatalf("NewSnowflake() error = %v", err)
+	}
+
+	var (
+		mu  sync.Mutex
+		ids = map[int64]bool{}
+		wg  sync.WaitGroup
+	)
+	for w := 0; w < 4; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			last := int64(-1)
+			for i := 0; i < 5000; i++ {
+				id := g.Next()
+				if id <= last {
+					t.Errorf("id %d is not after %d", id, last)
+					return
+				}
+				last = id
+				mu.Lock()
+				ids[id] = true
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if len(ids) != 20000 {
+		t.Errorf("got %d unique ids, want 20000", len(ids))
+	}
+
+	id := g.Next()
+	if node := id >> cfg.SequenceBits & (1<<cfg.NodeBits - 1); node != 7 {
+		t.Errorf("node of id = %d, want 7", node)
+	}
+	// Exhausted sequences borrow milliseconds, so the time may run ahead a bit
+	if ts := g.Time(id); ts.Before(time.Now().Add(-time.Second)) || ts.After(time.Now().Add(time.Second)) {
+		t.Errorf("Time() = %s, want around now", ts)
+	}
+	if _, err := strconv.ParseInt(g.NewString(), 10, 64); err != nil {
+		t.Errorf("NewString() is not a number: %v", err)
+	}
+}
+
+func TestNewSnowflakeValidatesConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*SnowflakeConfig)
+		want   string
+	}{
+		{"too many bits", func(c *SnowflakeConfig) { c.SequenceBits = 13 }, "at most 22"},
+		{"node too large", func(c *SnowflakeConfig) { c.NodeID = 1024 }, "between 0 and 1023"},
+		{"negative node", func(c *SnowflakeConfig) { c.NodeID = -1 }, "between 0 and 1023"},
+		{"future epoch", func(c *SnowflakeConfig) { c.Epoch = time.Now().Add(time.Hour) }, "epoch must be in the past"},
+		{"zero epoch", func(c *SnowflakeConfig) { c.Epoch = time.Time{} }, "epoch must be in the past"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultSnowflakeConfig(1)
+			tt.modify(&cfg)
+			if _, err := NewSnowflake(cfg); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("NewSnowflake() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package idgen
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// SnowflakeConfig holds snowflake generator configuration
+type SnowflakeConfig struct {
+	// Epoch is the start of timestamps, IDs last about 69 years from it
+	// with default bits
+	Epoch time.Time
+	// NodeID is unique per running instance, e.g. from a StatefulSet
+	// ordinal or a lease in Redis
+	NodeID       int64
+	NodeBits     uint8
+	SequenceBits uint8 // IDs per millisecond and node are 2^SequenceBits
+}
+
+// DefaultSnowflakeConfig returns default snowflake config for nodeID
+func DefaultSnowflakeConfig(nodeID int64) SnowflakeConfig {
+	return SnowflakeConfig{
+		Epoch:        time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		NodeID:       nodeID,
+		NodeBits:     10,
+		SequenceBits: 12,
+	}
+}
+
+// Snowflake generates 63-bit IDs: milliseconds since epoch, node ID and a
+// sequence within the millisecond. IDs fit bigint columns and are ordered
+// by time across nodes
+type Snowflake struct {
+	epoch        time.Time
+	node         int64
+	nodeBits     uint8
+	sequenceBits uint8
+
+	mu       sync.Mutex
+	lastMs   int64
+	sequence int64
+}
+
+var _ Generator = (*Snowflake)(nil)
+
+// NewSnowflake creates a snowflake generator
+func NewSnowflake(cfg SnowflakeConfig) (*Snowflake, error) {
+	if cfg.NodeBits+cfg.SequenceBits > 22 {
+		return nil, fmt.Errorf("snowflake node and sequence bits must be at most 22, got %d", cfg.NodeBits+cfg.SequenceBits)
+	}
+	if maxNode := int64(1)<<cfg.NodeBits - 1; cfg.NodeID < 0 || cfg.NodeID > maxNode {
+		return nil, fmt.Errorf("snowflake node ID must be between 0 and %d, got %d", maxNode, cfg.NodeID)
+	}
+	if cfg.Epoch.IsZero() || cfg.Epoch.After(time.Now()) {
+		return nil, fmt.Errorf("snowflake epoch must be in the past")
+	}
+
+	return &Snowflake{
+		epoch:        cfg.Epoch,
+		node:         cfg.NodeID,
+		nodeBits:     cfg.NodeBits,
+		sequenceBits: cfg.SequenceBits,
+	}, nil
+}
+
+// Next returns a new ID. When the sequence of a millisecond is exhausted
+// or the clock goes back, the next millisecond is borrowed instead of
+// blocking, IDs stay unique and ordered
+func (s *Snowflake) Next() int64 {
+	ms := time.Since(s.epoch).Milliseconds()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if ms <= s.lastMs {
+		ms = s.lastMs
+		s.sequence = (s.sequence + 1) & (int64(1)<<s.sequenceBits - 1)
+		if s.sequence == 0 {
+			ms++
+		}
+	} else {
+		s.sequence = 0
+	}
+	s.lastMs = ms
+
+	return ms<<(s.nodeBits+s.sequenceBits) | s.node<<s.sequenceBits | s.sequence
+}
+
+// NewString implements Generator
+func (s *Snowflake) NewString() string {
+	return strconv.FormatInt(s.Next(), 10)
+}
+
+// Time returns the timestamp of an ID of this generator
+func (s *Snowflake) Time(id int64) time.Time {
+	return s.epoch.Add(time.Duration(id>>(s.nodeBits+s.sequenceBits)) * time.Millisecond)
+}
@@ -0,0 +1,108 @@
+package idgen
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// crockford is the Crockford base32 alphabet of ULIDs
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// ULID generates ULIDs: 48-bit millisecond timestamp and 80 random bits as
+// 26 Crockford base32 characters. IDs of the same millisecond increment
+// the random part, so they stay ordered
+type ULID struct {
+	mu     sync.Mutex
+	lastMs int64
+	random [10]byte
+}
+
+var _ Generator = (*ULID)(nil)
+
+// NewULID creates a monotonic ULID generator
+func NewULID() *ULID {
+	return &ULID{}
+}
+
+// New returns a new ULID as 16 bytes
+func (g *ULID) New() [16]byte {
+	ms := time.Now().UnixMilli()
+
+	g.mu.Lock()
+	if ms <= g.lastMs {
+		// Same millisecond or the clock went back
+		ms = g.lastMs
+		if !increment(g.random[:]) {
+			// Random part overflowed, borrow the next millisecond
+			ms++
+			readRandom(g.random[:])
+		}
+	} else {
+		readRandom(g.random[:])
+	}
+	g.lastMs = ms
+
+	var id [16]byte
+	var timestamp [8]byte
+	binary.BigEndian.PutUint64(timestamp[:], uint64(ms))
+	copy(id[:6], timestamp[2:])
+	copy(id[6:], g.random[:])
+	g.mu.Unlock()
+
+	return id
+}
+
+// NewString implements Generator
+func (g *ULID) NewString() string {
+	id := g.New()
+	hi := binary.BigEndian.Uint64(id[:8])
+	lo := binary.BigEndian.Uint64(id[8:])
+
+	// 26 characters of 5 bits encode 130 bits, the top 2 are zero
+	var out [26]byte
+	for i := len(out) - 1; i >= 0; i-- {
+		out[i] = crockford[lo&31]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return string(out[:])
+}
+
+// ULIDTime returns the timestamp of a ULID string
+func ULIDTime(id string) (time.Time, error) {
+	if len(id) != 26 {
+		return time.Time{}, fmt.Errorf("invalid ULID length %d", len(id))
+	}
+
+	// The first 10 characters hold the 48-bit timestamp
+	var ms uint64
+	for _, c := range strings.ToUpper(id[:10]) {
+		v := strings.IndexRune(crockford, c)
+		if v < 0 {
+			return time.Time{}, fmt.Errorf("invalid ULID character %q", c)
+		}
+		ms = ms<<5 | uint64(v)
+	}
+	return time.UnixMilli(int64(ms)), nil
+}
+
+// increment adds one to a big-endian number, false on overflow
+func increment(b []byte) bool {
+	for i := len(b) - 1; i >= 0; i-- {
+		b[i]++
+		if b[i] != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func readRandom(b []byte) {
+	if _, err := rand.Read(b); err != nil {
+		panic(fmt.Sprintf("idgen: failed to read random bytes: %v", err))
+	}
+}
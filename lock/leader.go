@@ -0,0 +1,118 @@
+package lock
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/alimzhanovlr/sdk/logger"
+	"go.uber.org/fx"
+)
+
+// LeaderElector runs a function on exactly one replica at a time
+type LeaderElector struct {
+	locker        Locker
+	key           string
+	retryInterval time.Duration
+	logger        *logger.Logger
+
+	leader atomic.Bool
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewLeaderElector creates a new leader elector for key
+func NewLeaderElector(locker Locker, key string, retryInterval time.Duration, log *logger.Logger) *LeaderElector {
+	if retryInterval <= 0 {
+		retryInterval = 5 * time.Second
+	}
+	return &LeaderElector{
+		locker:        locker,
+		key:           key,
+		retryInterval: retryInterval,
+		logger:        log,
+	}
+}
+
+// IsLeader reports whether this replica currently holds leadership
+func (e *LeaderElector) IsLeader() bool {
+	return e.leader.Load()
+}
+
+// Run campaigns for leadership until ctx is done. While leader, fn runs
+// with a context that is cancelled when leadership is lost
+func (e *LeaderElector) Run(ctx context.Context, fn func(ctx context.Context)) {
+	for {
+		l, err := e.locker.TryAcquire(ctx, e.key)
+		if err != nil {
+			if !errors.Is(err, ErrNotAcquired) && ctx.Err() == nil {
+				e.logger.Error("Leader election failed",
+					logger.String("key", e.key),
+					logger.Error(err),
+				)
+			}
+		} else {
+			e.lead(ctx, l, fn)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(e.retryInterval):
+		}
+	}
+}
+
+// lead runs fn while lock is held
+func (e *LeaderElector) lead(ctx context.Context, l Lock, fn func(ctx context.Context)) {
+	e.leader.Store(true)
+	e.logger.Info("Acquired leadership", logger.String("key", e.key))
+
+	leaderCtx, cancel := context.WithCancel(ctx)
+	go func() {
+		select {
+		case <-l.Lost():
+			e.logger.Error("Lost leadership", logger.String("key", e.key))
+			cancel()
+		case <-leaderCtx.Done():
+		}
+	}()
+
+	fn(leaderCtx)
+	cancel()
+
+	e.leader.Store(false)
+
+	releaseCtx, releaseCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer releaseCancel()
+	if err := l.Release(releaseCtx); err != nil {
+		e.logger.Error("Failed to release leadership", logger.Error(err))
+	}
+}
+
+// Start runs elector in fx lifecycle
+func (e *LeaderElector) Start(lc fx.Lifecycle, fn func(ctx context.Context)) {
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			runCtx, cancel := context.WithCancel(context.Background())
+			e.cancel = cancel
+
+			e.wg.Add(1)
+			go func() {
+				defer e.wg.Done()
+				e.Run(runCtx, fn)
+			}()
+
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			if e.cancel != nil {
+				e.cancel()
+			}
+			e.wg.Wait()
+			return nil
+		},
+	})
+}
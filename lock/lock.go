@@ -0,0 +1,117 @@
+package lock
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrNotAcquired is returned when lock is held by someone else
+var ErrNotAcquired = errors.New("lock: not acquired")
+
+// Lock is a held distributed lock
+type Lock interface {
+	// Key returns lock key
+	Key() string
+	// Lost is closed when the lease could not be renewed and the lock
+	// may have been taken over by another holder
+	Lost() <-chan struct{}
+	// Release releases the lock
+	Release(ctx context.Context) error
+}
+
+// Locker acquires distributed locks
+type Locker interface {
+	// TryAcquire acquires lock without waiting, returns ErrNotAcquired if taken
+	TryAcquire(ctx context.Context, key string) (Lock, error)
+}
+
+// Acquire blocks until lock is acquired or ctx is done
+func Acquire(ctx context.Context, locker Locker, key string, retryInterval time.Duration) (Lock, error) {
+	if retryInterval <= 0 {
+		retryInterval = time.Second
+	}
+
+	for {
+		l, err := locker.TryAcquire(ctx, key)
+		if err == nil {
+			return l, nil
+		}
+		if !errors.Is(err, ErrNotAcquired) {
+			return nil, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(retryInterval):
+		}
+	}
+}
+
+// lease renews a lock in background until released
+type lease struct {
+	key     string
+	lost    chan struct{}
+	stop    chan struct{}
+	done    chan struct{}
+	once    sync.Once
+	release func(ctx context.Context) error
+}
+
+// newLease starts renewal loop, renew is called every interval
+func newLease(key string, interval time.Duration, renew func(ctx context.Context) error, release func(ctx context.Context) error) *lease {
+	l := &lease{
+		key:     key,
+		lost:    make(chan struct{}),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+		release: release,
+	}
+
+	go func() {
+		defer close(l.done)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-l.stop:
+				return
+			case <-ticker.C:
+				ctx, cancel := context.WithTimeout(context.Background(), interval)
+				err := renew(ctx)
+				cancel()
+				if err != nil {
+					close(l.lost)
+					return
+				}
+			}
+		}
+	}()
+
+	return l
+}
+
+// Key implements Lock
+func (l *lease) Key() string {
+	return l.key
+}
+
+// Lost implements Lock
+func (l *lease) Lost() <-chan struct{} {
+	return l.lost
+}
+
+// Release implements Lock
+func (l *lease) Release(ctx context.Context) error {
+	var err error
+	l.once.Do(func() {
+		close(l.stop)
+		<-l.done
+		err = l.release(ctx)
+	})
+	return err
+}
@@ -0,0 +1,80 @@
+package lock
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"hash/fnv"
+	"time"
+)
+
+// PostgresLocker implements Locker with PostgreSQL session advisory locks
+type PostgresLocker struct {
+	db            *sql.DB
+	checkInterval time.Duration
+}
+
+// NewPostgresLocker creates a Postgres advisory lock locker. The lock lives
+// as long as its dedicated connection, which is health-checked every
+// checkInterval to detect loss
+func NewPostgresLocker(db *sql.DB, checkInterval time.Duration) *PostgresLocker {
+	if checkInterval <= 0 {
+		checkInterval = 10 * time.Second
+	}
+	return &PostgresLocker{db: db, checkInterval: checkInterval}
+}
+
+// TryAcquire implements Locker
+func (l *PostgresLocker) TryAcquire(ctx context.Context, key string) (Lock, error) {
+	conn, err := l.db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get connection: %w", err)
+	}
+
+	id := AdvisoryKey(key)
+
+	var acquired bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", id).Scan(&acquired); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to acquire advisory lock: %w", err)
+	}
+
+	if !acquired {
+		conn.Close()
+		return nil, ErrNotAcquired
+	}
+
+	renew := func(ctx context.Context) error {
+		return conn.PingContext(ctx)
+	}
+
+	release := func(ctx context.Context) error {
+		var released bool
+		if err := conn.QueryRowContext(ctx, "SELECT pg_advisory_unlock($1)", id).Scan(&released); err != nil {
+			discard(conn)
+			return fmt.Errorf("failed to release advisory lock %s: %w", key, err)
+		}
+		if !released {
+			discard(conn)
+			return fmt.Errorf("advisory lock %s was not held by this session", key)
+		}
+		return conn.Close()
+	}
+
+	return newLease(key, l.checkInterval, renew, release), nil
+}
+
+// discard drops conn from the pool instead of returning it, so a session
+// that may still hold the advisory lock is closed and the lock is freed
+func discard(conn *sql.Conn) {
+	_ = conn.Raw(func(any) error { return driver.ErrBadConn })
+	conn.Close()
+}
+
+// AdvisoryKey maps string key to advisory lock id
+func AdvisoryKey(key string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	return int64(h.Sum64())
+}
@@ -0,0 +1,95 @@
+package lock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-redsync/redsync/v4"
+	redsyncredis "github.com/go-redsync/redsync/v4/redis"
+	"github.com/go-redsync/redsync/v4/redis/goredis/v9"
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisLocker implements Locker with redsync (Redlock algorithm)
+type RedisLocker struct {
+	rs  *redsync.Redsync
+	ttl time.Duration
+}
+
+// NewRedisLocker creates a Redis locker, pass several independent clients
+// to get Redlock quorum semantics
+func NewRedisLocker(ttl time.Duration, clients ...redis.UniversalClient) *RedisLocker {
+	if ttl <= 0 {
+		ttl = 30 * time.Second
+	}
+
+	pools := make([]redsyncredis.Pool, 0, len(clients))
+	for _, client := range clients {
+		pools = append(pools, goredis.NewPool(client))
+	}
+
+	return &RedisLocker{
+		rs:  redsync.New(pools...),
+		ttl: ttl,
+	}
+}
+
+// TryAcquire implements Locker
+func (l *RedisLocker) TryAcquire(ctx context.Context, key string) (Lock, error) {
+	mutex := l.rs.NewMutex(key,
+		redsync.WithExpiry(l.ttl),
+		redsync.WithTries(1),
+	)
+
+	if err := mutex.TryLockContext(ctx); err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if lockTaken(err) {
+			return nil, fmt.Errorf("%w: %v", ErrNotAcquired, err)
+		}
+		return nil, fmt.Errorf("failed to acquire redis lock %s: %w", key, err)
+	}
+
+	renew := func(ctx context.Context) error {
+		ok, err := mutex.ExtendContext(ctx)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return errors.New("lock: lease extension rejected")
+		}
+		return nil
+	}
+
+	release := func(ctx context.Context) error {
+		if _, err := mutex.UnlockContext(ctx); err != nil {
+			return fmt.Errorf("failed to release redis lock %s: %w", key, err)
+		}
+		return nil
+	}
+
+	return newLease(key, l.ttl/3, renew, release), nil
+}
+
+// lockTaken reports whether err means the lock is held by someone else or
+// the quorum was not reached, not that Redis is unavailable
+func lockTaken(err error) bool {
+	var (
+		taken     *redsync.ErrTaken
+		nodeTaken *redsync.ErrNodeTaken
+		redisErr  *redsync.RedisError
+	)
+	switch {
+	case errors.As(err, &taken):
+		return true
+	case errors.As(err, &redisErr):
+		// At least one node failed, the caller must see the outage
+		return false
+	case errors.Is(err, redsync.ErrFailed), errors.As(err, &nodeTaken):
+		return true
+	}
+	return false
+}
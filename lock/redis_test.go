@@ -0,0 +1,46 @@
+package lock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/go-redsync/redsync/v4"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestLockTaken(t *testing.T) {
+	refused := errors.New("dial tcp 127.0.0.1:6379: connect: connection refused")
+
+	tests := map[string]struct {
+		err  error
+		want bool
+	}{
+		"taken in quorum":     {&redsync.ErrTaken{Nodes: []int{0, 1}}, true},
+		"quorum failed":       {redsync.ErrFailed, true},
+		"node taken":          {fmt.Errorf("acquire: %w", &redsync.ErrNodeTaken{Node: 1}), true},
+		"redis down":          {&redsync.RedisError{Node: 0, Err: refused}, false},
+		"node taken and down": {errors.Join(&redsync.ErrNodeTaken{Node: 0}, &redsync.RedisError{Node: 1, Err: refused}), false},
+		"other":               {refused, false},
+	}
+	for name, tt := range tests {
+		if got := lockTaken(tt.err); got != tt.want {
+			t.Errorf("%s: lockTaken() = %v, want %v", name, got, tt.want)
+		}
+	}
+}
+
+func TestRedisLockerPassesInfrastructureErrors(t *testing.T) {
+	client := redis.NewClient(&redis.Options{Addr: "127.0.0.1:1", MaxRetries: -1})
+	defer client.Close()
+
+	_, err := NewRedisLocker(time.Second, client).TryAcquire(context.Background(), "jobs:report")
+	if err == nil {
+		t.Fatal("expected error for unreachable redis")
+	}
+	if errors.Is(err, ErrNotAcquired) {
+		t.Errorf("unreachable redis reported as a taken lock: %v", err)
+	}
+}
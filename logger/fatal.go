@@ -0,0 +1,139 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	// fatalTraceTimeout bounds flushing of the final trace
+	fatalTraceTimeout = 2 * time.Second
+	// fatalHookTimeout bounds every fatal hook
+	fatalHookTimeout = 3 * time.Second
+)
+
+// exit is replaced in tests
+var exit = os.Exit
+
+// fatalHook is a named step run before a fatal exit
+type fatalHook struct {
+	name string
+	fn   func(ctx context.Context) error
+}
+
+// terminator replaces zap's immediate os.Exit on Fatal and flushes sinks
+// before zap's panic on Panic and DPanic
+type terminator struct {
+	mu      sync.Mutex
+	hooks   []fatalHook
+	log     *Logger
+	exiting atomic.Bool
+}
+
+// OnFatal registers a hook run by Fatal before the process exits, e.g.
+// stopping the fx application. Hooks run in reverse registration order, a
+// hook that does not finish in a few seconds is abandoned.
+// Loggers not created by New, e.g. in tests, ignore hooks
+func (l *Logger) OnFatal(name string, fn func(ctx context.Context) error) {
+	if l.terminator == nil {
+		return
+	}
+	l.terminator.mu.Lock()
+	defer l.terminator.mu.Unlock()
+
+	l.terminator.hooks = append(l.terminator.hooks, fatalHook{name: name, fn: fn})
+}
+
+// OnWrite implements zapcore.CheckWriteHook. Fatal entries are followed by a
+// final trace, fatal hooks and flushing of sinks, then the process exits
+func (t *terminator) OnWrite(ce *zapcore.CheckedEntry, fields []zapcore.Field) {
+	if ce.Level < zapcore.FatalLevel {
+		// Panics may be recovered, so sinks are flushed but stay open
+		_ = t.log.Sync()
+		panic(ce.Message)
+	}
+
+	if !t.exiting.CompareAndSwap(false, true) {
+		// Fatal from a hook or another goroutine, the first one exits
+		select {}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), fatalTraceTimeout)
+	finalTrace(ctx, ce, fields)
+	cancel()
+
+	t.runHooks()
+	_ = t.log.Close()
+	exit(1)
+}
+
+func (t *terminator) runHooks() {
+	t.mu.Lock()
+	hooks := make([]fatalHook, len(t.hooks))
+	copy(hooks, t.hooks)
+	t.mu.Unlock()
+
+	for i := len(hooks) - 1; i >= 0; i-- {
+		if err := runFatalHook(hooks[i]); err != nil {
+			t.log.Error("Fatal hook failed", String("hook", hooks[i].name), Error(err))
+		}
+	}
+}
+
+func runFatalHook(hook fatalHook) error {
+	ctx, cancel := context.WithTimeout(context.Background(), fatalHookTimeout)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- hook.fn(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("timed out: %w", ctx.Err())
+	}
+}
+
+// finalTrace records the fatal entry as a span and flushes the tracer
+// provider, so the crash is visible in tracing as well
+func finalTrace(ctx context.Context, ce *zapcore.CheckedEntry, fields []zapcore.Field) {
+	enc := zapcore.NewMapObjectEncoder()
+	for _, field := range fields {
+		field.AddTo(enc)
+	}
+	attrs := []attribute.KeyValue{
+		attribute.String("log.severity", ce.Level.String()),
+		attribute.String("log.message", ce.Message),
+	}
+	if ce.LoggerName != "" {
+		attrs = append(attrs, attribute.String("log.logger", ce.LoggerName))
+	}
+	if ce.Caller.Defined {
+		attrs = append(attrs, attribute.String("log.caller", ce.Caller.TrimmedPath()))
+	}
+	for key, value := range enc.Fields {
+		attrs = append(attrs, spanAttribute(key, value))
+	}
+
+	_, span := otel.Tracer("github.com/alimzhanovlr/sdk/logger").Start(ctx, "fatal")
+	span.AddEvent("log", trace.WithAttributes(attrs...))
+	span.SetStatus(codes.Error, ce.Message)
+	span.End()
+
+	if provider, ok := otel.GetTracerProvider().(interface{ ForceFlush(context.Context) error }); ok {
+		_ = provider.ForceFlush(ctx)
+	}
+}
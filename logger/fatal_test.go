@@ -0,0 +1,126 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// stubExit replaces exit for the test and returns the recorded codes
+func stubExit(t *testing.T) *[]int {
+	t.Helper()
+	var codes []int
+	previous := exit
+	exit = func(code int) { codes = append(codes, code) }
+	t.Cleanup(func() { exit = previous })
+	return &codes
+}
+
+func newFileLogger(t *testing.T) (*Logger, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "app.log")
+	log, err := New(Config{Level: "info", OutputPath: path})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	return log, path
+}
+
+func TestFatalRunsHooksInReverseOrderAndExits(t *testing.T) {
+	codes := stubExit(t)
+	log, path := newFileLogger(t)
+
+	var order []string
+	log.OnFatal("server", func(context.Context) error {
+		order = append(order, "server")
+		return nil
+	})
+	log.OnFatal("consumer", func(context.Context) error {
+		order = append(order, "consumer")
+		return errors.New("still running")
+	})
+
+	log.Fatal("cannot continue", String("reason", "disk full"))
+
+	if got := strings.Join(order, ","); got != "consumer,server" {
+		t.Errorf("hooks ran as %s, want consumer,server", got)
+	}
+	if len(*codes) != 1 || (*codes)[0] != 1 {
+		t.Errorf("exit codes = %v, want [1]", *codes)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	output := string(data)
+	if !strings.Contains(output, `"msg":"cannot continue"`) || !strings.Contains(output, `"reason":"disk full"`) {
+		t.Errorf("fatal entry not flushed:\n%s", output)
+	}
+	if !strings.Contains(output, `"msg":"Fatal hook failed"`) || !strings.Contains(output, `"hook":"consumer"`) {
+		t.Errorf("failed hook not logged:\n%s", output)
+	}
+}
+
+func TestFatalRecordsFinalTrace(t *testing.T) {
+	stubExit(t)
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	log, _ := newFileLogger(t)
+	log.Fatal("cannot continue", Int("attempt", 3))
+
+	spans := recorder.Ended()
+	if len(spans) != 1 || spans[0].Name() != "fatal" {
+		t.Fatalf("spans = %v, want one fatal span", spans)
+	}
+	span := spans[0]
+	if span.Status().Code != codes.Error || span.Status().Description != "cannot continue" {
+		t.Errorf("status = %+v", span.Status())
+	}
+
+	attrs := map[string]string{}
+	for _, attr := range span.Events()[0].Attributes {
+		attrs[string(attr.Key)] = attr.Value.Emit()
+	}
+	if attrs["log.message"] != "cannot continue" || attrs["attempt"] != "3" || attrs["log.severity"] != "fatal" {
+		t.Errorf("event attributes = %v", attrs)
+	}
+}
+
+func TestPanicFlushesAndPanics(t *testing.T) {
+	codes := stubExit(t)
+	log, path := newFileLogger(t)
+
+	func() {
+		defer func() {
+			if r := recover(); r != "broken invariant" {
+				t.Errorf("recovered %v, want the log message", r)
+			}
+		}()
+		log.Panic("broken invariant")
+	}()
+
+	if len(*codes) != 0 {
+		t.Errorf("Panic must not exit, codes = %v", *codes)
+	}
+	data, _ := os.ReadFile(path)
+	if !strings.Contains(string(data), "broken invariant") {
+		t.Errorf("panic entry not written:\n%s", data)
+	}
+}
+
+func TestOnFatalIgnoredWithoutTerminator(t *testing.T) {
+	log := &Logger{}
+	log.OnFatal("noop", func(context.Context) error { return nil })
+}
@@ -0,0 +1,137 @@
+package logger
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// levels holds the root level and overrides of named loggers, shared by all
+// loggers derived from one New
+type levels struct {
+	mu        sync.RWMutex
+	root      zapcore.Level
+	overrides map[string]zapcore.Level
+}
+
+// enabled checks level against the override of the longest matching name
+// prefix, so "repository" covers "repository.orders"
+func (lv *levels) enabled(name string, level zapcore.Level) bool {
+	lv.mu.RLock()
+	defer lv.mu.RUnlock()
+
+	for n := name; n != ""; {
+		if min, ok := lv.overrides[n]; ok {
+			return level >= min
+		}
+		i := strings.LastIndexByte(n, '.')
+		if i < 0 {
+			break
+		}
+		n = n[:i]
+	}
+	return level >= lv.root
+}
+
+// levelCore filters entries by the level of a named logger
+type levelCore struct {
+	zapcore.Core
+	name   string
+	levels *levels
+}
+
+func (c *levelCore) Enabled(level zapcore.Level) bool {
+	return c.levels.enabled(c.name, level)
+}
+
+func (c *levelCore) With(fields []zapcore.Field) zapcore.Core {
+	return &levelCore{Core: c.Core.With(fields), name: c.name, levels: c.levels}
+}
+
+func (c *levelCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+// Named returns a child logger, names are joined with dots. Its level is the
+// override set for the name or its closest parent, the root level otherwise
+func (l *Logger) Named(name string) *Logger {
+	full := name
+	if l.name != "" {
+		full = l.name + "." + name
+	}
+	if l.levels == nil {
+		// Logger not created by New, levels are fixed
+		child := l.derive(l.Logger.Named(name))
+		child.name = full
+		return child
+	}
+
+	named := l.Logger.Named(name).WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		if lc, ok := core.(*levelCore); ok {
+			core = lc.Core
+		}
+		return &levelCore{Core: core, name: full, levels: l.levels}
+	}))
+
+	child := l.derive(named)
+	child.name = full
+	return child
+}
+
+// SetLevel changes level of the named logger and its children at runtime,
+// empty name changes the root level
+func (l *Logger) SetLevel(name, level string) error {
+	var parsed zapcore.Level
+	if err := parsed.UnmarshalText([]byte(level)); err != nil {
+		return fmt.Errorf("invalid log level %q", level)
+	}
+
+	l.levels.mu.Lock()
+	defer l.levels.mu.Unlock()
+
+	if name == "" {
+		l.levels.root = parsed
+	} else {
+		l.levels.overrides[name] = parsed
+	}
+	return nil
+}
+
+// ResetLevel removes the override of the named logger
+func (l *Logger) ResetLevel(name string) {
+	l.levels.mu.Lock()
+	delete(l.levels.overrides, name)
+	l.levels.mu.Unlock()
+}
+
+// Levels returns the root level under "" and all overrides
+func (l *Logger) Levels() map[string]string {
+	l.levels.mu.RLock()
+	defer l.levels.mu.RUnlock()
+
+	out := map[string]string{"": l.levels.root.String()}
+	for name, level := range l.levels.overrides {
+		out[name] = level.String()
+	}
+	return out
+}
+
+// parseLevelOverrides parses "name=level" entries
+func parseLevelOverrides(entries []string) (map[string]zapcore.Level, error) {
+	overrides := make(map[string]zapcore.Level, len(entries))
+	for _, entry := range entries {
+		name, value, ok := strings.Cut(entry, "=")
+		var level zapcore.Level
+		if !ok || name == "" || level.UnmarshalText([]byte(value)) != nil {
+			return nil, fmt.Errorf("invalid log level override %q, expected name=level", entry)
+		}
+		overrides[strings.TrimSpace(name)] = level
+	}
+	return overrides, nil
+}
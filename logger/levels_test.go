@@ -0,0 +1,91 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNamedLevels(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	log, err := New(Config{Level: "info", OutputPath: path, Levels: []string{"repository=debug", "repository.orders=error"}})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	repo := log.Named("repository")
+	orders := repo.Named("orders")
+	users := repo.Named("users")
+	http := log.Named("http")
+
+	repo.Debug("repo debug")
+	users.Debug("users debug")
+	orders.Warn("orders warn")
+	orders.Error("orders error")
+	http.Debug("http debug")
+	http.Info("http info")
+	_ = log.Sync()
+
+	data, _ := os.ReadFile(path)
+	output := string(data)
+	for _, want := range []string{"repo debug", "users debug", "orders error", "http info", `"logger":"repository.orders"`} {
+		if !strings.Contains(output, want) {
+			t.Errorf("output is missing %q:\n%s", want, output)
+		}
+	}
+	for _, unwanted := range []string{"orders warn", "http debug"} {
+		if strings.Contains(output, unwanted) {
+			t.Errorf("output contains %q:\n%s", unwanted, output)
+		}
+	}
+}
+
+func TestSetLevelAtRuntime(t *testing.T) {
+	log, path := newFileLogger(t)
+	worker := log.Named("worker")
+	child := worker.WithFields(String("job", "sync")).Named("batch")
+
+	child.Debug("before")
+	if err := log.SetLevel("worker", "debug"); err != nil {
+		t.Fatalf("SetLevel() error = %v", err)
+	}
+	child.Debug("override")
+	log.Debug("root debug")
+
+	log.ResetLevel("worker")
+	child.Debug("after reset")
+	if err := log.SetLevel("", "error"); err != nil {
+		t.Fatalf("SetLevel() of the root error = %v", err)
+	}
+	worker.Warn("root error level")
+	_ = log.Sync()
+
+	data, _ := os.ReadFile(path)
+	output := string(data)
+	if !strings.Contains(output, "override") || !strings.Contains(output, `"job":"sync"`) {
+		t.Errorf("override of the parent name not applied to children:\n%s", output)
+	}
+	for _, unwanted := range []string{"before", "root debug", "after reset", "root error level"} {
+		if strings.Contains(output, unwanted) {
+			t.Errorf("output contains %q:\n%s", unwanted, output)
+		}
+	}
+
+	if err := log.SetLevel("worker", "loud"); err == nil {
+		t.Error("SetLevel() with an invalid level error = nil")
+	}
+	_ = log.SetLevel("db", "warn")
+	levels := log.Levels()
+	if levels[""] != "error" || levels["db"] != "warn" || len(levels) != 2 {
+		t.Errorf("Levels() = %v", levels)
+	}
+}
+
+func TestInvalidLevelOverrides(t *testing.T) {
+	for _, entry := range []string{"repository", "=debug", "repository=loud"} {
+		if _, err := New(Config{Levels: []string{entry}}); err == nil || !strings.Contains(err.Error(), "expected name=level") {
+			t.Errorf("New() with %q error = %v", entry, err)
+		}
+	}
+}
@@ -1,8 +1,6 @@
 package logger
 
 import (
-	"os"
-
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
@@ -10,6 +8,14 @@ import (
 // Logger wraps zap logger
 type Logger struct {
 	*zap.Logger
+
+	// spanLevel is the minimum level added to spans by Ctx
+	spanLevel zapcore.Level
+	name      string
+	levels    *levels
+	sinks     []*netSink
+	// terminator handles Fatal and Panic entries, see OnFatal
+	terminator *terminator
 }
 
 // Config for logger
@@ -17,6 +23,13 @@ type Config struct {
 	Level      string
 	Format     string
 	OutputPath string
+	// OutputPaths replace OutputPath with several outputs: stdout, stderr,
+	// file paths and network sinks (kafka://, fluentd://, syslog://)
+	OutputPaths []string
+	// SpanEventLevel is the minimum level Ctx loggers add to the active span, warn by default
+	SpanEventLevel string
+	// Levels override level of named loggers, entries are "name=level"
+	Levels []string
 }
 
 // New creates a new logger instance
@@ -51,42 +64,70 @@ func New(cfg Config) (*Logger, error) {
 		encoder = zapcore.NewJSONEncoder(encoderConfig)
 	}
 
-	// Output
-	var output zapcore.WriteSyncer
-	if cfg.OutputPath == "stdout" || cfg.OutputPath == "" {
-		output = zapcore.AddSync(os.Stdout)
-	} else {
-		file, err := os.OpenFile(cfg.OutputPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-		if err != nil {
-			return nil, err
+	spanLevel := zapcore.WarnLevel
+	if cfg.SpanEventLevel != "" {
+		if err := spanLevel.UnmarshalText([]byte(cfg.SpanEventLevel)); err != nil {
+			spanLevel = zapcore.WarnLevel
 		}
-		output = zapcore.AddSync(file)
 	}
 
-	core := zapcore.NewCore(encoder, output, level)
-	zapLogger := zap.New(core, zap.AddCaller(), zap.AddCallerSkip(1))
+	overrides, err := parseLevelOverrides(cfg.Levels)
+	if err != nil {
+		return nil, err
+	}
+	lv := &levels{root: level, overrides: overrides}
 
-	return &Logger{Logger: zapLogger}, nil
+	// Output
+	paths := cfg.OutputPaths
+	if len(paths) == 0 {
+		paths = []string{cfg.OutputPath}
+	}
+	output, sinks, err := openOutputs(paths, encoder, encoderConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	// Levels are checked by levelCore, so the output core accepts everything
+	core := &levelCore{Core: output, levels: lv}
+	term := &terminator{}
+	zapLogger := zap.New(core, zap.AddCaller(), zap.AddCallerSkip(1),
+		zap.WithFatalHook(term), zap.WithPanicHook(term))
+
+	log := &Logger{Logger: zapLogger, spanLevel: spanLevel, levels: lv, sinks: sinks, terminator: term}
+	term.log = log
+	return log, nil
+}
+
+// derive returns logger with the same settings around z
+func (l *Logger) derive(z *zap.Logger) *Logger {
+	return &Logger{
+		Logger:     z,
+		spanLevel:  l.spanLevel,
+		name:       l.name,
+		levels:     l.levels,
+		sinks:      l.sinks,
+		terminator: l.terminator,
+	}
 }
 
 // WithFields adds fields to logger
 func (l *Logger) WithFields(fields ...zap.Field) *Logger {
-	return &Logger{Logger: l.With(fields...)}
+	return l.derive(l.With(fields...))
 }
 
 // WithError adds error field
 func (l *Logger) WithError(err error) *Logger {
-	return &Logger{Logger: l.With(zap.Error(err))}
+	return l.derive(l.With(zap.Error(err)))
 }
 
 // WithTraceID adds trace ID field
 func (l *Logger) WithTraceID(traceID string) *Logger {
-	return &Logger{Logger: l.With(zap.String("trace_id", traceID))}
+	return l.derive(l.With(zap.String("trace_id", traceID)))
 }
 
 // WithRequestID adds request ID field
 func (l *Logger) WithRequestID(requestID string) *Logger {
-	return &Logger{Logger: l.With(zap.String("request_id", requestID))}
+	return l.derive(l.With(zap.String("request_id", requestID)))
 }
 
 // Helper functions for zap fields
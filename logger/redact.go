@@ -0,0 +1,61 @@
+package logger
+
+import (
+	"encoding/json"
+	"sync/atomic"
+
+	"github.com/alimzhanovlr/sdk/redact"
+	"go.uber.org/zap"
+)
+
+// redactorHolder lets atomic.Pointer store an interface
+type redactorHolder struct {
+	sanitizer redact.ValueSanitizer
+}
+
+// redactor is the sanitizer used by Redacted, redact.Default until replaced
+var redactor atomic.Pointer[redactorHolder]
+
+func init() {
+	redactor.Store(&redactorHolder{sanitizer: redact.Default()})
+}
+
+// SetRedactor replaces the sanitizer used by Redacted, e.g. an
+// httpclient.Sanitizer loaded with httpclient.LoadSanitizerConfig, so logs
+// and HTTP client share field rules and detectors. nil restores the default
+func SetRedactor(sanitizer redact.ValueSanitizer) {
+	if sanitizer == nil {
+		sanitizer = redact.Default()
+	}
+	redactor.Store(&redactorHolder{sanitizer: sanitizer})
+}
+
+// Redacted logs value as JSON with sensitive fields and patterns masked by the
+// sanitizer rules. Use it instead of Any for request structs and other
+// payloads that may contain personal data. The value is sanitized only when
+// the entry is written
+func Redacted(key string, value interface{}) zap.Field {
+	return zap.Reflect(key, redactedValue{value: value})
+}
+
+// redactedValue sanitizes lazily. String covers encoders that format values
+// with fmt instead of JSON, e.g. span events, so the raw value never leaks
+type redactedValue struct {
+	value interface{}
+}
+
+func (r redactedValue) MarshalJSON() ([]byte, error) {
+	sanitized, err := redactor.Load().sanitizer.SanitizeValue(r.value)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(sanitized)
+}
+
+func (r redactedValue) String() string {
+	data, err := r.MarshalJSON()
+	if err != nil {
+		return "[unserializable]"
+	}
+	return string(data)
+}
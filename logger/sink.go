@@ -0,0 +1,318 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	// defaultSinkBuffer is the number of entries kept while a sink is unavailable
+	defaultSinkBuffer = 10000
+	// sinkBatchSize is the maximum number of entries sent at once
+	sinkBatchSize = 500
+
+	sinkMinBackoff = 100 * time.Millisecond
+	sinkMaxBackoff = 30 * time.Second
+
+	// sinkCloseTimeout bounds flushing of network sinks on Close
+	sinkCloseTimeout = 5 * time.Second
+)
+
+// openOutputs builds the output core: local outputs share one core with the
+// configured encoder, every network sink gets a JSON core
+func openOutputs(paths []string, encoder zapcore.Encoder, encoderConfig zapcore.EncoderConfig) (zapcore.Core, []*netSink, error) {
+	var (
+		local []zapcore.WriteSyncer
+		sinks []*netSink
+		cores []zapcore.Core
+	)
+	fail := func(err error) (zapcore.Core, []*netSink, error) {
+		for _, sink := range sinks {
+			_ = sink.close(0)
+		}
+		return nil, nil, err
+	}
+
+	for _, path := range paths {
+		path = strings.TrimSpace(path)
+		switch {
+		case path == "" || path == "stdout":
+			local = append(local, zapcore.AddSync(os.Stdout))
+		case path == "stderr":
+			local = append(local, zapcore.AddSync(os.Stderr))
+		case strings.Contains(path, "://"):
+			sink, err := openSink(path)
+			if err != nil {
+				return fail(err)
+			}
+			sinks = append(sinks, sink)
+			cores = append(cores, &sinkCore{enc: zapcore.NewJSONEncoder(encoderConfig), sink: sink})
+		default:
+			file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+			if err != nil {
+				return fail(err)
+			}
+			local = append(local, zapcore.AddSync(file))
+		}
+	}
+
+	if len(local) > 0 {
+		cores = append(cores, zapcore.NewCore(encoder, zapcore.NewMultiWriteSyncer(local...), zapcore.DebugLevel))
+	}
+	return zapcore.NewTee(cores...), sinks, nil
+}
+
+// Close flushes network sinks and stops them, entries not sent within a few
+// seconds are dropped. Local outputs are only synced
+func (l *Logger) Close() error {
+	// Sync of stdout fails on some platforms, it is not worth reporting
+	_ = l.Sync()
+
+	var errs []error
+	for _, sink := range l.sinks {
+		errs = append(errs, sink.close(sinkCloseTimeout))
+	}
+	return errors.Join(errs...)
+}
+
+// sinkEntry is an encoded log entry waiting to be sent
+type sinkEntry struct {
+	level zapcore.Level
+	time  time.Time
+	data  []byte
+}
+
+// sinkWriter sends entries over one connection, an error makes the sink
+// close it, reconnect and retry the batch
+type sinkWriter interface {
+	write(entries []sinkEntry) error
+	close() error
+}
+
+// sinkDialer opens a connection to the sink backend
+type sinkDialer func() (sinkWriter, error)
+
+// netSink buffers entries and sends them from a background goroutine, so a
+// slow or unavailable backend never blocks logging. Entries over the buffer
+// are dropped and counted. One sender goroutine per sink keeps entries
+// ordered and batched, which a worker pool would not
+type netSink struct {
+	name    string
+	dial    sinkDialer
+	queue   chan sinkEntry
+	pending atomic.Int64
+	dropped atomic.Uint64
+	stop    chan struct{}
+	done    chan struct{}
+	once    sync.Once
+}
+
+// openSink creates sink for an output URL:
+//
+//	kafka://broker:9092/topic?broker=broker2:9092
+//	fluentd://host:24224?tag=app
+//	syslog://host:514?tag=app (udp), syslog+tcp://host:514
+//
+// Every sink accepts buffer=N, the number of entries kept while it is down
+func openSink(raw string) (*netSink, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid log output %q: %w", raw, err)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("invalid log output %q: host is required", raw)
+	}
+
+	var dial sinkDialer
+	switch u.Scheme {
+	case "kafka":
+		dial, err = kafkaSinkDialer(u)
+	case "fluentd":
+		dial, err = fluentdSinkDialer(u)
+	case "syslog", "syslog+udp", "syslog+tcp":
+		dial, err = syslogSinkDialer(u)
+	default:
+		err = fmt.Errorf("unsupported scheme %q", u.Scheme)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid log output %q: %w", raw, err)
+	}
+
+	buffer := defaultSinkBuffer
+	if value := u.Query().Get("buffer"); value != "" {
+		if buffer, err = strconv.Atoi(value); err != nil || buffer <= 0 {
+			return nil, fmt.Errorf("invalid log output %q: buffer must be a positive number", raw)
+		}
+	}
+
+	s := &netSink{
+		name:  u.Scheme + "://" + u.Host,
+		dial:  dial,
+		queue: make(chan sinkEntry, buffer),
+		stop:  make(chan struct{}),
+		done:  make(chan struct{}),
+	}
+	go s.run()
+	return s, nil
+}
+
+// enqueue adds entry without blocking
+func (s *netSink) enqueue(entry sinkEntry) {
+	s.pending.Add(1)
+	select {
+	case s.queue <- entry:
+	default:
+		s.pending.Add(-1)
+		s.dropped.Add(1)
+	}
+}
+
+// flush waits until buffered entries are sent or timeout passes
+func (s *netSink) flush(timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for s.pending.Load() > 0 {
+		if time.Now().After(deadline) {
+			return fmt.Errorf("log sink %s: %d entries not flushed", s.name, s.pending.Load())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return nil
+}
+
+// close flushes the sink and stops it, entries still pending are dropped
+func (s *netSink) close(timeout time.Duration) error {
+	var err error
+	s.once.Do(func() {
+		err = s.flush(timeout)
+		close(s.stop)
+		<-s.done
+		if dropped := s.dropped.Load(); dropped > 0 {
+			fmt.Fprintf(os.Stderr, "log sink %s: %d entries dropped\n", s.name, dropped)
+		}
+	})
+	return err
+}
+
+func (s *netSink) run() {
+	defer close(s.done)
+
+	var w sinkWriter
+	defer func() {
+		if w != nil {
+			_ = w.close()
+		}
+	}()
+
+	batch := make([]sinkEntry, 0, sinkBatchSize)
+	backoff := sinkMinBackoff
+	failing := false
+
+	for {
+		select {
+		case <-s.stop:
+			return
+		case entry := <-s.queue:
+			batch = append(batch[:0], entry)
+		}
+	drain:
+		for len(batch) < sinkBatchSize {
+			select {
+			case entry := <-s.queue:
+				batch = append(batch, entry)
+			default:
+				break drain
+			}
+		}
+
+		for {
+			err := s.send(&w, batch)
+			if err == nil {
+				if failing {
+					fmt.Fprintf(os.Stderr, "log sink %s: recovered\n", s.name)
+				}
+				failing = false
+				backoff = sinkMinBackoff
+				break
+			}
+			if !failing {
+				// Reported once per outage, the logger itself may be the sink
+				fmt.Fprintf(os.Stderr, "log sink %s: %v, buffering\n", s.name, err)
+				failing = true
+			}
+
+			select {
+			case <-s.stop:
+				return
+			case <-time.After(backoff):
+			}
+			backoff = min(backoff*2, sinkMaxBackoff)
+		}
+		s.pending.Add(-int64(len(batch)))
+	}
+}
+
+// send writes batch, reconnecting if needed
+func (s *netSink) send(w *sinkWriter, batch []sinkEntry) error {
+	if *w == nil {
+		conn, err := s.dial()
+		if err != nil {
+			return fmt.Errorf("failed to connect: %w", err)
+		}
+		*w = conn
+	}
+	if err := (*w).write(batch); err != nil {
+		_ = (*w).close()
+		*w = nil
+		return fmt.Errorf("failed to write: %w", err)
+	}
+	return nil
+}
+
+// sinkCore encodes entries as JSON for a network sink, levels are checked by
+// the surrounding levelCore
+type sinkCore struct {
+	enc  zapcore.Encoder
+	sink *netSink
+}
+
+func (c *sinkCore) Enabled(zapcore.Level) bool {
+	return true
+}
+
+func (c *sinkCore) With(fields []zapcore.Field) zapcore.Core {
+	enc := c.enc.Clone()
+	for _, field := range fields {
+		field.AddTo(enc)
+	}
+	return &sinkCore{enc: enc, sink: c.sink}
+}
+
+func (c *sinkCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	return checked.AddCore(entry, c)
+}
+
+func (c *sinkCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	buf, err := c.enc.EncodeEntry(entry, fields)
+	if err != nil {
+		return err
+	}
+	data := append([]byte(nil), buf.Bytes()...)
+	buf.Free()
+
+	c.sink.enqueue(sinkEntry{level: entry.Level, time: entry.Time, data: data})
+	return nil
+}
+
+// Sync waits for the sink briefly, Close waits longer on shutdown
+func (c *sinkCore) Sync() error {
+	return c.sink.flush(time.Second)
+}
@@ -0,0 +1,152 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net"
+	"net/url"
+	"time"
+)
+
+const fluentdTimeout = 5 * time.Second
+
+// fluentdSink sends entries in forward mode of the Fluentd forward protocol:
+// [tag, [[time, record], ...]] encoded as MessagePack
+type fluentdSink struct {
+	conn net.Conn
+	tag  string
+}
+
+func fluentdSinkDialer(u *url.URL) (sinkDialer, error) {
+	tag := u.Query().Get("tag")
+	if tag == "" {
+		tag = "app"
+	}
+
+	return func() (sinkWriter, error) {
+		conn, err := net.DialTimeout("tcp", u.Host, fluentdTimeout)
+		if err != nil {
+			return nil, err
+		}
+		return &fluentdSink{conn: conn, tag: tag}, nil
+	}, nil
+}
+
+func (s *fluentdSink) write(entries []sinkEntry) error {
+	var buf bytes.Buffer
+	buf.WriteByte(0x92) // fixarray of 2
+	packValue(&buf, s.tag)
+	packArrayHeader(&buf, len(entries))
+
+	for _, entry := range entries {
+		dec := json.NewDecoder(bytes.NewReader(entry.data))
+		dec.UseNumber()
+		var record map[string]interface{}
+		if err := dec.Decode(&record); err != nil {
+			record = map[string]interface{}{"msg": string(bytes.TrimRight(entry.data, "\n"))}
+		}
+
+		buf.WriteByte(0x92)
+		packValue(&buf, entry.time.Unix())
+		packValue(&buf, record)
+	}
+
+	if err := s.conn.SetWriteDeadline(time.Now().Add(fluentdTimeout)); err != nil {
+		return err
+	}
+	_, err := s.conn.Write(buf.Bytes())
+	return err
+}
+
+func (s *fluentdSink) close() error {
+	return s.conn.Close()
+}
+
+// packValue encodes JSON decoded values as MessagePack
+func packValue(buf *bytes.Buffer, value interface{}) {
+	switch v := value.(type) {
+	case nil:
+		buf.WriteByte(0xc0)
+	case bool:
+		if v {
+			buf.WriteByte(0xc3)
+		} else {
+			buf.WriteByte(0xc2)
+		}
+	case int64:
+		buf.WriteByte(0xd3)
+		_ = binary.Write(buf, binary.BigEndian, v)
+	case float64:
+		buf.WriteByte(0xcb)
+		_ = binary.Write(buf, binary.BigEndian, math.Float64bits(v))
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			packValue(buf, n)
+		} else if f, err := v.Float64(); err == nil {
+			packValue(buf, f)
+		} else {
+			packValue(buf, v.String())
+		}
+	case string:
+		packStringHeader(buf, len(v))
+		buf.WriteString(v)
+	case []interface{}:
+		packArrayHeader(buf, len(v))
+		for _, item := range v {
+			packValue(buf, item)
+		}
+	case map[string]interface{}:
+		packMapHeader(buf, len(v))
+		for key, item := range v {
+			packValue(buf, key)
+			packValue(buf, item)
+		}
+	default:
+		packValue(buf, fmt.Sprint(v))
+	}
+}
+
+func packStringHeader(buf *bytes.Buffer, n int) {
+	switch {
+	case n < 32:
+		buf.WriteByte(0xa0 | byte(n))
+	case n <= math.MaxUint8:
+		buf.WriteByte(0xd9)
+		buf.WriteByte(byte(n))
+	case n <= math.MaxUint16:
+		buf.WriteByte(0xda)
+		_ = binary.Write(buf, binary.BigEndian, uint16(n))
+	default:
+		buf.WriteByte(0xdb)
+		_ = binary.Write(buf, binary.BigEndian, uint32(n))
+	}
+}
+
+func packArrayHeader(buf *bytes.Buffer, n int) {
+	switch {
+	case n < 16:
+		buf.WriteByte(0x90 | byte(n))
+	case n <= math.MaxUint16:
+		buf.WriteByte(0xdc)
+		_ = binary.Write(buf, binary.BigEndian, uint16(n))
+	default:
+		buf.WriteByte(0xdd)
+		_ = binary.Write(buf, binary.BigEndian, uint32(n))
+	}
+}
+
+func packMapHeader(buf *bytes.Buffer, n int) {
+	switch {
+	case n < 16:
+		buf.WriteByte(0x80 | byte(n))
+	case n <= math.MaxUint16:
+		buf.WriteByte(0xde)
+		_ = binary.Write(buf, binary.BigEndian, uint16(n))
+	default:
+		buf.WriteByte(0xdf)
+		_ = binary.Write(buf, binary.BigEndian, uint32(n))
+	}
+}
@@ -0,0 +1,53 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// kafkaWriteTimeout bounds one batch, a stuck broker triggers a retry
+const kafkaWriteTimeout = 10 * time.Second
+
+type kafkaSink struct {
+	writer *kafka.Writer
+}
+
+func kafkaSinkDialer(u *url.URL) (sinkDialer, error) {
+	topic := strings.Trim(u.Path, "/")
+	if topic == "" {
+		return nil, errors.New("kafka topic is required in path")
+	}
+	brokers := append([]string{u.Host}, u.Query()["broker"]...)
+
+	return func() (sinkWriter, error) {
+		return &kafkaSink{writer: &kafka.Writer{
+			Addr:         kafka.TCP(brokers...),
+			Topic:        topic,
+			Balancer:     &kafka.LeastBytes{},
+			RequiredAcks: kafka.RequireOne,
+			// Batches are collected by the sink
+			BatchTimeout: time.Millisecond,
+		}}, nil
+	}, nil
+}
+
+func (s *kafkaSink) write(entries []sinkEntry) error {
+	msgs := make([]kafka.Message, len(entries))
+	for i, entry := range entries {
+		msgs[i] = kafka.Message{Value: bytes.TrimRight(entry.data, "\n"), Time: entry.time}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), kafkaWriteTimeout)
+	defer cancel()
+	return s.writer.WriteMessages(ctx, msgs...)
+}
+
+func (s *kafkaSink) close() error {
+	return s.writer.Close()
+}
@@ -0,0 +1,101 @@
+package logger
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	syslogTimeout = 5 * time.Second
+	// syslogFacility is local0
+	syslogFacility = 16
+)
+
+// syslogSink sends RFC 5424 messages, over TCP with octet counting framing
+type syslogSink struct {
+	conn     net.Conn
+	stream   bool
+	hostname string
+	tag      string
+}
+
+func syslogSinkDialer(u *url.URL) (sinkDialer, error) {
+	network := "udp"
+	if u.Scheme == "syslog+tcp" {
+		network = "tcp"
+	}
+	tag := u.Query().Get("tag")
+	if tag == "" {
+		tag = filepath.Base(os.Args[0])
+	}
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "-"
+	}
+
+	return func() (sinkWriter, error) {
+		conn, err := net.DialTimeout(network, u.Host, syslogTimeout)
+		if err != nil {
+			return nil, err
+		}
+		return &syslogSink{conn: conn, stream: network == "tcp", hostname: hostname, tag: tag}, nil
+	}, nil
+}
+
+func (s *syslogSink) write(entries []sinkEntry) error {
+	if err := s.conn.SetWriteDeadline(time.Now().Add(syslogTimeout)); err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	for _, entry := range entries {
+		msg := fmt.Sprintf("<%d>1 %s %s %s %d - - %s",
+			syslogFacility*8+syslogSeverity(entry.level),
+			entry.time.UTC().Format(time.RFC3339Nano),
+			s.hostname, s.tag, os.Getpid(),
+			bytes.TrimRight(entry.data, "\n"))
+
+		if !s.stream {
+			// One datagram per message
+			if _, err := s.conn.Write([]byte(msg)); err != nil {
+				return err
+			}
+			continue
+		}
+		buf.WriteString(strconv.Itoa(len(msg)))
+		buf.WriteByte(' ')
+		buf.WriteString(msg)
+	}
+
+	if buf.Len() > 0 {
+		_, err := s.conn.Write(buf.Bytes())
+		return err
+	}
+	return nil
+}
+
+func (s *syslogSink) close() error {
+	return s.conn.Close()
+}
+
+func syslogSeverity(level zapcore.Level) int {
+	switch {
+	case level >= zapcore.DPanicLevel:
+		return 2 // critical
+	case level == zapcore.ErrorLevel:
+		return 3
+	case level == zapcore.WarnLevel:
+		return 4
+	case level == zapcore.InfoLevel:
+		return 6
+	}
+	return 7 // debug
+}
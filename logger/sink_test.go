@@ -0,0 +1,267 @@
+package logger
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// fakeSinkWriter records batches, failing while fail is set
+type fakeSinkWriter struct {
+	mu      sync.Mutex
+	fail    bool
+	dials   int
+	entries []string
+	block   chan struct{}
+}
+
+func (f *fakeSinkWriter) dial() (sinkWriter, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.dials++
+	if f.fail {
+		return nil, errors.New("connection refused")
+	}
+	return f, nil
+}
+
+func (f *fakeSinkWriter) write(entries []sinkEntry) error {
+	if f.block != nil {
+		<-f.block
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, entry := range entries {
+		f.entries = append(f.entries, string(entry.data))
+	}
+	return nil
+}
+
+func (f *fakeSinkWriter) close() error {
+	return nil
+}
+
+func (f *fakeSinkWriter) setFail(fail bool) {
+	f.mu.Lock()
+	f.fail = fail
+	f.mu.Unlock()
+}
+
+func (f *fakeSinkWriter) written() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.entries...)
+}
+
+func newTestSink(dial sinkDialer, buffer int) *netSink {
+	s := &netSink{
+		name:  "fake://sink",
+		dial:  dial,
+		queue: make(chan sinkEntry, buffer),
+		stop:  make(chan struct{}),
+		done:  make(chan struct{}),
+	}
+	go s.run()
+	return s
+}
+
+func TestNetSinkBuffersUntilBackendRecovers(t *testing.T) {
+	backend := &fakeSinkWriter{fail: true}
+	sink := newTestSink(backend.dial, 10)
+
+	for i := 0; i < 3; i++ {
+		sink.enqueue(sinkEntry{data: []byte(strconv.Itoa(i))})
+	}
+	if err := sink.flush(50 * time.Millisecond); err == nil {
+		t.Error("flush() of an unavailable sink error = nil")
+	}
+
+	backend.setFail(false)
+	if err := sink.close(2 * time.Second); err != nil {
+		t.Fatalf("close() error = %v", err)
+	}
+	if got := strings.Join(backend.written(), ","); got != "0,1,2" {
+		t.Errorf("written = %s, want buffered entries in order", got)
+	}
+	if backend.dials < 2 {
+		t.Errorf("dials = %d, want a reconnect", backend.dials)
+	}
+}
+
+func TestNetSinkDropsOverBuffer(t *testing.T) {
+	backend := &fakeSinkWriter{block: make(chan struct{})}
+	sink := newTestSink(backend.dial, 2)
+
+	// The first entry is taken by the blocked writer, two more fill the queue
+	sink.enqueue(sinkEntry{data: []byte("0")})
+	time.Sleep(20 * time.Millisecond)
+	for i := 1; i < 6; i++ {
+		sink.enqueue(sinkEntry{data: []byte(strconv.Itoa(i))})
+	}
+	if dropped := sink.dropped.Load(); dropped != 3 {
+		t.Errorf("dropped = %d, want 3", dropped)
+	}
+
+	close(backend.block)
+	if err := sink.close(time.Second); err != nil {
+		t.Fatalf("close() error = %v", err)
+	}
+	if got := strings.Join(backend.written(), ","); got != "0,1,2" {
+		t.Errorf("written = %s", got)
+	}
+}
+
+func TestOpenSinkValidatesURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"kafka:///logs", "host is required"},
+		{"http://collector:8080", `unsupported scheme "http"`},
+		{"syslog://localhost:514?buffer=0", "buffer must be a positive number"},
+		{"fluentd://localhost:24224?buffer=many", "buffer must be a positive number"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			if _, err := openSink(tt.url); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("openSink() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestSyslogTCPSink(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	received := make(chan []string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		var messages []string
+		for len(messages) < 2 {
+			// Octet counting: "<length> <message>"
+			prefix, err := r.ReadString(' ')
+			if err != nil {
+				break
+			}
+			n, _ := strconv.Atoi(strings.TrimSpace(prefix))
+			msg := make([]byte, n)
+			if _, err := io.ReadFull(r, msg); err != nil {
+				break
+			}
+			messages = append(messages, string(msg))
+		}
+		received <- messages
+	}()
+
+	log, err := New(Config{Level: "debug", OutputPaths: []string{fmt.Sprintf("syslog+tcp://%s?tag=orders", ln.Addr())}})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	log.Info("started", String("port", "8080"))
+	log.Error("failed")
+	if err := log.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	var messages []string
+	select {
+	case messages = <-received:
+	case <-time.After(2 * time.Second):
+		t.Fatal("syslog messages not received")
+	}
+	if len(messages) != 2 {
+		t.Fatalf("got %d messages, want 2", len(messages))
+	}
+	// local0: facility 16, info is 6 and error is 3
+	if !strings.HasPrefix(messages[0], "<134>1 ") || !strings.HasPrefix(messages[1], "<131>1 ") {
+		t.Errorf("priorities of %q", messages)
+	}
+	if !strings.Contains(messages[0], " orders ") || !strings.Contains(messages[0], `"msg":"started"`) || !strings.Contains(messages[0], `"port":"8080"`) {
+		t.Errorf("message = %q", messages[0])
+	}
+}
+
+func TestSyslogSeverity(t *testing.T) {
+	for level, want := range map[zapcore.Level]int{
+		zapcore.DebugLevel: 7, zapcore.InfoLevel: 6, zapcore.WarnLevel: 4,
+		zapcore.ErrorLevel: 3, zapcore.PanicLevel: 2, zapcore.FatalLevel: 2,
+	} {
+		if got := syslogSeverity(level); got != want {
+			t.Errorf("syslogSeverity(%s) = %d, want %d", level, got, want)
+		}
+	}
+}
+
+func TestFluentdForwardMessage(t *testing.T) {
+	client, server := net.Pipe()
+	defer server.Close()
+	sink := &fluentdSink{conn: client, tag: "app"}
+
+	written := make(chan []byte, 1)
+	go func() {
+		buf := make([]byte, 1024)
+		n, _ := server.Read(buf)
+		written <- buf[:n]
+	}()
+
+	entry := sinkEntry{time: time.Unix(1700000000, 0), data: []byte(`{"msg":"hi","n":1}` + "\n")}
+	if err := sink.write([]sinkEntry{entry}); err != nil {
+		t.Fatalf("write() error = %v", err)
+	}
+
+	var want bytes.Buffer
+	want.Write([]byte{0x92, 0xa3, 'a', 'p', 'p', 0x91, 0x92})
+	packValue(&want, int64(1700000000))
+	var record map[string]interface{}
+	dec := json.NewDecoder(bytes.NewReader(entry.data))
+	dec.UseNumber()
+	_ = dec.Decode(&record)
+	packValue(&want, record)
+
+	got := <-written
+	// Map order is random, compare the fixed header and the total size
+	if !bytes.HasPrefix(got, want.Bytes()[:16]) || len(got) != want.Len() {
+		t.Errorf("message = %x, want %x", got, want.Bytes())
+	}
+}
+
+func TestPackValue(t *testing.T) {
+	tests := []struct {
+		value interface{}
+		want  []byte
+	}{
+		{nil, []byte{0xc0}},
+		{true, []byte{0xc3}},
+		{"ab", []byte{0xa2, 'a', 'b'}},
+		{json.Number("7"), []byte{0xd3, 0, 0, 0, 0, 0, 0, 0, 7}},
+		{[]interface{}{false}, []byte{0x91, 0xc2}},
+		{strings.Repeat("x", 40), append([]byte{0xd9, 40}, strings.Repeat("x", 40)...)},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		packValue(&buf, tt.value)
+		if !bytes.Equal(buf.Bytes(), tt.want) {
+			t.Errorf("packValue(%v) = %x, want %x", tt.value, buf.Bytes(), tt.want)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Ctx returns logger correlated with the span in ctx: entries carry trace_id
+// and span_id, and entries at SpanEventLevel or above are also added to the
+// span as "log" events, so traces show the relevant errors
+func (l *Logger) Ctx(ctx context.Context) *Logger {
+	span := trace.SpanFromContext(ctx)
+	sc := span.SpanContext()
+	if !sc.IsValid() {
+		return l
+	}
+
+	fields := []zap.Field{
+		zap.String("trace_id", sc.TraceID().String()),
+		zap.String("span_id", sc.SpanID().String()),
+	}
+	if !span.IsRecording() {
+		return l.derive(l.With(fields...))
+	}
+
+	level := l.spanLevel
+	bridged := l.WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return zapcore.NewTee(core, &spanCore{span: span, level: level})
+	}))
+	return l.derive(bridged.With(fields...))
+}
+
+// spanCore writes entries as span events
+type spanCore struct {
+	span   trace.Span
+	level  zapcore.Level
+	fields []zapcore.Field
+}
+
+func (c *spanCore) Enabled(level zapcore.Level) bool {
+	return level >= c.level
+}
+
+func (c *spanCore) With(fields []zapcore.Field) zapcore.Core {
+	return &spanCore{
+		span:   c.span,
+		level:  c.level,
+		fields: append(append([]zapcore.Field{}, c.fields...), fields...),
+	}
+}
+
+func (c *spanCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+func (c *spanCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	enc := zapcore.NewMapObjectEncoder()
+	for _, field := range c.fields {
+		field.AddTo(enc)
+	}
+	for _, field := range fields {
+		field.AddTo(enc)
+	}
+
+	attrs := make([]attribute.KeyValue, 0, len(enc.Fields)+2)
+	attrs = append(attrs,
+		attribute.String("log.severity", entry.Level.String()),
+		attribute.String("log.message", entry.Message),
+	)
+	for key, value := range enc.Fields {
+		// Correlation fields duplicate the span itself
+		if key == "trace_id" || key == "span_id" {
+			continue
+		}
+		attrs = append(attrs, spanAttribute(key, value))
+	}
+
+	c.span.AddEvent("log", trace.WithAttributes(attrs...))
+	if entry.Level >= zapcore.ErrorLevel {
+		c.span.SetStatus(codes.Error, entry.Message)
+	}
+	return nil
+}
+
+func (c *spanCore) Sync() error {
+	return nil
+}
+
+func spanAttribute(key string, value interface{}) attribute.KeyValue {
+	switch v := value.(type) {
+	case string:
+		return attribute.String(key, v)
+	case bool:
+		return attribute.Bool(key, v)
+	case int64:
+		return attribute.Int64(key, v)
+	case int:
+		return attribute.Int(key, v)
+	case float64:
+		return attribute.Float64(key, v)
+	}
+	return attribute.String(key, fmt.Sprint(value))
+}
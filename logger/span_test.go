@@ -0,0 +1,93 @@
+package logger
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestCtxAddsEventsToRecordingSpan(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	t.Cleanup(func() { _ = tp.Shutdown(context.Background()) })
+
+	log, path := newFileLogger(t)
+	ctx, span := tp.Tracer("test").Start(context.Background(), "op")
+	traced := log.Ctx(ctx).WithFields(String("order_id", "42"))
+
+	traced.Info("below span level")
+	traced.Warn("slow query", Int("ms", 1500))
+	traced.Error("payment failed")
+	span.End()
+	_ = log.Sync()
+
+	ended := recorder.Ended()[0]
+	events := ended.Events()
+	if len(events) != 2 {
+		t.Fatalf("got %d events, want warn and error", len(events))
+	}
+	attrs := map[string]string{}
+	for _, attr := range events[0].Attributes {
+		attrs[string(attr.Key)] = attr.Value.Emit()
+	}
+	if attrs["log.message"] != "slow query" || attrs["log.severity"] != "warn" || attrs["ms"] != "1500" || attrs["order_id"] != "42" {
+		t.Errorf("event attributes = %v", attrs)
+	}
+	if _, ok := attrs["trace_id"]; ok {
+		t.Error("correlation fields added to the span event")
+	}
+	if ended.Status().Code != codes.Error || ended.Status().Description != "payment failed" {
+		t.Errorf("status = %+v, want the error entry", ended.Status())
+	}
+
+	data, _ := os.ReadFile(path)
+	output := string(data)
+	if !strings.Contains(output, `"trace_id":"`+ended.SpanContext().TraceID().String()+`"`) ||
+		!strings.Contains(output, `"span_id":"`+ended.SpanContext().SpanID().String()+`"`) {
+		t.Errorf("entries are not correlated with the span:\n%s", output)
+	}
+}
+
+func TestCtxSpanEventLevel(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	t.Cleanup(func() { _ = tp.Shutdown(context.Background()) })
+
+	log, err := New(Config{OutputPath: t.TempDir() + "/app.log", SpanEventLevel: "error"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, span := tp.Tracer("test").Start(context.Background(), "op")
+	log.Ctx(ctx).Warn("not added")
+	span.End()
+
+	if events := recorder.Ended()[0].Events(); len(events) != 0 {
+		t.Errorf("got %d events below SpanEventLevel", len(events))
+	}
+}
+
+func TestCtxWithoutRecordingSpan(t *testing.T) {
+	log, path := newFileLogger(t)
+
+	if log.Ctx(context.Background()) != log {
+		t.Error("Ctx() without a span must return the logger itself")
+	}
+
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: trace.TraceID{1},
+		SpanID:  trace.SpanID{2},
+	})
+	log.Ctx(trace.ContextWithRemoteSpanContext(context.Background(), sc)).Error("remote parent")
+	_ = log.Sync()
+
+	data, _ := os.ReadFile(path)
+	if !strings.Contains(string(data), `"trace_id":"`+sc.TraceID().String()+`"`) {
+		t.Errorf("entry of a non-recording span has no trace_id:\n%s", data)
+	}
+}
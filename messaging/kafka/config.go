@@ -0,0 +1,148 @@
+package kafka
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/alimzhanovlr/sdk/config"
+	"github.com/segmentio/kafka-go/sasl"
+	"github.com/segmentio/kafka-go/sasl/plain"
+	"github.com/segmentio/kafka-go/sasl/scram"
+)
+
+// Config holds Kafka configuration
+type Config struct {
+	Brokers  []string
+	ClientID string
+	GroupID  string
+
+	TLS  TLSConfig
+	SASL SASLConfig
+
+	// Producer settings
+	RequiredAcks int // -1 all, 0 none, 1 leader
+	BatchTimeout time.Duration
+
+	// Consumer settings
+	MinBytes        int
+	MaxBytes        int
+	StartFromOldest bool
+	ShutdownTimeout time.Duration
+	// RetryBackoff is the first pause before a failed message is handled
+	// again or a failed fetch is repeated, doubling up to MaxRetryBackoff.
+	// Defaults are 1s and 30s
+	RetryBackoff    time.Duration
+	MaxRetryBackoff time.Duration
+}
+
+// TLSConfig holds TLS configuration
+type TLSConfig struct {
+	Enabled            bool
+	CAFile             string
+	CertFile           string
+	KeyFile            string
+	InsecureSkipVerify bool
+}
+
+// SASLConfig holds SASL configuration
+type SASLConfig struct {
+	Mechanism string // plain, scram-sha-256, scram-sha-512
+	Username  string
+	Password  string
+}
+
+// DefaultConfig returns default Kafka config
+func DefaultConfig() Config {
+	return Config{
+		Brokers:         []string{"localhost:9092"},
+		ClientID:        "microservice",
+		RequiredAcks:    -1,
+		BatchTimeout:    10 * time.Millisecond,
+		MinBytes:        1,
+		MaxBytes:        10e6,
+		StartFromOldest: true,
+		ShutdownTimeout: 30 * time.Second,
+		RetryBackoff:    time.Second,
+		MaxRetryBackoff: 30 * time.Second,
+	}
+}
+
+// FromConfig converts the kafka section of service config
+func FromConfig(c config.KafkaConfig) Config {
+	return Config{
+		Brokers:  c.Brokers,
+		ClientID: c.ClientID,
+		GroupID:  c.GroupID,
+		TLS: TLSConfig{
+			Enabled:            c.TLS.Enabled,
+			CAFile:             c.TLS.CAFile,
+			CertFile:           c.TLS.CertFile,
+			KeyFile:            c.TLS.KeyFile,
+			InsecureSkipVerify: c.TLS.InsecureSkipVerify,
+		},
+		SASL: SASLConfig{
+			Mechanism: c.SASL.Mechanism,
+			Username:  c.SASL.Username,
+			Password:  c.SASL.Password,
+		},
+		RequiredAcks:    c.RequiredAcks,
+		BatchTimeout:    c.BatchTimeout,
+		MinBytes:        c.MinBytes,
+		MaxBytes:        c.MaxBytes,
+		StartFromOldest: c.StartFromOldest,
+		ShutdownTimeout: c.ShutdownTimeout,
+	}
+}
+
+// tlsConfig builds tls.Config from TLSConfig
+func (c TLSConfig) tlsConfig() (*tls.Config, error) {
+	if !c.Enabled {
+		return nil, nil
+	}
+
+	cfg := &tls.Config{
+		InsecureSkipVerify: c.InsecureSkipVerify,
+	}
+
+	if c.CAFile != "" {
+		ca, err := os.ReadFile(c.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("failed to parse CA file %s", c.CAFile)
+		}
+		cfg.RootCAs = pool
+	}
+
+	if c.CertFile != "" && c.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	return cfg, nil
+}
+
+// mechanism builds SASL mechanism from SASLConfig
+func (c SASLConfig) mechanism() (sasl.Mechanism, error) {
+	switch strings.ToLower(c.Mechanism) {
+	case "":
+		return nil, nil
+	case "plain":
+		return plain.Mechanism{Username: c.Username, Password: c.Password}, nil
+	case "scram-sha-256":
+		return scram.Mechanism(scram.SHA256, c.Username, c.Password)
+	case "scram-sha-512":
+		return scram.Mechanism(scram.SHA512, c.Username, c.Password)
+	default:
+		return nil, fmt.Errorf("unsupported sasl mechanism: %s", c.Mechanism)
+	}
+}
@@ -0,0 +1,296 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/alimzhanovlr/sdk/logger"
+	"github.com/alimzhanovlr/sdk/messaging"
+	"github.com/alimzhanovlr/sdk/retry"
+	"github.com/segmentio/kafka-go"
+	"go.uber.org/fx"
+)
+
+// Consumer consumes messages from Kafka within a consumer group
+type Consumer struct {
+	config      Config
+	dialer      *kafka.Dialer
+	logger      *logger.Logger
+	middlewares []messaging.Middleware
+	backoff     retry.Backoff
+
+	mu      sync.Mutex
+	readers map[string]*kafka.Reader
+	routes  map[string]messaging.Handler
+	cancel  context.CancelFunc
+	running bool
+	closed  bool
+	wg      sync.WaitGroup
+}
+
+var _ messaging.Subscriber = (*Consumer)(nil)
+
+// NewConsumer creates a new Kafka consumer
+func NewConsumer(cfg Config, log *logger.Logger, middlewares ...messaging.Middleware) (*Consumer, error) {
+	if len(cfg.Brokers) == 0 {
+		return nil, fmt.Errorf("kafka brokers are not configured")
+	}
+	if cfg.GroupID == "" {
+		return nil, fmt.Errorf("kafka consumer group id is not configured")
+	}
+
+	tlsCfg, err := cfg.TLS.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	mechanism, err := cfg.SASL.mechanism()
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.RetryBackoff <= 0 {
+		cfg.RetryBackoff = time.Second
+	}
+	if cfg.MaxRetryBackoff <= 0 {
+		cfg.MaxRetryBackoff = 30 * time.Second
+	}
+
+	return &Consumer{
+		config:  cfg,
+		backoff: retry.Jitter(retry.Exponential(cfg.RetryBackoff, cfg.MaxRetryBackoff)),
+		dialer: &kafka.Dialer{
+			ClientID:      cfg.ClientID,
+			Timeout:       10 * time.Second,
+			DualStack:     true,
+			TLS:           tlsCfg,
+			SASLMechanism: mechanism,
+		},
+		logger:      log,
+		middlewares: middlewares,
+		readers:     make(map[string]*kafka.Reader),
+		routes:      make(map[string]messaging.Handler),
+	}, nil
+}
+
+// Use appends middlewares applied to every subscription
+func (c *Consumer) Use(middlewares ...messaging.Middleware) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.middlewares = append(c.middlewares, middlewares...)
+}
+
+// Subscribe registers handler for topic
+func (c *Consumer) Subscribe(topic string, handler messaging.Handler) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, exists := c.routes[topic]; exists {
+		return fmt.Errorf("handler for topic %s already registered", topic)
+	}
+
+	c.routes[topic] = handler
+	return nil
+}
+
+// Run starts consuming all subscribed topics and blocks until ctx is cancelled.
+// A consumer runs once, another call returns an error
+func (c *Consumer) Run(ctx context.Context) error {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return fmt.Errorf("kafka consumer is closed")
+	}
+	if c.running {
+		c.mu.Unlock()
+		return fmt.Errorf("kafka consumer is already running")
+	}
+	c.running = true
+	ctx, c.cancel = context.WithCancel(ctx)
+
+	startOffset := kafka.LastOffset
+	if c.config.StartFromOldest {
+		startOffset = kafka.FirstOffset
+	}
+
+	for topic, handler := range c.routes {
+		reader := kafka.NewReader(kafka.ReaderConfig{
+			Brokers:     c.config.Brokers,
+			GroupID:     c.config.GroupID,
+			Topic:       topic,
+			Dialer:      c.dialer,
+			MinBytes:    c.config.MinBytes,
+			MaxBytes:    c.config.MaxBytes,
+			StartOffset: startOffset,
+		})
+		c.readers[topic] = reader
+
+		h := messaging.Chain(handler, c.middlewares...)
+
+		c.wg.Add(1)
+		go func(topic string, reader *kafka.Reader, h messaging.Handler) {
+			defer c.wg.Done()
+			c.consume(ctx, topic, reader, h)
+		}(topic, reader, h)
+	}
+	c.mu.Unlock()
+
+	<-ctx.Done()
+	return nil
+}
+
+// consume fetches, handles and commits messages one by one. Commits are
+// cumulative per partition, so a failed message is retried in place until it
+// succeeds (or DLQMiddleware stores it) instead of being skipped
+func (c *Consumer) consume(ctx context.Context, topic string, reader *kafka.Reader, h messaging.Handler) {
+	fetchFailures := 0
+	for {
+		kmsg, err := reader.FetchMessage(ctx)
+		if err != nil {
+			if ctx.Err() != nil || errors.Is(err, io.EOF) {
+				return
+			}
+			fetchFailures++
+			c.logger.Error("Failed to fetch message",
+				logger.String("topic", topic),
+				logger.Int("attempt", fetchFailures),
+				logger.Error(err),
+			)
+			if !sleep(ctx, c.backoff.Delay(fetchFailures)) {
+				return
+			}
+			continue
+		}
+		fetchFailures = 0
+
+		msg := &messaging.Message{
+			Topic:     kmsg.Topic,
+			Key:       kmsg.Key,
+			Value:     kmsg.Value,
+			Partition: kmsg.Partition,
+			Offset:    kmsg.Offset,
+			Timestamp: kmsg.Time,
+			Headers:   make(map[string]string, len(kmsg.Headers)),
+		}
+		for _, header := range kmsg.Headers {
+			msg.Headers[header.Key] = string(header.Value)
+		}
+
+		if !c.handle(ctx, topic, h, msg) {
+			// Stopped before the message succeeded: not committed, it is
+			// redelivered after rebalance or restart
+			return
+		}
+
+		if err := reader.CommitMessages(context.WithoutCancel(ctx), kmsg); err != nil {
+			c.logger.Error("Failed to commit message",
+				logger.String("topic", topic),
+				logger.Error(err),
+			)
+		}
+	}
+}
+
+// handle runs h until it succeeds, pausing with backoff between attempts.
+// Returns false when ctx is cancelled first
+func (c *Consumer) handle(ctx context.Context, topic string, h messaging.Handler, msg *messaging.Message) bool {
+	for attempt := 1; ; attempt++ {
+		// Handler runs with a detached context so that an in-flight message
+		// completes during shutdown instead of being aborted mid-way
+		err := h.Handle(context.WithoutCancel(ctx), msg)
+		if err == nil {
+			return true
+		}
+
+		c.logger.Error("Message handler failed",
+			logger.String("topic", topic),
+			logger.Int("partition", msg.Partition),
+			logger.Any("offset", msg.Offset),
+			logger.Int("attempt", attempt),
+			logger.Error(err),
+		)
+		if !sleep(ctx, c.backoff.Delay(attempt)) {
+			return false
+		}
+	}
+}
+
+// sleep pauses for d, false when ctx is cancelled first
+func sleep(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// Close stops consuming, waits for in-flight handlers and leaves the group
+func (c *Consumer) Close() error {
+	c.mu.Lock()
+	c.closed = true
+	if c.cancel != nil {
+		c.cancel()
+	}
+	c.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		c.wg.Wait()
+		close(done)
+	}()
+
+	timeout := c.config.ShutdownTimeout
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+
+	select {
+	case <-done:
+	case <-time.After(timeout):
+		c.logger.Error("Timed out waiting for in-flight messages")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var errs []error
+	for topic, reader := range c.readers {
+		if err := reader.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to close reader for %s: %w", topic, err))
+		}
+	}
+	c.readers = make(map[string]*kafka.Reader)
+
+	return errors.Join(errs...)
+}
+
+// Start registers consumer in fx lifecycle
+func (c *Consumer) Start(lc fx.Lifecycle) {
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			c.logger.Info("Starting kafka consumer",
+				logger.String("group_id", c.config.GroupID),
+			)
+
+			go func() {
+				if err := c.Run(context.Background()); err != nil {
+					c.logger.Error("Kafka consumer stopped", logger.Error(err))
+				}
+			}()
+
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			c.logger.Info("Shutting down kafka consumer")
+			return c.Close()
+		},
+	})
+}
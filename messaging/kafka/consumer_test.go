@@ -0,0 +1,155 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alimzhanovlr/sdk/breaker"
+	"github.com/alimzhanovlr/sdk/logger"
+	"github.com/alimzhanovlr/sdk/messaging"
+	"github.com/alimzhanovlr/sdk/retry"
+	"go.uber.org/zap"
+)
+
+func testConsumer() *Consumer {
+	return &Consumer{
+		logger:  &logger.Logger{Logger: zap.NewNop()},
+		backoff: retry.Constant(time.Millisecond),
+	}
+}
+
+func TestConsumerHandleRetriesInPlace(t *testing.T) {
+	attempts := 0
+	h := messaging.HandlerFunc(func(ctx context.Context, msg *messaging.Message) error {
+		attempts++
+		if attempts < 3 {
+			return errors.New("temporary")
+		}
+		return nil
+	})
+
+	if !testConsumer().handle(context.Background(), "orders", h, &messaging.Message{}) {
+		t.Fatal("handle should succeed after retries")
+	}
+	if attempts != 3 {
+		t.Errorf("attempts = %d, want 3", attempts)
+	}
+}
+
+func TestConsumerHandleStopsOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	attempts := 0
+	h := messaging.HandlerFunc(func(ctx context.Context, msg *messaging.Message) error {
+		attempts++
+		if attempts == 2 {
+			cancel()
+		}
+		return errors.New("down")
+	})
+
+	if testConsumer().handle(ctx, "orders", h, &messaging.Message{}) {
+		t.Fatal("handle must not report success for a failed message")
+	}
+	if attempts != 2 {
+		t.Errorf("attempts = %d, want 2", attempts)
+	}
+}
+
+func TestConsumerHandleWaitsForOpenBreaker(t *testing.T) {
+	cb := breaker.NewCircuitBreaker(breaker.Settings{
+		Name:    "orders",
+		Timeout: 20 * time.Millisecond,
+		ReadyToTrip: func(counts breaker.Counts) bool {
+			return counts.ConsecutiveFailures >= 1
+		},
+	})
+	calls := 0
+	h := messaging.Chain(messaging.HandlerFunc(func(ctx context.Context, msg *messaging.Message) error {
+		calls++
+		if calls == 1 {
+			return errors.New("dependency down")
+		}
+		return nil
+	}), messaging.BreakerMiddleware(cb))
+
+	start := time.Now()
+	if !testConsumer().handle(context.Background(), "orders", h, &messaging.Message{}) {
+		t.Fatal("handle should succeed once the breaker lets the message through")
+	}
+	if calls != 2 {
+		t.Errorf("calls = %d, want 2: rejected attempts must not reach the handler", calls)
+	}
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+		t.Errorf("handled after %s, want a pause until the breaker timeout", elapsed)
+	}
+}
+
+func TestConsumerHandleKeepsPausedMessageOnCancel(t *testing.T) {
+	cb := breaker.NewCircuitBreaker(breaker.Settings{
+		Name:    "orders",
+		Timeout: time.Minute,
+		ReadyToTrip: func(counts breaker.Counts) bool {
+			return counts.ConsecutiveFailures >= 1
+		},
+	})
+	cb.Execute(func() (interface{}, error) { return nil, errors.New("dependency down") })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	rejected := 0
+	h := messaging.Chain(messaging.HandlerFunc(func(ctx context.Context, msg *messaging.Message) error {
+		t.Fatal("handler must not run while the breaker is open")
+		return nil
+	}), func(next messaging.Handler) messaging.Handler {
+		return messaging.HandlerFunc(func(ctx context.Context, msg *messaging.Message) error {
+			err := next.Handle(ctx, msg)
+			if messaging.Paused(err) {
+				if rejected++; rejected == 3 {
+					cancel()
+				}
+			}
+			return err
+		})
+	}, messaging.BreakerMiddleware(cb))
+
+	if testConsumer().handle(ctx, "orders", h, &messaging.Message{}) {
+		t.Fatal("a paused message must stay uncommitted")
+	}
+	if rejected != 3 {
+		t.Errorf("rejected = %d, want 3", rejected)
+	}
+}
+
+func TestConsumerRunsOnce(t *testing.T) {
+	c := testConsumer()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- c.Run(ctx) }()
+
+	// Wait until the first Run holds the consumer
+	for {
+		c.mu.Lock()
+		running := c.running
+		c.mu.Unlock()
+		if running {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	if err := c.Run(context.Background()); err == nil {
+		t.Error("second Run returned nil, want an error while running")
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("first Run: %v", err)
+	}
+	if err := c.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Run(context.Background()); err == nil {
+		t.Error("Run after Close returned nil, want an error")
+	}
+}
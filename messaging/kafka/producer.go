@@ -0,0 +1,92 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/alimzhanovlr/sdk/messaging"
+	"github.com/segmentio/kafka-go"
+	"go.uber.org/fx"
+)
+
+// Producer publishes messages to Kafka
+type Producer struct {
+	writer *kafka.Writer
+}
+
+var _ messaging.Publisher = (*Producer)(nil)
+
+// NewProducer creates a new Kafka producer
+func NewProducer(cfg Config) (*Producer, error) {
+	if len(cfg.Brokers) == 0 {
+		return nil, fmt.Errorf("kafka brokers are not configured")
+	}
+
+	tlsCfg, err := cfg.TLS.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	mechanism, err := cfg.SASL.mechanism()
+	if err != nil {
+		return nil, err
+	}
+
+	writer := &kafka.Writer{
+		Addr:         kafka.TCP(cfg.Brokers...),
+		Balancer:     &kafka.Hash{},
+		RequiredAcks: kafka.RequiredAcks(cfg.RequiredAcks),
+		BatchTimeout: cfg.BatchTimeout,
+		Transport: &kafka.Transport{
+			ClientID: cfg.ClientID,
+			TLS:      tlsCfg,
+			SASL:     mechanism,
+		},
+	}
+
+	return &Producer{writer: writer}, nil
+}
+
+// Publish publishes messages to topic
+func (p *Producer) Publish(ctx context.Context, topic string, msgs ...*messaging.Message) error {
+	kmsgs := make([]kafka.Message, 0, len(msgs))
+	for _, msg := range msgs {
+		messaging.InjectTraceContext(ctx, msg)
+
+		kmsg := kafka.Message{
+			Topic: topic,
+			Key:   msg.Key,
+			Value: msg.Value,
+			Time:  msg.Timestamp,
+		}
+		if kmsg.Time.IsZero() {
+			kmsg.Time = time.Now()
+		}
+		for k, v := range msg.Headers {
+			kmsg.Headers = append(kmsg.Headers, kafka.Header{Key: k, Value: []byte(v)})
+		}
+
+		kmsgs = append(kmsgs, kmsg)
+	}
+
+	if err := p.writer.WriteMessages(ctx, kmsgs...); err != nil {
+		return fmt.Errorf("failed to publish to %s: %w", topic, err)
+	}
+
+	return nil
+}
+
+// Close flushes pending messages and closes the producer
+func (p *Producer) Close() error {
+	return p.writer.Close()
+}
+
+// Start registers producer shutdown in fx lifecycle
+func (p *Producer) Start(lc fx.Lifecycle) {
+	lc.Append(fx.Hook{
+		OnStop: func(ctx context.Context) error {
+			return p.Close()
+		},
+	})
+}
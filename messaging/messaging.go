@@ -0,0 +1,79 @@
+package messaging
+
+import (
+	"context"
+	"time"
+)
+
+// Message represents a broker-agnostic message
+type Message struct {
+	Topic     string
+	Key       []byte
+	Value     []byte
+	Headers   map[string]string
+	Partition int
+	Offset    int64
+	Timestamp time.Time
+}
+
+// Header returns header value or empty string
+func (m *Message) Header(key string) string {
+	if m.Headers == nil {
+		return ""
+	}
+	return m.Headers[key]
+}
+
+// SetHeader sets header value
+func (m *Message) SetHeader(key, value string) {
+	if m.Headers == nil {
+		m.Headers = make(map[string]string)
+	}
+	m.Headers[key] = value
+}
+
+// Handler processes a single message
+type Handler interface {
+	Handle(ctx context.Context, msg *Message) error
+}
+
+// HandlerFunc adapts a function to Handler
+type HandlerFunc func(ctx context.Context, msg *Message) error
+
+// Handle implements Handler
+func (f HandlerFunc) Handle(ctx context.Context, msg *Message) error {
+	return f(ctx, msg)
+}
+
+// Middleware wraps a handler
+type Middleware func(Handler) Handler
+
+// Chain applies middlewares to handler, first middleware is the outermost
+func Chain(h Handler, middlewares ...Middleware) Handler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		h = middlewares[i](h)
+	}
+	return h
+}
+
+// Publisher publishes messages to a broker
+type Publisher interface {
+	Publish(ctx context.Context, topic string, msgs ...*Message) error
+	Close() error
+}
+
+// Subscriber delivers messages from a broker to handlers
+type Subscriber interface {
+	Subscribe(topic string, handler Handler) error
+	Run(ctx context.Context) error
+	Close() error
+}
+
+// Common header keys
+const (
+	HeaderContentType   = "content-type"
+	HeaderRetryAttempt  = "x-retry-attempt"
+	HeaderOriginalTopic = "x-original-topic"
+	HeaderError         = "x-error"
+	HeaderFailedAt      = "x-failed-at"
+)
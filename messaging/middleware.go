@@ -0,0 +1,276 @@
+package messaging
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/alimzhanovlr/sdk/breaker"
+	"github.com/alimzhanovlr/sdk/errors"
+	"github.com/alimzhanovlr/sdk/httpclient"
+	"github.com/alimzhanovlr/sdk/logger"
+	"github.com/alimzhanovlr/sdk/metrics"
+	"github.com/alimzhanovlr/sdk/retry"
+	"github.com/alimzhanovlr/sdk/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+var propagator = propagation.TraceContext{}
+
+// LoggingMiddleware logs handled messages with sanitized payload
+func LoggingMiddleware(log *logger.Logger, sanitizerConfig *httpclient.SanitizerConfig) Middleware {
+	sanitizer := httpclient.NewSanitizer(sanitizerConfig)
+
+	return func(next Handler) Handler {
+		return HandlerFunc(func(ctx context.Context, msg *Message) error {
+			start := time.Now()
+
+			err := next.Handle(ctx, msg)
+
+			contentType := msg.Header(HeaderContentType)
+			if contentType == "" {
+				contentType = "application/json"
+			}
+
+			fields := []zap.Field{
+				zap.String("topic", msg.Topic),
+				zap.ByteString("key", msg.Key),
+				zap.Int("partition", msg.Partition),
+				zap.Int64("offset", msg.Offset),
+				zap.Duration("duration", time.Since(start)),
+				zap.String("payload", sanitizer.SanitizeBody(msg.Value, contentType)),
+			}
+
+			if traceID := tracing.GetTraceID(ctx); traceID != "" {
+				fields = append(fields, zap.String("trace_id", traceID))
+			}
+
+			if err != nil {
+				fields = append(fields, zap.Error(err))
+				log.Error("Message handling failed", fields...)
+			} else {
+				log.Debug("Message handled", fields...)
+			}
+
+			return err
+		})
+	}
+}
+
+// MetricsMiddleware records handled message count and latency per topic
+func MetricsMiddleware(reg *metrics.Registry) Middleware {
+	handled := reg.Counter("messaging_messages_handled_total", "Total number of handled messages")
+	duration := reg.Histogram("messaging_handle_duration_seconds", "Message handling duration", metrics.DefaultDurationBuckets...)
+
+	return func(next Handler) Handler {
+		return HandlerFunc(func(ctx context.Context, msg *Message) error {
+			start := time.Now()
+
+			err := next.Handle(ctx, msg)
+
+			attrs := []attribute.KeyValue{
+				attribute.String("topic", msg.Topic),
+				attribute.Bool("error", err != nil),
+			}
+			handled.Add(ctx, 1, attrs...)
+			duration.Record(ctx, time.Since(start).Seconds(), attrs...)
+
+			return err
+		})
+	}
+}
+
+// TracingMiddleware starts a consumer span continuing the producer trace
+func TracingMiddleware(tracer *tracing.Tracer) Middleware {
+	return func(next Handler) Handler {
+		return HandlerFunc(func(ctx context.Context, msg *Message) error {
+			ctx = ExtractTraceContext(ctx, msg)
+
+			ctx, span := tracer.Start(ctx, "consume "+msg.Topic, trace.WithSpanKind(trace.SpanKindConsumer))
+			defer span.End()
+
+			span.SetAttributes(
+				attribute.String("messaging.destination.name", msg.Topic),
+				attribute.Int("messaging.kafka.partition", msg.Partition),
+				attribute.Int64("messaging.kafka.offset", msg.Offset),
+			)
+
+			err := next.Handle(ctx, msg)
+			if err != nil {
+				span.RecordError(err)
+			}
+
+			return err
+		})
+	}
+}
+
+// RootTracingMiddleware starts a new trace per message linked to the
+// producer trace, for consumers whose processing should not stretch the
+// producer trace, e.g. delayed or long running work
+func RootTracingMiddleware(tracer *tracing.Tracer) Middleware {
+	return func(next Handler) Handler {
+		return HandlerFunc(func(ctx context.Context, msg *Message) error {
+			ctx, span := tracer.NewRootContext(ctx, "consume/"+msg.Topic,
+				tracing.WithRootKind(trace.SpanKindConsumer),
+				tracing.WithLinkFromHeaders(msg.Headers),
+				tracing.WithRootAttributes(
+					attribute.String("messaging.destination.name", msg.Topic),
+					attribute.Int("messaging.kafka.partition", msg.Partition),
+					attribute.Int64("messaging.kafka.offset", msg.Offset),
+				),
+			)
+			defer span.End()
+
+			err := next.Handle(ctx, msg)
+			if err != nil {
+				span.RecordError(err)
+			}
+
+			return err
+		})
+	}
+}
+
+// InjectTraceContext writes trace context from ctx into message headers
+func InjectTraceContext(ctx context.Context, msg *Message) {
+	if msg.Headers == nil {
+		msg.Headers = make(map[string]string)
+	}
+	propagator.Inject(ctx, propagation.MapCarrier(msg.Headers))
+}
+
+// ExtractTraceContext reads trace context from message headers into ctx
+func ExtractTraceContext(ctx context.Context, msg *Message) context.Context {
+	if len(msg.Headers) == 0 {
+		return ctx
+	}
+	return propagator.Extract(ctx, propagation.MapCarrier(msg.Headers))
+}
+
+// RetryConfig holds retry middleware configuration
+type RetryConfig struct {
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+// DefaultRetryConfig returns default retry config
+func DefaultRetryConfig() RetryConfig {
+	return RetryConfig{
+		MaxAttempts:    3,
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     5 * time.Second,
+	}
+}
+
+// RetryMiddleware retries failed handlers with exponential backoff
+func RetryMiddleware(config RetryConfig) Middleware {
+	policy := retry.Policy{
+		MaxAttempts: config.MaxAttempts,
+		Backoff:     retry.Exponential(config.InitialBackoff, config.MaxBackoff),
+		// An open breaker will not close within the backoff, leave the
+		// message to the consumer
+		Retryable: func(err error) bool { return !Paused(err) },
+	}
+
+	return func(next Handler) Handler {
+		return HandlerFunc(func(ctx context.Context, msg *Message) error {
+			attempt := 0
+			return retry.Do(ctx, policy, func(ctx context.Context) error {
+				attempt++
+				msg.SetHeader(HeaderRetryAttempt, fmt.Sprintf("%d", attempt))
+				return next.Handle(ctx, msg)
+			})
+		})
+	}
+}
+
+// BreakerMiddleware fails handling fast while cb is open, so a consumer
+// stops hammering a failing dependency. Put it inside RetryMiddleware and
+// DLQMiddleware: rejected messages are neither retried nor dead-lettered,
+// consumers keep them uncommitted and redeliver them later, see Paused.
+// Handler errors with a 4xx AppError, e.g. validation, are the message's
+// fault and never count as failures, other errors are judged by the
+// breaker's IsSuccessful
+func BreakerMiddleware(cb *breaker.CircuitBreaker) Middleware {
+	return func(next Handler) Handler {
+		return HandlerFunc(func(ctx context.Context, msg *Message) error {
+			var handlerErr error
+			_, err := cb.Execute(func() (interface{}, error) {
+				err := next.Handle(ctx, msg)
+				if rejectedMessage(err) {
+					handlerErr = err
+					return nil, nil
+				}
+				return nil, err
+			})
+			if handlerErr != nil {
+				return handlerErr
+			}
+			return err
+		})
+	}
+}
+
+// Paused reports that a circuit breaker rejected the message, it must stay
+// uncommitted and be handled again once the breaker lets requests through
+func Paused(err error) bool {
+	return stderrors.Is(err, breaker.ErrOpenState) || stderrors.Is(err, breaker.ErrTooManyRequests)
+}
+
+// rejectedMessage reports errors caused by the message itself
+func rejectedMessage(err error) bool {
+	var appErr *errors.AppError
+	return stderrors.As(err, &appErr) && appErr.StatusCode >= http.StatusBadRequest && appErr.StatusCode < http.StatusInternalServerError
+}
+
+// DLQMiddleware publishes messages that failed handling to a dead letter topic.
+// The error is swallowed once the message is safely stored in the DLQ.
+func DLQMiddleware(publisher Publisher, topicSuffix string) Middleware {
+	if topicSuffix == "" {
+		topicSuffix = ".dlq"
+	}
+
+	return func(next Handler) Handler {
+		return HandlerFunc(func(ctx context.Context, msg *Message) error {
+			err := next.Handle(ctx, msg)
+			if err == nil || Paused(err) {
+				return err
+			}
+
+			dlqMsg := &Message{
+				Key:     msg.Key,
+				Value:   msg.Value,
+				Headers: make(map[string]string, len(msg.Headers)+3),
+			}
+			for k, v := range msg.Headers {
+				dlqMsg.Headers[k] = v
+			}
+			dlqMsg.Headers[HeaderOriginalTopic] = msg.Topic
+			dlqMsg.Headers[HeaderError] = err.Error()
+			dlqMsg.Headers[HeaderFailedAt] = time.Now().UTC().Format(time.RFC3339)
+
+			if pubErr := publisher.Publish(ctx, msg.Topic+topicSuffix, dlqMsg); pubErr != nil {
+				return fmt.Errorf("failed to publish to dlq: %w (original error: %v)", pubErr, err)
+			}
+
+			return nil
+		})
+	}
+}
+
+// RecoverMiddleware converts handler panics into errors, see errors.Recover
+func RecoverMiddleware() Middleware {
+	return func(next Handler) Handler {
+		return HandlerFunc(func(ctx context.Context, msg *Message) (err error) {
+			defer errors.Recover(&err)
+			return next.Handle(ctx, msg)
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package messaging
+
+import (
+	"context"
+	stderrors "errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/alimzhanovlr/sdk/breaker"
+	"github.com/alimzhanovlr/sdk/errors"
+)
+
+type recordingPublisher struct {
+	topics []string
+}
+
+func (p *recordingPublisher) Publish(_ context.Context, topic string, _ ...*Message) error {
+	p.topics = append(p.topics, topic)
+	return nil
+}
+
+func (p *recordingPublisher) Close() error { return nil }
+
+func newTestBreaker() *breaker.CircuitBreaker {
+	return breaker.NewCircuitBreaker(breaker.Settings{
+		Name:    "test",
+		Timeout: time.Minute,
+		ReadyToTrip: func(counts breaker.Counts) bool {
+			return counts.ConsecutiveFailures >= 2
+		},
+	})
+}
+
+func TestBreakerIgnoresRejectedMessages(t *testing.T) {
+	cb := newTestBreaker()
+	invalid := errors.New("validation_error", "invalid payload", http.StatusUnprocessableEntity)
+	h := Chain(HandlerFunc(func(context.Context, *Message) error {
+		return invalid
+	}), BreakerMiddleware(cb))
+
+	for i := 0; i < 5; i++ {
+		if err := h.Handle(context.Background(), &Message{}); err != invalid {
+			t.Fatalf("attempt %d: err = %v, want handler error", i, err)
+		}
+	}
+	if cb.State() != breaker.StateClosed {
+		t.Errorf("state = %s, want closed", cb.State())
+	}
+}
+
+func TestBreakerOpenPausesInsteadOfDeadLettering(t *testing.T) {
+	cb := newTestBreaker()
+	publisher := &recordingPublisher{}
+	calls := 0
+	h := Chain(HandlerFunc(func(context.Context, *Message) error {
+		calls++
+		return stderrors.New("dependency down")
+	}),
+		DLQMiddleware(publisher, ""),
+		RetryMiddleware(RetryConfig{MaxAttempts: 5, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond}),
+		BreakerMiddleware(cb),
+	)
+
+	err := h.Handle(context.Background(), &Message{Topic: "orders"})
+	if !Paused(err) {
+		t.Fatalf("err = %v, want breaker rejection", err)
+	}
+	if calls != 2 {
+		t.Errorf("calls = %d, want 2 before the breaker opened", calls)
+	}
+	if len(publisher.topics) != 0 {
+		t.Errorf("published to %v, want nothing", publisher.topics)
+	}
+}
+
+func TestDLQPublishesHandlerFailures(t *testing.T) {
+	publisher := &recordingPublisher{}
+	h := Chain(HandlerFunc(func(context.Context, *Message) error {
+		return stderrors.New("boom")
+	}), DLQMiddleware(publisher, ""))
+
+	if err := h.Handle(context.Background(), &Message{Topic: "orders"}); err != nil {
+		t.Fatalf("err = %v, want nil after dead-lettering", err)
+	}
+	if len(publisher.topics) != 1 || publisher.topics[0] != "orders.dlq" {
+		t.Errorf("published to %v", publisher.topics)
+	}
+}
+
+func TestRecoverConvertsPanicToAppError(t *testing.T) {
+	h := Chain(HandlerFunc(func(context.Context, *Message) error {
+		panic("boom")
+	}), RecoverMiddleware())
+
+	err := h.Handle(context.Background(), &Message{Topic: "orders"})
+	var appErr *errors.AppError
+	if !stderrors.As(err, &appErr) || appErr.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("err = %v, want internal AppError", err)
+	}
+	var panicErr *errors.PanicError
+	if !stderrors.As(err, &panicErr) || panicErr.Value != "boom" || len(panicErr.Stack) == 0 {
+		t.Errorf("err = %v, want PanicError with the value and stack", err)
+	}
+}
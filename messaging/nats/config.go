@@ -0,0 +1,100 @@
+package nats
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// Config holds NATS configuration
+type Config struct {
+	URL  string
+	Name string
+
+	// Credentials
+	Username        string
+	Password        string
+	Token           string
+	CredentialsFile string
+
+	// TLS
+	TLSCAFile   string
+	TLSCertFile string
+	TLSKeyFile  string
+
+	// Reconnect settings
+	MaxReconnects int // -1 reconnects forever
+	ReconnectWait time.Duration
+
+	// JetStream settings
+	JetStream  bool
+	Stream     string
+	Durable    string // durable consumer name prefix
+	AckPolicy  string // explicit, all, none
+	AckWait    time.Duration
+	MaxDeliver int
+
+	// Core NATS queue group used when JetStream is disabled
+	QueueGroup string
+
+	ShutdownTimeout time.Duration
+}
+
+// DefaultConfig returns default NATS config
+func DefaultConfig() Config {
+	return Config{
+		URL:             nats.DefaultURL,
+		Name:            "microservice",
+		MaxReconnects:   -1,
+		ReconnectWait:   2 * time.Second,
+		JetStream:       true,
+		AckPolicy:       "explicit",
+		AckWait:         30 * time.Second,
+		MaxDeliver:      5,
+		ShutdownTimeout: 30 * time.Second,
+	}
+}
+
+// options builds connection options from config
+func (c Config) options() []nats.Option {
+	opts := []nats.Option{
+		nats.Name(c.Name),
+		nats.MaxReconnects(c.MaxReconnects),
+		nats.ReconnectWait(c.ReconnectWait),
+	}
+
+	if c.Username != "" {
+		opts = append(opts, nats.UserInfo(c.Username, c.Password))
+	}
+	if c.Token != "" {
+		opts = append(opts, nats.Token(c.Token))
+	}
+	if c.CredentialsFile != "" {
+		opts = append(opts, nats.UserCredentials(c.CredentialsFile))
+	}
+	if c.TLSCAFile != "" {
+		opts = append(opts, nats.RootCAs(c.TLSCAFile))
+	}
+	if c.TLSCertFile != "" && c.TLSKeyFile != "" {
+		opts = append(opts, nats.ClientCert(c.TLSCertFile, c.TLSKeyFile))
+	}
+
+	return opts
+}
+
+// ackPolicy converts config value to JetStream ack policy
+func (c Config) ackPolicy() (jetstream.AckPolicy, error) {
+	switch strings.ToLower(c.AckPolicy) {
+	case "", "explicit":
+		return jetstream.AckExplicitPolicy, nil
+	case "all":
+		return jetstream.AckAllPolicy, nil
+	case "none":
+		return jetstream.AckNonePolicy, nil
+	default:
+		return 0, fmt.Errorf("unsupported ack policy: %s", c.AckPolicy)
+	}
+}
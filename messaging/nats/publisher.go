@@ -0,0 +1,120 @@
+package nats
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/alimzhanovlr/sdk/logger"
+	"github.com/alimzhanovlr/sdk/messaging"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"go.uber.org/fx"
+)
+
+// Publisher publishes messages to NATS subjects
+type Publisher struct {
+	conn   *nats.Conn
+	js     jetstream.JetStream
+	config Config
+}
+
+var _ messaging.Publisher = (*Publisher)(nil)
+
+// NewPublisher creates a new NATS publisher
+func NewPublisher(cfg Config, log *logger.Logger) (*Publisher, error) {
+	conn, err := connect(cfg, log)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Publisher{conn: conn, config: cfg}
+
+	if cfg.JetStream {
+		p.js, err = jetstream.New(conn)
+		if err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("failed to create jetstream context: %w", err)
+		}
+	}
+
+	return p, nil
+}
+
+// Publish publishes messages to subject
+func (p *Publisher) Publish(ctx context.Context, topic string, msgs ...*messaging.Message) error {
+	for _, msg := range msgs {
+		messaging.InjectTraceContext(ctx, msg)
+
+		nmsg := toNATSMsg(topic, msg)
+
+		if p.js != nil {
+			if _, err := p.js.PublishMsg(ctx, nmsg); err != nil {
+				return fmt.Errorf("failed to publish to %s: %w", topic, err)
+			}
+			continue
+		}
+
+		if err := p.conn.PublishMsg(nmsg); err != nil {
+			return fmt.Errorf("failed to publish to %s: %w", topic, err)
+		}
+	}
+
+	return nil
+}
+
+// Close drains and closes the connection
+func (p *Publisher) Close() error {
+	return p.conn.Drain()
+}
+
+// Start registers publisher shutdown in fx lifecycle
+func (p *Publisher) Start(lc fx.Lifecycle) {
+	lc.Append(fx.Hook{
+		OnStop: func(ctx context.Context) error {
+			return p.Close()
+		},
+	})
+}
+
+// toNATSMsg converts message, the key is carried in headerKey
+func toNATSMsg(topic string, msg *messaging.Message) *nats.Msg {
+	nmsg := &nats.Msg{
+		Subject: topic,
+		Data:    msg.Value,
+		Header:  nats.Header{},
+	}
+	for k, v := range msg.Headers {
+		nmsg.Header.Set(k, v)
+	}
+	if len(msg.Key) > 0 {
+		nmsg.Header.Set(headerKey, string(msg.Key))
+	}
+	return nmsg
+}
+
+// headerKey carries message key, NATS has no native key concept
+const headerKey = "x-message-key"
+
+// connect opens a NATS connection with reconnect logging
+func connect(cfg Config, log *logger.Logger) (*nats.Conn, error) {
+	opts := append(cfg.options(),
+		nats.DisconnectErrHandler(func(_ *nats.Conn, err error) {
+			if err != nil {
+				log.Error("NATS disconnected", logger.Error(err))
+			}
+		}),
+		nats.ReconnectHandler(func(conn *nats.Conn) {
+			log.Info("NATS reconnected", logger.String("url", conn.ConnectedUrlRedacted()))
+		}),
+		nats.ClosedHandler(func(_ *nats.Conn) {
+			log.Info("NATS connection closed")
+		}),
+	)
+
+	conn, err := nats.Connect(cfg.URL, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to nats: %w", err)
+	}
+
+	return conn, nil
+}
@@ -0,0 +1,49 @@
+package nats
+
+import (
+	"testing"
+
+	"github.com/alimzhanovlr/sdk/messaging"
+)
+
+func TestToNATSMsgRoundTrip(t *testing.T) {
+	msg := &messaging.Message{
+		Key:   []byte("order-1"),
+		Value: []byte(`{"id":1}`),
+		Headers: map[string]string{
+			"traceparent":  "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			"Content-Type": "application/json",
+		},
+	}
+
+	nmsg := toNATSMsg("orders.created", msg)
+	if nmsg.Subject != "orders.created" || string(nmsg.Data) != `{"id":1}` {
+		t.Fatalf("subject = %q, data = %q", nmsg.Subject, nmsg.Data)
+	}
+	if got := nmsg.Header.Get(headerKey); got != "order-1" {
+		t.Errorf("%s = %q, want the message key", headerKey, got)
+	}
+
+	got := fromCoreMsg(nmsg)
+	if got.Topic != "orders.created" || string(got.Key) != "order-1" || string(got.Value) != `{"id":1}` {
+		t.Errorf("message = %+v", got)
+	}
+	for k, v := range msg.Headers {
+		if got.Header(k) != v {
+			t.Errorf("header %s = %q, want %q", k, got.Header(k), v)
+		}
+	}
+	if got.Timestamp.IsZero() {
+		t.Error("core message has no timestamp, want the receive time")
+	}
+}
+
+func TestToNATSMsgWithoutKey(t *testing.T) {
+	nmsg := toNATSMsg("orders.created", &messaging.Message{Value: []byte("v")})
+	if _, ok := nmsg.Header[headerKey]; ok {
+		t.Errorf("header = %v, want no key header", nmsg.Header)
+	}
+	if got := fromCoreMsg(nmsg); len(got.Key) != 0 {
+		t.Errorf("key = %q, want empty", got.Key)
+	}
+}
@@ -0,0 +1,354 @@
+package nats
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alimzhanovlr/sdk/logger"
+	"github.com/alimzhanovlr/sdk/messaging"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"go.uber.org/fx"
+)
+
+// Subscriber delivers NATS messages to handlers
+type Subscriber struct {
+	conn        *nats.Conn
+	js          jetstream.JetStream
+	config      Config
+	logger      *logger.Logger
+	middlewares []messaging.Middleware
+
+	mu       sync.Mutex
+	routes   map[string]messaging.Handler
+	stops    []func()
+	inflight sync.WaitGroup
+	cancel   context.CancelFunc
+	running  bool
+	closed   bool
+}
+
+var _ messaging.Subscriber = (*Subscriber)(nil)
+
+// NewSubscriber creates a new NATS subscriber
+func NewSubscriber(cfg Config, log *logger.Logger, middlewares ...messaging.Middleware) (*Subscriber, error) {
+	if cfg.JetStream && cfg.Stream == "" {
+		return nil, fmt.Errorf("nats stream is not configured")
+	}
+
+	conn, err := connect(cfg, log)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Subscriber{
+		conn:        conn,
+		config:      cfg,
+		logger:      log,
+		middlewares: middlewares,
+		routes:      make(map[string]messaging.Handler),
+	}
+
+	if cfg.JetStream {
+		s.js, err = jetstream.New(conn)
+		if err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("failed to create jetstream context: %w", err)
+		}
+	}
+
+	return s, nil
+}
+
+// Use appends middlewares applied to every subscription
+func (s *Subscriber) Use(middlewares ...messaging.Middleware) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.middlewares = append(s.middlewares, middlewares...)
+}
+
+// Subscribe registers handler for subject
+func (s *Subscriber) Subscribe(topic string, handler messaging.Handler) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.routes[topic]; exists {
+		return fmt.Errorf("handler for subject %s already registered", topic)
+	}
+
+	s.routes[topic] = handler
+	return nil
+}
+
+// Run starts consuming all subscribed subjects and blocks until ctx is cancelled.
+// A subscriber runs once, another call returns an error
+func (s *Subscriber) Run(ctx context.Context) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return fmt.Errorf("nats subscriber is closed")
+	}
+	if s.running {
+		s.mu.Unlock()
+		return fmt.Errorf("nats subscriber is already running")
+	}
+	s.running = true
+	ctx, s.cancel = context.WithCancel(ctx)
+
+	for topic, handler := range s.routes {
+		h := messaging.Chain(handler, s.middlewares...)
+
+		var err error
+		if s.js != nil {
+			err = s.consumeJetStream(ctx, topic, h)
+		} else {
+			err = s.consumeCore(topic, h)
+		}
+		if err != nil {
+			s.mu.Unlock()
+			s.Close()
+			return err
+		}
+	}
+	s.mu.Unlock()
+
+	<-ctx.Done()
+	return nil
+}
+
+// consumeJetStream attaches a durable pull consumer to subject
+func (s *Subscriber) consumeJetStream(ctx context.Context, topic string, h messaging.Handler) error {
+	ackPolicy, err := s.config.ackPolicy()
+	if err != nil {
+		return err
+	}
+
+	consumer, err := s.js.CreateOrUpdateConsumer(ctx, s.config.Stream, jetstream.ConsumerConfig{
+		Durable:       durableName(s.config.Durable, topic),
+		FilterSubject: topic,
+		AckPolicy:     ackPolicy,
+		AckWait:       s.config.AckWait,
+		MaxDeliver:    s.config.MaxDeliver,
+		DeliverPolicy: jetstream.DeliverAllPolicy,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create consumer for %s: %w", topic, err)
+	}
+
+	consumeCtx, err := consumer.Consume(func(jmsg jetstream.Msg) {
+		s.inflight.Add(1)
+		defer s.inflight.Done()
+		s.handleJetStream(ctx, topic, h, ackPolicy, jmsg)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to consume %s: %w", topic, err)
+	}
+
+	s.stops = append(s.stops, consumeCtx.Stop)
+	return nil
+}
+
+// handleJetStream runs handler and acks the message, or naks it for
+// redelivery when handler fails. While the breaker is open the message is
+// held in progress and retried instead, every nak counts against MaxDeliver
+// and a long outage would exhaust the deliveries. Holding the message also
+// stops the consumer from pulling more until the breaker closes
+func (s *Subscriber) handleJetStream(ctx context.Context, topic string, h messaging.Handler, ackPolicy jetstream.AckPolicy, jmsg jetstream.Msg) {
+	msg := fromJetStreamMsg(jmsg)
+	for {
+		// Handler runs with a detached context so that an in-flight message
+		// completes during shutdown instead of being aborted mid-way
+		err := h.Handle(context.WithoutCancel(ctx), msg)
+		if err == nil {
+			break
+		}
+
+		s.logger.Error("Message handler failed",
+			logger.String("subject", topic),
+			logger.Error(err),
+		)
+		if ackPolicy == jetstream.AckNonePolicy {
+			return
+		}
+
+		if !messaging.Paused(err) {
+			if nakErr := jmsg.Nak(); nakErr != nil {
+				s.logger.Error("Failed to nak message", logger.Error(nakErr))
+			}
+			return
+		}
+
+		if ipErr := jmsg.InProgress(); ipErr != nil {
+			s.logger.Error("Failed to extend message ack wait", logger.Error(ipErr))
+		}
+		if !sleep(ctx, s.pauseInterval()) {
+			// Stopping while paused: give the breaker time to close
+			// before the message is redelivered
+			if nakErr := jmsg.NakWithDelay(s.config.AckWait); nakErr != nil {
+				s.logger.Error("Failed to nak message", logger.Error(nakErr))
+			}
+			return
+		}
+	}
+
+	if ackPolicy != jetstream.AckNonePolicy {
+		if err := jmsg.Ack(); err != nil {
+			s.logger.Error("Failed to ack message", logger.Error(err))
+		}
+	}
+}
+
+// pauseInterval is how long a paused message waits before the next attempt,
+// half of AckWait so that InProgress is renewed before the server redelivers
+func (s *Subscriber) pauseInterval() time.Duration {
+	if s.config.AckWait <= 0 {
+		return 15 * time.Second
+	}
+	return s.config.AckWait / 2
+}
+
+// sleep pauses for d, false when ctx is cancelled first
+func sleep(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// consumeCore subscribes with plain NATS (at-most-once)
+func (s *Subscriber) consumeCore(topic string, h messaging.Handler) error {
+	sub, err := s.conn.QueueSubscribe(topic, s.config.QueueGroup, func(nmsg *nats.Msg) {
+		s.inflight.Add(1)
+		defer s.inflight.Done()
+
+		if err := h.Handle(context.Background(), fromCoreMsg(nmsg)); err != nil {
+			s.logger.Error("Message handler failed",
+				logger.String("subject", topic),
+				logger.Error(err),
+			)
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to %s: %w", topic, err)
+	}
+
+	s.stops = append(s.stops, func() {
+		_ = sub.Unsubscribe()
+	})
+	return nil
+}
+
+// Close stops consumers, waits for in-flight handlers and drains the connection
+func (s *Subscriber) Close() error {
+	s.mu.Lock()
+	s.closed = true
+	for _, stop := range s.stops {
+		stop()
+	}
+	s.stops = nil
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.inflight.Wait()
+		close(done)
+	}()
+
+	timeout := s.config.ShutdownTimeout
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+
+	select {
+	case <-done:
+	case <-time.After(timeout):
+		s.logger.Error("Timed out waiting for in-flight messages")
+	}
+
+	if err := s.conn.Drain(); err != nil && !errors.Is(err, nats.ErrConnectionClosed) {
+		return fmt.Errorf("failed to drain nats connection: %w", err)
+	}
+
+	return nil
+}
+
+// Start registers subscriber in fx lifecycle
+func (s *Subscriber) Start(lc fx.Lifecycle) {
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			s.logger.Info("Starting nats subscriber",
+				logger.String("stream", s.config.Stream),
+			)
+
+			go func() {
+				if err := s.Run(context.Background()); err != nil {
+					s.logger.Error("NATS subscriber stopped", logger.Error(err))
+				}
+			}()
+
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			s.logger.Info("Shutting down nats subscriber")
+			return s.Close()
+		},
+	})
+}
+
+// fromJetStreamMsg converts JetStream message, the stream sequence becomes
+// the message offset
+func fromJetStreamMsg(jmsg jetstream.Msg) *messaging.Message {
+	msg := &messaging.Message{
+		Topic:   jmsg.Subject(),
+		Value:   jmsg.Data(),
+		Headers: fromNATSHeader(jmsg.Headers()),
+	}
+	msg.Key = []byte(msg.Header(headerKey))
+	if meta, err := jmsg.Metadata(); err == nil {
+		msg.Offset = int64(meta.Sequence.Stream)
+		msg.Timestamp = meta.Timestamp
+	}
+	return msg
+}
+
+// fromCoreMsg converts core NATS message, which has no server timestamp
+func fromCoreMsg(nmsg *nats.Msg) *messaging.Message {
+	msg := &messaging.Message{
+		Topic:     nmsg.Subject,
+		Value:     nmsg.Data,
+		Headers:   fromNATSHeader(nmsg.Header),
+		Timestamp: time.Now(),
+	}
+	msg.Key = []byte(msg.Header(headerKey))
+	return msg
+}
+
+// fromNATSHeader flattens NATS multi-value header
+func fromNATSHeader(h nats.Header) map[string]string {
+	result := make(map[string]string, len(h))
+	for k := range h {
+		result[k] = h.Get(k)
+	}
+	return result
+}
+
+// durableName builds a valid durable consumer name for subject
+func durableName(prefix, subject string) string {
+	name := strings.NewReplacer(".", "_", "*", "any", ">", "all").Replace(subject)
+	if prefix == "" {
+		return name
+	}
+	return prefix + "_" + name
+}
@@ -0,0 +1,207 @@
+package nats
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alimzhanovlr/sdk/breaker"
+	"github.com/alimzhanovlr/sdk/logger"
+	"github.com/alimzhanovlr/sdk/messaging"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"go.uber.org/zap"
+)
+
+// fakeMsg is a JetStream message recording acknowledgements
+type fakeMsg struct {
+	jetstream.Msg
+
+	subject  string
+	data     []byte
+	header   nats.Header
+	meta     *jetstream.MsgMetadata
+	acks     int
+	naks     int
+	extended int
+	nakDelay time.Duration
+}
+
+func (m *fakeMsg) Subject() string      { return m.subject }
+func (m *fakeMsg) Data() []byte         { return m.data }
+func (m *fakeMsg) Headers() nats.Header { return m.header }
+
+func (m *fakeMsg) Metadata() (*jetstream.MsgMetadata, error) {
+	if m.meta == nil {
+		return nil, jetstream.ErrNotJSMessage
+	}
+	return m.meta, nil
+}
+
+func (m *fakeMsg) Ack() error { m.acks++; return nil }
+func (m *fakeMsg) Nak() error { m.naks++; return nil }
+
+func (m *fakeMsg) InProgress() error { m.extended++; return nil }
+
+func (m *fakeMsg) NakWithDelay(delay time.Duration) error {
+	m.naks++
+	m.nakDelay = delay
+	return nil
+}
+
+func testSubscriber() *Subscriber {
+	return &Subscriber{
+		config: Config{AckWait: 30 * time.Second},
+		logger: &logger.Logger{Logger: zap.NewNop()},
+	}
+}
+
+func TestFromJetStreamMsg(t *testing.T) {
+	published := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	header := nats.Header{}
+	header.Set(headerKey, "order-1")
+	header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+	msg := fromJetStreamMsg(&fakeMsg{
+		subject: "orders.created",
+		data:    []byte("v"),
+		header:  header,
+		meta: &jetstream.MsgMetadata{
+			Sequence:  jetstream.SequencePair{Stream: 42, Consumer: 7},
+			Timestamp: published,
+		},
+	})
+
+	if msg.Topic != "orders.created" || string(msg.Value) != "v" || string(msg.Key) != "order-1" {
+		t.Errorf("message = %+v", msg)
+	}
+	if msg.Header("traceparent") != header.Get("traceparent") {
+		t.Errorf("traceparent = %q", msg.Header("traceparent"))
+	}
+	if msg.Offset != 42 {
+		t.Errorf("offset = %d, want the stream sequence 42", msg.Offset)
+	}
+	if !msg.Timestamp.Equal(published) {
+		t.Errorf("timestamp = %s, want %s", msg.Timestamp, published)
+	}
+}
+
+func TestHandleJetStreamAcks(t *testing.T) {
+	errFailed := errors.New("down")
+	tests := []struct {
+		name      string
+		ackPolicy jetstream.AckPolicy
+		err       error
+		wantAcks  int
+		wantNaks  int
+		wantDelay time.Duration
+	}{
+		{"success acks", jetstream.AckExplicitPolicy, nil, 1, 0, 0},
+		{"failure naks", jetstream.AckExplicitPolicy, errFailed, 0, 1, 0},
+		{"open breaker on stop naks with delay", jetstream.AckExplicitPolicy, breaker.ErrOpenState, 0, 1, 30 * time.Second},
+		{"ack none policy", jetstream.AckNonePolicy, errFailed, 0, 0, 0},
+	}
+
+	// Stopped subscriber: a paused message is handed back at once
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jmsg := &fakeMsg{subject: "orders.created", header: nats.Header{}}
+			var handled *messaging.Message
+			h := messaging.HandlerFunc(func(ctx context.Context, msg *messaging.Message) error {
+				handled = msg
+				return tt.err
+			})
+
+			testSubscriber().handleJetStream(ctx, "orders.created", h, tt.ackPolicy, jmsg)
+
+			if handled == nil || handled.Topic != "orders.created" {
+				t.Fatalf("handled = %+v", handled)
+			}
+			if jmsg.acks != tt.wantAcks || jmsg.naks != tt.wantNaks {
+				t.Errorf("acks = %d, naks = %d, want %d and %d", jmsg.acks, jmsg.naks, tt.wantAcks, tt.wantNaks)
+			}
+			if jmsg.nakDelay != tt.wantDelay {
+				t.Errorf("nak delay = %s, want %s", jmsg.nakDelay, tt.wantDelay)
+			}
+		})
+	}
+}
+
+func TestHandleJetStreamHoldsPausedMessage(t *testing.T) {
+	s := testSubscriber()
+	s.config.AckWait = 10 * time.Millisecond
+
+	jmsg := &fakeMsg{subject: "orders.created", header: nats.Header{}}
+	calls := 0
+	h := messaging.HandlerFunc(func(ctx context.Context, msg *messaging.Message) error {
+		calls++
+		if calls < 3 {
+			return breaker.ErrOpenState
+		}
+		return nil
+	})
+
+	s.handleJetStream(context.Background(), "orders.created", h, jetstream.AckExplicitPolicy, jmsg)
+
+	if calls != 3 {
+		t.Errorf("calls = %d, want 3", calls)
+	}
+	if jmsg.naks != 0 {
+		t.Errorf("naks = %d, want 0: a paused message must not use up deliveries", jmsg.naks)
+	}
+	if jmsg.extended != 2 || jmsg.acks != 1 {
+		t.Errorf("in progress = %d, acks = %d, want 2 and 1", jmsg.extended, jmsg.acks)
+	}
+}
+
+func TestSubscriberRunsOnce(t *testing.T) {
+	s := testSubscriber()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- s.Run(ctx) }()
+
+	// Wait until the first Run holds the subscriber
+	for {
+		s.mu.Lock()
+		running := s.running
+		s.mu.Unlock()
+		if running {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	if err := s.Run(context.Background()); err == nil {
+		t.Error("second Run returned nil, want an error while running")
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("first Run: %v", err)
+	}
+
+	closed := testSubscriber()
+	closed.closed = true
+	if err := closed.Run(context.Background()); err == nil {
+		t.Error("Run after Close returned nil, want an error")
+	}
+}
+
+func TestDurableName(t *testing.T) {
+	tests := []struct {
+		prefix, subject, want string
+	}{
+		{"", "orders.created", "orders_created"},
+		{"billing", "orders.*", "billing_orders_any"},
+		{"billing", "orders.>", "billing_orders_all"},
+	}
+	for _, tt := range tests {
+		if got := durableName(tt.prefix, tt.subject); got != tt.want {
+			t.Errorf("durableName(%q, %q) = %q, want %q", tt.prefix, tt.subject, got, tt.want)
+		}
+	}
+}
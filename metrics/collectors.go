@@ -0,0 +1,271 @@
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/alimzhanovlr/sdk/breaker"
+	"github.com/alimzhanovlr/sdk/httpclient"
+	"github.com/alimzhanovlr/sdk/workerpool"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// Default duration buckets in seconds
+var DefaultDurationBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// RegisterDBStats reports database/sql pool stats for db under name
+func (r *Registry) RegisterDBStats(name string, db *sql.DB) error {
+	open, err := r.meter.Int64ObservableGauge(r.name("db_connections_open"),
+		metric.WithDescription("Number of established connections"))
+	if err != nil {
+		return err
+	}
+	inUse, err := r.meter.Int64ObservableGauge(r.name("db_connections_in_use"),
+		metric.WithDescription("Number of connections currently in use"))
+	if err != nil {
+		return err
+	}
+	idle, err := r.meter.Int64ObservableGauge(r.name("db_connections_idle"),
+		metric.WithDescription("Number of idle connections"))
+	if err != nil {
+		return err
+	}
+	waitCount, err := r.meter.Int64ObservableCounter(r.name("db_connections_wait_total"),
+		metric.WithDescription("Total number of connections waited for"))
+	if err != nil {
+		return err
+	}
+	waitDuration, err := r.meter.Float64ObservableCounter(r.name("db_connections_wait_seconds_total"),
+		metric.WithDescription("Total time blocked waiting for a new connection"))
+	if err != nil {
+		return err
+	}
+
+	attrs := metric.WithAttributes(attribute.String("db", name))
+
+	_, err = r.meter.RegisterCallback(func(ctx context.Context, o metric.Observer) error {
+		stats := db.Stats()
+		o.ObserveInt64(open, int64(stats.OpenConnections), attrs)
+		o.ObserveInt64(inUse, int64(stats.InUse), attrs)
+		o.ObserveInt64(idle, int64(stats.Idle), attrs)
+		o.ObserveInt64(waitCount, stats.WaitCount, attrs)
+		o.ObserveFloat64(waitDuration, stats.WaitDuration.Seconds(), attrs)
+		return nil
+	}, open, inUse, idle, waitCount, waitDuration)
+
+	return err
+}
+
+// RegisterSLO reports endpoint stats of tracker, it is read on every
+// collection
+func (r *Registry) RegisterSLO(tracker *httpclient.SLOTracker) error {
+	requests, err := r.meter.Int64ObservableGauge(r.name("http_client_slo_requests"),
+		metric.WithDescription("Requests to an endpoint within the SLO window"))
+	if err != nil {
+		return err
+	}
+	successRatio, err := r.meter.Float64ObservableGauge(r.name("http_client_slo_success_ratio"),
+		metric.WithDescription("Share of successful requests within the SLO window"))
+	if err != nil {
+		return err
+	}
+	latency, err := r.meter.Float64ObservableGauge(r.name("http_client_slo_latency_seconds"),
+		metric.WithDescription("Request latency percentiles within the SLO window"))
+	if err != nil {
+		return err
+	}
+	breached, err := r.meter.Int64ObservableGauge(r.name("http_client_slo_breached"),
+		metric.WithDescription("1 if an endpoint does not meet its objective"))
+	if err != nil {
+		return err
+	}
+
+	_, err = r.meter.RegisterCallback(func(ctx context.Context, o metric.Observer) error {
+		for _, s := range tracker.Stats() {
+			endpoint := []attribute.KeyValue{
+				attribute.String("host", s.Host),
+				attribute.String("path", s.Path),
+			}
+			attrs := metric.WithAttributes(endpoint...)
+
+			o.ObserveInt64(requests, s.Requests, attrs)
+			o.ObserveFloat64(successRatio, s.SuccessRate, attrs)
+			for quantile, value := range map[string]time.Duration{"0.5": s.P50, "0.9": s.P90, "0.99": s.P99} {
+				o.ObserveFloat64(latency, value.Seconds(),
+					metric.WithAttributes(append(endpoint, attribute.String("quantile", quantile))...))
+			}
+
+			var isBreached int64
+			if s.Breached() {
+				isBreached = 1
+			}
+			o.ObserveInt64(breached, isBreached, attrs)
+		}
+		return nil
+	}, requests, successRatio, latency, breached)
+
+	return err
+}
+
+// HTTPClientMetrics records outgoing HTTP request metrics,
+// it satisfies httpclient.Metrics
+type HTTPClientMetrics struct {
+	requests Counter
+	duration Histogram
+}
+
+// HTTPClient returns recorder for httpclient.NewMetricsRoundTripper
+func (r *Registry) HTTPClient() *HTTPClientMetrics {
+	return &HTTPClientMetrics{
+		requests: r.Counter("http_client_requests_total", "Total number of outgoing HTTP requests"),
+		duration: r.Histogram("http_client_request_duration_seconds", "Outgoing HTTP request duration", DefaultDurationBuckets...),
+	}
+}
+
+// ObserveRequest records a single outgoing request
+func (m *HTTPClientMetrics) ObserveRequest(method, host string, status int, duration time.Duration, err error) {
+	ctx := context.Background()
+	attrs := []attribute.KeyValue{
+		attribute.String("method", method),
+		attribute.String("host", host),
+		attribute.Int("status", status),
+		attribute.Bool("error", err != nil),
+	}
+
+	m.requests.Add(ctx, 1, attrs...)
+	m.duration.Record(ctx, duration.Seconds(), attrs...)
+}
+
+// SanitizerMetrics records log redaction activity,
+// it satisfies httpclient.SanitizerMetrics
+type SanitizerMetrics struct {
+	redactions     Counter
+	bodyRules      Counter
+	droppedBytes   Counter
+	categoryValues Counter
+	categoryCalls  Counter
+}
+
+// Sanitizer returns recorder for httpclient.LoggingConfig.SanitizerMetrics
+func (r *Registry) Sanitizer() *SanitizerMetrics {
+	return &SanitizerMetrics{
+		redactions:     r.Counter("sanitizer_redactions_total", "Number of values redacted from logs"),
+		bodyRules:      r.Counter("sanitizer_body_rules_total", "Number of times a body processing rule matched"),
+		droppedBytes:   r.Counter("sanitizer_body_dropped_bytes_total", "Body bytes not logged due to skip, truncate or summarize rules"),
+		categoryValues: r.Counter("sanitizer_data_category_redactions_total", "Number of values redacted from logs by data category"),
+		categoryCalls:  r.Counter("sanitizer_data_category_exchanges_total", "Number of HTTP exchanges carrying redacted data of a category"),
+	}
+}
+
+// ObserveRedaction records redacted values, zero count registers the series
+func (m *SanitizerMetrics) ObserveRedaction(category, rule string, count int) {
+	m.redactions.Add(context.Background(), float64(count),
+		attribute.String("category", category),
+		attribute.String("rule", rule),
+	)
+}
+
+// ObserveBodyRule records body rule matches and bytes left out of logs
+func (m *SanitizerMetrics) ObserveBodyRule(rule string, action httpclient.BodyAction, matches, droppedBytes int) {
+	attrs := []attribute.KeyValue{
+		attribute.String("rule", rule),
+		attribute.String("action", string(action)),
+	}
+
+	m.bodyRules.Add(context.Background(), float64(matches), attrs...)
+	m.droppedBytes.Add(context.Background(), float64(droppedBytes), attrs...)
+}
+
+// ObserveDataCategories records an exchange with host and values
+// redacted per data category
+func (m *SanitizerMetrics) ObserveDataCategories(host string, counts map[httpclient.DataCategory]int) {
+	for category, count := range counts {
+		attrs := []attribute.KeyValue{
+			attribute.String("host", host),
+			attribute.String("category", string(category)),
+		}
+
+		m.categoryCalls.Add(context.Background(), 1, attrs...)
+		m.categoryValues.Add(context.Background(), float64(count), attrs...)
+	}
+}
+
+// BreakerMetrics records circuit breaker activity, it satisfies
+// breaker.Metrics
+type BreakerMetrics struct {
+	state    Gauge
+	changes  Counter
+	requests Counter
+}
+
+// Breaker returns recorder for breaker.Settings.Metrics, one per registry
+// is shared by all breakers
+func (r *Registry) Breaker() *BreakerMetrics {
+	return &BreakerMetrics{
+		state:    r.Gauge("circuit_breaker_state", "Circuit breaker state: 0 closed, 1 half-open, 2 open"),
+		changes:  r.Counter("circuit_breaker_state_changes_total", "Number of circuit breaker state changes"),
+		requests: r.Counter("circuit_breaker_requests_total", "Requests through circuit breakers by result"),
+	}
+}
+
+// ObserveState records the current state and counts changes
+func (m *BreakerMetrics) ObserveState(name string, from, to breaker.State) {
+	ctx := context.Background()
+	m.state.Set(ctx, float64(to), attribute.String("name", name))
+	if from != to {
+		m.changes.Add(ctx, 1,
+			attribute.String("name", name),
+			attribute.String("from", from.String()),
+			attribute.String("to", to.String()),
+		)
+	}
+}
+
+// ObserveRequest records a request passed or rejected by a breaker
+func (m *BreakerMetrics) ObserveRequest(name string, result breaker.Result) {
+	m.requests.Add(context.Background(), 1,
+		attribute.String("name", name),
+		attribute.String("result", string(result)),
+	)
+}
+
+// WorkerPoolMetrics records worker pool activity, it satisfies
+// workerpool.Metrics
+type WorkerPoolMetrics struct {
+	queue    Gauge
+	tasks    Counter
+	wait     Histogram
+	duration Histogram
+}
+
+// WorkerPool returns recorder for workerpool.Config.Metrics, one per
+// registry is shared by all pools
+func (r *Registry) WorkerPool() *WorkerPoolMetrics {
+	return &WorkerPoolMetrics{
+		queue:    r.Gauge("workerpool_queue_depth", "Number of tasks waiting for a worker"),
+		tasks:    r.Counter("workerpool_tasks_total", "Number of executed tasks by result"),
+		wait:     r.Histogram("workerpool_task_wait_seconds", "Time tasks spent in the queue", DefaultDurationBuckets...),
+		duration: r.Histogram("workerpool_task_duration_seconds", "Task execution duration", DefaultDurationBuckets...),
+	}
+}
+
+// ObserveQueue records the current queue depth
+func (m *WorkerPoolMetrics) ObserveQueue(pool string, depth int) {
+	m.queue.Set(context.Background(), float64(depth), attribute.String("pool", pool))
+}
+
+// ObserveTask records an executed task
+func (m *WorkerPoolMetrics) ObserveTask(pool string, result workerpool.Result, wait, duration time.Duration) {
+	ctx := context.Background()
+	m.tasks.Add(ctx, 1,
+		attribute.String("pool", pool),
+		attribute.String("result", string(result)),
+	)
+	m.wait.Record(ctx, wait.Seconds(), attribute.String("pool", pool))
+	m.duration.Record(ctx, duration.Seconds(),
+		attribute.String("pool", pool),
+		attribute.String("result", string(result)),
+	)
+}
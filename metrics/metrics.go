@@ -0,0 +1,277 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	otelprom "go.opentelemetry.io/otel/exporters/prometheus"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.uber.org/fx"
+)
+
+// Exporter names
+const (
+	ExporterPrometheus = "prometheus"
+	ExporterOTLP       = "otlp"
+)
+
+// Config holds metrics configuration
+type Config struct {
+	Enabled     bool
+	ServiceName string
+	// Exporter is prometheus (pull via Path) or otlp (push to Endpoint)
+	Exporter  string
+	Path      string
+	Namespace string
+	Endpoint  string
+	Interval  time.Duration
+	// Resource replaces the service name only resource, e.g. tracing.NewResource
+	Resource *resource.Resource
+}
+
+// Counter is a monotonically increasing value
+type Counter interface {
+	Add(ctx context.Context, value float64, attrs ...attribute.KeyValue)
+}
+
+// Histogram records value distribution
+type Histogram interface {
+	Record(ctx context.Context, value float64, attrs ...attribute.KeyValue)
+}
+
+// Gauge records the current value
+type Gauge interface {
+	Set(ctx context.Context, value float64, attrs ...attribute.KeyValue)
+}
+
+// Registry is the single metrics registry shared by all subsystems
+type Registry struct {
+	config   Config
+	provider *sdkmetric.MeterProvider
+	meter    metric.Meter
+	gatherer prometheus.Gatherer
+
+	mu         sync.Mutex
+	counters   map[string]Counter
+	histograms map[string]Histogram
+	gauges     map[string]Gauge
+}
+
+// New creates a new metrics registry
+func New(cfg Config) (*Registry, error) {
+	r := newRegistry(cfg)
+	if !cfg.Enabled {
+		r.meter = noop.NewMeterProvider().Meter(cfg.ServiceName)
+		return r, nil
+	}
+
+	var reader sdkmetric.Reader
+	switch cfg.Exporter {
+	case "", ExporterPrometheus:
+		registry := prometheus.NewRegistry()
+		exp, err := otelprom.New(otelprom.WithRegisterer(registry))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create prometheus exporter: %w", err)
+		}
+		reader = exp
+		r.gatherer = registry
+	case ExporterOTLP:
+		exp, err := otlpmetricgrpc.New(context.Background(),
+			otlpmetricgrpc.WithEndpoint(cfg.Endpoint),
+			otlpmetricgrpc.WithInsecure(),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create otlp exporter: %w", err)
+		}
+		interval := cfg.Interval
+		if interval <= 0 {
+			interval = 15 * time.Second
+		}
+		reader = sdkmetric.NewPeriodicReader(exp, sdkmetric.WithInterval(interval))
+	default:
+		return nil, fmt.Errorf("unknown metrics exporter: %s", cfg.Exporter)
+	}
+
+	r.useReader(reader)
+	otel.SetMeterProvider(r.provider)
+	return r, nil
+}
+
+func newRegistry(cfg Config) *Registry {
+	return &Registry{
+		config:     cfg,
+		counters:   make(map[string]Counter),
+		histograms: make(map[string]Histogram),
+		gauges:     make(map[string]Gauge),
+	}
+}
+
+// useReader creates the meter provider exporting through reader
+func (r *Registry) useReader(reader sdkmetric.Reader) {
+	res := r.config.Resource
+	if res == nil {
+		res = resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(r.config.ServiceName))
+	}
+
+	r.provider = sdkmetric.NewMeterProvider(
+		sdkmetric.WithReader(reader),
+		sdkmetric.WithResource(res),
+	)
+	r.meter = r.provider.Meter(r.config.ServiceName)
+}
+
+// Meter returns underlying OpenTelemetry meter for advanced instruments
+func (r *Registry) Meter() metric.Meter {
+	return r.meter
+}
+
+// Counter returns counter by name, creating it on first use
+func (r *Registry) Counter(name, description string) Counter {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	name = r.name(name)
+	if c, ok := r.counters[name]; ok {
+		return c
+	}
+
+	inst, err := r.meter.Float64Counter(name, metric.WithDescription(description))
+	if err != nil {
+		otel.Handle(err)
+	}
+	c := &counter{inst: inst}
+	r.counters[name] = c
+	return c
+}
+
+// Histogram returns histogram by name, creating it on first use.
+// Buckets are optional explicit bucket boundaries
+func (r *Registry) Histogram(name, description string, buckets ...float64) Histogram {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	name = r.name(name)
+	if h, ok := r.histograms[name]; ok {
+		return h
+	}
+
+	opts := []metric.Float64HistogramOption{metric.WithDescription(description)}
+	if len(buckets) > 0 {
+		opts = append(opts, metric.WithExplicitBucketBoundaries(buckets...))
+	}
+
+	inst, err := r.meter.Float64Histogram(name, opts...)
+	if err != nil {
+		otel.Handle(err)
+	}
+	h := &histogram{inst: inst}
+	r.histograms[name] = h
+	return h
+}
+
+// Gauge returns gauge by name, creating it on first use
+func (r *Registry) Gauge(name, description string) Gauge {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	name = r.name(name)
+	if g, ok := r.gauges[name]; ok {
+		return g
+	}
+
+	inst, err := r.meter.Float64Gauge(name, metric.WithDescription(description))
+	if err != nil {
+		otel.Handle(err)
+	}
+	g := &gauge{inst: inst}
+	r.gauges[name] = g
+	return g
+}
+
+// name applies configured namespace
+func (r *Registry) name(name string) string {
+	if r.config.Namespace == "" || strings.HasPrefix(name, r.config.Namespace+"_") {
+		return name
+	}
+	return r.config.Namespace + "_" + name
+}
+
+// Path returns scrape path, empty when metrics are not pulled
+func (r *Registry) Path() string {
+	if r.gatherer == nil {
+		return ""
+	}
+	if r.config.Path == "" {
+		return "/metrics"
+	}
+	return r.config.Path
+}
+
+// HTTPHandler returns Prometheus scrape handler
+func (r *Registry) HTTPHandler() http.Handler {
+	if r.gatherer == nil {
+		return http.NotFoundHandler()
+	}
+	return promhttp.HandlerFor(r.gatherer, promhttp.HandlerOpts{})
+}
+
+// Handler returns Prometheus scrape handler for Fiber
+func (r *Registry) Handler() fiber.Handler {
+	return adaptor.HTTPHandler(r.HTTPHandler())
+}
+
+// Shutdown flushes and stops exporters
+func (r *Registry) Shutdown(ctx context.Context) error {
+	if r.provider == nil {
+		return nil
+	}
+	return r.provider.Shutdown(ctx)
+}
+
+// Start registers registry shutdown in fx lifecycle
+func (r *Registry) Start(lc fx.Lifecycle) {
+	lc.Append(fx.Hook{
+		OnStop: func(ctx context.Context) error {
+			return r.Shutdown(ctx)
+		},
+	})
+}
+
+type counter struct {
+	inst metric.Float64Counter
+}
+
+func (c *counter) Add(ctx context.Context, value float64, attrs ...attribute.KeyValue) {
+	c.inst.Add(ctx, value, metric.WithAttributes(attrs...))
+}
+
+type histogram struct {
+	inst metric.Float64Histogram
+}
+
+func (h *histogram) Record(ctx context.Context, value float64, attrs ...attribute.KeyValue) {
+	h.inst.Record(ctx, value, metric.WithAttributes(attrs...))
+}
+
+type gauge struct {
+	inst metric.Float64Gauge
+}
+
+func (g *gauge) Set(ctx context.Context, value float64, attrs ...attribute.KeyValue) {
+	g.inst.Record(ctx, value, metric.WithAttributes(attrs...))
+}
@@ -0,0 +1,166 @@
+package metrics
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/alimzhanovlr/sdk/breaker"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// newManualRegistry returns a registry exporting through a manual reader
+func newManualRegistry(t *testing.T, cfg Config) (*Registry, *sdkmetric.ManualReader) {
+	t.Helper()
+	cfg.Enabled = true
+	reader := sdkmetric.NewManualReader()
+	r := newRegistry(cfg)
+	r.useReader(reader)
+	t.Cleanup(func() { _ = r.Shutdown(context.Background()) })
+	return r, reader
+}
+
+// collect returns collected metrics by name
+func collect(t *testing.T, reader *sdkmetric.ManualReader) map[string]metricdata.Metrics {
+	t.Helper()
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatal(err)
+	}
+	result := make(map[string]metricdata.Metrics)
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			result[m.Name] = m
+		}
+	}
+	return result
+}
+
+func TestRegistryReusesInstruments(t *testing.T) {
+	r, _ := newManualRegistry(t, Config{ServiceName: "test", Namespace: "shop"})
+
+	if r.Counter("orders_total", "") != r.Counter("shop_orders_total", "") {
+		t.Error("counter is created twice, the namespace prefix must not be doubled")
+	}
+	if r.Histogram("latency_seconds", "") != r.Histogram("latency_seconds", "") {
+		t.Error("histogram is created twice")
+	}
+	if r.Gauge("queue_depth", "") != r.Gauge("queue_depth", "") {
+		t.Error("gauge is created twice")
+	}
+}
+
+func TestRegistryExportsThroughReader(t *testing.T) {
+	r, reader := newManualRegistry(t, Config{ServiceName: "test", Namespace: "shop"})
+	ctx := context.Background()
+
+	orders := r.Counter("orders_total", "Orders")
+	orders.Add(ctx, 2, attribute.String("status", "paid"))
+	orders.Add(ctx, 1, attribute.String("status", "paid"))
+	r.Histogram("latency_seconds", "Latency", 0.1, 1).Record(ctx, 0.5)
+	depth := r.Gauge("queue_depth", "Queue depth")
+	depth.Set(ctx, 7)
+	depth.Set(ctx, 3)
+
+	got := collect(t, reader)
+
+	sum, ok := got["shop_orders_total"].Data.(metricdata.Sum[float64])
+	if !ok || len(sum.DataPoints) != 1 || sum.DataPoints[0].Value != 3 || !sum.IsMonotonic {
+		t.Errorf("counter = %+v, want monotonic 3", got["shop_orders_total"].Data)
+	} else if status, _ := sum.DataPoints[0].Attributes.Value("status"); status.AsString() != "paid" {
+		t.Errorf("counter attributes = %v", sum.DataPoints[0].Attributes)
+	}
+
+	hist, ok := got["shop_latency_seconds"].Data.(metricdata.Histogram[float64])
+	if !ok || len(hist.DataPoints) != 1 {
+		t.Fatalf("histogram = %+v", got["shop_latency_seconds"].Data)
+	}
+	point := hist.DataPoints[0]
+	if len(point.Bounds) != 2 || point.Bounds[0] != 0.1 || point.Bounds[1] != 1 {
+		t.Errorf("bounds = %v, want explicit buckets", point.Bounds)
+	}
+	if point.Count != 1 || point.BucketCounts[1] != 1 {
+		t.Errorf("histogram point = %+v, want one value in (0.1, 1]", point)
+	}
+
+	gauge, ok := got["shop_queue_depth"].Data.(metricdata.Gauge[float64])
+	if !ok || len(gauge.DataPoints) != 1 || gauge.DataPoints[0].Value != 3 {
+		t.Errorf("gauge = %+v, want the last value 3", got["shop_queue_depth"].Data)
+	}
+}
+
+func TestBreakerMetrics(t *testing.T) {
+	r, reader := newManualRegistry(t, Config{ServiceName: "test"})
+	m := r.Breaker()
+
+	m.ObserveState("payments", breaker.StateClosed, breaker.StateClosed)
+	m.ObserveState("payments", breaker.StateClosed, breaker.StateOpen)
+	m.ObserveRequest("payments", breaker.ResultRejected)
+
+	got := collect(t, reader)
+	if state := got["circuit_breaker_state"].Data.(metricdata.Gauge[float64]); state.DataPoints[0].Value != float64(breaker.StateOpen) {
+		t.Errorf("state = %v, want open", state.DataPoints[0].Value)
+	}
+	// The initial observation is not a change
+	if changes := got["circuit_breaker_state_changes_total"].Data.(metricdata.Sum[float64]); len(changes.DataPoints) != 1 || changes.DataPoints[0].Value != 1 {
+		t.Errorf("changes = %+v, want 1", changes.DataPoints)
+	}
+	if _, ok := got["circuit_breaker_requests_total"]; !ok {
+		t.Error("requests are not recorded")
+	}
+}
+
+func TestPrometheusHandler(t *testing.T) {
+	r, err := New(Config{Enabled: true, ServiceName: "test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Shutdown(context.Background())
+
+	r.Counter("jobs_total", "Jobs").Add(context.Background(), 1, attribute.String("job", "report"))
+
+	if r.Path() != "/metrics" {
+		t.Errorf("Path() = %q, want /metrics", r.Path())
+	}
+	rec := httptest.NewRecorder()
+	r.HTTPHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body, _ := io.ReadAll(rec.Body)
+	if !strings.Contains(string(body), `jobs_total{job="report"`) {
+		t.Errorf("scrape does not contain the counter:\n%s", body)
+	}
+}
+
+func TestDisabledRegistry(t *testing.T) {
+	r, err := New(Config{ServiceName: "test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Instruments are no-ops, callers do not check Enabled
+	r.Counter("jobs_total", "").Add(context.Background(), 1)
+	r.Histogram("latency_seconds", "").Record(context.Background(), 1)
+	r.Gauge("queue_depth", "").Set(context.Background(), 1)
+
+	if r.Path() != "" {
+		t.Errorf("Path() = %q, want empty", r.Path())
+	}
+	rec := httptest.NewRecorder()
+	r.HTTPHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("scrape status = %d, want 404", rec.Code)
+	}
+	if err := r.Shutdown(context.Background()); err != nil {
+		t.Errorf("Shutdown() error = %v", err)
+	}
+}
+
+func TestUnknownExporter(t *testing.T) {
+	if _, err := New(Config{Enabled: true, Exporter: "statsd"}); err == nil {
+		t.Error("unknown exporter is accepted")
+	}
+}
@@ -0,0 +1,102 @@
+package middleware
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/alimzhanovlr/sdk/audit"
+	"github.com/alimzhanovlr/sdk/logger"
+	"github.com/gofiber/fiber/v2"
+)
+
+// AuditConfig holds request auditing configuration
+type AuditConfig struct {
+	// Auditor records requests of Methods, nil only sets the actor for
+	// usecases calling Auditor.Record
+	Auditor *audit.Auditor
+	Methods []string
+	// Resource names the resource of a request, defaults to the route path
+	// as type and the id parameter
+	Resource func(c *fiber.Ctx) audit.Resource
+	// Logger reports events that failed to be recorded, required with
+	// Auditor
+	Logger *logger.Logger
+}
+
+// DefaultAuditConfig returns config recording mutating requests
+func DefaultAuditConfig(auditor *audit.Auditor, log *logger.Logger) AuditConfig {
+	return AuditConfig{
+		Auditor: auditor,
+		Methods: []string{fiber.MethodPost, fiber.MethodPut, fiber.MethodPatch, fiber.MethodDelete},
+		Logger:  log,
+	}
+}
+
+// AuditMiddleware stores the actor (SubjectKey, IP, user agent) in the user
+// context and records requests of configured methods with their outcome.
+// Must run after auth middleware.
+//
+// Events are recorded after the handler has committed its changes, a sink
+// failure is logged and does not fail the response. Actions that must not
+// go unrecorded are audited by the usecase with Auditor.Prepare and
+// SQLSink.Save inside its own transaction
+func AuditMiddleware(config AuditConfig) fiber.Handler {
+	if config.Auditor != nil && config.Logger == nil {
+		panic("audit middleware: Logger is required")
+	}
+	if config.Resource == nil {
+		config.Resource = func(c *fiber.Ctx) audit.Resource {
+			return audit.Resource{Type: c.Route().Path, ID: c.Params("id")}
+		}
+	}
+
+	return func(c *fiber.Ctx) error {
+		actor := audit.Actor{
+			Type:      audit.ActorUser,
+			IP:        c.IP(),
+			UserAgent: c.Get(fiber.HeaderUserAgent),
+		}
+		if subject, ok := c.Locals(SubjectKey).(string); ok && subject != "" {
+			actor.ID = subject
+		} else {
+			actor.ID = "anonymous"
+		}
+		c.SetUserContext(audit.WithActor(c.UserContext(), actor))
+
+		if config.Auditor == nil || !slices.Contains(config.Methods, c.Method()) {
+			return c.Next()
+		}
+
+		err := c.Next()
+
+		status := c.Response().StatusCode()
+		if e, ok := err.(*fiber.Error); ok {
+			status = e.Code
+		} else if err != nil {
+			status = fiber.StatusInternalServerError
+		}
+		outcome := audit.OutcomeSuccess
+		switch {
+		case status == fiber.StatusUnauthorized || status == fiber.StatusForbidden:
+			outcome = audit.OutcomeDenied
+		case status >= 400:
+			outcome = audit.OutcomeFailure
+		}
+
+		event := audit.Event{
+			Action:   strings.ToLower(c.Method()) + " " + c.Route().Path,
+			Resource: config.Resource(c),
+			Outcome:  outcome,
+		}
+		if recordErr := config.Auditor.Record(c.UserContext(), event); recordErr != nil {
+			config.Logger.Ctx(c.UserContext()).Error("Failed to record audit event",
+				logger.String("action", event.Action),
+				logger.String("resource_type", event.Resource.Type),
+				logger.String("resource_id", event.Resource.ID),
+				logger.String("outcome", event.Outcome),
+				logger.Error(recordErr),
+			)
+		}
+		return err
+	}
+}
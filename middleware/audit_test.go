@@ -0,0 +1,110 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alimzhanovlr/sdk/audit"
+	"github.com/alimzhanovlr/sdk/logger"
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+type auditSink struct {
+	events []*audit.Event
+	err    error
+}
+
+func (s *auditSink) Write(_ context.Context, events ...*audit.Event) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.events = append(s.events, events...)
+	return nil
+}
+
+func auditApp(sink *auditSink, log *logger.Logger, status int) *fiber.App {
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals(SubjectKey, "u1")
+		return c.Next()
+	})
+	app.Use(AuditMiddleware(DefaultAuditConfig(audit.New(sink, audit.Config{}), log)))
+	app.All("/orders/:id", func(c *fiber.Ctx) error {
+		return c.SendStatus(status)
+	})
+	return app
+}
+
+func TestAuditMiddlewareRecordsOutcome(t *testing.T) {
+	tests := []struct {
+		status  int
+		outcome string
+	}{
+		{fiber.StatusOK, audit.OutcomeSuccess},
+		{fiber.StatusForbidden, audit.OutcomeDenied},
+		{fiber.StatusConflict, audit.OutcomeFailure},
+	}
+	for _, tt := range tests {
+		sink := &auditSink{}
+		app := auditApp(sink, &logger.Logger{Logger: zap.NewNop()}, tt.status)
+
+		resp, err := app.Test(httptest.NewRequest(fiber.MethodDelete, "/orders/7", nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+
+		if len(sink.events) != 1 {
+			t.Fatalf("status %d: events = %d", tt.status, len(sink.events))
+		}
+		event := sink.events[0]
+		if event.Outcome != tt.outcome || event.Actor.ID != "u1" || event.Resource.ID != "7" || event.Action != "delete /orders/:id" {
+			t.Errorf("status %d: unexpected event %+v", tt.status, event)
+		}
+	}
+}
+
+func TestAuditMiddlewareSkipsReads(t *testing.T) {
+	sink := &auditSink{}
+	app := auditApp(sink, &logger.Logger{Logger: zap.NewNop()}, fiber.StatusOK)
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/orders/7", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if len(sink.events) != 0 {
+		t.Errorf("events = %d, want 0", len(sink.events))
+	}
+}
+
+func TestAuditMiddlewareLogsSinkFailure(t *testing.T) {
+	core, logs := observer.New(zap.ErrorLevel)
+	app := auditApp(&auditSink{err: errors.New("sink down")}, &logger.Logger{Logger: zap.New(core)}, fiber.StatusOK)
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodPost, "/orders/7", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != fiber.StatusOK {
+		t.Errorf("status = %d, want 200", resp.StatusCode)
+	}
+	if logs.FilterMessage("Failed to record audit event").Len() != 1 {
+		t.Errorf("logs = %v", logs.All())
+	}
+}
+
+func TestAuditMiddlewareRequiresLogger(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic without logger")
+		}
+	}()
+	AuditMiddleware(DefaultAuditConfig(audit.New(&auditSink{}, audit.Config{}), nil))
+}
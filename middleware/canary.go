@@ -0,0 +1,117 @@
+package middleware
+
+import (
+	"hash/fnv"
+	"math/rand/v2"
+	"strconv"
+
+	"github.com/alimzhanovlr/sdk/metrics"
+	"github.com/gofiber/fiber/v2"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Canary variants
+const (
+	VariantStable = "stable"
+	VariantCanary = "canary"
+)
+
+// CanaryConfig holds canary routing configuration
+type CanaryConfig struct {
+	Name    string  // Rollout name in metrics
+	Percent float64 // Share of traffic routed to canary, 0-100
+	// Header and Cookie force a variant with value stable or canary
+	Header string
+	Cookie string
+	// Sticky returns a key assigned to the same variant on every request,
+	// e.g. user ID. Requests are assigned randomly when it is nil or empty
+	Sticky  func(c *fiber.Ctx) string
+	Metrics *metrics.Registry // Optional, records requests per variant
+}
+
+// DefaultCanaryConfig returns default canary config
+func DefaultCanaryConfig(name string, percent float64) CanaryConfig {
+	return CanaryConfig{
+		Name:    name,
+		Percent: percent,
+		Header:  "X-Canary",
+		Cookie:  "canary",
+	}
+}
+
+// CanaryMiddleware routes a share of requests to the canary handler and the
+// rest to the next handler of the route:
+//
+//	api.Get("/orders", middleware.CanaryMiddleware(cfg, v2.List), v1.List)
+//
+// The variant is stored in locals, see GetVariant
+func CanaryMiddleware(config CanaryConfig, canary fiber.Handler) fiber.Handler {
+	var requests metrics.Counter
+	if config.Metrics != nil {
+		requests = config.Metrics.Counter("http_server_canary_requests_total", "Requests per canary variant")
+	}
+
+	return func(c *fiber.Ctx) error {
+		variant := config.variant(c)
+		c.Locals("variant", variant)
+
+		var err error
+		if variant == VariantCanary {
+			err = canary(c)
+		} else {
+			err = c.Next()
+		}
+
+		if requests != nil {
+			status := c.Response().StatusCode()
+			if e, ok := err.(*fiber.Error); ok {
+				status = e.Code
+			} else if err != nil {
+				status = fiber.StatusInternalServerError
+			}
+
+			requests.Add(c.UserContext(), 1,
+				attribute.String("rollout", config.Name),
+				attribute.String("variant", variant),
+				attribute.String("status", strconv.Itoa(status)),
+			)
+		}
+		return err
+	}
+}
+
+// GetVariant returns canary variant of the request, empty outside CanaryMiddleware
+func GetVariant(c *fiber.Ctx) string {
+	variant, _ := c.Locals("variant").(string)
+	return variant
+}
+
+func (config CanaryConfig) variant(c *fiber.Ctx) string {
+	for _, forced := range []string{c.Get(config.Header), c.Cookies(config.Cookie)} {
+		if forced == VariantCanary || forced == VariantStable {
+			return forced
+		}
+	}
+
+	// Basis points keep fractional percents like 0.5
+	var bucket uint64
+	if key := config.stickyKey(c); key != "" {
+		h := fnv.New64a()
+		h.Write([]byte(config.Name + ":" + key))
+		bucket = h.Sum64() % 10000
+	} else {
+		bucket = rand.Uint64N(10000)
+	}
+
+	if float64(bucket) < config.Percent*100 {
+		return VariantCanary
+	}
+	return VariantStable
+}
+
+func (config CanaryConfig) stickyKey(c *fiber.Ctx) string {
+	if config.Sticky == nil {
+		return ""
+	}
+	return config.Sticky(c)
+}
@@ -0,0 +1,166 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/alimzhanovlr/sdk/metrics"
+	"github.com/gofiber/fiber/v2"
+)
+
+func newCanaryApp(config CanaryConfig) *fiber.App {
+	app := fiber.New()
+	app.Get("/orders", CanaryMiddleware(config, func(c *fiber.Ctx) error {
+		return c.SendString("canary:" + GetVariant(c))
+	}), func(c *fiber.Ctx) error {
+		return c.SendString("stable:" + GetVariant(c))
+	})
+	app.Get("/failing", CanaryMiddleware(config, func(c *fiber.Ctx) error {
+		return fiber.ErrServiceUnavailable
+	}), func(c *fiber.Ctx) error {
+		return c.SendString("stable")
+	})
+	return app
+}
+
+func canaryBody(t *testing.T, app *fiber.App, req *http.Request) string {
+	t.Helper()
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	return string(body)
+}
+
+func TestCanaryForcedVariant(t *testing.T) {
+	tests := []struct {
+		name    string
+		percent float64
+		header  string
+		cookie  string
+		want    string
+	}{
+		{"header canary", 0, "canary", "", "canary:canary"},
+		{"header stable", 100, "stable", "", "stable:stable"},
+		{"cookie canary", 0, "", "canary", "canary:canary"},
+		{"cookie stable", 100, "", "stable", "stable:stable"},
+		{"header wins over cookie", 0, "canary", "stable", "canary:canary"},
+		{"unknown value ignored", 0, "beta", "", "stable:stable"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newCanaryApp(DefaultCanaryConfig("orders-v2", tt.percent))
+			req := httptest.NewRequest(fiber.MethodGet, "/orders", nil)
+			if tt.header != "" {
+				req.Header.Set("X-Canary", tt.header)
+			}
+			if tt.cookie != "" {
+				req.AddCookie(&http.Cookie{Name: "canary", Value: tt.cookie})
+			}
+			if got := canaryBody(t, app, req); got != tt.want {
+				t.Errorf("body = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCanaryPercent(t *testing.T) {
+	for _, tt := range []struct {
+		percent  float64
+		min, max int
+	}{
+		{0, 0, 0},
+		{100, 1000, 1000},
+		{20, 140, 260},
+	} {
+		app := newCanaryApp(DefaultCanaryConfig("orders-v2", tt.percent))
+		canary := 0
+		for i := 0; i < 1000; i++ {
+			if strings.HasPrefix(canaryBody(t, app, httptest.NewRequest(fiber.MethodGet, "/orders", nil)), "canary") {
+				canary++
+			}
+		}
+		if canary < tt.min || canary > tt.max {
+			t.Errorf("percent %v: %d of 1000 requests routed to canary, want %d-%d", tt.percent, canary, tt.min, tt.max)
+		}
+	}
+}
+
+func TestCanarySticky(t *testing.T) {
+	config := DefaultCanaryConfig("orders-v2", 50)
+	config.Sticky = func(c *fiber.Ctx) string { return c.Get("X-User-ID") }
+	app := newCanaryApp(config)
+
+	request := func(user string) string {
+		req := httptest.NewRequest(fiber.MethodGet, "/orders", nil)
+		req.Header.Set("X-User-ID", user)
+		return canaryBody(t, app, req)
+	}
+
+	canary := 0
+	for i := 0; i < 200; i++ {
+		user := "user-" + strconv.Itoa(i)
+		first := request(user)
+		for j := 0; j < 3; j++ {
+			if got := request(user); got != first {
+				t.Fatalf("%s: got %q after %q, want the same variant", user, got, first)
+			}
+		}
+		if strings.HasPrefix(first, "canary") {
+			canary++
+		}
+	}
+	if canary < 60 || canary > 140 {
+		t.Errorf("%d of 200 users in canary, want about half", canary)
+	}
+
+	// The rollout name salts the hash, so users are reshuffled per rollout
+	other := config
+	other.Name = "payments-v2"
+	otherApp := newCanaryApp(other)
+	moved := 0
+	for i := 0; i < 200; i++ {
+		req := httptest.NewRequest(fiber.MethodGet, "/orders", nil)
+		req.Header.Set("X-User-ID", "user-"+strconv.Itoa(i))
+		if canaryBody(t, otherApp, req) != request("user-"+strconv.Itoa(i)) {
+			moved++
+		}
+	}
+	if moved == 0 {
+		t.Error("every user got the same variant in an unrelated rollout")
+	}
+}
+
+func TestCanaryMetrics(t *testing.T) {
+	reg, err := metrics.New(metrics.Config{Enabled: true, ServiceName: "test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := DefaultCanaryConfig("orders-v2", 0)
+	config.Metrics = reg
+	app := newCanaryApp(config)
+
+	canaryBody(t, app, httptest.NewRequest(fiber.MethodGet, "/orders", nil))
+	req := httptest.NewRequest(fiber.MethodGet, "/failing", nil)
+	req.Header.Set("X-Canary", "canary")
+	canaryBody(t, app, req)
+
+	scrape := httptest.NewRecorder()
+	reg.HTTPHandler().ServeHTTP(scrape, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body, _ := io.ReadAll(scrape.Body)
+	for _, want := range []string{
+		`rollout="orders-v2",status="200",variant="stable"`,
+		`rollout="orders-v2",status="503",variant="canary"`,
+	} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("metrics have no series with %s:\n%s", want, body)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package middleware
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// CoalesceConfig holds request coalescing configuration
+type CoalesceConfig struct {
+	// Subject identifies the caller, responses are shared only between
+	// requests of the same subject. An empty subject disables coalescing for
+	// the request. Defaults to the Authorization and Cookie headers; return
+	// a constant for public endpoints to coalesce anonymous requests
+	Subject func(c *fiber.Ctx) string
+	// Next skips coalescing when it returns true
+	Next func(c *fiber.Ctx) bool
+}
+
+// DefaultCoalesceConfig returns default coalescing config
+func DefaultCoalesceConfig() CoalesceConfig {
+	return CoalesceConfig{
+		Subject: func(c *fiber.Ctx) string {
+			auth, cookie := c.Get(fiber.HeaderAuthorization), c.Get(fiber.HeaderCookie)
+			if auth == "" && cookie == "" {
+				return ""
+			}
+			return auth + "\x00" + cookie
+		},
+	}
+}
+
+// coalescedResponse is a response captured from the leading request
+type coalescedResponse struct {
+	done    chan struct{}
+	status  int
+	headers [][2]string
+	body    []byte
+	err     error
+	// shared is false for streamed bodies, waiting requests run the handler themselves
+	shared bool
+}
+
+// CoalesceMiddleware merges concurrent identical GET requests: the first one
+// runs the handler, the others wait and receive a copy of its response.
+// Keys are method, path, query and subject; Set-Cookie is never shared
+func CoalesceMiddleware(config CoalesceConfig) fiber.Handler {
+	if config.Subject == nil {
+		config.Subject = DefaultCoalesceConfig().Subject
+	}
+
+	var (
+		mu       sync.Mutex
+		inflight = make(map[string]*coalescedResponse)
+	)
+
+	return func(c *fiber.Ctx) error {
+		if c.Method() != fiber.MethodGet || (config.Next != nil && config.Next(c)) {
+			return c.Next()
+		}
+
+		// Without a subject the caller cannot be told apart from others
+		subject := config.Subject(c)
+		if subject == "" {
+			return c.Next()
+		}
+
+		// Fiber reuses request buffers, the key must outlive this request
+		key := strings.Clone(c.OriginalURL() + "\x00" + subject)
+
+		mu.Lock()
+		if call, ok := inflight[key]; ok {
+			mu.Unlock()
+			<-call.done
+			if !call.shared {
+				return c.Next()
+			}
+			return call.replay(c)
+		}
+		call := &coalescedResponse{done: make(chan struct{})}
+		inflight[key] = call
+		mu.Unlock()
+
+		defer func() {
+			mu.Lock()
+			delete(inflight, key)
+			mu.Unlock()
+			close(call.done)
+		}()
+
+		call.err = c.Next()
+		call.capture(c)
+		return call.err
+	}
+}
+
+func (r *coalescedResponse) capture(c *fiber.Ctx) {
+	resp := c.Response()
+	if resp.IsBodyStream() {
+		return
+	}
+
+	r.status = resp.StatusCode()
+	r.body = append([]byte(nil), resp.Body()...)
+	resp.Header.VisitAll(func(key, value []byte) {
+		if string(key) == fiber.HeaderSetCookie {
+			return
+		}
+		r.headers = append(r.headers, [2]string{string(key), string(value)})
+	})
+	r.shared = true
+}
+
+func (r *coalescedResponse) replay(c *fiber.Ctx) error {
+	if r.err != nil {
+		return r.err
+	}
+
+	// Headers already set for this request, like X-Request-ID, are kept
+	own := make(map[string]bool)
+	c.Response().Header.VisitAll(func(key, _ []byte) {
+		if string(key) != fiber.HeaderContentType {
+			own[string(key)] = true
+		}
+	})
+	for _, header := range r.headers {
+		if !own[header[0]] {
+			c.Response().Header.Add(header[0], header[1])
+		}
+	}
+	c.Status(r.status)
+	return c.Send(r.body)
+}
@@ -0,0 +1,116 @@
+package middleware
+
+import (
+	"io"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// coalesceApp blocks handlers until release is closed and counts their runs
+func coalesceApp(config CoalesceConfig) (*fiber.App, *atomic.Int32, chan struct{}, chan struct{}) {
+	var calls atomic.Int32
+	started := make(chan struct{}, 16)
+	release := make(chan struct{})
+
+	app := fiber.New()
+	app.Get("/report", CoalesceMiddleware(config), func(c *fiber.Ctx) error {
+		n := calls.Add(1)
+		started <- struct{}{}
+		<-release
+		c.Set("X-Run", string(rune('0'+n)))
+		return c.SendString("report for " + c.Get(fiber.HeaderAuthorization))
+	})
+	return app, &calls, started, release
+}
+
+func concurrentGets(t *testing.T, app *fiber.App, headers []map[string]string, started, release chan struct{}, expectRuns int) []string {
+	t.Helper()
+
+	bodies := make([]string, len(headers))
+	var wg sync.WaitGroup
+	for i, h := range headers {
+		wg.Add(1)
+		go func(i int, h map[string]string) {
+			defer wg.Done()
+			req := httptest.NewRequest(fiber.MethodGet, "/report", nil)
+			for key, value := range h {
+				req.Header.Set(key, value)
+			}
+			resp, err := app.Test(req, -1)
+			if err != nil {
+				t.Errorf("request failed: %v", err)
+				return
+			}
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			bodies[i] = string(body)
+		}(i, h)
+	}
+
+	for i := 0; i < expectRuns; i++ {
+		select {
+		case <-started:
+		case <-time.After(2 * time.Second):
+			t.Errorf("handler ran %d times, want %d", i, expectRuns)
+			i = expectRuns
+		}
+	}
+	// Let the remaining requests reach the middleware before the handlers finish
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	return bodies
+}
+
+func TestCoalesceSharesResponseForSameSubject(t *testing.T) {
+	app, calls, started, release := coalesceApp(DefaultCoalesceConfig())
+	auth := map[string]string{fiber.HeaderAuthorization: "Bearer a"}
+
+	done := make(chan []string)
+	go func() {
+		// Releases after the first handler run, followers must not start another
+		done <- concurrentGets(t, app, []map[string]string{auth, auth, auth, auth}, started, release, 1)
+	}()
+
+	for _, body := range <-done {
+		if body != "report for Bearer a" {
+			t.Errorf("unexpected body %q", body)
+		}
+	}
+	if calls.Load() != 1 {
+		t.Errorf("handler ran %d times, want 1", calls.Load())
+	}
+}
+
+func TestCoalesceSeparatesSubjects(t *testing.T) {
+	app, calls, started, release := coalesceApp(DefaultCoalesceConfig())
+
+	bodies := concurrentGets(t, app, []map[string]string{
+		{fiber.HeaderAuthorization: "Bearer a"},
+		{fiber.HeaderAuthorization: "Bearer b"},
+		{fiber.HeaderCookie: "session=1"},
+		{fiber.HeaderCookie: "session=2"},
+	}, started, release, 4)
+
+	if calls.Load() != 4 {
+		t.Errorf("handler ran %d times, want 4", calls.Load())
+	}
+	if bodies[0] != "report for Bearer a" || bodies[1] != "report for Bearer b" {
+		t.Errorf("responses mixed between subjects: %q", bodies)
+	}
+}
+
+func TestCoalesceSkipsRequestsWithoutSubject(t *testing.T) {
+	app, calls, started, release := coalesceApp(DefaultCoalesceConfig())
+
+	concurrentGets(t, app, []map[string]string{{}, {}, {}}, started, release, 3)
+
+	if calls.Load() != 3 {
+		t.Errorf("anonymous requests were coalesced: handler ran %d times", calls.Load())
+	}
+}
@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"math"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ConcurrencyLimitConfig holds concurrency limiting configuration
+type ConcurrencyLimitConfig struct {
+	MaxConcurrent int           // Maximum number of requests handled at once
+	QueueSize     int           // Maximum number of requests waiting for a slot
+	QueueTimeout  time.Duration // Maximum time a request waits in queue
+	// RetryAfter is sent in Retry-After header, defaults to QueueTimeout
+	RetryAfter time.Duration
+	Message    string // Error message
+}
+
+// DefaultConcurrencyLimitConfig returns default concurrency limit config
+func DefaultConcurrencyLimitConfig() ConcurrencyLimitConfig {
+	return ConcurrencyLimitConfig{
+		MaxConcurrent: 50,
+		QueueSize:     100,
+		QueueTimeout:  5 * time.Second,
+		Message:       "Server is busy, please try again later",
+	}
+}
+
+// ConcurrencyLimitMiddleware limits requests handled at once by the routes it
+// is attached to. Excess requests wait in a bounded queue and are rejected with
+// 429 and Retry-After when the queue is full or the wait times out. Use one
+// instance per route group so a heavy endpoint cannot starve the others
+func ConcurrencyLimitMiddleware(config ConcurrencyLimitConfig) fiber.Handler {
+	defaults := DefaultConcurrencyLimitConfig()
+	if config.MaxConcurrent <= 0 {
+		config.MaxConcurrent = defaults.MaxConcurrent
+	}
+	if config.Message == "" {
+		config.Message = defaults.Message
+	}
+	if config.RetryAfter <= 0 {
+		config.RetryAfter = config.QueueTimeout
+	}
+	retryAfter := strconv.Itoa(int(math.Max(1, math.Ceil(config.RetryAfter.Seconds()))))
+
+	slots := make(chan struct{}, config.MaxConcurrent)
+	var queued atomic.Int64
+
+	reject := func(c *fiber.Ctx) error {
+		c.Set(fiber.HeaderRetryAfter, retryAfter)
+		return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
+			"error": fiber.Map{
+				"code":    "concurrency_limit_exceeded",
+				"message": config.Message,
+			},
+		})
+	}
+
+	return func(c *fiber.Ctx) error {
+		select {
+		case slots <- struct{}{}:
+		default:
+			if queued.Add(1) > int64(config.QueueSize) || config.QueueTimeout <= 0 {
+				queued.Add(-1)
+				return reject(c)
+			}
+
+			timer := time.NewTimer(config.QueueTimeout)
+			select {
+			case slots <- struct{}{}:
+				timer.Stop()
+				queued.Add(-1)
+			case <-timer.C:
+				queued.Add(-1)
+				return reject(c)
+			}
+		}
+		defer func() { <-slots }()
+
+		return c.Next()
+	}
+}
@@ -0,0 +1,144 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// newConcurrencyApp serves /slow, blocking until release is closed, and /fast
+func newConcurrencyApp(config ConcurrencyLimitConfig) (app *fiber.App, started chan struct{}, release chan struct{}) {
+	started = make(chan struct{}, 10)
+	release = make(chan struct{})
+	app = fiber.New()
+	app.Use(ConcurrencyLimitMiddleware(config))
+	app.Get("/slow", func(c *fiber.Ctx) error {
+		started <- struct{}{}
+		<-release
+		return c.SendString("slow")
+	})
+	app.Get("/fast", func(c *fiber.Ctx) error { return c.SendString("fast") })
+	app.Get("/fail", func(c *fiber.Ctx) error { return errors.New("boom") })
+	return app, started, release
+}
+
+func concurrencyRequest(t *testing.T, app *fiber.App, path string) *http.Response {
+	t.Helper()
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, path, nil), -1)
+	if err != nil {
+		t.Error(err)
+		return nil
+	}
+	resp.Body.Close()
+	return resp
+}
+
+func TestConcurrencyLimitRejectsWithoutQueue(t *testing.T) {
+	app, started, release := newConcurrencyApp(ConcurrencyLimitConfig{
+		MaxConcurrent: 1,
+		RetryAfter:    3 * time.Second,
+	})
+
+	done := make(chan int)
+	go func() { done <- concurrencyRequest(t, app, "/slow").StatusCode }()
+	<-started
+
+	resp := concurrencyRequest(t, app, "/fast")
+	if resp.StatusCode != fiber.StatusTooManyRequests {
+		t.Errorf("status = %d, want 429 while the slot is taken", resp.StatusCode)
+	}
+	if got := resp.Header.Get(fiber.HeaderRetryAfter); got != "3" {
+		t.Errorf("Retry-After = %q, want 3", got)
+	}
+
+	close(release)
+	if status := <-done; status != fiber.StatusOK {
+		t.Errorf("slow request: status = %d", status)
+	}
+	if resp := concurrencyRequest(t, app, "/fast"); resp.StatusCode != fiber.StatusOK {
+		t.Errorf("status = %d after the slot was released", resp.StatusCode)
+	}
+}
+
+func TestConcurrencyLimitQueuesUntilSlotFrees(t *testing.T) {
+	app, started, release := newConcurrencyApp(ConcurrencyLimitConfig{
+		MaxConcurrent: 1,
+		QueueSize:     1,
+		QueueTimeout:  5 * time.Second,
+	})
+
+	slow := make(chan int)
+	go func() { slow <- concurrencyRequest(t, app, "/slow").StatusCode }()
+	<-started
+
+	queued := make(chan int)
+	go func() { queued <- concurrencyRequest(t, app, "/fast").StatusCode }()
+
+	select {
+	case status := <-queued:
+		t.Fatalf("queued request finished with %d while the slot was taken", status)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// The queue holds one request, the next one is rejected immediately
+	if resp := concurrencyRequest(t, app, "/fast"); resp.StatusCode != fiber.StatusTooManyRequests {
+		t.Errorf("status = %d with a full queue, want 429", resp.StatusCode)
+	}
+
+	close(release)
+	if status := <-slow; status != fiber.StatusOK {
+		t.Errorf("slow request: status = %d", status)
+	}
+	if status := <-queued; status != fiber.StatusOK {
+		t.Errorf("queued request: status = %d, want 200", status)
+	}
+}
+
+func TestConcurrencyLimitQueueTimeout(t *testing.T) {
+	app, started, release := newConcurrencyApp(ConcurrencyLimitConfig{
+		MaxConcurrent: 1,
+		QueueSize:     1,
+		QueueTimeout:  20 * time.Millisecond,
+	})
+
+	slow := make(chan struct{})
+	go func() {
+		concurrencyRequest(t, app, "/slow")
+		close(slow)
+	}()
+	defer func() {
+		close(release)
+		<-slow
+	}()
+	<-started
+
+	start := time.Now()
+	resp := concurrencyRequest(t, app, "/fast")
+	if resp.StatusCode != fiber.StatusTooManyRequests {
+		t.Errorf("status = %d, want 429 after the queue timeout", resp.StatusCode)
+	}
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+		t.Errorf("rejected after %s, want to wait for the queue timeout", elapsed)
+	}
+	// Retry-After defaults to the queue timeout, rounded up to a second
+	if got := resp.Header.Get(fiber.HeaderRetryAfter); got != "1" {
+		t.Errorf("Retry-After = %q, want 1", got)
+	}
+}
+
+func TestConcurrencyLimitReleasesSlotOnError(t *testing.T) {
+	app, _, _ := newConcurrencyApp(ConcurrencyLimitConfig{MaxConcurrent: 1})
+
+	for i := 0; i < 3; i++ {
+		if resp := concurrencyRequest(t, app, "/fail"); resp.StatusCode != fiber.StatusInternalServerError {
+			t.Fatalf("request %d: status = %d, want 500", i, resp.StatusCode)
+		}
+	}
+	if resp := concurrencyRequest(t, app, "/fast"); resp.StatusCode != fiber.StatusOK {
+		t.Errorf("status = %d, failed requests must release their slots", resp.StatusCode)
+	}
+}
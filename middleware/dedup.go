@@ -0,0 +1,108 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// DedupConfig holds webhook deduplication configuration
+type DedupConfig struct {
+	Store DedupStore    // Seen payloads, defaults to in-memory store
+	TTL   time.Duration // Window in which a redelivery is a duplicate
+	// InFlightTTL bounds how long a request is considered in flight, so a
+	// crashed instance does not block redeliveries for the whole TTL
+	InFlightTTL time.Duration
+	// RetryAfter is sent with 503 to redeliveries of a request in flight
+	RetryAfter time.Duration
+	// Key returns the deduplication key, defaults to SHA-256 of method, path
+	// and body. Use the provider delivery ID header when it has one
+	Key func(c *fiber.Ctx) string
+}
+
+// DefaultDedupConfig returns default deduplication config
+func DefaultDedupConfig() DedupConfig {
+	return DedupConfig{
+		TTL:         24 * time.Hour,
+		InFlightTTL: time.Minute,
+		RetryAfter:  5 * time.Second,
+	}
+}
+
+// DedupMiddleware drops requests seen within TTL and answers them with 200,
+// so aggressive redeliveries are acknowledged without being processed again.
+// A redelivery that arrives while the first attempt is still running gets 503
+// with Retry-After, because the first attempt may still fail. A request that
+// fails with an error or a non-2xx status is forgotten, so the provider
+// redelivery is processed
+func DedupMiddleware(config DedupConfig) fiber.Handler {
+	defaults := DefaultDedupConfig()
+	if config.Store == nil {
+		config.Store = NewMemoryDedupStore()
+	}
+	if config.TTL <= 0 {
+		config.TTL = defaults.TTL
+	}
+	if config.InFlightTTL <= 0 {
+		config.InFlightTTL = defaults.InFlightTTL
+	}
+	if config.RetryAfter <= 0 {
+		config.RetryAfter = defaults.RetryAfter
+	}
+	if config.Key == nil {
+		config.Key = payloadHash
+	}
+	retryAfter := strconv.Itoa(int(math.Max(1, math.Ceil(config.RetryAfter.Seconds()))))
+
+	return func(c *fiber.Ctx) error {
+		ctx := c.UserContext()
+		key := config.Key(c)
+
+		state, err := config.Store.Claim(ctx, key, config.InFlightTTL)
+		if err != nil {
+			return fmt.Errorf("failed to check duplicate: %w", err)
+		}
+		switch state {
+		case DedupDone:
+			return c.Status(fiber.StatusOK).JSON(fiber.Map{
+				"status": "duplicate",
+			})
+		case DedupInFlight:
+			c.Set(fiber.HeaderRetryAfter, retryAfter)
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+				"error": fiber.Map{
+					"code":    "duplicate_in_flight",
+					"message": "The same request is being processed, please retry later",
+				},
+			})
+		}
+
+		err = c.Next()
+		if status := c.Response().StatusCode(); err != nil || status < 200 || status >= 300 {
+			if removeErr := config.Store.Remove(ctx, key); removeErr != nil && err == nil {
+				return fmt.Errorf("failed to forget failed request: %w", removeErr)
+			}
+			return err
+		}
+
+		if err := config.Store.Complete(ctx, key, config.TTL); err != nil {
+			return fmt.Errorf("failed to mark request done: %w", err)
+		}
+		return nil
+	}
+}
+
+func payloadHash(c *fiber.Ctx) string {
+	h := sha256.New()
+	h.Write([]byte(c.Method()))
+	h.Write([]byte{0})
+	h.Write([]byte(c.Path()))
+	h.Write([]byte{0})
+	h.Write(c.Body())
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,150 @@
+package middleware
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// DedupState is the state of a deduplication key
+type DedupState int
+
+const (
+	// DedupNew means the key was not stored and is now claimed by the caller
+	DedupNew DedupState = iota
+	// DedupInFlight means another request claimed the key and has not finished
+	DedupInFlight
+	// DedupDone means a request with the key has been processed
+	DedupDone
+)
+
+// DedupStore remembers keys for a TTL window
+type DedupStore interface {
+	// Claim stores key as in flight for ttl if it is not stored and returns
+	// the state before the call, DedupNew if the caller claimed the key
+	Claim(ctx context.Context, key string, ttl time.Duration) (DedupState, error)
+	// Complete stores key as done for ttl
+	Complete(ctx context.Context, key string, ttl time.Duration) error
+	// Remove forgets key so it can be claimed again
+	Remove(ctx context.Context, key string) error
+}
+
+type dedupEntry struct {
+	state   DedupState
+	expires time.Time
+}
+
+// MemoryDedupStore is DedupStore for a single instance
+type MemoryDedupStore struct {
+	mu        sync.Mutex
+	keys      map[string]dedupEntry
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// NewMemoryDedupStore creates in-memory DedupStore
+func NewMemoryDedupStore() *MemoryDedupStore {
+	return &MemoryDedupStore{
+		keys:      make(map[string]dedupEntry),
+		lastSweep: time.Now(),
+		now:       time.Now,
+	}
+}
+
+// Claim implements DedupStore
+func (s *MemoryDedupStore) Claim(_ context.Context, key string, ttl time.Duration) (DedupState, error) {
+	now := s.now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Expired keys are swept at most once a minute
+	if now.Sub(s.lastSweep) > time.Minute {
+		for k, entry := range s.keys {
+			if now.After(entry.expires) {
+				delete(s.keys, k)
+			}
+		}
+		s.lastSweep = now
+	}
+
+	if entry, ok := s.keys[key]; ok && now.Before(entry.expires) {
+		return entry.state, nil
+	}
+	s.keys[key] = dedupEntry{state: DedupInFlight, expires: now.Add(ttl)}
+	return DedupNew, nil
+}
+
+// Complete implements DedupStore
+func (s *MemoryDedupStore) Complete(_ context.Context, key string, ttl time.Duration) error {
+	now := s.now()
+
+	s.mu.Lock()
+	s.keys[key] = dedupEntry{state: DedupDone, expires: now.Add(ttl)}
+	s.mu.Unlock()
+	return nil
+}
+
+// Remove implements DedupStore
+func (s *MemoryDedupStore) Remove(_ context.Context, key string) error {
+	s.mu.Lock()
+	delete(s.keys, key)
+	s.mu.Unlock()
+	return nil
+}
+
+// Values stored by RedisDedupStore
+const (
+	redisDedupInFlight = "in_flight"
+	redisDedupDone     = "done"
+)
+
+// claimScript returns the stored value or claims the key and returns ""
+var claimScript = redis.NewScript(`
+local value = redis.call("GET", KEYS[1])
+if value then
+	return value
+end
+redis.call("SET", KEYS[1], ARGV[1], "PX", ARGV[2])
+return ""
+`)
+
+// RedisDedupStore is DedupStore shared by all instances
+type RedisDedupStore struct {
+	client redis.UniversalClient
+	prefix string
+}
+
+// NewRedisDedupStore creates Redis DedupStore, keys are stored under prefix
+func NewRedisDedupStore(client redis.UniversalClient, prefix string) *RedisDedupStore {
+	return &RedisDedupStore{client: client, prefix: prefix}
+}
+
+// Claim implements DedupStore
+func (s *RedisDedupStore) Claim(ctx context.Context, key string, ttl time.Duration) (DedupState, error) {
+	value, err := claimScript.Run(ctx, s.client, []string{s.prefix + key}, redisDedupInFlight, ttl.Milliseconds()).Text()
+	if err != nil {
+		return 0, err
+	}
+
+	switch value {
+	case "":
+		return DedupNew, nil
+	case redisDedupInFlight:
+		return DedupInFlight, nil
+	default:
+		return DedupDone, nil
+	}
+}
+
+// Complete implements DedupStore
+func (s *RedisDedupStore) Complete(ctx context.Context, key string, ttl time.Duration) error {
+	return s.client.Set(ctx, s.prefix+key, redisDedupDone, ttl).Err()
+}
+
+// Remove implements DedupStore
+func (s *RedisDedupStore) Remove(ctx context.Context, key string) error {
+	return s.client.Del(ctx, s.prefix+key).Err()
+}
@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func testDedupStore(t *testing.T, store DedupStore, advance func(time.Duration)) {
+	t.Helper()
+	ctx := context.Background()
+
+	claim := func(key string, ttl time.Duration, want DedupState) {
+		t.Helper()
+		state, err := store.Claim(ctx, key, ttl)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if state != want {
+			t.Errorf("Claim(%s) = %v, want %v", key, state, want)
+		}
+	}
+
+	claim("a", time.Minute, DedupNew)
+	claim("a", time.Minute, DedupInFlight)
+
+	if err := store.Complete(ctx, "a", time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	claim("a", time.Minute, DedupDone)
+
+	// Done keys outlive the in-flight TTL
+	advance(2 * time.Minute)
+	claim("a", time.Minute, DedupDone)
+
+	claim("b", time.Minute, DedupNew)
+	if err := store.Remove(ctx, "b"); err != nil {
+		t.Fatal(err)
+	}
+	claim("b", time.Minute, DedupNew)
+
+	// An abandoned claim expires
+	advance(2 * time.Minute)
+	claim("b", time.Minute, DedupNew)
+
+	advance(2 * time.Hour)
+	claim("a", time.Minute, DedupNew)
+}
+
+func TestMemoryDedupStore(t *testing.T) {
+	now := time.Now()
+	store := NewMemoryDedupStore()
+	store.now = func() time.Time { return now }
+
+	testDedupStore(t, store, func(d time.Duration) { now = now.Add(d) })
+
+	now = now.Add(2 * time.Minute)
+	store.Claim(context.Background(), "c", time.Minute)
+	if len(store.keys) != 1 {
+		t.Errorf("expired keys are not swept: %d stored", len(store.keys))
+	}
+}
+
+func TestRedisDedupStore(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer client.Close()
+
+	store := NewRedisDedupStore(client, "webhook:")
+	testDedupStore(t, store, server.FastForward)
+
+	if !server.Exists("webhook:a") {
+		t.Error("keys must be stored under prefix")
+	}
+}
@@ -0,0 +1,118 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func dedupRequest(t *testing.T, app *fiber.App, body string) *http.Response {
+	t.Helper()
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodPost, "/hook", strings.NewReader(body)), -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp
+}
+
+func TestDedupAcknowledgesProcessedRedelivery(t *testing.T) {
+	calls := 0
+	app := fiber.New()
+	app.Post("/hook", DedupMiddleware(DefaultDedupConfig()), func(c *fiber.Ctx) error {
+		calls++
+		return c.SendString("processed")
+	})
+
+	for i, want := range []string{"processed", `{"status":"duplicate"}`} {
+		resp := dedupRequest(t, app, `{"id":"evt_1"}`)
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != fiber.StatusOK || string(body) != want {
+			t.Errorf("delivery %d: %d %s, want 200 %s", i, resp.StatusCode, body, want)
+		}
+	}
+
+	dedupRequest(t, app, `{"id":"evt_2"}`).Body.Close()
+	if calls != 2 {
+		t.Errorf("handler calls = %d, want 2", calls)
+	}
+}
+
+func TestDedupRejectsRedeliveryInFlight(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan int)
+	calls := 0
+
+	app := fiber.New()
+	app.Post("/hook", DedupMiddleware(DedupConfig{RetryAfter: 3 * time.Second}), func(c *fiber.Ctx) error {
+		calls++
+		if calls == 1 {
+			close(started)
+			return c.SendStatus(<-release)
+		}
+		return c.SendStatus(fiber.StatusOK)
+	})
+
+	first := make(chan int)
+	go func() {
+		resp, err := app.Test(httptest.NewRequest(fiber.MethodPost, "/hook", strings.NewReader("evt")), -1)
+		if err != nil {
+			first <- 0
+			return
+		}
+		resp.Body.Close()
+		first <- resp.StatusCode
+	}()
+	<-started
+
+	resp := dedupRequest(t, app, "evt")
+	resp.Body.Close()
+	if resp.StatusCode != fiber.StatusServiceUnavailable {
+		t.Errorf("redelivery in flight: status = %d, want 503", resp.StatusCode)
+	}
+	if got := resp.Header.Get(fiber.HeaderRetryAfter); got != "3" {
+		t.Errorf("Retry-After = %q, want 3", got)
+	}
+
+	// The first attempt fails, so the next redelivery must be processed
+	release <- fiber.StatusInternalServerError
+	if status := <-first; status != fiber.StatusInternalServerError {
+		t.Fatalf("first attempt: status = %d", status)
+	}
+
+	resp = dedupRequest(t, app, "evt")
+	resp.Body.Close()
+	if resp.StatusCode != fiber.StatusOK || calls != 2 {
+		t.Errorf("redelivery after failure: status = %d, calls = %d", resp.StatusCode, calls)
+	}
+}
+
+func TestDedupForgetsHandlerError(t *testing.T) {
+	fail := true
+	app := fiber.New()
+	app.Post("/hook", DedupMiddleware(DefaultDedupConfig()), func(c *fiber.Ctx) error {
+		if fail {
+			fail = false
+			return fiber.ErrBadGateway
+		}
+		return c.SendString("processed")
+	})
+
+	resp := dedupRequest(t, app, "evt")
+	resp.Body.Close()
+	if resp.StatusCode != fiber.StatusBadGateway {
+		t.Fatalf("first attempt: status = %d", resp.StatusCode)
+	}
+
+	resp = dedupRequest(t, app, "evt")
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "processed" {
+		t.Errorf("redelivery after error: %s", body)
+	}
+}
@@ -0,0 +1,122 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alimzhanovlr/sdk/metrics"
+	"github.com/gofiber/fiber/v2"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Deprecation describes a deprecated route
+type Deprecation struct {
+	// Since is when the route was deprecated, Deprecation header (RFC 9745)
+	Since time.Time
+	// Sunset is when the route stops working, Sunset header (RFC 8594)
+	Sunset time.Time
+	// Link points to migration docs or the successor route
+	Link string
+	// Message is a notice for clients in Warning and X-Deprecation headers
+	Message string
+}
+
+// DeprecationConfig holds deprecated routes
+type DeprecationConfig struct {
+	// Routes are keyed by method and route as registered, e.g.
+	// "GET /api/v1/users/:id", method "*" matches any method
+	Routes  map[string]Deprecation
+	Metrics *metrics.Registry // Optional, records requests to deprecated routes
+}
+
+// DeprecationMiddleware adds deprecation headers to successful responses of
+// the configured routes. Install it globally, the route is known after the
+// handler ran
+func DeprecationMiddleware(config DeprecationConfig) fiber.Handler {
+	requests := deprecatedRequests(config.Metrics)
+
+	return func(c *fiber.Ctx) error {
+		err := c.Next()
+		if err != nil || c.Response().StatusCode() >= fiber.StatusBadRequest {
+			return err
+		}
+
+		route := c.Route().Path
+		d, ok := config.Routes[c.Method()+" "+route]
+		if !ok {
+			d, ok = config.Routes["* "+route]
+		}
+		if ok {
+			setDeprecationHeaders(c, d)
+			recordDeprecated(c, requests)
+		}
+		return nil
+	}
+}
+
+// Deprecated marks a single route deprecated:
+//
+//	api.Get("/v1/users/:id", middleware.Deprecated(middleware.Deprecation{
+//		Sunset: time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC),
+//		Link:   "https://docs.example.com/migrate-v2",
+//	}), h.Get)
+func Deprecated(d Deprecation) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		err := c.Next()
+		if err == nil && c.Response().StatusCode() < fiber.StatusBadRequest {
+			setDeprecationHeaders(c, d)
+		}
+		return err
+	}
+}
+
+// AddWarning adds a Warning header with code 299, e.g. when a handler
+// ignores a deprecated request field. Quotes of text are escaped
+func AddWarning(c *fiber.Ctx, text string) {
+	text = strings.ReplaceAll(text, `\`, `\\`)
+	text = strings.ReplaceAll(text, `"`, `\"`)
+	c.Response().Header.Add(fiber.HeaderWarning, `299 - "`+text+`"`)
+}
+
+func setDeprecationHeaders(c *fiber.Ctx, d Deprecation) {
+	if d.Since.IsZero() {
+		c.Set("Deprecation", "true")
+	} else {
+		c.Set("Deprecation", "@"+strconv.FormatInt(d.Since.Unix(), 10))
+	}
+	if !d.Sunset.IsZero() {
+		c.Set("Sunset", d.Sunset.UTC().Format(http.TimeFormat))
+	}
+	if d.Link != "" {
+		c.Response().Header.Add(fiber.HeaderLink, "<"+d.Link+`>; rel="deprecation"`)
+	}
+
+	message := d.Message
+	if message == "" {
+		message = "This endpoint is deprecated"
+		if !d.Sunset.IsZero() {
+			message += " and will be removed after " + d.Sunset.UTC().Format(time.DateOnly)
+		}
+	}
+	c.Set("X-Deprecation", message)
+	AddWarning(c, message)
+}
+
+func deprecatedRequests(reg *metrics.Registry) metrics.Counter {
+	if reg == nil {
+		return nil
+	}
+	return reg.Counter("http_server_deprecated_requests_total", "Requests to deprecated routes")
+}
+
+func recordDeprecated(c *fiber.Ctx, requests metrics.Counter) {
+	if requests == nil {
+		return
+	}
+	requests.Add(c.UserContext(), 1,
+		attribute.String("method", c.Method()),
+		attribute.String("route", c.Route().Path),
+	)
+}
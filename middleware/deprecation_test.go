@@ -0,0 +1,105 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alimzhanovlr/sdk/metrics"
+	"github.com/gofiber/fiber/v2"
+)
+
+func deprecationResponse(t *testing.T, app *fiber.App, method, path string) *http.Response {
+	t.Helper()
+	resp, err := app.Test(httptest.NewRequest(method, path, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	return resp
+}
+
+func TestDeprecationMiddleware(t *testing.T) {
+	reg, err := metrics.New(metrics.Config{Enabled: true, ServiceName: "test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	since := time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)
+	sunset := time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC)
+
+	app := fiber.New()
+	app.Use(DeprecationMiddleware(DeprecationConfig{
+		Routes: map[string]Deprecation{
+			"GET /v1/users/:id": {Since: since, Sunset: sunset, Link: "https://docs.example.com/v2"},
+			"* /v1/legacy":      {Message: `Use "/v2/modern"`},
+		},
+		Metrics: reg,
+	}))
+	ok := func(c *fiber.Ctx) error { return c.SendString("ok") }
+	app.Get("/v1/users/:id", ok)
+	app.Delete("/v1/users/:id", ok)
+	app.Post("/v1/legacy", ok)
+	app.Get("/v1/broken", func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusNotFound) })
+
+	resp := deprecationResponse(t, app, fiber.MethodGet, "/v1/users/42")
+	headers := map[string]string{
+		"Deprecation":   "@1736899200",
+		"Sunset":        "Wed, 31 Dec 2025 00:00:00 GMT",
+		"Link":          `<https://docs.example.com/v2>; rel="deprecation"`,
+		"X-Deprecation": "This endpoint is deprecated and will be removed after 2025-12-31",
+		"Warning":       `299 - "This endpoint is deprecated and will be removed after 2025-12-31"`,
+	}
+	for name, want := range headers {
+		if got := resp.Header.Get(name); got != want {
+			t.Errorf("%s = %q, want %q", name, got, want)
+		}
+	}
+
+	if resp := deprecationResponse(t, app, fiber.MethodDelete, "/v1/users/42"); resp.Header.Get("Deprecation") != "" {
+		t.Error("route deprecated for GET only has headers on DELETE")
+	}
+
+	resp = deprecationResponse(t, app, fiber.MethodPost, "/v1/legacy")
+	if resp.Header.Get("Deprecation") != "true" || resp.Header.Get("Warning") != `299 - "Use \"/v2/modern\""` {
+		t.Errorf("wildcard method headers = %v", resp.Header)
+	}
+
+	if resp := deprecationResponse(t, app, fiber.MethodGet, "/v1/broken"); resp.Header.Get("Deprecation") != "" {
+		t.Error("error response has deprecation headers")
+	}
+
+	scrape := httptest.NewRecorder()
+	reg.HTTPHandler().ServeHTTP(scrape, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body, _ := io.ReadAll(scrape.Body)
+	if !strings.Contains(string(body), `http_server_deprecated_requests_total{`) ||
+		!strings.Contains(string(body), `route="/v1/users/:id"`) {
+		t.Errorf("deprecated requests are not counted:\n%s", body)
+	}
+}
+
+func TestDeprecatedRoute(t *testing.T) {
+	app := fiber.New()
+	app.Get("/v1/orders", Deprecated(Deprecation{Link: "https://docs.example.com/orders"}), func(c *fiber.Ctx) error {
+		AddWarning(c, `field "sort" is ignored`)
+		return c.SendString("ok")
+	})
+	app.Get("/v1/failing", Deprecated(Deprecation{}), func(c *fiber.Ctx) error {
+		return fiber.ErrBadRequest
+	})
+
+	resp := deprecationResponse(t, app, fiber.MethodGet, "/v1/orders")
+	if resp.Header.Get("Deprecation") != "true" || resp.Header.Get("X-Deprecation") != "This endpoint is deprecated" {
+		t.Errorf("headers = %v", resp.Header)
+	}
+	warnings := resp.Header.Values("Warning")
+	if len(warnings) != 2 || warnings[0] != `299 - "field \"sort\" is ignored"` {
+		t.Errorf("Warning = %q, want the handler warning and the deprecation", warnings)
+	}
+
+	if resp := deprecationResponse(t, app, fiber.MethodGet, "/v1/failing"); resp.Header.Get("Deprecation") != "" {
+		t.Error("failed request has deprecation headers")
+	}
+}
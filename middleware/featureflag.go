@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"github.com/alimzhanovlr/sdk/featureflag"
+	"github.com/alimzhanovlr/sdk/tenancy"
+	"github.com/gofiber/fiber/v2"
+)
+
+// FeatureFlagsMiddleware stores a flag snapshot in the user context, so
+// featureflag.Enabled returns the same value for the whole request. attrs
+// defaults to the subject (SubjectKey) as user and the context tenant,
+// must run after auth and tenant middleware
+func FeatureFlagsMiddleware(engine *featureflag.Engine, attrs func(c *fiber.Ctx) featureflag.Attributes) fiber.Handler {
+	if attrs == nil {
+		attrs = DefaultFlagAttributes
+	}
+
+	return func(c *fiber.Ctx) error {
+		snapshot := engine.Snapshot(attrs(c))
+		c.SetUserContext(featureflag.WithSnapshot(c.UserContext(), snapshot))
+		return c.Next()
+	}
+}
+
+// DefaultFlagAttributes returns user and tenant attributes of the request
+func DefaultFlagAttributes(c *fiber.Ctx) featureflag.Attributes {
+	attrs := make(featureflag.Attributes, 2)
+	if subject, ok := c.Locals(SubjectKey).(string); ok && subject != "" {
+		attrs[featureflag.AttributeUser] = subject
+	}
+	if tenant := tenancy.ID(c.UserContext()); tenant != "" {
+		attrs[featureflag.AttributeTenant] = tenant
+	}
+	return attrs
+}
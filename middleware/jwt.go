@@ -0,0 +1,141 @@
+package middleware
+
+import (
+	stderrors "errors"
+	"net/http"
+	"strings"
+
+	"github.com/alimzhanovlr/sdk/auth"
+	"github.com/alimzhanovlr/sdk/errors"
+	"github.com/gofiber/fiber/v2"
+)
+
+// ClaimsKey is the locals key JWTMiddleware stores *auth.Claims under
+const ClaimsKey = "claims"
+
+// JWT errors
+var (
+	ErrTokenMissing = errors.New("token_missing", "Authorization token is missing", http.StatusUnauthorized)
+	ErrTokenInvalid = errors.New("token_invalid", "Authorization token is invalid", http.StatusUnauthorized)
+	ErrTokenExpired = errors.New("token_expired", "Authorization token has expired", http.StatusUnauthorized)
+	ErrForbidden    = errors.New("forbidden", "Insufficient permissions", http.StatusForbidden)
+)
+
+// TokenVerifier verifies tokens, *auth.Tokens implements it
+type TokenVerifier interface {
+	Verify(token string) (*auth.Claims, error)
+}
+
+// JWTConfig holds JWT authentication configuration
+type JWTConfig struct {
+	Verifier TokenVerifier
+	// Extractor returns the raw token, defaults to the Bearer token of the
+	// Authorization header
+	Extractor func(c *fiber.Ctx) string
+	// Optional lets requests without a token through, invalid tokens are
+	// still rejected
+	Optional bool
+	// ErrorHandler writes the authentication error, defaults to JSON with 401
+	ErrorHandler func(c *fiber.Ctx, err *errors.AppError) error
+}
+
+// DefaultJWTConfig returns config reading Bearer tokens
+func DefaultJWTConfig(verifier TokenVerifier) JWTConfig {
+	return JWTConfig{
+		Verifier:  verifier,
+		Extractor: TokenFromHeader(fiber.HeaderAuthorization, "Bearer"),
+	}
+}
+
+// JWTMiddleware verifies the token and stores claims in locals (ClaimsKey),
+// the subject under SubjectKey and claims in the user context for usecases
+// (auth.ClaimsFromContext)
+func JWTMiddleware(config JWTConfig) fiber.Handler {
+	if config.Extractor == nil {
+		config.Extractor = TokenFromHeader(fiber.HeaderAuthorization, "Bearer")
+	}
+	if config.ErrorHandler == nil {
+		config.ErrorHandler = func(c *fiber.Ctx, err *errors.AppError) error {
+			c.Set(fiber.HeaderWWWAuthenticate, `Bearer error="invalid_token"`)
+			return sendAppError(c, err)
+		}
+	}
+
+	return func(c *fiber.Ctx) error {
+		token := config.Extractor(c)
+		if token == "" {
+			if config.Optional {
+				return c.Next()
+			}
+			return config.ErrorHandler(c, ErrTokenMissing)
+		}
+
+		claims, err := config.Verifier.Verify(token)
+		if err != nil {
+			if stderrors.Is(err, auth.ErrTokenExpired) {
+				return config.ErrorHandler(c, ErrTokenExpired)
+			}
+			return config.ErrorHandler(c, ErrTokenInvalid)
+		}
+
+		c.Locals(ClaimsKey, claims)
+		c.Locals(SubjectKey, claims.Subject)
+		c.SetUserContext(auth.WithClaims(c.UserContext(), claims))
+		return c.Next()
+	}
+}
+
+// RequireRoles rejects requests whose claims have none of roles, must run
+// after JWTMiddleware
+func RequireRoles(roles ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		claims := Claims(c)
+		if claims == nil {
+			return sendAppError(c, ErrTokenMissing)
+		}
+		for _, role := range roles {
+			if claims.HasRole(role) {
+				return c.Next()
+			}
+		}
+		return sendAppError(c, ErrForbidden)
+	}
+}
+
+// Claims returns claims verified by JWTMiddleware, nil without a token
+func Claims(c *fiber.Ctx) *auth.Claims {
+	claims, _ := c.Locals(ClaimsKey).(*auth.Claims)
+	return claims
+}
+
+// sendAppError writes err as JSON, the server error handler turns errors
+// other than fiber.Error into 500
+func sendAppError(c *fiber.Ctx, err *errors.AppError) error {
+	return c.Status(err.StatusCode).JSON(fiber.Map{
+		"error": fiber.Map{
+			"code":    err.Code,
+			"message": err.Message,
+		},
+	})
+}
+
+// TokenFromHeader extracts a token from header, after scheme when set
+func TokenFromHeader(header, scheme string) func(c *fiber.Ctx) string {
+	return func(c *fiber.Ctx) string {
+		value := strings.TrimSpace(c.Get(header))
+		if scheme == "" {
+			return value
+		}
+		if len(value) > len(scheme) && strings.EqualFold(value[:len(scheme)], scheme) && value[len(scheme)] == ' ' {
+			return strings.TrimSpace(value[len(scheme)+1:])
+		}
+		return ""
+	}
+}
+
+// TokenFromCookie extracts a token from cookie
+func TokenFromCookie(name string) func(c *fiber.Ctx) string {
+	return func(c *fiber.Ctx) string {
+		return c.Cookies(name)
+	}
+}
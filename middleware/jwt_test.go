@@ -0,0 +1,139 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alimzhanovlr/sdk/auth"
+	"github.com/alimzhanovlr/sdk/errors"
+	"github.com/gofiber/fiber/v2"
+)
+
+func newJWTTokens(t *testing.T) *auth.Tokens {
+	t.Helper()
+	key, err := auth.NewHMACKey("k1", []byte(strings.Repeat("s", 32)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys, err := auth.NewKeySet(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return auth.New(keys, auth.Config{Issuer: "gateway", Leeway: time.Second})
+}
+
+func jwtApp(config JWTConfig, handlers ...fiber.Handler) *fiber.App {
+	app := fiber.New()
+	app.Use(JWTMiddleware(config))
+	handlers = append(handlers, func(c *fiber.Ctx) error {
+		subject := "anonymous"
+		if claims, ok := auth.ClaimsFromContext(c.UserContext()); ok && Claims(c) == claims {
+			subject, _ = c.Locals(SubjectKey).(string)
+		}
+		return c.SendString(subject)
+	})
+	app.Get("/", handlers...)
+	return app
+}
+
+func getWithToken(t *testing.T, app *fiber.App, authorization string) (int, string, string) {
+	t.Helper()
+	req := httptest.NewRequest(fiber.MethodGet, "/", nil)
+	if authorization != "" {
+		req.Header.Set(fiber.HeaderAuthorization, authorization)
+	}
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(body), resp.Header.Get(fiber.HeaderWWWAuthenticate)
+}
+
+func TestJWTMiddleware(t *testing.T) {
+	tokens := newJWTTokens(t)
+	valid, _ := tokens.Sign(auth.Claims{Subject: "user-1"})
+	expired, _ := tokens.Sign(auth.Claims{Subject: "user-1", ExpiresAt: auth.NewNumericDate(time.Now().Add(-time.Minute))})
+	otherIssuer, _ := tokens.Sign(auth.Claims{Subject: "user-1", Issuer: "evil"})
+
+	tests := []struct {
+		name          string
+		optional      bool
+		authorization string
+		wantStatus    int
+		wantBody      string
+	}{
+		{"valid", false, "Bearer " + valid, fiber.StatusOK, "user-1"},
+		{"lowercase scheme", false, "bearer " + valid, fiber.StatusOK, "user-1"},
+		{"missing", false, "", fiber.StatusUnauthorized, "token_missing"},
+		{"other scheme", false, "Basic " + valid, fiber.StatusUnauthorized, "token_missing"},
+		{"expired", false, "Bearer " + expired, fiber.StatusUnauthorized, "token_expired"},
+		{"other issuer", false, "Bearer " + otherIssuer, fiber.StatusUnauthorized, "token_invalid"},
+		{"tampered", false, "Bearer " + valid + "x", fiber.StatusUnauthorized, "token_invalid"},
+		{"optional without token", true, "", fiber.StatusOK, "anonymous"},
+		{"optional with invalid token", true, "Bearer " + otherIssuer, fiber.StatusUnauthorized, "token_invalid"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultJWTConfig(tokens)
+			config.Optional = tt.optional
+			status, body, challenge := getWithToken(t, jwtApp(config), tt.authorization)
+			if status != tt.wantStatus || !strings.Contains(body, tt.wantBody) {
+				t.Errorf("got %d %s, want %d %s", status, body, tt.wantStatus, tt.wantBody)
+			}
+			if status == fiber.StatusUnauthorized && challenge == "" {
+				t.Error("WWW-Authenticate header is not set")
+			}
+		})
+	}
+}
+
+func TestJWTMiddlewareCookieAndErrorHandler(t *testing.T) {
+	tokens := newJWTTokens(t)
+	token, _ := tokens.Sign(auth.Claims{Subject: "user-1"})
+
+	var handled string
+	app := jwtApp(JWTConfig{
+		Verifier:  tokens,
+		Extractor: TokenFromCookie("session"),
+		ErrorHandler: func(c *fiber.Ctx, err *errors.AppError) error {
+			handled = err.Code
+			return c.SendStatus(fiber.StatusTeapot)
+		},
+	})
+
+	req := httptest.NewRequest(fiber.MethodGet, "/", nil)
+	req.AddCookie(&http.Cookie{Name: "session", Value: token})
+	resp, _ := app.Test(req)
+	if resp.StatusCode != fiber.StatusOK {
+		t.Errorf("status with a cookie token = %d, want 200", resp.StatusCode)
+	}
+
+	if status, _, _ := getWithToken(t, app, "Bearer "+token); status != fiber.StatusTeapot || handled != "token_missing" {
+		t.Errorf("got %d with %q, want the custom error handler", status, handled)
+	}
+}
+
+func TestRequireRoles(t *testing.T) {
+	tokens := newJWTTokens(t)
+	admin, _ := tokens.Sign(auth.Claims{Subject: "admin-1", Roles: []string{"admin"}})
+	viewer, _ := tokens.Sign(auth.Claims{Subject: "viewer-1", Roles: []string{"viewer"}})
+
+	app := jwtApp(DefaultJWTConfig(tokens), RequireRoles("owner", "admin"))
+	if status, body, _ := getWithToken(t, app, "Bearer "+admin); status != fiber.StatusOK || body != "admin-1" {
+		t.Errorf("admin got %d %s", status, body)
+	}
+	if status, body, _ := getWithToken(t, app, "Bearer "+viewer); status != fiber.StatusForbidden || !strings.Contains(body, "forbidden") {
+		t.Errorf("viewer got %d %s, want 403", status, body)
+	}
+
+	optional := JWTConfig{Verifier: tokens, Optional: true}
+	if status, _, _ := getWithToken(t, jwtApp(optional, RequireRoles("admin")), ""); status != fiber.StatusUnauthorized {
+		t.Errorf("anonymous got %d, want 401", status)
+	}
+}
@@ -13,8 +13,9 @@ func LoggerMiddleware(log *logger.Logger) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		start := time.Now()
 
-		// Get trace ID if available
+		// Get trace and request IDs if available
 		traceID, _ := c.Locals("trace_id").(string)
+		requestID, _ := c.Locals("request_id").(string)
 
 		// Continue with request
 		err := c.Next()
@@ -34,6 +35,9 @@ func LoggerMiddleware(log *logger.Logger) fiber.Handler {
 		if traceID != "" {
 			fields = append(fields, zap.String("trace_id", traceID))
 		}
+		if requestID != "" {
+			fields = append(fields, zap.String("request_id", requestID))
+		}
 
 		if err != nil {
 			fields = append(fields, zap.Error(err))
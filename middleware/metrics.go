@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/alimzhanovlr/sdk/metrics"
+	"github.com/gofiber/fiber/v2"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// MetricsMiddleware records request count and latency
+func MetricsMiddleware(reg *metrics.Registry) fiber.Handler {
+	requests := reg.Counter("http_server_requests_total", "Total number of HTTP requests")
+	duration := reg.Histogram("http_server_request_duration_seconds", "HTTP request duration", metrics.DefaultDurationBuckets...)
+	inFlight := reg.Gauge("http_server_requests_in_flight", "Number of HTTP requests being served")
+
+	var active int64
+	scrapePath := reg.Path()
+
+	return func(c *fiber.Ctx) error {
+		if scrapePath != "" && c.Path() == scrapePath {
+			return c.Next()
+		}
+
+		start := time.Now()
+		ctx := c.UserContext()
+
+		inFlight.Set(ctx, float64(atomic.AddInt64(&active, 1)))
+		err := c.Next()
+		inFlight.Set(ctx, float64(atomic.AddInt64(&active, -1)))
+
+		status := c.Response().StatusCode()
+		if err != nil {
+			if e, ok := err.(*fiber.Error); ok {
+				status = e.Code
+			} else {
+				status = fiber.StatusInternalServerError
+			}
+		}
+
+		// Use route pattern to keep cardinality bounded
+		attrs := []attribute.KeyValue{
+			attribute.String("method", c.Method()),
+			attribute.String("route", c.Route().Path),
+			attribute.String("status", strconv.Itoa(status)),
+		}
+
+		requests.Add(ctx, 1, attrs...)
+		duration.Record(ctx, time.Since(start).Seconds(), attrs...)
+
+		return err
+	}
+}
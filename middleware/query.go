@@ -0,0 +1,455 @@
+package middleware
+
+import (
+	"encoding"
+	"fmt"
+	"net/http"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alimzhanovlr/sdk/errors"
+	"github.com/alimzhanovlr/sdk/server"
+	"github.com/gofiber/fiber/v2"
+)
+
+// queryParamsKey is the locals key QueryMiddleware stores parsed params under
+const queryParamsKey = "query_params"
+
+// QueryConfig holds query parameter parsing configuration
+type QueryConfig struct {
+	// ErrorHandler writes the invalid_query error, defaults to
+	// server.SendError with a reason per parameter in details
+	ErrorHandler func(c *fiber.Ctx, err *errors.AppError) error
+}
+
+// QueryMiddleware parses query params of the route into T by struct tags and
+// stores the result for Query. Invalid params are answered with 400
+// invalid_query, details list a reason for every invalid param.
+//
+// Fields are read from the `query` tag, fields without it are ignored.
+// Supported types are strings, bools, integers, floats, time.Duration,
+// time.Time (RFC 3339 or 2006-01-02), encoding.TextUnmarshaler, pointers to
+// them (nil when the param is absent) and slices of them (repeated or comma
+// separated values). Other tags:
+//
+//	default:"20"        value when the param is absent or empty
+//	required:"true"     absent param is an error
+//	enum:"asc,desc"     allowed values
+//	min:"1" max:"100"   bounds of numbers and durations, length of strings
+//
+// Example:
+//
+//	type ListOrdersQuery struct {
+//		Status []string   `query:"status" enum:"open,paid,shipped"`
+//		Sort   string     `query:"sort" default:"created_at" enum:"created_at,total"`
+//		Limit  int        `query:"limit" default:"20" min:"1" max:"100"`
+//		Since  *time.Time `query:"since"`
+//	}
+//
+//	app.Get("/orders", middleware.QueryMiddleware[ListOrdersQuery](middleware.QueryConfig{}), func(c *fiber.Ctx) error {
+//		q := middleware.Query[ListOrdersQuery](c)
+//		...
+//	})
+//
+// An unsupported field type or invalid tag panics when the middleware is
+// created, so schema mistakes fail at startup
+func QueryMiddleware[T any](config QueryConfig) fiber.Handler {
+	schema, err := queryParamsSchema(reflect.TypeFor[T]())
+	if err != nil {
+		panic(err)
+	}
+	if config.ErrorHandler == nil {
+		config.ErrorHandler = func(c *fiber.Ctx, err *errors.AppError) error {
+			return server.SendError(c, err)
+		}
+	}
+
+	return func(c *fiber.Ctx) error {
+		var params T
+		if err := schema.decode(c, reflect.ValueOf(&params).Elem()); err != nil {
+			return config.ErrorHandler(c, err)
+		}
+
+		c.Locals(queryParamsKey, &params)
+		return c.Next()
+	}
+}
+
+// Query returns params parsed by QueryMiddleware[T], zero T when the route
+// has no such middleware
+func Query[T any](c *fiber.Ctx) T {
+	if params, ok := c.Locals(queryParamsKey).(*T); ok {
+		return *params
+	}
+	var zero T
+	return zero
+}
+
+// ParseQuery parses query params into T like QueryMiddleware, for handlers
+// that parse params themselves. Invalid params return *errors.AppError
+func ParseQuery[T any](c *fiber.Ctx) (T, error) {
+	var params T
+	schema, err := queryParamsSchema(reflect.TypeFor[T]())
+	if err != nil {
+		return params, err
+	}
+	if err := schema.decode(c, reflect.ValueOf(&params).Elem()); err != nil {
+		return params, err
+	}
+	return params, nil
+}
+
+// invalidQuery builds bad request error with a reason per param
+func invalidQuery(details map[string]interface{}) *errors.AppError {
+	return errors.New("invalid_query", "Invalid query parameters", http.StatusBadRequest).
+		WithDetails(details)
+}
+
+// querySchemas caches schemas per struct type
+var querySchemas sync.Map
+
+// querySchema describes query params of a struct
+type querySchema struct {
+	fields []queryField
+}
+
+// queryField describes a single query param
+type queryField struct {
+	index    []int
+	name     string
+	required bool
+	defaults []string
+	enum     []string
+	min, max *float64
+	slice    bool
+	pointer  bool
+	elem     reflect.Type
+	parse    func(string) (reflect.Value, error)
+}
+
+// queryParamsSchema returns cached schema of t
+func queryParamsSchema(t reflect.Type) (*querySchema, error) {
+	if cached, ok := querySchemas.Load(t); ok {
+		return cached.(*querySchema), nil
+	}
+
+	schema, err := buildQuerySchema(t)
+	if err != nil {
+		return nil, err
+	}
+	querySchemas.Store(t, schema)
+	return schema, nil
+}
+
+func buildQuerySchema(t reflect.Type) (*querySchema, error) {
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("query params: %s is not a struct", t)
+	}
+
+	schema := &querySchema{}
+	for _, sf := range reflect.VisibleFields(t) {
+		name, ok := sf.Tag.Lookup("query")
+		if !ok || name == "-" || !sf.IsExported() {
+			continue
+		}
+
+		field, err := newQueryField(sf, name)
+		if err != nil {
+			return nil, fmt.Errorf("query params: %s.%s: %w", t, sf.Name, err)
+		}
+		schema.fields = append(schema.fields, field)
+	}
+	return schema, nil
+}
+
+func newQueryField(sf reflect.StructField, name string) (queryField, error) {
+	field := queryField{
+		index:    sf.Index,
+		name:     name,
+		required: sf.Tag.Get("required") == "true",
+		elem:     sf.Type,
+	}
+
+	switch field.elem.Kind() {
+	case reflect.Pointer:
+		field.pointer = true
+		field.elem = field.elem.Elem()
+	case reflect.Slice:
+		field.slice = true
+		field.elem = field.elem.Elem()
+	}
+
+	parse, err := queryParser(field.elem)
+	if err != nil {
+		return field, err
+	}
+	field.parse = parse
+
+	if enum, ok := sf.Tag.Lookup("enum"); ok {
+		field.enum = splitQueryValues(enum)
+	}
+	for _, bound := range []struct {
+		tag string
+		dst **float64
+	}{{"min", &field.min}, {"max", &field.max}} {
+		raw, ok := sf.Tag.Lookup(bound.tag)
+		if !ok {
+			continue
+		}
+		limit, err := field.bound(raw)
+		if err != nil {
+			return field, fmt.Errorf("invalid %s %q: %w", bound.tag, raw, err)
+		}
+		*bound.dst = &limit
+	}
+
+	// Defaults are checked by the same rules as request values
+	if def, ok := sf.Tag.Lookup("default"); ok {
+		field.defaults = []string{def}
+		if field.slice {
+			field.defaults = splitQueryValues(def)
+		}
+		if _, reason := field.values(field.defaults); reason != "" {
+			return field, fmt.Errorf("invalid default %q: %s", def, reason)
+		}
+	}
+	return field, nil
+}
+
+// bound parses min or max: a value of the field type for numbers and
+// durations, a length for strings
+func (f queryField) bound(raw string) (float64, error) {
+	if f.elem.Kind() == reflect.String {
+		n, err := strconv.Atoi(raw)
+		return float64(n), err
+	}
+	v, err := f.parse(raw)
+	if err != nil {
+		return 0, err
+	}
+	n, ok := queryNumber(v)
+	if !ok {
+		return 0, fmt.Errorf("bounds are not supported for %s", f.elem)
+	}
+	return n, nil
+}
+
+// decode fills dst from query params of the request
+func (s *querySchema) decode(c *fiber.Ctx, dst reflect.Value) *errors.AppError {
+	args := c.Context().QueryArgs()
+	details := make(map[string]interface{})
+
+	for _, field := range s.fields {
+		var raw []string
+		for _, value := range args.PeekMulti(field.name) {
+			if field.slice {
+				raw = append(raw, splitQueryValues(string(value))...)
+			} else if len(value) > 0 {
+				raw = append(raw, string(value))
+			}
+		}
+		if len(raw) == 0 {
+			if field.required {
+				details[field.name] = "is required"
+				continue
+			}
+			raw = field.defaults
+		}
+		if len(raw) == 0 {
+			continue
+		}
+		if !field.slice && len(raw) > 1 {
+			details[field.name] = "must be specified once"
+			continue
+		}
+
+		values, reason := field.values(raw)
+		if reason != "" {
+			details[field.name] = reason
+			continue
+		}
+		field.set(dst.FieldByIndex(field.index), values)
+	}
+
+	if len(details) > 0 {
+		return invalidQuery(details)
+	}
+	return nil
+}
+
+// values parses and checks raw values, reason describes the first invalid one
+func (f queryField) values(raw []string) ([]reflect.Value, string) {
+	values := make([]reflect.Value, 0, len(raw))
+	for _, s := range raw {
+		if len(f.enum) > 0 && !slices.Contains(f.enum, s) {
+			return nil, fmt.Sprintf("must be one of [%s]", strings.Join(f.enum, " "))
+		}
+
+		v, err := f.parse(s)
+		if err != nil {
+			return nil, queryTypeReason(f.elem)
+		}
+		if reason := f.checkBounds(v); reason != "" {
+			return nil, reason
+		}
+		values = append(values, v)
+	}
+	return values, ""
+}
+
+// checkBounds checks min and max
+func (f queryField) checkBounds(v reflect.Value) string {
+	if f.min == nil && f.max == nil {
+		return ""
+	}
+
+	if v.Kind() == reflect.String {
+		n := float64(len([]rune(v.String())))
+		if f.min != nil && n < *f.min {
+			return fmt.Sprintf("must be at least %v characters long", *f.min)
+		}
+		if f.max != nil && n > *f.max {
+			return fmt.Sprintf("must be at most %v characters long", *f.max)
+		}
+		return ""
+	}
+
+	n, _ := queryNumber(v)
+	if f.min != nil && n < *f.min {
+		return "must be greater than or equal to " + f.formatBound(*f.min)
+	}
+	if f.max != nil && n > *f.max {
+		return "must be less than or equal to " + f.formatBound(*f.max)
+	}
+	return ""
+}
+
+func (f queryField) formatBound(n float64) string {
+	if f.elem == reflect.TypeFor[time.Duration]() {
+		return time.Duration(n).String()
+	}
+	return strconv.FormatFloat(n, 'f', -1, 64)
+}
+
+// set stores parsed values into the struct field
+func (f queryField) set(dst reflect.Value, values []reflect.Value) {
+	switch {
+	case f.slice:
+		slice := reflect.MakeSlice(dst.Type(), 0, len(values))
+		dst.Set(reflect.Append(slice, values...))
+	case f.pointer:
+		ptr := reflect.New(f.elem)
+		ptr.Elem().Set(values[0])
+		dst.Set(ptr)
+	default:
+		dst.Set(values[0])
+	}
+}
+
+// queryParser returns parser for a single value of type t
+func queryParser(t reflect.Type) (func(string) (reflect.Value, error), error) {
+	switch t {
+	case reflect.TypeFor[time.Time]():
+		return func(s string) (reflect.Value, error) {
+			if v, err := time.Parse(time.RFC3339, s); err == nil {
+				return reflect.ValueOf(v), nil
+			}
+			v, err := time.Parse(time.DateOnly, s)
+			return reflect.ValueOf(v), err
+		}, nil
+	case reflect.TypeFor[time.Duration]():
+		return func(s string) (reflect.Value, error) {
+			v, err := time.ParseDuration(s)
+			return reflect.ValueOf(v), err
+		}, nil
+	}
+
+	if reflect.PointerTo(t).Implements(reflect.TypeFor[encoding.TextUnmarshaler]()) {
+		return func(s string) (reflect.Value, error) {
+			v := reflect.New(t)
+			err := v.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+			return v.Elem(), err
+		}, nil
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return func(s string) (reflect.Value, error) {
+			return reflect.ValueOf(s).Convert(t), nil
+		}, nil
+	case reflect.Bool:
+		return func(s string) (reflect.Value, error) {
+			v, err := strconv.ParseBool(s)
+			return reflect.ValueOf(v).Convert(t), err
+		}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return func(s string) (reflect.Value, error) {
+			v, err := strconv.ParseInt(s, 10, t.Bits())
+			return reflect.ValueOf(v).Convert(t), err
+		}, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return func(s string) (reflect.Value, error) {
+			v, err := strconv.ParseUint(s, 10, t.Bits())
+			return reflect.ValueOf(v).Convert(t), err
+		}, nil
+	case reflect.Float32, reflect.Float64:
+		return func(s string) (reflect.Value, error) {
+			v, err := strconv.ParseFloat(s, t.Bits())
+			return reflect.ValueOf(v).Convert(t), err
+		}, nil
+	}
+	return nil, fmt.Errorf("unsupported type %s", t)
+}
+
+// queryTypeReason describes the expected format of type t
+func queryTypeReason(t reflect.Type) string {
+	switch t {
+	case reflect.TypeFor[time.Time]():
+		return "must be a time in RFC 3339 format or a date like 2006-01-02"
+	case reflect.TypeFor[time.Duration]():
+		return "must be a duration like 30s or 5m"
+	}
+	if reflect.PointerTo(t).Implements(reflect.TypeFor[encoding.TextUnmarshaler]()) {
+		return "has invalid format"
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return "must be true or false"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return "must be an integer"
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "must be a non-negative integer"
+	case reflect.Float32, reflect.Float64:
+		return "must be a number"
+	}
+	return "has invalid format"
+}
+
+// queryNumber converts numeric values for bound checks
+func queryNumber(v reflect.Value) (float64, bool) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	}
+	return 0, false
+}
+
+// splitQueryValues splits comma separated values, skipping empty ones
+func splitQueryValues(s string) []string {
+	var values []string
+	for _, value := range strings.Split(s, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
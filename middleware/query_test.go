@@ -0,0 +1,244 @@
+package middleware
+
+import (
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"net/netip"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alimzhanovlr/sdk/errors"
+	"github.com/gofiber/fiber/v2"
+)
+
+type listQuery struct {
+	Status  []string      `query:"status" enum:"open,paid,shipped"`
+	Sort    string        `query:"sort" default:"created_at" enum:"created_at,total"`
+	Limit   int           `query:"limit" default:"20" min:"1" max:"100"`
+	Offset  uint          `query:"offset"`
+	Search  string        `query:"q" min:"2" max:"5"`
+	Since   *time.Time    `query:"since"`
+	Until   time.Time     `query:"until"`
+	Timeout time.Duration `query:"timeout" default:"5s" max:"1m"`
+	Archive *bool         `query:"archived"`
+	Price   float64       `query:"price" min:"0.5"`
+	IP      netip.Addr    `query:"ip"`
+	IDs     []int         `query:"id" max:"10"`
+	Ignored string
+}
+
+// parseTestQuery runs QueryMiddleware on path and returns parsed params or
+// details of the invalid_query error
+func parseTestQuery[T any](t *testing.T, path string) (T, map[string]interface{}) {
+	t.Helper()
+	var (
+		parsed  T
+		details map[string]interface{}
+	)
+	app := fiber.New()
+	app.Get("/", QueryMiddleware[T](QueryConfig{
+		ErrorHandler: func(c *fiber.Ctx, err *errors.AppError) error {
+			details = err.Details
+			return c.SendStatus(err.StatusCode)
+		},
+	}), func(c *fiber.Ctx) error {
+		parsed = Query[T](c)
+		return c.SendStatus(fiber.StatusNoContent)
+	})
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, path, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if details == nil && resp.StatusCode != fiber.StatusNoContent {
+		t.Fatalf("status = %d", resp.StatusCode)
+	}
+	return parsed, details
+}
+
+func TestQueryDefaults(t *testing.T) {
+	q, details := parseTestQuery[listQuery](t, "/?sort=&limit=")
+	if details != nil {
+		t.Fatalf("details = %v", details)
+	}
+	if q.Sort != "created_at" || q.Limit != 20 || q.Timeout != 5*time.Second {
+		t.Errorf("defaults = %+v", q)
+	}
+	if q.Status != nil || q.Since != nil || q.Archive != nil || q.Ignored != "" {
+		t.Errorf("absent params are set: %+v", q)
+	}
+}
+
+func TestQueryValues(t *testing.T) {
+	q, details := parseTestQuery[listQuery](t,
+		"/?status=open&status=paid,shipped&sort=total&limit=100&offset=7&q=ab&since=2025-03-01"+
+			"&until=2025-03-02T10:00:00%2B03:00&timeout=1m&archived=false&price=0.5&ip=10.0.0.1&id=1,2&Ignored=x")
+	if details != nil {
+		t.Fatalf("details = %v", details)
+	}
+
+	if !reflect.DeepEqual(q.Status, []string{"open", "paid", "shipped"}) {
+		t.Errorf("Status = %v, want repeated and comma separated values", q.Status)
+	}
+	if q.Sort != "total" || q.Limit != 100 || q.Offset != 7 || q.Search != "ab" || q.Timeout != time.Minute || q.Price != 0.5 {
+		t.Errorf("params = %+v", q)
+	}
+	if q.Since == nil || !q.Since.Equal(time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Since = %v, want a date", q.Since)
+	}
+	if !q.Until.Equal(time.Date(2025, 3, 2, 7, 0, 0, 0, time.UTC)) {
+		t.Errorf("Until = %v, want RFC 3339 time", q.Until)
+	}
+	if q.Archive == nil || *q.Archive {
+		t.Errorf("Archive = %v, want pointer to false", q.Archive)
+	}
+	if q.IP != netip.MustParseAddr("10.0.0.1") || !reflect.DeepEqual(q.IDs, []int{1, 2}) {
+		t.Errorf("IP = %v, IDs = %v", q.IP, q.IDs)
+	}
+	if q.Ignored != "" {
+		t.Error("field without query tag was set")
+	}
+}
+
+func TestQueryInvalid(t *testing.T) {
+	tests := []struct {
+		query string
+		param string
+		want  string
+	}{
+		{"limit=0", "limit", "must be greater than or equal to 1"},
+		{"limit=101", "limit", "must be less than or equal to 100"},
+		{"limit=ten", "limit", "must be an integer"},
+		{"limit=5&limit=6", "limit", "must be specified once"},
+		{"offset=-1", "offset", "must be a non-negative integer"},
+		{"q=a", "q", "must be at least 2 characters long"},
+		{"q=abcdef", "q", "must be at most 5 characters long"},
+		{"q=%D0%B0%D0%B1%D0%B2", "q", ""},
+		{"sort=name", "sort", "must be one of [created_at total]"},
+		{"status=open,closed", "status", "must be one of [open paid shipped]"},
+		{"since=01.03.2025", "since", "must be a time in RFC 3339 format or a date like 2006-01-02"},
+		{"until=2025-02-30", "until", "must be a time in RFC 3339 format or a date like 2006-01-02"},
+		{"timeout=5", "timeout", "must be a duration like 30s or 5m"},
+		{"timeout=2m", "timeout", "must be less than or equal to 1m0s"},
+		{"archived=maybe", "archived", "must be true or false"},
+		{"price=0.1", "price", "must be greater than or equal to 0.5"},
+		{"price=cheap", "price", "must be a number"},
+		{"ip=10.0.0", "ip", "has invalid format"},
+		{"id=1&id=11", "id", "must be less than or equal to 10"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			_, details := parseTestQuery[listQuery](t, "/?"+tt.query)
+			if tt.want == "" {
+				if details != nil {
+					t.Errorf("details = %v, want valid", details)
+				}
+				return
+			}
+			if details[tt.param] != tt.want {
+				t.Errorf("details = %v, want %s: %s", details, tt.param, tt.want)
+			}
+		})
+	}
+
+	// Every invalid param is reported
+	_, details := parseTestQuery[listQuery](t, "/?limit=0&sort=name&archived=maybe")
+	if len(details) != 3 {
+		t.Errorf("details = %v, want 3 params", details)
+	}
+}
+
+func TestQueryRequiredAndErrorResponse(t *testing.T) {
+	type required struct {
+		Tenant string `query:"tenant" required:"true"`
+	}
+	app := fiber.New()
+	app.Get("/", QueryMiddleware[required](QueryConfig{}), func(c *fiber.Ctx) error {
+		return c.SendString(Query[required](c).Tenant)
+	})
+
+	resp, _ := app.Test(httptest.NewRequest(fiber.MethodGet, "/?tenant=", nil))
+	defer resp.Body.Close()
+	var body struct {
+		Error struct {
+			Code    string            `json:"code"`
+			Details map[string]string `json:"details"`
+		} `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != fiber.StatusBadRequest || body.Error.Code != "invalid_query" || body.Error.Details["tenant"] != "is required" {
+		t.Errorf("got %d %+v", resp.StatusCode, body)
+	}
+
+	resp, _ = app.Test(httptest.NewRequest(fiber.MethodGet, "/?tenant=acme", nil))
+	got, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(got) != "acme" {
+		t.Errorf("body = %q, want acme", got)
+	}
+}
+
+func TestParseQuery(t *testing.T) {
+	app := fiber.New()
+	app.Get("/", func(c *fiber.Ctx) error {
+		q, err := ParseQuery[listQuery](c)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).SendString(errors.GetAppError(err).Code)
+		}
+		return c.SendString(q.Sort)
+	})
+	app.Get("/plain", func(c *fiber.Ctx) error {
+		return c.SendString(Query[listQuery](c).Sort)
+	})
+
+	for path, want := range map[string]string{"/?sort=total": "total", "/?sort=x": "invalid_query", "/plain?sort=total": ""} {
+		resp, _ := app.Test(httptest.NewRequest(fiber.MethodGet, path, nil))
+		got, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if string(got) != want {
+			t.Errorf("%s: body = %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestQueryMiddlewarePanicsOnInvalidSchema(t *testing.T) {
+	tests := []struct {
+		name string
+		make func()
+		want string
+	}{
+		{"unsupported type", func() {
+			QueryMiddleware[struct {
+				M map[string]string `query:"m"`
+			}](QueryConfig{})
+		}, "unsupported type"},
+		{"invalid default", func() {
+			QueryMiddleware[struct {
+				N int `query:"n" default:"200" max:"100"`
+			}](QueryConfig{})
+		}, `invalid default "200"`},
+		{"invalid bound", func() {
+			QueryMiddleware[struct {
+				B bool `query:"b" min:"1"`
+			}](QueryConfig{})
+		}, "invalid min"},
+		{"not a struct", func() { QueryMiddleware[int](QueryConfig{}) }, "is not a struct"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				err, _ := recover().(error)
+				if err == nil || !strings.Contains(err.Error(), tt.want) {
+					t.Errorf("panic = %v, want %q", err, tt.want)
+				}
+			}()
+			tt.make()
+		})
+	}
+}
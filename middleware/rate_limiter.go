@@ -1,10 +1,12 @@
 package middleware
 
 import (
+	"math"
+	"strconv"
 	"time"
 
+	"github.com/alimzhanovlr/sdk/ratelimit"
 	"github.com/gofiber/fiber/v2"
-	"github.com/gofiber/fiber/v2/middleware/limiter"
 )
 
 // RateLimitConfig holds rate limiting configuration
@@ -12,6 +14,11 @@ type RateLimitConfig struct {
 	Max        int           // Maximum number of requests
 	Expiration time.Duration // Time window
 	Message    string        // Error message
+	// KeyGenerator returns the client key, defaults to c.IP()
+	KeyGenerator func(c *fiber.Ctx) string
+	// Limiter replaces Max and Expiration, e.g. a limiter with a Redis store
+	// shared by instances or the one the HTTP client of a service uses
+	Limiter *ratelimit.Limiter
 }
 
 // DefaultRateLimitConfig returns default rate limit config
@@ -23,18 +30,50 @@ func DefaultRateLimitConfig() RateLimitConfig {
 	}
 }
 
-// RateLimitMiddleware returns rate limiting middleware
+// RateLimitMiddleware returns rate limiting middleware. Without Limiter it
+// allows Max requests per client in any sliding Expiration window of this
+// instance. X-RateLimit-* headers are set on every response, Retry-After on
+// rejected ones
 func RateLimitMiddleware(config RateLimitConfig) fiber.Handler {
-	return limiter.New(limiter.Config{
-		Max:        config.Max,
-		Expiration: config.Expiration,
-		LimitReached: func(c *fiber.Ctx) error {
+	limiter := config.Limiter
+	if limiter == nil {
+		limiter = ratelimit.New(ratelimit.NewMemoryStore(), ratelimit.Limit{
+			Rate:      config.Max,
+			Period:    config.Expiration,
+			Algorithm: ratelimit.SlidingWindow,
+		})
+	}
+
+	keyGenerator := config.KeyGenerator
+	if keyGenerator == nil {
+		keyGenerator = func(c *fiber.Ctx) string {
+			return c.IP()
+		}
+	}
+
+	return func(c *fiber.Ctx) error {
+		result, err := limiter.Allow(c.UserContext(), keyGenerator(c))
+		if err != nil {
+			return err
+		}
+
+		c.Set("X-RateLimit-Limit", strconv.Itoa(result.Limit))
+		c.Set("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		c.Set("X-RateLimit-Reset", strconv.Itoa(ceilSeconds(result.ResetAfter)))
+
+		if !result.Allowed {
+			c.Set(fiber.HeaderRetryAfter, strconv.Itoa(max(1, ceilSeconds(result.RetryAfter))))
 			return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
 				"error": fiber.Map{
 					"code":    "rate_limit_exceeded",
 					"message": config.Message,
 				},
 			})
-		},
-	})
+		}
+		return c.Next()
+	}
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
 }
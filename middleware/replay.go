@@ -0,0 +1,106 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/alimzhanovlr/sdk/errors"
+	"github.com/gofiber/fiber/v2"
+)
+
+// Replay protection errors
+var (
+	ErrReplayHeaders  = errors.New("replay_headers_invalid", "Timestamp and nonce headers are required", http.StatusBadRequest)
+	ErrReplayExpired  = errors.New("request_expired", "Request timestamp is outside of replay window", http.StatusUnauthorized)
+	ErrReplayDetected = errors.New("request_replayed", "Nonce has already been used", http.StatusConflict)
+)
+
+// Nonce length bounds keep the nonce cache small
+const (
+	minNonceLength = 8
+	maxNonceLength = 128
+)
+
+// ReplayConfig holds replay protection configuration
+type ReplayConfig struct {
+	Store           DedupStore    // Used nonces, defaults to in-memory store
+	Window          time.Duration // Maximum age and clock skew of X-Timestamp
+	TimestampHeader string        // Unix seconds or RFC 3339
+	NonceHeader     string
+	// Scope namespaces nonces, e.g. per partner. Defaults to the subject
+	Scope func(c *fiber.Ctx) string
+	// ErrorHandler writes the rejection, defaults to JSON with the error status
+	ErrorHandler func(c *fiber.Ctx, err *errors.AppError) error
+}
+
+// DefaultReplayConfig returns default replay protection config
+func DefaultReplayConfig() ReplayConfig {
+	return ReplayConfig{
+		Window:          5 * time.Minute,
+		TimestampHeader: "X-Timestamp",
+		NonceHeader:     "X-Nonce",
+		Scope:           SubjectFromLocals(SubjectKey),
+	}
+}
+
+// ReplayProtectionMiddleware rejects requests with a timestamp outside of the
+// window or a nonce already used within it. Combine it with signature
+// verification that covers both headers, otherwise they can be rewritten
+func ReplayProtectionMiddleware(config ReplayConfig) fiber.Handler {
+	defaults := DefaultReplayConfig()
+	if config.Store == nil {
+		config.Store = NewMemoryDedupStore()
+	}
+	if config.Window <= 0 {
+		config.Window = defaults.Window
+	}
+	if config.TimestampHeader == "" {
+		config.TimestampHeader = defaults.TimestampHeader
+	}
+	if config.NonceHeader == "" {
+		config.NonceHeader = defaults.NonceHeader
+	}
+	if config.Scope == nil {
+		config.Scope = defaults.Scope
+	}
+	if config.ErrorHandler == nil {
+		config.ErrorHandler = sendAppError
+	}
+
+	return func(c *fiber.Ctx) error {
+		nonce := c.Get(config.NonceHeader)
+		timestamp, ok := parseTimestamp(c.Get(config.TimestampHeader))
+		if !ok || len(nonce) < minNonceLength || len(nonce) > maxNonceLength {
+			return config.ErrorHandler(c, ErrReplayHeaders)
+		}
+
+		age := time.Since(timestamp)
+		if age > config.Window || age < -config.Window {
+			return config.ErrorHandler(c, ErrReplayExpired)
+		}
+
+		// A nonce must outlive the window on both sides of the clock skew
+		state, err := config.Store.Claim(c.UserContext(), config.Scope(c)+":"+nonce, 2*config.Window)
+		if err != nil {
+			return fmt.Errorf("failed to check nonce: %w", err)
+		}
+		if state != DedupNew {
+			return config.ErrorHandler(c, ErrReplayDetected)
+		}
+
+		return c.Next()
+	}
+}
+
+func parseTimestamp(value string) (time.Time, bool) {
+	if value == "" {
+		return time.Time{}, false
+	}
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(seconds, 0), true
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	return t, err == nil
+}
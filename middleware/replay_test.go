@@ -0,0 +1,150 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func newReplayApp(config ReplayConfig) (*fiber.App, *int) {
+	calls := 0
+	app := fiber.New()
+	app.Post("/transfer", ReplayProtectionMiddleware(config), func(c *fiber.Ctx) error {
+		calls++
+		return c.SendStatus(fiber.StatusOK)
+	})
+	return app, &calls
+}
+
+func replayRequest(t *testing.T, app *fiber.App, timestamp, nonce string, headers ...string) int {
+	t.Helper()
+	req := httptest.NewRequest(fiber.MethodPost, "/transfer", nil)
+	if timestamp != "" {
+		req.Header.Set("X-Timestamp", timestamp)
+	}
+	if nonce != "" {
+		req.Header.Set("X-Nonce", nonce)
+	}
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	return resp.StatusCode
+}
+
+func unixAgo(d time.Duration) string {
+	return strconv.FormatInt(time.Now().Add(-d).Unix(), 10)
+}
+
+func TestReplayWindow(t *testing.T) {
+	app, calls := newReplayApp(ReplayConfig{Window: time.Minute})
+
+	tests := []struct {
+		name      string
+		timestamp string
+		want      int
+	}{
+		{"current", unixAgo(0), fiber.StatusOK},
+		{"inside window", unixAgo(50 * time.Second), fiber.StatusOK},
+		{"rfc3339", time.Now().Add(-10 * time.Second).UTC().Format(time.RFC3339), fiber.StatusOK},
+		{"too old", unixAgo(2 * time.Minute), fiber.StatusUnauthorized},
+		{"client clock ahead within window", unixAgo(-50 * time.Second), fiber.StatusOK},
+		{"client clock ahead beyond window", unixAgo(-2 * time.Minute), fiber.StatusUnauthorized},
+		{"malformed", "yesterday", fiber.StatusBadRequest},
+		{"missing", "", fiber.StatusBadRequest},
+	}
+
+	accepted := 0
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nonce := "nonce-" + strconv.Itoa(1000+i)
+			if got := replayRequest(t, app, tt.timestamp, nonce); got != tt.want {
+				t.Errorf("status = %d, want %d", got, tt.want)
+			}
+		})
+		if tt.want == fiber.StatusOK {
+			accepted++
+		}
+	}
+	if *calls != accepted {
+		t.Errorf("handler calls = %d, want %d", *calls, accepted)
+	}
+}
+
+func TestReplayNonceReuse(t *testing.T) {
+	app, calls := newReplayApp(DefaultReplayConfig())
+
+	if got := replayRequest(t, app, unixAgo(0), "nonce-0001"); got != fiber.StatusOK {
+		t.Fatalf("first request: status = %d", got)
+	}
+	// A fresh timestamp does not make a used nonce valid again
+	if got := replayRequest(t, app, unixAgo(-time.Second), "nonce-0001"); got != fiber.StatusConflict {
+		t.Errorf("replayed nonce: status = %d, want 409", got)
+	}
+	if got := replayRequest(t, app, unixAgo(0), "nonce-0002"); got != fiber.StatusOK {
+		t.Errorf("new nonce: status = %d", got)
+	}
+	if *calls != 2 {
+		t.Errorf("handler calls = %d, want 2", *calls)
+	}
+}
+
+func TestReplayNonceLength(t *testing.T) {
+	app, calls := newReplayApp(DefaultReplayConfig())
+
+	for _, nonce := range []string{"", "short", string(make([]byte, maxNonceLength+1))} {
+		if got := replayRequest(t, app, unixAgo(0), nonce); got != fiber.StatusBadRequest {
+			t.Errorf("nonce of %d bytes: status = %d, want 400", len(nonce), got)
+		}
+	}
+	if *calls != 0 {
+		t.Errorf("handler calls = %d, want 0", *calls)
+	}
+}
+
+func TestReplayNonceScope(t *testing.T) {
+	app, calls := newReplayApp(ReplayConfig{
+		Scope: func(c *fiber.Ctx) string { return c.Get("X-Partner") },
+	})
+
+	if got := replayRequest(t, app, unixAgo(0), "nonce-0001", "X-Partner", "acme"); got != fiber.StatusOK {
+		t.Fatalf("acme: status = %d", got)
+	}
+	if got := replayRequest(t, app, unixAgo(0), "nonce-0001", "X-Partner", "globex"); got != fiber.StatusOK {
+		t.Errorf("same nonce of another partner: status = %d, want 200", got)
+	}
+	if got := replayRequest(t, app, unixAgo(0), "nonce-0001", "X-Partner", "acme"); got != fiber.StatusConflict {
+		t.Errorf("acme replay: status = %d, want 409", got)
+	}
+	if *calls != 2 {
+		t.Errorf("handler calls = %d, want 2", *calls)
+	}
+}
+
+func TestReplayNonceOutlivesWindow(t *testing.T) {
+	now := time.Now()
+	store := NewMemoryDedupStore()
+	store.now = func() time.Time { return now }
+	app, _ := newReplayApp(ReplayConfig{Store: store, Window: time.Minute})
+
+	if got := replayRequest(t, app, unixAgo(0), "nonce-0001"); got != fiber.StatusOK {
+		t.Fatalf("status = %d", got)
+	}
+	// A timestamp accepted at one edge of the skew stays valid until the
+	// other edge, the nonce is kept for two windows
+	now = now.Add(2*time.Minute - time.Second)
+	if got := replayRequest(t, app, unixAgo(0), "nonce-0001"); got != fiber.StatusConflict {
+		t.Errorf("within two windows: status = %d, want 409", got)
+	}
+	now = now.Add(2 * time.Second)
+	if got := replayRequest(t, app, unixAgo(0), "nonce-0001"); got != fiber.StatusOK {
+		t.Errorf("after two windows: status = %d, want 200", got)
+	}
+}
@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"github.com/alimzhanovlr/sdk/httpclient"
+	"github.com/alimzhanovlr/sdk/idgen"
+	"github.com/gofiber/fiber/v2"
+)
+
+// maxRequestIDLength limits client supplied request IDs
+const maxRequestIDLength = 128
+
+// RequestIDMiddleware reads X-Request-ID or generates a new one, echoes it in
+// response and stores it in user context so httpclient propagates it downstream
+func RequestIDMiddleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		requestID := c.Get(httpclient.RequestIDHeader)
+		if requestID == "" || len(requestID) > maxRequestIDLength {
+			requestID = idgen.NewString()
+		}
+
+		c.Locals("request_id", requestID)
+		c.Set(httpclient.RequestIDHeader, requestID)
+		c.SetUserContext(httpclient.WithRequestID(c.UserContext(), requestID))
+
+		return c.Next()
+	}
+}
@@ -0,0 +1,236 @@
+package middleware
+
+import (
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alimzhanovlr/sdk/errors"
+	"github.com/gofiber/fiber/v2"
+)
+
+// SignatureAlgorithm is a webhook signature algorithm
+type SignatureAlgorithm string
+
+// Supported signature algorithms
+const (
+	SignatureHMACSHA256 SignatureAlgorithm = "hmac-sha256"
+	SignatureEd25519    SignatureAlgorithm = "ed25519"
+)
+
+// SignatureFormat is the layout of the signature header
+type SignatureFormat string
+
+// Supported signature header formats
+const (
+	// SignatureFormatPlain is a hex (HMAC) or base64 (Ed25519) signature with
+	// optional "sha256=" prefix, several may be separated by commas
+	SignatureFormatPlain SignatureFormat = "plain"
+	// SignatureFormatStripe is "t=<unix>,v1=<hex>[,v1=<hex>]" signing "<t>.<body>"
+	SignatureFormatStripe SignatureFormat = "stripe"
+)
+
+// Signature verification errors
+var (
+	ErrSignatureMissing = errors.New("signature_missing", "Signature is missing", http.StatusUnauthorized)
+	ErrSignatureInvalid = errors.New("signature_invalid", "Signature is invalid", http.StatusUnauthorized)
+	ErrSignatureExpired = errors.New("signature_expired", "Signature timestamp is outside of tolerance", http.StatusUnauthorized)
+)
+
+// rawBodyKey stores the verified body in locals
+const rawBodyKey = "signed_raw_body"
+
+// VerifySignatureConfig holds webhook signature verification configuration
+type VerifySignatureConfig struct {
+	Algorithm SignatureAlgorithm
+	Format    SignatureFormat
+	Secret    []byte            // HMAC secret
+	PublicKey ed25519.PublicKey // Ed25519 public key
+	Header    string            // Signature header
+	// TimestampHeader carries unix seconds for plain format, when set the
+	// signed payload is "<timestamp>.<body>"
+	TimestampHeader string
+	Tolerance       time.Duration // Maximum timestamp age and skew
+	// ErrorHandler writes the verification error, defaults to JSON with 401
+	ErrorHandler func(c *fiber.Ctx, err *errors.AppError) error
+}
+
+// DefaultVerifySignatureConfig returns HMAC-SHA256 config for X-Signature and X-Timestamp headers
+func DefaultVerifySignatureConfig(secret []byte) VerifySignatureConfig {
+	return VerifySignatureConfig{
+		Algorithm:       SignatureHMACSHA256,
+		Format:          SignatureFormatPlain,
+		Secret:          secret,
+		Header:          "X-Signature",
+		TimestampHeader: "X-Timestamp",
+		Tolerance:       5 * time.Minute,
+	}
+}
+
+// GitHubSignatureConfig returns config for X-Hub-Signature-256 webhooks
+func GitHubSignatureConfig(secret []byte) VerifySignatureConfig {
+	return VerifySignatureConfig{
+		Algorithm: SignatureHMACSHA256,
+		Format:    SignatureFormatPlain,
+		Secret:    secret,
+		Header:    "X-Hub-Signature-256",
+	}
+}
+
+// StripeSignatureConfig returns config for Stripe-Signature webhooks
+func StripeSignatureConfig(secret []byte) VerifySignatureConfig {
+	return VerifySignatureConfig{
+		Algorithm: SignatureHMACSHA256,
+		Format:    SignatureFormatStripe,
+		Secret:    secret,
+		Header:    "Stripe-Signature",
+		Tolerance: 5 * time.Minute,
+	}
+}
+
+// VerifySignatureMiddleware rejects requests without a valid signature of the
+// raw body. Handlers get the exact signed bytes with SignedBody.
+//
+// An empty HMAC secret, a missing or malformed Ed25519 public key or an
+// unknown algorithm panics when the middleware is created: with an empty
+// secret anyone can compute a valid signature
+func VerifySignatureMiddleware(config VerifySignatureConfig) fiber.Handler {
+	if config.Algorithm == "" {
+		config.Algorithm = SignatureHMACSHA256
+	}
+	switch config.Algorithm {
+	case SignatureHMACSHA256:
+		if len(config.Secret) == 0 {
+			panic("middleware: signature verification requires a non-empty HMAC secret")
+		}
+	case SignatureEd25519:
+		if len(config.PublicKey) != ed25519.PublicKeySize {
+			panic(fmt.Sprintf("middleware: ed25519 public key must be %d bytes, got %d", ed25519.PublicKeySize, len(config.PublicKey)))
+		}
+	default:
+		panic(fmt.Sprintf("middleware: unsupported signature algorithm %q", config.Algorithm))
+	}
+	if config.Format == "" {
+		config.Format = SignatureFormatPlain
+	}
+	if config.Header == "" {
+		config.Header = "X-Signature"
+	}
+	if config.ErrorHandler == nil {
+		config.ErrorHandler = sendAppError
+	}
+
+	return func(c *fiber.Ctx) error {
+		// Request().Body() is not decompressed, unlike c.Body()
+		body := c.Request().Body()
+
+		if err := config.verify(c, body); err != nil {
+			return config.ErrorHandler(c, err)
+		}
+
+		c.Locals(rawBodyKey, body)
+		return c.Next()
+	}
+}
+
+// SignedBody returns the raw body verified by VerifySignatureMiddleware
+func SignedBody(c *fiber.Ctx) []byte {
+	body, _ := c.Locals(rawBodyKey).([]byte)
+	return body
+}
+
+func (config VerifySignatureConfig) verify(c *fiber.Ctx, body []byte) *errors.AppError {
+	header := c.Get(config.Header)
+	if header == "" {
+		return ErrSignatureMissing
+	}
+
+	var (
+		timestamp  string
+		signatures []string
+	)
+	switch config.Format {
+	case SignatureFormatStripe:
+		for _, part := range strings.Split(header, ",") {
+			key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+			switch key {
+			case "t":
+				timestamp = value
+			case "v1":
+				signatures = append(signatures, value)
+			}
+		}
+		if timestamp == "" {
+			return ErrSignatureMissing
+		}
+	default:
+		for _, part := range strings.Split(header, ",") {
+			signatures = append(signatures, strings.TrimPrefix(strings.TrimSpace(part), "sha256="))
+		}
+		if config.TimestampHeader != "" {
+			if timestamp = c.Get(config.TimestampHeader); timestamp == "" {
+				return ErrSignatureMissing
+			}
+		}
+	}
+
+	payload := body
+	if timestamp != "" {
+		if err := config.checkTimestamp(timestamp); err != nil {
+			return err
+		}
+		payload = append([]byte(timestamp+"."), body...)
+	}
+
+	for _, signature := range signatures {
+		if config.valid(payload, signature) {
+			return nil
+		}
+	}
+	return ErrSignatureInvalid
+}
+
+func (config VerifySignatureConfig) checkTimestamp(timestamp string) *errors.AppError {
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrSignatureInvalid
+	}
+	if config.Tolerance <= 0 {
+		return nil
+	}
+
+	age := time.Since(time.Unix(seconds, 0))
+	if age > config.Tolerance || age < -config.Tolerance {
+		return ErrSignatureExpired
+	}
+	return nil
+}
+
+func (config VerifySignatureConfig) valid(payload []byte, signature string) bool {
+	switch config.Algorithm {
+	case SignatureEd25519:
+		// Hex digits are valid base64 too, so fall back on length
+		sig, err := base64.StdEncoding.DecodeString(signature)
+		if err != nil || len(sig) != ed25519.SignatureSize {
+			if sig, err = hex.DecodeString(signature); err != nil {
+				return false
+			}
+		}
+		return ed25519.Verify(config.PublicKey, payload, sig)
+	default:
+		sig, err := hex.DecodeString(signature)
+		if err != nil {
+			return false
+		}
+		mac := hmac.New(sha256.New, config.Secret)
+		mac.Write(payload)
+		return hmac.Equal(sig, mac.Sum(nil))
+	}
+}
@@ -0,0 +1,123 @@
+package middleware
+
+import (
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func signedApp(config VerifySignatureConfig) *fiber.App {
+	app := fiber.New()
+	app.Post("/hook", VerifySignatureMiddleware(config), func(c *fiber.Ctx) error {
+		return c.Send(SignedBody(c))
+	})
+	return app
+}
+
+func postHook(t *testing.T, app *fiber.App, body string, headers map[string]string) int {
+	t.Helper()
+
+	req := httptest.NewRequest(fiber.MethodPost, "/hook", strings.NewReader(body))
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
+	return resp.StatusCode
+}
+
+func hmacHex(secret []byte, payload string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestVerifySignatureMiddlewareRejectsWeakConfig(t *testing.T) {
+	tests := map[string]VerifySignatureConfig{
+		"empty secret":       {Algorithm: SignatureHMACSHA256},
+		"default algorithm":  {},
+		"missing public key": {Algorithm: SignatureEd25519},
+		"short public key":   {Algorithm: SignatureEd25519, PublicKey: make([]byte, 16)},
+		"unknown algorithm":  {Algorithm: "md5", Secret: []byte("s")},
+	}
+	for name, config := range tests {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("expected panic")
+				}
+			}()
+			VerifySignatureMiddleware(config)
+		})
+	}
+}
+
+func TestVerifySignatureMiddlewareHMAC(t *testing.T) {
+	secret := []byte("webhook-secret")
+	app := signedApp(DefaultVerifySignatureConfig(secret))
+	body := `{"event":"paid"}`
+	ts := strconv.FormatInt(time.Now().Unix(), 10)
+
+	valid := map[string]string{"X-Signature": "sha256=" + hmacHex(secret, ts+"."+body), "X-Timestamp": ts}
+	if status := postHook(t, app, body, valid); status != fiber.StatusOK {
+		t.Errorf("valid signature: status %d", status)
+	}
+
+	forged := map[string]string{"X-Signature": hmacHex([]byte("other"), ts+"."+body), "X-Timestamp": ts}
+	if status := postHook(t, app, body, forged); status != fiber.StatusUnauthorized {
+		t.Errorf("forged signature: status %d", status)
+	}
+
+	old := strconv.FormatInt(time.Now().Add(-time.Hour).Unix(), 10)
+	expired := map[string]string{"X-Signature": hmacHex(secret, old+"."+body), "X-Timestamp": old}
+	if status := postHook(t, app, body, expired); status != fiber.StatusUnauthorized {
+		t.Errorf("expired signature: status %d", status)
+	}
+}
+
+func TestVerifySignatureMiddlewareStripe(t *testing.T) {
+	secret := []byte("whsec")
+	app := signedApp(StripeSignatureConfig(secret))
+	body := `{"id":"evt_1"}`
+	ts := strconv.FormatInt(time.Now().Unix(), 10)
+
+	header := "t=" + ts + ",v1=deadbeef,v1=" + hmacHex(secret, ts+"."+body)
+	if status := postHook(t, app, body, map[string]string{"Stripe-Signature": header}); status != fiber.StatusOK {
+		t.Errorf("valid stripe signature: status %d", status)
+	}
+	if status := postHook(t, app, body, map[string]string{"Stripe-Signature": "v1=" + hmacHex(secret, body)}); status != fiber.StatusUnauthorized {
+		t.Errorf("stripe signature without timestamp: status %d", status)
+	}
+}
+
+func TestVerifySignatureMiddlewareEd25519(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	app := signedApp(VerifySignatureConfig{Algorithm: SignatureEd25519, PublicKey: public})
+	body := `{"event":"ping"}`
+
+	signature := base64.StdEncoding.EncodeToString(ed25519.Sign(private, []byte(body)))
+	if status := postHook(t, app, body, map[string]string{"X-Signature": signature}); status != fiber.StatusOK {
+		t.Errorf("valid signature: status %d", status)
+	}
+	if status := postHook(t, app, body+" ", map[string]string{"X-Signature": signature}); status != fiber.StatusUnauthorized {
+		t.Errorf("modified body: status %d", status)
+	}
+	if status := postHook(t, app, body, nil); status != fiber.StatusUnauthorized {
+		t.Errorf("missing signature: status %d", status)
+	}
+}
@@ -0,0 +1,212 @@
+package middleware
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/alimzhanovlr/sdk/idgen"
+	"github.com/gofiber/fiber/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// SubjectKey is the locals key auth middleware stores the verified subject
+// (JWT sub claim, API key owner) under
+const SubjectKey = "subject"
+
+// ConcurrencyStore counts in-flight requests per key
+type ConcurrencyStore interface {
+	// Acquire takes a slot if fewer than limit are taken and returns its
+	// ID, ttl bounds slots leaked by crashed instances
+	Acquire(ctx context.Context, key string, limit int, ttl time.Duration) (slot string, ok bool, err error)
+	// Release frees a slot taken by Acquire
+	Release(ctx context.Context, key, slot string) error
+}
+
+// SubjectConcurrencyConfig holds per-subject concurrency limiting configuration
+type SubjectConcurrencyConfig struct {
+	Store ConcurrencyStore // Slot counters, defaults to in-memory store
+	Limit int              // Maximum in-flight requests per subject
+	TTL   time.Duration    // Lifetime of a slot in shared stores
+	// Subject identifies the caller, requests without subject are not
+	// limited. Defaults to the verified SubjectKey, anonymous requests share
+	// a bucket per client IP
+	Subject func(c *fiber.Ctx) string
+	Message string // Error message
+}
+
+// DefaultSubjectConcurrencyConfig returns default per-subject concurrency config
+func DefaultSubjectConcurrencyConfig() SubjectConcurrencyConfig {
+	return SubjectConcurrencyConfig{
+		Limit:   10,
+		TTL:     time.Minute,
+		Subject: SubjectFromLocals(SubjectKey, SubjectFromIP),
+		Message: "Too many concurrent requests, please try again later",
+	}
+}
+
+// SubjectFromLocals reads the subject set by auth middleware, fallbacks are
+// tried in order when it is missing
+func SubjectFromLocals(key string, fallbacks ...func(c *fiber.Ctx) string) func(c *fiber.Ctx) string {
+	return func(c *fiber.Ctx) string {
+		if subject, ok := c.Locals(key).(string); ok && subject != "" {
+			return subject
+		}
+		for _, fallback := range fallbacks {
+			if subject := fallback(c); subject != "" {
+				return subject
+			}
+		}
+		return ""
+	}
+}
+
+// SubjectFromIP identifies anonymous callers by client IP, behind a proxy
+// set fiber.Config.ProxyHeader so c.IP() is the client one
+func SubjectFromIP(c *fiber.Ctx) string {
+	return "ip:" + c.IP()
+}
+
+// SubjectFromHeader identifies the caller by a credential header such as an
+// API key, the value is hashed so it is not kept in the store. The header is
+// not verified, any client can pick a fresh value for a fresh bucket, so use
+// it only behind a gateway that authenticates the header
+func SubjectFromHeader(header string) func(c *fiber.Ctx) string {
+	return func(c *fiber.Ctx) string {
+		value := c.Get(header)
+		if value == "" {
+			return ""
+		}
+		sum := sha256.Sum256([]byte(value))
+		return header + ":" + hex.EncodeToString(sum[:16])
+	}
+}
+
+// SubjectConcurrencyMiddleware limits in-flight requests of every subject, so
+// a single noisy tenant cannot take all capacity. Excess requests get 429
+func SubjectConcurrencyMiddleware(config SubjectConcurrencyConfig) fiber.Handler {
+	defaults := DefaultSubjectConcurrencyConfig()
+	if config.Store == nil {
+		config.Store = NewMemoryConcurrencyStore()
+	}
+	if config.Limit <= 0 {
+		config.Limit = defaults.Limit
+	}
+	if config.TTL <= 0 {
+		config.TTL = defaults.TTL
+	}
+	if config.Subject == nil {
+		config.Subject = defaults.Subject
+	}
+	if config.Message == "" {
+		config.Message = defaults.Message
+	}
+
+	return func(c *fiber.Ctx) error {
+		subject := config.Subject(c)
+		if subject == "" {
+			return c.Next()
+		}
+
+		ctx := c.UserContext()
+		slot, ok, err := config.Store.Acquire(ctx, subject, config.Limit, config.TTL)
+		if err != nil {
+			return fmt.Errorf("failed to acquire concurrency slot: %w", err)
+		}
+		if !ok {
+			return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
+				"error": fiber.Map{
+					"code":    "concurrency_limit_exceeded",
+					"message": config.Message,
+				},
+			})
+		}
+
+		// Release with a fresh context, the request one may be canceled
+		defer config.Store.Release(context.WithoutCancel(ctx), subject, slot)
+
+		return c.Next()
+	}
+}
+
+// MemoryConcurrencyStore is ConcurrencyStore for a single instance
+type MemoryConcurrencyStore struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+// NewMemoryConcurrencyStore creates in-memory ConcurrencyStore
+func NewMemoryConcurrencyStore() *MemoryConcurrencyStore {
+	return &MemoryConcurrencyStore{counts: make(map[string]int)}
+}
+
+// Acquire implements ConcurrencyStore, slots are counted and ttl is not
+// needed in memory
+func (s *MemoryConcurrencyStore) Acquire(_ context.Context, key string, limit int, _ time.Duration) (string, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.counts[key] >= limit {
+		return "", false, nil
+	}
+	s.counts[key]++
+	return "", true, nil
+}
+
+// Release implements ConcurrencyStore
+func (s *MemoryConcurrencyStore) Release(_ context.Context, key, _ string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.counts[key] <= 1 {
+		delete(s.counts, key)
+	} else {
+		s.counts[key]--
+	}
+	return nil
+}
+
+// RedisConcurrencyStore is ConcurrencyStore shared by all instances
+type RedisConcurrencyStore struct {
+	client redis.UniversalClient
+	prefix string
+}
+
+// NewRedisConcurrencyStore creates Redis ConcurrencyStore, keys are stored under prefix
+func NewRedisConcurrencyStore(client redis.UniversalClient, prefix string) *RedisConcurrencyStore {
+	return &RedisConcurrencyStore{client: client, prefix: prefix}
+}
+
+// acquireScript drops slots older than the ttl, then adds a slot scored by
+// the server time unless limit are taken. Every slot expires on its own, so
+// busy subjects do not keep slots of crashed instances alive
+var acquireScript = redis.NewScript(`
+local time = redis.call("TIME")
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+local ttl = tonumber(ARGV[2])
+redis.call("ZREMRANGEBYSCORE", KEYS[1], "-inf", now - ttl)
+if redis.call("ZCARD", KEYS[1]) >= tonumber(ARGV[1]) then
+	return 0
+end
+redis.call("ZADD", KEYS[1], now, ARGV[3])
+redis.call("PEXPIRE", KEYS[1], ttl)
+return 1
+`)
+
+// Acquire implements ConcurrencyStore
+func (s *RedisConcurrencyStore) Acquire(ctx context.Context, key string, limit int, ttl time.Duration) (string, bool, error) {
+	slot := idgen.NewString()
+	taken, err := acquireScript.Run(ctx, s.client, []string{s.prefix + key}, limit, ttl.Milliseconds(), slot).Int()
+	if err != nil {
+		return "", false, err
+	}
+	return slot, taken == 1, nil
+}
+
+// Release implements ConcurrencyStore
+func (s *RedisConcurrencyStore) Release(ctx context.Context, key, slot string) error {
+	return s.client.ZRem(ctx, s.prefix+key, slot).Err()
+}
@@ -0,0 +1,154 @@
+package middleware
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type slotStore struct {
+	mu       sync.Mutex
+	taken    map[string]bool
+	released []string
+	keys     []string
+	next     int
+}
+
+func (s *slotStore) Acquire(_ context.Context, key string, limit int, _ time.Duration) (string, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys = append(s.keys, key)
+	if len(s.taken) >= limit {
+		return "", false, nil
+	}
+	s.next++
+	slot := string(rune('a' + s.next))
+	s.taken[slot] = true
+	return slot, true, nil
+}
+
+func (s *slotStore) Release(_ context.Context, _, slot string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.taken, slot)
+	s.released = append(s.released, slot)
+	return nil
+}
+
+func TestSubjectConcurrencyReleasesAcquiredSlot(t *testing.T) {
+	store := &slotStore{taken: map[string]bool{}}
+	app := fiber.New()
+	app.Use(SubjectConcurrencyMiddleware(SubjectConcurrencyConfig{
+		Store:   store,
+		Limit:   1,
+		Subject: SubjectFromHeader("X-API-Key"),
+	}))
+	app.Get("/", func(c *fiber.Ctx) error { return c.SendString("ok") })
+
+	for i := 0; i < 2; i++ {
+		req := httptest.NewRequest(fiber.MethodGet, "/", nil)
+		req.Header.Set("X-API-Key", "k1")
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != fiber.StatusOK {
+			t.Fatalf("request %d: status = %d", i, resp.StatusCode)
+		}
+	}
+	if len(store.released) != 2 || store.released[0] == store.released[1] {
+		t.Errorf("released = %v, want both slots", store.released)
+	}
+}
+
+func TestSubjectConcurrencyLimitsInFlight(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{}, 1)
+	app := fiber.New()
+	// Stands in for auth middleware
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals(SubjectKey, c.Get("X-User"))
+		return c.Next()
+	})
+	app.Use(SubjectConcurrencyMiddleware(SubjectConcurrencyConfig{Limit: 1}))
+	app.Get("/", func(c *fiber.Ctx) error {
+		started <- struct{}{}
+		<-release
+		return c.SendString("ok")
+	})
+
+	request := func(user string) int {
+		req := httptest.NewRequest(fiber.MethodGet, "/", nil)
+		req.Header.Set("X-User", user)
+		resp, err := app.Test(req, 5000)
+		if err != nil {
+			t.Error(err)
+			return 0
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	first := make(chan int)
+	go func() { first <- request("k1") }()
+	<-started
+
+	if status := request("k1"); status != fiber.StatusTooManyRequests {
+		t.Errorf("second request of the subject: status = %d, want 429", status)
+	}
+	go func() { <-started }()
+	close(release)
+	if status := request("k2"); status != fiber.StatusOK {
+		t.Errorf("other subject: status = %d, want 200", status)
+	}
+	if status := <-first; status != fiber.StatusOK {
+		t.Errorf("first request: status = %d", status)
+	}
+	if status := request("k1"); status != fiber.StatusOK {
+		t.Errorf("after release: status = %d, want 200", status)
+	}
+}
+
+func TestSubjectConcurrencyIgnoresUnverifiedHeader(t *testing.T) {
+	store := &slotStore{taken: map[string]bool{}}
+	app := fiber.New()
+	app.Use(SubjectConcurrencyMiddleware(SubjectConcurrencyConfig{Store: store, Limit: 10}))
+	app.Get("/", func(c *fiber.Ctx) error { return c.SendString("ok") })
+
+	for _, key := range []string{"k1", "k2"} {
+		req := httptest.NewRequest(fiber.MethodGet, "/", nil)
+		req.Header.Set("X-API-Key", key)
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+	if len(store.keys) != 2 || store.keys[0] != store.keys[1] || !strings.HasPrefix(store.keys[0], "ip:") {
+		t.Errorf("keys = %v, want one ip bucket for unverified api keys", store.keys)
+	}
+}
+
+func TestMemoryConcurrencyStore(t *testing.T) {
+	store := NewMemoryConcurrencyStore()
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if _, ok, _ := store.Acquire(ctx, "s", 2, time.Minute); !ok {
+			t.Fatalf("acquire %d failed", i)
+		}
+	}
+	if _, ok, _ := store.Acquire(ctx, "s", 2, time.Minute); ok {
+		t.Error("third slot must be refused")
+	}
+	_ = store.Release(ctx, "s", "")
+	if _, ok, _ := store.Acquire(ctx, "s", 2, time.Minute); !ok {
+		t.Error("released slot must be available")
+	}
+}
@@ -0,0 +1,89 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/alimzhanovlr/sdk/errors"
+	"github.com/alimzhanovlr/sdk/tenancy"
+	"github.com/gofiber/fiber/v2"
+)
+
+// Tenant errors
+var (
+	ErrTenantMissing = errors.New("tenant_missing", "Tenant is not specified", http.StatusBadRequest)
+	ErrTenantUnknown = errors.New("tenant_unknown", "Tenant does not exist", http.StatusForbidden)
+)
+
+// TenantConfig holds tenant resolution configuration
+type TenantConfig struct {
+	// Extractor returns the tenant ID. By default it is the tenant claim of
+	// a verified token, see TrustHeader
+	Extractor func(c *fiber.Ctx) string
+	Header    string
+	// TrustHeader reads Header for requests without claims, enable it only
+	// behind a gateway that sets the header itself. Claims still win, so a
+	// caller cannot switch tenants with it
+	TrustHeader bool
+	// Resolver loads tenant details, a nil one makes Tenant{ID: id}.
+	// Resolvers return ErrTenantUnknown or their own AppError for unknown IDs
+	Resolver tenancy.Resolver
+	// Optional lets requests without a tenant through, e.g. in a
+	// single-tenant deployment
+	Optional bool
+}
+
+// DefaultTenantConfig returns config reading the JWT tenant claim, the
+// X-Tenant-ID header is read with TrustHeader
+func DefaultTenantConfig() TenantConfig {
+	return TenantConfig{
+		Header: "X-Tenant-ID",
+	}
+}
+
+// TenantMiddleware stores the request tenant in the user context for
+// tenancy.FromContext and config.ForTenant, must run after JWTMiddleware
+// when tokens carry tenants
+func TenantMiddleware(config TenantConfig) fiber.Handler {
+	if config.Header == "" {
+		config.Header = "X-Tenant-ID"
+	}
+	if config.Extractor == nil {
+		config.Extractor = func(c *fiber.Ctx) string {
+			if claims := Claims(c); claims != nil {
+				return claims.Tenant
+			}
+			if config.TrustHeader {
+				return c.Get(config.Header)
+			}
+			return ""
+		}
+	}
+
+	return func(c *fiber.Ctx) error {
+		id := config.Extractor(c)
+		if id == "" {
+			if config.Optional {
+				return c.Next()
+			}
+			return sendAppError(c, ErrTenantMissing)
+		}
+		if err := tenancy.ValidateID(id); err != nil {
+			return sendAppError(c, ErrTenantUnknown)
+		}
+
+		tenant := tenancy.Tenant{ID: id}
+		if config.Resolver != nil {
+			var err error
+			if tenant, err = config.Resolver.Resolve(c.UserContext(), id); err != nil {
+				if errors.IsAppError(err) {
+					return sendAppError(c, errors.GetAppError(err))
+				}
+				return fmt.Errorf("failed to resolve tenant: %w", err)
+			}
+		}
+
+		c.SetUserContext(tenancy.WithTenant(c.UserContext(), tenant))
+		return c.Next()
+	}
+}
@@ -0,0 +1,116 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alimzhanovlr/sdk/auth"
+	"github.com/alimzhanovlr/sdk/tenancy"
+	"github.com/gofiber/fiber/v2"
+)
+
+func tenantApp(config TenantConfig, claims *auth.Claims) *fiber.App {
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		if claims != nil {
+			c.Locals(ClaimsKey, claims)
+		}
+		return c.Next()
+	})
+	app.Use(TenantMiddleware(config))
+	app.Get("/", func(c *fiber.Ctx) error {
+		tenant, _ := tenancy.FromContext(c.UserContext())
+		return c.SendString(tenant.ID + "/" + tenant.Name)
+	})
+	return app
+}
+
+func getTenant(t *testing.T, app *fiber.App, header string) (int, string) {
+	t.Helper()
+	req := httptest.NewRequest(fiber.MethodGet, "/", nil)
+	if header != "" {
+		req.Header.Set("X-Tenant-ID", header)
+	}
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body := make([]byte, 256)
+	n, _ := resp.Body.Read(body)
+	return resp.StatusCode, string(body[:n])
+}
+
+func TestTenantFromClaims(t *testing.T) {
+	app := tenantApp(DefaultTenantConfig(), &auth.Claims{Tenant: "acme"})
+
+	status, body := getTenant(t, app, "other")
+	if status != fiber.StatusOK || body != "acme/" {
+		t.Errorf("status = %d, body = %q, claim must win over the header", status, body)
+	}
+}
+
+func TestTenantHeaderIgnoredByDefault(t *testing.T) {
+	status, _ := getTenant(t, tenantApp(DefaultTenantConfig(), nil), "acme")
+	if status != fiber.StatusBadRequest {
+		t.Errorf("status = %d, want 400 without claims", status)
+	}
+}
+
+func TestTenantHeaderWithTrustHeader(t *testing.T) {
+	config := DefaultTenantConfig()
+	config.TrustHeader = true
+
+	status, body := getTenant(t, tenantApp(config, nil), "acme")
+	if status != fiber.StatusOK || body != "acme/" {
+		t.Errorf("status = %d, body = %q", status, body)
+	}
+}
+
+func TestTenantOptional(t *testing.T) {
+	config := DefaultTenantConfig()
+	config.Optional = true
+
+	status, body := getTenant(t, tenantApp(config, nil), "")
+	if status != fiber.StatusOK || body != "/" {
+		t.Errorf("status = %d, body = %q", status, body)
+	}
+}
+
+func TestTenantRejectsInvalidID(t *testing.T) {
+	status, _ := getTenant(t, tenantApp(DefaultTenantConfig(), &auth.Claims{Tenant: "../etc"}), "")
+	if status != fiber.StatusForbidden {
+		t.Errorf("status = %d, want 403", status)
+	}
+}
+
+func TestTenantResolver(t *testing.T) {
+	config := DefaultTenantConfig()
+	config.Resolver = tenancy.ResolverFunc(func(_ context.Context, id string) (tenancy.Tenant, error) {
+		switch id {
+		case "acme":
+			return tenancy.Tenant{ID: id, Name: "Acme"}, nil
+		case "down":
+			return tenancy.Tenant{}, errors.New("tenants table unavailable")
+		}
+		return tenancy.Tenant{}, ErrTenantUnknown
+	})
+
+	tests := []struct {
+		tenant string
+		status int
+		body   string
+	}{
+		{"acme", fiber.StatusOK, "acme/Acme"},
+		{"ghost", fiber.StatusForbidden, ""},
+		{"down", fiber.StatusInternalServerError, ""},
+	}
+	for _, tt := range tests {
+		status, body := getTenant(t, tenantApp(config, &auth.Claims{Tenant: tt.tenant}), "")
+		if status != tt.status || (tt.body != "" && body != tt.body) {
+			t.Errorf("%s: status = %d, body = %q", tt.tenant, status, body)
+		}
+	}
+}
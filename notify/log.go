@@ -0,0 +1,39 @@
+package notify
+
+import (
+	"context"
+
+	"github.com/alimzhanovlr/sdk/logger"
+	"go.uber.org/zap"
+)
+
+// LogProvider only logs messages, use it in development and for push until
+// a real provider is configured
+type LogProvider struct {
+	channel Channel
+	logger  *logger.Logger
+}
+
+// NewLogProvider creates logging provider for channel
+func NewLogProvider(channel Channel, log *logger.Logger) *LogProvider {
+	return &LogProvider{channel: channel, logger: log}
+}
+
+// Name implements Provider
+func (p *LogProvider) Name() string {
+	return "log"
+}
+
+// Channel implements Provider
+func (p *LogProvider) Channel() Channel {
+	return p.channel
+}
+
+// Send implements Provider
+func (p *LogProvider) Send(ctx context.Context, msg *Message) error {
+	p.logger.Debug("Notification delivery skipped by log provider",
+		zap.String("channel", string(msg.Channel)),
+		zap.Strings("to", maskRecipients(msg.To)),
+	)
+	return nil
+}
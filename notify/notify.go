@@ -0,0 +1,285 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/alimzhanovlr/sdk/httpclient"
+	"github.com/alimzhanovlr/sdk/i18n"
+	"github.com/alimzhanovlr/sdk/logger"
+	"github.com/alimzhanovlr/sdk/retry"
+	"go.uber.org/zap"
+)
+
+// Channel is a delivery channel
+type Channel string
+
+const (
+	ChannelEmail Channel = "email"
+	ChannelSMS   Channel = "sms"
+	ChannelPush  Channel = "push"
+)
+
+// Message is a notification to deliver
+type Message struct {
+	Channel Channel
+	To      []string
+	From    string
+	Subject string
+	// Body is plain text body
+	Body string
+	// HTML is optional HTML body for email
+	HTML string
+	// Data is custom payload for push notifications
+	Data map[string]string
+}
+
+// Provider delivers messages for one channel
+type Provider interface {
+	Name() string
+	Channel() Channel
+	Send(ctx context.Context, msg *Message) error
+}
+
+// permanentError marks errors that must not be retried
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent wraps err so that Notifier does not retry it
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// IsPermanent reports whether err must not be retried
+func IsPermanent(err error) bool {
+	var pe *permanentError
+	return errors.As(err, &pe)
+}
+
+// RecipientError reports recipients a provider failed to deliver to, the
+// others received the message. Notifier retries only the failed recipients
+// whose errors are not permanent
+type RecipientError struct {
+	Failed map[string]error
+}
+
+func (e *RecipientError) Error() string {
+	recipients := make([]string, 0, len(e.Failed))
+	for to := range e.Failed {
+		recipients = append(recipients, maskRecipient(to))
+	}
+	slices.Sort(recipients)
+	return fmt.Sprintf("failed to deliver to %d recipients: %s", len(e.Failed), strings.Join(recipients, ", "))
+}
+
+func (e *RecipientError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Failed))
+	for _, err := range e.Failed {
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+// Template references i18n message IDs used to render a notification
+type Template struct {
+	SubjectID string
+	BodyID    string
+	HTMLID    string
+}
+
+// RetryConfig holds retry configuration
+type RetryConfig struct {
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+// DefaultRetryConfig returns default retry config
+func DefaultRetryConfig() RetryConfig {
+	return RetryConfig{
+		MaxAttempts:    3,
+		InitialBackoff: 500 * time.Millisecond,
+		MaxBackoff:     10 * time.Second,
+	}
+}
+
+// Notifier routes messages to providers with retries and logging
+type Notifier struct {
+	providers map[Channel]Provider
+	i18n      *i18n.I18n
+	logger    *logger.Logger
+	sanitizer *httpclient.Sanitizer
+	retry     RetryConfig
+}
+
+// New creates a new notifier, translator may be nil if templates are not used
+func New(log *logger.Logger, translator *i18n.I18n, retry RetryConfig, providers ...Provider) *Notifier {
+	if retry.MaxAttempts < 1 {
+		retry.MaxAttempts = 1
+	}
+
+	n := &Notifier{
+		providers: make(map[Channel]Provider),
+		i18n:      translator,
+		logger:    log,
+		sanitizer: httpclient.NewSanitizer(nil),
+		retry:     retry,
+	}
+	for _, p := range providers {
+		n.providers[p.Channel()] = p
+	}
+	return n
+}
+
+// Send delivers message through provider registered for its channel. When
+// the provider reports a RecipientError, only the failed recipients are
+// retried, recipients that still fail are returned in a RecipientError
+func (n *Notifier) Send(ctx context.Context, msg *Message) error {
+	provider, ok := n.providers[msg.Channel]
+	if !ok {
+		return fmt.Errorf("no provider for channel %s", msg.Channel)
+	}
+	if len(msg.To) == 0 {
+		return fmt.Errorf("message has no recipients")
+	}
+
+	start := time.Now()
+	attempts := 0
+	pending := *msg
+	rejected := make(map[string]error)
+
+	err := retry.Do(ctx, retry.Policy{
+		MaxAttempts: n.retry.MaxAttempts,
+		Backoff:     retry.Exponential(n.retry.InitialBackoff, n.retry.MaxBackoff),
+		Retryable: func(err error) bool {
+			return !IsPermanent(err)
+		},
+		OnRetry: func(attempt int, err error, wait time.Duration) {
+			n.logger.Warn("Notification send failed, retrying",
+				zap.String("provider", provider.Name()),
+				zap.Int("attempt", attempt),
+				zap.Duration("backoff", wait),
+				zap.Error(err),
+			)
+		},
+	}, func(ctx context.Context) error {
+		attempts++
+		err := provider.Send(ctx, &pending)
+		var recipientErr *RecipientError
+		if !errors.As(err, &recipientErr) {
+			return err
+		}
+
+		failed := make(map[string]error)
+		for to, err := range recipientErr.Failed {
+			if IsPermanent(err) {
+				rejected[to] = err
+			} else {
+				failed[to] = err
+			}
+		}
+		pending.To = slices.DeleteFunc(slices.Clone(pending.To), func(to string) bool {
+			_, ok := failed[to]
+			return !ok
+		})
+		if len(failed) == 0 {
+			return nil
+		}
+		return &RecipientError{Failed: failed}
+	})
+	if len(rejected) > 0 {
+		var recipientErr *RecipientError
+		switch {
+		case err == nil:
+			err = &RecipientError{Failed: rejected}
+		case errors.As(err, &recipientErr):
+			maps.Copy(rejected, recipientErr.Failed)
+			err = &RecipientError{Failed: rejected}
+		default:
+			err = errors.Join(err, &RecipientError{Failed: rejected})
+		}
+	}
+
+	fields := []zap.Field{
+		zap.String("channel", string(msg.Channel)),
+		zap.String("provider", provider.Name()),
+		zap.Strings("to", maskRecipients(msg.To)),
+		zap.String("subject", n.sanitizer.SanitizeBody([]byte(msg.Subject), "text/plain")),
+		zap.String("body", n.sanitizer.SanitizeBody([]byte(msg.Body), "text/plain")),
+		zap.Int("attempts", attempts),
+		zap.Duration("duration", time.Since(start)),
+	}
+
+	if err != nil {
+		fields = append(fields, zap.Error(err))
+		n.logger.Error("Notification failed", fields...)
+		return fmt.Errorf("failed to send %s notification: %w", msg.Channel, err)
+	}
+
+	n.logger.Info("Notification sent", fields...)
+	return nil
+}
+
+// SendTemplate renders template in lang through i18n and sends it
+func (n *Notifier) SendTemplate(ctx context.Context, channel Channel, to []string, lang string, tmpl Template, data map[string]interface{}) error {
+	msg, err := n.Render(channel, to, lang, tmpl, data)
+	if err != nil {
+		return err
+	}
+	return n.Send(ctx, msg)
+}
+
+// Render builds message from template without sending it
+func (n *Notifier) Render(channel Channel, to []string, lang string, tmpl Template, data map[string]interface{}) (*Message, error) {
+	if n.i18n == nil {
+		return nil, fmt.Errorf("notifier has no i18n configured")
+	}
+
+	msg := &Message{
+		Channel: channel,
+		To:      to,
+	}
+	if tmpl.SubjectID != "" {
+		msg.Subject = n.i18n.T(lang, tmpl.SubjectID, data)
+	}
+	if tmpl.BodyID != "" {
+		msg.Body = n.i18n.T(lang, tmpl.BodyID, data)
+	}
+	if tmpl.HTMLID != "" {
+		msg.HTML = n.i18n.T(lang, tmpl.HTMLID, data)
+	}
+
+	return msg, nil
+}
+
+// maskRecipients hides recipient addresses keeping them distinguishable
+func maskRecipients(to []string) []string {
+	masked := make([]string, len(to))
+	for i, addr := range to {
+		masked[i] = maskRecipient(addr)
+	}
+	return masked
+}
+
+// maskRecipient keeps first character and email domain or last 2 phone digits
+func maskRecipient(addr string) string {
+	if at := strings.LastIndex(addr, "@"); at > 0 {
+		return addr[:1] + "***" + addr[at:]
+	}
+	if len(addr) > 4 {
+		return addr[:1] + "***" + addr[len(addr)-2:]
+	}
+	return "***"
+}
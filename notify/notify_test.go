@@ -0,0 +1,101 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/alimzhanovlr/sdk/logger"
+	"go.uber.org/zap"
+)
+
+// flakyProvider fails recipients listed in failures, the count is how many
+// sends fail for them, a negative count fails them permanently
+type flakyProvider struct {
+	failures map[string]int
+	sent     [][]string
+}
+
+func (p *flakyProvider) Name() string     { return "flaky" }
+func (p *flakyProvider) Channel() Channel { return ChannelSMS }
+
+func (p *flakyProvider) Send(_ context.Context, msg *Message) error {
+	p.sent = append(p.sent, slices.Clone(msg.To))
+
+	failed := make(map[string]error)
+	for _, to := range msg.To {
+		switch n := p.failures[to]; {
+		case n < 0:
+			failed[to] = Permanent(errors.New("invalid number"))
+		case n > 0:
+			p.failures[to]--
+			failed[to] = errors.New("rate limited")
+		}
+	}
+	if len(failed) > 0 {
+		return &RecipientError{Failed: failed}
+	}
+	return nil
+}
+
+func newTestNotifier(provider Provider) *Notifier {
+	return New(&logger.Logger{Logger: zap.NewNop()}, nil, RetryConfig{MaxAttempts: 3}, provider)
+}
+
+func TestSendRetriesOnlyFailedRecipients(t *testing.T) {
+	provider := &flakyProvider{failures: map[string]int{"+2": 1}}
+
+	err := newTestNotifier(provider).Send(context.Background(), &Message{Channel: ChannelSMS, To: []string{"+1", "+2", "+3"}})
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	want := [][]string{{"+1", "+2", "+3"}, {"+2"}}
+	if !slices.EqualFunc(provider.sent, want, slices.Equal[[]string]) {
+		t.Errorf("sent = %v, want %v", provider.sent, want)
+	}
+}
+
+func TestSendDoesNotRetryRejectedRecipients(t *testing.T) {
+	provider := &flakyProvider{failures: map[string]int{"+2": -1, "+3": 1}}
+
+	err := newTestNotifier(provider).Send(context.Background(), &Message{Channel: ChannelSMS, To: []string{"+1", "+2", "+3"}})
+
+	var recipientErr *RecipientError
+	if !errors.As(err, &recipientErr) {
+		t.Fatalf("err = %v, want RecipientError", err)
+	}
+	if len(recipientErr.Failed) != 1 || !IsPermanent(recipientErr.Failed["+2"]) {
+		t.Errorf("failed = %v", recipientErr.Failed)
+	}
+	want := [][]string{{"+1", "+2", "+3"}, {"+3"}}
+	if !slices.EqualFunc(provider.sent, want, slices.Equal[[]string]) {
+		t.Errorf("sent = %v, want %v", provider.sent, want)
+	}
+}
+
+func TestSendReportsRecipientsFailingEveryAttempt(t *testing.T) {
+	provider := &flakyProvider{failures: map[string]int{"+2": 10}}
+
+	err := newTestNotifier(provider).Send(context.Background(), &Message{Channel: ChannelSMS, To: []string{"+1", "+2"}})
+
+	var recipientErr *RecipientError
+	if !errors.As(err, &recipientErr) || len(recipientErr.Failed) != 1 || recipientErr.Failed["+2"] == nil {
+		t.Fatalf("err = %v", err)
+	}
+	if len(provider.sent) != 3 {
+		t.Errorf("attempts = %d, want 3", len(provider.sent))
+	}
+}
+
+func TestMessageIsNotModified(t *testing.T) {
+	provider := &flakyProvider{failures: map[string]int{"+2": 1}}
+	msg := &Message{Channel: ChannelSMS, To: []string{"+1", "+2"}}
+
+	if err := newTestNotifier(provider).Send(context.Background(), msg); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(msg.To, []string{"+1", "+2"}) {
+		t.Errorf("To = %v", msg.To)
+	}
+}
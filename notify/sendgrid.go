@@ -0,0 +1,124 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+const sendGridURL = "https://api.sendgrid.com/v3/mail/send"
+
+// SendGridConfig holds SendGrid provider configuration
+type SendGridConfig struct {
+	APIKey string
+	From   string
+	// HTTPClient is optional, use it to plug httpclient.LoggingRoundTripper
+	HTTPClient *http.Client
+}
+
+// SendGrid sends email through SendGrid v3 API
+type SendGrid struct {
+	config SendGridConfig
+	client *http.Client
+}
+
+// NewSendGrid creates SendGrid email provider
+func NewSendGrid(cfg SendGridConfig) *SendGrid {
+	client := cfg.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &SendGrid{config: cfg, client: client}
+}
+
+// Name implements Provider
+func (s *SendGrid) Name() string {
+	return "sendgrid"
+}
+
+// Channel implements Provider
+func (s *SendGrid) Channel() Channel {
+	return ChannelEmail
+}
+
+type sendGridAddress struct {
+	Email string `json:"email"`
+}
+
+type sendGridContent struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+type sendGridRequest struct {
+	Personalizations []struct {
+		To []sendGridAddress `json:"to"`
+	} `json:"personalizations"`
+	From    sendGridAddress   `json:"from"`
+	Subject string            `json:"subject"`
+	Content []sendGridContent `json:"content"`
+}
+
+// Send implements Provider
+func (s *SendGrid) Send(ctx context.Context, msg *Message) error {
+	from := msg.From
+	if from == "" {
+		from = s.config.From
+	}
+
+	payload := sendGridRequest{
+		From:    sendGridAddress{Email: from},
+		Subject: msg.Subject,
+		Content: []sendGridContent{{Type: "text/plain", Value: msg.Body}},
+	}
+	if msg.HTML != "" {
+		payload.Content = append(payload.Content, sendGridContent{Type: "text/html", Value: msg.HTML})
+	}
+
+	payload.Personalizations = make([]struct {
+		To []sendGridAddress `json:"to"`
+	}, 1)
+	for _, to := range msg.To {
+		payload.Personalizations[0].To = append(payload.Personalizations[0].To, sendGridAddress{Email: to})
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return Permanent(err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sendGridURL, bytes.NewReader(body))
+	if err != nil {
+		return Permanent(err)
+	}
+	req.Header.Set("Authorization", "Bearer "+s.config.APIKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("sendgrid request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	return checkResponse("sendgrid", resp)
+}
+
+// checkResponse maps provider HTTP status to retryable or permanent error
+func checkResponse(provider string, resp *http.Response) error {
+	if resp.StatusCode < 300 {
+		return nil
+	}
+
+	detail, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	err := fmt.Errorf("%s responded with status %d: %s", provider, resp.StatusCode, bytes.TrimSpace(detail))
+
+	// Retry rate limits and server errors only
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+		return err
+	}
+	return Permanent(err)
+}
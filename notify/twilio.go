@@ -0,0 +1,90 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const twilioURL = "https://api.twilio.com/2010-04-01/Accounts/%s/Messages.json"
+
+// TwilioConfig holds Twilio provider configuration
+type TwilioConfig struct {
+	AccountSID string
+	AuthToken  string
+	From       string
+	HTTPClient *http.Client
+}
+
+// Twilio sends SMS through Twilio REST API. It is a minimal stub covering
+// plain text messages only, use the official SDK for anything else
+type Twilio struct {
+	config TwilioConfig
+	client *http.Client
+}
+
+// NewTwilio creates Twilio SMS provider
+func NewTwilio(cfg TwilioConfig) *Twilio {
+	client := cfg.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &Twilio{config: cfg, client: client}
+}
+
+// Name implements Provider
+func (t *Twilio) Name() string {
+	return "twilio"
+}
+
+// Channel implements Provider
+func (t *Twilio) Channel() Channel {
+	return ChannelSMS
+}
+
+// Send implements Provider, sends one request per recipient and reports
+// failed ones in a RecipientError
+func (t *Twilio) Send(ctx context.Context, msg *Message) error {
+	from := msg.From
+	if from == "" {
+		from = t.config.From
+	}
+
+	failed := make(map[string]error)
+	for _, to := range msg.To {
+		if err := t.send(ctx, from, to, msg.Body); err != nil {
+			failed[to] = err
+		}
+	}
+	if len(failed) > 0 {
+		return &RecipientError{Failed: failed}
+	}
+	return nil
+}
+
+// send sends single SMS
+func (t *Twilio) send(ctx context.Context, from, to, body string) error {
+	form := url.Values{}
+	form.Set("From", from)
+	form.Set("To", to)
+	form.Set("Body", body)
+
+	endpoint := fmt.Sprintf(twilioURL, t.config.AccountSID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return Permanent(err)
+	}
+	req.SetBasicAuth(t.config.AccountSID, t.config.AuthToken)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("twilio request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	return checkResponse("twilio", resp)
+}
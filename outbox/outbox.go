@@ -0,0 +1,81 @@
+package outbox
+
+import (
+	"time"
+
+	"github.com/alimzhanovlr/sdk/idgen"
+	"github.com/alimzhanovlr/sdk/messaging"
+)
+
+// Event represents a message stored in the outbox table
+type Event struct {
+	ID          string
+	Topic       string
+	Key         []byte
+	Payload     []byte
+	Headers     map[string]string
+	CreatedAt   time.Time
+	Attempts    int
+	LastError   string
+	PublishedAt *time.Time
+}
+
+// NewEvent creates a new outbox event
+func NewEvent(topic string, key, payload []byte) *Event {
+	return &Event{
+		ID:        idgen.NewString(),
+		Topic:     topic,
+		Key:       key,
+		Payload:   payload,
+		Headers:   make(map[string]string),
+		CreatedAt: time.Now().UTC(),
+	}
+}
+
+// Message converts event to messaging message
+func (e *Event) Message() *messaging.Message {
+	headers := make(map[string]string, len(e.Headers)+1)
+	for k, v := range e.Headers {
+		headers[k] = v
+	}
+	headers[HeaderEventID] = e.ID
+
+	return &messaging.Message{
+		Topic:     e.Topic,
+		Key:       e.Key,
+		Value:     e.Payload,
+		Headers:   headers,
+		Timestamp: e.CreatedAt,
+	}
+}
+
+// HeaderEventID lets consumers deduplicate redelivered events
+const HeaderEventID = "x-outbox-event-id"
+
+// DefaultTable is the default outbox table name
+const DefaultTable = "outbox_events"
+
+// Schema returns PostgreSQL DDL for the outbox table
+func Schema(table string) string {
+	if table == "" {
+		table = DefaultTable
+	}
+
+	return `CREATE TABLE IF NOT EXISTS ` + table + ` (
+	id           TEXT PRIMARY KEY,
+	topic        TEXT NOT NULL,
+	key          BYTEA,
+	payload      BYTEA NOT NULL,
+	headers      JSONB NOT NULL DEFAULT '{}',
+	created_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+	attempts     INT NOT NULL DEFAULT 0,
+	last_error   TEXT NOT NULL DEFAULT '',
+	locked_until TIMESTAMPTZ,
+	published_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS ` + table + `_pending_idx
+	ON ` + table + ` (created_at)
+	WHERE published_at IS NULL;
+`
+}
@@ -0,0 +1,326 @@
+package outbox
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/alimzhanovlr/sdk/logger"
+	"github.com/alimzhanovlr/sdk/messaging"
+	"github.com/alimzhanovlr/sdk/metrics"
+	"github.com/alimzhanovlr/sdk/retry"
+	"github.com/alimzhanovlr/sdk/workerpool"
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/fx"
+)
+
+// RelayConfig holds relay configuration
+type RelayConfig struct {
+	PollInterval time.Duration
+	BatchSize    int
+	LockTimeout  time.Duration
+	MaxAttempts  int // 0 retries forever
+	// RetryBackoff is how long a failed event stays locked, by attempt
+	RetryBackoff retry.Backoff
+	// Workers publishing a batch concurrently, 1 keeps the claim order
+	// within a batch: it stops at the first failure and the rest waits with
+	// it. Order is not guaranteed across batches, not even per aggregate:
+	// events inserted while a failed one backs off may be claimed and
+	// published before it. More workers speed up slow brokers and reorder
+	// within a batch too, so consumers must tolerate reordering either way
+	Workers int
+	Metrics *metrics.Registry // Optional
+}
+
+// DefaultRelayConfig returns default relay config
+func DefaultRelayConfig() RelayConfig {
+	return RelayConfig{
+		PollInterval: time.Second,
+		BatchSize:    100,
+		LockTimeout:  30 * time.Second,
+		MaxAttempts:  0,
+		RetryBackoff: retry.Exponential(time.Second, 5*time.Minute),
+		Workers:      1,
+	}
+}
+
+// Stats holds relay counters
+type Stats struct {
+	Published uint64
+	Failed    uint64
+	Polls     uint64
+	LastError string
+}
+
+// Relay publishes pending outbox events with at-least-once semantics
+type Relay struct {
+	repo      *Repository
+	publisher messaging.Publisher
+	logger    *logger.Logger
+	config    RelayConfig
+
+	published atomic.Uint64
+	failed    atomic.Uint64
+	polls     atomic.Uint64
+	lastError atomic.Value
+
+	metrics *relayMetrics
+	pool    *workerpool.Pool
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+// NewRelay creates a new outbox relay
+func NewRelay(repo *Repository, publisher messaging.Publisher, log *logger.Logger, cfg RelayConfig) *Relay {
+	defaults := DefaultRelayConfig()
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = defaults.PollInterval
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = defaults.BatchSize
+	}
+	if cfg.LockTimeout <= 0 {
+		cfg.LockTimeout = defaults.LockTimeout
+	}
+	if cfg.RetryBackoff == nil {
+		cfg.RetryBackoff = defaults.RetryBackoff
+	}
+	if cfg.Workers <= 0 {
+		cfg.Workers = defaults.Workers
+	}
+
+	r := &Relay{
+		repo:      repo,
+		publisher: publisher,
+		logger:    log,
+		config:    cfg,
+	}
+	if cfg.Metrics != nil {
+		r.metrics = newRelayMetrics(cfg.Metrics)
+	}
+
+	return r
+}
+
+type relayMetrics struct {
+	claimed       metrics.Counter
+	claimErrors   metrics.Counter
+	claimDuration metrics.Histogram
+	events        metrics.Counter
+	lag           metrics.Histogram
+}
+
+func newRelayMetrics(reg *metrics.Registry) *relayMetrics {
+	return &relayMetrics{
+		claimed:       reg.Counter("outbox_claimed_events_total", "Number of events claimed by the relay"),
+		claimErrors:   reg.Counter("outbox_claim_errors_total", "Number of failed claim queries"),
+		claimDuration: reg.Histogram("outbox_claim_duration_seconds", "Claim query duration", metrics.DefaultDurationBuckets...),
+		events:        reg.Counter("outbox_events_total", "Number of publish attempts by result"),
+		lag: reg.Histogram("outbox_event_lag_seconds", "Time from saving an event to its publish attempt",
+			0.01, 0.05, 0.1, 0.5, 1, 2.5, 5, 10, 30, 60, 300, 900),
+	}
+}
+
+func (m *relayMetrics) observeClaim(claimed int, duration time.Duration, err error) {
+	ctx := context.Background()
+	m.claimDuration.Record(ctx, duration.Seconds(), attribute.Bool("error", err != nil))
+	if err != nil {
+		m.claimErrors.Add(ctx, 1)
+		return
+	}
+	m.claimed.Add(ctx, float64(claimed))
+}
+
+// Run polls the outbox until ctx is cancelled
+func (r *Relay) Run(ctx context.Context) {
+	if r.config.Workers > 1 {
+		r.pool = workerpool.New(workerpool.Config{
+			Name:      "outbox_relay",
+			Workers:   r.config.Workers,
+			QueueSize: r.config.BatchSize,
+		})
+		defer func() {
+			// Batches are awaited by poll, the pool is idle here
+			_ = r.pool.Shutdown(context.Background())
+		}()
+	}
+
+	ticker := time.NewTicker(r.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		// Drain fully published batches without waiting for the next tick,
+		// a failure waits for the tick and the event's backoff
+		for r.poll(ctx) == r.config.BatchSize {
+			if ctx.Err() != nil {
+				return
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// poll claims and publishes one batch, returns number of published events
+func (r *Relay) poll(ctx context.Context) int {
+	r.polls.Add(1)
+
+	start := time.Now()
+	events, err := r.repo.Claim(ctx, r.config.BatchSize, r.config.LockTimeout, r.config.MaxAttempts)
+	if r.metrics != nil && ctx.Err() == nil {
+		r.metrics.observeClaim(len(events), time.Since(start), err)
+	}
+	if err != nil {
+		if ctx.Err() == nil {
+			r.recordError(err)
+			r.logger.Error("Failed to claim outbox events", logger.Error(err))
+		}
+		return 0
+	}
+
+	if r.pool == nil {
+		for i, event := range events {
+			if !r.publish(ctx, event) {
+				// Later events wait as long as the failed one to keep the order
+				r.release(ctx, events[i+1:], r.config.RetryBackoff.Delay(event.Attempts))
+				return i
+			}
+		}
+		return len(events)
+	}
+
+	var (
+		wg        sync.WaitGroup
+		published atomic.Int64
+	)
+	for _, event := range events {
+		wg.Add(1)
+		err := r.pool.Submit(ctx, func(context.Context) error {
+			defer wg.Done()
+			if r.publish(ctx, event) {
+				published.Add(1)
+			}
+			return nil
+		})
+		if err != nil {
+			// Cancelled, the rest is claimed again after lock expiry
+			wg.Done()
+			break
+		}
+	}
+	wg.Wait()
+
+	return int(published.Load())
+}
+
+// publish sends one claimed event and marks its outcome, false when the
+// broker rejected it
+func (r *Relay) publish(ctx context.Context, event *Event) bool {
+	if err := r.publisher.Publish(ctx, event.Topic, event.Message()); err != nil {
+		r.failed.Add(1)
+		r.recordError(err)
+		r.observe(event, "failed")
+		r.logger.Error("Failed to publish outbox event",
+			logger.String("event_id", event.ID),
+			logger.String("topic", event.Topic),
+			logger.Int("attempts", event.Attempts),
+			logger.Error(err),
+		)
+		retryAfter := r.config.RetryBackoff.Delay(event.Attempts)
+		if markErr := r.repo.MarkFailed(context.WithoutCancel(ctx), event.ID, err, retryAfter); markErr != nil {
+			r.logger.Error("Failed to mark outbox event failed", logger.Error(markErr))
+		}
+		return false
+	}
+
+	// If this fails the event is published again after lock expiry,
+	// consumers deduplicate by HeaderEventID
+	if err := r.repo.MarkPublished(context.WithoutCancel(ctx), event.ID); err != nil {
+		r.recordError(err)
+		r.observe(event, "unmarked")
+		r.logger.Error("Failed to mark outbox event published",
+			logger.String("event_id", event.ID),
+			logger.Error(err),
+		)
+		return true
+	}
+	r.published.Add(1)
+	r.observe(event, "published")
+	return true
+}
+
+// release returns claimed events that were not attempted
+func (r *Relay) release(ctx context.Context, events []*Event, retryAfter time.Duration) {
+	for _, event := range events {
+		if err := r.repo.Release(context.WithoutCancel(ctx), event.ID, retryAfter); err != nil {
+			r.logger.Error("Failed to release outbox event",
+				logger.String("event_id", event.ID),
+				logger.Error(err),
+			)
+		}
+	}
+}
+
+// observe records a publish attempt, unmarked events are published but
+// sent again after lock expiry
+func (r *Relay) observe(event *Event, result string) {
+	if r.metrics == nil {
+		return
+	}
+
+	ctx := context.Background()
+	attrs := []attribute.KeyValue{
+		attribute.String("topic", event.Topic),
+		attribute.String("result", result),
+	}
+	r.metrics.events.Add(ctx, 1, attrs...)
+	r.metrics.lag.Record(ctx, time.Since(event.CreatedAt).Seconds(), attrs...)
+}
+
+// Stats returns relay counters
+func (r *Relay) Stats() Stats {
+	lastError, _ := r.lastError.Load().(string)
+	return Stats{
+		Published: r.published.Load(),
+		Failed:    r.failed.Load(),
+		Polls:     r.polls.Load(),
+		LastError: lastError,
+	}
+}
+
+func (r *Relay) recordError(err error) {
+	r.lastError.Store(err.Error())
+}
+
+// Start registers relay in fx lifecycle
+func (r *Relay) Start(lc fx.Lifecycle) {
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			r.logger.Info("Starting outbox relay")
+
+			runCtx, cancel := context.WithCancel(context.Background())
+			r.cancel = cancel
+
+			r.wg.Add(1)
+			go func() {
+				defer r.wg.Done()
+				r.Run(runCtx)
+			}()
+
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			r.logger.Info("Shutting down outbox relay")
+			if r.cancel != nil {
+				r.cancel()
+			}
+			r.wg.Wait()
+			return nil
+		},
+	})
+}
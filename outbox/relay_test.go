@@ -0,0 +1,264 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alimzhanovlr/sdk/logger"
+	"github.com/alimzhanovlr/sdk/messaging"
+	"github.com/alimzhanovlr/sdk/metrics"
+	"github.com/alimzhanovlr/sdk/retry"
+	"go.uber.org/zap"
+)
+
+// fakeDriver returns claimed for the first query, or for every query with
+// repeat, and logs executed statements with their first argument
+type fakeDriver struct {
+	mu      sync.Mutex
+	claimed [][]driver.Value
+	repeat  bool
+	queries int
+	execs   []string
+}
+
+func (d *fakeDriver) Open(string) (driver.Conn, error) { return &fakeConn{driver: d}, nil }
+
+func (d *fakeDriver) Connect(context.Context) (driver.Conn, error) { return d.Open("") }
+func (d *fakeDriver) Driver() driver.Driver                        { return d }
+
+func (d *fakeDriver) executed() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]string(nil), d.execs...)
+}
+
+type fakeConn struct {
+	driver *fakeDriver
+}
+
+func (c *fakeConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (c *fakeConn) Close() error                        { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error)           { return nil, errors.New("not supported") }
+
+func (c *fakeConn) QueryContext(context.Context, string, []driver.NamedValue) (driver.Rows, error) {
+	c.driver.mu.Lock()
+	defer c.driver.mu.Unlock()
+	c.driver.queries++
+	rows := &fakeRows{values: c.driver.claimed}
+	if !c.driver.repeat {
+		c.driver.claimed = nil
+	}
+	return rows, nil
+}
+
+func (c *fakeConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.driver.mu.Lock()
+	defer c.driver.mu.Unlock()
+	entry := "published " + args[0].Value.(string)
+	switch {
+	case strings.Contains(query, "last_error = $2"):
+		entry = fmt.Sprintf("failed %s for %dms", args[0].Value, args[2].Value)
+	case strings.Contains(query, "attempts = attempts - 1"):
+		entry = fmt.Sprintf("released %s for %dms", args[0].Value, args[1].Value)
+	}
+	c.driver.execs = append(c.driver.execs, entry)
+	return driver.RowsAffected(1), nil
+}
+
+type fakeRows struct {
+	values [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string {
+	return []string{"id", "topic", "key", "payload", "headers", "created_at", "attempts", "last_error", "published_at"}
+}
+
+func (r *fakeRows) Close() error { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
+
+func claimedRow(id, topic string, createdAt time.Time) []driver.Value {
+	return []driver.Value{id, topic, []byte(nil), []byte("{}"), []byte(`{"trace":"1"}`), createdAt, int64(1), "", nil}
+}
+
+type fakePublisher struct {
+	mu        sync.Mutex
+	calls     []string
+	published []string
+	fail      map[string]bool
+}
+
+func (p *fakePublisher) Publish(_ context.Context, topic string, msgs ...*messaging.Message) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, msg := range msgs {
+		p.calls = append(p.calls, msg.Headers[HeaderEventID])
+	}
+	if p.fail[topic] {
+		return errors.New("broker unavailable")
+	}
+	for _, msg := range msgs {
+		p.published = append(p.published, msg.Headers[HeaderEventID])
+	}
+	return nil
+}
+
+func (p *fakePublisher) Close() error { return nil }
+
+func TestClaimSortsClaimedRows(t *testing.T) {
+	created := time.Now().Add(-time.Minute)
+	// RETURNING hands rows back in update order, not in the subquery order
+	d := &fakeDriver{claimed: [][]driver.Value{
+		claimedRow("evt-3", "orders", created.Add(time.Second)),
+		claimedRow("evt-4", "orders", created.Add(2*time.Second)),
+		claimedRow("evt-2", "orders", created),
+		claimedRow("evt-1", "orders", created),
+	}}
+	db := sql.OpenDB(d)
+	defer db.Close()
+
+	publisher := &fakePublisher{}
+	relay := NewRelay(NewRepository(db, ""), publisher, &logger.Logger{Logger: zap.NewNop()}, RelayConfig{BatchSize: 10})
+	if got := relay.poll(context.Background()); got != 4 {
+		t.Fatalf("poll() = %d, want 4", got)
+	}
+
+	if got := strings.Join(publisher.published, ","); got != "evt-1,evt-2,evt-3,evt-4" {
+		t.Errorf("published = %s, want created_at, id order", got)
+	}
+	if got := strings.Join(d.executed(), ","); got != "published evt-1,published evt-2,published evt-3,published evt-4" {
+		t.Errorf("marked = %s", got)
+	}
+}
+
+func TestRelayPublishesBatchAndRecordsMetrics(t *testing.T) {
+	created := time.Now().Add(-time.Minute)
+	d := &fakeDriver{claimed: [][]driver.Value{
+		claimedRow("evt-1", "orders", created),
+		claimedRow("evt-2", "orders", created.Add(time.Second)),
+		claimedRow("evt-3", "payments", created.Add(2*time.Second)),
+	}}
+	db := sql.OpenDB(d)
+	defer db.Close()
+
+	reg, err := metrics.New(metrics.Config{Enabled: true, ServiceName: "outbox-test"})
+	if err != nil {
+		t.Fatalf("metrics.New() error = %v", err)
+	}
+
+	publisher := &fakePublisher{fail: map[string]bool{"payments": true}}
+	relay := NewRelay(NewRepository(db, ""), publisher, &logger.Logger{Logger: zap.NewNop()}, RelayConfig{
+		BatchSize: 10,
+		Metrics:   reg,
+	})
+
+	if got := relay.poll(context.Background()); got != 2 {
+		t.Fatalf("poll() = %d, want 2", got)
+	}
+
+	if got := strings.Join(publisher.published, ","); got != "evt-1,evt-2" {
+		t.Errorf("published = %s, want evt-1,evt-2", got)
+	}
+	if got := strings.Join(d.executed(), ","); got != "published evt-1,published evt-2,failed evt-3 for 1000ms" {
+		t.Errorf("marked = %s", got)
+	}
+
+	stats := relay.Stats()
+	if stats.Published != 2 || stats.Failed != 1 || stats.Polls != 1 || stats.LastError != "broker unavailable" {
+		t.Errorf("Stats() = %+v", stats)
+	}
+
+	rec := httptest.NewRecorder()
+	reg.HTTPHandler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body := rec.Body.String()
+	for _, want := range []string{
+		"outbox_claimed_events_total",
+		`outbox_events_total{otel_scope_name="outbox-test",otel_scope_schema_url="",otel_scope_version="",result="published",topic="orders"} 2`,
+		`outbox_events_total{otel_scope_name="outbox-test",otel_scope_schema_url="",otel_scope_version="",result="failed",topic="payments"} 1`,
+		"outbox_event_lag_seconds_bucket",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("metrics do not contain %s\n%s", want, body)
+		}
+	}
+}
+
+func TestRelayStopsBatchAtFirstFailure(t *testing.T) {
+	created := time.Now().Add(-time.Minute)
+	d := &fakeDriver{claimed: [][]driver.Value{
+		claimedRow("evt-1", "orders", created),
+		claimedRow("evt-2", "payments", created.Add(time.Second)),
+		claimedRow("evt-3", "orders", created.Add(2*time.Second)),
+		claimedRow("evt-4", "orders", created.Add(3*time.Second)),
+	}}
+	db := sql.OpenDB(d)
+	defer db.Close()
+
+	publisher := &fakePublisher{fail: map[string]bool{"payments": true}}
+	relay := NewRelay(NewRepository(db, ""), publisher, &logger.Logger{Logger: zap.NewNop()}, RelayConfig{
+		BatchSize:    10,
+		RetryBackoff: retry.Constant(5 * time.Second),
+	})
+
+	if got := relay.poll(context.Background()); got != 1 {
+		t.Fatalf("poll() = %d, want 1", got)
+	}
+
+	// Events after the failed one are not attempted and wait with it, so
+	// they are claimed again behind it
+	if got := strings.Join(publisher.calls, ","); got != "evt-1,evt-2" {
+		t.Errorf("publish calls = %s, want evt-1,evt-2", got)
+	}
+	want := "published evt-1,failed evt-2 for 5000ms,released evt-3 for 5000ms,released evt-4 for 5000ms"
+	if got := strings.Join(d.executed(), ","); got != want {
+		t.Errorf("marked = %s, want %s", got, want)
+	}
+}
+
+func TestRunDoesNotDrainFailingBatches(t *testing.T) {
+	created := time.Now().Add(-time.Minute)
+	d := &fakeDriver{repeat: true, claimed: [][]driver.Value{
+		claimedRow("evt-1", "orders", created),
+		claimedRow("evt-2", "orders", created.Add(time.Second)),
+	}}
+	db := sql.OpenDB(d)
+	defer db.Close()
+
+	publisher := &fakePublisher{fail: map[string]bool{"orders": true}}
+	relay := NewRelay(NewRepository(db, ""), publisher, &logger.Logger{Logger: zap.NewNop()}, RelayConfig{
+		BatchSize:    2,
+		PollInterval: time.Hour,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	relay.Run(ctx)
+
+	// A full batch that failed waits for the next tick instead of being
+	// claimed again right away
+	d.mu.Lock()
+	queries := d.queries
+	d.mu.Unlock()
+	if queries != 1 {
+		t.Errorf("claim queries = %d, want 1", queries)
+	}
+	if got := strings.Join(publisher.calls, ","); got != "evt-1" {
+		t.Errorf("publish calls = %s, want evt-1", got)
+	}
+}
@@ -0,0 +1,184 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Execer is implemented by *sql.DB and *sql.Tx
+type Execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// Repository persists outbox events in PostgreSQL
+type Repository struct {
+	db    *sql.DB
+	table string
+}
+
+// NewRepository creates a new outbox repository
+func NewRepository(db *sql.DB, table string) *Repository {
+	if table == "" {
+		table = DefaultTable
+	}
+	return &Repository{db: db, table: table}
+}
+
+// Migrate creates the outbox table if it does not exist
+func (r *Repository) Migrate(ctx context.Context) error {
+	if _, err := r.db.ExecContext(ctx, Schema(r.table)); err != nil {
+		return fmt.Errorf("failed to create outbox table: %w", err)
+	}
+	return nil
+}
+
+// Save stores events using the caller's transaction, so they are committed
+// atomically with the state change that produced them
+func (r *Repository) Save(ctx context.Context, tx Execer, events ...*Event) error {
+	query := `INSERT INTO ` + r.table + ` (id, topic, key, payload, headers, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)`
+
+	for _, e := range events {
+		headers, err := json.Marshal(e.Headers)
+		if err != nil {
+			return fmt.Errorf("failed to marshal event headers: %w", err)
+		}
+
+		if _, err := tx.ExecContext(ctx, query, e.ID, e.Topic, e.Key, e.Payload, headers, e.CreatedAt); err != nil {
+			return fmt.Errorf("failed to save outbox event: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Claim locks up to limit pending events for lockFor so concurrent relays
+// do not publish the same batch, events are returned oldest first
+func (r *Repository) Claim(ctx context.Context, limit int, lockFor time.Duration, maxAttempts int) ([]*Event, error) {
+	query := `UPDATE ` + r.table + ` SET locked_until = now() + $2 * interval '1 millisecond', attempts = attempts + 1
+		WHERE id IN (
+			SELECT id FROM ` + r.table + `
+			WHERE published_at IS NULL
+				AND (locked_until IS NULL OR locked_until < now())
+				AND ($3 <= 0 OR attempts < $3)
+			ORDER BY created_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, topic, key, payload, headers, created_at, attempts, last_error, published_at`
+
+	rows, err := r.db.QueryContext(ctx, query, limit, lockFor.Milliseconds(), maxAttempts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim outbox events: %w", err)
+	}
+	defer rows.Close()
+
+	events, err := scanEvents(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	// RETURNING does not keep the order of the subquery, sort the claimed rows
+	slices.SortFunc(events, func(a, b *Event) int {
+		if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+	return events, nil
+}
+
+// MarkPublished marks event as published
+func (r *Repository) MarkPublished(ctx context.Context, id string) error {
+	query := `UPDATE ` + r.table + ` SET published_at = now(), locked_until = NULL, last_error = '' WHERE id = $1`
+	if _, err := r.db.ExecContext(ctx, query, id); err != nil {
+		return fmt.Errorf("failed to mark outbox event published: %w", err)
+	}
+	return nil
+}
+
+// MarkFailed records publish error and keeps the event locked for
+// retryAfter, so a broken broker is not hit again right away
+func (r *Repository) MarkFailed(ctx context.Context, id string, publishErr error, retryAfter time.Duration) error {
+	query := `UPDATE ` + r.table + ` SET locked_until = now() + $3 * interval '1 millisecond', last_error = $2 WHERE id = $1`
+	if _, err := r.db.ExecContext(ctx, query, id, publishErr.Error(), retryAfter.Milliseconds()); err != nil {
+		return fmt.Errorf("failed to mark outbox event failed: %w", err)
+	}
+	return nil
+}
+
+// Release returns a claimed but not attempted event, it is claimed again
+// after retryAfter without counting the attempt
+func (r *Repository) Release(ctx context.Context, id string, retryAfter time.Duration) error {
+	query := `UPDATE ` + r.table + ` SET locked_until = now() + $2 * interval '1 millisecond', attempts = attempts - 1 WHERE id = $1`
+	if _, err := r.db.ExecContext(ctx, query, id, retryAfter.Milliseconds()); err != nil {
+		return fmt.Errorf("failed to release outbox event: %w", err)
+	}
+	return nil
+}
+
+// ListStuck returns unpublished events older than olderThan
+func (r *Repository) ListStuck(ctx context.Context, olderThan time.Duration, limit int) ([]*Event, error) {
+	query := `SELECT id, topic, key, payload, headers, created_at, attempts, last_error, published_at
+		FROM ` + r.table + `
+		WHERE published_at IS NULL AND created_at < now() - $1 * interval '1 millisecond'
+		ORDER BY created_at
+		LIMIT $2`
+
+	rows, err := r.db.QueryContext(ctx, query, olderThan.Milliseconds(), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stuck outbox events: %w", err)
+	}
+	defer rows.Close()
+
+	return scanEvents(rows)
+}
+
+// Reset clears attempts and lock so the relay picks the event up again
+func (r *Repository) Reset(ctx context.Context, id string) error {
+	query := `UPDATE ` + r.table + ` SET attempts = 0, locked_until = NULL WHERE id = $1 AND published_at IS NULL`
+	res, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to reset outbox event: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("outbox event %s not found or already published", id)
+	}
+	return nil
+}
+
+// Cleanup deletes events published before retention period
+func (r *Repository) Cleanup(ctx context.Context, retention time.Duration) (int64, error) {
+	query := `DELETE FROM ` + r.table + ` WHERE published_at < now() - $1 * interval '1 millisecond'`
+	res, err := r.db.ExecContext(ctx, query, retention.Milliseconds())
+	if err != nil {
+		return 0, fmt.Errorf("failed to cleanup outbox events: %w", err)
+	}
+	return res.RowsAffected()
+}
+
+// scanEvents reads events from rows
+func scanEvents(rows *sql.Rows) ([]*Event, error) {
+	var events []*Event
+	for rows.Next() {
+		var (
+			e       Event
+			headers []byte
+		)
+		if err := rows.Scan(&e.ID, &e.Topic, &e.Key, &e.Payload, &headers, &e.CreatedAt, &e.Attempts, &e.LastError, &e.PublishedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan outbox event: %w", err)
+		}
+		if len(headers) > 0 {
+			if err := json.Unmarshal(headers, &e.Headers); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal event headers: %w", err)
+			}
+		}
+		events = append(events, &e)
+	}
+	return events, rows.Err()
+}
@@ -0,0 +1,152 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/alimzhanovlr/sdk/errors"
+	"github.com/alimzhanovlr/sdk/server"
+	"github.com/gofiber/fiber/v2"
+)
+
+// Default limits
+const (
+	DefaultPerPage = 20
+	MaxPerPage     = 100
+)
+
+// Query parameter names
+const (
+	ParamPage    = "page"
+	ParamPerPage = "per_page"
+	ParamCursor  = "cursor"
+	ParamLimit   = "limit"
+)
+
+// HeaderTotalCount carries total number of items
+const HeaderTotalCount = "X-Total-Count"
+
+// Page is offset pagination request, the zero value is the first page of
+// DefaultPerPage items
+type Page struct {
+	Page    int
+	PerPage int
+}
+
+// Limit returns SQL limit
+func (p Page) Limit() int {
+	return p.normalize().PerPage
+}
+
+// Offset returns SQL offset
+func (p Page) Offset() int {
+	p = p.normalize()
+	return (p.Page - 1) * p.PerPage
+}
+
+// Meta returns response metadata for total items
+func (p Page) Meta(total int) *server.Meta {
+	p = p.normalize()
+	return server.CalculateMeta(p.Page, p.PerPage, total)
+}
+
+// normalize replaces values ParsePage never returns with defaults
+func (p Page) normalize() Page {
+	if p.Page < 1 {
+		p.Page = 1
+	}
+	if p.PerPage <= 0 {
+		p.PerPage = DefaultPerPage
+	}
+	return p
+}
+
+// Cursor is keyset pagination request
+type Cursor struct {
+	// After is opaque cursor of the last seen item, empty for first page
+	After string
+	Limit int
+}
+
+// Decode decodes cursor into v, returns false for first page
+func (c Cursor) Decode(v interface{}) (bool, error) {
+	if c.After == "" {
+		return false, nil
+	}
+	if err := DecodeCursor(c.After, v); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// ParsePage reads page and per_page query params
+func ParsePage(c *fiber.Ctx) (Page, error) {
+	page := c.QueryInt(ParamPage, 1)
+	perPage := c.QueryInt(ParamPerPage, DefaultPerPage)
+
+	if page < 1 {
+		return Page{}, invalidParam(ParamPage, "must be greater than 0")
+	}
+	if perPage < 1 || perPage > MaxPerPage {
+		return Page{}, invalidParam(ParamPerPage, fmt.Sprintf("must be between 1 and %d", MaxPerPage))
+	}
+
+	return Page{Page: page, PerPage: perPage}, nil
+}
+
+// ParseCursor reads cursor and limit query params
+func ParseCursor(c *fiber.Ctx) (Cursor, error) {
+	limit := c.QueryInt(ParamLimit, DefaultPerPage)
+	if limit < 1 || limit > MaxPerPage {
+		return Cursor{}, invalidParam(ParamLimit, fmt.Sprintf("must be between 1 and %d", MaxPerPage))
+	}
+
+	after := c.Query(ParamCursor)
+	if after != "" {
+		if _, err := base64.RawURLEncoding.DecodeString(after); err != nil {
+			return Cursor{}, invalidParam(ParamCursor, "malformed cursor")
+		}
+	}
+
+	return Cursor{After: after, Limit: limit}, nil
+}
+
+// EncodeCursor encodes v (usually last item sort keys) into opaque cursor
+func EncodeCursor(v interface{}) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode cursor: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// DecodeCursor decodes opaque cursor into v
+func DecodeCursor(cursor string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return invalidParam(ParamCursor, "malformed cursor")
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return invalidParam(ParamCursor, "malformed cursor")
+	}
+	return nil
+}
+
+// Trim drops the extra item fetched with limit+1 and reports whether more items exist
+func Trim[T any](items []T, limit int) ([]T, bool) {
+	if len(items) > limit {
+		return items[:limit], true
+	}
+	return items, false
+}
+
+// invalidParam builds bad request error for query param
+func invalidParam(param, reason string) *errors.AppError {
+	return errors.New("invalid_pagination", "Invalid pagination parameters", http.StatusBadRequest).
+		WithDetails(map[string]interface{}{
+			"param":  param,
+			"reason": reason,
+		})
+}
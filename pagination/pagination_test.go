@@ -0,0 +1,174 @@
+package pagination
+
+import (
+	stderrors "errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alimzhanovlr/sdk/errors"
+	"github.com/gofiber/fiber/v2"
+)
+
+// parseQuery runs parse on a request with query string
+func parseQuery[T any](t *testing.T, query string, parse func(*fiber.Ctx) (T, error)) (T, error) {
+	t.Helper()
+	var (
+		got T
+		err error
+	)
+	app := fiber.New()
+	app.Get("/", func(c *fiber.Ctx) error {
+		got, err = parse(c)
+		return nil
+	})
+	resp, testErr := app.Test(httptest.NewRequest(fiber.MethodGet, "/?"+query, nil))
+	if testErr != nil {
+		t.Fatal(testErr)
+	}
+	resp.Body.Close()
+	return got, err
+}
+
+// invalidParamOf returns the param reported by an invalid_pagination error
+func invalidParamOf(err error) string {
+	var appErr *errors.AppError
+	if !stderrors.As(err, &appErr) || appErr.Code != "invalid_pagination" {
+		return ""
+	}
+	param, _ := appErr.Details["param"].(string)
+	return param
+}
+
+func TestParsePage(t *testing.T) {
+	tests := []struct {
+		query    string
+		want     Page
+		badParam string
+	}{
+		{"", Page{Page: 1, PerPage: DefaultPerPage}, ""},
+		{"page=3&per_page=50", Page{Page: 3, PerPage: 50}, ""},
+		{"per_page=100", Page{Page: 1, PerPage: 100}, ""},
+		{"page=0", Page{}, ParamPage},
+		{"page=-1", Page{}, ParamPage},
+		{"per_page=0", Page{}, ParamPerPage},
+		{"per_page=101", Page{}, ParamPerPage},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			got, err := parseQuery(t, tt.query, ParsePage)
+			if tt.badParam != "" {
+				if param := invalidParamOf(err); param != tt.badParam {
+					t.Fatalf("err = %v, want invalid %s", err, tt.badParam)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("ParsePage() = %+v, %v, want %+v", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseCursor(t *testing.T) {
+	cursor, err := EncodeCursor([]interface{}{"2024-01-01", 42})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		query    string
+		want     Cursor
+		badParam string
+	}{
+		{"", Cursor{Limit: DefaultPerPage}, ""},
+		{"limit=5&cursor=" + cursor, Cursor{After: cursor, Limit: 5}, ""},
+		{"limit=0", Cursor{}, ParamLimit},
+		{"limit=101", Cursor{}, ParamLimit},
+		{"cursor=not*base64", Cursor{}, ParamCursor},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			got, err := parseQuery(t, tt.query, ParseCursor)
+			if tt.badParam != "" {
+				if param := invalidParamOf(err); param != tt.badParam {
+					t.Fatalf("err = %v, want invalid %s", err, tt.badParam)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("ParseCursor() = %+v, %v, want %+v", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	type key struct {
+		CreatedAt string `json:"created_at"`
+		ID        int    `json:"id"`
+	}
+
+	encoded, err := EncodeCursor(key{CreatedAt: "2024-01-01T00:00:00Z", ID: 42})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got key
+	ok, err := Cursor{After: encoded}.Decode(&got)
+	if err != nil || !ok {
+		t.Fatalf("Decode() = %v, %v", ok, err)
+	}
+	if got != (key{CreatedAt: "2024-01-01T00:00:00Z", ID: 42}) {
+		t.Errorf("decoded %+v", got)
+	}
+
+	if ok, err := (Cursor{}).Decode(&got); ok || err != nil {
+		t.Errorf("first page Decode() = %v, %v, want false, nil", ok, err)
+	}
+
+	// Valid base64 with a body that is not JSON
+	if _, err := (Cursor{After: "bm90LWpzb24"}).Decode(&got); invalidParamOf(err) != ParamCursor {
+		t.Errorf("err = %v, want invalid cursor", err)
+	}
+	if err := DecodeCursor("%%%", &got); invalidParamOf(err) != ParamCursor {
+		t.Errorf("err = %v, want invalid cursor", err)
+	}
+}
+
+func TestPageZeroValue(t *testing.T) {
+	tests := []struct {
+		page          Page
+		limit, offset int
+		totalPages    int
+	}{
+		{Page{}, DefaultPerPage, 0, 3},
+		{Page{Page: 3, PerPage: 10}, 10, 20, 5},
+		{Page{Page: 2}, DefaultPerPage, DefaultPerPage, 3},
+		{Page{Page: -1, PerPage: -5}, DefaultPerPage, 0, 3},
+	}
+
+	for _, tt := range tests {
+		if got := tt.page.Limit(); got != tt.limit {
+			t.Errorf("%+v Limit() = %d, want %d", tt.page, got, tt.limit)
+		}
+		if got := tt.page.Offset(); got != tt.offset {
+			t.Errorf("%+v Offset() = %d, want %d", tt.page, got, tt.offset)
+		}
+		if got := tt.page.Meta(50).TotalPages; got != tt.totalPages {
+			t.Errorf("%+v Meta(50).TotalPages = %d, want %d", tt.page, got, tt.totalPages)
+		}
+	}
+}
+
+func TestTrim(t *testing.T) {
+	items, more := Trim([]int{1, 2, 3}, 2)
+	if len(items) != 2 || !more {
+		t.Errorf("Trim() = %v, %v, want 2 items and more", items, more)
+	}
+	items, more = Trim([]int{1, 2}, 2)
+	if len(items) != 2 || more {
+		t.Errorf("Trim() = %v, %v, want 2 items and no more", items, more)
+	}
+}
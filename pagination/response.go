@@ -0,0 +1,71 @@
+package pagination
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// SetTotalCount sets X-Total-Count header
+func SetTotalCount(c *fiber.Ctx, total int) {
+	c.Set(HeaderTotalCount, strconv.Itoa(total))
+}
+
+// SetPageLinks sets X-Total-Count and RFC 8288 Link header with first, prev, next and last pages
+func SetPageLinks(c *fiber.Ctx, p Page, total int) {
+	SetTotalCount(c, total)
+
+	p = p.normalize()
+	lastPage := (total + p.PerPage - 1) / p.PerPage
+	if lastPage < 1 {
+		lastPage = 1
+	}
+
+	links := []string{link(c, "first", pageQuery(1, p.PerPage))}
+	if p.Page > 1 {
+		links = append(links, link(c, "prev", pageQuery(p.Page-1, p.PerPage)))
+	}
+	if p.Page < lastPage {
+		links = append(links, link(c, "next", pageQuery(p.Page+1, p.PerPage)))
+	}
+	links = append(links, link(c, "last", pageQuery(lastPage, p.PerPage)))
+
+	c.Set(fiber.HeaderLink, strings.Join(links, ", "))
+}
+
+// SetCursorLinks sets Link header with next page when next cursor is not empty
+func SetCursorLinks(c *fiber.Ctx, cur Cursor, next string) {
+	if next == "" {
+		return
+	}
+
+	query := map[string]string{
+		ParamCursor: next,
+		ParamLimit:  strconv.Itoa(cur.Limit),
+	}
+	c.Set(fiber.HeaderLink, link(c, "next", query))
+}
+
+// pageQuery returns offset pagination params
+func pageQuery(page, perPage int) map[string]string {
+	return map[string]string{
+		ParamPage:    strconv.Itoa(page),
+		ParamPerPage: strconv.Itoa(perPage),
+	}
+}
+
+// link builds Link header entry preserving other query params
+func link(c *fiber.Ctx, rel string, params map[string]string) string {
+	query := url.Values{}
+	c.Context().QueryArgs().VisitAll(func(key, value []byte) {
+		query.Add(string(key), string(value))
+	})
+	for key, value := range params {
+		query.Set(key, value)
+	}
+
+	return fmt.Sprintf(`<%s%s?%s>; rel="%s"`, c.BaseURL(), c.Path(), query.Encode(), rel)
+}
@@ -0,0 +1,106 @@
+package pagination
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func pageLinksResponse(t *testing.T, target string, p Page, total int) *http.Response {
+	t.Helper()
+	app := fiber.New()
+	app.Get("/orders", func(c *fiber.Ctx) error {
+		SetPageLinks(c, p, total)
+		return nil
+	})
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, target, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	return resp
+}
+
+func TestSetPageLinks(t *testing.T) {
+	tests := []struct {
+		name   string
+		target string
+		page   Page
+		total  int
+		want   string
+	}{
+		{
+			name:   "middle page keeps other params",
+			target: "/orders?status=paid&page=2&per_page=10",
+			page:   Page{Page: 2, PerPage: 10},
+			total:  35,
+			want: `<http://example.com/orders?page=1&per_page=10&status=paid>; rel="first", ` +
+				`<http://example.com/orders?page=1&per_page=10&status=paid>; rel="prev", ` +
+				`<http://example.com/orders?page=3&per_page=10&status=paid>; rel="next", ` +
+				`<http://example.com/orders?page=4&per_page=10&status=paid>; rel="last"`,
+		},
+		{
+			name:   "single empty page",
+			target: "/orders",
+			page:   Page{Page: 1, PerPage: 10},
+			total:  0,
+			want: `<http://example.com/orders?page=1&per_page=10>; rel="first", ` +
+				`<http://example.com/orders?page=1&per_page=10>; rel="last"`,
+		},
+		{
+			name:   "zero value page",
+			target: "/orders",
+			page:   Page{},
+			total:  45,
+			want: `<http://example.com/orders?page=1&per_page=20>; rel="first", ` +
+				`<http://example.com/orders?page=2&per_page=20>; rel="next", ` +
+				`<http://example.com/orders?page=3&per_page=20>; rel="last"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := pageLinksResponse(t, tt.target, tt.page, tt.total)
+			if got := resp.Header.Get(fiber.HeaderLink); got != tt.want {
+				t.Errorf("Link = %s\nwant   %s", got, tt.want)
+			}
+			if got, want := resp.Header.Get(HeaderTotalCount), strconv.Itoa(tt.total); got != want {
+				t.Errorf("%s = %q, want %q", HeaderTotalCount, got, want)
+			}
+		})
+	}
+}
+
+func TestSetCursorLinks(t *testing.T) {
+	app := fiber.New()
+	app.Get("/orders", func(c *fiber.Ctx) error {
+		cur := Cursor{Limit: 5}
+		if c.Query("last") == "" {
+			SetCursorLinks(c, cur, "abc")
+		} else {
+			SetCursorLinks(c, cur, "")
+		}
+		return nil
+	})
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/orders?status=paid", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if got, want := resp.Header.Get(fiber.HeaderLink), `<http://example.com/orders?cursor=abc&limit=5&status=paid>; rel="next"`; got != want {
+		t.Errorf("Link = %s, want %s", got, want)
+	}
+
+	resp, err = app.Test(httptest.NewRequest(fiber.MethodGet, "/orders?last=1", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if got := resp.Header.Get(fiber.HeaderLink); got != "" {
+		t.Errorf("Link = %s on the last page, want none", got)
+	}
+}
@@ -0,0 +1,71 @@
+package pagination
+
+import (
+	"fmt"
+	"strings"
+)
+
+// LimitOffset appends LIMIT/OFFSET with PostgreSQL placeholders continuing args
+func LimitOffset(query string, args []interface{}, limit, offset int) (string, []interface{}) {
+	n := len(args)
+	query = fmt.Sprintf("%s LIMIT $%d OFFSET $%d", query, n+1, n+2)
+	return query, append(args, limit, offset)
+}
+
+// Keyset builds keyset (seek) pagination over a unique column tuple,
+// e.g. Keyset{Columns: []string{"created_at", "id"}, Desc: true}
+type Keyset struct {
+	Columns []string
+	Desc    bool
+}
+
+// Apply appends seek condition, ORDER BY and LIMIT to query. After holds
+// sort key values of the last seen item, nil for the first page. Query
+// must not contain ORDER BY or LIMIT, set hasWhere when it already has a
+// WHERE clause. Limit+1 rows are requested, use Trim to detect more pages
+func (k Keyset) Apply(query string, args []interface{}, hasWhere bool, after []interface{}, limit int) (string, []interface{}, error) {
+	if len(k.Columns) == 0 {
+		return "", nil, fmt.Errorf("keyset has no columns")
+	}
+
+	var b strings.Builder
+	b.WriteString(query)
+
+	if after != nil {
+		if len(after) != len(k.Columns) {
+			return "", nil, fmt.Errorf("cursor has %d values, keyset has %d columns", len(after), len(k.Columns))
+		}
+
+		if hasWhere {
+			b.WriteString(" AND ")
+		} else {
+			b.WriteString(" WHERE ")
+		}
+
+		placeholders := make([]string, len(after))
+		for i, value := range after {
+			args = append(args, value)
+			placeholders[i] = fmt.Sprintf("$%d", len(args))
+		}
+
+		op := ">"
+		if k.Desc {
+			op = "<"
+		}
+		fmt.Fprintf(&b, "(%s) %s (%s)", strings.Join(k.Columns, ", "), op, strings.Join(placeholders, ", "))
+	}
+
+	direction := "ASC"
+	if k.Desc {
+		direction = "DESC"
+	}
+	order := make([]string, len(k.Columns))
+	for i, column := range k.Columns {
+		order[i] = column + " " + direction
+	}
+
+	args = append(args, limit+1)
+	fmt.Fprintf(&b, " ORDER BY %s LIMIT $%d", strings.Join(order, ", "), len(args))
+
+	return b.String(), args, nil
+}
@@ -0,0 +1,81 @@
+package pagination
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLimitOffset(t *testing.T) {
+	query, args := LimitOffset("SELECT * FROM orders WHERE user_id = $1", []interface{}{"u1"}, 20, 40)
+
+	if want := "SELECT * FROM orders WHERE user_id = $1 LIMIT $2 OFFSET $3"; query != want {
+		t.Errorf("query = %q, want %q", query, want)
+	}
+	if !reflect.DeepEqual(args, []interface{}{"u1", 20, 40}) {
+		t.Errorf("args = %v", args)
+	}
+}
+
+func TestKeysetApply(t *testing.T) {
+	tests := []struct {
+		name     string
+		keyset   Keyset
+		query    string
+		args     []interface{}
+		hasWhere bool
+		after    []interface{}
+		want     string
+		wantArgs []interface{}
+	}{
+		{
+			name:     "first page",
+			keyset:   Keyset{Columns: []string{"created_at", "id"}, Desc: true},
+			query:    "SELECT * FROM orders",
+			want:     "SELECT * FROM orders ORDER BY created_at DESC, id DESC LIMIT $1",
+			wantArgs: []interface{}{11},
+		},
+		{
+			name:     "next page",
+			keyset:   Keyset{Columns: []string{"created_at", "id"}},
+			query:    "SELECT * FROM orders",
+			after:    []interface{}{"2024-01-01", 42},
+			want:     "SELECT * FROM orders WHERE (created_at, id) > ($1, $2) ORDER BY created_at ASC, id ASC LIMIT $3",
+			wantArgs: []interface{}{"2024-01-01", 42, 11},
+		},
+		{
+			name:     "placeholders continue args",
+			keyset:   Keyset{Columns: []string{"created_at", "id"}, Desc: true},
+			query:    "SELECT * FROM orders WHERE user_id = $1 AND status = $2",
+			args:     []interface{}{"u1", "paid"},
+			hasWhere: true,
+			after:    []interface{}{"2024-01-01", 42},
+			want:     "SELECT * FROM orders WHERE user_id = $1 AND status = $2 AND (created_at, id) < ($3, $4) ORDER BY created_at DESC, id DESC LIMIT $5",
+			wantArgs: []interface{}{"u1", "paid", "2024-01-01", 42, 11},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, args, err := tt.keyset.Apply(tt.query, tt.args, tt.hasWhere, tt.after, 10)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if query != tt.want {
+				t.Errorf("query = %q\nwant    %q", query, tt.want)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("args = %v, want %v", args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestKeysetApplyErrors(t *testing.T) {
+	if _, _, err := (Keyset{}).Apply("SELECT 1", nil, false, nil, 10); err == nil {
+		t.Error("keyset without columns is accepted")
+	}
+	keyset := Keyset{Columns: []string{"created_at", "id"}}
+	if _, _, err := keyset.Apply("SELECT 1", nil, false, []interface{}{"2024-01-01"}, 10); err == nil {
+		t.Error("cursor with fewer values than columns is accepted")
+	}
+}
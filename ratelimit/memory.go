@@ -0,0 +1,192 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// MemoryStore is Store for a single instance
+type MemoryStore struct {
+	mu        sync.Mutex
+	states    map[string]*state
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// state of a key, fields used depend on the algorithm
+type state struct {
+	expires time.Time
+
+	// GCRA
+	tat time.Time
+
+	// TokenBucket
+	tokens float64
+	last   time.Time
+
+	// SlidingWindow
+	window     time.Time
+	prev, curr int
+}
+
+// NewMemoryStore creates in-memory Store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		states:    make(map[string]*state),
+		lastSweep: time.Now(),
+		now:       time.Now,
+	}
+}
+
+// Allow implements Store
+func (s *MemoryStore) Allow(_ context.Context, key string, limit Limit, n int) (Result, error) {
+	limit = limit.withDefaults()
+	now := s.now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Expired states are swept at most once a minute
+	if now.Sub(s.lastSweep) > time.Minute {
+		for k, st := range s.states {
+			if now.After(st.expires) {
+				delete(s.states, k)
+			}
+		}
+		s.lastSweep = now
+	}
+
+	// The same key under different algorithms has separate state
+	stateKey := string(limit.Algorithm) + ":" + key
+	st, ok := s.states[stateKey]
+	if !ok || now.After(st.expires) {
+		st = &state{}
+		s.states[stateKey] = st
+	}
+
+	switch limit.Algorithm {
+	case TokenBucket:
+		return st.tokenBucket(limit, n, now), nil
+	case SlidingWindow:
+		return st.slidingWindow(limit, n, now), nil
+	default:
+		return st.gcra(limit, n, now), nil
+	}
+}
+
+// gcra keeps the theoretical arrival time of the next event, an event is
+// allowed if it is at most Burst emissions ahead of now
+func (st *state) gcra(limit Limit, n int, now time.Time) Result {
+	emission := limit.emission()
+	burst := time.Duration(limit.Burst) * emission
+
+	tat := st.tat
+	if tat.Before(now) {
+		tat = now
+	}
+	newTat := tat.Add(time.Duration(n) * emission)
+	allowAt := newTat.Add(-burst)
+
+	result := Result{Limit: limit.Burst}
+	if now.Before(allowAt) {
+		result.Remaining = remaining(float64(burst-tat.Sub(now)) / float64(emission))
+		result.RetryAfter = allowAt.Sub(now)
+		result.ResetAfter = tat.Sub(now)
+		return result
+	}
+
+	st.tat = newTat
+	st.expires = newTat
+	result.Allowed = true
+	result.Remaining = remaining(float64(burst-newTat.Sub(now)) / float64(emission))
+	result.ResetAfter = newTat.Sub(now)
+	return result
+}
+
+// tokenBucket refills tokens for the time since the last event
+func (st *state) tokenBucket(limit Limit, n int, now time.Time) Result {
+	rate := float64(limit.Rate) / float64(limit.Period) // tokens per nanosecond
+	burst := float64(limit.Burst)
+
+	if st.last.IsZero() {
+		st.tokens = burst
+	} else if elapsed := now.Sub(st.last); elapsed > 0 {
+		st.tokens = math.Min(burst, st.tokens+float64(elapsed)*rate)
+	}
+	st.last = now
+
+	result := Result{Limit: limit.Burst}
+	if st.tokens >= float64(n) {
+		st.tokens -= float64(n)
+		result.Allowed = true
+	} else {
+		result.RetryAfter = ceilDuration((float64(n) - st.tokens) / rate)
+	}
+	result.Remaining = remaining(st.tokens)
+	result.ResetAfter = ceilDuration((burst - st.tokens) / rate)
+	st.expires = now.Add(result.ResetAfter)
+	return result
+}
+
+// slidingWindow counts events of the current fixed window and weights the
+// previous one by the part of it still inside the sliding window
+func (st *state) slidingWindow(limit Limit, n int, now time.Time) Result {
+	period := limit.Period
+	rate := float64(limit.Rate)
+	// Windows are aligned to the Unix epoch, like in the Redis store
+	start := time.Unix(0, now.UnixNano()-now.UnixNano()%int64(period))
+
+	if !st.window.Equal(start) {
+		if start.Sub(st.window) == period {
+			st.prev = st.curr
+		} else {
+			st.prev = 0
+		}
+		st.curr = 0
+		st.window = start
+	}
+
+	elapsed := now.Sub(start)
+	count := float64(st.prev)*float64(period-elapsed)/float64(period) + float64(st.curr)
+
+	result := Result{Limit: limit.Rate}
+	if count+float64(n) <= rate {
+		st.curr += n
+		result.Allowed = true
+		result.Remaining = remaining(rate - count - float64(n))
+	} else {
+		result.Remaining = remaining(rate - count)
+		if float64(st.curr+n) > rate {
+			// Not before the next window, where the current one is weighted
+			result.RetryAfter = period - elapsed +
+				ceilDuration(math.Max(0, float64(period)*(1-(rate-float64(n))/float64(st.curr))))
+		} else {
+			result.RetryAfter = ceilDuration(float64(period)*(1-(rate-float64(st.curr+n))/float64(st.prev))) - elapsed
+		}
+		if result.RetryAfter <= 0 {
+			result.RetryAfter = time.Nanosecond
+		}
+	}
+
+	switch {
+	case st.curr > 0:
+		result.ResetAfter = 2*period - elapsed
+	case st.prev > 0:
+		result.ResetAfter = period - elapsed
+	}
+	st.expires = start.Add(2 * period)
+	return result
+}
+
+func remaining(v float64) int {
+	if v < 0 {
+		return 0
+	}
+	return int(math.Floor(v + 1e-9))
+}
+
+func ceilDuration(ns float64) time.Duration {
+	return time.Duration(math.Ceil(ns))
+}
@@ -0,0 +1,109 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// step takes n events after advancing the clock by advance
+type step struct {
+	advance    time.Duration
+	n          int
+	allowed    bool
+	remaining  int
+	retryAfter time.Duration
+	resetAfter time.Duration
+}
+
+// algorithmSteps are shared expectations for stores, the clock starts at a
+// second boundary so sliding windows are aligned with the first step
+var algorithmSteps = map[Algorithm][]step{
+	GCRA: {
+		{n: 1, allowed: true, remaining: 1, resetAfter: 500 * time.Millisecond},
+		{n: 1, allowed: true, remaining: 0, resetAfter: time.Second},
+		{n: 1, allowed: false, remaining: 0, retryAfter: 500 * time.Millisecond, resetAfter: time.Second},
+		{advance: 500 * time.Millisecond, n: 1, allowed: true, remaining: 0, resetAfter: time.Second},
+		{advance: 2 * time.Second, n: 2, allowed: true, remaining: 0, resetAfter: time.Second},
+	},
+	TokenBucket: {
+		{n: 1, allowed: true, remaining: 1, resetAfter: 500 * time.Millisecond},
+		{n: 1, allowed: true, remaining: 0, resetAfter: time.Second},
+		{n: 1, allowed: false, remaining: 0, retryAfter: 500 * time.Millisecond, resetAfter: time.Second},
+		{advance: 250 * time.Millisecond, n: 1, allowed: false, remaining: 0, retryAfter: 250 * time.Millisecond, resetAfter: 750 * time.Millisecond},
+		{advance: 250 * time.Millisecond, n: 1, allowed: true, remaining: 0, resetAfter: time.Second},
+		{advance: 2 * time.Second, n: 2, allowed: true, remaining: 0, resetAfter: time.Second},
+	},
+	SlidingWindow: {
+		{n: 1, allowed: true, remaining: 1, resetAfter: 2 * time.Second},
+		{n: 1, allowed: true, remaining: 0, resetAfter: 2 * time.Second},
+		{n: 1, allowed: false, remaining: 0, retryAfter: 1500 * time.Millisecond, resetAfter: 2 * time.Second},
+		// The previous window is weighted by half
+		{advance: 1500 * time.Millisecond, n: 1, allowed: true, remaining: 0, resetAfter: 1500 * time.Millisecond},
+		{n: 1, allowed: false, remaining: 0, retryAfter: 500 * time.Millisecond, resetAfter: 1500 * time.Millisecond},
+		{advance: 3 * time.Second, n: 2, allowed: true, remaining: 0, resetAfter: 1500 * time.Millisecond},
+	},
+}
+
+// closeTo compares durations with the microsecond resolution of the Redis store
+func closeTo(got, want time.Duration) bool {
+	diff := got - want
+	return diff > -time.Microsecond && diff < time.Microsecond
+}
+
+func TestMemoryStoreAlgorithms(t *testing.T) {
+	for algorithm, steps := range algorithmSteps {
+		t.Run(string(algorithm), func(t *testing.T) {
+			now := time.Unix(1_700_000_000, 0)
+			store := NewMemoryStore()
+			store.now = func() time.Time { return now }
+			limit := Limit{Rate: 2, Period: time.Second, Algorithm: algorithm}.withDefaults()
+
+			for i, s := range steps {
+				now = now.Add(s.advance)
+				got, err := store.Allow(context.Background(), "k", limit, s.n)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got.Allowed != s.allowed || got.Remaining != s.remaining ||
+					!closeTo(got.RetryAfter, s.retryAfter) || !closeTo(got.ResetAfter, s.resetAfter) {
+					t.Errorf("step %d: got %+v, want %+v", i, got, s)
+				}
+				if got.Limit != 2 {
+					t.Errorf("step %d: limit = %d", i, got.Limit)
+				}
+			}
+		})
+	}
+}
+
+func TestMemoryStoreSeparatesKeysAndAlgorithms(t *testing.T) {
+	store := NewMemoryStore()
+	ctx := context.Background()
+
+	for _, limit := range []Limit{
+		{Rate: 1, Algorithm: GCRA},
+		{Rate: 1, Algorithm: TokenBucket},
+	} {
+		for _, key := range []string{"a", "b"} {
+			result, err := store.Allow(ctx, key, limit.withDefaults(), 1)
+			if err != nil || !result.Allowed {
+				t.Errorf("%s %s: allowed = %v, err = %v", limit.Algorithm, key, result.Allowed, err)
+			}
+		}
+	}
+}
+
+func TestMemoryStoreSweepsExpiredStates(t *testing.T) {
+	now := time.Now()
+	store := NewMemoryStore()
+	store.now = func() time.Time { return now }
+
+	store.Allow(context.Background(), "a", PerSecond(1).withDefaults(), 1)
+	now = now.Add(2 * time.Minute)
+	store.Allow(context.Background(), "b", PerSecond(1).withDefaults(), 1)
+
+	if len(store.states) != 1 {
+		t.Errorf("states = %d, want 1", len(store.states))
+	}
+}
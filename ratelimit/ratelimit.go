@@ -0,0 +1,174 @@
+// Package ratelimit is the limiter core shared by the server rate limit
+// middleware and the HTTP client, so a limit is defined once and behaves the
+// same on both sides
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Algorithm selects how a limit is enforced
+type Algorithm string
+
+const (
+	// GCRA is the generic cell rate algorithm, a token bucket kept as a
+	// single timestamp. Events are spread evenly with Burst allowed at once
+	GCRA Algorithm = "gcra"
+	// TokenBucket refills Rate tokens per Period up to Burst
+	TokenBucket Algorithm = "token_bucket"
+	// SlidingWindow allows Rate events in any Period, the previous window
+	// is weighted by its overlap with the current one
+	SlidingWindow Algorithm = "sliding_window"
+)
+
+// ErrLimitExceeded is returned by Wait when the limit does not allow the
+// event before the context deadline
+var ErrLimitExceeded = errors.New("ratelimit: limit exceeded")
+
+// Limit defines how many events are allowed
+type Limit struct {
+	Rate   int           // Events per Period
+	Period time.Duration // Defaults to a second
+	// Burst is how many events are allowed at once by GCRA and TokenBucket,
+	// defaults to Rate. SlidingWindow allows Rate at once
+	Burst     int
+	Algorithm Algorithm // Defaults to GCRA
+}
+
+// PerSecond returns a limit of rate events per second
+func PerSecond(rate int) Limit {
+	return Limit{Rate: rate, Period: time.Second}
+}
+
+// PerMinute returns a limit of rate events per minute
+func PerMinute(rate int) Limit {
+	return Limit{Rate: rate, Period: time.Minute}
+}
+
+func (l Limit) withDefaults() Limit {
+	if l.Rate <= 0 {
+		l.Rate = 1
+	}
+	if l.Period <= 0 {
+		l.Period = time.Second
+	}
+	if l.Burst <= 0 || l.Algorithm == SlidingWindow {
+		l.Burst = l.Rate
+	}
+	if l.Algorithm == "" {
+		l.Algorithm = GCRA
+	}
+	return l
+}
+
+// Validate checks that the limit with defaults applied can be enforced:
+// events must be spread at least a microsecond apart, the resolution of the
+// Redis store
+func (l Limit) Validate() error {
+	l = l.withDefaults()
+	if l.emission() < time.Microsecond {
+		return fmt.Errorf("ratelimit: rate of %d per %s spreads events less than a microsecond apart", l.Rate, l.Period)
+	}
+	return nil
+}
+
+// emission is the interval between evenly spread events
+func (l Limit) emission() time.Duration {
+	return l.Period / time.Duration(l.Rate)
+}
+
+// Result is a limiter decision
+type Result struct {
+	Allowed   bool
+	Limit     int // Events allowed at once, for X-RateLimit-Limit
+	Remaining int // Events allowed right after this one
+	// RetryAfter is when the denied event will be allowed, zero if allowed
+	RetryAfter time.Duration
+	// ResetAfter is when the limit is fully available again
+	ResetAfter time.Duration
+}
+
+// Store keeps limiter state, Allow must be atomic per key
+type Store interface {
+	// Allow takes n events of key if limit allows them, Limiter passes the
+	// limit with defaults applied
+	Allow(ctx context.Context, key string, limit Limit, n int) (Result, error)
+}
+
+// Limiter applies a limit to keys in a store
+type Limiter struct {
+	store Store
+	limit Limit
+}
+
+// New creates a limiter, a nil store defaults to an in-memory store. New
+// panics if the limit is rejected by Validate
+//
+//	limiter := ratelimit.New(redis.NewStore(client, "ratelimit:"), ratelimit.Limit{
+//		Rate:   100,
+//		Period: time.Minute,
+//		Burst:  20,
+//	})
+func New(store Store, limit Limit) *Limiter {
+	if err := limit.Validate(); err != nil {
+		panic(err)
+	}
+	if store == nil {
+		store = NewMemoryStore()
+	}
+	return &Limiter{
+		store: store,
+		limit: limit.withDefaults(),
+	}
+}
+
+// Limit returns the limit with defaults applied
+func (l *Limiter) Limit() Limit {
+	return l.limit
+}
+
+// Allow takes one event of key
+func (l *Limiter) Allow(ctx context.Context, key string) (Result, error) {
+	return l.AllowN(ctx, key, 1)
+}
+
+// AllowN takes n events of key at once, n above Burst is never allowed
+func (l *Limiter) AllowN(ctx context.Context, key string, n int) (Result, error) {
+	if n > l.limit.Burst {
+		return Result{}, fmt.Errorf("ratelimit: %d events exceed burst of %d", n, l.limit.Burst)
+	}
+	result, err := l.store.Allow(ctx, key, l.limit, n)
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to check rate limit: %w", err)
+	}
+	return result, nil
+}
+
+// Wait blocks until an event of key is allowed. ErrLimitExceeded is returned
+// right away if the event is not allowed before the context deadline
+func (l *Limiter) Wait(ctx context.Context, key string) error {
+	for {
+		result, err := l.Allow(ctx, key)
+		if err != nil {
+			return err
+		}
+		if result.Allowed {
+			return nil
+		}
+
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < result.RetryAfter {
+			return ErrLimitExceeded
+		}
+
+		timer := time.NewTimer(result.RetryAfter)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestLimitValidate(t *testing.T) {
+	tests := []struct {
+		limit Limit
+		valid bool
+	}{
+		{limit: PerSecond(100), valid: true},
+		{limit: Limit{}, valid: true},
+		{limit: Limit{Rate: 1000, Period: time.Millisecond}, valid: true},
+		{limit: Limit{Rate: 1001, Period: time.Millisecond}, valid: false},
+		{limit: Limit{Rate: 2_000_000_000, Period: time.Second}, valid: false},
+		{limit: Limit{Rate: 10, Period: time.Nanosecond}, valid: false},
+	}
+
+	for _, tt := range tests {
+		if err := tt.limit.Validate(); (err == nil) != tt.valid {
+			t.Errorf("Validate(%+v) = %v, want valid %v", tt.limit, err, tt.valid)
+		}
+	}
+}
+
+func TestNewPanicsOnInvalidLimit(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic")
+		}
+	}()
+	New(nil, Limit{Rate: 2_000_000_000, Period: time.Second})
+}
+
+func TestLimiterDefaults(t *testing.T) {
+	limit := New(nil, Limit{Rate: 5}).Limit()
+	if limit.Period != time.Second || limit.Burst != 5 || limit.Algorithm != GCRA {
+		t.Errorf("unexpected defaults %+v", limit)
+	}
+
+	limit = New(nil, Limit{Rate: 5, Burst: 10, Algorithm: SlidingWindow}).Limit()
+	if limit.Burst != 5 {
+		t.Errorf("sliding window burst = %d, want rate", limit.Burst)
+	}
+}
+
+func TestAllowNAboveBurst(t *testing.T) {
+	limiter := New(nil, Limit{Rate: 5, Burst: 2})
+	if _, err := limiter.AllowN(context.Background(), "k", 3); err == nil {
+		t.Error("expected error for n above burst")
+	}
+}
+
+func TestWaitFailsFastBeforeDeadline(t *testing.T) {
+	limiter := New(nil, PerMinute(1))
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	if err := limiter.Wait(ctx, "k"); err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	if err := limiter.Wait(ctx, "k"); !errors.Is(err, ErrLimitExceeded) {
+		t.Errorf("Wait() = %v, want ErrLimitExceeded", err)
+	}
+	if time.Since(start) > 50*time.Millisecond {
+		t.Error("Wait must not sleep when the deadline is too close")
+	}
+}
+
+func TestWaitBlocksUntilAllowed(t *testing.T) {
+	limiter := New(nil, Limit{Rate: 1, Period: 50 * time.Millisecond})
+	ctx := context.Background()
+
+	limiter.Wait(ctx, "k")
+	start := time.Now()
+	if err := limiter.Wait(ctx, "k"); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+		t.Errorf("Wait returned after %s", elapsed)
+	}
+}
@@ -0,0 +1,155 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/alimzhanovlr/sdk/ratelimit"
+	"github.com/redis/go-redis/v9"
+)
+
+// Store is ratelimit.Store shared by instances through Redis. Scripts use
+// the Redis clock, so instances with skewed clocks see the same limit
+type Store struct {
+	client redis.UniversalClient
+	prefix string
+}
+
+var _ ratelimit.Store = (*Store)(nil)
+
+// NewStore creates Redis-backed ratelimit.Store, prefix namespaces keys
+func NewStore(client redis.UniversalClient, prefix string) *Store {
+	return &Store{client: client, prefix: prefix}
+}
+
+// Scripts take rate, period in microseconds, burst and n, and return
+// allowed, remaining, retry after and reset after in microseconds. They
+// mirror ratelimit.MemoryStore
+const clockScript = `
+local t = redis.call("TIME")
+local now = tonumber(t[1]) * 1000000 + tonumber(t[2])
+local rate = tonumber(ARGV[1])
+local period = tonumber(ARGV[2])
+local burst = tonumber(ARGV[3])
+local n = tonumber(ARGV[4])
+`
+
+var gcraScript = redis.NewScript(clockScript + `
+local emission = period / rate
+local burst_offset = emission * burst
+local tat = tonumber(redis.call("GET", KEYS[1]) or now)
+if tat < now then
+	tat = now
+end
+local new_tat = tat + emission * n
+local allow_at = new_tat - burst_offset
+if now < allow_at then
+	return {0, math.max(0, math.floor((burst_offset - (tat - now)) / emission)), math.ceil(allow_at - now), math.ceil(tat - now)}
+end
+redis.call("SET", KEYS[1], string.format("%.0f", new_tat), "PX", math.ceil((new_tat - now) / 1000) + 1)
+return {1, math.max(0, math.floor((burst_offset - (new_tat - now)) / emission)), 0, math.ceil(new_tat - now)}
+`)
+
+var tokenBucketScript = redis.NewScript(clockScript + `
+local per_us = rate / period
+local state = redis.call("HMGET", KEYS[1], "tokens", "last")
+local tokens = tonumber(state[1])
+local last = tonumber(state[2])
+if tokens == nil or last == nil then
+	tokens = burst
+elseif now > last then
+	tokens = math.min(burst, tokens + (now - last) * per_us)
+end
+local allowed = 0
+local retry = 0
+if tokens >= n then
+	tokens = tokens - n
+	allowed = 1
+else
+	retry = math.ceil((n - tokens) / per_us)
+end
+local reset = math.ceil((burst - tokens) / per_us)
+redis.call("HSET", KEYS[1], "tokens", string.format("%.17g", tokens), "last", string.format("%.0f", now))
+redis.call("PEXPIRE", KEYS[1], math.ceil(reset / 1000) + 1)
+return {allowed, math.floor(tokens), retry, reset}
+`)
+
+var slidingWindowScript = redis.NewScript(clockScript + `
+local start = now - (now % period)
+local state = redis.call("HMGET", KEYS[1], "window", "prev", "curr")
+local window = tonumber(state[1]) or start
+local prev = tonumber(state[2]) or 0
+local curr = tonumber(state[3]) or 0
+if window ~= start then
+	if start - window == period then
+		prev = curr
+	else
+		prev = 0
+	end
+	curr = 0
+end
+local elapsed = now - start
+local count = prev * (period - elapsed) / period + curr
+local allowed = 0
+local remaining = 0
+local retry = 0
+if count + n <= rate then
+	curr = curr + n
+	allowed = 1
+	remaining = math.floor(rate - count - n)
+else
+	remaining = math.max(0, math.floor(rate - count))
+	if curr + n > rate then
+		retry = period - elapsed + math.ceil(math.max(0, period * (1 - (rate - n) / curr)))
+	else
+		retry = math.ceil(period * (1 - (rate - curr - n) / prev)) - elapsed
+	end
+	if retry <= 0 then
+		retry = 1
+	end
+end
+local reset = 0
+if curr > 0 then
+	reset = 2 * period - elapsed
+elseif prev > 0 then
+	reset = period - elapsed
+end
+redis.call("HSET", KEYS[1], "window", string.format("%.0f", start), "prev", prev, "curr", curr)
+redis.call("PEXPIRE", KEYS[1], math.ceil((2 * period - elapsed) / 1000) + 1)
+return {allowed, remaining, retry, reset}
+`)
+
+// Allow implements ratelimit.Store
+func (s *Store) Allow(ctx context.Context, key string, limit ratelimit.Limit, n int) (ratelimit.Result, error) {
+	script := gcraScript
+	switch limit.Algorithm {
+	case ratelimit.TokenBucket:
+		script = tokenBucketScript
+	case ratelimit.SlidingWindow:
+		script = slidingWindowScript
+	}
+
+	// The same key under different algorithms has separate state
+	keys := []string{s.prefix + string(limit.Algorithm) + ":" + key}
+	values, err := script.Run(ctx, s.client, keys,
+		limit.Rate, limit.Period.Microseconds(), limit.Burst, n).Int64Slice()
+	if err != nil {
+		return ratelimit.Result{}, err
+	}
+	if len(values) != 4 {
+		return ratelimit.Result{}, fmt.Errorf("unexpected rate limit script result: %v", values)
+	}
+
+	result := ratelimit.Result{
+		Allowed:    values[0] == 1,
+		Limit:      limit.Burst,
+		Remaining:  int(values[1]),
+		RetryAfter: time.Duration(values[2]) * time.Microsecond,
+		ResetAfter: time.Duration(values[3]) * time.Microsecond,
+	}
+	if limit.Algorithm == ratelimit.SlidingWindow {
+		result.Limit = limit.Rate
+	}
+	return result, nil
+}
@@ -0,0 +1,105 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/alimzhanovlr/sdk/ratelimit"
+	"github.com/redis/go-redis/v9"
+)
+
+// step takes n events after advancing the Redis clock by advance, the
+// expectations match ratelimit.MemoryStore
+type step struct {
+	advance    time.Duration
+	n          int
+	allowed    bool
+	remaining  int
+	retryAfter time.Duration
+	resetAfter time.Duration
+}
+
+var algorithmSteps = map[ratelimit.Algorithm][]step{
+	ratelimit.GCRA: {
+		{n: 1, allowed: true, remaining: 1, resetAfter: 500 * time.Millisecond},
+		{n: 1, allowed: true, remaining: 0, resetAfter: time.Second},
+		{n: 1, allowed: false, remaining: 0, retryAfter: 500 * time.Millisecond, resetAfter: time.Second},
+		{advance: 500 * time.Millisecond, n: 1, allowed: true, remaining: 0, resetAfter: time.Second},
+		{advance: 2 * time.Second, n: 2, allowed: true, remaining: 0, resetAfter: time.Second},
+	},
+	ratelimit.TokenBucket: {
+		{n: 1, allowed: true, remaining: 1, resetAfter: 500 * time.Millisecond},
+		{n: 1, allowed: true, remaining: 0, resetAfter: time.Second},
+		{n: 1, allowed: false, remaining: 0, retryAfter: 500 * time.Millisecond, resetAfter: time.Second},
+		{advance: 250 * time.Millisecond, n: 1, allowed: false, remaining: 0, retryAfter: 250 * time.Millisecond, resetAfter: 750 * time.Millisecond},
+		{advance: 250 * time.Millisecond, n: 1, allowed: true, remaining: 0, resetAfter: time.Second},
+		{advance: 2 * time.Second, n: 2, allowed: true, remaining: 0, resetAfter: time.Second},
+	},
+	ratelimit.SlidingWindow: {
+		{n: 1, allowed: true, remaining: 1, resetAfter: 2 * time.Second},
+		{n: 1, allowed: true, remaining: 0, resetAfter: 2 * time.Second},
+		{n: 1, allowed: false, remaining: 0, retryAfter: 1500 * time.Millisecond, resetAfter: 2 * time.Second},
+		{advance: 1500 * time.Millisecond, n: 1, allowed: true, remaining: 0, resetAfter: 1500 * time.Millisecond},
+		{n: 1, allowed: false, remaining: 0, retryAfter: 500 * time.Millisecond, resetAfter: 1500 * time.Millisecond},
+		{advance: 3 * time.Second, n: 2, allowed: true, remaining: 0, resetAfter: 1500 * time.Millisecond},
+	},
+}
+
+func closeTo(got, want time.Duration) bool {
+	diff := got - want
+	return diff > -time.Microsecond && diff < time.Microsecond
+}
+
+func TestStoreAlgorithms(t *testing.T) {
+	for algorithm, steps := range algorithmSteps {
+		t.Run(string(algorithm), func(t *testing.T) {
+			server := miniredis.RunT(t)
+			client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+			defer client.Close()
+
+			now := time.Unix(1_700_000_000, 0)
+			server.SetTime(now)
+			limiter := ratelimit.New(NewStore(client, "rl:"), ratelimit.Limit{Rate: 2, Algorithm: algorithm})
+
+			for i, s := range steps {
+				now = now.Add(s.advance)
+				server.SetTime(now)
+				server.FastForward(s.advance)
+
+				got, err := limiter.AllowN(context.Background(), "k", s.n)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got.Allowed != s.allowed || got.Remaining != s.remaining ||
+					!closeTo(got.RetryAfter, s.retryAfter) || !closeTo(got.ResetAfter, s.resetAfter) {
+					t.Errorf("step %d: got %+v, want %+v", i, got, s)
+				}
+			}
+
+			if keys := server.Keys(); len(keys) != 1 || keys[0] != "rl:"+string(algorithm)+":k" {
+				t.Errorf("unexpected keys %v", keys)
+			}
+		})
+	}
+}
+
+func TestStoreExpiresState(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer client.Close()
+
+	store := NewStore(client, "rl:")
+	for _, algorithm := range []ratelimit.Algorithm{ratelimit.GCRA, ratelimit.TokenBucket, ratelimit.SlidingWindow} {
+		limiter := ratelimit.New(store, ratelimit.Limit{Rate: 1, Algorithm: algorithm})
+		if _, err := limiter.Allow(context.Background(), "k"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	server.FastForward(3 * time.Second)
+	if keys := server.Keys(); len(keys) != 0 {
+		t.Errorf("state must expire once the limit is fully available, got %v", keys)
+	}
+}
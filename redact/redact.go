@@ -0,0 +1,171 @@
+// Package redact masks sensitive fields in values before they are logged or
+// stored. It has no dependencies on the HTTP client, httpclient.Sanitizer
+// implements ValueSanitizer with the full rule set
+package redact
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// DefaultMask replaces masked values
+const DefaultMask = "***REDACTED***"
+
+// ValueSanitizer returns a JSON-compatible copy of value with sensitive data
+// masked
+type ValueSanitizer interface {
+	SanitizeValue(value interface{}) (interface{}, error)
+}
+
+// DefaultFields are field names masked by default, matched as substrings
+// after FoldFieldName
+func DefaultFields() []string {
+	return []string{
+		// Authentication
+		"password", "passwd", "pwd", "secret", "token",
+		"api_key", "apikey", "api_secret", "access_token", "refresh_token",
+		"client_secret", "client_id", "authorization", "auth",
+		"bearer", "session", "session_id", "cookie",
+
+		// Personal data
+		"ssn", "social_security", "passport", "driver_license",
+		"tax_id", "ein", "vat",
+
+		// Financial data
+		"credit_card", "card_number", "card_num", "cvv", "cvc",
+		"pin", "account_number", "routing_number", "iban", "swift",
+
+		// Cryptography
+		"private_key", "public_key", "encryption_key", "signing_key",
+		"certificate", "cert", "key", "pem",
+
+		// Service specific
+		"stripe_key", "aws_secret", "gcp_key", "azure_key",
+		"webhook_secret", "signing_secret",
+	}
+}
+
+// DefaultPatterns find secrets in free text. The first group, when present,
+// is kept and the rest of the match is masked
+func DefaultPatterns() []*regexp.Regexp {
+	return []*regexp.Regexp{
+		// Bearer tokens
+		regexp.MustCompile(`(?i)(bearer\s+)[a-zA-Z0-9\-._~+/]+=*`),
+
+		// API keys
+		regexp.MustCompile(`(?i)(api[_-]?key["']?\s*[:=]\s*["']?)[a-zA-Z0-9\-_]{20,}`),
+		regexp.MustCompile(`(?i)(x-api-key:\s*)[a-zA-Z0-9\-_]{20,}`),
+
+		// AWS keys
+		regexp.MustCompile(`(AKIA[0-9A-Z]{16})`),
+		regexp.MustCompile(`(?i)(aws[_-]?secret[_-]?access[_-]?key["']?\s*[:=]\s*["']?)([a-zA-Z0-9/+=]{40})`),
+
+		// Google API keys
+		regexp.MustCompile(`(AIza[0-9A-Za-z\-_]{35})`),
+
+		// GitHub tokens
+		regexp.MustCompile(`(gh[ps]_[a-zA-Z0-9]{36})`),
+
+		// JWT
+		regexp.MustCompile(`(eyJ[a-zA-Z0-9_-]*\.eyJ[a-zA-Z0-9_-]*\.[a-zA-Z0-9_-]*)`),
+
+		// Private key headers
+		regexp.MustCompile(`-----BEGIN (RSA |EC |OPENSSH )?PRIVATE KEY-----`),
+	}
+}
+
+// FoldFieldName normalizes a field name for matching: NFKC, lower case,
+// letters and digits only. "Pass Word", "pass_word" and "ｐａｓｓｗｏｒｄ" all
+// give "password"
+func FoldFieldName(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(norm.NFKC.String(name)) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// Fields masks values of fields whose folded name contains one of the
+// sensitive names and applies patterns to the remaining strings
+type Fields struct {
+	mask     string
+	folded   []string
+	patterns []*regexp.Regexp
+}
+
+// NewFields creates a ValueSanitizer, an empty mask means DefaultMask
+func NewFields(mask string, fields []string, patterns []*regexp.Regexp) *Fields {
+	if mask == "" {
+		mask = DefaultMask
+	}
+	f := &Fields{mask: mask, patterns: patterns}
+	for _, field := range fields {
+		if folded := FoldFieldName(field); folded != "" {
+			f.folded = append(f.folded, folded)
+		}
+	}
+	return f
+}
+
+// Default uses DefaultFields and DefaultPatterns
+func Default() *Fields {
+	return NewFields(DefaultMask, DefaultFields(), DefaultPatterns())
+}
+
+// SanitizeValue encodes value as JSON and masks the decoded copy
+func (f *Fields) SanitizeValue(value interface{}) (interface{}, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal value: %w", err)
+	}
+
+	var decoded interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal value: %w", err)
+	}
+	return f.sanitize(decoded), nil
+}
+
+func (f *Fields) sanitize(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, val := range v {
+			if f.IsSensitive(key) {
+				v[key] = f.mask
+			} else {
+				v[key] = f.sanitize(val)
+			}
+		}
+		return v
+	case []interface{}:
+		for i, val := range v {
+			v[i] = f.sanitize(val)
+		}
+		return v
+	case string:
+		for _, pattern := range f.patterns {
+			v = pattern.ReplaceAllString(v, "$1"+f.mask)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// IsSensitive reports whether the field name matches one of the fields
+func (f *Fields) IsSensitive(name string) bool {
+	folded := FoldFieldName(name)
+	for _, sensitive := range f.folded {
+		if strings.Contains(folded, sensitive) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,60 @@
+package redact
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestFieldsSanitizeValue(t *testing.T) {
+	type credentials struct {
+		Login    string `json:"login"`
+		Password string `json:"Pass_Word"`
+	}
+	value := struct {
+		Credentials credentials       `json:"credentials"`
+		Headers     map[string]string `json:"headers"`
+		Notes       []string          `json:"notes"`
+	}{
+		Credentials: credentials{Login: "john", Password: "hunter2"},
+		Headers:     map[string]string{"ｐａｓｓｗｏｒｄ": "x", "accept": "json"},
+		Notes:       []string{"Bearer abc.def", "plain"},
+	}
+
+	result, err := Default().SanitizeValue(value)
+	if err != nil {
+		t.Fatalf("SanitizeValue() error = %v", err)
+	}
+
+	data, _ := json.Marshal(result)
+	want := `{"credentials":{"Pass_Word":"***REDACTED***","login":"john"},"headers":{"accept":"json","ｐａｓｓｗｏｒｄ":"***REDACTED***"},"notes":["Bearer ***REDACTED***","plain"]}`
+	if string(data) != want {
+		t.Errorf("SanitizeValue() = %s\nwant %s", data, want)
+	}
+}
+
+func TestFieldsSanitizeValueError(t *testing.T) {
+	if _, err := Default().SanitizeValue(make(chan int)); err == nil {
+		t.Error("SanitizeValue() should fail on unserializable value")
+	}
+}
+
+func TestFoldFieldName(t *testing.T) {
+	for _, name := range []string{"password", "Pass Word", "pass-word", "ＰＡＳＳＷＯＲＤ"} {
+		if got := FoldFieldName(name); got != "password" {
+			t.Errorf("FoldFieldName(%q) = %q", name, got)
+		}
+	}
+}
+
+func TestNewFieldsCustomMask(t *testing.T) {
+	fields := NewFields("[x]", []string{"iin"}, nil)
+	result, _ := fields.SanitizeValue(map[string]string{"client_iin": "900101300123", "name": "a"})
+
+	data, _ := json.Marshal(result)
+	if string(data) != `{"client_iin":"[x]","name":"a"}` {
+		t.Errorf("unexpected result %s", data)
+	}
+	if !fields.IsSensitive("IIN") || fields.IsSensitive("name") {
+		t.Error("IsSensitive mismatch")
+	}
+}
@@ -0,0 +1,107 @@
+package respond
+
+import (
+	"sync"
+
+	"github.com/alimzhanovlr/sdk/errors"
+	"github.com/alimzhanovlr/sdk/server"
+	"github.com/alimzhanovlr/sdk/tracing"
+	"github.com/gofiber/fiber/v2"
+)
+
+// Encoder marshals a response body
+type Encoder func(v interface{}) ([]byte, error)
+
+var (
+	mu sync.RWMutex
+	// contentTypes keeps registration order, the first one is the default
+	contentTypes = []string{fiber.MIMEApplicationJSON, fiber.MIMEApplicationXML}
+	encoders     = map[string]Encoder{
+		fiber.MIMEApplicationXML: marshalXML,
+	}
+)
+
+// RegisterEncoder adds a content type negotiated by the Accept header, e.g.
+//
+//	respond.RegisterEncoder("application/msgpack", msgpack.Marshal)
+func RegisterEncoder(contentType string, encode Encoder) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if _, ok := encoders[contentType]; !ok && contentType != fiber.MIMEApplicationJSON {
+		contentTypes = append(contentTypes, contentType)
+	}
+	encoders[contentType] = encode
+}
+
+// OK sends 200 with data and optional pagination meta
+func OK(c *fiber.Ctx, data interface{}, meta *server.Meta) error {
+	return Send(c, fiber.StatusOK, server.Response{
+		Success: true,
+		Data:    data,
+		Meta:    meta,
+	})
+}
+
+// Created sends 201 with data
+func Created(c *fiber.Ctx, data interface{}) error {
+	return Send(c, fiber.StatusCreated, server.Response{
+		Success: true,
+		Data:    data,
+	})
+}
+
+// NoContent sends 204 without body
+func NoContent(c *fiber.Ctx) error {
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// Error sends err as AppError with its status code
+func Error(c *fiber.Ctx, err error) error {
+	appErr := errors.GetAppError(err)
+	errors.Track(appErr)
+
+	return Send(c, appErr.StatusCode, server.Response{
+		Success: false,
+		Error: &server.ErrorInfo{
+			Code:    appErr.Code,
+			Message: appErr.Message,
+			Details: appErr.Details,
+		},
+	})
+}
+
+// Send encodes the response in the format accepted by the client, JSON when
+// Accept is missing or matches nothing registered. trace_id is set from the
+// request span
+func Send(c *fiber.Ctx, status int, resp server.Response) error {
+	if resp.TraceID == "" {
+		resp.TraceID = tracing.GetTraceID(c.UserContext())
+	}
+
+	contentType, encode := negotiate(c)
+	if encode == nil {
+		// fiber.Config.JSONEncoder is utils.JSONMarshal, a distinct named type
+		encode = Encoder(c.App().Config().JSONEncoder)
+	}
+
+	body, err := encode(resp)
+	if err != nil {
+		return err
+	}
+
+	c.Vary(fiber.HeaderAccept)
+	c.Set(fiber.HeaderContentType, contentType)
+	return c.Status(status).Send(body)
+}
+
+func negotiate(c *fiber.Ctx) (string, Encoder) {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	contentType := c.Accepts(contentTypes...)
+	if contentType == "" {
+		contentType = fiber.MIMEApplicationJSON
+	}
+	return contentType, encoders[contentType]
+}
@@ -0,0 +1,107 @@
+package respond
+
+import (
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/alimzhanovlr/sdk/errors"
+	"github.com/gofiber/fiber/v2"
+)
+
+func request(t *testing.T, handler fiber.Handler, accept string) (int, string, string) {
+	t.Helper()
+
+	app := fiber.New()
+	app.Get("/", handler)
+
+	req := httptest.NewRequest(fiber.MethodGet, "/", nil)
+	if accept != "" {
+		req.Header.Set(fiber.HeaderAccept, accept)
+	}
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, resp.Header.Get(fiber.HeaderContentType), string(body)
+}
+
+func TestSendJSONByDefault(t *testing.T) {
+	status, contentType, body := request(t, func(c *fiber.Ctx) error {
+		return OK(c, fiber.Map{"id": 1}, nil)
+	}, "")
+
+	if status != fiber.StatusOK || contentType != fiber.MIMEApplicationJSON {
+		t.Fatalf("got %d %q", status, contentType)
+	}
+	var resp struct {
+		Success bool           `json:"success"`
+		Data    map[string]int `json:"data"`
+	}
+	if err := json.Unmarshal([]byte(body), &resp); err != nil {
+		t.Fatalf("invalid json %q: %v", body, err)
+	}
+	if !resp.Success || resp.Data["id"] != 1 {
+		t.Errorf("unexpected body %s", body)
+	}
+}
+
+func TestSendXMLMap(t *testing.T) {
+	status, contentType, body := request(t, func(c *fiber.Ctx) error {
+		return Created(c, fiber.Map{"name": "a", "tags": []string{"x", "y"}, "nested": map[string]int{"n": 2}})
+	}, fiber.MIMEApplicationXML)
+
+	if status != fiber.StatusCreated || contentType != fiber.MIMEApplicationXML {
+		t.Fatalf("got %d %q: %s", status, contentType, body)
+	}
+	want := "<response><success>true</success><data><name>a</name><nested><n>2</n></nested><tags><item>x</item><item>y</item></tags></data></response>"
+	if body != want {
+		t.Errorf("body = %s\nwant   %s", body, want)
+	}
+}
+
+func TestSendXMLStruct(t *testing.T) {
+	type user struct {
+		ID int `xml:"id"`
+	}
+	_, _, body := request(t, func(c *fiber.Ctx) error {
+		return OK(c, user{ID: 7}, nil)
+	}, fiber.MIMEApplicationXML)
+
+	if !strings.Contains(body, "<data><id>7</id></data>") {
+		t.Errorf("unexpected body %s", body)
+	}
+}
+
+func TestErrorXMLKeepsDetails(t *testing.T) {
+	status, _, body := request(t, func(c *fiber.Ctx) error {
+		return Error(c, errors.New("invalid_input", "bad input", fiber.StatusBadRequest).
+			WithDetails(map[string]interface{}{"field": "email"}))
+	}, fiber.MIMEApplicationXML)
+
+	if status != fiber.StatusBadRequest {
+		t.Fatalf("status = %d", status)
+	}
+	if !strings.Contains(body, "<code>invalid_input</code>") || !strings.Contains(body, "<details><field>email</field></details>") {
+		t.Errorf("details lost: %s", body)
+	}
+}
+
+func TestRegisterEncoder(t *testing.T) {
+	RegisterEncoder("text/plain", func(v interface{}) ([]byte, error) {
+		return []byte("plain"), nil
+	})
+
+	_, contentType, body := request(t, func(c *fiber.Ctx) error {
+		return OK(c, nil, nil)
+	}, "text/plain")
+
+	if contentType != "text/plain" || body != "plain" {
+		t.Errorf("got %q %q", contentType, body)
+	}
+}
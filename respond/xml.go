@@ -0,0 +1,126 @@
+package respond
+
+import (
+	"encoding/xml"
+	"fmt"
+	"reflect"
+	"slices"
+
+	"github.com/alimzhanovlr/sdk/server"
+)
+
+// xmlResponse mirrors server.Response with map-aware data and details,
+// encoding/xml cannot marshal maps on its own
+type xmlResponse struct {
+	XMLName xml.Name      `xml:"response"`
+	Success bool          `xml:"success"`
+	Data    *xmlValue     `xml:"data,omitempty"`
+	Error   *xmlErrorInfo `xml:"error,omitempty"`
+	Meta    *server.Meta  `xml:"meta,omitempty"`
+	TraceID string        `xml:"trace_id,omitempty"`
+}
+
+type xmlErrorInfo struct {
+	Code    string    `xml:"code"`
+	Message string    `xml:"message"`
+	Details *xmlValue `xml:"details,omitempty"`
+}
+
+// xmlValue encodes maps as one element per key in sorted order and slices
+// as repeated <item> elements, everything else with encoding/xml
+type xmlValue struct {
+	v interface{}
+}
+
+func newXMLValue(v interface{}) *xmlValue {
+	if v == nil {
+		return nil
+	}
+	return &xmlValue{v: v}
+}
+
+func (x xmlValue) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return encodeXMLValue(e, start, reflect.ValueOf(x.v))
+}
+
+func encodeXMLValue(e *xml.Encoder, start xml.StartElement, v reflect.Value) error {
+	for v.IsValid() && (v.Kind() == reflect.Interface || v.Kind() == reflect.Pointer) {
+		if v.IsNil() {
+			return nil
+		}
+		if _, ok := v.Interface().(xml.Marshaler); ok {
+			break
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return fmt.Errorf("xml: unsupported map key type %s", v.Type().Key())
+		}
+		if err := e.EncodeToken(start); err != nil {
+			return err
+		}
+		keys := v.MapKeys()
+		slices.SortFunc(keys, func(a, b reflect.Value) int {
+			switch {
+			case a.String() < b.String():
+				return -1
+			case a.String() > b.String():
+				return 1
+			}
+			return 0
+		})
+		for _, key := range keys {
+			if err := encodeXMLValue(e, xml.StartElement{Name: xml.Name{Local: key.String()}}, v.MapIndex(key)); err != nil {
+				return err
+			}
+		}
+		return e.EncodeToken(start.End())
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return e.EncodeElement(v.Interface(), start)
+		}
+		if err := e.EncodeToken(start); err != nil {
+			return err
+		}
+		for i := 0; i < v.Len(); i++ {
+			if err := encodeXMLValue(e, xml.StartElement{Name: xml.Name{Local: "item"}}, v.Index(i)); err != nil {
+				return err
+			}
+		}
+		return e.EncodeToken(start.End())
+	default:
+		return e.EncodeElement(v.Interface(), start)
+	}
+}
+
+// marshalXML is the default XML encoder, it keeps map payloads and error
+// details that xml.Marshal would reject or drop
+func marshalXML(v interface{}) ([]byte, error) {
+	resp, ok := v.(server.Response)
+	if !ok {
+		return xml.Marshal(v)
+	}
+
+	out := xmlResponse{
+		Success: resp.Success,
+		Data:    newXMLValue(resp.Data),
+		Meta:    resp.Meta,
+		TraceID: resp.TraceID,
+	}
+	if resp.Error != nil {
+		out.Error = &xmlErrorInfo{
+			Code:    resp.Error.Code,
+			Message: resp.Error.Message,
+		}
+		if len(resp.Error.Details) > 0 {
+			out.Error.Details = newXMLValue(resp.Error.Details)
+		}
+	}
+	return xml.Marshal(out)
+}
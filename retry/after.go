@@ -0,0 +1,63 @@
+package retry
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// afterError carries a server hint of when to retry
+type afterError struct {
+	err   error
+	after time.Duration
+}
+
+func (e *afterError) Error() string {
+	return e.err.Error()
+}
+
+func (e *afterError) Unwrap() error {
+	return e.err
+}
+
+// After wraps err with a hint to wait at least d before the next attempt,
+// e.g. a parsed Retry-After header
+func After(err error, d time.Duration) error {
+	if err == nil {
+		return nil
+	}
+	return &afterError{err: err, after: d}
+}
+
+// AfterHint returns the hint of an error wrapped by After
+func AfterHint(err error) (time.Duration, bool) {
+	var after *afterError
+	if errors.As(err, &after) {
+		return after.after, true
+	}
+	return 0, false
+}
+
+// ParseRetryAfter parses a Retry-After header value (RFC 9110), delay in
+// seconds or an HTTP date
+func ParseRetryAfter(value string) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	date, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+	return max(time.Until(date), 0), true
+}
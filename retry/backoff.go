@@ -0,0 +1,57 @@
+package retry
+
+import (
+	"math/rand"
+	"time"
+)
+
+// Backoff computes pauses between attempts
+type Backoff interface {
+	// Delay returns the pause after the failed attempt, starting at 1
+	Delay(attempt int) time.Duration
+}
+
+// BackoffFunc adapts a function to Backoff
+type BackoffFunc func(attempt int) time.Duration
+
+// Delay implements Backoff
+func (f BackoffFunc) Delay(attempt int) time.Duration {
+	return f(attempt)
+}
+
+// Constant pauses for d after every attempt
+func Constant(d time.Duration) Backoff {
+	return BackoffFunc(func(int) time.Duration {
+		return d
+	})
+}
+
+// Exponential doubles the pause from initial after every attempt, maxDelay
+// 0 means no limit
+func Exponential(initial, maxDelay time.Duration) Backoff {
+	return BackoffFunc(func(attempt int) time.Duration {
+		delay := initial
+		for i := 1; i < attempt; i++ {
+			delay *= 2
+			if maxDelay > 0 && delay >= maxDelay {
+				return maxDelay
+			}
+		}
+		if maxDelay > 0 && delay > maxDelay {
+			return maxDelay
+		}
+		return delay
+	})
+}
+
+// Jitter randomizes pauses of b between zero and the pause ("full jitter"),
+// so clients failed at once do not retry at once
+func Jitter(b Backoff) Backoff {
+	return BackoffFunc(func(attempt int) time.Duration {
+		delay := b.Delay(attempt)
+		if delay <= 0 {
+			return 0
+		}
+		return time.Duration(rand.Int63n(int64(delay)))
+	})
+}
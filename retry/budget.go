@@ -0,0 +1,52 @@
+package retry
+
+import "sync"
+
+// Budget limits retries to a dependency across calls, so retries do not
+// multiply load during an outage. It works like gRPC retry throttling: a
+// failed attempt takes a token, a success returns ratio of one, retries
+// are allowed while more than half of maxTokens are left
+type Budget struct {
+	mu        sync.Mutex
+	tokens    float64
+	maxTokens float64
+	ratio     float64
+}
+
+// NewBudget creates a retry budget, e.g. NewBudget(10, 0.1) stops retries
+// when failures outnumber successes ten to one
+func NewBudget(maxTokens int, ratio float64) *Budget {
+	return &Budget{
+		tokens:    float64(maxTokens),
+		maxTokens: float64(maxTokens),
+		ratio:     ratio,
+	}
+}
+
+// Allowed reports whether retries are currently allowed
+func (b *Budget) Allowed() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.tokens > b.maxTokens/2
+}
+
+// failure takes a token and reports whether a retry is allowed, a nil
+// budget always allows
+func (b *Budget) failure() bool {
+	if b == nil {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tokens = max(b.tokens-1, 0)
+	return b.tokens > b.maxTokens/2
+}
+
+func (b *Budget) success() {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tokens = min(b.tokens+b.ratio, b.maxTokens)
+}
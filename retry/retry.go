@@ -0,0 +1,143 @@
+// Package retry runs operations again after transient failures, shared by
+// the HTTP and gRPC clients, messaging consumers, notifications and
+// repository code
+package retry
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Policy controls retries of an operation
+type Policy struct {
+	// MaxAttempts includes the first attempt, defaults to 1
+	MaxAttempts int
+	// Backoff is the pause after a failed attempt, no pause if nil
+	Backoff Backoff
+	// Retryable reports whether err is transient, defaults to any error
+	// except Permanent ones. Context errors are never retried
+	Retryable func(err error) bool
+	// Budget is shared by calls to one dependency, optional
+	Budget *Budget
+
+	// OnRetry is called before the pause after a failed attempt
+	OnRetry func(attempt int, err error, wait time.Duration)
+	// OnGiveUp is called when the last error is returned
+	OnGiveUp func(attempts int, err error)
+}
+
+// DefaultPolicy returns default retry policy
+func DefaultPolicy() Policy {
+	return Policy{
+		MaxAttempts: 3,
+		Backoff:     Jitter(Exponential(100*time.Millisecond, 5*time.Second)),
+	}
+}
+
+// Do calls fn until it succeeds, returns a non-retryable error or attempts
+// run out. The pause is the larger of Backoff and an After hint of the
+// error. Do gives up without waiting if the pause would outlast the
+// context deadline. The last error of fn is returned, joined with the
+// context error if the context is done during a pause
+//
+//	err := retry.Do(ctx, retry.DefaultPolicy(), func(ctx context.Context) error {
+//		return repo.UpdateBalance(ctx, id, amount)
+//	})
+func Do(ctx context.Context, policy Policy, fn func(ctx context.Context) error) error {
+	maxAttempts := max(policy.MaxAttempts, 1)
+
+	for attempt := 1; ; attempt++ {
+		err := fn(ctx)
+		if err == nil {
+			policy.Budget.success()
+			return nil
+		}
+
+		var permanent *permanentError
+		if errors.As(err, &permanent) {
+			return giveUp(policy, attempt, permanent.err)
+		}
+		if ctx.Err() != nil || !policy.retryable(err) {
+			return giveUp(policy, attempt, err)
+		}
+		// The budget counts every transient failure, the last one too
+		if !policy.Budget.failure() || attempt >= maxAttempts {
+			return giveUp(policy, attempt, err)
+		}
+
+		var wait time.Duration
+		if policy.Backoff != nil {
+			wait = policy.Backoff.Delay(attempt)
+		}
+		if hint, ok := AfterHint(err); ok && hint > wait {
+			wait = hint
+		}
+
+		// No attempt fits after the pause
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) <= wait {
+			return giveUp(policy, attempt, err)
+		}
+
+		if policy.OnRetry != nil {
+			policy.OnRetry(attempt, err, wait)
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return giveUp(policy, attempt, errors.Join(err, ctx.Err()))
+		case <-timer.C:
+		}
+	}
+}
+
+// DoValue is Do for operations returning a value
+func DoValue[T any](ctx context.Context, policy Policy, fn func(ctx context.Context) (T, error)) (T, error) {
+	var result T
+	err := Do(ctx, policy, func(ctx context.Context) error {
+		var err error
+		result, err = fn(ctx)
+		return err
+	})
+	return result, err
+}
+
+func (p Policy) retryable(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if p.Retryable == nil {
+		return true
+	}
+	return p.Retryable(err)
+}
+
+func giveUp(policy Policy, attempts int, err error) error {
+	if policy.OnGiveUp != nil {
+		policy.OnGiveUp(attempts, err)
+	}
+	return err
+}
+
+// permanentError stops retries
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e *permanentError) Unwrap() error {
+	return e.err
+}
+
+// Permanent marks err as not retryable, Do returns err itself
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
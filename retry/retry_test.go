@@ -0,0 +1,203 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+var errTransient = errors.New("connection reset")
+
+// failing returns fn failing n times with err before it succeeds
+func failing(n int, err error) (func(context.Context) error, *int) {
+	calls := 0
+	return func(context.Context) error {
+		calls++
+		if calls <= n {
+			return err
+		}
+		return nil
+	}, &calls
+}
+
+func TestDoRetriesUntilSuccess(t *testing.T) {
+	var waits []time.Duration
+	policy := Policy{
+		MaxAttempts: 4,
+		Backoff:     Exponential(time.Millisecond, 0),
+		OnRetry:     func(attempt int, err error, wait time.Duration) { waits = append(waits, wait) },
+		OnGiveUp:    func(int, error) { t.Error("OnGiveUp called on success") },
+	}
+	fn, calls := failing(3, errTransient)
+
+	if err := Do(context.Background(), policy, fn); err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	if *calls != 4 {
+		t.Errorf("calls = %d, want 4", *calls)
+	}
+	if len(waits) != 3 || waits[0] != time.Millisecond || waits[2] != 4*time.Millisecond {
+		t.Errorf("waits = %v, want exponential pauses", waits)
+	}
+}
+
+func TestDoGivesUp(t *testing.T) {
+	tests := []struct {
+		name      string
+		policy    Policy
+		err       error
+		wantCalls int
+		wantErr   error
+	}{
+		{"attempts run out", Policy{MaxAttempts: 3}, errTransient, 3, errTransient},
+		{"zero attempts run once", Policy{}, errTransient, 1, errTransient},
+		{"permanent", Policy{MaxAttempts: 3}, Permanent(errTransient), 1, errTransient},
+		{"not retryable", Policy{MaxAttempts: 3, Retryable: func(err error) bool { return false }}, errTransient, 1, errTransient},
+		{"context error", Policy{MaxAttempts: 3}, context.DeadlineExceeded, 1, context.DeadlineExceeded},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gaveUp int
+			tt.policy.OnGiveUp = func(attempts int, err error) { gaveUp = attempts }
+			fn, calls := failing(10, tt.err)
+
+			err := Do(context.Background(), tt.policy, fn)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Do() error = %v, want %v", err, tt.wantErr)
+			}
+			if *calls != tt.wantCalls || gaveUp != tt.wantCalls {
+				t.Errorf("calls = %d, gave up after %d, want %d", *calls, gaveUp, tt.wantCalls)
+			}
+		})
+	}
+
+	// Permanent errors are returned unwrapped
+	fn, _ := failing(1, Permanent(errTransient))
+	if err := Do(context.Background(), Policy{MaxAttempts: 2}, fn); err != errTransient {
+		t.Errorf("Do() error = %#v, want the wrapped error itself", err)
+	}
+}
+
+func TestDoStopsOnContext(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	// The pause outlasts the deadline, Do returns without waiting
+	start := time.Now()
+	fn, calls := failing(10, errTransient)
+	err := Do(ctx, Policy{MaxAttempts: 5, Backoff: Constant(time.Second)}, fn)
+	if !errors.Is(err, errTransient) || *calls != 1 || time.Since(start) > 40*time.Millisecond {
+		t.Errorf("Do() = %v after %d calls and %s", err, *calls, time.Since(start))
+	}
+
+	// Cancelled during the pause
+	ctx, cancel = context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+	fn, _ = failing(10, errTransient)
+	err = Do(ctx, Policy{MaxAttempts: 5, Backoff: Constant(time.Second)}, fn)
+	if !errors.Is(err, errTransient) || !errors.Is(err, context.Canceled) {
+		t.Errorf("Do() error = %v, want the last error joined with the context error", err)
+	}
+}
+
+func TestDoWaitsForAfterHint(t *testing.T) {
+	var wait time.Duration
+	policy := Policy{
+		MaxAttempts: 2,
+		Backoff:     Constant(time.Millisecond),
+		OnRetry:     func(_ int, _ error, w time.Duration) { wait = w },
+	}
+	fn, _ := failing(1, After(errTransient, 30*time.Millisecond))
+
+	if err := Do(context.Background(), policy, fn); err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	if wait != 30*time.Millisecond {
+		t.Errorf("wait = %s, want the After hint over the backoff", wait)
+	}
+	if After(nil, time.Second) != nil {
+		t.Error("After(nil) != nil")
+	}
+}
+
+func TestDoValue(t *testing.T) {
+	calls := 0
+	got, err := DoValue(context.Background(), Policy{MaxAttempts: 2}, func(context.Context) (string, error) {
+		calls++
+		if calls == 1 {
+			return "", errTransient
+		}
+		return "ok", nil
+	})
+	if err != nil || got != "ok" {
+		t.Errorf("DoValue() = %q, %v", got, err)
+	}
+}
+
+func TestBudgetThrottlesRetries(t *testing.T) {
+	budget := NewBudget(4, 0.5)
+	policy := Policy{MaxAttempts: 10, Budget: budget}
+
+	// 4 tokens, retries stop at 2 or less: two failures pass, the third stops
+	fn, calls := failing(100, errTransient)
+	_ = Do(context.Background(), policy, fn)
+	if *calls != 2 || budget.Allowed() {
+		t.Errorf("calls = %d, allowed = %v, want retries stopped by the budget", *calls, budget.Allowed())
+	}
+
+	// Successes refill the budget by ratio
+	ok, _ := failing(0, nil)
+	for i := 0; i < 2; i++ {
+		_ = Do(context.Background(), policy, ok)
+	}
+	if !budget.Allowed() {
+		t.Error("budget not refilled by successes")
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	exp := Exponential(100*time.Millisecond, time.Second)
+	for attempt, want := range map[int]time.Duration{1: 100 * time.Millisecond, 2: 200 * time.Millisecond, 4: 800 * time.Millisecond, 5: time.Second, 60: time.Second} {
+		if got := exp.Delay(attempt); got != want {
+			t.Errorf("Exponential.Delay(%d) = %s, want %s", attempt, got, want)
+		}
+	}
+
+	jitter := Jitter(Constant(10 * time.Millisecond))
+	for i := 0; i < 100; i++ {
+		if d := jitter.Delay(1); d < 0 || d >= 10*time.Millisecond {
+			t.Fatalf("Jitter delay = %s, want [0, 10ms)", d)
+		}
+	}
+	if d := Jitter(Constant(0)).Delay(1); d != 0 {
+		t.Errorf("Jitter of zero = %s", d)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	tests := []struct {
+		value  string
+		want   time.Duration
+		wantOK bool
+	}{
+		{"120", 2 * time.Minute, true},
+		{" 0 ", 0, true},
+		{"-5", 0, false},
+		{"", 0, false},
+		{"soon", 0, false},
+		{time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat), 0, true},
+	}
+	for _, tt := range tests {
+		got, ok := ParseRetryAfter(tt.value)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("ParseRetryAfter(%q) = %s, %v, want %s, %v", tt.value, got, ok, tt.want, tt.wantOK)
+		}
+	}
+
+	future := time.Now().Add(time.Minute).UTC().Format(http.TimeFormat)
+	if got, ok := ParseRetryAfter(future); !ok || got <= 50*time.Second || got > time.Minute {
+		t.Errorf("ParseRetryAfter(date) = %s, %v", got, ok)
+	}
+}
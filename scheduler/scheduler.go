@@ -0,0 +1,404 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	apperrors "github.com/alimzhanovlr/sdk/errors"
+	"github.com/alimzhanovlr/sdk/lock"
+	"github.com/alimzhanovlr/sdk/logger"
+	"github.com/alimzhanovlr/sdk/metrics"
+	"github.com/alimzhanovlr/sdk/tracing"
+	"github.com/robfig/cron/v3"
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/fx"
+)
+
+// Job describes a scheduled job
+type Job struct {
+	// Name must be unique, it is also used as the lock key
+	Name string
+	// Schedule is a cron expression (5 or 6 fields) or descriptor like @every 1m
+	Schedule string
+	// Run executes the job
+	Run func(ctx context.Context) error
+	// Singleton runs the job on one replica at a time using the Locker.
+	// The lock is released when the run is over
+	Singleton bool
+	// HoldUntilNextTick keeps the singleton lock between runs, so replicas
+	// with skewed clocks do not run the same tick again. With
+	// lock.PostgresLocker every held lock pins a pooled connection for the
+	// whole interval, e.g. 24 hours for a daily job: enable it only for
+	// short intervals or with lock.RedisLocker
+	HoldUntilNextTick bool
+	// Timeout limits a single run, zero means no limit
+	Timeout time.Duration
+}
+
+// Config holds scheduler configuration
+type Config struct {
+	Enabled  bool
+	Timezone string
+}
+
+// JobStats holds per-job counters
+type JobStats struct {
+	Runs         uint64
+	Failures     uint64
+	Skipped      uint64
+	LastRun      time.Time
+	LastDuration time.Duration
+	LastError    string
+}
+
+// Scheduler runs cron jobs
+type Scheduler struct {
+	cron   *cron.Cron
+	config Config
+	locker lock.Locker
+	logger *logger.Logger
+	tracer *tracing.Tracer
+
+	runs     metrics.Counter
+	duration metrics.Histogram
+
+	mu    sync.Mutex
+	jobs  map[string]Job
+	stats map[string]*JobStats
+	// held are singleton locks kept until the next tick, see
+	// Job.HoldUntilNextTick
+	held map[string]lock.Lock
+
+	// ctx is the parent of every run, cancelled when the scheduler stops
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// Params for scheduler constructor
+type Params struct {
+	fx.In
+
+	Config  Config
+	Logger  *logger.Logger
+	Tracer  *tracing.Tracer
+	Locker  lock.Locker       `optional:"true"`
+	Metrics *metrics.Registry `optional:"true"`
+}
+
+// New creates a new scheduler
+func New(p Params) (*Scheduler, error) {
+	location := time.Local
+	if p.Config.Timezone != "" {
+		loc, err := time.LoadLocation(p.Config.Timezone)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load timezone: %w", err)
+		}
+		location = loc
+	}
+
+	parser := cron.NewParser(
+		cron.SecondOptional | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor,
+	)
+
+	s := &Scheduler{
+		cron:   cron.New(cron.WithParser(parser), cron.WithLocation(location)),
+		config: p.Config,
+		locker: p.Locker,
+		logger: p.Logger,
+		tracer: p.Tracer,
+		jobs:   make(map[string]Job),
+		stats:  make(map[string]*JobStats),
+		held:   make(map[string]lock.Lock),
+	}
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+	if p.Metrics != nil {
+		s.runs = p.Metrics.Counter("scheduler_job_runs_total", "Number of job runs by result")
+		s.duration = p.Metrics.Histogram("scheduler_job_duration_seconds", "Job run duration", metrics.DefaultDurationBuckets...)
+	}
+
+	return s, nil
+}
+
+// Register adds a job to the scheduler
+func (s *Scheduler) Register(job Job) error {
+	if job.Name == "" {
+		return fmt.Errorf("job name is required")
+	}
+	if job.Run == nil {
+		return fmt.Errorf("job %s has no run function", job.Name)
+	}
+	if job.Singleton && s.locker == nil {
+		return fmt.Errorf("job %s is singleton but no locker is configured", job.Name)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.jobs[job.Name]; exists {
+		return fmt.Errorf("job %s already registered", job.Name)
+	}
+
+	if _, err := s.cron.AddFunc(job.Schedule, func() { s.execute(job) }); err != nil {
+		return fmt.Errorf("invalid schedule for job %s: %w", job.Name, err)
+	}
+
+	s.jobs[job.Name] = job
+	s.stats[job.Name] = &JobStats{}
+	return nil
+}
+
+// RegisterJobs registers jobs via callback, mirroring server.RegisterRoutes
+func (s *Scheduler) RegisterJobs(register func(*Scheduler) error) error {
+	return register(s)
+}
+
+// RunNow executes job immediately, respecting singleton locking
+func (s *Scheduler) RunNow(name string) error {
+	s.mu.Lock()
+	job, ok := s.jobs[name]
+	s.mu.Unlock()
+
+	if !ok {
+		return fmt.Errorf("job %s not found", name)
+	}
+
+	s.execute(job)
+	return nil
+}
+
+// execute runs a single job invocation
+func (s *Scheduler) execute(job Job) {
+	ctx, cancel := context.WithCancel(s.ctx)
+	defer cancel()
+
+	if job.Singleton {
+		// The previous tick of this replica is over
+		s.releaseHeld(job.Name)
+
+		l, err := s.locker.TryAcquire(ctx, "scheduler:"+job.Name)
+		if errors.Is(err, lock.ErrNotAcquired) {
+			s.logger.Debug("Job tick is handled by another replica", logger.String("job", job.Name))
+			s.record(job.Name, 0, nil, true)
+			return
+		}
+		if err != nil {
+			s.logger.Error("Failed to acquire job lock",
+				logger.String("job", job.Name),
+				logger.Error(err),
+			)
+			s.record(job.Name, 0, err, false)
+			return
+		}
+		if job.HoldUntilNextTick {
+			defer s.hold(job.Name, l)
+		} else {
+			defer s.release(job.Name, l)
+		}
+
+		// Stop the job if another replica may have taken over
+		done := ctx.Done()
+		go func() {
+			select {
+			case <-l.Lost():
+				cancel()
+			case <-done:
+			}
+		}()
+	}
+
+	if job.Timeout > 0 {
+		var timeoutCancel context.CancelFunc
+		ctx, timeoutCancel = context.WithTimeout(ctx, job.Timeout)
+		defer timeoutCancel()
+	}
+
+	ctx, span := s.tracer.NewRootContext(ctx, "job/"+job.Name, tracing.WithRootAttributes(
+		attribute.String("job.name", job.Name),
+		attribute.String("job.schedule", job.Schedule),
+	))
+	defer span.End()
+
+	start := time.Now()
+	err := s.safeRun(ctx, job)
+	duration := time.Since(start)
+
+	if err != nil {
+		span.RecordError(err)
+		s.logger.Error("Job failed",
+			logger.String("job", job.Name),
+			logger.String("duration", duration.String()),
+			logger.Error(err),
+		)
+	} else {
+		s.logger.Info("Job completed",
+			logger.String("job", job.Name),
+			logger.String("duration", duration.String()),
+		)
+	}
+
+	s.record(job.Name, duration, err, false)
+}
+
+// hold keeps the lock of a finished singleton run until the next tick, a
+// lost lock or a run finishing after stop releases it at once
+func (s *Scheduler) hold(name string, l lock.Lock) {
+	select {
+	case <-l.Lost():
+		_ = l.Release(context.Background())
+		return
+	default:
+	}
+
+	s.mu.Lock()
+	if s.ctx.Err() != nil {
+		s.mu.Unlock()
+		s.release(name, l)
+		return
+	}
+	s.held[name] = l
+	s.mu.Unlock()
+}
+
+// releaseHeld releases the lock kept by hold for job name
+func (s *Scheduler) releaseHeld(name string) {
+	s.mu.Lock()
+	l, ok := s.held[name]
+	delete(s.held, name)
+	s.mu.Unlock()
+
+	if ok {
+		s.release(name, l)
+	}
+}
+
+// release releases the lock of job name
+func (s *Scheduler) release(name string, l lock.Lock) {
+	if err := l.Release(context.Background()); err != nil {
+		s.logger.Warn("Failed to release job lock",
+			logger.String("job", name),
+			logger.Error(err),
+		)
+	}
+}
+
+// releaseAll releases locks of all jobs
+func (s *Scheduler) releaseAll() {
+	s.mu.Lock()
+	names := make([]string, 0, len(s.held))
+	for name := range s.held {
+		names = append(names, name)
+	}
+	s.mu.Unlock()
+
+	for _, name := range names {
+		s.releaseHeld(name)
+	}
+}
+
+// safeRun runs job converting panics to errors
+func (s *Scheduler) safeRun(ctx context.Context, job Job) (err error) {
+	defer apperrors.Recover(&err)
+	return job.Run(ctx)
+}
+
+// record updates job stats
+func (s *Scheduler) record(name string, duration time.Duration, err error, skipped bool) {
+	s.observe(name, duration, err, skipped)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats := s.stats[name]
+	if skipped {
+		stats.Skipped++
+		return
+	}
+
+	stats.Runs++
+	stats.LastRun = time.Now()
+	stats.LastDuration = duration
+	stats.LastError = ""
+	if err != nil {
+		stats.Failures++
+		stats.LastError = err.Error()
+	}
+}
+
+// observe records a run in metrics, skipped runs have no duration
+func (s *Scheduler) observe(name string, duration time.Duration, err error, skipped bool) {
+	if s.runs == nil {
+		return
+	}
+
+	result := "success"
+	switch {
+	case skipped:
+		result = "skipped"
+	case err != nil:
+		result = "failure"
+	}
+
+	ctx := context.Background()
+	s.runs.Add(ctx, 1, attribute.String("job", name), attribute.String("result", result))
+	if !skipped {
+		s.duration.Record(ctx, duration.Seconds(), attribute.String("job", name), attribute.String("result", result))
+	}
+}
+
+// Stats returns a copy of job stats
+func (s *Scheduler) Stats() map[string]JobStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := make(map[string]JobStats, len(s.stats))
+	for name, stats := range s.stats {
+		result[name] = *stats
+	}
+	return result
+}
+
+// Start registers scheduler in fx lifecycle
+func (s *Scheduler) Start(lc fx.Lifecycle) {
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			if !s.config.Enabled {
+				s.logger.Info("Scheduler is disabled")
+				return nil
+			}
+
+			s.logger.Info("Starting scheduler", logger.Int("jobs", len(s.jobs)))
+			s.cron.Start()
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			if !s.config.Enabled {
+				return nil
+			}
+
+			s.logger.Info("Shutting down scheduler")
+			return s.stop(ctx)
+		},
+	})
+}
+
+// stop waits for running jobs until ctx expires, then cancels them. Held
+// locks are released either way, a cancelled job releases its own lock
+// when it returns
+func (s *Scheduler) stop(ctx context.Context) error {
+	var err error
+	select {
+	case <-s.cron.Stop().Done():
+	case <-ctx.Done():
+		err = fmt.Errorf("timed out waiting for running jobs: %w", ctx.Err())
+	}
+
+	s.mu.Lock()
+	s.cancel()
+	s.mu.Unlock()
+
+	s.releaseAll()
+	return err
+}
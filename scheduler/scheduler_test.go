@@ -0,0 +1,274 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alimzhanovlr/sdk/lock"
+	"github.com/alimzhanovlr/sdk/logger"
+	"github.com/alimzhanovlr/sdk/tracing"
+	"go.uber.org/zap"
+)
+
+// memoryLocker is an in-process Locker shared by schedulers of one test
+type memoryLocker struct {
+	mu       sync.Mutex
+	held     map[string]bool
+	released int
+}
+
+func (m *memoryLocker) TryAcquire(_ context.Context, key string) (lock.Lock, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.held[key] {
+		return nil, lock.ErrNotAcquired
+	}
+	m.held[key] = true
+	return &memoryLock{locker: m, key: key, lost: make(chan struct{})}, nil
+}
+
+func (m *memoryLocker) isHeld(key string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.held[key]
+}
+
+type memoryLock struct {
+	locker *memoryLocker
+	key    string
+	lost   chan struct{}
+	once   sync.Once
+}
+
+func (l *memoryLock) Key() string           { return l.key }
+func (l *memoryLock) Lost() <-chan struct{} { return l.lost }
+
+func (l *memoryLock) Release(context.Context) error {
+	l.once.Do(func() {
+		l.locker.mu.Lock()
+		defer l.locker.mu.Unlock()
+		delete(l.locker.held, l.key)
+		l.locker.released++
+	})
+	return nil
+}
+
+func newTestScheduler(t *testing.T, locker lock.Locker) *Scheduler {
+	t.Helper()
+	tracer, err := tracing.New(tracing.Config{})
+	if err != nil {
+		t.Fatalf("tracing.New() error = %v", err)
+	}
+	s, err := New(Params{
+		Config: Config{Enabled: true, Timezone: "UTC"},
+		Logger: &logger.Logger{Logger: zap.NewNop()},
+		Tracer: tracer,
+		Locker: locker,
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	return s
+}
+
+func TestSingletonLockIsHeldUntilNextTick(t *testing.T) {
+	locker := &memoryLocker{held: map[string]bool{}}
+	replicaA := newTestScheduler(t, locker)
+	replicaB := newTestScheduler(t, locker)
+
+	var mu sync.Mutex
+	runs := map[string]int{}
+	job := func(replica string) Job {
+		return Job{
+			Name:              "report",
+			Schedule:          "@every 1m",
+			Singleton:         true,
+			HoldUntilNextTick: true,
+			Run: func(context.Context) error {
+				mu.Lock()
+				defer mu.Unlock()
+				runs[replica]++
+				return nil
+			},
+		}
+	}
+	if err := replicaA.Register(job("a")); err != nil {
+		t.Fatal(err)
+	}
+	if err := replicaB.Register(job("b")); err != nil {
+		t.Fatal(err)
+	}
+
+	// A finished the tick quickly, B fires the same tick late
+	_ = replicaA.RunNow("report")
+	if !locker.isHeld("scheduler:report") {
+		t.Fatal("lock released right after the run")
+	}
+	_ = replicaB.RunNow("report")
+
+	if runs["a"] != 1 || runs["b"] != 0 {
+		t.Fatalf("runs = %v, want the tick handled by a only", runs)
+	}
+	if got := replicaB.Stats()["report"].Skipped; got != 1 {
+		t.Errorf("b skipped = %d, want 1", got)
+	}
+
+	// Next tick of A releases the previous lock and takes it again
+	_ = replicaA.RunNow("report")
+	if runs["a"] != 2 || locker.released != 1 {
+		t.Errorf("runs = %v, released = %d", runs, locker.released)
+	}
+
+	replicaA.releaseAll()
+	if locker.isHeld("scheduler:report") {
+		t.Error("lock is held after releaseAll")
+	}
+}
+
+func TestSingletonLockIsReleasedAfterRun(t *testing.T) {
+	locker := &memoryLocker{held: map[string]bool{}}
+	replicaA := newTestScheduler(t, locker)
+	replicaB := newTestScheduler(t, locker)
+
+	runs := 0
+	job := Job{
+		Name:      "cleanup",
+		Schedule:  "@daily",
+		Singleton: true,
+		Run: func(context.Context) error {
+			if !locker.isHeld("scheduler:cleanup") {
+				t.Error("job runs without the lock")
+			}
+			runs++
+			return nil
+		},
+	}
+	if err := replicaA.Register(job); err != nil {
+		t.Fatal(err)
+	}
+	if err := replicaB.Register(job); err != nil {
+		t.Fatal(err)
+	}
+
+	_ = replicaA.RunNow("cleanup")
+	if locker.isHeld("scheduler:cleanup") || locker.released != 1 {
+		t.Fatalf("lock is kept after the run, released = %d", locker.released)
+	}
+	_ = replicaB.RunNow("cleanup")
+	if runs != 2 {
+		t.Errorf("runs = %d, want 2 for sequential runs", runs)
+	}
+}
+
+func TestLostLockIsNotHeld(t *testing.T) {
+	locker := &memoryLocker{held: map[string]bool{}}
+	s := newTestScheduler(t, locker)
+
+	l, _ := locker.TryAcquire(context.Background(), "scheduler:sync")
+	close(l.(*memoryLock).lost)
+	s.hold("sync", l)
+
+	if locker.isHeld("scheduler:sync") {
+		t.Error("lost lock was kept")
+	}
+}
+
+func TestStopCancelsRunningJobsAndReleasesLocks(t *testing.T) {
+	locker := &memoryLocker{held: map[string]bool{}}
+	s := newTestScheduler(t, locker)
+
+	held, _ := locker.TryAcquire(context.Background(), "scheduler:report")
+	s.hold("report", held)
+
+	started := make(chan struct{})
+	var once sync.Once
+	err := s.Register(Job{
+		Name:      "export",
+		Schedule:  "@every 1s",
+		Singleton: true,
+		Run: func(ctx context.Context) error {
+			once.Do(func() { close(started) })
+			<-ctx.Done()
+			return ctx.Err()
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s.cron.Start()
+	select {
+	case <-started:
+	case <-time.After(3 * time.Second):
+		t.Fatal("job did not start")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := s.stop(ctx); err == nil {
+		t.Error("stop() = nil, want a timeout error for the running job")
+	}
+
+	if locker.isHeld("scheduler:report") {
+		t.Error("held lock is kept after a timed out stop")
+	}
+	deadline := time.Now().Add(time.Second)
+	for locker.isHeld("scheduler:export") {
+		if time.Now().After(deadline) {
+			t.Fatal("running job was not cancelled and kept its lock")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestRunRecoversPanicsAndRecordsFailures(t *testing.T) {
+	s := newTestScheduler(t, nil)
+
+	if err := s.Register(Job{Name: "panics", Schedule: "* * * * *", Run: func(context.Context) error {
+		panic("boom")
+	}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Register(Job{Name: "fails", Schedule: "* * * * *", Run: func(context.Context) error {
+		return errors.New("failed")
+	}}); err != nil {
+		t.Fatal(err)
+	}
+
+	_ = s.RunNow("panics")
+	_ = s.RunNow("fails")
+
+	stats := s.Stats()
+	if stats["panics"].Failures != 1 || stats["fails"].Failures != 1 || stats["fails"].LastError != "failed" {
+		t.Errorf("Stats() = %+v", stats)
+	}
+	if !strings.Contains(stats["panics"].LastError, "panic: boom") {
+		t.Errorf("panic error = %q", stats["panics"].LastError)
+	}
+}
+
+func TestRegisterValidatesJobs(t *testing.T) {
+	s := newTestScheduler(t, nil)
+	run := func(context.Context) error { return nil }
+
+	tests := []struct {
+		name string
+		job  Job
+	}{
+		{"no name", Job{Schedule: "@hourly", Run: run}},
+		{"no run", Job{Name: "a", Schedule: "@hourly"}},
+		{"bad schedule", Job{Name: "a", Schedule: "every hour", Run: run}},
+		{"singleton without locker", Job{Name: "a", Schedule: "@hourly", Run: run, Singleton: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := s.Register(tt.job); err == nil {
+				t.Error("Register() error = nil")
+			}
+		})
+	}
+}
@@ -0,0 +1,350 @@
+package search
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/alimzhanovlr/sdk/retry"
+)
+
+// Bulk actions
+const (
+	ActionIndex  = "index"
+	ActionCreate = "create"
+	ActionUpdate = "update"
+	ActionDelete = "delete"
+)
+
+// ErrBulkClosed is returned by Add after Close
+var ErrBulkClosed = errors.New("search: bulk indexer closed")
+
+// BulkItem is a document operation of a bulk request
+type BulkItem struct {
+	// Action defaults to index
+	Action string
+	// Index defaults to BulkConfig.Index
+	Index string
+	ID    string
+	// Document is the source for index and create and the update body for
+	// update, e.g. {"doc": {...}}. Delete has none
+	Document interface{}
+}
+
+// BulkConfig holds bulk indexer configuration
+type BulkConfig struct {
+	Index string
+	// Workers send bulk requests concurrently
+	Workers int
+	// FlushBytes flushes a worker buffer reaching this size
+	FlushBytes int
+	// FlushInterval flushes non-empty buffers periodically
+	FlushInterval time.Duration
+	// QueueSize bounds items waiting for a worker, Add blocks when it is full
+	QueueSize int
+	// MaxRetries retries items rejected with 429 when the cluster is
+	// overloaded, with backoff. Negative disables retries. A whole request
+	// rejected with 429, 502, 503 or 504 is resent by the client, see
+	// Config.MaxRetries
+	MaxRetries int
+	// OnError is called for items that failed, optional
+	OnError func(ctx context.Context, item BulkItem, err error)
+}
+
+// DefaultBulkConfig returns default bulk indexer config
+func DefaultBulkConfig() BulkConfig {
+	return BulkConfig{
+		Workers:       2,
+		FlushBytes:    5 << 20,
+		FlushInterval: time.Second,
+		QueueSize:     1000,
+		MaxRetries:    3,
+	}
+}
+
+// BulkStats are counters of a bulk indexer
+type BulkStats struct {
+	Added     uint64
+	Succeeded uint64
+	Failed    uint64
+	Requests  uint64
+}
+
+type bulkEntry struct {
+	item BulkItem
+	data []byte
+}
+
+// BulkIndexer batches items into bulk requests. Workers block while a
+// request is in flight and Add blocks when the queue is full, so producers
+// slow down to what the cluster accepts
+type BulkIndexer struct {
+	client *Client
+	config BulkConfig
+	queue  chan bulkEntry
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu     sync.RWMutex
+	closed bool
+
+	added     atomic.Uint64
+	succeeded atomic.Uint64
+	failed    atomic.Uint64
+	requests  atomic.Uint64
+}
+
+// NewBulkIndexer starts a bulk indexer, Close flushes it
+func (c *Client) NewBulkIndexer(config BulkConfig) *BulkIndexer {
+	defaults := DefaultBulkConfig()
+	if config.Workers <= 0 {
+		config.Workers = defaults.Workers
+	}
+	if config.FlushBytes <= 0 {
+		config.FlushBytes = defaults.FlushBytes
+	}
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = defaults.FlushInterval
+	}
+	if config.QueueSize <= 0 {
+		config.QueueSize = defaults.QueueSize
+	}
+	if config.MaxRetries == 0 {
+		config.MaxRetries = defaults.MaxRetries
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	b := &BulkIndexer{
+		client: c,
+		config: config,
+		queue:  make(chan bulkEntry, config.QueueSize),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+
+	b.wg.Add(config.Workers)
+	for i := 0; i < config.Workers; i++ {
+		go b.worker()
+	}
+	return b
+}
+
+// Add queues an item, waiting for queue space while ctx allows. Encoding
+// errors are returned here, indexing errors go to OnError
+func (b *BulkIndexer) Add(ctx context.Context, item BulkItem) error {
+	if item.Action == "" {
+		item.Action = ActionIndex
+	}
+	if item.Index == "" {
+		item.Index = b.config.Index
+	}
+	data, err := b.encode(item)
+	if err != nil {
+		return err
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if b.closed {
+		return ErrBulkClosed
+	}
+
+	select {
+	case b.queue <- bulkEntry{item: item, data: data}:
+		b.added.Add(1)
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close stops accepting items and waits until queued items are sent. When
+// ctx is done first, in-flight requests are cancelled
+func (b *BulkIndexer) Close(ctx context.Context) error {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return nil
+	}
+	b.closed = true
+	close(b.queue)
+	b.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		b.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		b.cancel()
+		return nil
+	case <-ctx.Done():
+		b.cancel()
+		<-done
+		return fmt.Errorf("failed to flush bulk indexer: %w", ctx.Err())
+	}
+}
+
+// Stats returns counters
+func (b *BulkIndexer) Stats() BulkStats {
+	return BulkStats{
+		Added:     b.added.Load(),
+		Succeeded: b.succeeded.Load(),
+		Failed:    b.failed.Load(),
+		Requests:  b.requests.Load(),
+	}
+}
+
+func (b *BulkIndexer) encode(item BulkItem) ([]byte, error) {
+	switch item.Action {
+	case ActionIndex, ActionCreate, ActionUpdate, ActionDelete:
+	default:
+		return nil, fmt.Errorf("unsupported bulk action %q", item.Action)
+	}
+	if item.Index == "" {
+		return nil, errors.New("bulk item index is required")
+	}
+	if item.ID == "" && item.Action != ActionIndex {
+		return nil, fmt.Errorf("bulk %s requires an id", item.Action)
+	}
+
+	meta := map[string]string{"_index": b.client.IndexName(item.Index)}
+	if item.ID != "" {
+		meta["_id"] = item.ID
+	}
+	data, err := json.Marshal(map[string]interface{}{item.Action: meta})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode bulk item: %w", err)
+	}
+	data = append(data, '\n')
+
+	if item.Action != ActionDelete {
+		doc, err := json.Marshal(item.Document)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode bulk document: %w", err)
+		}
+		data = append(append(data, doc...), '\n')
+	}
+	return data, nil
+}
+
+func (b *BulkIndexer) worker() {
+	defer b.wg.Done()
+
+	ticker := time.NewTicker(b.config.FlushInterval)
+	defer ticker.Stop()
+
+	var (
+		batch []bulkEntry
+		size  int
+	)
+	flush := func() {
+		if len(batch) > 0 {
+			b.flush(batch)
+			batch, size = nil, 0
+		}
+	}
+
+	for {
+		select {
+		case entry, ok := <-b.queue:
+			if !ok {
+				flush()
+				return
+			}
+			batch = append(batch, entry)
+			size += len(entry.data)
+			if size >= b.config.FlushBytes {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+type bulkResponse struct {
+	Errors bool `json:"errors"`
+	Items  []map[string]struct {
+		Status int `json:"status"`
+		Error  *struct {
+			Type   string `json:"type"`
+			Reason string `json:"reason"`
+		} `json:"error"`
+	} `json:"items"`
+}
+
+// flush sends batch, resending items rejected with 429 until retries run out
+func (b *BulkIndexer) flush(batch []bulkEntry) {
+	backoff := retry.Jitter(retry.Exponential(500*time.Millisecond, 30*time.Second))
+
+	for attempt := 1; len(batch) > 0; attempt++ {
+		var body bytes.Buffer
+		for _, entry := range batch {
+			body.Write(entry.data)
+		}
+
+		b.requests.Add(1)
+		var resp bulkResponse
+		err := b.client.do(b.ctx, "bulk", http.MethodPost, "/_bulk", "application/x-ndjson", body.Bytes(), &resp)
+		if err == nil && len(resp.Items) != len(batch) {
+			err = fmt.Errorf("bulk response has %d items, sent %d", len(resp.Items), len(batch))
+		}
+		if err != nil {
+			for _, entry := range batch {
+				b.fail(entry.item, err)
+			}
+			return
+		}
+
+		var rejected []bulkEntry
+		for i, result := range resp.Items {
+			for _, r := range result {
+				switch {
+				case r.Error == nil && r.Status < 300,
+					// Deleting a missing document is not an error
+					batch[i].item.Action == ActionDelete && r.Status == http.StatusNotFound:
+					b.succeeded.Add(1)
+				case r.Status == http.StatusTooManyRequests && attempt <= b.config.MaxRetries:
+					rejected = append(rejected, batch[i])
+				default:
+					itemErr := &Error{Status: r.Status}
+					if r.Error != nil {
+						itemErr.Type, itemErr.Reason = r.Error.Type, r.Error.Reason
+					}
+					b.fail(batch[i].item, itemErr)
+				}
+			}
+		}
+		if len(rejected) == 0 {
+			return
+		}
+
+		select {
+		case <-time.After(backoff.Delay(attempt)):
+			batch = rejected
+		case <-b.ctx.Done():
+			for _, entry := range rejected {
+				b.fail(entry.item, b.ctx.Err())
+			}
+			return
+		}
+	}
+}
+
+func (b *BulkIndexer) fail(item BulkItem, err error) {
+	b.failed.Add(1)
+	if b.config.OnError != nil {
+		b.config.OnError(b.ctx, item, err)
+	}
+}
@@ -0,0 +1,104 @@
+package search
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/alimzhanovlr/sdk/config"
+)
+
+// Config holds search client configuration
+type Config struct {
+	// Addresses are node URLs, requests are spread round robin
+	Addresses []string
+	Username  string
+	Password  string
+	// APIKey is the base64 encoded id:key, takes precedence over basic auth
+	APIKey string
+	TLS    TLSConfig
+	// Timeout limits one attempt of a request
+	Timeout time.Duration
+	// MaxRetries retries connection errors, 429, 502, 503 and 504 on the
+	// next node. Other POST requests than search, refresh and bulk, such as
+	// index without id, are retried only when the node was unreachable
+	MaxRetries int
+	// IndexPrefix is prepended to index and template names
+	IndexPrefix string
+	// Transport is optional, use it to plug httpclient round trippers
+	Transport http.RoundTripper
+}
+
+// TLSConfig holds TLS configuration
+type TLSConfig struct {
+	Enabled            bool
+	CAFile             string
+	CertFile           string
+	KeyFile            string
+	InsecureSkipVerify bool
+}
+
+// DefaultConfig returns default search config
+func DefaultConfig() Config {
+	return Config{
+		Addresses:  []string{"http://localhost:9200"},
+		Timeout:    10 * time.Second,
+		MaxRetries: 3,
+	}
+}
+
+// FromConfig converts the search section of service config
+func FromConfig(c config.SearchConfig) Config {
+	return Config{
+		Addresses: c.Addresses,
+		Username:  c.Username,
+		Password:  c.Password,
+		APIKey:    c.APIKey,
+		TLS: TLSConfig{
+			Enabled:            c.TLS.Enabled,
+			CAFile:             c.TLS.CAFile,
+			CertFile:           c.TLS.CertFile,
+			KeyFile:            c.TLS.KeyFile,
+			InsecureSkipVerify: c.TLS.InsecureSkipVerify,
+		},
+		Timeout:     c.Timeout,
+		MaxRetries:  c.MaxRetries,
+		IndexPrefix: c.IndexPrefix,
+	}
+}
+
+// tlsConfig builds tls.Config from TLSConfig
+func (c TLSConfig) tlsConfig() (*tls.Config, error) {
+	if !c.Enabled {
+		return nil, nil
+	}
+
+	cfg := &tls.Config{
+		InsecureSkipVerify: c.InsecureSkipVerify,
+	}
+
+	if c.CAFile != "" {
+		ca, err := os.ReadFile(c.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("failed to parse CA file %s", c.CAFile)
+		}
+		cfg.RootCAs = pool
+	}
+
+	if c.CertFile != "" && c.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	return cfg, nil
+}
@@ -0,0 +1,396 @@
+// Package search is an Elasticsearch and OpenSearch client over their common
+// REST API: documents, search, bulk indexing and index templates. Requests
+// are traced, spread over nodes and retried on transient failures
+package search
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/alimzhanovlr/sdk/retry"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/alimzhanovlr/sdk/search"
+
+// ErrNotFound is returned when a document or index does not exist
+var ErrNotFound = errors.New("search: not found")
+
+// Error is an error response of the cluster
+type Error struct {
+	Status int
+	Type   string
+	Reason string
+}
+
+func (e *Error) Error() string {
+	if e.Type == "" {
+		return fmt.Sprintf("search: status %d", e.Status)
+	}
+	return fmt.Sprintf("search: status %d: %s: %s", e.Status, e.Type, e.Reason)
+}
+
+// Is makes 404 responses match ErrNotFound
+func (e *Error) Is(target error) bool {
+	return target == ErrNotFound && e.Status == http.StatusNotFound
+}
+
+// Client is a search cluster client, safe for concurrent use
+type Client struct {
+	config Config
+	http   *http.Client
+	nodes  []*url.URL
+	next   atomic.Uint64
+	tracer trace.Tracer
+	policy retry.Policy
+}
+
+// New creates a client, no request is made
+func New(cfg Config) (*Client, error) {
+	if len(cfg.Addresses) == 0 {
+		return nil, errors.New("search addresses are required")
+	}
+
+	nodes := make([]*url.URL, 0, len(cfg.Addresses))
+	for _, addr := range cfg.Addresses {
+		u, err := url.Parse(strings.TrimSuffix(addr, "/"))
+		if err != nil || u.Host == "" {
+			return nil, fmt.Errorf("invalid search address %q", addr)
+		}
+		nodes = append(nodes, u)
+	}
+
+	transport := cfg.Transport
+	if transport == nil {
+		tlsConfig, err := cfg.TLS.tlsConfig()
+		if err != nil {
+			return nil, err
+		}
+		t := http.DefaultTransport.(*http.Transport).Clone()
+		t.TLSClientConfig = tlsConfig
+		t.MaxIdleConnsPerHost = 32
+		transport = t
+	}
+
+	return &Client{
+		config: cfg,
+		http:   &http.Client{Transport: transport, Timeout: cfg.Timeout},
+		nodes:  nodes,
+		tracer: otel.Tracer(instrumentationName),
+		policy: retry.Policy{
+			MaxAttempts: cfg.MaxRetries + 1,
+			Backoff:     retry.Jitter(retry.Exponential(100*time.Millisecond, 5*time.Second)),
+			Retryable:   retryable,
+		},
+	}, nil
+}
+
+// IndexName returns name with the configured prefix, applied by every
+// method taking an index
+func (c *Client) IndexName(name string) string {
+	if c.config.IndexPrefix == "" || strings.HasPrefix(name, c.config.IndexPrefix) {
+		return name
+	}
+	return c.config.IndexPrefix + name
+}
+
+// Ping checks that a node responds
+func (c *Client) Ping(ctx context.Context) error {
+	return c.Do(ctx, "ping", http.MethodHead, "/", nil, nil)
+}
+
+// Index stores document under id, an empty id lets the cluster generate
+// one. The generated or given id is returned
+func (c *Client) Index(ctx context.Context, index, id string, document interface{}) (string, error) {
+	method, path := http.MethodPost, "/"+url.PathEscape(c.IndexName(index))+"/_doc"
+	if id != "" {
+		method, path = http.MethodPut, path+"/"+url.PathEscape(id)
+	}
+
+	var result struct {
+		ID string `json:"_id"`
+	}
+	if err := c.Do(ctx, "index", method, path, document, &result); err != nil {
+		return "", err
+	}
+	return result.ID, nil
+}
+
+// Get decodes the source of a document into dst, ErrNotFound if it does not
+// exist
+func (c *Client) Get(ctx context.Context, index, id string, dst interface{}) error {
+	var result struct {
+		Found  bool            `json:"found"`
+		Source json.RawMessage `json:"_source"`
+	}
+	path := "/" + url.PathEscape(c.IndexName(index)) + "/_doc/" + url.PathEscape(id)
+	if err := c.Do(ctx, "get", http.MethodGet, path, nil, &result); err != nil {
+		return err
+	}
+	if !result.Found {
+		return ErrNotFound
+	}
+	if err := json.Unmarshal(result.Source, dst); err != nil {
+		return fmt.Errorf("failed to decode document: %w", err)
+	}
+	return nil
+}
+
+// Delete removes a document, deleting a missing document is not an error
+func (c *Client) Delete(ctx context.Context, index, id string) error {
+	path := "/" + url.PathEscape(c.IndexName(index)) + "/_doc/" + url.PathEscape(id)
+	err := c.Do(ctx, "delete", http.MethodDelete, path, nil, nil)
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	return err
+}
+
+// Refresh makes recent changes of an index searchable, e.g. in tests
+func (c *Client) Refresh(ctx context.Context, index string) error {
+	return c.Do(ctx, "refresh", http.MethodPost, "/"+url.PathEscape(c.IndexName(index))+"/_refresh", nil, nil)
+}
+
+// SearchResult is a search response
+type SearchResult struct {
+	Took     int  `json:"took"`
+	TimedOut bool `json:"timed_out"`
+	Hits     struct {
+		Total    Total    `json:"total"`
+		MaxScore *float64 `json:"max_score"`
+		Hits     []Hit    `json:"hits"`
+	} `json:"hits"`
+	Aggregations map[string]json.RawMessage `json:"aggregations,omitempty"`
+}
+
+// Total is the number of matching documents, a lower bound when Relation is
+// gte
+type Total struct {
+	Value    int64  `json:"value"`
+	Relation string `json:"relation"`
+}
+
+// UnmarshalJSON accepts the object form and the plain number of older
+// versions
+func (t *Total) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] != '{' {
+		t.Relation = "eq"
+		return json.Unmarshal(data, &t.Value)
+	}
+	type total Total
+	return json.Unmarshal(data, (*total)(t))
+}
+
+// Hit is a matching document
+type Hit struct {
+	Index     string                     `json:"_index"`
+	ID        string                     `json:"_id"`
+	Score     *float64                   `json:"_score"`
+	Source    json.RawMessage            `json:"_source"`
+	Sort      []interface{}              `json:"sort,omitempty"`
+	Highlight map[string][]string        `json:"highlight,omitempty"`
+	Fields    map[string]json.RawMessage `json:"fields,omitempty"`
+}
+
+// Decode decodes the document source into dst
+func (h *Hit) Decode(dst interface{}) error {
+	if err := json.Unmarshal(h.Source, dst); err != nil {
+		return fmt.Errorf("failed to decode hit %s: %w", h.ID, err)
+	}
+	return nil
+}
+
+// Search runs query, the request body of the search API, e.g.
+// map[string]interface{}{"query": ..., "size": 20}. Several indices are comma
+// separated
+func (c *Client) Search(ctx context.Context, index string, query interface{}) (*SearchResult, error) {
+	indices := strings.Split(index, ",")
+	for i, name := range indices {
+		indices[i] = url.PathEscape(c.IndexName(strings.TrimSpace(name)))
+	}
+
+	var result SearchResult
+	if err := c.Do(ctx, "search", http.MethodPost, "/"+strings.Join(indices, ",")+"/_search", query, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Do sends a request to the next node and decodes a JSON response into
+// result, use it for APIs without a method. Body is JSON encoded unless it is
+// []byte. Operation names the span
+func (c *Client) Do(ctx context.Context, operation, method, path string, body, result interface{}) error {
+	var payload []byte
+	switch b := body.(type) {
+	case nil:
+	case []byte:
+		payload = b
+	default:
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return fmt.Errorf("failed to encode search request: %w", err)
+		}
+	}
+	return c.do(ctx, operation, method, path, "application/json", payload, result)
+}
+
+func (c *Client) do(ctx context.Context, operation, method, path, contentType string, payload []byte, result interface{}) error {
+	ctx, span := c.tracer.Start(ctx, "search "+operation, trace.WithSpanKind(trace.SpanKindClient))
+	defer span.End()
+	span.SetAttributes(
+		attribute.String("db.system", "elasticsearch"),
+		attribute.String("db.operation", operation),
+		attribute.String("http.request.method", method),
+		attribute.String("url.path", path),
+	)
+
+	policy := c.policy
+	switch {
+	case idempotent(operation, method):
+	case operation == "bulk":
+		// A bulk request rejected as a whole by an overloaded or unavailable
+		// node was not applied, so it is resent like a read. Items without
+		// an id may be duplicated if a gateway timed out after the node
+		// applied them
+	default:
+		// The node may have applied the request before failing
+		policy.Retryable = connectError
+	}
+
+	attempts := 0
+	err := retry.Do(ctx, policy, func(ctx context.Context) error {
+		attempts++
+		return c.send(ctx, method, path, contentType, payload, result)
+	})
+	span.SetAttributes(attribute.Int("search.attempts", attempts))
+
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		span.SetAttributes(attribute.Int("http.response.status_code", apiErr.Status))
+	}
+	// A missing document is an answer, not a failure
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return err
+}
+
+func (c *Client) send(ctx context.Context, method, path, contentType string, payload []byte, result interface{}) error {
+	node := c.nodes[int(c.next.Add(1)-1)%len(c.nodes)]
+
+	var body io.Reader
+	if payload != nil {
+		body = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, node.String()+path, body)
+	if err != nil {
+		return retry.Permanent(fmt.Errorf("failed to create search request: %w", err))
+	}
+	if payload != nil {
+		req.Header.Set("Content-Type", contentType)
+	}
+	req.Header.Set("Accept", "application/json")
+	switch {
+	case c.config.APIKey != "":
+		req.Header.Set("Authorization", "ApiKey "+c.config.APIKey)
+	case c.config.Username != "":
+		req.SetBasicAuth(c.config.Username, c.config.Password)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send search request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		apiErr := decodeError(resp)
+		if wait, ok := retry.ParseRetryAfter(resp.Header.Get("Retry-After")); ok {
+			return retry.After(apiErr, wait)
+		}
+		return apiErr
+	}
+
+	if result == nil || method == http.MethodHead {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return retry.Permanent(fmt.Errorf("failed to decode search response: %w", err))
+	}
+	return nil
+}
+
+func decodeError(resp *http.Response) *Error {
+	apiErr := &Error{Status: resp.StatusCode}
+
+	var body struct {
+		Error json.RawMessage `json:"error"`
+	}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if json.Unmarshal(data, &body) != nil || len(body.Error) == 0 {
+		return apiErr
+	}
+
+	var cause struct {
+		Type   string `json:"type"`
+		Reason string `json:"reason"`
+	}
+	if json.Unmarshal(body.Error, &cause) == nil {
+		apiErr.Type, apiErr.Reason = cause.Type, cause.Reason
+	} else {
+		// Some errors are a plain string
+		_ = json.Unmarshal(body.Error, &apiErr.Reason)
+	}
+	return apiErr
+}
+
+// readOperations are POST requests that change nothing and may be resent
+var readOperations = map[string]bool{"search": true, "refresh": true}
+
+// idempotent reports whether the request may be resent after a node
+// received it, POST index and bulk would create duplicate documents
+func idempotent(operation, method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete:
+		return true
+	case http.MethodPost:
+		return readOperations[operation]
+	default:
+		return false
+	}
+}
+
+// connectError reports errors where the request never reached a node
+func connectError(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// retryable reports connection errors and overloaded or unavailable nodes
+func retryable(err error) bool {
+	var apiErr *Error
+	if !errors.As(err, &apiErr) {
+		return true
+	}
+	switch apiErr.Status {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,141 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func newTestClient(t *testing.T, handler http.HandlerFunc, extraAddrs ...string) (*Client, *atomic.Int32) {
+	t.Helper()
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		handler(w, r)
+	}))
+	t.Cleanup(srv.Close)
+
+	cfg := DefaultConfig()
+	cfg.Addresses = append(extraAddrs, srv.URL)
+	cfg.MaxRetries = 2
+	client, err := New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.policy.Backoff = nil
+	return client, &calls
+}
+
+func unavailable(w http.ResponseWriter, _ *http.Request) {
+	w.WriteHeader(http.StatusServiceUnavailable)
+}
+
+// closedAddr returns an address nothing listens on
+func closedAddr(t *testing.T) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+	return "http://" + addr
+}
+
+func TestRetriesIdempotentRequests(t *testing.T) {
+	client, calls := newTestClient(t, unavailable)
+
+	var apiErr *Error
+	if err := client.Delete(context.Background(), "docs", "1"); !errors.As(err, &apiErr) || apiErr.Status != http.StatusServiceUnavailable {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if calls.Load() != 3 {
+		t.Errorf("calls = %d, want 3", calls.Load())
+	}
+}
+
+func TestDoesNotResendIndexWithoutID(t *testing.T) {
+	client, calls := newTestClient(t, unavailable)
+
+	if _, err := client.Index(context.Background(), "docs", "", map[string]string{"a": "b"}); err == nil {
+		t.Fatal("expected error")
+	}
+	if calls.Load() != 1 {
+		t.Errorf("calls = %d, want 1", calls.Load())
+	}
+}
+
+func TestRetriesIndexWithoutIDOnConnectError(t *testing.T) {
+	client, calls := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("method = %s", r.Method)
+		}
+		_, _ = w.Write([]byte(`{"_id":"generated"}`))
+	}, closedAddr(t))
+
+	id, err := client.Index(context.Background(), "docs", "", map[string]string{"a": "b"})
+	if err != nil {
+		t.Fatalf("Index() error = %v", err)
+	}
+	if id != "generated" || calls.Load() != 1 {
+		t.Errorf("id = %q, calls = %d", id, calls.Load())
+	}
+}
+
+func TestDecodeErrorIsPermanent(t *testing.T) {
+	client, calls := newTestClient(t, func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"_id":`))
+	})
+
+	var result struct{}
+	if err := client.Do(context.Background(), "get", http.MethodGet, "/docs/_doc/1", nil, &result); err == nil {
+		t.Fatal("expected decode error")
+	}
+	if calls.Load() != 1 {
+		t.Errorf("calls = %d, want 1", calls.Load())
+	}
+}
+
+func TestBulkResendsRejectedRequest(t *testing.T) {
+	var requests atomic.Int32
+	client, calls := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			// The circuit breaker rejects the whole request
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write([]byte(`{"error":{"type":"circuit_breaking_exception","reason":"data too large"}}`))
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		items := strings.Repeat(`{"index":{"status":201}},`, strings.Count(string(body), "\n")/2)
+		_, _ = w.Write([]byte(`{"errors":false,"items":[` + strings.TrimSuffix(items, ",") + `]}`))
+	})
+
+	bulk := client.NewBulkIndexer(BulkConfig{
+		Index:   "docs",
+		Workers: 1,
+		OnError: func(ctx context.Context, item BulkItem, err error) {
+			t.Errorf("OnError(%s) = %v", item.ID, err)
+		},
+	})
+	for _, id := range []string{"1", "2"} {
+		if err := bulk.Add(context.Background(), BulkItem{ID: id, Document: map[string]string{"a": "b"}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := bulk.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if calls.Load() < 2 {
+		t.Errorf("calls = %d, want the rejected request resent", calls.Load())
+	}
+	if stats := bulk.Stats(); stats.Succeeded != 2 || stats.Failed != 0 {
+		t.Errorf("stats = %+v, want both items indexed", stats)
+	}
+}
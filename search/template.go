@@ -0,0 +1,141 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// IndexTemplate is a composable index template, applied to indices created
+// with a matching name. Names, patterns and aliases get the index prefix
+type IndexTemplate struct {
+	Name          string
+	IndexPatterns []string
+	// Priority picks the template when several match
+	Priority int
+	// Version is compared by EnsureIndexTemplates to skip unchanged templates
+	Version  int
+	Settings map[string]interface{}
+	Mappings map[string]interface{}
+	Aliases  map[string]interface{}
+}
+
+// PutIndexTemplate creates or replaces a template
+func (c *Client) PutIndexTemplate(ctx context.Context, tmpl IndexTemplate) error {
+	patterns := make([]string, len(tmpl.IndexPatterns))
+	for i, pattern := range tmpl.IndexPatterns {
+		patterns[i] = c.IndexName(pattern)
+	}
+	template := map[string]interface{}{}
+	if tmpl.Settings != nil {
+		template["settings"] = tmpl.Settings
+	}
+	if tmpl.Mappings != nil {
+		template["mappings"] = tmpl.Mappings
+	}
+	if tmpl.Aliases != nil {
+		aliases := make(map[string]interface{}, len(tmpl.Aliases))
+		for alias, body := range tmpl.Aliases {
+			aliases[c.IndexName(alias)] = body
+		}
+		template["aliases"] = aliases
+	}
+
+	body := map[string]interface{}{
+		"index_patterns": patterns,
+		"priority":       tmpl.Priority,
+		"version":        tmpl.Version,
+		"template":       template,
+	}
+	if err := c.Do(ctx, "put_index_template", http.MethodPut, templatePath(c.IndexName(tmpl.Name)), body, nil); err != nil {
+		return fmt.Errorf("failed to put index template %s: %w", tmpl.Name, err)
+	}
+	return nil
+}
+
+// IndexTemplateVersion returns the version of a stored template,
+// ErrNotFound if there is none
+func (c *Client) IndexTemplateVersion(ctx context.Context, name string) (int, error) {
+	var result struct {
+		IndexTemplates []struct {
+			IndexTemplate struct {
+				Version int `json:"version"`
+			} `json:"index_template"`
+		} `json:"index_templates"`
+	}
+	if err := c.Do(ctx, "get_index_template", http.MethodGet, templatePath(c.IndexName(name)), nil, &result); err != nil {
+		return 0, err
+	}
+	if len(result.IndexTemplates) == 0 {
+		return 0, ErrNotFound
+	}
+	return result.IndexTemplates[0].IndexTemplate.Version, nil
+}
+
+// EnsureIndexTemplates puts templates that are missing or older than
+// Version, so every instance can call it on startup
+func (c *Client) EnsureIndexTemplates(ctx context.Context, templates ...IndexTemplate) error {
+	for _, tmpl := range templates {
+		version, err := c.IndexTemplateVersion(ctx, tmpl.Name)
+		switch {
+		case errors.Is(err, ErrNotFound):
+		case err != nil:
+			return fmt.Errorf("failed to get index template %s: %w", tmpl.Name, err)
+		case version >= tmpl.Version:
+			continue
+		}
+		if err := c.PutIndexTemplate(ctx, tmpl); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteIndexTemplate removes a template, deleting a missing template is not
+// an error
+func (c *Client) DeleteIndexTemplate(ctx context.Context, name string) error {
+	err := c.Do(ctx, "delete_index_template", http.MethodDelete, templatePath(c.IndexName(name)), nil, nil)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return fmt.Errorf("failed to delete index template %s: %w", name, err)
+	}
+	return nil
+}
+
+// CreateIndex creates an index, body holds settings, mappings and aliases
+// not covered by templates and may be nil. An existing index is not an error
+func (c *Client) CreateIndex(ctx context.Context, name string, body interface{}) error {
+	err := c.Do(ctx, "create_index", http.MethodPut, "/"+url.PathEscape(c.IndexName(name)), body, nil)
+
+	var apiErr *Error
+	if errors.As(err, &apiErr) && apiErr.Type == "resource_already_exists_exception" {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create index %s: %w", name, err)
+	}
+	return nil
+}
+
+// IndexExists reports whether an index or alias exists
+func (c *Client) IndexExists(ctx context.Context, name string) (bool, error) {
+	err := c.Do(ctx, "index_exists", http.MethodHead, "/"+url.PathEscape(c.IndexName(name)), nil, nil)
+	if errors.Is(err, ErrNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// DeleteIndex removes an index, deleting a missing index is not an error
+func (c *Client) DeleteIndex(ctx context.Context, name string) error {
+	err := c.Do(ctx, "delete_index", http.MethodDelete, "/"+url.PathEscape(c.IndexName(name)), nil, nil)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return fmt.Errorf("failed to delete index %s: %w", name, err)
+	}
+	return nil
+}
+
+func templatePath(name string) string {
+	return "/_index_template/" + url.PathEscape(name)
+}
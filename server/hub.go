@@ -0,0 +1,110 @@
+package server
+
+import (
+	"errors"
+	"sync"
+)
+
+// ErrHubClosed is returned by Subscribe after the hub is closed
+var ErrHubClosed = errors.New("hub is closed")
+
+// Hub broadcasts messages to subscribed stream clients (WebSocket, SSE)
+type Hub struct {
+	mu      sync.RWMutex
+	clients map[*HubClient]struct{}
+	buffer  int
+	closed  bool
+}
+
+// HubClient is a hub subscription
+type HubClient struct {
+	send chan []byte
+	once sync.Once
+}
+
+// NewHub creates a hub, buffer is the per-client queue size.
+// Use Server.NewHub to close it automatically on shutdown
+func NewHub(buffer int) *Hub {
+	if buffer <= 0 {
+		buffer = 16
+	}
+	return &Hub{
+		clients: make(map[*HubClient]struct{}),
+		buffer:  buffer,
+	}
+}
+
+// Subscribe registers a new client
+func (h *Hub) Subscribe() (*HubClient, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		return nil, ErrHubClosed
+	}
+	client := &HubClient{send: make(chan []byte, h.buffer)}
+	h.clients[client] = struct{}{}
+	return client, nil
+}
+
+// Unsubscribe removes the client and closes its channel
+func (h *Hub) Unsubscribe(client *HubClient) {
+	h.mu.Lock()
+	delete(h.clients, client)
+	h.mu.Unlock()
+	client.close()
+}
+
+// Broadcast sends the message to all clients without blocking and returns
+// the number of receivers. Clients with a full queue are disconnected
+func (h *Hub) Broadcast(msg []byte) int {
+	h.mu.RLock()
+	var slow []*HubClient
+	sent := 0
+	for client := range h.clients {
+		select {
+		case client.send <- msg:
+			sent++
+		default:
+			slow = append(slow, client)
+		}
+	}
+	h.mu.RUnlock()
+
+	for _, client := range slow {
+		h.Unsubscribe(client)
+	}
+	return sent
+}
+
+// Len returns the number of connected clients
+func (h *Hub) Len() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.clients)
+}
+
+// Close disconnects all clients and rejects new subscriptions
+func (h *Hub) Close() {
+	h.mu.Lock()
+	clients := h.clients
+	h.clients = make(map[*HubClient]struct{})
+	h.closed = true
+	h.mu.Unlock()
+
+	for client := range clients {
+		client.close()
+	}
+}
+
+// Messages returns the client queue, it is closed when the client is
+// unsubscribed, dropped as slow or the hub is closed
+func (c *HubClient) Messages() <-chan []byte {
+	return c.send
+}
+
+func (c *HubClient) close() {
+	c.once.Do(func() {
+		close(c.send)
+	})
+}
@@ -0,0 +1,94 @@
+package server
+
+import (
+	"errors"
+	"sync"
+	"testing"
+)
+
+func TestHubBroadcast(t *testing.T) {
+	hub := NewHub(2)
+	a, _ := hub.Subscribe()
+	b, _ := hub.Subscribe()
+
+	if sent := hub.Broadcast([]byte("hello")); sent != 2 {
+		t.Errorf("sent = %d, want 2", sent)
+	}
+	for _, client := range []*HubClient{a, b} {
+		if msg := <-client.Messages(); string(msg) != "hello" {
+			t.Errorf("message = %q, want hello", msg)
+		}
+	}
+}
+
+func TestHubDropsSlowClient(t *testing.T) {
+	hub := NewHub(1)
+	slow, _ := hub.Subscribe()
+	fast, _ := hub.Subscribe()
+
+	hub.Broadcast([]byte("1"))
+	<-fast.Messages()
+	if sent := hub.Broadcast([]byte("2")); sent != 1 {
+		t.Errorf("sent = %d, want only the client with room in its queue", sent)
+	}
+
+	if hub.Len() != 1 {
+		t.Errorf("Len() = %d, want the slow client dropped", hub.Len())
+	}
+	// The queued message is still delivered, then the channel is closed
+	if msg := <-slow.Messages(); string(msg) != "1" {
+		t.Errorf("message = %q, want 1", msg)
+	}
+	if _, ok := <-slow.Messages(); ok {
+		t.Error("slow client channel is open")
+	}
+	if msg := <-fast.Messages(); string(msg) != "2" {
+		t.Errorf("message = %q, want 2", msg)
+	}
+}
+
+func TestHubClose(t *testing.T) {
+	hub := NewHub(1)
+	client, _ := hub.Subscribe()
+
+	hub.Close()
+	if _, ok := <-client.Messages(); ok {
+		t.Error("client channel is open after Close")
+	}
+	if _, err := hub.Subscribe(); !errors.Is(err, ErrHubClosed) {
+		t.Errorf("Subscribe() error = %v, want ErrHubClosed", err)
+	}
+	if sent := hub.Broadcast([]byte("late")); sent != 0 {
+		t.Errorf("sent = %d after Close", sent)
+	}
+
+	// A handler unsubscribing after shutdown and a second Close are safe
+	hub.Unsubscribe(client)
+	hub.Close()
+}
+
+func TestHubConcurrentUse(t *testing.T) {
+	hub := NewHub(4)
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			client, err := hub.Subscribe()
+			if err != nil {
+				return
+			}
+			for range client.Messages() {
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for range 100 {
+				hub.Broadcast([]byte("tick"))
+			}
+		}()
+	}
+
+	hub.Close()
+	wg.Wait()
+}
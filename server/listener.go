@@ -0,0 +1,87 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// listenFDsStart is the first descriptor passed by systemd (SD_LISTEN_FDS_START)
+const listenFDsStart = 3
+
+// listen opens the server socket: the one passed by systemd when socket
+// activation is enabled, otherwise Host:Port on fiber.Config.Network with
+// optional SO_REUSEPORT
+func (s *Server) listen() (net.Listener, error) {
+	if s.config.SocketActivation {
+		ln, err := activationListener(s.config.SocketName)
+		if err != nil {
+			return nil, fmt.Errorf("socket activation: %w", err)
+		}
+		if ln != nil {
+			return ln, nil
+		}
+	}
+
+	addr := fmt.Sprintf("%s:%d", s.config.Host, s.config.Port)
+	var lc net.ListenConfig
+	if s.config.ReusePort {
+		lc.Control = reusePortControl
+	}
+
+	// fiber.New defaults Network to tcp4
+	network := s.app.Config().Network
+	if network == "" {
+		network = fiber.NetworkTCP4
+	}
+	ln, err := lc.Listen(context.Background(), network, addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+	return ln, nil
+}
+
+// activationListener returns the socket passed by systemd with
+// FileDescriptorName name, or the first one when name is empty. Returns nil
+// when the process was not started by socket activation. The LISTEN_*
+// variables are unset, so child processes do not take the sockets as theirs
+func activationListener(name string) (net.Listener, error) {
+	if pid, err := strconv.Atoi(os.Getenv("LISTEN_PID")); err != nil || pid != os.Getpid() {
+		return nil, nil
+	}
+	defer func() {
+		for _, key := range []string{"LISTEN_PID", "LISTEN_FDS", "LISTEN_FDNAMES"} {
+			_ = os.Unsetenv(key)
+		}
+	}()
+	count, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil || count <= 0 {
+		return nil, nil
+	}
+
+	names := strings.Split(os.Getenv("LISTEN_FDNAMES"), ":")
+	for i := 0; i < count; i++ {
+		fdName := ""
+		if i < len(names) {
+			fdName = names[i]
+		}
+		if name != "" && fdName != name {
+			continue
+		}
+
+		f := os.NewFile(uintptr(listenFDsStart+i), fdName)
+		ln, err := net.FileListener(f)
+		// FileListener dups the descriptor
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("descriptor %d is not a listening socket: %w", listenFDsStart+i, err)
+		}
+		return ln, nil
+	}
+	return nil, fmt.Errorf("no socket named %q in LISTEN_FDNAMES", name)
+}
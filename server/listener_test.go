@@ -0,0 +1,137 @@
+package server
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/alimzhanovlr/sdk/config"
+	"github.com/alimzhanovlr/sdk/logger"
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+// TestActivationHelper runs in a child process started by
+// TestSocketActivation, which passes sockets as descriptors 3 and up like
+// systemd does. LISTEN_PID is only known in the child, so it is set here
+func TestActivationHelper(t *testing.T) {
+	name, ok := os.LookupEnv("SERVER_ACTIVATION_HELPER")
+	if !ok {
+		t.Skip("helper process")
+	}
+	os.Setenv("LISTEN_PID", strconv.Itoa(os.Getpid()))
+
+	srv := New(Params{
+		Config: &config.Config{Server: config.ServerConfig{SocketActivation: true, SocketName: name}},
+		Logger: &logger.Logger{Logger: zap.NewNop()},
+	})
+	ln, err := srv.listen()
+	if err != nil {
+		fmt.Printf("error=%v\n", err)
+		return
+	}
+	defer ln.Close()
+	fmt.Printf("addr=%s\n", ln.Addr())
+	fmt.Printf("env=%s%s%s\n", os.Getenv("LISTEN_PID"), os.Getenv("LISTEN_FDS"), os.Getenv("LISTEN_FDNAMES"))
+}
+
+func TestSocketActivation(t *testing.T) {
+	var (
+		files []*os.File
+		addrs []string
+	)
+	for i := 0; i < 2; i++ {
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		f, err := ln.(*net.TCPListener).File()
+		ln.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { f.Close() })
+		files = append(files, f)
+		addrs = append(addrs, ln.Addr().String())
+	}
+
+	run := func(name string) string {
+		t.Helper()
+		cmd := exec.Command(os.Args[0], "-test.run=^TestActivationHelper$")
+		cmd.Env = append(os.Environ(),
+			"SERVER_ACTIVATION_HELPER="+name,
+			"LISTEN_FDS=2",
+			"LISTEN_FDNAMES=http:admin",
+		)
+		cmd.ExtraFiles = files
+		out, err := cmd.Output()
+		if err != nil {
+			t.Fatalf("helper failed: %v\n%s", err, out)
+		}
+		return string(out)
+	}
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"", "addr=" + addrs[0]},
+		{"http", "addr=" + addrs[0]},
+		{"admin", "addr=" + addrs[1]},
+		{"grpc", `error=socket activation: no socket named "grpc" in LISTEN_FDNAMES`},
+	}
+	for _, tt := range tests {
+		t.Run("name="+tt.name, func(t *testing.T) {
+			out := run(tt.name)
+			if !strings.Contains(out, tt.want+"\n") {
+				t.Errorf("helper output:\n%s\nwant %s", out, tt.want)
+			}
+			if strings.HasPrefix(tt.want, "addr=") && !strings.Contains(out, "env=\n") {
+				t.Errorf("LISTEN_* variables are not unset:\n%s", out)
+			}
+		})
+	}
+}
+
+func TestActivationListenerIgnoresOtherProcess(t *testing.T) {
+	t.Setenv("LISTEN_PID", strconv.Itoa(os.Getpid()+1))
+	t.Setenv("LISTEN_FDS", "1")
+
+	ln, err := activationListener("")
+	if ln != nil || err != nil {
+		t.Errorf("activationListener() = %v, %v, want nil for sockets of another process", ln, err)
+	}
+	if os.Getenv("LISTEN_FDS") != "1" {
+		t.Error("variables of another process were unset")
+	}
+}
+
+func TestListenUsesFiberNetwork(t *testing.T) {
+	srv := &Server{
+		app:    fiber.New(),
+		config: config.ServerConfig{Host: "", Port: 0},
+	}
+	ln, err := srv.listen()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	// tcp4 binds the IPv4 wildcard, tcp would bind [::]
+	if host, _, _ := net.SplitHostPort(ln.Addr().String()); host != "0.0.0.0" {
+		t.Errorf("address = %s, want the tcp4 wildcard", ln.Addr())
+	}
+
+	srv.app = fiber.New(fiber.Config{Network: fiber.NetworkTCP6})
+	ln6, err := srv.listen()
+	if err != nil {
+		t.Skipf("IPv6 is not available: %v", err)
+	}
+	defer ln6.Close()
+	if host, _, _ := net.SplitHostPort(ln6.Addr().String()); host != "::" {
+		t.Errorf("address = %s, want the tcp6 wildcard", ln6.Addr())
+	}
+}
@@ -1,31 +1,36 @@
 package server
 
 import (
+	"encoding/xml"
+
 	"github.com/alimzhanovlr/sdk/errors"
 	"github.com/gofiber/fiber/v2"
 )
 
 // Response represents a standard API response
 type Response struct {
-	Success bool        `json:"success"`
-	Data    interface{} `json:"data,omitempty"`
-	Error   *ErrorInfo  `json:"error,omitempty"`
-	Meta    *Meta       `json:"meta,omitempty"`
+	XMLName xml.Name    `json:"-" xml:"response"`
+	Success bool        `json:"success" xml:"success"`
+	Data    interface{} `json:"data,omitempty" xml:"data,omitempty"`
+	Error   *ErrorInfo  `json:"error,omitempty" xml:"error,omitempty"`
+	Meta    *Meta       `json:"meta,omitempty" xml:"meta,omitempty"`
+	TraceID string      `json:"trace_id,omitempty" xml:"trace_id,omitempty"`
 }
 
 // ErrorInfo represents error information
 type ErrorInfo struct {
-	Code    string                 `json:"code"`
-	Message string                 `json:"message"`
-	Details map[string]interface{} `json:"details,omitempty"`
+	Code    string `json:"code" xml:"code"`
+	Message string `json:"message" xml:"message"`
+	// Details are skipped by encoding/xml, respond encodes them for XML clients
+	Details map[string]interface{} `json:"details,omitempty" xml:"-"`
 }
 
 // Meta represents response metadata
 type Meta struct {
-	Page       int `json:"page,omitempty"`
-	PerPage    int `json:"per_page,omitempty"`
-	Total      int `json:"total,omitempty"`
-	TotalPages int `json:"total_pages,omitempty"`
+	Page       int `json:"page,omitempty" xml:"page,omitempty"`
+	PerPage    int `json:"per_page,omitempty" xml:"per_page,omitempty"`
+	Total      int `json:"total,omitempty" xml:"total,omitempty"`
+	TotalPages int `json:"total_pages,omitempty" xml:"total_pages,omitempty"`
 }
 
 // SendSuccess sends a success response
@@ -61,6 +66,7 @@ func SendNoContent(c *fiber.Ctx) error {
 // SendError sends an error response
 func SendError(c *fiber.Ctx, err error) error {
 	appErr := errors.GetAppError(err)
+	errors.Track(appErr)
 
 	return c.Status(appErr.StatusCode).JSON(Response{
 		Success: false,
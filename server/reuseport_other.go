@@ -0,0 +1,13 @@
+//go:build !unix || solaris
+
+package server
+
+import (
+	"errors"
+	"syscall"
+)
+
+// reusePortControl is not supported on this platform
+func reusePortControl(network, address string, c syscall.RawConn) error {
+	return errors.New("SO_REUSEPORT is not supported on this platform")
+}
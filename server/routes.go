@@ -0,0 +1,130 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RouteRegistry serves route groups registered and removed at runtime under a
+// prefix. Fiber's router is static after Listen, so every group is built into
+// its own app and requests are dispatched to it; requests in flight finish on
+// the group version they started with
+type RouteRegistry struct {
+	prefix string
+	config fiber.Config
+
+	mu     sync.RWMutex
+	groups map[string]*routeGroup
+}
+
+// userContextKey carries c.UserContext() into the group app, Locals live on
+// the shared fasthttp context while the user context does not
+type userContextKey struct{}
+
+type routeGroup struct {
+	path  string
+	serve func(c *fiber.Ctx)
+}
+
+// NewRouteRegistry mounts a registry at prefix. Call it after global
+// middleware is added, so it runs for dynamic routes as well
+func (s *Server) NewRouteRegistry(prefix string) *RouteRegistry {
+	r := &RouteRegistry{
+		prefix: strings.TrimSuffix(prefix, "/"),
+		config: s.app.Config(),
+		groups: make(map[string]*routeGroup),
+	}
+	r.config.DisableStartupMessage = true
+
+	s.app.Use(r.prefix, r.handle)
+	return r
+}
+
+// Register builds the group at path relative to the registry prefix and
+// atomically replaces a group registered under the same name
+func (r *RouteRegistry) Register(name, path string, register func(router fiber.Router)) error {
+	if name == "" {
+		return fmt.Errorf("route group name is required")
+	}
+	path = r.prefix + "/" + strings.Trim(path, "/")
+
+	app := fiber.New(r.config)
+	app.Use(func(c *fiber.Ctx) error {
+		if ctx, ok := c.Locals(userContextKey{}).(context.Context); ok {
+			c.SetUserContext(ctx)
+		}
+		return c.Next()
+	})
+	register(app.Group(path))
+	handler := app.Handler()
+
+	group := &routeGroup{
+		path: strings.TrimSuffix(path, "/"),
+		serve: func(c *fiber.Ctx) {
+			c.Locals(userContextKey{}, c.UserContext())
+			handler(c.Context())
+		},
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for other, g := range r.groups {
+		if other != name && g.path == group.path {
+			return fmt.Errorf("route group %s already serves %s", other, path)
+		}
+	}
+	r.groups[name] = group
+	return nil
+}
+
+// Unregister removes the group, returns false if it is not registered
+func (r *RouteRegistry) Unregister(name string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.groups[name]; !ok {
+		return false
+	}
+	delete(r.groups, name)
+	return true
+}
+
+// Names returns registered group names
+func (r *RouteRegistry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.groups))
+	for name := range r.groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// handle dispatches to the group with the longest matching path, unmatched
+// requests continue to static routes
+func (r *RouteRegistry) handle(c *fiber.Ctx) error {
+	path := c.Path()
+
+	r.mu.RLock()
+	var match *routeGroup
+	for _, g := range r.groups {
+		if (path == g.path || strings.HasPrefix(path, g.path+"/")) && (match == nil || len(g.path) > len(match.path)) {
+			match = g
+		}
+	}
+	r.mu.RUnlock()
+
+	if match == nil {
+		return c.Next()
+	}
+	match.serve(c)
+	return nil
+}
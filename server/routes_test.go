@@ -0,0 +1,136 @@
+package server
+
+import (
+	"context"
+	"io"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type ctxKey struct{}
+
+func body(t *testing.T, app *fiber.App, path string) (int, string) {
+	t.Helper()
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, path, nil))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	b, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(b)
+}
+
+func TestRouteRegistryRegisterReplaceUnregister(t *testing.T) {
+	srv := newTestServer("")
+	routes := srv.NewRouteRegistry("/plugins")
+	srv.App().Get("/plugins/static", func(c *fiber.Ctx) error { return c.SendString("static") })
+
+	version := func(v string) func(fiber.Router) {
+		return func(r fiber.Router) {
+			r.Get("/hello", func(c *fiber.Ctx) error { return c.SendString(v) })
+		}
+	}
+	if err := routes.Register("billing", "billing", version("v1")); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	if _, got := body(t, srv.App(), "/plugins/billing/hello"); got != "v1" {
+		t.Errorf("body = %q, want v1", got)
+	}
+
+	if err := routes.Register("billing", "/billing/", version("v2")); err != nil {
+		t.Fatalf("Register() replace error = %v", err)
+	}
+	if _, got := body(t, srv.App(), "/plugins/billing/hello"); got != "v2" {
+		t.Errorf("body = %q, want v2 after replace", got)
+	}
+	if err := routes.Register("other", "billing", version("v3")); err == nil {
+		t.Error("Register() of a taken path error = nil")
+	}
+
+	if _, got := body(t, srv.App(), "/plugins/static"); got != "static" {
+		t.Errorf("unmatched path body = %q, want static route", got)
+	}
+
+	if !routes.Unregister("billing") || routes.Unregister("billing") {
+		t.Error("Unregister() must report removal once")
+	}
+	if code, _ := body(t, srv.App(), "/plugins/billing/hello"); code != fiber.StatusNotFound {
+		t.Errorf("status after Unregister = %d, want 404", code)
+	}
+}
+
+func TestRouteRegistryLongestPrefixWins(t *testing.T) {
+	srv := newTestServer("")
+	routes := srv.NewRouteRegistry("/plugins")
+
+	_ = routes.Register("tenant", "t", func(r fiber.Router) {
+		r.Get("/*", func(c *fiber.Ctx) error { return c.SendString("tenant") })
+	})
+	_ = routes.Register("acme", "t/acme", func(r fiber.Router) {
+		r.Get("/*", func(c *fiber.Ctx) error { return c.SendString("acme") })
+	})
+
+	if _, got := body(t, srv.App(), "/plugins/t/acme/orders"); got != "acme" {
+		t.Errorf("body = %q, want acme", got)
+	}
+	if _, got := body(t, srv.App(), "/plugins/t/acmex"); got != "tenant" {
+		t.Errorf("body = %q, want tenant", got)
+	}
+	if got := routes.Names(); len(got) != 2 || got[0] != "acme" || got[1] != "tenant" {
+		t.Errorf("Names() = %v", got)
+	}
+}
+
+func TestRouteRegistryPropagatesUserContextAndLocals(t *testing.T) {
+	srv := newTestServer("")
+	srv.App().Use(func(c *fiber.Ctx) error {
+		c.SetUserContext(context.WithValue(c.UserContext(), ctxKey{}, "trace-1"))
+		c.Locals("tenant", "acme")
+		return c.Next()
+	})
+	routes := srv.NewRouteRegistry("/plugins")
+
+	_ = routes.Register("ctx", "ctx", func(r fiber.Router) {
+		r.Get("/", func(c *fiber.Ctx) error {
+			value, _ := c.UserContext().Value(ctxKey{}).(string)
+			tenant, _ := c.Locals("tenant").(string)
+			return c.SendString(value + " " + tenant)
+		})
+	})
+
+	if _, got := body(t, srv.App(), "/plugins/ctx"); got != "trace-1 acme" {
+		t.Errorf("body = %q, want user context and locals of the outer request", got)
+	}
+}
+
+func TestRouteRegistryConcurrentSwaps(t *testing.T) {
+	srv := newTestServer("")
+	routes := srv.NewRouteRegistry("/plugins")
+	register := func(r fiber.Router) {
+		r.Get("/ping", func(c *fiber.Ctx) error { return c.SendString("pong") })
+	}
+	_ = routes.Register("ping", "ping", register)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				_ = routes.Register("ping", "ping", register)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				if code, _ := body(t, srv.App(), "/plugins/ping/ping"); code != fiber.StatusOK {
+					t.Errorf("status = %d during swaps", code)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -2,23 +2,38 @@ package server
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net"
+	"sync"
 	"time"
 
+	"github.com/alimzhanovlr/sdk/buildinfo"
 	"github.com/alimzhanovlr/sdk/config"
+	apperrors "github.com/alimzhanovlr/sdk/errors"
+	"github.com/alimzhanovlr/sdk/featureflag"
+	"github.com/alimzhanovlr/sdk/health"
 	"github.com/alimzhanovlr/sdk/logger"
+	"github.com/alimzhanovlr/sdk/metrics"
 	"github.com/alimzhanovlr/sdk/tracing"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/recover"
 	"go.uber.org/fx"
+	"go.uber.org/zap"
 )
 
 // Server wraps Fiber app
 type Server struct {
-	app    *fiber.App
+	app *fiber.App
+	// admin serves /debug/* on ServerConfig.AdminAddr, nil when disabled
+	admin  *fiber.App
 	config config.ServerConfig
 	logger *logger.Logger
 	tracer *tracing.Tracer
+
+	mu    sync.Mutex
+	hubs  []*Hub
+	hooks []shutdownHook
 }
 
 // Params for server constructor
@@ -28,6 +43,15 @@ type Params struct {
 	Config *config.Config
 	Logger *logger.Logger
 	Tracer *tracing.Tracer
+	// Metrics exposes scrape endpoint when prometheus exporter is used
+	Metrics *metrics.Registry `optional:"true"`
+	// Health serves /readyz from registered checks
+	Health *health.Registry `optional:"true"`
+	// ErrorStats serves /debug/errors on the admin listener when error
+	// stats are enabled
+	ErrorStats *apperrors.Stats `optional:"true"`
+	// FeatureFlags serves /debug/flags on the admin listener when provided
+	FeatureFlags *featureflag.Engine `optional:"true"`
 }
 
 // New creates a new server
@@ -35,6 +59,7 @@ func New(p Params) *Server {
 	app := fiber.New(fiber.Config{
 		ReadTimeout:  time.Duration(p.Config.Server.ReadTimeout) * time.Second,
 		WriteTimeout: time.Duration(p.Config.Server.WriteTimeout) * time.Second,
+		BodyLimit:    p.Config.Server.BodyLimit,
 		ErrorHandler: errorHandler(p.Logger),
 	})
 
@@ -43,8 +68,44 @@ func New(p Params) *Server {
 		EnableStackTrace: true,
 	}))
 
+	// Expose metrics endpoint
+	if p.Metrics != nil && p.Metrics.Path() != "" {
+		app.Get(p.Metrics.Path(), p.Metrics.Handler())
+	}
+
+	// Expose build metadata to tell which build serves traffic
+	app.Get("/version", buildinfo.Handler())
+
+	// Debug endpoints live on the admin listener only
+	var admin *fiber.App
+	if p.Config.Server.AdminAddr != "" {
+		admin = newAdminApp(p.Logger)
+
+		// Expose config fingerprint to compare replicas after a rollout
+		admin.Get("/debug/config", configHandler)
+
+		// Expose error occurrences by code
+		if p.ErrorStats != nil {
+			admin.Get("/debug/errors", errorStatsHandler(p.ErrorStats))
+		}
+
+		// Expose feature flag definitions and evaluations
+		if p.FeatureFlags != nil {
+			admin.Get("/debug/flags", p.FeatureFlags.Handler())
+		}
+	}
+
+	// Expose probes, liveness does not depend on downstreams
+	if p.Health != nil {
+		app.Get("/livez", func(c *fiber.Ctx) error {
+			return c.JSON(fiber.Map{"status": health.StatusUp})
+		})
+		app.Get("/readyz", p.Health.Handler())
+	}
+
 	return &Server{
 		app:    app,
+		admin:  admin,
 		config: p.Config.Server,
 		logger: p.Logger,
 		tracer: p.Tracer,
@@ -56,35 +117,115 @@ func (s *Server) App() *fiber.App {
 	return s.app
 }
 
+// Admin returns the app served on ServerConfig.AdminAddr for debug and
+// operational endpoints, nil when the admin listener is disabled
+func (s *Server) Admin() *fiber.App {
+	return s.admin
+}
+
+// newAdminApp creates the app for the admin listener
+func newAdminApp(log *logger.Logger) *fiber.App {
+	admin := fiber.New(fiber.Config{
+		DisableStartupMessage: true,
+		ErrorHandler:          errorHandler(log),
+	})
+	admin.Use(recover.New())
+	return admin
+}
+
 // Start starts the server
 func (s *Server) Start(lc fx.Lifecycle) {
 	lc.Append(fx.Hook{
 		OnStart: func(ctx context.Context) error {
-			addr := fmt.Sprintf("%s:%d", s.config.Host, s.config.Port)
+			// Bind before returning, so a busy port fails the start
+			ln, err := s.listen()
+			if err != nil {
+				return err
+			}
 			s.logger.Info("Starting server",
-				logger.String("address", addr),
+				logger.String("address", ln.Addr().String()),
+				zap.Bool("reuse_port", s.config.ReusePort),
+				zap.Bool("socket_activation", s.config.SocketActivation),
 			)
 
 			go func() {
-				if err := s.app.Listen(addr); err != nil {
+				if err := s.app.Listener(ln); err != nil {
 					s.logger.Error("Failed to start server", logger.Error(err))
 				}
 			}()
 
+			if s.admin != nil {
+				adminLn, err := net.Listen("tcp", s.config.AdminAddr)
+				if err != nil {
+					return errors.Join(fmt.Errorf("failed to listen on admin address %s: %w", s.config.AdminAddr, err), s.app.ShutdownWithContext(ctx))
+				}
+				s.logger.Info("Starting admin server", logger.String("address", adminLn.Addr().String()))
+
+				go func() {
+					if err := s.admin.Listener(adminLn); err != nil {
+						s.logger.Error("Failed to start admin server", logger.Error(err))
+					}
+				}()
+			}
+
 			return nil
 		},
 		OnStop: func(ctx context.Context) error {
 			s.logger.Info("Shutting down server")
-			return s.app.Shutdown()
+			s.mu.Lock()
+			for _, hub := range s.hubs {
+				hub.Close()
+			}
+			s.mu.Unlock()
+
+			// A hung request must not block the hooks past the stop deadline
+			err := s.app.ShutdownWithContext(ctx)
+			if s.admin != nil {
+				err = errors.Join(err, s.admin.ShutdownWithContext(ctx))
+			}
+			return errors.Join(err, s.runShutdownHooks(ctx))
 		},
 	})
 }
 
+// NewHub creates a hub closed on shutdown before the server waits for
+// open connections, so WebSocket and SSE streams do not block it
+func (s *Server) NewHub(buffer int) *Hub {
+	hub := NewHub(buffer)
+	s.mu.Lock()
+	s.hubs = append(s.hubs, hub)
+	s.mu.Unlock()
+	return hub
+}
+
 // RegisterRoutes registers route handler
 func (s *Server) RegisterRoutes(register func(*fiber.App)) {
 	register(s.app)
 }
 
+// configHandler returns fingerprint of the current config revision, never the values
+func configHandler(c *fiber.Ctx) error {
+	rev := config.Snapshot()
+	if rev == nil {
+		return fiber.ErrNotFound
+	}
+	return c.JSON(fiber.Map{
+		"fingerprint": rev.Fingerprint(),
+		"generation":  rev.Generation(),
+		"loaded_at":   rev.LoadedAt(),
+	})
+}
+
+// errorStatsHandler returns AppError codes, the most frequent first
+func errorStatsHandler(stats *apperrors.Stats) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		return c.JSON(fiber.Map{
+			"since": stats.StartedAt(),
+			"codes": stats.Snapshot(),
+		})
+	}
+}
+
 // errorHandler handles Fiber errors
 func errorHandler(log *logger.Logger) fiber.ErrorHandler {
 	return func(c *fiber.Ctx, err error) error {
@@ -94,6 +235,8 @@ func errorHandler(log *logger.Logger) fiber.ErrorHandler {
 		if e, ok := err.(*fiber.Error); ok {
 			code = e.Code
 			message = e.Message
+		} else {
+			apperrors.Track(err)
 		}
 
 		log.Error("Request error",
@@ -0,0 +1,50 @@
+package server
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alimzhanovlr/sdk/config"
+	"github.com/alimzhanovlr/sdk/logger"
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+func newTestServer(adminAddr string) *Server {
+	return New(Params{
+		Config: &config.Config{Server: config.ServerConfig{AdminAddr: adminAddr}},
+		Logger: &logger.Logger{Logger: zap.NewNop()},
+	})
+}
+
+func status(t *testing.T, app *fiber.App, path string) int {
+	t.Helper()
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, path, nil))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
+	return resp.StatusCode
+}
+
+func TestDebugEndpointsOnlyOnAdminApp(t *testing.T) {
+	config.Swap(&config.Config{}, map[string]interface{}{"app": "test"})
+
+	srv := newTestServer("127.0.0.1:0")
+	if code := status(t, srv.App(), "/debug/config"); code != fiber.StatusNotFound {
+		t.Errorf("public /debug/config status = %d, want 404", code)
+	}
+	if code := status(t, srv.Admin(), "/debug/config"); code != fiber.StatusOK {
+		t.Errorf("admin /debug/config status = %d, want 200", code)
+	}
+}
+
+func TestAdminAppDisabledByDefault(t *testing.T) {
+	srv := newTestServer("")
+	if srv.Admin() != nil {
+		t.Error("admin app must be disabled without admin_addr")
+	}
+	if code := status(t, srv.App(), "/debug/config"); code != fiber.StatusNotFound {
+		t.Errorf("public /debug/config status = %d, want 404", code)
+	}
+}
@@ -0,0 +1,81 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/alimzhanovlr/sdk/logger"
+	"go.uber.org/zap"
+)
+
+// shutdownHook is a named cleanup step run after the server stops
+type shutdownHook struct {
+	name    string
+	fn      func(ctx context.Context) error
+	timeout time.Duration
+}
+
+// OnShutdown registers a hook run after HTTP connections are drained. Hooks
+// run in reverse registration order, like defer, so a component registered
+// after its dependencies is stopped before them. timeout limits the hook, zero
+// means the remaining shutdown time; a hook that times out is abandoned
+func (s *Server) OnShutdown(name string, fn func(ctx context.Context) error, timeout time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.hooks = append(s.hooks, shutdownHook{name: name, fn: fn, timeout: timeout})
+}
+
+// runShutdownHooks runs all hooks even if some fail and joins their errors
+func (s *Server) runShutdownHooks(ctx context.Context) error {
+	s.mu.Lock()
+	hooks := make([]shutdownHook, len(s.hooks))
+	copy(hooks, s.hooks)
+	s.mu.Unlock()
+
+	var errs []error
+	for i := len(hooks) - 1; i >= 0; i-- {
+		if err := s.runShutdownHook(ctx, hooks[i]); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (s *Server) runShutdownHook(ctx context.Context, hook shutdownHook) error {
+	if hook.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, hook.timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		done <- hook.fn(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	if err != nil {
+		s.logger.Error("Shutdown hook failed",
+			logger.String("hook", hook.name),
+			zap.Duration("duration", time.Since(start)),
+			logger.Error(err),
+		)
+		return fmt.Errorf("shutdown hook %s: %w", hook.name, err)
+	}
+
+	s.logger.Info("Shutdown hook completed",
+		logger.String("hook", hook.name),
+		zap.Duration("duration", time.Since(start)),
+	)
+	return nil
+}
@@ -0,0 +1,99 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestShutdownHooksRunInReverseOrder(t *testing.T) {
+	srv := newTestServer("")
+	var order []string
+	for _, name := range []string{"db", "cache", "consumer"} {
+		srv.OnShutdown(name, func(context.Context) error {
+			order = append(order, name)
+			return nil
+		}, 0)
+	}
+
+	if err := srv.runShutdownHooks(context.Background()); err != nil {
+		t.Fatalf("runShutdownHooks() error = %v", err)
+	}
+	if len(order) != 3 || order[0] != "consumer" || order[1] != "cache" || order[2] != "db" {
+		t.Errorf("order = %v, want [consumer cache db]", order)
+	}
+}
+
+func TestShutdownHooksJoinErrors(t *testing.T) {
+	srv := newTestServer("")
+	errDB := errors.New("db close failed")
+	errCache := errors.New("cache close failed")
+	ran := false
+
+	srv.OnShutdown("db", func(context.Context) error { return errDB }, 0)
+	srv.OnShutdown("metrics", func(context.Context) error { ran = true; return nil }, 0)
+	srv.OnShutdown("cache", func(context.Context) error { return errCache }, 0)
+
+	err := srv.runShutdownHooks(context.Background())
+	if !errors.Is(err, errDB) || !errors.Is(err, errCache) {
+		t.Errorf("runShutdownHooks() error = %v, want both hook errors", err)
+	}
+	if !ran {
+		t.Error("hook after a failed one did not run")
+	}
+}
+
+func TestShutdownHookTimeout(t *testing.T) {
+	srv := newTestServer("")
+	deadlines := make(chan time.Time, 1)
+	srv.OnShutdown("flush", func(ctx context.Context) error {
+		deadline, _ := ctx.Deadline()
+		deadlines <- deadline
+		<-ctx.Done()
+		return ctx.Err()
+	}, 20*time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	start := time.Now()
+	err := srv.runShutdownHooks(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("runShutdownHooks() error = %v, want deadline exceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("hook ran for %s, want the 20ms hook timeout", elapsed)
+	}
+	if deadline := <-deadlines; deadline.IsZero() || deadline.After(start.Add(time.Second)) {
+		t.Errorf("hook deadline = %s, want about 20ms from start", deadline)
+	}
+}
+
+func TestShutdownHookIgnoringContextIsAbandoned(t *testing.T) {
+	srv := newTestServer("")
+	release := make(chan struct{})
+	defer close(release)
+
+	later := false
+	srv.OnShutdown("later", func(context.Context) error { later = true; return nil }, 0)
+	srv.OnShutdown("stuck", func(context.Context) error {
+		<-release
+		return nil
+	}, 20*time.Millisecond)
+
+	done := make(chan error, 1)
+	go func() { done <- srv.runShutdownHooks(context.Background()) }()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("runShutdownHooks() error = %v, want deadline exceeded", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("shutdown waits for a hook that ignores its context")
+	}
+	if !later {
+		t.Error("hook after the abandoned one did not run")
+	}
+}
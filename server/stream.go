@@ -0,0 +1,91 @@
+package server
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gofiber/contrib/websocket"
+	"github.com/gofiber/fiber/v2"
+)
+
+// SSEEvent is a Server-Sent Event
+type SSEEvent struct {
+	ID    string
+	Event string
+	Data  string
+	// Retry tells the browser the reconnection delay
+	Retry time.Duration
+}
+
+// SSEWriter writes events to an SSE stream
+type SSEWriter struct {
+	w *bufio.Writer
+}
+
+// Send writes the event and flushes it, an error means the client disconnected
+func (w *SSEWriter) Send(event SSEEvent) error {
+	if event.ID != "" {
+		fmt.Fprintf(w.w, "id: %s\n", event.ID)
+	}
+	if event.Event != "" {
+		fmt.Fprintf(w.w, "event: %s\n", event.Event)
+	}
+	if event.Retry > 0 {
+		fmt.Fprintf(w.w, "retry: %d\n", event.Retry.Milliseconds())
+	}
+	for _, line := range strings.Split(event.Data, "\n") {
+		fmt.Fprintf(w.w, "data: %s\n", line)
+	}
+	w.w.WriteString("\n")
+	return w.w.Flush()
+}
+
+// SendJSON writes v as JSON data of the event
+func (w *SSEWriter) SendJSON(event string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+	return w.Send(SSEEvent{Event: event, Data: string(data)})
+}
+
+// Ping writes a comment keeping proxies from closing an idle stream
+func (w *SSEWriter) Ping() error {
+	w.w.WriteString(": ping\n\n")
+	return w.w.Flush()
+}
+
+// SSE starts a Server-Sent Events stream. stream runs after the handler
+// returns, so it must not use c; read everything needed from c before
+func SSE(c *fiber.Ctx, stream func(w *SSEWriter)) error {
+	c.Set(fiber.HeaderContentType, "text/event-stream")
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	c.Set(fiber.HeaderConnection, "keep-alive")
+	// Disable nginx buffering
+	c.Set("X-Accel-Buffering", "no")
+
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		stream(&SSEWriter{w: w})
+	})
+	return nil
+}
+
+// LastEventID returns the id of the last event received by a reconnecting client
+func LastEventID(c *fiber.Ctx) string {
+	return c.Get("Last-Event-ID")
+}
+
+// WebSocket returns a handler upgrading the connection, plain HTTP requests
+// get 426 Upgrade Required. Locals set by earlier middleware are available via conn.Locals
+func WebSocket(handler func(conn *websocket.Conn), config ...websocket.Config) fiber.Handler {
+	upgrade := websocket.New(handler, config...)
+	return func(c *fiber.Ctx) error {
+		if !websocket.IsWebSocketUpgrade(c) {
+			return fiber.ErrUpgradeRequired
+		}
+		return upgrade(c)
+	}
+}
@@ -0,0 +1,62 @@
+package server
+
+import (
+	"io"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/contrib/websocket"
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestSSEFraming(t *testing.T) {
+	app := fiber.New()
+	app.Get("/events", func(c *fiber.Ctx) error {
+		return SSE(c, func(w *SSEWriter) {
+			_ = w.Send(SSEEvent{ID: "7", Event: "update", Data: "line 1\nline 2", Retry: 3 * time.Second})
+			_ = w.SendJSON("order", map[string]int{"id": 1})
+			_ = w.Ping()
+		})
+	})
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/events", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if ct := resp.Header.Get(fiber.HeaderContentType); ct != "text/event-stream" {
+		t.Errorf("content type = %q", ct)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "id: 7\nevent: update\nretry: 3000\ndata: line 1\ndata: line 2\n\n" +
+		"event: order\ndata: {\"id\":1}\n\n" +
+		": ping\n\n"
+	if string(body) != want {
+		t.Errorf("body = %q, want %q", body, want)
+	}
+}
+
+func TestWebSocketRequiresUpgrade(t *testing.T) {
+	app := fiber.New()
+	called := false
+	app.Get("/ws", WebSocket(func(conn *websocket.Conn) { called = true }))
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/ws", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != fiber.StatusUpgradeRequired {
+		t.Errorf("status = %d, want 426", resp.StatusCode)
+	}
+	if called {
+		t.Error("handler ran for a plain HTTP request")
+	}
+}
@@ -0,0 +1,252 @@
+// Package shutdown stops programs without fx gracefully, e.g. CLI tools and
+// workers: a root context is cancelled on SIGINT or SIGTERM, then registered
+// components are stopped in reverse order, each within its timeout
+//
+//	sd := shutdown.New(shutdown.DefaultConfig())
+//	sd.Register("db", func(ctx context.Context) error { return db.Close() })
+//	go consumer.Run(sd.Context())
+//	return sd.Wait()
+package shutdown
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/alimzhanovlr/sdk/logger"
+	"go.uber.org/zap"
+)
+
+// exit is replaced in tests
+var exit = os.Exit
+
+// Config holds shutdown configuration
+type Config struct {
+	// Signals start the shutdown, a second one exits immediately
+	Signals []os.Signal
+	// Timeout bounds stopping all components
+	Timeout time.Duration
+	// ComponentTimeout bounds one component unless it has its own
+	ComponentTimeout time.Duration
+	// Logger is optional, defaults to no logging
+	Logger *logger.Logger
+}
+
+// DefaultConfig returns default shutdown config
+func DefaultConfig() Config {
+	return Config{
+		Signals:          []os.Signal{os.Interrupt, syscall.SIGTERM},
+		Timeout:          30 * time.Second,
+		ComponentTimeout: 10 * time.Second,
+	}
+}
+
+// Failure is a component that failed to stop
+type Failure struct {
+	Component string
+	Err       error
+}
+
+// Error lists components that failed to stop
+type Error struct {
+	Failures []Failure
+}
+
+func (e *Error) Error() string {
+	parts := make([]string, len(e.Failures))
+	for i, f := range e.Failures {
+		parts[i] = f.Component + ": " + f.Err.Error()
+	}
+	return "failed to stop " + strings.Join(parts, "; ")
+}
+
+// Unwrap returns component errors
+func (e *Error) Unwrap() []error {
+	errs := make([]error, len(e.Failures))
+	for i, f := range e.Failures {
+		errs[i] = f.Err
+	}
+	return errs
+}
+
+type component struct {
+	name    string
+	fn      func(ctx context.Context) error
+	timeout time.Duration
+}
+
+// Option configures a component
+type Option func(*component)
+
+// Timeout overrides Config.ComponentTimeout for a component
+func Timeout(d time.Duration) Option {
+	return func(c *component) {
+		c.timeout = d
+	}
+}
+
+// Manager cancels the root context on a signal and stops components
+type Manager struct {
+	config Config
+	ctx    context.Context
+	cancel context.CancelCauseFunc
+
+	signals chan os.Signal
+	stopped chan struct{}
+
+	mu         sync.Mutex
+	components []component
+
+	stopOnce sync.Once
+	stopErr  error
+}
+
+// New creates a manager listening for signals
+func New(config Config) *Manager {
+	defaults := DefaultConfig()
+	if len(config.Signals) == 0 {
+		config.Signals = defaults.Signals
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = defaults.Timeout
+	}
+	if config.ComponentTimeout <= 0 {
+		config.ComponentTimeout = defaults.ComponentTimeout
+	}
+	if config.Logger == nil {
+		config.Logger = &logger.Logger{Logger: zap.NewNop()}
+	}
+
+	ctx, cancel := context.WithCancelCause(context.Background())
+	m := &Manager{
+		config:  config,
+		ctx:     ctx,
+		cancel:  cancel,
+		signals: make(chan os.Signal, 2),
+		stopped: make(chan struct{}),
+	}
+	signal.Notify(m.signals, config.Signals...)
+	go m.listen()
+	return m
+}
+
+// Context returns the root context, cancelled when shutdown starts.
+// context.Cause tells the signal
+func (m *Manager) Context() context.Context {
+	return m.ctx
+}
+
+// Register adds a component, components are stopped in reverse
+// registration order, so register dependencies first
+func (m *Manager) Register(name string, fn func(ctx context.Context) error, opts ...Option) {
+	c := component{name: name, fn: fn, timeout: m.config.ComponentTimeout}
+	for _, opt := range opts {
+		opt(&c)
+	}
+
+	m.mu.Lock()
+	m.components = append(m.components, c)
+	m.mu.Unlock()
+}
+
+// Shutdown starts the shutdown without a signal, e.g. when a worker is done
+func (m *Manager) Shutdown() {
+	m.cancel(errors.New("shutdown requested"))
+}
+
+// Wait blocks until shutdown starts and stops components, see Stop
+func (m *Manager) Wait() error {
+	<-m.ctx.Done()
+	return m.Stop()
+}
+
+// Stop cancels the root context and stops components once, later calls
+// return the same result. Components still running at their timeout are
+// abandoned and reported, the next ones are stopped anyway
+func (m *Manager) Stop() error {
+	m.stopOnce.Do(func() {
+		m.Shutdown()
+		m.stopErr = m.stop()
+		signal.Stop(m.signals)
+		close(m.stopped)
+	})
+	return m.stopErr
+}
+
+func (m *Manager) listen() {
+	select {
+	case sig := <-m.signals:
+		m.config.Logger.Info("Shutdown signal received", logger.String("signal", sig.String()))
+		m.cancel(fmt.Errorf("received %s", sig))
+	case <-m.ctx.Done():
+	}
+
+	// Stuck teardown is not waited for after another signal
+	select {
+	case sig := <-m.signals:
+		m.config.Logger.Warn("Second signal received, exiting", logger.String("signal", sig.String()))
+		exit(1)
+	case <-m.stopped:
+	}
+}
+
+func (m *Manager) stop() error {
+	m.mu.Lock()
+	components := make([]component, len(m.components))
+	copy(components, m.components)
+	m.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), m.config.Timeout)
+	defer cancel()
+
+	var failures []Failure
+	for i := len(components) - 1; i >= 0; i-- {
+		c := components[i]
+		start := time.Now()
+		if err := m.stopComponent(ctx, c); err != nil {
+			m.config.Logger.Error("Component failed to stop", logger.String("component", c.name), logger.Error(err))
+			failures = append(failures, Failure{Component: c.name, Err: err})
+			continue
+		}
+		m.config.Logger.Info("Component stopped",
+			logger.String("component", c.name),
+			zap.Duration("duration", time.Since(start)),
+		)
+	}
+
+	if len(failures) > 0 {
+		return &Error{Failures: failures}
+	}
+	return nil
+}
+
+func (m *Manager) stopComponent(parent context.Context, c component) error {
+	if err := parent.Err(); err != nil {
+		return fmt.Errorf("not stopped, shutdown timed out: %w", err)
+	}
+	ctx, cancel := context.WithTimeout(parent, c.timeout)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- fmt.Errorf("panic: %v", r)
+			}
+		}()
+		done <- c.fn(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("timed out: %w", ctx.Err())
+	}
+}
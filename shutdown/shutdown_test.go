@@ -0,0 +1,155 @@
+package shutdown
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestStopInReverseOrder(t *testing.T) {
+	m := New(DefaultConfig())
+
+	var order []string
+	for _, name := range []string{"db", "cache", "consumer"} {
+		m.Register(name, func(context.Context) error {
+			order = append(order, name)
+			return nil
+		})
+	}
+
+	if err := m.Stop(); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+	if got := strings.Join(order, ","); got != "consumer,cache,db" {
+		t.Errorf("stopped as %s, want consumer,cache,db", got)
+	}
+	if m.Context().Err() == nil {
+		t.Error("root context not cancelled by Stop")
+	}
+
+	// Later calls return the same result without stopping again
+	if err := m.Stop(); err != nil || len(order) != 3 {
+		t.Errorf("second Stop() = %v, stopped %d times", err, len(order))
+	}
+}
+
+func TestStopReportsFailuresAndContinues(t *testing.T) {
+	m := New(Config{ComponentTimeout: 20 * time.Millisecond})
+
+	failed := errors.New("flush failed")
+	var stoppedDB bool
+	m.Register("db", func(context.Context) error {
+		stoppedDB = true
+		return nil
+	})
+	m.Register("stuck", func(ctx context.Context) error {
+		<-ctx.Done()
+		time.Sleep(time.Hour)
+		return nil
+	})
+	m.Register("panics", func(context.Context) error { panic("boom") })
+	m.Register("broken", func(context.Context) error { return failed })
+	m.Register("slow", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}, Timeout(time.Millisecond))
+
+	err := m.Stop()
+
+	var stopErr *Error
+	if !errors.As(err, &stopErr) {
+		t.Fatalf("Stop() error = %v, want *Error", err)
+	}
+	var names []string
+	for _, f := range stopErr.Failures {
+		names = append(names, f.Component)
+	}
+	if got := strings.Join(names, ","); got != "slow,broken,panics,stuck" {
+		t.Errorf("failures = %s", got)
+	}
+	if !errors.Is(err, failed) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Stop() error does not wrap component errors: %v", err)
+	}
+	if !stoppedDB {
+		t.Error("components after a stuck one were not stopped")
+	}
+}
+
+func TestStopTimeoutSkipsRemainingComponents(t *testing.T) {
+	m := New(Config{Timeout: 20 * time.Millisecond, ComponentTimeout: time.Second})
+
+	m.Register("first", func(context.Context) error { return nil })
+	m.Register("slow", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	err := m.Stop()
+	if err == nil || !strings.Contains(err.Error(), "first: not stopped, shutdown timed out") {
+		t.Errorf("Stop() error = %v, want first skipped", err)
+	}
+}
+
+func TestSignalStartsShutdown(t *testing.T) {
+	m := New(DefaultConfig())
+	t.Cleanup(func() { _ = m.Stop() })
+
+	m.signals <- syscall.SIGTERM
+
+	select {
+	case <-m.Context().Done():
+	case <-time.After(time.Second):
+		t.Fatal("root context not cancelled by a signal")
+	}
+	if cause := context.Cause(m.Context()); cause == nil || !strings.Contains(cause.Error(), "terminated") {
+		t.Errorf("cause = %v, want the signal", cause)
+	}
+}
+
+func TestSecondSignalExits(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		codes []int
+	)
+	exited := make(chan struct{})
+	previous := exit
+	exit = func(code int) {
+		mu.Lock()
+		codes = append(codes, code)
+		mu.Unlock()
+		close(exited)
+	}
+	t.Cleanup(func() { exit = previous })
+
+	m := New(DefaultConfig())
+	release := make(chan struct{})
+	m.Register("stuck", func(context.Context) error {
+		<-release
+		return nil
+	})
+
+	m.signals <- syscall.SIGTERM
+	stopped := make(chan error, 1)
+	go func() { stopped <- m.Wait() }()
+	m.signals <- syscall.SIGINT
+
+	select {
+	case <-exited:
+	case <-time.After(time.Second):
+		t.Fatal("second signal did not exit")
+	}
+	mu.Lock()
+	if len(codes) != 1 || codes[0] != 1 {
+		t.Errorf("exit codes = %v, want [1]", codes)
+	}
+	mu.Unlock()
+
+	close(release)
+	if err := <-stopped; err != nil {
+		t.Errorf("Wait() error = %v", err)
+	}
+}
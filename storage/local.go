@@ -0,0 +1,202 @@
+package storage
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// metaSuffix is sidecar file with object attributes
+const metaSuffix = ".meta.json"
+
+// Local implements Storage on local filesystem, intended for tests and development
+type Local struct {
+	root      string
+	publicURL string
+}
+
+// localMeta is persisted next to object
+type localMeta struct {
+	ContentType string            `json:"content_type"`
+	ETag        string            `json:"etag"`
+	Metadata    map[string]string `json:"metadata,omitempty"`
+}
+
+// NewLocal creates local storage rooted at root
+func NewLocal(root, publicURL string) (*Local, error) {
+	if root == "" {
+		root = "./data"
+	}
+	if err := os.MkdirAll(root, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create storage root: %w", err)
+	}
+	return &Local{root: root, publicURL: strings.TrimSuffix(publicURL, "/")}, nil
+}
+
+// Put implements Storage
+func (l *Local) Put(ctx context.Context, key string, r io.Reader, size int64, opts PutOptions) (*ObjectInfo, error) {
+	p, err := l.path(key)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	// Write to temp file so readers never see partial objects
+	tmp, err := os.CreateTemp(filepath.Dir(p), ".upload-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	hash := md5.New()
+	written, err := io.Copy(io.MultiWriter(tmp, hash), &ctxReader{ctx: ctx, r: r})
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to write object %s: %w", key, err)
+	}
+	if size >= 0 && written != size {
+		return nil, fmt.Errorf("failed to write object %s: expected %d bytes, got %d", key, size, written)
+	}
+
+	contentType := opts.ContentType
+	if contentType == "" {
+		contentType = mime.TypeByExtension(path.Ext(key))
+	}
+
+	meta := localMeta{
+		ContentType: contentType,
+		ETag:        hex.EncodeToString(hash.Sum(nil)),
+		Metadata:    opts.Metadata,
+	}
+	data, err := json.Marshal(meta)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(p+metaSuffix, data, 0644); err != nil {
+		return nil, fmt.Errorf("failed to write object metadata: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), p); err != nil {
+		return nil, fmt.Errorf("failed to store object %s: %w", key, err)
+	}
+
+	return l.Stat(ctx, key)
+}
+
+// Get implements Storage
+func (l *Local) Get(ctx context.Context, key string) (io.ReadCloser, *ObjectInfo, error) {
+	info, err := l.Stat(ctx, key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	p, _ := l.path(key)
+	f, err := os.Open(p)
+	if err != nil {
+		return nil, nil, l.wrapError(key, err)
+	}
+
+	return f, info, nil
+}
+
+// Stat implements Storage
+func (l *Local) Stat(ctx context.Context, key string) (*ObjectInfo, error) {
+	p, err := l.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	fi, err := os.Stat(p)
+	if err != nil {
+		return nil, l.wrapError(key, err)
+	}
+
+	var meta localMeta
+	if data, err := os.ReadFile(p + metaSuffix); err == nil {
+		_ = json.Unmarshal(data, &meta)
+	}
+
+	return &ObjectInfo{
+		Key:          key,
+		Size:         fi.Size(),
+		ContentType:  meta.ContentType,
+		ETag:         meta.ETag,
+		LastModified: fi.ModTime(),
+		Metadata:     meta.Metadata,
+	}, nil
+}
+
+// Delete implements Storage
+func (l *Local) Delete(ctx context.Context, key string) error {
+	p, err := l.path(key)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range []string{p, p + metaSuffix} {
+		if err := os.Remove(name); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("failed to delete object %s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// Presign implements Storage. Links are not signed, serve them only in development
+func (l *Local) Presign(ctx context.Context, key, method string, expiry time.Duration) (string, error) {
+	if err := checkPresignMethod(method); err != nil {
+		return "", err
+	}
+	if _, err := l.path(key); err != nil {
+		return "", err
+	}
+
+	expires := strconv.FormatInt(time.Now().Add(expiry).Unix(), 10)
+	return l.publicURL + "/" + (&url.URL{Path: key}).EscapedPath() + "?expires=" + expires, nil
+}
+
+// path resolves key inside root rejecting traversal
+func (l *Local) path(key string) (string, error) {
+	clean := path.Clean("/" + key)
+	if key == "" || clean == "/" || strings.HasSuffix(clean, metaSuffix) {
+		return "", fmt.Errorf("invalid object key: %q", key)
+	}
+	return filepath.Join(l.root, filepath.FromSlash(clean)), nil
+}
+
+// wrapError maps missing files to ErrNotFound
+func (l *Local) wrapError(key string, err error) error {
+	if errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("%w: %s", ErrNotFound, key)
+	}
+	return fmt.Errorf("failed to get object %s: %w", key, err)
+}
+
+// ctxReader stops copying when context is done
+type ctxReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (c *ctxReader) Read(p []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return c.r.Read(p)
+}
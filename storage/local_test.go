@@ -0,0 +1,138 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newTestLocal(t *testing.T) (*Local, string) {
+	t.Helper()
+	root := filepath.Join(t.TempDir(), "root")
+	l, err := NewLocal(root, "http://localhost:8080/files/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return l, root
+}
+
+func TestLocalPutGetDelete(t *testing.T) {
+	l, _ := newTestLocal(t)
+	ctx := context.Background()
+
+	info, err := l.Put(ctx, "avatars/42.png", strings.NewReader("image"), 5, PutOptions{
+		Metadata: map[string]string{"owner": "42"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// md5("image")
+	if info.Size != 5 || info.ContentType != "image/png" || info.ETag != "78805a221a988e79ef3f42d7c5bfd418" {
+		t.Errorf("Put() info = %+v", info)
+	}
+
+	r, info, err := l.Get(ctx, "avatars/42.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := io.ReadAll(r)
+	r.Close()
+	if string(data) != "image" || info.Metadata["owner"] != "42" {
+		t.Errorf("Get() = %q, %+v", data, info)
+	}
+
+	// Overwrite replaces content and attributes
+	if _, err := l.Put(ctx, "avatars/42.png", strings.NewReader("new image"), -1, PutOptions{ContentType: "image/webp"}); err != nil {
+		t.Fatal(err)
+	}
+	if info, err := l.Stat(ctx, "avatars/42.png"); err != nil || info.Size != 9 || info.ContentType != "image/webp" || info.Metadata != nil {
+		t.Errorf("Stat() after overwrite = %+v, %v", info, err)
+	}
+
+	if err := l.Delete(ctx, "avatars/42.png"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := l.Stat(ctx, "avatars/42.png"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Stat() after delete error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestLocalMissingKey(t *testing.T) {
+	l, _ := newTestLocal(t)
+	ctx := context.Background()
+
+	if _, _, err := l.Get(ctx, "missing.txt"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get() error = %v, want ErrNotFound", err)
+	}
+	if _, err := l.Stat(ctx, "missing.txt"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Stat() error = %v, want ErrNotFound", err)
+	}
+	if err := l.Delete(ctx, "missing.txt"); err != nil {
+		t.Errorf("Delete() of a missing object error = %v, want nil", err)
+	}
+}
+
+func TestLocalSizeMismatch(t *testing.T) {
+	l, _ := newTestLocal(t)
+	ctx := context.Background()
+
+	if _, err := l.Put(ctx, "report.csv", strings.NewReader("a,b"), 10, PutOptions{}); err == nil {
+		t.Fatal("Put() with a short body succeeded")
+	}
+	if _, err := l.Stat(ctx, "report.csv"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("partial object is visible: %v", err)
+	}
+}
+
+func TestLocalKeysStayInsideRoot(t *testing.T) {
+	l, root := newTestLocal(t)
+	ctx := context.Background()
+
+	for _, key := range []string{"../escape.txt", "a/../../escape.txt", "/../../escape.txt"} {
+		if _, err := l.Put(ctx, key, strings.NewReader("x"), 1, PutOptions{}); err != nil {
+			t.Fatalf("Put(%q) error = %v", key, err)
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(filepath.Dir(root), "escape.txt")); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("object is written outside of the root: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "escape.txt")); err != nil {
+		t.Errorf("object is not stored under the root: %v", err)
+	}
+}
+
+func TestLocalRejectsInvalidKeys(t *testing.T) {
+	l, _ := newTestLocal(t)
+	ctx := context.Background()
+
+	for _, key := range []string{"", "/", "..", "photo.png" + metaSuffix} {
+		if _, err := l.Put(ctx, key, strings.NewReader("x"), 1, PutOptions{}); err == nil {
+			t.Errorf("Put(%q) succeeded", key)
+		}
+		if _, err := l.Stat(ctx, key); err == nil || errors.Is(err, ErrNotFound) {
+			t.Errorf("Stat(%q) error = %v, want invalid key", key, err)
+		}
+	}
+}
+
+func TestLocalPresign(t *testing.T) {
+	l, _ := newTestLocal(t)
+	ctx := context.Background()
+
+	link, err := l.Presign(ctx, "docs/annual report.pdf", http.MethodGet, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(link, "http://localhost:8080/files/docs/annual%20report.pdf?expires=") {
+		t.Errorf("Presign() = %s", link)
+	}
+	if _, err := l.Presign(ctx, "docs/report.pdf", http.MethodDelete, 0); err == nil {
+		t.Error("Presign() accepts DELETE")
+	}
+}
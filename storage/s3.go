@@ -0,0 +1,144 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// S3 implements Storage for S3-compatible services
+type S3 struct {
+	client *minio.Client
+	bucket string
+}
+
+// NewS3 creates S3 storage. Empty credentials fall back to
+// environment variables and IAM role
+func NewS3(cfg Config) (*S3, error) {
+	creds := credentials.NewStaticV4(cfg.AccessKey, cfg.SecretKey, "")
+	if cfg.AccessKey == "" {
+		creds = credentials.NewChainCredentials([]credentials.Provider{
+			&credentials.EnvAWS{},
+			&credentials.EnvMinio{},
+			&credentials.IAM{Client: &http.Client{Transport: http.DefaultTransport}},
+		})
+	}
+
+	client, err := minio.New(cfg.Endpoint, &minio.Options{
+		Creds:  creds,
+		Secure: cfg.UseSSL,
+		Region: cfg.Region,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create s3 client: %w", err)
+	}
+
+	return &S3{client: client, bucket: cfg.Bucket}, nil
+}
+
+// Client returns underlying minio client
+func (s *S3) Client() *minio.Client {
+	return s.client
+}
+
+// Put implements Storage
+func (s *S3) Put(ctx context.Context, key string, r io.Reader, size int64, opts PutOptions) (*ObjectInfo, error) {
+	info, err := s.client.PutObject(ctx, s.bucket, key, r, size, minio.PutObjectOptions{
+		ContentType:  opts.ContentType,
+		UserMetadata: opts.Metadata,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to put object %s: %w", key, err)
+	}
+
+	return &ObjectInfo{
+		Key:          key,
+		Size:         info.Size,
+		ContentType:  opts.ContentType,
+		ETag:         info.ETag,
+		LastModified: info.LastModified,
+		Metadata:     opts.Metadata,
+	}, nil
+}
+
+// Get implements Storage
+func (s *S3) Get(ctx context.Context, key string) (io.ReadCloser, *ObjectInfo, error) {
+	obj, err := s.client.GetObject(ctx, s.bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, nil, s.wrapError(key, err)
+	}
+
+	// GetObject is lazy, Stat performs the request
+	stat, err := obj.Stat()
+	if err != nil {
+		obj.Close()
+		return nil, nil, s.wrapError(key, err)
+	}
+
+	return obj, toObjectInfo(stat), nil
+}
+
+// Stat implements Storage
+func (s *S3) Stat(ctx context.Context, key string) (*ObjectInfo, error) {
+	stat, err := s.client.StatObject(ctx, s.bucket, key, minio.StatObjectOptions{})
+	if err != nil {
+		return nil, s.wrapError(key, err)
+	}
+	return toObjectInfo(stat), nil
+}
+
+// Delete implements Storage
+func (s *S3) Delete(ctx context.Context, key string) error {
+	if err := s.client.RemoveObject(ctx, s.bucket, key, minio.RemoveObjectOptions{}); err != nil {
+		return fmt.Errorf("failed to delete object %s: %w", key, err)
+	}
+	return nil
+}
+
+// Presign implements Storage
+func (s *S3) Presign(ctx context.Context, key, method string, expiry time.Duration) (string, error) {
+	if err := checkPresignMethod(method); err != nil {
+		return "", err
+	}
+
+	var (
+		u   *url.URL
+		err error
+	)
+	if method == http.MethodPut {
+		u, err = s.client.PresignedPutObject(ctx, s.bucket, key, expiry)
+	} else {
+		u, err = s.client.PresignedGetObject(ctx, s.bucket, key, expiry, url.Values{})
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to presign object %s: %w", key, err)
+	}
+
+	return u.String(), nil
+}
+
+// wrapError maps missing object errors to ErrNotFound
+func (s *S3) wrapError(key string, err error) error {
+	if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+		return fmt.Errorf("%w: %s", ErrNotFound, key)
+	}
+	return fmt.Errorf("failed to get object %s: %w", key, err)
+}
+
+// toObjectInfo converts minio object info
+func toObjectInfo(stat minio.ObjectInfo) *ObjectInfo {
+	return &ObjectInfo{
+		Key:          stat.Key,
+		Size:         stat.Size,
+		ContentType:  stat.ContentType,
+		ETag:         stat.ETag,
+		LastModified: stat.LastModified,
+		Metadata:     stat.UserMetadata,
+	}
+}
@@ -0,0 +1,100 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/alimzhanovlr/sdk/tracing"
+)
+
+// ErrNotFound is returned when object does not exist
+var ErrNotFound = errors.New("storage: object not found")
+
+// Drivers
+const (
+	DriverS3    = "s3"
+	DriverLocal = "local"
+)
+
+// Config holds storage configuration
+type Config struct {
+	// Driver is s3 (S3, MinIO, GCS interoperability API) or local
+	Driver    string
+	Endpoint  string
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	UseSSL    bool
+	// LocalPath is root directory for local driver
+	LocalPath string
+	// PublicURL is base URL used by local driver for presigned links
+	PublicURL string
+}
+
+// ObjectInfo describes stored object
+type ObjectInfo struct {
+	Key          string
+	Size         int64
+	ContentType  string
+	ETag         string
+	LastModified time.Time
+	Metadata     map[string]string
+}
+
+// PutOptions configures upload
+type PutOptions struct {
+	ContentType string
+	Metadata    map[string]string
+}
+
+// Storage is object storage client
+type Storage interface {
+	// Put uploads object streaming from r, size -1 means unknown
+	Put(ctx context.Context, key string, r io.Reader, size int64, opts PutOptions) (*ObjectInfo, error)
+	// Get returns object reader, caller must close it
+	Get(ctx context.Context, key string) (io.ReadCloser, *ObjectInfo, error)
+	// Stat returns object info
+	Stat(ctx context.Context, key string) (*ObjectInfo, error)
+	// Delete removes object, deleting missing object is not an error
+	Delete(ctx context.Context, key string) error
+	// Presign returns temporary URL for GET or PUT
+	Presign(ctx context.Context, key, method string, expiry time.Duration) (string, error)
+}
+
+// New creates storage for configured driver, instrumented with tracer
+func New(cfg Config, tracer *tracing.Tracer) (Storage, error) {
+	var (
+		s   Storage
+		err error
+	)
+
+	switch cfg.Driver {
+	case DriverS3:
+		s, err = NewS3(cfg)
+	case "", DriverLocal:
+		s, err = NewLocal(cfg.LocalPath, cfg.PublicURL)
+	default:
+		return nil, fmt.Errorf("unknown storage driver: %s", cfg.Driver)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if tracer == nil {
+		return s, nil
+	}
+	return &tracedStorage{next: s, tracer: tracer, driver: cfg.Driver, bucket: cfg.Bucket}, nil
+}
+
+// checkPresignMethod validates presign method
+func checkPresignMethod(method string) error {
+	if method != http.MethodGet && method != http.MethodPut {
+		return fmt.Errorf("unsupported presign method: %s", method)
+	}
+	return nil
+}
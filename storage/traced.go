@@ -0,0 +1,76 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/alimzhanovlr/sdk/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracedStorage adds spans around storage calls
+type tracedStorage struct {
+	next   Storage
+	tracer *tracing.Tracer
+	driver string
+	bucket string
+}
+
+// start starts storage span
+func (t *tracedStorage) start(ctx context.Context, op, key string) (context.Context, trace.Span) {
+	ctx, span := t.tracer.Start(ctx, "storage."+op, trace.WithSpanKind(trace.SpanKindClient))
+	span.SetAttributes(
+		attribute.String("storage.driver", t.driver),
+		attribute.String("storage.bucket", t.bucket),
+		attribute.String("storage.key", key),
+	)
+	return ctx, span
+}
+
+// end records error and ends span
+func end(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+	}
+	span.End()
+}
+
+func (t *tracedStorage) Put(ctx context.Context, key string, r io.Reader, size int64, opts PutOptions) (*ObjectInfo, error) {
+	ctx, span := t.start(ctx, "put", key)
+	info, err := t.next.Put(ctx, key, r, size, opts)
+	if info != nil {
+		span.SetAttributes(attribute.Int64("storage.size", info.Size))
+	}
+	end(span, err)
+	return info, err
+}
+
+func (t *tracedStorage) Get(ctx context.Context, key string) (io.ReadCloser, *ObjectInfo, error) {
+	ctx, span := t.start(ctx, "get", key)
+	rc, info, err := t.next.Get(ctx, key)
+	end(span, err)
+	return rc, info, err
+}
+
+func (t *tracedStorage) Stat(ctx context.Context, key string) (*ObjectInfo, error) {
+	ctx, span := t.start(ctx, "stat", key)
+	info, err := t.next.Stat(ctx, key)
+	end(span, err)
+	return info, err
+}
+
+func (t *tracedStorage) Delete(ctx context.Context, key string) error {
+	ctx, span := t.start(ctx, "delete", key)
+	err := t.next.Delete(ctx, key)
+	end(span, err)
+	return err
+}
+
+func (t *tracedStorage) Presign(ctx context.Context, key, method string, expiry time.Duration) (string, error) {
+	ctx, span := t.start(ctx, "presign", key)
+	u, err := t.next.Presign(ctx, key, method, expiry)
+	end(span, err)
+	return u, err
+}
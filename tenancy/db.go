@@ -0,0 +1,260 @@
+package tenancy
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Strategy isolates tenant data in the database. The tenant is empty in
+// SingleTenant mode without a tenant in the context
+type Strategy interface {
+	// Conn returns a connection scoped to tenant, closing it releases the
+	// scope
+	Conn(ctx context.Context, tenant Tenant) (*Conn, error)
+}
+
+// Conn is a connection scoped to a tenant
+type Conn struct {
+	*sql.Conn
+	release func() error
+}
+
+// Close releases the tenant scope and returns the connection to its pool
+func (c *Conn) Close() error {
+	var err error
+	if c.release != nil {
+		err = c.release()
+	}
+	return errors.Join(err, c.Conn.Close())
+}
+
+// DatabasePerTenant keeps a connection pool per tenant database
+type DatabasePerTenant struct {
+	open func(ctx context.Context, tenant Tenant) (*sql.DB, error)
+
+	mu  sync.Mutex
+	dbs map[string]*sql.DB
+}
+
+var _ Strategy = (*DatabasePerTenant)(nil)
+
+// NewDatabasePerTenant creates the strategy, open is called once per tenant,
+// e.g. with a DSN from tenant attributes. Pool limits should account for
+// the number of tenants per instance
+func NewDatabasePerTenant(open func(ctx context.Context, tenant Tenant) (*sql.DB, error)) *DatabasePerTenant {
+	return &DatabasePerTenant{open: open, dbs: make(map[string]*sql.DB)}
+}
+
+// DB returns the pool of tenant, opening it on first use
+func (s *DatabasePerTenant) DB(ctx context.Context, tenant Tenant) (*sql.DB, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if db, ok := s.dbs[tenant.ID]; ok {
+		return db, nil
+	}
+
+	db, err := s.open(ctx, tenant)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database of tenant %q: %w", tenant.ID, err)
+	}
+	s.dbs[tenant.ID] = db
+	return db, nil
+}
+
+// Conn implements Strategy
+func (s *DatabasePerTenant) Conn(ctx context.Context, tenant Tenant) (*Conn, error) {
+	db, err := s.DB(ctx, tenant)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get connection of tenant %q: %w", tenant.ID, err)
+	}
+	return &Conn{Conn: conn}, nil
+}
+
+// Close closes pools of all tenants, e.g. on shutdown
+func (s *DatabasePerTenant) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var errs []error
+	for id, db := range s.dbs {
+		errs = append(errs, db.Close())
+		delete(s.dbs, id)
+	}
+	return errors.Join(errs...)
+}
+
+// SchemaPerTenant shares one PostgreSQL pool and sets search_path to the
+// tenant schema for the time a connection is held
+type SchemaPerTenant struct {
+	db     *sql.DB
+	schema func(tenant Tenant) string
+}
+
+var _ Strategy = (*SchemaPerTenant)(nil)
+
+// NewSchemaPerTenant creates the strategy, schema names a tenant schema and
+// defaults to "tenant_<id>"
+func NewSchemaPerTenant(db *sql.DB, schema func(tenant Tenant) string) *SchemaPerTenant {
+	if schema == nil {
+		schema = func(tenant Tenant) string {
+			return "tenant_" + strings.ReplaceAll(tenant.ID, "-", "_")
+		}
+	}
+	return &SchemaPerTenant{db: db, schema: schema}
+}
+
+// Schema returns the schema of tenant
+func (s *SchemaPerTenant) Schema(tenant Tenant) string {
+	return s.schema(tenant)
+}
+
+// Conn implements Strategy, the connection uses the default search_path
+// without a tenant
+func (s *SchemaPerTenant) Conn(ctx context.Context, tenant Tenant) (*Conn, error) {
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get connection: %w", err)
+	}
+	if tenant.ID == "" {
+		return &Conn{Conn: conn}, nil
+	}
+
+	if err := ValidateID(tenant.ID); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	if _, err := conn.ExecContext(ctx, "SET search_path TO "+quoteIdent(s.schema(tenant))); err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("failed to set schema of tenant %q: %w", tenant.ID, err)
+	}
+
+	return &Conn{
+		Conn: conn,
+		// The pooled connection must not keep the schema for the next user
+		release: func() error {
+			_, err := conn.ExecContext(context.Background(), "RESET search_path")
+			if err != nil {
+				// A connection in an unknown state is dropped from the pool
+				_ = conn.Raw(func(interface{}) error { return driver.ErrBadConn })
+			}
+			return err
+		},
+	}, nil
+}
+
+// Migrate creates the tenant schema if needed and runs migrate with a
+// connection scoped to it
+func (s *SchemaPerTenant) Migrate(ctx context.Context, tenant Tenant, migrate func(ctx context.Context, conn *Conn) error) error {
+	if err := ValidateID(tenant.ID); err != nil {
+		return err
+	}
+	if _, err := s.db.ExecContext(ctx, "CREATE SCHEMA IF NOT EXISTS "+quoteIdent(s.schema(tenant))); err != nil {
+		return fmt.Errorf("failed to create schema of tenant %q: %w", tenant.ID, err)
+	}
+
+	conn, err := s.Conn(ctx, tenant)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	return migrate(ctx, conn)
+}
+
+// DB runs queries on the context tenant's data through a strategy. In
+// MultiTenant mode queries without a tenant fail with ErrNoTenant instead
+// of reaching shared or default data
+type DB struct {
+	strategy Strategy
+	mode     Mode
+}
+
+// NewDB creates tenant scoped database access
+func NewDB(strategy Strategy, mode Mode) *DB {
+	return &DB{strategy: strategy, mode: mode}
+}
+
+// Conn returns a connection scoped to the context tenant, the caller must
+// close it
+func (d *DB) Conn(ctx context.Context) (*Conn, error) {
+	tenant, ok := FromContext(ctx)
+	if !ok && d.mode == MultiTenant {
+		return nil, ErrNoTenant
+	}
+	return d.strategy.Conn(ctx, tenant)
+}
+
+// ExecContext executes a statement for the context tenant
+func (d *DB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	conn, err := d.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	return conn.ExecContext(ctx, query, args...)
+}
+
+// Query runs fn with rows of the query for the context tenant, rows are
+// closed afterwards
+func (d *DB) Query(ctx context.Context, fn func(rows *sql.Rows) error, query string, args ...interface{}) error {
+	conn, err := d.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	rows, err := conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	if err := fn(rows); err != nil {
+		return err
+	}
+	return rows.Err()
+}
+
+// Tx runs fn in a transaction for the context tenant, committed when fn
+// returns nil and rolled back otherwise
+func (d *DB) Tx(ctx context.Context, opts *sql.TxOptions, fn func(ctx context.Context, tx *sql.Tx) error) (err error) {
+	conn, err := d.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	tx, err := conn.BeginTx(ctx, opts)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	if err = fn(ctx, tx); err != nil {
+		return err
+	}
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// quoteIdent quotes a PostgreSQL identifier
+func quoteIdent(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
@@ -0,0 +1,202 @@
+package tenancy
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// recordingDriver logs statements of every connection, statements with a
+// prefix in fail return an error
+type recordingDriver struct {
+	mu         sync.Mutex
+	statements []string
+	opened     int
+	fail       map[string]bool
+}
+
+func (d *recordingDriver) Open(string) (driver.Conn, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.opened++
+	return &recordingConn{driver: d}, nil
+}
+
+func (d *recordingDriver) log() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]string(nil), d.statements...)
+}
+
+type recordingConn struct {
+	driver *recordingDriver
+}
+
+func (c *recordingConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("not supported")
+}
+
+func (c *recordingConn) Close() error { return nil }
+
+func (c *recordingConn) Begin() (driver.Tx, error) { return c, nil }
+
+func (c *recordingConn) Commit() error   { return nil }
+func (c *recordingConn) Rollback() error { return nil }
+
+func (c *recordingConn) ExecContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Result, error) {
+	c.driver.mu.Lock()
+	defer c.driver.mu.Unlock()
+	c.driver.statements = append(c.driver.statements, query)
+	for prefix := range c.driver.fail {
+		if strings.HasPrefix(query, prefix) {
+			return nil, errors.New("statement failed")
+		}
+	}
+	return driver.RowsAffected(0), nil
+}
+
+type recordingConnector struct {
+	driver *recordingDriver
+}
+
+func (c recordingConnector) Connect(context.Context) (driver.Conn, error) { return c.driver.Open("") }
+func (c recordingConnector) Driver() driver.Driver                        { return c.driver }
+
+func newRecordingDB(t *testing.T) (*sql.DB, *recordingDriver) {
+	t.Helper()
+	d := &recordingDriver{fail: map[string]bool{}}
+	db := sql.OpenDB(recordingConnector{driver: d})
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	return db, d
+}
+
+func TestSchemaPerTenantSetsAndResetsSearchPath(t *testing.T) {
+	db, d := newRecordingDB(t)
+	strategy := NewSchemaPerTenant(db, nil)
+
+	conn, err := strategy.Conn(context.Background(), Tenant{ID: "acme-eu"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := conn.ExecContext(context.Background(), "SELECT 1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := conn.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{`SET search_path TO "tenant_acme_eu"`, "SELECT 1", "RESET search_path"}
+	if got := d.log(); strings.Join(got, ";") != strings.Join(want, ";") {
+		t.Errorf("statements = %q, want %q", got, want)
+	}
+}
+
+func TestSchemaPerTenantDropsConnectionWhenResetFails(t *testing.T) {
+	db, d := newRecordingDB(t)
+	strategy := NewSchemaPerTenant(db, nil)
+	d.mu.Lock()
+	d.fail["RESET"] = true
+	d.mu.Unlock()
+
+	conn, err := strategy.Conn(context.Background(), Tenant{ID: "acme"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := conn.Close(); err == nil {
+		t.Error("expected reset error")
+	}
+
+	d.mu.Lock()
+	delete(d.fail, "RESET")
+	d.mu.Unlock()
+	conn, err = strategy.Conn(context.Background(), Tenant{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.Close()
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.opened != 2 {
+		t.Errorf("opened = %d, the connection with a tenant schema must not be reused", d.opened)
+	}
+}
+
+func TestSchemaPerTenantWithoutTenantKeepsDefaultPath(t *testing.T) {
+	db, d := newRecordingDB(t)
+
+	conn, err := NewSchemaPerTenant(db, nil).Conn(context.Background(), Tenant{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.Close()
+	if got := d.log(); len(got) != 0 {
+		t.Errorf("statements = %q, want none", got)
+	}
+}
+
+func TestSchemaPerTenantRejectsInvalidID(t *testing.T) {
+	db, d := newRecordingDB(t)
+
+	if _, err := NewSchemaPerTenant(db, nil).Conn(context.Background(), Tenant{ID: `x"; DROP SCHEMA public; --`}); err == nil {
+		t.Fatal("expected error")
+	}
+	if got := d.log(); len(got) != 0 {
+		t.Errorf("statements = %q, want none", got)
+	}
+}
+
+func TestDBRequiresTenantInMultiTenantMode(t *testing.T) {
+	db, _ := newRecordingDB(t)
+	strategy := NewSchemaPerTenant(db, nil)
+
+	if _, err := NewDB(strategy, MultiTenant).ExecContext(context.Background(), "SELECT 1"); !errors.Is(err, ErrNoTenant) {
+		t.Errorf("err = %v, want ErrNoTenant", err)
+	}
+	if _, err := NewDB(strategy, SingleTenant).ExecContext(context.Background(), "SELECT 1"); err != nil {
+		t.Errorf("single tenant err = %v", err)
+	}
+}
+
+func TestDBTxUsesTenantSchema(t *testing.T) {
+	db, d := newRecordingDB(t)
+	ctx := WithTenant(context.Background(), Tenant{ID: "acme"})
+
+	err := NewDB(NewSchemaPerTenant(db, nil), MultiTenant).Tx(ctx, nil, func(ctx context.Context, tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, "UPDATE orders SET paid = true")
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{`SET search_path TO "tenant_acme"`, "UPDATE orders SET paid = true", "RESET search_path"}
+	if got := d.log(); strings.Join(got, ";") != strings.Join(want, ";") {
+		t.Errorf("statements = %q, want %q", got, want)
+	}
+}
+
+func TestDatabasePerTenantOpensOncePerTenant(t *testing.T) {
+	opened := map[string]int{}
+	strategy := NewDatabasePerTenant(func(_ context.Context, tenant Tenant) (*sql.DB, error) {
+		opened[tenant.ID]++
+		db, _ := newRecordingDB(t)
+		return db, nil
+	})
+	defer strategy.Close()
+
+	for _, id := range []string{"a", "b", "a"} {
+		conn, err := strategy.Conn(context.Background(), Tenant{ID: id})
+		if err != nil {
+			t.Fatal(err)
+		}
+		conn.Close()
+	}
+	if opened["a"] != 1 || opened["b"] != 1 {
+		t.Errorf("opened = %v", opened)
+	}
+}
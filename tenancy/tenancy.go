@@ -0,0 +1,92 @@
+// Package tenancy carries the current tenant through contexts and scopes
+// data access to it, with a database or a schema per tenant
+package tenancy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/alimzhanovlr/sdk/config"
+)
+
+// ErrNoTenant is returned when multi-tenant code runs without a tenant
+var ErrNoTenant = errors.New("tenancy: no tenant in context")
+
+// Tenant is a customer whose data is isolated from others
+type Tenant struct {
+	ID   string
+	Name string
+	// Attributes hold routing data resolved with the tenant, e.g. region
+	// or plan
+	Attributes map[string]string
+}
+
+// Mode decides whether data access requires a tenant
+type Mode int
+
+const (
+	// SingleTenant allows access without a tenant, strategies use their
+	// default database or schema
+	SingleTenant Mode = iota
+	// MultiTenant refuses access without a tenant in the context
+	MultiTenant
+)
+
+type tenantKey struct{}
+
+// WithTenant returns context carrying tenant, config.ForTenant sees its ID
+// as well
+func WithTenant(ctx context.Context, tenant Tenant) context.Context {
+	ctx = config.WithTenant(ctx, tenant.ID)
+	return context.WithValue(ctx, tenantKey{}, tenant)
+}
+
+// FromContext returns tenant stored by WithTenant
+func FromContext(ctx context.Context) (Tenant, bool) {
+	tenant, ok := ctx.Value(tenantKey{}).(Tenant)
+	return tenant, ok && tenant.ID != ""
+}
+
+// ID returns ID of the context tenant, empty without one
+func ID(ctx context.Context) string {
+	tenant, _ := FromContext(ctx)
+	return tenant.ID
+}
+
+// Require returns the context tenant or ErrNoTenant, repositories of shared
+// tables call it before building tenant_id filters
+func Require(ctx context.Context) (Tenant, error) {
+	tenant, ok := FromContext(ctx)
+	if !ok {
+		return Tenant{}, ErrNoTenant
+	}
+	return tenant, nil
+}
+
+// Resolver loads a tenant by ID, e.g. from a tenants table or a cache
+type Resolver interface {
+	Resolve(ctx context.Context, id string) (Tenant, error)
+}
+
+// ResolverFunc adapts a function to Resolver
+type ResolverFunc func(ctx context.Context, id string) (Tenant, error)
+
+// Resolve implements Resolver
+func (f ResolverFunc) Resolve(ctx context.Context, id string) (Tenant, error) {
+	return f(ctx, id)
+}
+
+// ValidateID checks that id is safe in schema, database and file names:
+// letters, digits, '_' and '-' up to 63 characters
+func ValidateID(id string) error {
+	if id == "" || len(id) > 63 {
+		return fmt.Errorf("invalid tenant id %q: length must be 1 to 63", id)
+	}
+	for _, r := range id {
+		if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') && (r < '0' || r > '9') && r != '_' && r != '-' {
+			return fmt.Errorf("invalid tenant id %q: unexpected character %q", id, r)
+		}
+	}
+	return nil
+}
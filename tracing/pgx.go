@@ -0,0 +1,53 @@
+package tracing
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// PgxTracer creates query spans for pgx connections and pools, the same as
+// WrapDB does for database/sql:
+//
+//	cfg.ConnConfig.Tracer = tracing.NewPgxTracer()
+type PgxTracer struct{}
+
+var (
+	_ pgx.QueryTracer = (*PgxTracer)(nil)
+	_ pgx.BatchTracer = (*PgxTracer)(nil)
+)
+
+// NewPgxTracer creates pgx tracer
+func NewPgxTracer() *PgxTracer {
+	return &PgxTracer{}
+}
+
+// TraceQueryStart implements pgx.QueryTracer
+func (t *PgxTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	ctx, _ = startDBSpan(ctx, "postgresql", data.SQL)
+	return ctx
+}
+
+// TraceQueryEnd implements pgx.QueryTracer
+func (t *PgxTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	endDBSpan(trace.SpanFromContext(ctx), data.CommandTag.RowsAffected(), data.Err)
+}
+
+// TraceBatchStart implements pgx.BatchTracer, the batch span is the parent
+// of its queries
+func (t *PgxTracer) TraceBatchStart(ctx context.Context, _ *pgx.Conn, _ pgx.TraceBatchStartData) context.Context {
+	ctx, _ = startDBSpan(ctx, "postgresql", "BATCH")
+	return ctx
+}
+
+// TraceBatchQuery implements pgx.BatchTracer
+func (t *PgxTracer) TraceBatchQuery(ctx context.Context, _ *pgx.Conn, data pgx.TraceBatchQueryData) {
+	_, span := startDBSpan(ctx, "postgresql", data.SQL)
+	endDBSpan(span, data.CommandTag.RowsAffected(), data.Err)
+}
+
+// TraceBatchEnd implements pgx.BatchTracer
+func (t *PgxTracer) TraceBatchEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceBatchEndData) {
+	endDBSpan(trace.SpanFromContext(ctx), -1, data.Err)
+}
@@ -0,0 +1,127 @@
+package tracing
+
+import (
+	"context"
+
+	"github.com/alimzhanovlr/sdk/redact"
+	"go.opentelemetry.io/otel/attribute"
+	tracesdk "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// RedactingProcessor masks span and event attributes matched by the
+// sanitizer field list and text patterns before passing spans to the next
+// processor, so tokens and personal data put into attributes by application
+// code never reach the exporter
+type RedactingProcessor struct {
+	next      tracesdk.SpanProcessor
+	sanitizer redact.ValueSanitizer
+}
+
+var _ tracesdk.SpanProcessor = (*RedactingProcessor)(nil)
+
+// NewRedactingProcessor wraps next, usually the batcher of an exporter. nil
+// sanitizer means redact.Default, pass httpclient.Sanitizer to share the
+// HTTP logging rules
+func NewRedactingProcessor(next tracesdk.SpanProcessor, sanitizer redact.ValueSanitizer) *RedactingProcessor {
+	if sanitizer == nil {
+		sanitizer = redact.Default()
+	}
+	return &RedactingProcessor{next: next, sanitizer: sanitizer}
+}
+
+// OnStart implements tracesdk.SpanProcessor
+func (p *RedactingProcessor) OnStart(parent context.Context, span tracesdk.ReadWriteSpan) {
+	p.next.OnStart(parent, span)
+}
+
+// OnEnd implements tracesdk.SpanProcessor. Ended spans are read-only, so a
+// span with redacted attributes is passed on wrapped
+func (p *RedactingProcessor) OnEnd(span tracesdk.ReadOnlySpan) {
+	attrs, changed := p.redact(span.Attributes())
+
+	events := span.Events()
+	var redactedEvents []tracesdk.Event
+	for i, event := range events {
+		eventAttrs, eventChanged := p.redact(event.Attributes)
+		if !eventChanged {
+			continue
+		}
+		if redactedEvents == nil {
+			redactedEvents = make([]tracesdk.Event, len(events))
+			copy(redactedEvents, events)
+		}
+		redactedEvents[i].Attributes = eventAttrs
+	}
+
+	if !changed && redactedEvents == nil {
+		p.next.OnEnd(span)
+		return
+	}
+	if redactedEvents == nil {
+		redactedEvents = events
+	}
+	p.next.OnEnd(redactedSpan{ReadOnlySpan: span, attributes: attrs, events: redactedEvents})
+}
+
+// redactedSpan replaces attributes and events of an ended span
+type redactedSpan struct {
+	tracesdk.ReadOnlySpan
+	attributes []attribute.KeyValue
+	events     []tracesdk.Event
+}
+
+// Attributes implements tracesdk.ReadOnlySpan
+func (s redactedSpan) Attributes() []attribute.KeyValue {
+	return s.attributes
+}
+
+// Events implements tracesdk.ReadOnlySpan
+func (s redactedSpan) Events() []tracesdk.Event {
+	return s.events
+}
+
+// Shutdown implements tracesdk.SpanProcessor
+func (p *RedactingProcessor) Shutdown(ctx context.Context) error {
+	return p.next.Shutdown(ctx)
+}
+
+// ForceFlush implements tracesdk.SpanProcessor
+func (p *RedactingProcessor) ForceFlush(ctx context.Context) error {
+	return p.next.ForceFlush(ctx)
+}
+
+// redact sanitizes attributes as one JSON object, values are compared in
+// their string form and changed ones are replaced by masked strings
+func (p *RedactingProcessor) redact(attrs []attribute.KeyValue) ([]attribute.KeyValue, bool) {
+	if len(attrs) == 0 {
+		return attrs, false
+	}
+
+	values := make(map[string]interface{}, len(attrs))
+	for _, attr := range attrs {
+		values[string(attr.Key)] = attr.Value.Emit()
+	}
+
+	sanitized, err := p.sanitizer.SanitizeValue(values)
+	masked, ok := sanitized.(map[string]interface{})
+	if err != nil || !ok {
+		return attrs, false
+	}
+
+	var out []attribute.KeyValue
+	for i, attr := range attrs {
+		value, _ := masked[string(attr.Key)].(string)
+		if value == values[string(attr.Key)] {
+			continue
+		}
+		if out == nil {
+			out = make([]attribute.KeyValue, len(attrs))
+			copy(out, attrs)
+		}
+		out[i] = attribute.String(string(attr.Key), value)
+	}
+	if out == nil {
+		return attrs, false
+	}
+	return out, true
+}
@@ -0,0 +1,76 @@
+package tracing
+
+import (
+	"os"
+
+	"github.com/alimzhanovlr/sdk/buildinfo"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/resource"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+)
+
+// k8sEnv maps resource attributes to downward API env vars, the first set
+// variable wins:
+//
+//	env:
+//	  - name: K8S_POD_NAME
+//	    valueFrom: {fieldRef: {fieldPath: metadata.name}}
+var k8sEnv = []struct {
+	attr func(string) attribute.KeyValue
+	vars []string
+}{
+	{semconv.K8SPodName, []string{"K8S_POD_NAME", "POD_NAME"}},
+	{semconv.K8SNamespaceName, []string{"K8S_NAMESPACE_NAME", "POD_NAMESPACE"}},
+	{semconv.K8SNodeName, []string{"K8S_NODE_NAME", "NODE_NAME"}},
+}
+
+// NewResource describes the service for traces and metrics: service name
+// and version, build commit and date, deployment.environment, Kubernetes
+// pod, namespace and node from downward API env vars, ResourceAttributes and
+// OTEL_RESOURCE_ATTRIBUTES. Version defaults to the buildinfo version
+func NewResource(cfg Config) *resource.Resource {
+	attrs := []attribute.KeyValue{semconv.ServiceName(cfg.ServiceName)}
+
+	build := buildinfo.Get()
+	version := cfg.ServiceVersion
+	if version == "" {
+		version = build.Version
+	}
+	if version != "" {
+		attrs = append(attrs, semconv.ServiceVersion(version))
+	}
+	if build.Commit != "" {
+		attrs = append(attrs, attribute.String("build.commit", build.Commit))
+	}
+	if build.BuildDate != "" {
+		attrs = append(attrs, attribute.String("build.date", build.BuildDate))
+	}
+	if cfg.Environment != "" {
+		attrs = append(attrs, semconv.DeploymentEnvironment(cfg.Environment))
+	}
+	if host, err := os.Hostname(); err == nil {
+		attrs = append(attrs, semconv.HostName(host))
+	}
+
+	for _, k8s := range k8sEnv {
+		for _, name := range k8s.vars {
+			if value := os.Getenv(name); value != "" {
+				attrs = append(attrs, k8s.attr(value))
+				break
+			}
+		}
+	}
+
+	for key, value := range cfg.ResourceAttributes {
+		attrs = append(attrs, attribute.String(key, value))
+	}
+
+	res := resource.NewWithAttributes(semconv.SchemaURL, attrs...)
+
+	// OTEL_RESOURCE_ATTRIBUTES is set by operators and wins over config
+	merged, err := resource.Merge(res, resource.Environment())
+	if err != nil {
+		return res
+	}
+	return merged
+}
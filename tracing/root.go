@@ -0,0 +1,87 @@
+package tracing
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// rootOptions holds NewRootContext options
+type rootOptions struct {
+	kind  trace.SpanKind
+	attrs []attribute.KeyValue
+	links []trace.Link
+}
+
+// RootOption configures NewRootContext
+type RootOption func(*rootOptions)
+
+// WithRootKind sets span kind, internal by default
+func WithRootKind(kind trace.SpanKind) RootOption {
+	return func(o *rootOptions) {
+		o.kind = kind
+	}
+}
+
+// WithRootAttributes sets span attributes
+func WithRootAttributes(attrs ...attribute.KeyValue) RootOption {
+	return func(o *rootOptions) {
+		o.attrs = append(o.attrs, attrs...)
+	}
+}
+
+// WithLink links the root span to another span, e.g. the one that scheduled
+// the work. Invalid span contexts are ignored
+func WithLink(sc trace.SpanContext, attrs ...attribute.KeyValue) RootOption {
+	return func(o *rootOptions) {
+		if sc.IsValid() {
+			o.links = append(o.links, trace.Link{SpanContext: sc, Attributes: attrs})
+		}
+	}
+}
+
+// WithLinkFromHeaders links the root span to the trace propagated in message
+// headers, e.g. messaging.Message.Headers
+func WithLinkFromHeaders(headers map[string]string) RootOption {
+	ctx := propagation.TraceContext{}.Extract(context.Background(), propagation.MapCarrier(headers))
+	return WithLink(trace.SpanContextFromContext(ctx), attribute.String("link.type", "origin"))
+}
+
+// NewRootContext starts a span of a new trace for background work, such as
+// a scheduler job or a consumed message. A span in ctx is linked rather than
+// becoming the parent, so long async flows get their own traces that are
+// still connected in the UI. Cancellation and values of ctx are kept
+func NewRootContext(ctx context.Context, name string, opts ...RootOption) (context.Context, trace.Span) {
+	return startRoot(ctx, otel.Tracer(instrumentationName), name, opts)
+}
+
+// NewRootContext is NewRootContext using the tracer
+func (t *Tracer) NewRootContext(ctx context.Context, name string, opts ...RootOption) (context.Context, trace.Span) {
+	if !t.enabled {
+		return ctx, trace.SpanFromContext(ctx)
+	}
+	return startRoot(ctx, t.tracer, name, opts)
+}
+
+func startRoot(ctx context.Context, tracer trace.Tracer, name string, opts []RootOption) (context.Context, trace.Span) {
+	o := &rootOptions{kind: trace.SpanKindInternal}
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		o.links = append(o.links, trace.Link{
+			SpanContext: sc,
+			Attributes:  []attribute.KeyValue{attribute.String("link.type", "caller")},
+		})
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return tracer.Start(ctx, name,
+		trace.WithNewRoot(),
+		trace.WithSpanKind(o.kind),
+		trace.WithAttributes(o.attrs...),
+		trace.WithLinks(o.links...),
+	)
+}
@@ -0,0 +1,89 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type rootCtxKey struct{}
+
+func TestNewRootContextLinksCallerAndOrigin(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	t.Cleanup(func() { _ = tp.Shutdown(context.Background()) })
+	tracer := &Tracer{provider: tp, tracer: tp.Tracer("test"), enabled: true}
+
+	// The message was produced in another trace
+	producerCtx, producer := tp.Tracer("test").Start(context.Background(), "produce")
+	headers := map[string]string{}
+	propagation.TraceContext{}.Inject(producerCtx, propagation.MapCarrier(headers))
+	producer.End()
+
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), rootCtxKey{}, "kept"))
+	ctx, caller := tp.Tracer("test").Start(ctx, "poll")
+
+	rootCtx, root := tracer.NewRootContext(ctx, "handle",
+		WithRootKind(trace.SpanKindConsumer),
+		WithRootAttributes(attribute.String("messaging.destination", "orders")),
+		WithLinkFromHeaders(headers),
+		WithLink(trace.SpanContext{}),
+	)
+	root.End()
+	caller.End()
+
+	if rootCtx.Value(rootCtxKey{}) != "kept" {
+		t.Error("values of ctx are lost")
+	}
+	cancel()
+	if rootCtx.Err() == nil {
+		t.Error("cancellation of ctx is not propagated")
+	}
+
+	var handled sdktrace.ReadOnlySpan
+	for _, span := range recorder.Ended() {
+		if span.Name() == "handle" {
+			handled = span
+		}
+	}
+	if handled == nil {
+		t.Fatal("root span not recorded")
+	}
+	if handled.Parent().IsValid() || handled.SpanContext().TraceID() == caller.SpanContext().TraceID() {
+		t.Error("root span has the caller as parent")
+	}
+	if handled.SpanKind() != trace.SpanKindConsumer {
+		t.Errorf("kind = %s, want consumer", handled.SpanKind())
+	}
+	if attributeMap(handled.Attributes())["messaging.destination"] != "orders" {
+		t.Errorf("attributes = %v", handled.Attributes())
+	}
+
+	links := handled.Links()
+	if len(links) != 2 {
+		t.Fatalf("got %d links, want caller and origin", len(links))
+	}
+	wantLinks := map[string]trace.TraceID{
+		"caller": caller.SpanContext().TraceID(),
+		"origin": producer.SpanContext().TraceID(),
+	}
+	for _, link := range links {
+		linkType := attributeMap(link.Attributes)["link.type"]
+		if want, ok := wantLinks[linkType]; !ok || link.SpanContext.TraceID() != want {
+			t.Errorf("link %s to trace %s", linkType, link.SpanContext.TraceID())
+		}
+	}
+}
+
+func TestNewRootContextDisabledTracer(t *testing.T) {
+	ctx := context.WithValue(context.Background(), rootCtxKey{}, "kept")
+	got, span := (&Tracer{}).NewRootContext(ctx, "job")
+	if got != ctx || span.SpanContext().IsValid() {
+		t.Error("disabled tracer must return ctx and its span")
+	}
+}
@@ -0,0 +1,138 @@
+package tracing
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"slices"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// rowsDriver returns n rows of one column, then fails with err if set
+type rowsDriver struct {
+	n   int
+	err error
+}
+
+func (d *rowsDriver) Open(string) (driver.Conn, error) { return &rowsConn{driver: d}, nil }
+
+type rowsConn struct {
+	driver *rowsDriver
+}
+
+func (c *rowsConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (c *rowsConn) Close() error                        { return nil }
+func (c *rowsConn) Begin() (driver.Tx, error)           { return nil, errors.New("not supported") }
+
+func (c *rowsConn) QueryContext(context.Context, string, []driver.NamedValue) (driver.Rows, error) {
+	return &fakeRows{left: c.driver.n, err: c.driver.err}, nil
+}
+
+type fakeRows struct {
+	left int
+	err  error
+}
+
+func (r *fakeRows) Columns() []string { return []string{"id"} }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.left == 0 {
+		if r.err != nil {
+			return r.err
+		}
+		return io.EOF
+	}
+	r.left--
+	dest[0] = int64(r.left)
+	return nil
+}
+
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+	return recorder
+}
+
+func openTraced(t *testing.T, name string, d driver.Driver) *sql.DB {
+	t.Helper()
+	// Drivers cannot be unregistered, repeated runs reuse the first one
+	if !slices.Contains(sql.Drivers(), name) {
+		sql.Register(name, d)
+	}
+	traced, err := WrapDB(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := sql.Open(traced, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestQuerySpanEndsOnRowsClose(t *testing.T) {
+	recorder := recordSpans(t)
+	db := openTraced(t, "tracing-rows", &rowsDriver{n: 3})
+
+	rows, err := db.QueryContext(context.Background(), "SELECT id FROM t WHERE name = 'x'")
+	if err != nil {
+		t.Fatal(err)
+	}
+	count := 0
+	for rows.Next() {
+		count++
+		if len(recorder.Ended()) != 0 {
+			t.Fatal("span ended while rows are read")
+		}
+	}
+	// database/sql closes rows after the last one
+	if err := rows.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	spans := recorder.Ended()
+	if count != 3 || len(spans) != 1 {
+		t.Fatalf("rows = %d, spans = %d", count, len(spans))
+	}
+	for _, attr := range spans[0].Attributes() {
+		if attr.Key == "db.statement" && attr.Value.AsString() != "SELECT id FROM t WHERE name = ?" {
+			t.Errorf("db.statement = %q", attr.Value.AsString())
+		}
+	}
+	if spans[0].Status().Code == codes.Error {
+		t.Errorf("unexpected error status %v", spans[0].Status())
+	}
+}
+
+func TestQuerySpanRecordsRowsError(t *testing.T) {
+	recorder := recordSpans(t)
+	db := openTraced(t, "tracing-rows-error", &rowsDriver{n: 1, err: errors.New("connection reset")})
+
+	rows, err := db.QueryContext(context.Background(), "SELECT id FROM t")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for rows.Next() {
+	}
+	if rows.Err() == nil {
+		t.Fatal("expected rows error")
+	}
+	rows.Close()
+
+	spans := recorder.Ended()
+	if len(spans) != 1 || spans[0].Status().Code != codes.Error {
+		t.Fatalf("spans = %d, status = %v", len(spans), spans[0].Status())
+	}
+}
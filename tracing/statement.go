@@ -0,0 +1,194 @@
+package tracing
+
+import (
+	"context"
+	"strings"
+	"unicode"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName names tracers of SDK instrumentation
+const instrumentationName = "github.com/alimzhanovlr/sdk/tracing"
+
+// SanitizeStatement replaces string and numeric literals with ? and collapses
+// whitespace, so db.statement carries no values even for queries built
+// without placeholders. Comments are dropped, doubled quotes, E strings with
+// backslash escapes and $$ dollar quoted strings are understood, double
+// quotes are identifiers
+func SanitizeStatement(query string) string {
+	return sanitizeStatement(query, false)
+}
+
+// SanitizeStatementFor is SanitizeStatement in the dialect of db.system:
+// for mysql double quotes are strings, backslashes escape in every string
+// and # starts a comment
+func SanitizeStatementFor(system, query string) string {
+	return sanitizeStatement(query, system == "mysql")
+}
+
+func sanitizeStatement(query string, mysql bool) string {
+	var b strings.Builder
+	b.Grow(len(query))
+
+	runes := []rune(query)
+	space := false
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			space = b.Len() > 0
+			continue
+		case r == '-' && next(runes, i) == '-', mysql && r == '#':
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+			space = b.Len() > 0
+			continue
+		case r == '/' && next(runes, i) == '*':
+			for i += 2; i < len(runes) && !(runes[i] == '*' && next(runes, i) == '/'); i++ {
+			}
+			i++
+			space = b.Len() > 0
+			continue
+		case r == '\'':
+			i = skipQuoted(runes, i, mysql)
+			r = '?'
+		case (r == 'E' || r == 'e') && next(runes, i) == '\'' && (i == 0 || !isIdentRune(runes[i-1])):
+			i = skipQuoted(runes, i+1, true)
+			r = '?'
+		case r == '"' && mysql:
+			i = skipQuoted(runes, i, true)
+			r = '?'
+		case r == '"':
+			// A quoted identifier may contain anything, keep it as is
+			end := skipQuoted(runes, i, false)
+			b.WriteString(separator(&space))
+			b.WriteString(string(runes[i:min(end+1, len(runes))]))
+			i = end
+			continue
+		case r == '$' && (i == 0 || !isIdentRune(runes[i-1])):
+			if end, ok := skipDollarQuoted(runes, i); ok {
+				i = end
+				r = '?'
+			}
+		case unicode.IsDigit(r) && (i == 0 || !isIdentRune(runes[i-1])):
+			for i+1 < len(runes) && (unicode.IsDigit(runes[i+1]) || runes[i+1] == '.') {
+				i++
+			}
+			r = '?'
+		}
+
+		b.WriteString(separator(&space))
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// separator returns the pending space once
+func separator(space *bool) string {
+	if *space {
+		*space = false
+		return " "
+	}
+	return ""
+}
+
+// next returns the rune after i, 0 at the end
+func next(runes []rune, i int) rune {
+	if i+1 < len(runes) {
+		return runes[i+1]
+	}
+	return 0
+}
+
+// skipQuoted returns the index of the quote closing the one at start, a
+// doubled quote is escaped and so is any rune after a backslash when
+// backslash is set
+func skipQuoted(runes []rune, start int, backslash bool) int {
+	quote := runes[start]
+	i := start + 1
+	for ; i < len(runes); i++ {
+		switch {
+		case backslash && runes[i] == '\\':
+			i++
+		case runes[i] == quote:
+			if next(runes, i) != quote {
+				return i
+			}
+			i++
+		}
+	}
+	return i
+}
+
+// skipDollarQuoted returns the index of the last rune of a $tag$...$tag$
+// string starting at start, false for placeholders like $1
+func skipDollarQuoted(runes []rune, start int) (int, bool) {
+	i := start + 1
+	for i < len(runes) && runes[i] != '$' {
+		if !(runes[i] == '_' || unicode.IsLetter(runes[i]) || (i > start+1 && unicode.IsDigit(runes[i]))) {
+			return 0, false
+		}
+		i++
+	}
+	if i >= len(runes) {
+		return 0, false
+	}
+
+	tag := string(runes[start : i+1])
+	body := string(runes[i+1:])
+	end := strings.Index(body, tag)
+	if end < 0 {
+		return len(runes) - 1, true
+	}
+	return i + len([]rune(body[:end+len(tag)])), true
+}
+
+// isIdentRune reports whether r continues an identifier or a $1 placeholder
+func isIdentRune(r rune) bool {
+	return r == '_' || r == '$' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// statementOperation returns the first keyword of query, e.g. SELECT
+func statementOperation(query string) string {
+	fields := strings.Fields(query)
+	if len(fields) == 0 {
+		return ""
+	}
+	return strings.ToUpper(strings.TrimLeft(fields[0], "("))
+}
+
+// startDBSpan starts a client span for a query
+func startDBSpan(ctx context.Context, system, query string) (context.Context, trace.Span) {
+	statement := SanitizeStatementFor(system, query)
+	operation := statementOperation(statement)
+	name := operation
+	if name == "" {
+		name = "db.query"
+	}
+
+	return otel.Tracer(instrumentationName).Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("db.system", system),
+			attribute.String("db.operation", operation),
+			attribute.String("db.statement", statement),
+		),
+	)
+}
+
+// endDBSpan records rows affected (negative when unknown) and error status
+func endDBSpan(span trace.Span, rows int64, err error) {
+	if rows >= 0 {
+		span.SetAttributes(attribute.Int64("db.rows_affected", rows))
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
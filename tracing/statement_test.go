@@ -0,0 +1,59 @@
+package tracing
+
+import "testing"
+
+func TestSanitizeStatement(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{"literals", "SELECT * FROM users WHERE email = 'a@b.c' AND age > 30", "SELECT * FROM users WHERE email = ? AND age > ?"},
+		{"whitespace", "SELECT id\n\t FROM  users", "SELECT id FROM users"},
+		{"doubled quote", "SELECT 'it''s' FROM t", "SELECT ? FROM t"},
+		{"placeholders", "UPDATE t SET a = $1, b = ? WHERE c2 = $2", "UPDATE t SET a = $1, b = ? WHERE c2 = $2"},
+		{"decimal", "SELECT 3.14", "SELECT ?"},
+		{"identifier with digits", "SELECT col1 FROM t2", "SELECT col1 FROM t2"},
+		{"escape string", `SELECT E'it\'s secret' FROM t`, "SELECT ? FROM t"},
+		{"escape string keeps going", `SELECT E'a\\', 'b' FROM t`, "SELECT ?, ? FROM t"},
+		{"backslash is literal in plain strings", `SELECT 'C:\', 'x' FROM t`, "SELECT ?, ? FROM t"},
+		{"dollar quoted", "SELECT $$it's 'secret'$$ FROM t", "SELECT ? FROM t"},
+		{"tagged dollar quoted", "DO $fn$ BEGIN RAISE 'x$$y'; END $fn$", "DO ?"},
+		{"quoted identifier", `SELECT "Secret Col" FROM "t"`, `SELECT "Secret Col" FROM "t"`},
+		{"line comment", "SELECT 1 -- token=abc\nFROM t", "SELECT ? FROM t"},
+		{"block comment", "/* user 42 */ SELECT * FROM t /* card 4111 */", "SELECT * FROM t"},
+		{"unterminated", "SELECT 'abc", "SELECT ?"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SanitizeStatement(tt.query); got != tt.want {
+				t.Errorf("SanitizeStatement(%q) = %q, want %q", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSanitizeStatementMySQL(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{"double quoted string", `SELECT * FROM users WHERE name = "O\"Brien"`, "SELECT * FROM users WHERE name = ?"},
+		{"backslash escape", `SELECT 'it\'s', 1`, "SELECT ?, ?"},
+		{"hash comment", "SELECT 1 # password=x\nFROM t", "SELECT ? FROM t"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SanitizeStatementFor("mysql", tt.query); got != tt.want {
+				t.Errorf("SanitizeStatementFor(mysql, %q) = %q, want %q", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStatementOperationSkipsComments(t *testing.T) {
+	if got := statementOperation(SanitizeStatement("/* app */ select 1")); got != "SELECT" {
+		t.Errorf("operation = %q", got)
+	}
+}
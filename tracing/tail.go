@@ -0,0 +1,253 @@
+package tracing
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/codes"
+	tracesdk "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TailSamplingConfig configures tail-based sampling. Every span is recorded,
+// spans are buffered per trace and the whole trace is kept if any span has
+// error status or is slower than LatencyThreshold; other traces are kept
+// with Config.SampleRate probability
+type TailSamplingConfig struct {
+	Enabled bool
+	// LatencyThreshold keeps traces with a slower span, zero disables it
+	LatencyThreshold time.Duration
+	// DecisionWait is how long spans wait for the local root span, traces
+	// whose root is in another service are decided when it passes
+	DecisionWait time.Duration
+	// MaxTraces bounds buffered traces, the oldest are decided early
+	MaxTraces int
+}
+
+// DefaultTailSamplingConfig returns tail sampling defaults
+func DefaultTailSamplingConfig() TailSamplingConfig {
+	return TailSamplingConfig{
+		Enabled:          true,
+		LatencyThreshold: time.Second,
+		DecisionWait:     10 * time.Second,
+		MaxTraces:        10000,
+	}
+}
+
+// pendingTrace holds spans of an undecided trace
+type pendingTrace struct {
+	spans     []tracesdk.ReadOnlySpan
+	keep      bool
+	firstSeen time.Time
+}
+
+// decision is remembered for spans ending after their trace was decided
+type decision struct {
+	keep bool
+	at   time.Time
+}
+
+// TailSamplingProcessor buffers spans and passes kept traces to the next
+// processor, usually the batcher of an exporter
+type TailSamplingProcessor struct {
+	next     tracesdk.SpanProcessor
+	config   TailSamplingConfig
+	baseline tracesdk.Sampler
+
+	mu      sync.Mutex
+	pending map[trace.TraceID]*pendingTrace
+	decided map[trace.TraceID]decision
+
+	stop chan struct{}
+	done chan struct{}
+	once sync.Once
+}
+
+var _ tracesdk.SpanProcessor = (*TailSamplingProcessor)(nil)
+
+// NewTailSamplingProcessor wraps next, sampleRate is the share of ordinary
+// traces kept
+func NewTailSamplingProcessor(next tracesdk.SpanProcessor, config TailSamplingConfig, sampleRate float64) *TailSamplingProcessor {
+	defaults := DefaultTailSamplingConfig()
+	if config.DecisionWait <= 0 {
+		config.DecisionWait = defaults.DecisionWait
+	}
+	if config.MaxTraces <= 0 {
+		config.MaxTraces = defaults.MaxTraces
+	}
+
+	p := &TailSamplingProcessor{
+		next:     next,
+		config:   config,
+		baseline: tracesdk.TraceIDRatioBased(sampleRate),
+		pending:  make(map[trace.TraceID]*pendingTrace),
+		decided:  make(map[trace.TraceID]decision),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	go p.run()
+	return p
+}
+
+// OnStart implements tracesdk.SpanProcessor
+func (p *TailSamplingProcessor) OnStart(parent context.Context, span tracesdk.ReadWriteSpan) {
+	p.next.OnStart(parent, span)
+}
+
+// OnEnd implements tracesdk.SpanProcessor
+func (p *TailSamplingProcessor) OnEnd(span tracesdk.ReadOnlySpan) {
+	traceID := span.SpanContext().TraceID()
+	interesting := p.interesting(span)
+
+	p.mu.Lock()
+	if d, ok := p.decided[traceID]; ok {
+		p.mu.Unlock()
+		// Late span follows the decision, an error span is kept anyway
+		if d.keep || interesting {
+			p.next.OnEnd(span)
+		}
+		return
+	}
+
+	pending, ok := p.pending[traceID]
+	if !ok {
+		if len(p.pending) >= p.config.MaxTraces {
+			p.evictOldestLocked()
+		}
+		pending = &pendingTrace{firstSeen: time.Now()}
+		p.pending[traceID] = pending
+	}
+	pending.spans = append(pending.spans, span)
+	pending.keep = pending.keep || interesting
+
+	// The local root ends last, so the trace is complete in this service
+	parent := span.Parent()
+	if parent.IsValid() && !parent.IsRemote() {
+		p.mu.Unlock()
+		return
+	}
+	spans, keep := p.decideLocked(traceID)
+	p.mu.Unlock()
+
+	p.export(spans, keep)
+}
+
+// Shutdown implements tracesdk.SpanProcessor, pending traces are decided
+func (p *TailSamplingProcessor) Shutdown(ctx context.Context) error {
+	p.once.Do(func() {
+		close(p.stop)
+		<-p.done
+	})
+	p.decideAll(time.Time{})
+	return p.next.Shutdown(ctx)
+}
+
+// ForceFlush implements tracesdk.SpanProcessor, pending traces are decided
+func (p *TailSamplingProcessor) ForceFlush(ctx context.Context) error {
+	p.decideAll(time.Time{})
+	return p.next.ForceFlush(ctx)
+}
+
+func (p *TailSamplingProcessor) interesting(span tracesdk.ReadOnlySpan) bool {
+	if span.Status().Code == codes.Error {
+		return true
+	}
+	threshold := p.config.LatencyThreshold
+	return threshold > 0 && span.EndTime().Sub(span.StartTime()) > threshold
+}
+
+// decideLocked removes the trace from pending and returns its spans and
+// whether they are kept
+func (p *TailSamplingProcessor) decideLocked(traceID trace.TraceID) ([]tracesdk.ReadOnlySpan, bool) {
+	pending := p.pending[traceID]
+	delete(p.pending, traceID)
+
+	keep := pending.keep
+	if !keep {
+		result := p.baseline.ShouldSample(tracesdk.SamplingParameters{TraceID: traceID})
+		keep = result.Decision == tracesdk.RecordAndSample
+	}
+	p.decided[traceID] = decision{keep: keep, at: time.Now()}
+	return pending.spans, keep
+}
+
+func (p *TailSamplingProcessor) evictOldestLocked() {
+	var (
+		oldestID trace.TraceID
+		oldest   *pendingTrace
+	)
+	for id, pending := range p.pending {
+		if oldest == nil || pending.firstSeen.Before(oldest.firstSeen) {
+			oldestID, oldest = id, pending
+		}
+	}
+	if oldest == nil {
+		return
+	}
+
+	spans, keep := p.decideLocked(oldestID)
+	if keep {
+		// Exported under the lock, the next processor only queues spans
+		for _, span := range spans {
+			p.next.OnEnd(span)
+		}
+	}
+}
+
+// decideAll decides traces first seen before deadline, all with zero deadline
+func (p *TailSamplingProcessor) decideAll(deadline time.Time) {
+	type batch struct {
+		spans []tracesdk.ReadOnlySpan
+		keep  bool
+	}
+
+	p.mu.Lock()
+	var batches []batch
+	for id, pending := range p.pending {
+		if deadline.IsZero() || pending.firstSeen.Before(deadline) {
+			spans, keep := p.decideLocked(id)
+			batches = append(batches, batch{spans: spans, keep: keep})
+		}
+	}
+	p.mu.Unlock()
+
+	for _, b := range batches {
+		p.export(b.spans, b.keep)
+	}
+}
+
+func (p *TailSamplingProcessor) export(spans []tracesdk.ReadOnlySpan, keep bool) {
+	if !keep {
+		return
+	}
+	for _, span := range spans {
+		p.next.OnEnd(span)
+	}
+}
+
+// run decides traces waiting longer than DecisionWait and forgets old
+// decisions
+func (p *TailSamplingProcessor) run() {
+	defer close(p.done)
+
+	ticker := time.NewTicker(max(p.config.DecisionWait/4, 100*time.Millisecond))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.stop:
+			return
+		case now := <-ticker.C:
+			p.decideAll(now.Add(-p.config.DecisionWait))
+
+			p.mu.Lock()
+			for id, d := range p.decided {
+				if now.Sub(d.at) > p.config.DecisionWait {
+					delete(p.decided, id)
+				}
+			}
+			p.mu.Unlock()
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func newTailSampling(t *testing.T, config TailSamplingConfig, sampleRate float64) (trace.Tracer, *TailSamplingProcessor, *tracetest.SpanRecorder) {
+	t.Helper()
+	recorder := tracetest.NewSpanRecorder()
+	processor := NewTailSamplingProcessor(recorder, config, sampleRate)
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(processor))
+	t.Cleanup(func() { _ = tp.Shutdown(context.Background()) })
+	return tp.Tracer("test"), processor, recorder
+}
+
+// spanNames returns names of exported spans
+func spanNames(recorder *tracetest.SpanRecorder) []string {
+	var names []string
+	for _, span := range recorder.Ended() {
+		names = append(names, span.Name())
+	}
+	return names
+}
+
+// runTrace ends a child span and its local root, child is changed by fn
+func runTrace(tracer trace.Tracer, name string, fn func(child trace.Span)) {
+	ctx, root := tracer.Start(context.Background(), name)
+	_, child := tracer.Start(ctx, name+".child")
+	fn(child)
+	child.End()
+	root.End()
+}
+
+func TestTailSamplingKeepsErrorAndSlowTraces(t *testing.T) {
+	tracer, _, recorder := newTailSampling(t, TailSamplingConfig{LatencyThreshold: time.Second}, 0)
+
+	runTrace(tracer, "ok", func(trace.Span) {})
+	runTrace(tracer, "failed", func(child trace.Span) { child.SetStatus(codes.Error, "boom") })
+
+	start := time.Now()
+	ctx, root := tracer.Start(context.Background(), "slow", trace.WithTimestamp(start))
+	_, child := tracer.Start(ctx, "slow.child", trace.WithTimestamp(start))
+	child.End(trace.WithTimestamp(start.Add(2 * time.Second)))
+	root.End(trace.WithTimestamp(start.Add(2 * time.Second)))
+
+	got := spanNames(recorder)
+	want := []string{"failed.child", "failed", "slow.child", "slow"}
+	if len(got) != len(want) {
+		t.Fatalf("exported %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("exported %v, want %v", got, want)
+			break
+		}
+	}
+}
+
+func TestTailSamplingBaselineRate(t *testing.T) {
+	tracer, _, recorder := newTailSampling(t, TailSamplingConfig{}, 1)
+	runTrace(tracer, "ok", func(trace.Span) {})
+	if got := spanNames(recorder); len(got) != 2 {
+		t.Errorf("exported %v, want the whole trace with rate 1", got)
+	}
+}
+
+func TestTailSamplingLateSpansFollowDecision(t *testing.T) {
+	tracer, _, recorder := newTailSampling(t, TailSamplingConfig{}, 0)
+
+	ctx, root := tracer.Start(context.Background(), "root")
+	_, late := tracer.Start(ctx, "late")
+	_, lateError := tracer.Start(ctx, "late-error")
+	root.End()
+
+	late.End()
+	lateError.SetStatus(codes.Error, "boom")
+	lateError.End()
+
+	if got := spanNames(recorder); len(got) != 1 || got[0] != "late-error" {
+		t.Errorf("exported %v, want only the late error span of a dropped trace", got)
+	}
+}
+
+func TestTailSamplingRemoteParentWaitsForDecision(t *testing.T) {
+	tracer, processor, recorder := newTailSampling(t, TailSamplingConfig{DecisionWait: time.Hour}, 0)
+
+	remote := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{1},
+		SpanID:     trace.SpanID{1},
+		TraceFlags: trace.FlagsSampled,
+		Remote:     true,
+	})
+	ctx := trace.ContextWithRemoteSpanContext(context.Background(), remote)
+	ctx, server := tracer.Start(ctx, "server")
+	_, query := tracer.Start(ctx, "query")
+	query.SetStatus(codes.Error, "timeout")
+	query.End()
+
+	if got := spanNames(recorder); len(got) != 0 {
+		t.Fatalf("exported %v before the local root ended", got)
+	}
+	server.End()
+	if got := spanNames(recorder); len(got) != 2 {
+		t.Fatalf("exported %v, want the trace when the local root ends", got)
+	}
+
+	// Spans without their local root wait for ForceFlush or DecisionWait
+	orphan := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{2},
+		SpanID:     trace.SpanID{2},
+		TraceFlags: trace.FlagsSampled,
+	}))
+	_, child := tracer.Start(orphan, "orphan")
+	child.SetStatus(codes.Error, "boom")
+	child.End()
+	if got := spanNames(recorder); len(got) != 2 {
+		t.Fatalf("exported %v, orphan decided too early", got)
+	}
+	_ = processor.ForceFlush(context.Background())
+	if got := spanNames(recorder); len(got) != 3 || got[2] != "orphan" {
+		t.Errorf("exported %v, want the orphan after ForceFlush", got)
+	}
+}
+
+func TestTailSamplingEvictsOldestTrace(t *testing.T) {
+	tracer, processor, recorder := newTailSampling(t, TailSamplingConfig{MaxTraces: 1, DecisionWait: time.Hour}, 0)
+
+	ctx1, root1 := tracer.Start(context.Background(), "first")
+	_, child1 := tracer.Start(ctx1, "first.child")
+	child1.SetStatus(codes.Error, "boom")
+	child1.End()
+
+	ctx2, root2 := tracer.Start(context.Background(), "second")
+	_, child2 := tracer.Start(ctx2, "second.child")
+	child2.End()
+
+	if got := spanNames(recorder); len(got) != 1 || got[0] != "first.child" {
+		t.Errorf("exported %v, want the evicted error trace", got)
+	}
+	processor.mu.Lock()
+	pending := len(processor.pending)
+	processor.mu.Unlock()
+	if pending != 1 {
+		t.Errorf("pending traces = %d, want MaxTraces", pending)
+	}
+
+	root1.End()
+	root2.End()
+	if got := spanNames(recorder); len(got) != 2 || got[1] != "first" {
+		t.Errorf("exported %v, want the late root of the kept trace", got)
+	}
+}
@@ -4,12 +4,11 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/alimzhanovlr/sdk/redact"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/exporters/jaeger"
-	"go.opentelemetry.io/otel/sdk/resource"
 	tracesdk "go.opentelemetry.io/otel/sdk/trace"
-	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
 	"go.opentelemetry.io/otel/trace"
 )
 
@@ -19,6 +18,20 @@ type Config struct {
 	ServiceName string
 	Endpoint    string
 	SampleRate  float64
+	// ServiceVersion is service.version, the main module version by default
+	ServiceVersion string
+	// Environment is deployment.environment, e.g. production
+	Environment string
+	// ResourceAttributes are added to the resource of traces and metrics
+	ResourceAttributes map[string]string
+	// RedactAttributes masks span attributes matched by Sanitizer, see
+	// RedactingProcessor
+	RedactAttributes bool
+	// Sanitizer defines sensitive fields, redact.Default when nil
+	Sanitizer redact.ValueSanitizer
+	// TailSampling keeps error and slow traces, SampleRate then applies to
+	// the other traces only
+	TailSampling TailSamplingConfig
 }
 
 // Tracer wraps OpenTelemetry tracer
@@ -40,14 +53,23 @@ func New(cfg Config) (*Tracer, error) {
 		return nil, fmt.Errorf("failed to create jaeger exporter: %w", err)
 	}
 
+	var processor tracesdk.SpanProcessor = tracesdk.NewBatchSpanProcessor(exp)
+	if cfg.RedactAttributes {
+		processor = NewRedactingProcessor(processor, cfg.Sanitizer)
+	}
+
+	sampler := tracesdk.TraceIDRatioBased(cfg.SampleRate)
+	if cfg.TailSampling.Enabled {
+		processor = NewTailSamplingProcessor(processor, cfg.TailSampling, cfg.SampleRate)
+		// Every span is recorded, the processor decides what is exported
+		sampler = tracesdk.AlwaysSample()
+	}
+
 	// Create trace provider
 	tp := tracesdk.NewTracerProvider(
-		tracesdk.WithBatcher(exp),
-		tracesdk.WithResource(resource.NewWithAttributes(
-			semconv.SchemaURL,
-			semconv.ServiceName(cfg.ServiceName),
-		)),
-		tracesdk.WithSampler(tracesdk.TraceIDRatioBased(cfg.SampleRate)),
+		tracesdk.WithSpanProcessor(processor),
+		tracesdk.WithResource(NewResource(cfg)),
+		tracesdk.WithSampler(sampler),
 	)
 
 	otel.SetTracerProvider(tp)
@@ -61,6 +83,16 @@ func New(cfg Config) (*Tracer, error) {
 	}, nil
 }
 
+// NewWithProvider creates a tracer on an existing provider, e.g. one shared
+// with other instrumentation or recording spans in tests
+func NewWithProvider(tp *tracesdk.TracerProvider, serviceName string) *Tracer {
+	return &Tracer{
+		provider: tp,
+		tracer:   tp.Tracer(serviceName),
+		enabled:  true,
+	}
+}
+
 // Start starts a new span
 func (t *Tracer) Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
 	if !t.enabled {
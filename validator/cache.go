@@ -0,0 +1,146 @@
+package validator
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// typeCache memoizes reflection metadata of validated struct types, the
+// static tags are cached by go-playground itself
+type typeCache struct {
+	enabled bool
+	// generation changes on RegisterRule, entries of older generations are
+	// compiled again
+	generation atomic.Uint64
+	types      sync.Map // reflect.Type -> *typeInfo
+	masks      sync.Map // maskKey -> []string
+}
+
+// typeInfo is compiled metadata of a struct type
+type typeInfo struct {
+	generation uint64
+	rules      []compiledRule
+}
+
+// compiledRule is a field with its rules resolved, in order of names
+type compiledRule struct {
+	ruleField
+	resolved []Rule
+}
+
+// maskKey identifies a resolved field mask path
+type maskKey struct {
+	t    reflect.Type
+	path string
+}
+
+// typeInfo returns compiled metadata of t, a struct type
+func (v *Validator) typeInfo(t reflect.Type) (*typeInfo, error) {
+	generation := v.cache.generation.Load()
+	if v.cache.enabled {
+		if cached, ok := v.cache.types.Load(t); ok {
+			if info := cached.(*typeInfo); info.generation == generation {
+				return info, nil
+			}
+		}
+	}
+
+	v.mu.RLock()
+	info := &typeInfo{generation: generation}
+	for _, field := range ruleFields(t) {
+		compiled := compiledRule{ruleField: field}
+		for _, name := range field.rules {
+			rule, ok := v.rules[name]
+			if !ok {
+				v.mu.RUnlock()
+				return nil, fmt.Errorf("validation rule %q is not registered", name)
+			}
+			compiled.resolved = append(compiled.resolved, rule)
+		}
+		info.rules = append(info.rules, compiled)
+	}
+	v.mu.RUnlock()
+
+	if v.cache.enabled {
+		v.cache.types.Store(t, info)
+	}
+	return info, nil
+}
+
+// maskPaths returns Go namespaces included by a field mask path, unknown
+// paths are not cached
+func (v *Validator) maskPaths(t reflect.Type, path string) ([]string, error) {
+	key := maskKey{t: t, path: path}
+	if v.cache.enabled {
+		if cached, ok := v.cache.masks.Load(key); ok {
+			return cached.([]string), nil
+		}
+	}
+
+	include := make(map[string]bool)
+	if err := maskFields(t, path, include); err != nil {
+		return nil, err
+	}
+	paths := sortedFields(include)
+
+	if v.cache.enabled {
+		v.cache.masks.Store(key, paths)
+	}
+	return paths, nil
+}
+
+// invalidate makes compiled rules stale, masks do not depend on rules
+func (c *typeCache) invalidate() {
+	c.generation.Add(1)
+}
+
+// ruleField is a struct field with the rules tag
+type ruleField struct {
+	index []int
+	path  string // Go namespace, e.g. Address.City
+	key   string
+	rules []string
+}
+
+// ruleFields walks exported fields and nested structs, keys match static
+// error details
+func ruleFields(t reflect.Type) []ruleField {
+	var fields []ruleField
+	// Types on the current path, recursive types are walked once
+	path := map[reflect.Type]bool{}
+	var walk func(t reflect.Type, index []int, prefix string)
+	walk = func(t reflect.Type, index []int, prefix string) {
+		path[t] = true
+		defer delete(path, t)
+
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if !f.IsExported() {
+				continue
+			}
+			fieldIndex := append(append([]int(nil), index...), i)
+
+			if tag := f.Tag.Get("rules"); tag != "" && tag != "-" {
+				fields = append(fields, ruleField{
+					index: fieldIndex,
+					path:  prefix + f.Name,
+					key:   strings.ToLower(f.Name),
+					rules: splitRules(tag),
+				})
+			}
+
+			ft := f.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct && ft.PkgPath() != "time" && !path[ft] {
+				walk(ft, fieldIndex, prefix+f.Name+".")
+			}
+		}
+	}
+	walk(t, nil, "")
+	return fields
+}
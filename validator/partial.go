@@ -0,0 +1,337 @@
+package validator
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/alimzhanovlr/sdk/errors"
+)
+
+// Option configures ValidateCtx
+type Option func(*validateOptions)
+
+type validateOptions struct {
+	partial bool
+	fields  []string
+}
+
+// Partial validates only provided fields for PATCH requests: non-nil
+// pointers, slices, maps and interfaces. Other fields are always provided,
+// so patch DTOs declare optional fields as pointers:
+//
+//	type UpdateUserInput struct {
+//		Name  *string `json:"name" validate:"required,min=2"`
+//		Email *string `json:"email" validate:"required,email"`
+//	}
+func Partial() Option {
+	return func(o *validateOptions) {
+		o.partial = true
+	}
+}
+
+// Fields validates only fields of the mask, JSON names with dots for nested
+// fields, e.g. "address.city". A struct field includes all its fields.
+// ParseMergePatch returns the mask of a JSON merge patch
+func Fields(paths ...string) Option {
+	return func(o *validateOptions) {
+		o.fields = append(o.fields, paths...)
+	}
+}
+
+// ParseMergePatch applies a JSON merge patch (RFC 7396) to dst and returns
+// paths of provided fields for Fields. dst is usually the current entity or
+// DTO, so omitted fields keep their values. null sets a field to its zero
+// value and still counts as provided, so required fields cannot be removed
+func ParseMergePatch(body []byte, dst interface{}) ([]string, error) {
+	var patch map[string]interface{}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	if err := decoder.Decode(&patch); err != nil {
+		return nil, errors.Wrap(err, "bad_request", "Invalid merge patch", http.StatusBadRequest)
+	}
+
+	target := reflect.ValueOf(dst)
+	if target.Kind() != reflect.Ptr || target.IsNil() {
+		return nil, fmt.Errorf("merge patch target must be a non-nil pointer, got %T", dst)
+	}
+	if err := applyMergePatch(target.Elem(), patch, ""); err != nil {
+		return nil, errors.Wrap(err, "bad_request", "Invalid merge patch", http.StatusBadRequest)
+	}
+
+	var paths []string
+	collectPatchPaths(patch, "", &paths)
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// applyMergePatch sets patched fields, nested objects are merged into
+// existing structs instead of replacing them, maps and slices are replaced
+func applyMergePatch(dst reflect.Value, patch map[string]interface{}, prefix string) error {
+	for dst.Kind() == reflect.Ptr {
+		if dst.IsNil() {
+			dst.Set(reflect.New(dst.Type().Elem()))
+		}
+		dst = dst.Elem()
+	}
+	if dst.Kind() != reflect.Struct {
+		data, err := json.Marshal(patch)
+		if err != nil {
+			return err
+		}
+		return json.Unmarshal(data, dst.Addr().Interface())
+	}
+
+	for key, value := range patch {
+		field, ok := fieldByJSONName(dst.Type(), key)
+		if !ok {
+			return fmt.Errorf("unknown field %q", prefix+key)
+		}
+		target, ok := settableField(dst, field.Index)
+		if !ok {
+			return fmt.Errorf("cannot set %q of nil embedded pointer to unexported struct", prefix+key)
+		}
+
+		if value == nil {
+			target.Set(reflect.Zero(target.Type()))
+			continue
+		}
+		if nested, ok := value.(map[string]interface{}); ok && isStructLike(target.Type()) {
+			if err := applyMergePatch(target, nested, prefix+key+"."); err != nil {
+				return err
+			}
+			continue
+		}
+
+		data, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		fresh := reflect.New(target.Type())
+		if err := json.Unmarshal(data, fresh.Interface()); err != nil {
+			return fmt.Errorf("invalid value of %q: %w", prefix+key, err)
+		}
+		target.Set(fresh.Elem())
+	}
+	return nil
+}
+
+func collectPatchPaths(patch map[string]interface{}, prefix string, paths *[]string) {
+	for key, value := range patch {
+		path := prefix + key
+		if nested, ok := value.(map[string]interface{}); ok && len(nested) > 0 {
+			collectPatchPaths(nested, path+".", paths)
+			continue
+		}
+		*paths = append(*paths, path)
+	}
+}
+
+// partialFields returns Go namespaces of fields to validate, e.g.
+// "Address.City", relative to the struct like StructPartial expects
+func (v *Validator) partialFields(data interface{}, o validateOptions) (map[string]bool, error) {
+	value := reflect.ValueOf(data)
+	for value.Kind() == reflect.Ptr && !value.IsNil() {
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return nil, nil
+	}
+
+	include := make(map[string]bool)
+	if o.partial {
+		providedFields(value, "", include)
+	}
+	for _, path := range o.fields {
+		paths, err := v.maskPaths(value.Type(), path)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range paths {
+			include[p] = true
+		}
+	}
+	return include, nil
+}
+
+// providedFields adds fields that are not nil, descending into structs
+func providedFields(v reflect.Value, prefix string, include map[string]bool) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		fv := v.Field(i)
+		switch fv.Kind() {
+		case reflect.Ptr, reflect.Slice, reflect.Map, reflect.Interface:
+			if fv.IsNil() {
+				continue
+			}
+		}
+
+		name := prefix + f.Name
+		include[name] = true
+
+		for fv.Kind() == reflect.Ptr {
+			fv = fv.Elem()
+		}
+		if fv.Kind() == reflect.Struct && fv.Type().PkgPath() != "time" {
+			providedFields(fv, name+".", include)
+		}
+	}
+}
+
+// maskFields resolves a JSON path to Go names, a struct includes all fields
+func maskFields(t reflect.Type, path string, include map[string]bool) error {
+	var names []string
+	for _, key := range strings.Split(path, ".") {
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		if t.Kind() != reflect.Struct {
+			return validationError(map[string]interface{}{path: "unknown field"})
+		}
+		field, ok := fieldByJSONName(t, key)
+		if !ok {
+			return validationError(map[string]interface{}{path: "unknown field"})
+		}
+		for _, name := range fieldNames(t, field.Index) {
+			names = append(names, name)
+			include[strings.Join(names, ".")] = true
+		}
+		t = field.Type
+	}
+
+	if isStructLike(t) {
+		allFields(t, strings.Join(names, ".")+".", include, map[reflect.Type]bool{})
+	}
+	return nil
+}
+
+// allFields adds fields of t, recursive types are expanded once
+func allFields(t reflect.Type, prefix string, include map[string]bool, path map[reflect.Type]bool) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	path[t] = true
+	defer delete(path, t)
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		include[prefix+f.Name] = true
+
+		ft := f.Type
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if isStructLike(ft) && !path[ft] {
+			allFields(ft, prefix+f.Name+".", include, path)
+		}
+	}
+}
+
+// fieldByJSONName finds a field by its json tag name or, without a tag, by
+// its Go name ignoring case like encoding/json does. Fields promoted from
+// untagged embedded structs are found as well, the shallowest one wins and
+// a tagged one wins at the same depth, other conflicts hide the name
+func fieldByJSONName(t reflect.Type, name string) (reflect.StructField, bool) {
+	var (
+		found     reflect.StructField
+		tagged    bool
+		ambiguous bool
+	)
+	for _, f := range reflect.VisibleFields(t) {
+		if !f.IsExported() || !promoted(t, f.Index) {
+			continue
+		}
+		tag, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if tag == "-" || (f.Anonymous && tag == "" && isStructLike(f.Type)) {
+			continue
+		}
+		if tag != name && (tag != "" || !strings.EqualFold(f.Name, name)) {
+			continue
+		}
+
+		switch {
+		case found.Index == nil || len(f.Index) < len(found.Index):
+			found, tagged, ambiguous = f, tag != "", false
+		case len(f.Index) == len(found.Index):
+			if tag != "" && !tagged {
+				found, tagged, ambiguous = f, true, false
+			} else if (tag != "") == tagged {
+				ambiguous = true
+			}
+		}
+	}
+	if found.Index == nil || ambiguous {
+		return reflect.StructField{}, false
+	}
+	return found, true
+}
+
+// promoted reports whether encoding/json promotes the field at index, all
+// structs on the way must be embedded without a json name
+func promoted(t reflect.Type, index []int) bool {
+	for _, i := range index[:len(index)-1] {
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		f := t.Field(i)
+		if name, _, _ := strings.Cut(f.Tag.Get("json"), ","); !f.Anonymous || name != "" {
+			return false
+		}
+		t = f.Type
+	}
+	return true
+}
+
+// fieldNames returns Go names of fields on the way to index, embedded
+// structs included like validator namespaces
+func fieldNames(t reflect.Type, index []int) []string {
+	names := make([]string, 0, len(index))
+	for _, i := range index {
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		f := t.Field(i)
+		names = append(names, f.Name)
+		t = f.Type
+	}
+	return names
+}
+
+// settableField returns the field at index, allocating nil embedded
+// pointers. Like encoding/json it fails on a nil pointer to an unexported
+// struct, which cannot be set
+func settableField(v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 {
+			for v.Kind() == reflect.Ptr {
+				if v.IsNil() {
+					if !v.CanSet() {
+						return reflect.Value{}, false
+					}
+					v.Set(reflect.New(v.Type().Elem()))
+				}
+				v = v.Elem()
+			}
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
+
+func isStructLike(t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct && t.PkgPath() != "time"
+}
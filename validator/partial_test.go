@@ -0,0 +1,127 @@
+package validator
+
+import (
+	"context"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/alimzhanovlr/sdk/errors"
+)
+
+type AuditFields struct {
+	CreatedBy string `json:"created_by" validate:"required"`
+	Version   int    `json:"version" validate:"gte=1"`
+}
+
+type Meta struct {
+	Note string `json:"note" validate:"max=5"`
+}
+
+type patchDocument struct {
+	*AuditFields
+	Meta
+	Title    string `json:"title" validate:"required"`
+	Revision int    `json:"version"`
+}
+
+type namedEmbedding struct {
+	Meta  `json:"meta"`
+	Title string `json:"title"`
+}
+
+type conflictA struct {
+	Code string
+}
+
+type conflictB struct {
+	Code string
+}
+
+type ambiguousEmbedding struct {
+	conflictA
+	conflictB
+}
+
+func TestFieldByJSONName(t *testing.T) {
+	docType := reflect.TypeOf(patchDocument{})
+	tests := []struct {
+		name  string
+		typ   reflect.Type
+		key   string
+		index []int
+		found bool
+	}{
+		{"own field", docType, "title", []int{2}, true},
+		{"promoted through pointer", docType, "created_by", []int{0, 0}, true},
+		{"promoted value", docType, "note", []int{1, 0}, true},
+		{"untagged name ignores case", docType, "TITLE", nil, false},
+		{"own field wins over promoted one", docType, "version", []int{3}, true},
+		{"embedded struct itself", docType, "Meta", nil, false},
+		{"tagged embedding is not promoted", reflect.TypeOf(namedEmbedding{}), "note", nil, false},
+		{"tagged embedding by name", reflect.TypeOf(namedEmbedding{}), "meta", []int{0}, true},
+		{"ambiguous", reflect.TypeOf(ambiguousEmbedding{}), "code", nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			field, ok := fieldByJSONName(tt.typ, tt.key)
+			if ok != tt.found {
+				t.Fatalf("found = %v, want %v", ok, tt.found)
+			}
+			if ok && !slices.Equal(field.Index, tt.index) {
+				t.Errorf("index = %v, want %v", field.Index, tt.index)
+			}
+		})
+	}
+}
+
+func TestParseMergePatchEmbeddedFields(t *testing.T) {
+	var doc patchDocument
+	paths, err := ParseMergePatch([]byte(`{"created_by":"ann","note":"hi","title":"Doc"}`), &doc)
+	if err != nil {
+		t.Fatalf("ParseMergePatch() error = %v", err)
+	}
+
+	if doc.AuditFields == nil || doc.CreatedBy != "ann" || doc.Note != "hi" || doc.Title != "Doc" {
+		t.Errorf("unexpected document %+v", doc)
+	}
+	if !slices.Equal(paths, []string{"created_by", "note", "title"}) {
+		t.Errorf("paths = %v", paths)
+	}
+}
+
+type hiddenEmbedding struct {
+	*conflictA
+}
+
+func TestParseMergePatchNilUnexportedEmbedding(t *testing.T) {
+	if _, err := ParseMergePatch([]byte(`{"code":"x"}`), &hiddenEmbedding{}); err == nil {
+		t.Error("expected error for nil pointer to unexported struct")
+	}
+
+	doc := hiddenEmbedding{conflictA: &conflictA{}}
+	if _, err := ParseMergePatch([]byte(`{"code":"x"}`), &doc); err != nil || doc.Code != "x" {
+		t.Errorf("err = %v, code = %q", err, doc.Code)
+	}
+}
+
+func TestValidateFieldsOfEmbeddedStruct(t *testing.T) {
+	v := New()
+	doc := &patchDocument{AuditFields: &AuditFields{Version: 1}, Meta: Meta{Note: "too long"}}
+
+	err := v.ValidateCtx(context.Background(), doc, Fields("note"))
+	appErr, ok := err.(*errors.AppError)
+	if !ok {
+		t.Fatalf("err = %v, want validation error", err)
+	}
+	if _, ok := appErr.Details["note"]; !ok || len(appErr.Details) != 1 {
+		t.Errorf("details = %v, want only note", appErr.Details)
+	}
+
+	if err := v.ValidateCtx(context.Background(), doc, Fields("title", "version")); err == nil {
+		t.Error("expected title to be required")
+	}
+	if err := v.ValidateCtx(context.Background(), doc, Fields("created_by")); err == nil {
+		t.Error("expected created_by to be required")
+	}
+}
@@ -0,0 +1,235 @@
+package validator
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// maxParallelRules bounds rules of one ValidateCtx call running at once
+const maxParallelRules = 8
+
+// Rule validates a field value with I/O, e.g. uniqueness against a
+// repository or existence of a referenced ID. A violation is returned as
+// Invalid, any other error aborts validation
+type Rule interface {
+	Validate(ctx context.Context, value interface{}) error
+}
+
+// RuleFunc adapts a function to Rule
+type RuleFunc func(ctx context.Context, value interface{}) error
+
+// Validate implements Rule
+func (f RuleFunc) Validate(ctx context.Context, value interface{}) error {
+	return f(ctx, value)
+}
+
+// Violation is a rule failure reported to the client
+type Violation struct {
+	Message string
+}
+
+// Error implements error interface
+func (v *Violation) Error() string {
+	return v.Message
+}
+
+// Invalid returns a violation with formatted message
+func Invalid(format string, args ...interface{}) error {
+	return &Violation{Message: fmt.Sprintf(format, args...)}
+}
+
+// RegisterRule registers a rule referenced by the rules struct tag:
+//
+//	type CreateUserInput struct {
+//		Email   string `json:"email" validate:"required,email" rules:"unique_email"`
+//		GroupID string `json:"group_id" validate:"required,uuid" rules:"group_exists"`
+//	}
+//
+//	v.RegisterRule("unique_email", validator.RuleFunc(func(ctx context.Context, value interface{}) error {
+//		exists, err := repo.EmailExists(ctx, value.(string))
+//		if err != nil {
+//			return err
+//		}
+//		if exists {
+//			return validator.Invalid("email is already taken")
+//		}
+//		return nil
+//	}))
+func (v *Validator) RegisterRule(name string, rule Rule) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.rules == nil {
+		v.rules = make(map[string]Rule)
+	}
+	v.rules[name] = rule
+	v.cache.invalidate()
+}
+
+// ValidateCtx validates static tags, then runs rules of fields that passed
+// them, so a malformed email is not looked up. Zero values are skipped by
+// rules, required handles them. All failures are returned in one
+// validation error. Partial and Fields limit validation to provided fields
+func (v *Validator) ValidateCtx(ctx context.Context, data interface{}, opts ...Option) error {
+	var o validateOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	var include map[string]bool
+	if o.partial || len(o.fields) > 0 {
+		var err error
+		if include, err = v.partialFields(data, o); err != nil {
+			return err
+		}
+	}
+
+	details := make(map[string]interface{})
+
+	var err error
+	if include != nil {
+		err = v.validate.StructPartialCtx(ctx, data, sortedFields(include)...)
+	} else {
+		err = v.validate.StructCtx(ctx, data)
+	}
+	if err != nil && !v.collectFieldErrors(err, details) {
+		return v.formatValidationError(err)
+	}
+
+	checks, err := v.ruleChecks(data, details, include)
+	if err != nil {
+		return err
+	}
+	if err := runRuleChecks(ctx, checks, details); err != nil {
+		return err
+	}
+
+	if len(details) > 0 {
+		return validationError(details)
+	}
+	return nil
+}
+
+// ruleCheck is a rule applied to a field value
+type ruleCheck struct {
+	field string
+	name  string
+	rule  Rule
+	value interface{}
+	err   error
+}
+
+// ruleChecks lists rules of non-zero fields without static errors, only of
+// included fields when include is not nil
+func (v *Validator) ruleChecks(data interface{}, failed map[string]interface{}, include map[string]bool) ([]*ruleCheck, error) {
+	value := reflect.ValueOf(data)
+	for value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return nil, nil
+		}
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return nil, nil
+	}
+
+	info, err := v.typeInfo(value.Type())
+	if err != nil {
+		return nil, err
+	}
+
+	var checks []*ruleCheck
+	for _, field := range info.rules {
+		fieldValue, ok := fieldByIndex(value, field.index)
+		if !ok || fieldValue.IsZero() {
+			continue
+		}
+		if _, ok := failed[field.key]; ok {
+			continue
+		}
+		if include != nil && !include[field.path] {
+			continue
+		}
+		for j, rule := range field.resolved {
+			checks = append(checks, &ruleCheck{
+				field: field.key,
+				name:  field.rules[j],
+				rule:  rule,
+				value: fieldValue.Interface(),
+			})
+		}
+	}
+	return checks, nil
+}
+
+// runRuleChecks runs checks in parallel and adds violations to details, the
+// first violation of a field wins
+func runRuleChecks(ctx context.Context, checks []*ruleCheck, details map[string]interface{}) error {
+	if len(checks) == 0 {
+		return nil
+	}
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, maxParallelRules)
+	for _, check := range checks {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			check.err = check.rule.Validate(ctx, check.value)
+		}()
+	}
+	wg.Wait()
+
+	for _, check := range checks {
+		if check.err == nil {
+			continue
+		}
+		var violation *Violation
+		if !stderrors.As(check.err, &violation) {
+			return fmt.Errorf("failed to run validation rule %s: %w", check.name, check.err)
+		}
+		if _, ok := details[check.field]; !ok {
+			details[check.field] = violation.Message
+		}
+	}
+	return nil
+}
+
+func sortedFields(include map[string]bool) []string {
+	fields := make([]string, 0, len(include))
+	for field := range include {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+func splitRules(tag string) []string {
+	var rules []string
+	for _, name := range strings.Split(tag, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			rules = append(rules, name)
+		}
+	}
+	return rules
+}
+
+// fieldByIndex is reflect.Value.FieldByIndex stopping at nil pointers
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, bool) {
+	for _, idx := range index {
+		if v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+		v = v.Field(idx)
+	}
+	return v, true
+}
@@ -0,0 +1,179 @@
+package validator
+
+import (
+	"context"
+	stderrors "errors"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/alimzhanovlr/sdk/errors"
+)
+
+type signupInput struct {
+	Email   string  `json:"email" validate:"required,email" rules:"unique_email"`
+	GroupID string  `json:"group_id" rules:"group_exists"`
+	Nick    *string `json:"nick" validate:"omitempty,min=2" rules:"nick_free"`
+}
+
+var errRepoDown = stderrors.New("repository is down")
+
+// newRulesValidator registers test rules recording the rules that ran
+func newRulesValidator() (*Validator, func() []string) {
+	var mu sync.Mutex
+	var called []string
+	record := func(name string) {
+		mu.Lock()
+		called = append(called, name)
+		mu.Unlock()
+	}
+
+	v := New()
+	v.RegisterRule("unique_email", RuleFunc(func(ctx context.Context, value interface{}) error {
+		record("unique_email")
+		switch value.(string) {
+		case "taken@example.com":
+			return Invalid("email is already taken")
+		case "down@example.com":
+			return errRepoDown
+		}
+		return nil
+	}))
+	v.RegisterRule("group_exists", RuleFunc(func(ctx context.Context, value interface{}) error {
+		record("group_exists")
+		if value.(string) == "missing" {
+			return Invalid("group %s does not exist", value)
+		}
+		return nil
+	}))
+	v.RegisterRule("nick_free", RuleFunc(func(ctx context.Context, value interface{}) error {
+		record("nick_free")
+		return nil
+	}))
+
+	return v, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		result := slices.Clone(called)
+		slices.Sort(result)
+		return result
+	}
+}
+
+func TestValidateCtxRules(t *testing.T) {
+	nick := "ann"
+	tests := []struct {
+		name        string
+		input       signupInput
+		opts        []Option
+		wantCalled  []string
+		wantDetails map[string]string // keys are lowercased field names, an empty message checks only the key
+		wantErr     error
+	}{
+		{
+			name:       "valid input runs all rules",
+			input:      signupInput{Email: "ann@example.com", GroupID: "admins", Nick: &nick},
+			wantCalled: []string{"group_exists", "nick_free", "unique_email"},
+		},
+		{
+			name:        "field failing static tags is not looked up",
+			input:       signupInput{Email: "not-an-email", GroupID: "admins"},
+			wantCalled:  []string{"group_exists"},
+			wantDetails: map[string]string{"email": ""},
+		},
+		{
+			name:       "zero values are skipped",
+			input:      signupInput{Email: "ann@example.com"},
+			wantCalled: []string{"unique_email"},
+		},
+		{
+			name:       "violations are aggregated",
+			input:      signupInput{Email: "taken@example.com", GroupID: "missing"},
+			wantCalled: []string{"group_exists", "unique_email"},
+			wantDetails: map[string]string{
+				"email":   "email is already taken",
+				"groupid": "group missing does not exist",
+			},
+		},
+		{
+			name:       "other errors abort validation",
+			input:      signupInput{Email: "down@example.com", GroupID: "missing"},
+			wantCalled: []string{"group_exists", "unique_email"},
+			wantErr:    errRepoDown,
+		},
+		{
+			name:       "fields limit rules",
+			input:      signupInput{Email: "taken@example.com", GroupID: "admins"},
+			opts:       []Option{Fields("group_id")},
+			wantCalled: []string{"group_exists"},
+		},
+		{
+			name:       "partial skips absent pointers",
+			input:      signupInput{Email: "ann@example.com"},
+			opts:       []Option{Partial()},
+			wantCalled: []string{"unique_email"},
+		},
+		{
+			name:       "partial runs rules of provided pointers",
+			input:      signupInput{Email: "ann@example.com", Nick: &nick},
+			opts:       []Option{Partial()},
+			wantCalled: []string{"nick_free", "unique_email"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, called := newRulesValidator()
+			err := v.ValidateCtx(context.Background(), &tt.input, tt.opts...)
+
+			if got := called(); !slices.Equal(got, tt.wantCalled) {
+				t.Errorf("rules called = %v, want %v", got, tt.wantCalled)
+			}
+
+			switch {
+			case tt.wantErr != nil:
+				if !stderrors.Is(err, tt.wantErr) {
+					t.Fatalf("error = %v, want %v", err, tt.wantErr)
+				}
+				var appErr *errors.AppError
+				if stderrors.As(err, &appErr) {
+					t.Errorf("error = %v, want an internal error, not a validation error", err)
+				}
+			case len(tt.wantDetails) == 0:
+				if err != nil {
+					t.Fatalf("error = %v, want nil", err)
+				}
+			default:
+				appErr, ok := err.(*errors.AppError)
+				if !ok {
+					t.Fatalf("error = %v, want a validation error", err)
+				}
+				if len(appErr.Details) != len(tt.wantDetails) {
+					t.Errorf("details = %v, want keys of %v", appErr.Details, tt.wantDetails)
+				}
+				for field, message := range tt.wantDetails {
+					got, ok := appErr.Details[field]
+					if !ok {
+						t.Errorf("details miss %s: %v", field, appErr.Details)
+					} else if message != "" && got != message {
+						t.Errorf("details[%s] = %v, want %q", field, got, message)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestValidateCtxUnregisteredRule(t *testing.T) {
+	type input struct {
+		Code string `json:"code" rules:"code_exists"`
+	}
+
+	err := New().ValidateCtx(context.Background(), input{Code: "x"})
+	if err == nil {
+		t.Fatal("error = nil, want an unregistered rule error")
+	}
+	if _, ok := err.(*errors.AppError); ok {
+		t.Errorf("error = %v, want an internal error, not a validation error", err)
+	}
+}
@@ -3,6 +3,7 @@ package validator
 import (
 	"fmt"
 	"strings"
+	"sync"
 
 	"github.com/alimzhanovlr/sdk/errors"
 	"github.com/go-playground/validator/v10"
@@ -11,12 +12,34 @@ import (
 // Validator wraps go-playground validator
 type Validator struct {
 	validate *validator.Validate
+
+	mu    sync.RWMutex
+	rules map[string]Rule
+	cache typeCache
+}
+
+// Config holds validator configuration
+type Config struct {
+	// CacheTypes memoizes rules and field masks per struct type, disable it
+	// only when types are generated at runtime
+	CacheTypes bool
+}
+
+// DefaultConfig returns default validator config
+func DefaultConfig() Config {
+	return Config{CacheTypes: true}
 }
 
 // New creates a new validator instance
 func New() *Validator {
+	return NewWithConfig(DefaultConfig())
+}
+
+// NewWithConfig creates a validator with config
+func NewWithConfig(cfg Config) *Validator {
 	return &Validator{
 		validate: validator.New(),
+		cache:    typeCache{enabled: cfg.CacheTypes},
 	}
 }
 
@@ -30,18 +53,34 @@ func (v *Validator) Validate(data interface{}) error {
 
 // formatValidationError formats validation errors into AppError
 func (v *Validator) formatValidationError(err error) error {
-	if validationErrors, ok := err.(validator.ValidationErrors); ok {
-		details := make(map[string]interface{})
+	details := make(map[string]interface{})
+	if v.collectFieldErrors(err, details) {
+		return validationError(details)
+	}
 
-		for _, e := range validationErrors {
-			field := strings.ToLower(e.Field())
-			details[field] = formatFieldError(e)
-		}
+	return errors.Wrap(err, "validation_error", "Validation failed", 400)
+}
 
-		return errors.ErrValidation.WithDetails(details)
+// collectFieldErrors adds field errors to details, false when err is not
+// a field validation error
+func (v *Validator) collectFieldErrors(err error, details map[string]interface{}) bool {
+	validationErrors, ok := err.(validator.ValidationErrors)
+	if !ok {
+		return false
 	}
 
-	return errors.Wrap(err, "validation_error", "Validation failed", 400)
+	for _, e := range validationErrors {
+		field := strings.ToLower(e.Field())
+		details[field] = formatFieldError(e)
+	}
+	return true
+}
+
+// validationError copies ErrValidation, so details of concurrent requests
+// do not overwrite each other
+func validationError(details map[string]interface{}) error {
+	return errors.New(errors.ErrValidation.Code, errors.ErrValidation.Message, errors.ErrValidation.StatusCode).
+		WithDetails(details)
 }
 
 // formatFieldError formats a single field validation error
@@ -0,0 +1,145 @@
+package validator
+
+import (
+	"context"
+	"testing"
+)
+
+type benchAddress struct {
+	City    string `json:"city" validate:"required"`
+	Country string `json:"country" validate:"required,len=2"`
+}
+
+type benchCreateOrder struct {
+	CustomerID string        `json:"customer_id" validate:"required,uuid" rules:"customer_exists"`
+	Email      string        `json:"email" validate:"required,email"`
+	Amount     float64       `json:"amount" validate:"gt=0"`
+	Currency   string        `json:"currency" validate:"oneof=KZT RUB USD"`
+	Address    *benchAddress `json:"address" validate:"required"`
+}
+
+type benchUpdateOrder struct {
+	Email    *string       `json:"email" validate:"required,email"`
+	Amount   *float64      `json:"amount" validate:"required,gt=0"`
+	Currency *string       `json:"currency" validate:"required,oneof=KZT RUB USD"`
+	Address  *benchAddress `json:"address"`
+}
+
+func newBenchValidator(cache bool) *Validator {
+	v := NewWithConfig(Config{CacheTypes: cache})
+	v.RegisterRule("customer_exists", RuleFunc(func(ctx context.Context, value interface{}) error {
+		return nil
+	}))
+	return v
+}
+
+func benchOrder() *benchCreateOrder {
+	return &benchCreateOrder{
+		CustomerID: "0b6f0c1e-6a5b-4a8e-9b8a-2f1f3c4d5e6f",
+		Email:      "user@example.com",
+		Amount:     1500,
+		Currency:   "KZT",
+		Address:    &benchAddress{City: "Almaty", Country: "KZ"},
+	}
+}
+
+// ====================================================================================
+// BENCHMARKS: full validation
+// ====================================================================================
+
+func BenchmarkValidate_Static(b *testing.B) {
+	v := newBenchValidator(true)
+	order := benchOrder()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = v.Validate(order)
+	}
+}
+
+func BenchmarkValidateCtx_Rules_Cached(b *testing.B) {
+	v := newBenchValidator(true)
+	order := benchOrder()
+	ctx := context.Background()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = v.ValidateCtx(ctx, order)
+	}
+}
+
+func BenchmarkValidateCtx_Rules_Uncached(b *testing.B) {
+	v := newBenchValidator(false)
+	order := benchOrder()
+	ctx := context.Background()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = v.ValidateCtx(ctx, order)
+	}
+}
+
+func BenchmarkValidateCtx_Invalid(b *testing.B) {
+	v := newBenchValidator(true)
+	order := benchOrder()
+	order.Email = "not-an-email"
+	order.Amount = 0
+	ctx := context.Background()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = v.ValidateCtx(ctx, order)
+	}
+}
+
+func BenchmarkValidateCtx_Parallel(b *testing.B) {
+	v := newBenchValidator(true)
+	ctx := context.Background()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		order := benchOrder()
+		for pb.Next() {
+			_ = v.ValidateCtx(ctx, order)
+		}
+	})
+}
+
+// ====================================================================================
+// BENCHMARKS: partial validation
+// ====================================================================================
+
+func BenchmarkValidateCtx_Partial(b *testing.B) {
+	v := newBenchValidator(true)
+	email := "user@example.com"
+	input := &benchUpdateOrder{Email: &email}
+	ctx := context.Background()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = v.ValidateCtx(ctx, input, Partial())
+	}
+}
+
+func BenchmarkValidateCtx_Fields_Cached(b *testing.B) {
+	v := newBenchValidator(true)
+	order := benchOrder()
+	ctx := context.Background()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = v.ValidateCtx(ctx, order, Fields("email", "address.city"))
+	}
+}
+
+func BenchmarkValidateCtx_Fields_Uncached(b *testing.B) {
+	v := newBenchValidator(false)
+	order := benchOrder()
+	ctx := context.Background()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = v.ValidateCtx(ctx, order, Fields("email", "address.city"))
+	}
+}
+
+func BenchmarkParseMergePatch(b *testing.B) {
+	body := []byte(`{"email":"new@example.com","address":{"city":"Astana"}}`)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		order := benchOrder()
+		_, _ = ParseMergePatch(body, order)
+	}
+}
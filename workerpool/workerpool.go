@@ -0,0 +1,313 @@
+// Package workerpool runs tasks on a fixed number of goroutines with a
+// bounded queue, so background work cannot grow without limit under load.
+// It backs the "background" pool of app.Module and concurrent publishing in
+// outbox.Relay. Network log sinks of package logger keep their own single
+// sender goroutine instead: pool workers would reorder entries and send
+// them one by one instead of in batches
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	apperrors "github.com/alimzhanovlr/sdk/errors"
+)
+
+var (
+	// ErrClosed is returned when submitting to a pool after Shutdown
+	ErrClosed = errors.New("workerpool: pool is closed")
+	// ErrQueueFull is returned by TrySubmit when the queue is full
+	ErrQueueFull = errors.New("workerpool: queue is full")
+)
+
+// Task is a unit of work, ctx is cancelled on its timeout and when
+// Shutdown gives up waiting
+type Task func(ctx context.Context) error
+
+// Result is the outcome of a task reported to Metrics
+type Result string
+
+const (
+	ResultSuccess Result = "success"
+	ResultFailure Result = "failure"
+	ResultPanic   Result = "panic"
+)
+
+// Metrics records pool activity, metrics.Registry.WorkerPool implements it
+type Metrics interface {
+	ObserveQueue(pool string, depth int)
+	ObserveTask(pool string, result Result, wait, duration time.Duration)
+}
+
+// Config holds pool configuration
+type Config struct {
+	// Name identifies the pool in metrics and errors
+	Name    string
+	Workers int
+	// QueueSize is the number of tasks waiting for a worker, Submit blocks
+	// and TrySubmit fails when it is full
+	QueueSize int
+	// TaskTimeout bounds every task unless overridden by Timeout, zero
+	// means no limit
+	TaskTimeout time.Duration
+	// OnError is called with errors of tasks, panics are recovered into
+	// internal AppError
+	OnError func(ctx context.Context, err error)
+	Metrics Metrics
+}
+
+// DefaultConfig returns default pool config
+func DefaultConfig() Config {
+	return Config{
+		Name:      "default",
+		Workers:   8,
+		QueueSize: 100,
+	}
+}
+
+// Stats holds pool counters
+type Stats struct {
+	Workers   int
+	Queued    int
+	Running   int64
+	Completed uint64 // tasks returned nil
+	Failed    uint64 // tasks returned an error or panicked
+}
+
+// Pool executes submitted tasks on a fixed number of workers
+type Pool struct {
+	config Config
+	queue  chan job
+
+	// ctx is cancelled when Shutdown stops waiting, so running tasks
+	// can give up
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu     sync.RWMutex
+	closed bool
+	// closing wakes Submit calls waiting for queue space, so Shutdown can
+	// take the write lock
+	closing   chan struct{}
+	closeOnce sync.Once
+	wg        sync.WaitGroup
+
+	running   atomic.Int64
+	completed atomic.Uint64
+	failed    atomic.Uint64
+}
+
+// job is a queued task
+type job struct {
+	ctx     context.Context
+	task    Task
+	timeout time.Duration
+	queued  time.Time
+}
+
+// New creates a pool and starts its workers
+func New(config Config) *Pool {
+	defaults := DefaultConfig()
+	if config.Name == "" {
+		config.Name = defaults.Name
+	}
+	if config.Workers <= 0 {
+		config.Workers = defaults.Workers
+	}
+	if config.QueueSize < 0 {
+		config.QueueSize = 0
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &Pool{
+		config:  config,
+		queue:   make(chan job, config.QueueSize),
+		ctx:     ctx,
+		cancel:  cancel,
+		closing: make(chan struct{}),
+	}
+
+	p.wg.Add(config.Workers)
+	for i := 0; i < config.Workers; i++ {
+		go p.work()
+	}
+	return p
+}
+
+// TaskOption configures a submitted task
+type TaskOption func(*job)
+
+// Timeout overrides Config.TaskTimeout for the task
+func Timeout(d time.Duration) TaskOption {
+	return func(j *job) {
+		j.timeout = d
+	}
+}
+
+// Submit queues task, waiting for space while ctx allows. The task gets
+// values and trace of ctx but not its cancellation, so work submitted from
+// a request handler outlives the request
+func (p *Pool) Submit(ctx context.Context, task Task, opts ...TaskOption) error {
+	j := p.newJob(ctx, task, opts)
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.closed {
+		return ErrClosed
+	}
+
+	select {
+	case p.queue <- j:
+		p.observeQueue()
+		return nil
+	default:
+	}
+
+	select {
+	case p.queue <- j:
+		p.observeQueue()
+		return nil
+	case <-p.closing:
+		return ErrClosed
+	case <-ctx.Done():
+		return fmt.Errorf("failed to submit task to pool %s: %w", p.config.Name, ctx.Err())
+	}
+}
+
+// TrySubmit queues task without waiting, ErrQueueFull means the caller
+// should shed the work or do it inline
+func (p *Pool) TrySubmit(ctx context.Context, task Task, opts ...TaskOption) error {
+	j := p.newJob(ctx, task, opts)
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.closed {
+		return ErrClosed
+	}
+
+	select {
+	case p.queue <- j:
+		p.observeQueue()
+		return nil
+	default:
+		return ErrQueueFull
+	}
+}
+
+// Shutdown stops accepting tasks and waits until queued and running ones
+// finish. When ctx is done first, contexts of remaining tasks are cancelled
+// and the error reports how many were still pending
+func (p *Pool) Shutdown(ctx context.Context) error {
+	p.closeOnce.Do(func() { close(p.closing) })
+
+	p.mu.Lock()
+	if !p.closed {
+		p.closed = true
+		// Submit holds the read lock while sending, so no send can follow
+		close(p.queue)
+	}
+	p.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		p.cancel()
+		return nil
+	case <-ctx.Done():
+		pending := len(p.queue) + int(p.running.Load())
+		p.cancel()
+		return fmt.Errorf("failed to drain pool %s, %d tasks pending: %w", p.config.Name, pending, ctx.Err())
+	}
+}
+
+// Stats returns pool counters
+func (p *Pool) Stats() Stats {
+	return Stats{
+		Workers:   p.config.Workers,
+		Queued:    len(p.queue),
+		Running:   p.running.Load(),
+		Completed: p.completed.Load(),
+		Failed:    p.failed.Load(),
+	}
+}
+
+func (p *Pool) newJob(ctx context.Context, task Task, opts []TaskOption) job {
+	j := job{
+		ctx:     context.WithoutCancel(ctx),
+		task:    task,
+		timeout: p.config.TaskTimeout,
+		queued:  time.Now(),
+	}
+	for _, opt := range opts {
+		opt(&j)
+	}
+	return j
+}
+
+func (p *Pool) work() {
+	defer p.wg.Done()
+
+	for j := range p.queue {
+		p.observeQueue()
+		p.run(j)
+	}
+}
+
+func (p *Pool) run(j job) {
+	p.running.Add(1)
+	defer p.running.Add(-1)
+
+	ctx, cancel := context.WithCancel(j.ctx)
+	defer cancel()
+	stop := context.AfterFunc(p.ctx, cancel)
+	defer stop()
+	if j.timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, j.timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	err := execute(ctx, j.task)
+	duration := time.Since(start)
+
+	result := ResultSuccess
+	if err != nil {
+		result = ResultFailure
+		var panicErr *apperrors.PanicError
+		if errors.As(err, &panicErr) {
+			result = ResultPanic
+		}
+
+		p.failed.Add(1)
+		if p.config.OnError != nil {
+			p.config.OnError(j.ctx, err)
+		}
+	} else {
+		p.completed.Add(1)
+	}
+
+	if p.config.Metrics != nil {
+		p.config.Metrics.ObserveTask(p.config.Name, result, start.Sub(j.queued), duration)
+	}
+}
+
+func (p *Pool) observeQueue() {
+	if p.config.Metrics != nil {
+		p.config.Metrics.ObserveQueue(p.config.Name, len(p.queue))
+	}
+}
+
+// execute runs task recovering its panic
+func execute(ctx context.Context, task Task) (err error) {
+	defer apperrors.Recover(&err)
+	return task(ctx)
+}
@@ -0,0 +1,195 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	apperrors "github.com/alimzhanovlr/sdk/errors"
+)
+
+type recordingMetrics struct {
+	mu      sync.Mutex
+	results []Result
+}
+
+func (m *recordingMetrics) ObserveQueue(string, int) {}
+
+func (m *recordingMetrics) ObserveTask(_ string, result Result, _, _ time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.results = append(m.results, result)
+}
+
+func TestShutdownDrainsQueuedTasks(t *testing.T) {
+	pool := New(Config{Name: "test", Workers: 2, QueueSize: 20})
+
+	var done atomic.Int64
+	for i := 0; i < 20; i++ {
+		err := pool.Submit(context.Background(), func(context.Context) error {
+			time.Sleep(time.Millisecond)
+			done.Add(1)
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("Submit() error = %v", err)
+		}
+	}
+
+	if err := pool.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	if got := done.Load(); got != 20 {
+		t.Errorf("completed %d tasks, want 20", got)
+	}
+	if stats := pool.Stats(); stats.Completed != 20 || stats.Queued != 0 {
+		t.Errorf("Stats() = %+v", stats)
+	}
+
+	if err := pool.Submit(context.Background(), func(context.Context) error { return nil }); !errors.Is(err, ErrClosed) {
+		t.Errorf("Submit() after Shutdown error = %v, want ErrClosed", err)
+	}
+	if err := pool.TrySubmit(context.Background(), func(context.Context) error { return nil }); !errors.Is(err, ErrClosed) {
+		t.Errorf("TrySubmit() after Shutdown error = %v, want ErrClosed", err)
+	}
+}
+
+func TestShutdownDeadlineCancelsRunningTasks(t *testing.T) {
+	pool := New(Config{Name: "test", Workers: 1, QueueSize: 1})
+
+	started := make(chan struct{})
+	cancelled := make(chan struct{})
+	_ = pool.Submit(context.Background(), func(ctx context.Context) error {
+		close(started)
+		<-ctx.Done()
+		close(cancelled)
+		return ctx.Err()
+	})
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err := pool.Shutdown(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Shutdown() error = %v, want deadline exceeded", err)
+	}
+
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Fatal("running task was not cancelled")
+	}
+}
+
+func TestShutdownWakesBlockedSubmit(t *testing.T) {
+	pool := New(Config{Name: "test", Workers: 1, QueueSize: 1})
+
+	release := make(chan struct{})
+	block := func(context.Context) error {
+		<-release
+		return nil
+	}
+	// One task running, one queued, the next Submit waits for space
+	_ = pool.Submit(context.Background(), block)
+	_ = pool.Submit(context.Background(), block)
+
+	submitted := make(chan error, 1)
+	go func() {
+		submitted <- pool.Submit(context.Background(), block)
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := pool.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Shutdown() error = %v, want deadline exceeded", err)
+	}
+
+	select {
+	case err := <-submitted:
+		if !errors.Is(err, ErrClosed) {
+			t.Errorf("blocked Submit() error = %v, want ErrClosed", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Submit stayed blocked after Shutdown")
+	}
+	close(release)
+}
+
+func TestTaskTimeout(t *testing.T) {
+	metrics := &recordingMetrics{}
+	var failed atomic.Value
+	pool := New(Config{
+		Name:        "test",
+		Workers:     1,
+		TaskTimeout: time.Hour,
+		Metrics:     metrics,
+		OnError:     func(_ context.Context, err error) { failed.Store(err) },
+	})
+
+	_ = pool.Submit(context.Background(), func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}, Timeout(10*time.Millisecond))
+
+	if err := pool.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	if err, _ := failed.Load().(error); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("OnError got %v, want deadline exceeded", err)
+	}
+	if len(metrics.results) != 1 || metrics.results[0] != ResultFailure {
+		t.Errorf("results = %v, want [failure]", metrics.results)
+	}
+}
+
+func TestPanicIsRecovered(t *testing.T) {
+	metrics := &recordingMetrics{}
+	var failed atomic.Value
+	pool := New(Config{
+		Name:    "test",
+		Workers: 1,
+		Metrics: metrics,
+		OnError: func(_ context.Context, err error) { failed.Store(err) },
+	})
+
+	_ = pool.Submit(context.Background(), func(context.Context) error {
+		panic("boom")
+	})
+	_ = pool.Submit(context.Background(), func(context.Context) error { return nil })
+
+	if err := pool.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+
+	var panicErr *apperrors.PanicError
+	if err, _ := failed.Load().(error); !errors.As(err, &panicErr) {
+		t.Errorf("OnError got %v, want PanicError", err)
+	}
+	if stats := pool.Stats(); stats.Failed != 1 || stats.Completed != 1 {
+		t.Errorf("Stats() = %+v, worker did not survive the panic", stats)
+	}
+	if len(metrics.results) != 2 || metrics.results[0] != ResultPanic {
+		t.Errorf("results = %v, want [panic success]", metrics.results)
+	}
+}
+
+func TestTrySubmitQueueFull(t *testing.T) {
+	pool := New(Config{Name: "test", Workers: 1, QueueSize: 0})
+
+	release := make(chan struct{})
+	_ = pool.Submit(context.Background(), func(context.Context) error {
+		<-release
+		return nil
+	})
+
+	if err := pool.TrySubmit(context.Background(), func(context.Context) error { return nil }); !errors.Is(err, ErrQueueFull) {
+		t.Errorf("TrySubmit() error = %v, want ErrQueueFull", err)
+	}
+
+	close(release)
+	_ = pool.Shutdown(context.Background())
+}